@@ -0,0 +1,539 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	c "github.com/Azareal/Gosora/common"
+	qgen "github.com/Azareal/Gosora/query_gen"
+	"github.com/pkg/errors"
+)
+
+// The rows the sources hand over, the IDs in them are the ones from the other software and are mapped to the ones they're given in Gosora by the importer
+
+type Group struct {
+	ID       int
+	Name     string
+	Tag      string
+	IsAdmin  bool
+	IsMod    bool
+	IsBanned bool
+	MapTo    int // An existing group in Gosora which this one should be folded into rather than being created, e.g. the administrators
+}
+
+type User struct {
+	ID           int
+	Name         string
+	Email        string
+	Hash         string // The password hash, it's kept when CheckPassword knows how to check it, otherwise the user has to reset their password
+	Salt         string
+	Group        int
+	Active       bool
+	CreatedAt    time.Time
+	LastActiveAt time.Time
+}
+
+type Forum struct {
+	ID     int
+	Name   string
+	Desc   string
+	Active bool
+}
+
+type Topic struct {
+	ID        int
+	Forum     int
+	Title     string
+	Content   string
+	CreatedBy int
+	CreatedAt time.Time
+	IP        string
+	Closed    bool
+	Sticky    bool
+	Views     int
+}
+
+type Reply struct {
+	ID        int
+	Topic     int
+	Content   string
+	CreatedBy int
+	CreatedAt time.Time
+	IP        string
+}
+
+type Attachment struct {
+	ID         int
+	Topic      int
+	Reply      int // Zero when the attachment is on the opening post of the topic
+	UploadedBy int
+	Name       string // The name the file was uploaded with, the extension is taken from this
+	Path       string // Where the file can be found on this machine
+}
+
+// Message is a private message, the replies to a message point to the first message in the conversation with Root
+type Message struct {
+	ID        int
+	Root      int
+	From      int
+	To        []int
+	Body      string
+	CreatedAt time.Time
+}
+
+// Source is a piece of forum software which can be imported from.
+// Each method calls f on every row of that kind in the order they should be imported in, replies have to come after the replies they follow in the same topic.
+type Source interface {
+	Groups(f func(g *Group) error) error
+	Users(f func(u *User) error) error
+	Forums(f func(fo *Forum) error) error
+	Topics(f func(t *Topic) error) error
+	Replies(f func(r *Reply) error) error
+	Attachments(f func(a *Attachment) error) error
+	Messages(f func(m *Message) error) error
+}
+
+// Importer brings the rows from a source into Gosora through the stores, so that the counters and caches are kept up to date as they are for posts made on the forum.
+// The stores stamp everything with the current time, so the timestamps are put back afterwards.
+type Importer struct {
+	src    Source
+	state  *State
+	orphan int
+
+	setUserDates      *sql.Stmt
+	setTopicMeta      *sql.Stmt
+	setTopicLastReply *sql.Stmt
+	setReplyDate      *sql.Stmt
+	setConvoDates     *sql.Stmt
+	setConvoLastReply *sql.Stmt
+}
+
+func NewImporter(src Source, state *State, orphan int) (*Importer, error) {
+	acc := qgen.NewAcc()
+	return &Importer{
+		src:    src,
+		state:  state,
+		orphan: orphan,
+
+		setUserDates:      acc.Update("users").Set("createdAt=?,lastActiveAt=?").Where("uid=?").Prepare(),
+		setTopicMeta:      acc.Update("topics").Set("createdAt=?,lastReplyAt=?,views=?").Where("tid=?").Prepare(),
+		setTopicLastReply: acc.Update("topics").Set("lastReplyAt=?").Where("tid=?").Prepare(),
+		setReplyDate:      acc.Update("replies").Set("createdAt=?").Where("rid=?").Prepare(),
+		setConvoDates:     acc.Update("conversations").Set("createdAt=?,lastReplyAt=?").Where("cid=?").Prepare(),
+		setConvoLastReply: acc.Update("conversations").Set("lastReplyBy=?,lastReplyAt=?").Where("cid=?").Prepare(),
+	}, acc.FirstError()
+}
+
+type stage struct {
+	kind string
+	run  func(im *Importer) error
+}
+
+// The order matters, each stage leans on the IDs mapped by the ones before it
+var stages = []stage{
+	{"groups", (*Importer).groups},
+	{"users", (*Importer).users},
+	{"forums", (*Importer).forums},
+	{"topics", (*Importer).topics},
+	{"replies", (*Importer).replies},
+	{"attachments", (*Importer).attachments},
+	{"messages", (*Importer).messages},
+}
+
+// Run goes through each of the stages which haven't been finished yet
+func (im *Importer) Run() error {
+	for _, st := range stages {
+		if im.state.Done(st.kind) {
+			log.Printf("The %s have already been imported", st.kind)
+			continue
+		}
+		log.Printf("Importing the %s", st.kind)
+		err := st.run(im)
+		if err != nil {
+			return errors.Wrap(err, "importing the "+st.kind)
+		}
+		err = im.state.MarkDone(st.kind)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// user maps the ID of a user in the source to the one they have here, posts by users who weren't imported, such as guests, are given to the orphan user
+func (im *Importer) user(oldID int) int {
+	uid, ok := im.state.Get("users", oldID)
+	if !ok {
+		return im.orphan
+	}
+	return uid
+}
+
+func (im *Importer) groups() error {
+	// Groups which are created rather than folded into an existing one start out with the permissions of the default group, as Gosora doesn't have an equivalent for the permission systems of the other software
+	base, err := c.Groups.Get(c.Config.DefaultGroup)
+	if err != nil {
+		return err
+	}
+	var perms map[string]bool
+	err = json.Unmarshal(base.PermissionsText, &perms)
+	if err != nil {
+		return err
+	}
+
+	return im.src.Groups(func(g *Group) error {
+		if _, ok := im.state.Get("groups", g.ID); ok {
+			return nil
+		}
+		if g.MapTo != 0 {
+			return im.state.Set("groups", g.ID, g.MapTo)
+		}
+		gid, err := c.Groups.Create(truncate(g.Name, 100), truncate(g.Tag, 50), g.IsAdmin, g.IsMod, g.IsBanned)
+		if err != nil {
+			return err
+		}
+		group, err := c.Groups.Get(gid)
+		if err != nil {
+			return err
+		}
+		err = group.UpdatePerms(perms)
+		if err != nil {
+			return err
+		}
+		log.Printf("Created the group '%s', you might want to look over it's permissions", g.Name)
+		return im.state.Set("groups", g.ID, gid)
+	})
+}
+
+func (im *Importer) users() error {
+	var noHash int
+	err := im.src.Users(func(u *User) error {
+		if _, ok := im.state.Get("users", u.ID); ok {
+			return nil
+		}
+		gid, ok := im.state.Get("groups", u.Group)
+		if !u.Active {
+			gid = c.Config.ActivationGroup
+		} else if !ok {
+			gid = c.Config.DefaultGroup
+		}
+		// This password is thrown away, it's replaced with the old hash below or the user has to reset it
+		password, err := c.GenerateSafeString(32)
+		if err != nil {
+			return err
+		}
+
+		// Someone might have been registered with this name here already, so we tack a number on the end until we find a free one
+		name := truncate(u.Name, c.Config.MaxUsernameLength)
+		var uid int
+		for i := 2; ; i++ {
+			uid, err = c.Users.Create(name, password, c.CanonEmail(u.Email), gid, u.Active)
+			if err != c.ErrAccountExists {
+				break
+			}
+			suffix := strconv.Itoa(i)
+			name = truncate(u.Name, c.Config.MaxUsernameLength-len(suffix)) + suffix
+		}
+		if err != nil {
+			return errors.Wrap(err, "user "+strconv.Itoa(u.ID))
+		}
+		if name != u.Name {
+			log.Printf("The user '%s' has been renamed to '%s'", u.Name, name)
+		}
+
+		if c.HashAlgo(u.Hash) != "" {
+			err = c.SetPasswordHash(uid, u.Hash, u.Salt)
+			if err != nil {
+				return err
+			}
+		} else {
+			noHash++
+		}
+		if u.Email != "" {
+			err = c.Emails.Add(uid, u.Email, "")
+			if err != nil {
+				return err
+			}
+			if u.Active {
+				err = c.Emails.VerifyEmail(u.Email)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		_, err = im.setUserDates.Exec(u.CreatedAt, u.LastActiveAt, uid)
+		if err != nil {
+			return err
+		}
+		return im.state.Set("users", u.ID, uid)
+	})
+	if noHash > 0 {
+		log.Printf("The password hashes of %d users couldn't be brought over, they'll have to reset their passwords", noHash)
+	}
+	return err
+}
+
+func (im *Importer) forums() error {
+	return im.src.Forums(func(f *Forum) error {
+		if _, ok := im.state.Get("forums", f.ID); ok {
+			return nil
+		}
+		fid, err := c.Forums.Create(truncate(f.Name, 100), truncate(f.Desc, 200), f.Active, "all")
+		if err != nil {
+			return err
+		}
+		return im.state.Set("forums", f.ID, fid)
+	})
+}
+
+func (im *Importer) topics() error {
+	return im.src.Topics(func(t *Topic) error {
+		if _, ok := im.state.Get("topics", t.ID); ok {
+			return nil
+		}
+		fid, ok := im.state.Get("forums", t.Forum)
+		if !ok {
+			log.Printf("Skipping topic %d as it's forum wasn't imported", t.ID)
+			return nil
+		}
+		uid := im.user(t.CreatedBy)
+		content := c.PreparseMessage(t.Content)
+		tid, err := c.Topics.Create(fid, truncate(t.Title, c.Config.MaxTopicTitleLength), content, uid, t.IP)
+		if err == c.ErrNoTitle || err == c.ErrNoBody {
+			log.Printf("Skipping topic %d: %s", t.ID, err)
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "topic "+strconv.Itoa(t.ID))
+		}
+		err = im.postStats(uid, content, true)
+		if err != nil {
+			return err
+		}
+
+		topic, err := c.Topics.Get(tid)
+		if err != nil {
+			return err
+		}
+		if t.Closed {
+			err = topic.Lock()
+			if err != nil {
+				return err
+			}
+		}
+		if t.Sticky {
+			err = topic.Stick()
+			if err != nil {
+				return err
+			}
+		}
+		_, err = im.setTopicMeta.Exec(t.CreatedAt, t.CreatedAt, t.Views, tid)
+		if err != nil {
+			return err
+		}
+		return im.state.Set("topics", t.ID, tid)
+	})
+}
+
+func (im *Importer) replies() error {
+	return im.src.Replies(func(r *Reply) error {
+		if _, ok := im.state.Get("replies", r.ID); ok {
+			return nil
+		}
+		tid, ok := im.state.Get("topics", r.Topic)
+		if !ok {
+			log.Printf("Skipping reply %d as it's topic wasn't imported", r.ID)
+			return nil
+		}
+		topic, err := c.Topics.Get(tid)
+		if err != nil {
+			return err
+		}
+		uid := im.user(r.CreatedBy)
+		content := c.PreparseMessage(r.Content)
+		if content == "" {
+			log.Printf("Skipping reply %d as it's empty", r.ID)
+			return nil
+		}
+		rid, err := c.Rstore.Create(topic, content, r.IP, uid)
+		if err != nil {
+			return errors.Wrap(err, "reply "+strconv.Itoa(r.ID))
+		}
+		err = im.postStats(uid, content, false)
+		if err != nil {
+			return err
+		}
+		_, err = im.setReplyDate.Exec(r.CreatedAt, rid)
+		if err != nil {
+			return err
+		}
+		_, err = im.setTopicLastReply.Exec(r.CreatedAt, tid)
+		if err != nil {
+			return err
+		}
+		return im.state.Set("replies", r.ID, rid)
+	})
+}
+
+func (im *Importer) postStats(uid int, content string, topic bool) error {
+	u, err := c.Users.Get(uid)
+	if err != nil {
+		return err
+	}
+	return u.IncreasePostStats(c.WordCount(content), topic)
+}
+
+func (im *Importer) attachments() error {
+	return im.src.Attachments(func(a *Attachment) error {
+		if _, ok := im.state.Get("attachments", a.ID); ok {
+			return nil
+		}
+		tid, ok := im.state.Get("topics", a.Topic)
+		if !ok {
+			return nil
+		}
+		topic, err := c.Topics.Get(tid)
+		if err != nil {
+			return err
+		}
+		oid, otable, extra := tid, "topics", ""
+		if a.Reply != 0 {
+			rid, ok := im.state.Get("replies", a.Reply)
+			if !ok {
+				return nil
+			}
+			oid, otable, extra = rid, "replies", strconv.Itoa(tid)
+		}
+
+		filename, err := copyAttachment(a.Path, a.Name)
+		if err != nil {
+			log.Printf("Skipping attachment %d: %s", a.ID, err)
+			return nil
+		}
+		aid, err := c.Attachments.Add(topic.ParentID, "forums", oid, otable, im.user(a.UploadedBy), filename, extra)
+		if err != nil {
+			return err
+		}
+		err = c.Attachments.AddLinked(otable, oid)
+		if err != nil {
+			return err
+		}
+		return im.state.Set("attachments", a.ID, aid)
+	})
+}
+
+var extReg = regexp.MustCompile("[^A-Za-z0-9]+")
+
+// copyAttachment puts the file into the attachment folder under the name of it's hash, the same as an upload would be
+func copyAttachment(path, name string) (string, error) {
+	extarr := strings.Split(name, ".")
+	if len(extarr) < 2 {
+		return "", errors.New("the file doesn't have an extension")
+	}
+	ext := strings.ToLower(extReg.ReplaceAllString(extarr[len(extarr)-1], ""))
+	if !c.AllowedFileExts.Contains(ext) {
+		return "", errors.New("files with the extension '" + ext + "' aren't allowed")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, f)
+	if err != nil {
+		return "", err
+	}
+	filename := hex.EncodeToString(h.Sum(nil)) + "." + ext
+	dest := "./attachs/" + filename
+	if _, err := os.Stat(dest); err == nil {
+		return filename, nil
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", err
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, f)
+	if err != nil {
+		out.Close()
+		os.Remove(dest)
+		return "", err
+	}
+	return filename, out.Close()
+}
+
+// messages brings over the private messages as conversations, the state maps each message to the conversation it ended up in
+func (im *Importer) messages() error {
+	return im.src.Messages(func(m *Message) error {
+		if _, ok := im.state.Get("messages", m.ID); ok {
+			return nil
+		}
+		from := im.user(m.From)
+		body := c.PreparseMessage(m.Body)
+		if body == "" {
+			return nil
+		}
+
+		if cid, ok := im.state.Get("messages", m.Root); ok && m.Root != 0 {
+			post := &c.ConversationPost{CID: cid, Body: body, CreatedBy: from}
+			_, err := post.Create()
+			if err != nil {
+				return err
+			}
+			_, err = im.setConvoLastReply.Exec(from, m.CreatedAt, cid)
+			if err != nil {
+				return err
+			}
+			return im.state.Set("messages", m.ID, cid)
+		}
+
+		var to []int
+		for _, id := range m.To {
+			if uid, ok := im.state.Get("users", id); ok {
+				to = append(to, uid)
+			}
+		}
+		if len(to) == 0 {
+			log.Printf("Skipping message %d as none of it's recipients were imported", m.ID)
+			return nil
+		}
+		cid, err := c.Convos.Create(body, from, to)
+		if err != nil {
+			return err
+		}
+		_, err = im.setConvoDates.Exec(m.CreatedAt, m.CreatedAt, cid)
+		if err != nil {
+			return err
+		}
+		return im.state.Set("messages", m.ID, cid)
+	})
+}
+
+// truncate cuts s down to at most n bytes without splitting a character in two
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	if n <= 0 {
+		return ""
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
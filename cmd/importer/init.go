@@ -0,0 +1,133 @@
+package main
+
+import (
+	"log"
+
+	c "github.com/Azareal/Gosora/common"
+	p "github.com/Azareal/Gosora/common/phrases"
+	_ "github.com/Azareal/Gosora/extend"
+	qgen "github.com/Azareal/Gosora/query_gen"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+func prepDatabase() error {
+	switch c.DbConfig.Adapter {
+	case "mysql", "":
+		return qgen.Builder.Init("mysql", map[string]string{
+			"host":      c.DbConfig.Host,
+			"port":      c.DbConfig.Port,
+			"name":      c.DbConfig.Dbname,
+			"username":  c.DbConfig.Username,
+			"password":  c.DbConfig.Password,
+			"collation": "utf8mb4_general_ci",
+		})
+	case "pgsql":
+		err := qgen.Builder.Init("pgsql", map[string]string{
+			"host":     c.DbConfig.Host,
+			"port":     c.DbConfig.Port,
+			"name":     c.DbConfig.Dbname,
+			"username": c.DbConfig.Username,
+			"password": c.DbConfig.Password,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = qgen.Builder.GetConn().Exec(qgen.PgsqlLastvalFunc)
+		return err
+	case "sqlite":
+		// The driver is only built in with the sqlite build tag
+		return qgen.Builder.Init("sqlite", map[string]string{"name": c.DbConfig.Dbname})
+	}
+	return errors.New("The importer doesn't support the '" + c.DbConfig.Adapter + "' adapter")
+}
+
+// initGosora sets up the stores the import goes through, along with the things they lean on while parsing posts, such as the settings and the plugins
+func initGosora() (err error) {
+	log.Print("Connecting to the database")
+	err = prepDatabase()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = c.DbInits.Run()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Print("Initialising the stores")
+	c.TopicListThaw = c.NewSingleServerThaw()
+	c.Groups, err = c.NewMemoryGroupStore()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = c.Groups.LoadGroups()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// The caches are left out, as most of what we touch won't be touched again
+	c.Users, err = c.NewDefaultUserStore(nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Topics, err = c.NewDefaultTopicStore(nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Forums, err = c.NewMemoryForumStore()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = c.Forums.LoadForums()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.FPStore, err = c.NewMemoryForumPermsStore()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = c.FPStore.Init()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	err = c.LoadSettings()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	acc := qgen.NewAcc()
+	c.Rstore, err = c.NewSQLReplyStore(acc, nil)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Convos, err = c.NewDefaultConversationStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.GroupPromotions, err = c.NewDefaultGroupPromotionStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.WordFilters, err = c.NewDefaultWordFilterStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Attachments, err = c.NewDefaultAttachmentStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Emails, err = c.NewDefaultEmailStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err = p.InitPhrases(c.Site.Language); err != nil {
+		return errors.WithStack(err)
+	}
+	if err = c.InitEmoji(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	// The active plugins have a say in how posts are parsed, e.g. BBCode
+	log.Print("Loading the plugins")
+	return errors.WithStack(c.InitExtend())
+}
@@ -0,0 +1,96 @@
+/*
+*
+* Gosora Importer
+* Copyright Azareal 2020
+*
+ */
+// The importer brings the users, groups, forums, topics, replies, attachments and private messages over from other forum software.
+// It's run from the root of a Gosora installation, after the installer, and it goes through the usual stores, so the caches and counters stay correct.
+// What has been imported is written to a state file as it goes, so an import which was interrupted picks up where it left off when it's run again.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime/debug"
+
+	c "github.com/Azareal/Gosora/common"
+)
+
+// sources are the forum software we know how to import from
+var sources = map[string]func(opts *Options) (Source, error){
+	"phpbb3": NewPhpbbSource,
+}
+
+// Options are the flags handed to the sources
+type Options struct {
+	Dump   string // The SQL dump of the old database
+	Prefix string // The prefix on the tables of the old database
+	Files  string // The root folder of the old installation, this is where the attachments are copied from
+}
+
+func main() {
+	// Capture panics, so that we can see what went wrong in the log rather than just a stack trace
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Println(r)
+			debug.PrintStack()
+			log.Fatal("")
+		}
+	}()
+
+	var opts Options
+	source := flag.String("source", "phpbb3", "the software the data is coming from")
+	flag.StringVar(&opts.Dump, "dump", "", "the SQL dump of the old database, as made by mysqldump")
+	flag.StringVar(&opts.Prefix, "prefix", "phpbb_", "the prefix on the tables of the old database")
+	flag.StringVar(&opts.Files, "files", "", "the root folder of the old installation, attachments are skipped when this isn't given")
+	statePath := flag.String("state", "./import_state.log", "where the progress of the import is kept, so that it can be resumed")
+	orphan := flag.Int("orphan-uid", 1, "the user which is given the posts of users who weren't imported")
+	flag.Parse()
+
+	newSource, ok := sources[*source]
+	if !ok {
+		log.Fatal("We don't know how to import from '" + *source + "'")
+	}
+	if opts.Dump == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	log.Print("Loading the configuration data")
+	err := c.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Print("Processing configuration data")
+	err = c.ProcessConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = initGosora()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := newSource(&opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	state, err := OpenState(*statePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer state.Close()
+
+	im, err := NewImporter(src, state, *orphan)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = im.Run()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Print("The import has finished")
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"html"
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PhpbbSource imports from phpBB 3.0 through to 3.3
+type PhpbbSource struct {
+	dump   *sqlDump
+	prefix string
+	files  string
+
+	firstPosts map[int]bool // The posts which open a topic, they become the content of the topic rather than a reply
+}
+
+func NewPhpbbSource(opts *Options) (Source, error) {
+	dump, err := openDump(opts.Dump)
+	if err != nil {
+		return nil, err
+	}
+	return &PhpbbSource{dump: dump, prefix: opts.Prefix, files: opts.Files}, nil
+}
+
+func (s *PhpbbSource) table(name string) string {
+	return s.prefix + name
+}
+
+func unixTime(r dumpRow, col string) time.Time {
+	return time.Unix(int64(r.Int(col)), 0).UTC()
+}
+
+// visible checks whether a topic or post was approved and hasn't been soft deleted, phpBB 3.0 only has the approval flag
+func visible(r dumpRow, kind string) bool {
+	if v, ok := r[kind+"_visibility"]; ok {
+		return v == "1"
+	}
+	if v, ok := r[kind+"_approved"]; ok {
+		return v == "1"
+	}
+	return true
+}
+
+func (s *PhpbbSource) Groups(f func(g *Group) error) error {
+	return s.dump.Each(s.table("groups"), func(r dumpRow) error {
+		g := &Group{ID: r.Int("group_id"), Name: html.UnescapeString(r.Str("group_name"))}
+		// The special groups phpBB makes for itself, these have equivalents here
+		if r.Int("group_type") == 3 {
+			switch g.Name {
+			case "ADMINISTRATORS":
+				g.MapTo = 1
+			case "GLOBAL_MODERATORS":
+				g.MapTo = 2
+			default:
+				// The registered users end up in the default group and the guests and bots aren't imported
+				return nil
+			}
+		}
+		return f(g)
+	})
+}
+
+func (s *PhpbbSource) Users(f func(u *User) error) error {
+	return s.dump.Each(s.table("users"), func(r dumpRow) error {
+		userType := r.Int("user_type")
+		// The anonymous user and the bots
+		if userType == 2 {
+			return nil
+		}
+		lastActive := unixTime(r, "user_lastvisit")
+		if r.Int("user_lastvisit") == 0 {
+			lastActive = unixTime(r, "user_regdate")
+		}
+		return f(&User{
+			ID:           r.Int("user_id"),
+			Name:         html.UnescapeString(r.Str("username")),
+			Email:        r.Str("user_email"),
+			Hash:         r.Str("user_password"),
+			Group:        r.Int("group_id"),
+			Active:       userType != 1,
+			CreatedAt:    unixTime(r, "user_regdate"),
+			LastActiveAt: lastActive,
+		})
+	})
+}
+
+func (s *PhpbbSource) Forums(f func(fo *Forum) error) error {
+	// The forums are brought over in the order they're shown in, which is kept in a nested set
+	type sortForum struct {
+		left int
+		fo   *Forum
+	}
+	var forums []sortForum
+	err := s.dump.Each(s.table("forums"), func(r dumpRow) error {
+		// Categories and links don't have anything in them
+		if r.Int("forum_type") != 1 {
+			return nil
+		}
+		forums = append(forums, sortForum{r.Int("left_id"), &Forum{
+			ID:     r.Int("forum_id"),
+			Name:   html.UnescapeString(r.Str("forum_name")),
+			Desc:   phpbbText(r.Str("forum_desc"), r.Str("forum_desc_uid")),
+			Active: true,
+		}})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(forums, func(i, j int) bool {
+		return forums[i].left < forums[j].left
+	})
+	for _, sf := range forums {
+		err = f(sf.fo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadTopics goes through the topics and calls f on each one which should be imported
+func (s *PhpbbSource) loadTopics(f func(r dumpRow)) error {
+	return s.dump.Each(s.table("topics"), func(r dumpRow) error {
+		// Moved topics leave a shadow behind in the old forum
+		if r.Int("topic_status") == 2 || r.Int("topic_moved_id") != 0 || !visible(r, "topic") {
+			return nil
+		}
+		f(r)
+		return nil
+	})
+}
+
+func (s *PhpbbSource) loadFirstPosts() error {
+	if s.firstPosts != nil {
+		return nil
+	}
+	s.firstPosts = make(map[int]bool)
+	return s.loadTopics(func(r dumpRow) {
+		s.firstPosts[r.Int("topic_first_post_id")] = true
+	})
+}
+
+// Topics takes the details from the topics table and the content from their first posts, so it goes through the dump twice
+func (s *PhpbbSource) Topics(f func(t *Topic) error) error {
+	topics := make(map[int]*Topic)
+	s.firstPosts = make(map[int]bool)
+	err := s.loadTopics(func(r dumpRow) {
+		pid := r.Int("topic_first_post_id")
+		s.firstPosts[pid] = true
+		topics[pid] = &Topic{
+			ID:        r.Int("topic_id"),
+			Forum:     r.Int("forum_id"),
+			Title:     html.UnescapeString(r.Str("topic_title")),
+			CreatedBy: r.Int("topic_poster"),
+			CreatedAt: unixTime(r, "topic_time"),
+			Closed:    r.Int("topic_status") == 1,
+			Sticky:    r.Int("topic_type") > 0, // Announcements are treated as stickies
+			Views:     r.Int("topic_views"),
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.dump.Each(s.table("posts"), func(r dumpRow) error {
+		t, ok := topics[r.Int("post_id")]
+		if !ok {
+			return nil
+		}
+		t.Content = phpbbText(r.Str("post_text"), r.Str("bbcode_uid"))
+		t.IP = r.Str("poster_ip")
+		return f(t)
+	})
+}
+
+func (s *PhpbbSource) Replies(f func(r *Reply) error) error {
+	err := s.loadFirstPosts()
+	if err != nil {
+		return err
+	}
+	return s.dump.Each(s.table("posts"), func(r dumpRow) error {
+		if s.firstPosts[r.Int("post_id")] || !visible(r, "post") {
+			return nil
+		}
+		return f(&Reply{
+			ID:        r.Int("post_id"),
+			Topic:     r.Int("topic_id"),
+			Content:   phpbbText(r.Str("post_text"), r.Str("bbcode_uid")),
+			CreatedBy: r.Int("poster_id"),
+			CreatedAt: unixTime(r, "post_time"),
+			IP:        r.Str("poster_ip"),
+		})
+	})
+}
+
+func (s *PhpbbSource) Attachments(f func(a *Attachment) error) error {
+	if s.files == "" {
+		log.Print("Skipping the attachments as the folder of the old installation wasn't given")
+		return nil
+	}
+	err := s.loadFirstPosts()
+	if err != nil {
+		return err
+	}
+	return s.dump.Each(s.table("attachments"), func(r dumpRow) error {
+		// Conversations don't have attachments here
+		if r.Int("in_message") == 1 || r.Int("is_orphan") == 1 {
+			return nil
+		}
+		a := &Attachment{
+			ID:         r.Int("attach_id"),
+			Topic:      r.Int("topic_id"),
+			UploadedBy: r.Int("poster_id"),
+			Name:       r.Str("real_filename"),
+			Path:       filepath.Join(s.files, "files", filepath.Base(r.Str("physical_filename"))),
+		}
+		if pid := r.Int("post_msg_id"); !s.firstPosts[pid] {
+			a.Reply = pid
+		}
+		return f(a)
+	})
+}
+
+func (s *PhpbbSource) Messages(f func(m *Message) error) error {
+	return s.dump.Each(s.table("privmsgs"), func(r dumpRow) error {
+		m := &Message{
+			ID:        r.Int("msg_id"),
+			Root:      r.Int("root_level"),
+			From:      r.Int("author_id"),
+			Body:      phpbbText(r.Str("message_text"), r.Str("bbcode_uid")),
+			CreatedAt: unixTime(r, "message_time"),
+		}
+		// Conversations don't have subjects here, so it's put at the top of the first message
+		if m.Root == 0 {
+			if subject := html.UnescapeString(r.Str("message_subject")); subject != "" {
+				m.Body = subject + "\n\n" + m.Body
+			}
+		}
+		// The recipients look like u_2:u_3:g_5, messages sent to groups are only brought over for the users named in them
+		for _, list := range []string{r.Str("to_address"), r.Str("bcc_address")} {
+			for _, to := range strings.Split(list, ":") {
+				if !strings.HasPrefix(to, "u_") {
+					continue
+				}
+				uid, err := strconv.Atoi(to[2:])
+				if err == nil {
+					m.To = append(m.To, uid)
+				}
+			}
+		}
+		return f(m)
+	})
+}
+
+var (
+	phpbbSmilies  = regexp.MustCompile(`<!-- s(.*?) --><img [^>]*><!-- s.*? -->`)
+	phpbbLinks    = regexp.MustCompile(`<!-- [mwle] --><a [^>]*href="([^"]*)"[^>]*>.*?</a><!-- [mwle] -->`)
+	phpbbListEnds = regexp.MustCompile(`\[/\*:m\]|\[/list:[ou]\]`)
+	phpbbTags     = regexp.MustCompile(`<[^>]*>`)
+)
+
+// phpbbText turns a post as phpBB stores it back into the BBCode it was written with
+func phpbbText(text, uid string) string {
+	// phpBB 3.2 onwards keeps posts as XML with the original markup inside of it, so we only have to strip the tags
+	if strings.HasPrefix(text, "<r>") || strings.HasPrefix(text, "<t>") {
+		text = strings.Replace(text, "<br/>\n", "\n", -1)
+		text = strings.Replace(text, "<br/>", "\n", -1)
+		text = phpbbTags.ReplaceAllString(text, "")
+		return html.UnescapeString(text)
+	}
+
+	// Older versions tag the BBCode with a unique ID and turn smilies and links into HTML
+	if uid != "" {
+		text = strings.Replace(text, ":"+uid, "", -1)
+	}
+	text = phpbbListEnds.ReplaceAllStringFunc(text, func(end string) string {
+		if end == "[/*:m]" {
+			return ""
+		}
+		return "[/list]"
+	})
+	text = phpbbSmilies.ReplaceAllString(text, "$1")
+	text = phpbbLinks.ReplaceAllString(text, "$1")
+	return html.UnescapeString(text)
+}
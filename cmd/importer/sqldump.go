@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// sqlDump reads the rows out of a dump made by mysqldump, so that the old database doesn't have to be loaded into a server first.
+// It only understands as much SQL as mysqldump writes, which is CREATE TABLE for the names of the columns and INSERT for the rows.
+type sqlDump struct {
+	path    string
+	columns map[string][]string
+}
+
+// dumpRow maps the names of the columns to their values, NULLs come through as blank strings
+type dumpRow map[string]string
+
+func (r dumpRow) Str(col string) string {
+	return r[col]
+}
+
+func (r dumpRow) Int(col string) int {
+	i, _ := strconv.Atoi(r[col])
+	return i
+}
+
+func openDump(path string) (*sqlDump, error) {
+	_, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlDump{path: path, columns: make(map[string][]string)}, nil
+}
+
+// Each calls f on every row of table in the order they appear in the dump
+func (d *sqlDump) Each(table string, f func(r dumpRow) error) error {
+	file, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return readStatements(bufio.NewReaderSize(file, 1<<20), func(stmt string) error {
+		sc := &sqlScanner{s: stmt}
+		switch {
+		case sc.keyword("CREATE"):
+			if !sc.keyword("TABLE") {
+				return nil
+			}
+			if sc.keyword("IF") {
+				sc.keyword("NOT")
+				sc.keyword("EXISTS")
+			}
+			name := sc.ident()
+			d.columns[name] = createColumns(sc.rest())
+		case sc.keyword("INSERT"), sc.keyword("REPLACE"):
+			sc.keyword("IGNORE")
+			sc.keyword("INTO")
+			if sc.ident() != table {
+				return nil
+			}
+			cols := d.columns[table]
+			sc.skipSpace()
+			if sc.peek() == '(' {
+				sc.i++
+				cols = nil
+				for {
+					cols = append(cols, sc.ident())
+					sc.skipSpace()
+					if sc.peek() != ',' {
+						break
+					}
+					sc.i++
+				}
+				if !sc.expect(')') {
+					return errors.New("malformed column list for " + table)
+				}
+			}
+			if len(cols) == 0 {
+				return errors.New("the columns for " + table + " weren't found in the dump")
+			}
+			if !sc.keyword("VALUES") {
+				return errors.New("malformed insert for " + table)
+			}
+			return sc.tuples(cols, f)
+		}
+		return nil
+	})
+}
+
+// createColumns picks the names of the columns out of the body of a CREATE TABLE, mysqldump puts each of them on their own line
+func createColumns(body string) (cols []string) {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "`") {
+			sc := &sqlScanner{s: line}
+			cols = append(cols, sc.ident())
+		}
+	}
+	return cols
+}
+
+// readStatements splits the dump up into statements and calls f on each of them, the comments are dropped along the way
+func readStatements(br *bufio.Reader, f func(stmt string) error) error {
+	var buf []byte
+	var quote byte
+	var lineComment, blockComment bool
+	var prev byte
+	flush := func() error {
+		stmt := strings.TrimSpace(string(buf))
+		buf = buf[:0]
+		if stmt == "" {
+			return nil
+		}
+		return f(stmt)
+	}
+
+	for {
+		ch, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case lineComment:
+			if ch == '\n' {
+				lineComment = false
+				buf = append(buf, '\n')
+			}
+			continue
+		case blockComment:
+			if prev == '*' && ch == '/' {
+				blockComment = false
+			}
+			prev = ch
+			continue
+		case quote != 0:
+			buf = append(buf, ch)
+			if ch == '\\' && quote != '`' {
+				next, err := br.ReadByte()
+				if err != nil {
+					return err
+				}
+				buf = append(buf, next)
+			} else if ch == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch ch {
+		case '\'', '"', '`':
+			quote = ch
+		case '#':
+			lineComment = true
+			continue
+		case '-':
+			if next, _ := br.Peek(2); len(next) == 2 && next[0] == '-' && (next[1] == ' ' || next[1] == '\t' || next[1] == '\n' || next[1] == '\r') {
+				br.ReadByte()
+				lineComment = true
+				continue
+			}
+		case '/':
+			// The versioned comments mysqldump sprinkles about are only ever SET statements and table options, so they can go too
+			if next, _ := br.Peek(1); len(next) == 1 && next[0] == '*' {
+				br.ReadByte()
+				blockComment = true
+				prev = 0
+				continue
+			}
+		case ';':
+			err = flush()
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		buf = append(buf, ch)
+	}
+	return flush()
+}
+
+type sqlScanner struct {
+	s string
+	i int
+}
+
+func (sc *sqlScanner) skipSpace() {
+	for sc.i < len(sc.s) {
+		switch sc.s[sc.i] {
+		case ' ', '\t', '\n', '\r':
+			sc.i++
+		default:
+			return
+		}
+	}
+}
+
+func (sc *sqlScanner) peek() byte {
+	if sc.i >= len(sc.s) {
+		return 0
+	}
+	return sc.s[sc.i]
+}
+
+func (sc *sqlScanner) rest() string {
+	return sc.s[sc.i:]
+}
+
+func (sc *sqlScanner) expect(ch byte) bool {
+	sc.skipSpace()
+	if sc.peek() != ch {
+		return false
+	}
+	sc.i++
+	return true
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch == '$' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+// keyword consumes kw if it's the next word
+func (sc *sqlScanner) keyword(kw string) bool {
+	sc.skipSpace()
+	end := sc.i + len(kw)
+	if end > len(sc.s) || !strings.EqualFold(sc.s[sc.i:end], kw) {
+		return false
+	}
+	if end < len(sc.s) && isIdentChar(sc.s[end]) {
+		return false
+	}
+	sc.i = end
+	return true
+}
+
+// ident reads a name which might be wrapped in backticks, a database name in front of it is dropped
+func (sc *sqlScanner) ident() (name string) {
+	sc.skipSpace()
+	if sc.peek() == '`' {
+		end := strings.IndexByte(sc.s[sc.i+1:], '`')
+		if end == -1 {
+			name, sc.i = sc.s[sc.i+1:], len(sc.s)
+		} else {
+			name = sc.s[sc.i+1 : sc.i+1+end]
+			sc.i += end + 2
+		}
+	} else {
+		start := sc.i
+		for sc.i < len(sc.s) && isIdentChar(sc.s[sc.i]) {
+			sc.i++
+		}
+		name = sc.s[start:sc.i]
+	}
+	if sc.peek() == '.' {
+		sc.i++
+		return sc.ident()
+	}
+	return name
+}
+
+// tuples reads the rows out of a VALUES list
+func (sc *sqlScanner) tuples(cols []string, f func(r dumpRow) error) error {
+	for {
+		if !sc.expect('(') {
+			return errors.New("malformed row")
+		}
+		row := make(dumpRow, len(cols))
+		for n := 0; ; n++ {
+			val, err := sc.value()
+			if err != nil {
+				return err
+			}
+			if n < len(cols) {
+				row[cols[n]] = val
+			}
+			sc.skipSpace()
+			if sc.peek() == ',' {
+				sc.i++
+				continue
+			}
+			if !sc.expect(')') {
+				return errors.New("malformed row")
+			}
+			break
+		}
+		err := f(row)
+		if err != nil {
+			return err
+		}
+		if !sc.expect(',') {
+			return nil
+		}
+	}
+}
+
+// value reads a single literal
+func (sc *sqlScanner) value() (string, error) {
+	sc.skipSpace()
+	switch ch := sc.peek(); {
+	case ch == '\'' || ch == '"':
+		return sc.str()
+	case ch == '_':
+		// A character set introducer, such as _binary 'abc'
+		sc.ident()
+		sc.skipSpace()
+		return sc.str()
+	case (ch == 'x' || ch == 'X') && sc.i+1 < len(sc.s) && sc.s[sc.i+1] == '\'':
+		sc.i++
+		s, err := sc.str()
+		if err != nil {
+			return "", err
+		}
+		b, err := hex.DecodeString(s)
+		return string(b), err
+	case sc.keyword("NULL"):
+		return "", nil
+	}
+
+	start := sc.i
+	for sc.i < len(sc.s) && sc.s[sc.i] != ',' && sc.s[sc.i] != ')' {
+		sc.i++
+	}
+	val := strings.TrimSpace(sc.s[start:sc.i])
+	if strings.HasPrefix(val, "0x") {
+		b, err := hex.DecodeString(val[2:])
+		return string(b), err
+	}
+	return val, nil
+}
+
+// str reads a quoted string and undoes the escaping
+func (sc *sqlScanner) str() (string, error) {
+	quote := sc.peek()
+	sc.i++
+	var sb strings.Builder
+	for sc.i < len(sc.s) {
+		ch := sc.s[sc.i]
+		sc.i++
+		switch {
+		case ch == '\\' && sc.i < len(sc.s):
+			esc := sc.s[sc.i]
+			sc.i++
+			switch esc {
+			case '0':
+				sb.WriteByte(0)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'Z':
+				sb.WriteByte(26)
+			case '%', '_':
+				// These are only escaped in patterns, so the backslash stays
+				sb.WriteByte('\\')
+				sb.WriteByte(esc)
+			default:
+				sb.WriteByte(esc)
+			}
+		case ch == quote:
+			// Two quotes in a row are an escaped quote
+			if sc.peek() == quote {
+				sc.i++
+				sb.WriteByte(quote)
+				continue
+			}
+			return sb.String(), nil
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return "", errors.New("unterminated string")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// State is the journal of an import, every row which has been brought over is appended to it along with the ID it was given, and so is the end of each stage.
+// It's only ever appended to, so the most we can lose from an interruption is a row which was inserted before it could be written down.
+type State struct {
+	f    *os.File
+	ids  map[string]map[int]int
+	done map[string]bool
+}
+
+// OpenState reads in the journal at path, if there is one, and opens it for appending
+func OpenState(path string) (*State, error) {
+	s := &State{ids: make(map[string]map[int]int), done: make(map[string]bool)}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s.f = f
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0:
+			continue
+		case len(fields) == 2 && fields[0] == "done":
+			s.done[fields[1]] = true
+		case len(fields) == 3:
+			oldID, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", path, line, err)
+			}
+			newID, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %s", path, line, err)
+			}
+			s.set(fields[0], oldID, newID)
+		default:
+			// A line which was cut off by a crash, the row it was about will be imported again
+			continue
+		}
+	}
+	return s, scanner.Err()
+}
+
+func (s *State) set(kind string, oldID, newID int) {
+	m, ok := s.ids[kind]
+	if !ok {
+		m = make(map[int]int)
+		s.ids[kind] = m
+	}
+	m[oldID] = newID
+}
+
+// Get returns the ID the row of this kind with the old ID was given in Gosora
+func (s *State) Get(kind string, oldID int) (newID int, ok bool) {
+	newID, ok = s.ids[kind][oldID]
+	return newID, ok
+}
+
+// Set records that the row of this kind with the old ID is now newID
+func (s *State) Set(kind string, oldID, newID int) error {
+	s.set(kind, oldID, newID)
+	_, err := fmt.Fprintf(s.f, "%s %d %d\n", kind, oldID, newID)
+	return err
+}
+
+// Done reports whether every row of this kind has been imported
+func (s *State) Done(kind string) bool {
+	return s.done[kind]
+}
+
+func (s *State) MarkDone(kind string) error {
+	s.done[kind] = true
+	_, err := fmt.Fprintf(s.f, "done %s\n", kind)
+	if err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+func (s *State) Close() error {
+	return s.f.Close()
+}
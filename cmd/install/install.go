@@ -192,7 +192,7 @@ func handleDatabaseDetails() (adap install.InstallAdapter, ok bool) {
 	//var dbPort string
 
 	for {
-		fmt.Println("Which database adapter do you wish to use? mysql or mssql? Default: mysql")
+		fmt.Println("Which database adapter do you wish to use? mysql, pgsql, mssql or sqlite? Default: mysql")
 		if !scanner.Scan() {
 			return nil, false
 		}
@@ -208,6 +208,21 @@ func handleDatabaseDetails() (adap install.InstallAdapter, ok bool) {
 	}
 	fmt.Println("Set database adapter to " + dbAdapter)
 
+	// SQLite doesn't have a server to connect to, all it needs is somewhere to put the database file
+	if adap.Name() == "sqlite" {
+		fmt.Println("Where do you want to put the database file? Default: " + defaultDbname + ".db")
+		if !scanner.Scan() {
+			return nil, false
+		}
+		dbName = scanner.Text()
+		if dbName == "" {
+			dbName = defaultDbname + ".db"
+		}
+		fmt.Println("Set database file to " + dbName)
+		adap.SetConfig("", "", "", dbName, adap.DefaultPort())
+		return adap, true
+	}
+
 	fmt.Println("Database Host? Default: " + defaultHost)
 	if !scanner.Scan() {
 		return nil, false
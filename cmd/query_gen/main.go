@@ -10,6 +10,7 @@ import (
 	"strconv"
 
 	c "github.com/Azareal/Gosora/common"
+	"github.com/Azareal/Gosora/migrations"
 	qgen "github.com/Azareal/Gosora/query_gen"
 )
 
@@ -29,6 +30,7 @@ func main() {
 		log.Printf("Building the queries for the %s adapter", a.GetName())
 		qgen.Install.SetAdapterInstance(a)
 		qgen.Install.AddPlugins(NewPrimaryKeySpitter()) // TODO: Do we really need to fill the spitter for every adapter?
+		qgen.Install.AddPlugins(NewSchemaSpitter())
 
 		err := writeStatements(a)
 		if err != nil {
@@ -93,9 +95,16 @@ func seedTables(a qgen.Adapter) error {
 	qgen.Install.SimpleInsert("settings", "name, content, type", "'meta_desc','','html-attribute'")
 	qgen.Install.SimpleInsert("settings", "name, content, type", "'rapid_loading','1','bool'")
 	qgen.Install.SimpleInsert("settings", "name, content, type", "'google_site_verify','','html-attribute'")
+	qgen.Install.SimpleInsert("settings", "name, content, type", "'own_post_edit_window','0','int'")
+	qgen.Install.SimpleInsert("settings", "name, content, type", "'own_post_delete_window','0','int'")
 	qgen.Install.SimpleInsert("themes", "uname, default", "'cosora',1")
 	qgen.Install.SimpleInsert("emails", "email, uid, validated", "'admin@localhost',1,1") // ? - Use a different default email or let the admin input it during installation?
 
+	// Fresh installations are built with the latest schema, so every migration is already in effect
+	for _, m := range migrations.List() {
+		qgen.Install.SimpleInsert("schema_migrations", "version,name,checksum,appliedAt", strconv.Itoa(m.Version)+",'"+m.Name+"','"+m.Checksum()+"',UTC_TIMESTAMP()")
+	}
+
 	/*
 		The Permissions:
 
@@ -133,15 +142,22 @@ func seedTables(a qgen.Adapter) error {
 
 		Forum Permissions:
 		ViewTopic
+		ViewOwnTopic
 		LikeItem
 		CreateTopic
 		EditTopic
+		EditOwnTopic
 		DeleteTopic
+		DeleteOwnTopic
 		CreateReply
+		CreateReplyToOwn
 		EditReply
+		EditOwnReply
 		DeleteReply
+		DeleteOwnReply
 		PinTopic
 		CloseTopic
+		CloseOwnTopic
 		MoveTopic
 	*/
 
@@ -174,7 +190,7 @@ func seedTables(a qgen.Adapter) error {
 	perms = c.Perms{BanUsers: true, ActivateUsers: true, EditUser: true, EditUserEmail: false, EditUserGroup: true, ViewIPs: true, UploadFiles: true, UploadAvatars: true, UseConvos: true, UseConvosOnlyWithMod: true, CreateProfileReply: true, AutoEmbed: true, AutoLink: true, ViewTopic: true, LikeItem: true, CreateTopic: true, EditTopic: true, DeleteTopic: true, CreateReply: true, EditReply: true, DeleteReply: true, PinTopic: true, CloseTopic: true, MoveTopic: true}
 	addGroup("Moderator", perms, true, false, false, "Mod")
 
-	perms = c.Perms{UploadFiles: true, UploadAvatars: true, UseConvos: true, UseConvosOnlyWithMod: true, CreateProfileReply: true, AutoEmbed: true, AutoLink: true, ViewTopic: true, LikeItem: true, CreateTopic: true, CreateReply: true}
+	perms = c.Perms{UploadFiles: true, UploadAvatars: true, UseConvos: true, UseConvosOnlyWithMod: true, CreateProfileReply: true, AutoEmbed: true, AutoLink: true, ViewTopic: true, LikeItem: true, CreateTopic: true, EditOwnTopic: true, DeleteOwnTopic: true, CreateReply: true, EditOwnReply: true, DeleteOwnReply: true}
 	addGroup("Member", perms, false, false, false, "")
 
 	perms = c.Perms{ViewTopic: true}
@@ -208,7 +224,7 @@ func seedTables(a qgen.Adapter) error {
 
 	qgen.Install.SimpleInsert("forums_permissions", "gid, fid, permissions", `2,2,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"LikeItem":true,"EditTopic":true,"DeleteTopic":true,"EditReply":true,"DeleteReply":true,"PinTopic":true,"CloseTopic":true,"MoveTopic":true}'`)
 
-	qgen.Install.SimpleInsert("forums_permissions", "gid, fid, permissions", `3,2,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"LikeItem":true}'`)
+	qgen.Install.SimpleInsert("forums_permissions", "gid, fid, permissions", `3,2,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"LikeItem":true,"EditOwnTopic":true,"DeleteOwnTopic":true,"EditOwnReply":true,"DeleteOwnReply":true}'`)
 
 	qgen.Install.SimpleInsert("forums_permissions", "gid, fid, permissions", `4,2,'{"ViewTopic":true}'`)
 
@@ -1,7 +1,14 @@
 package main
 
-import "strings"
-import "github.com/Azareal/Gosora/query_gen"
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/Azareal/Gosora/query_gen"
+)
 
 type PrimaryKeySpitter struct {
 	keys map[string]string
@@ -43,3 +50,112 @@ var dbTablePrimaryKeys = map[string]string{
 	}
 	return writeFile("./gen_tables.go", out+"}\n")
 }
+
+type schemaTable struct {
+	name    string
+	serial  string
+	columns []string
+	deps    []string
+}
+
+// SchemaSpitter lists the tables and their columns in schema.json for the backup system, tables come after the ones they have foreign keys on, so they can be restored in order
+type SchemaSpitter struct {
+	tables []schemaTable
+}
+
+func NewSchemaSpitter() *SchemaSpitter {
+	return &SchemaSpitter{}
+}
+
+func (spit *SchemaSpitter) Hook(name string, args ...interface{}) error {
+	if name == "CreateTableStart" {
+		table := args[0].(*qgen.DBInstallTable)
+		// The plugins stick around for the adapters which come after the one they were added for
+		for _, st := range spit.tables {
+			if st.name == table.Name {
+				return nil
+			}
+		}
+		st := schemaTable{name: table.Name}
+		for _, col := range table.Columns {
+			st.columns = append(st.columns, col.Name)
+			if col.AutoIncrement {
+				st.serial = col.Name
+			}
+		}
+		for _, key := range table.Keys {
+			if key.Type == "foreign" && key.FTable != table.Name {
+				st.deps = append(st.deps, key.FTable)
+			}
+		}
+		spit.tables = append(spit.tables, st)
+	}
+	return nil
+}
+
+func (spit *SchemaSpitter) Write() error {
+	data, err := ioutil.ReadFile("./schema/schema.json")
+	if err != nil {
+		return err
+	}
+	var schema struct {
+		DBVersion          string
+		DynamicFileVersion string
+		MinGoVersion       string
+		MinVersion         string
+	}
+	err = json.Unmarshal(data, &schema)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]bool)
+	for _, table := range spit.tables {
+		known[table.name] = true
+	}
+	var ordered []schemaTable
+	done := make(map[string]bool)
+	for len(ordered) < len(spit.tables) {
+		before := len(ordered)
+		for _, table := range spit.tables {
+			if done[table.name] {
+				continue
+			}
+			ready := true
+			for _, dep := range table.deps {
+				if known[dep] && !done[dep] {
+					ready = false
+				}
+			}
+			if ready {
+				ordered = append(ordered, table)
+				done[table.name] = true
+			}
+		}
+		if len(ordered) == before {
+			return errors.New("the foreign keys between the tables go around in a circle")
+		}
+	}
+
+	q := strconv.Quote
+	out := "{\n\t\"DBVersion\":" + q(schema.DBVersion) + ",\n\t\"DynamicFileVersion\":" + q(schema.DynamicFileVersion) + ",\n\t\"MinGoVersion\":" + q(schema.MinGoVersion) + ",\n\t\"MinVersion\":" + q(schema.MinVersion) + ",\n\t\"Tables\":[\n"
+	for i, table := range ordered {
+		out += "\t\t{\"Name\":" + q(table.name)
+		if table.serial != "" {
+			out += ",\"Serial\":" + q(table.serial)
+		}
+		out += ",\"Columns\":["
+		for ii, col := range table.columns {
+			if ii != 0 {
+				out += ","
+			}
+			out += q(col)
+		}
+		out += "]}"
+		if i != len(ordered)-1 {
+			out += ","
+		}
+		out += "\n"
+	}
+	return writeFile("./schema/schema.json", out+"\t]\n}\n")
+}
@@ -78,6 +78,7 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 			{"profile_comments", "int", 0, false, false, "0"},
 			{"who_can_convo", "int", 0, false, false, "0"},
 			{"enable_embeds", "int", 0, false, false, "-1"},
+			ccol("lang", 50, "''"),
 			ccol("email", 200, "''"),
 			ccol("avatar", 100, "''"),
 			text("message"),
@@ -232,6 +233,18 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 		},
 	)
 
+	// Accounts which are waiting out the grace period before they're anonymised or purged at the request of their owners
+	createTable("users_self_deletes", "", "",
+		[]tC{
+			{"uid", "int", 0, false, false, ""},
+			ccol("mode", 20, ""), // anonymise or purge
+			createdAt("requestedAt"),
+		},
+		[]tblKey{
+			{"uid", "primary", "", false},
+		},
+	)
+
 	// TODO: Should we add a users prefix to this table to fit the "unofficial convention"?
 	// TODO: Add an autoincrement key?
 	createTable("emails", "", "",
@@ -279,6 +292,8 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 			ccol("preset", 100, "''"),
 			{"parentID", "int", 0, false, false, "0"},
 			ccol("parentType", 50, "''"),
+			bcol("questions", false),
+			bcol("category", false),
 			{"lastTopicID", "int", 0, false, false, "0"},
 			{"lastReplyerID", "int", 0, false, false, "0"},
 		},
@@ -300,6 +315,21 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 		},
 	)
 
+	createTable("forums_moderators", "", "",
+		[]tC{
+			{"fid", "int", 0, false, false, ""},
+			{"uid", "int", 0, false, false, ""},
+			bcol("pinTopic", false),
+			bcol("closeTopic", false),
+			bcol("moveTopic", false),
+			bcol("deletePosts", false),
+			bcol("editPosts", false),
+		},
+		[]tblKey{
+			{"fid,uid", "primary", "", false},
+		},
+	)
+
 	createTable("topics", mysqlPre, mysqlCol,
 		[]tC{
 			{"tid", "int", 0, false, true, ""},
@@ -333,6 +363,13 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 			ccol("css_class", 100, "''"),
 			{"poll", "int", 0, false, false, "0"},
 			ccol("data", 200, "''"),
+			{"prefix", "int", 0, false, false, "0"},
+			{"answer", "int", 0, false, false, "0"}, // The rid of the accepted answer, if this is a question
+			bcol("deleted", false),                  // Soft deleted topics sit in the trash until they're restored or purged
+			{"deletedAt", "datetime", 0, true, false, ""},
+			{"deletedBy", "int", 0, false, false, "0"},
+			{"lastEdit", "int", 0, false, false, "0"}, // A unix timestamp, zero if it has never been edited
+			{"lastEditBy", "int", 0, false, false, "0"},
 		},
 		[]tK{
 			{"tid", "primary", "", false},
@@ -358,6 +395,10 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 			{"words", "int", 0, false, false, "1"}, // ? - replies has a default of 1 and topics has 0? why?
 			ccol("actionType", 20, "''"),
 			{"poll", "int", 0, false, false, "0"},
+			{"votes", "int", 0, false, false, "0"},
+			bcol("deleted", false),
+			{"deletedAt", "datetime", 0, true, false, ""},
+			{"deletedBy", "int", 0, false, false, "0"},
 		},
 		[]tK{
 			{"rid", "primary", "", false},
@@ -384,11 +425,13 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 	createTable("revisions", mysqlPre, mysqlCol,
 		[]tC{
 			{"reviseID", "int", 0, false, true, ""},
+			ccol("title", 100, "''"), // Only topics have titles
 			text("content"),
 			{"contentID", "int", 0, false, false, ""},
 			ccol("contentType", 100, "replies"),
 			createdAt(),
-			// TODO: Add a createdBy column?
+			{"createdBy", "int", 0, false, false, "0"}, // The person who made this edit, or the author for the original
+			ccol("ip", 200, "''"),
 		},
 		[]tblKey{
 			{"reviseID", "primary", "", false},
@@ -474,7 +517,7 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 			{"pid", "int", 0, false, true, ""},
 			{"cid", "int", 0, false, false, ""},
 			{"createdBy", "int", 0, false, false, ""},
-			ccol("body", 50, ""),
+			text("body"),
 			ccol("post", 50, "''"),
 		},
 		[]tblKey{
@@ -511,6 +554,85 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 		}, nil,
 	)
 
+	createTable("profile_fields", mysqlPre, mysqlCol,
+		[]tC{
+			{"pfid", "int", 0, false, true, ""},
+			ccol("name", 100, ""),
+			ccol("type", 50, ""), // text, textarea, url, select, date, number
+			text("options"),      // newline separated list of choices for select fields
+			bcol("required", false),
+			{"maxLength", "int", 0, false, false, "0"},
+			text("allowedGroups"),                    // groups who can see the field, empty for everyone
+			{"privacy", "int", 0, false, false, "1"}, // 1 = public, 2 = registered, 3 = self
+			bcol("showOnPosts", false),
+			{"order", "int", 0, false, false, "0"},
+		},
+		[]tK{
+			{"pfid", "primary", "", false},
+		},
+	)
+
+	createTable("users_profile_fields", mysqlPre, mysqlCol,
+		[]tC{
+			{"uid", "int", 0, false, false, ""},  // TODO: Make this a foreign key
+			{"pfid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			text("value"),
+			{"privacy", "int", 0, false, false, "0"}, // 0 = field default
+		},
+		[]tK{
+			{"uid,pfid", "unique", "", false},
+		},
+	)
+
+	createTable("users_theme_settings", mysqlPre, mysqlCol,
+		[]tC{
+			{"uid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			ccol("theme", 50, ""),
+			ccol("name", 50, ""),
+			ccol("value", 50, ""),
+		},
+		[]tK{
+			{"uid,theme,name", "unique", "", false},
+		},
+	)
+
+	createTable("topic_prefixes", mysqlPre, mysqlCol,
+		[]tC{
+			{"prid", "int", 0, false, true, ""},
+			ccol("name", 100, ""),
+			ccol("cssClass", 100, "''"),
+			text("forums"),        // forums the prefix can be used in, empty for all of them
+			text("allowedGroups"), // groups who can use the prefix, empty for everyone
+			{"order", "int", 0, false, false, "0"},
+		},
+		[]tK{
+			{"prid", "primary", "", false},
+		},
+	)
+
+	createTable("topic_tags", mysqlPre, mysqlCol,
+		[]tC{
+			{"tid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			ccol("tag", 100, ""),
+		},
+		[]tK{
+			{"tid,tag", "unique", "", false},
+		},
+	)
+
+	createTable("reply_votes", "", "",
+		[]tC{
+			{"rid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			{"tid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			{"uid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			{"weight", "tinyint", 0, false, false, "1"},
+			createdAt(),
+		},
+		[]tK{
+			{"rid,uid", "unique", "", false},
+		},
+	)
+
 	createTable("activity_stream_matches", "", "",
 		[]tC{
 			{"watcher", "int", 0, false, false, ""}, // TODO: Make this a foreign key
@@ -797,18 +919,96 @@ func createTables2(a qgen.Adapter, f func(table, charset, collation string, colu
 		}, nil,
 	)
 
+	// TODO: Drop this, the cluster_events table has taken over
 	createTable("sync", "", "",
 		[]tC{
 			{"last_update", "datetime", 0, false, false, ""},
 		}, nil,
 	)
 
+	// The servers in a cluster tell each other what they've changed through this table, when the database driver is in use
+	createTable("cluster_events", "", "",
+		[]tC{
+			{"ceid", "int", 0, false, true, ""},
+			ccol("origin", 50, ""), // The server which published the event
+			ccol("type", 50, ""),
+			{"eid", "int", 0, false, false, "0"},
+			text("data"),
+			createdAt(),
+		},
+		[]tblKey{
+			{"ceid", "primary", "", false},
+		},
+	)
+
+	createTable("drafts", mysqlPre, mysqlCol,
+		[]tC{
+			{"draftID", "int", 0, false, true, ""},
+			{"uid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			ccol("contextType", 50, ""),         // forum, topic or convo
+			{"contextID", "int", 0, false, false, ""},
+			ccol("title", 100, "''"),
+			text("content"),
+			{"updatedAt", "datetime", 0, false, false, ""},
+		},
+		[]tK{
+			{"draftID", "primary", "", false},
+			{"uid,contextType,contextID", "unique", "", false},
+		},
+	)
+
+	createTable("topics_scheduled", mysqlPre, mysqlCol,
+		[]tC{
+			{"stid", "int", 0, false, true, ""},
+			{"parentID", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			ccol("title", 100, ""),
+			text("content"),
+			{"createdBy", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			ccol("ip", 200, "''"),
+			{"prefix", "int", 0, false, false, "0"},
+			text("tags"), // Comma separated
+			{"publishAt", "datetime", 0, false, false, ""},
+			createdAt(),
+		},
+		[]tK{
+			{"stid", "primary", "", false},
+		},
+	)
+
+	createTable("topics_timed_actions", mysqlPre, mysqlCol,
+		[]tC{
+			{"taid", "int", 0, false, true, ""},
+			{"tid", "int", 0, false, false, ""}, // TODO: Make this a foreign key
+			ccol("action", 50, ""),              // unpin or lock
+			{"days", "int", 0, false, false, ""},
+			bcol("inactive", false),
+			{"createdBy", "int", 0, false, false, ""},
+			createdAt(),
+		},
+		[]tK{
+			{"taid", "primary", "", false},
+			{"tid,action", "unique", "", false},
+		},
+	)
+
 	createTable("updates", "", "",
 		[]tC{
 			{"dbVersion", "int", 0, false, false, "0"},
 		}, nil,
 	)
 
+	createTable("schema_migrations", "", "",
+		[]tC{
+			{"version", "int", 0, false, false, ""},
+			ccol("name", 200, ""),
+			ccol("checksum", 100, ""),
+			{"appliedAt", "datetime", 0, false, false, ""},
+		},
+		[]tK{
+			{"version", "primary", "", false},
+		},
+	)
+
 	createTable("meta", "", "",
 		[]tC{
 			ccol("name", 200, ""),
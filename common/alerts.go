@@ -326,6 +326,8 @@ func AddActivityAndNotifyTarget(a Alert) error {
 			_ = WsHub.pushAlert(a.TargetUserID, a)
 			//fmt.Println("err:",err)
 		}()
+		// They might be connected to one of the other servers
+		ClusterPublish(ClusterWsAlert, id)
 	}
 	return nil
 }
@@ -344,6 +346,7 @@ func NotifyWatchers(asid int) error {
 	// Alert the subscribers about this without blocking us from doing something else
 	if EnableWebsockets {
 		go notifyWatchers(asid)
+		ClusterPublish(ClusterWsWatchers, asid)
 	}
 	return nil
 }
@@ -380,5 +383,9 @@ func notifyWatchers(asid int) {
 }
 
 func DismissAlert(uid, aid int) {
-	_ = WsHub.PushMessage(uid, `{"event":"dismiss-alert","id":`+strconv.Itoa(aid)+`}`)
+	msg := `{"event":"dismiss-alert","id":` + strconv.Itoa(aid) + `}`
+	_ = WsHub.PushMessage(uid, msg)
+	if EnableWebsockets {
+		ClusterPublishData(ClusterWsMessage, uid, msg)
+	}
 }
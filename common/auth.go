@@ -7,6 +7,7 @@
 package common
 
 import (
+	"crypto/md5"
 	"crypto/sha256"
 	"crypto/subtle"
 	"database/sql"
@@ -49,6 +50,7 @@ var DefaultHashAlgo = "bcrypt" // Override this in the configuration file, not h
 //func(realPassword string, password string, salt string) (err error)
 var CheckPasswordFuncs = map[string]func(string, string, string) error{
 	"bcrypt": BcryptCheckPassword,
+	"phpass": PhpassCheckPassword,
 	//"argon2": Argon2CheckPassword,
 }
 
@@ -58,9 +60,13 @@ var GeneratePasswordFuncs = map[string]func(string) (string, string, error){
 	//"argon2": Argon2GeneratePassword,
 }
 
-// TODO: Redirect 2b to bcrypt too?
+// The other prefixes are for hashes brought over from other software, they're replaced with the default algorithm when the user next logs in
 var HashPrefixes = map[string]string{
 	"$2a$": "bcrypt",
+	"$2b$": "bcrypt",
+	"$2y$": "bcrypt",
+	"$H$":  "phpass",
+	"$P$":  "phpass",
 	//"argon2$": "argon2",
 }
 
@@ -115,6 +121,12 @@ func (auth *DefaultAuth) Authenticate(name, password string) (uid int, err error
 		LogError(err)
 		return 0, ErrSecretError, false
 	}
+	if HashAlgo(realPassword) != DefaultHashAlgo {
+		err = SetPassword(uid, password)
+		if err != nil {
+			LogError(err)
+		}
+	}
 
 	_, err = MFAstore.Get(uid)
 	if err != sql.ErrNoRows && err != nil {
@@ -295,17 +307,21 @@ func (auth *DefaultAuth) CreateProvisionalSession(uid int) (provSession, signedS
 	return provSession, hex.EncodeToString(h.Sum(nil)), nil
 }
 
-func CheckPassword(realPassword, password, salt string) (err error) {
+// HashAlgo returns the name of the algorithm this hash was made with, or a blank string if we don't recognise it
+func HashAlgo(realPassword string) string {
 	blasted := strings.Split(realPassword, "$")
 	prefix := blasted[0]
 	if len(blasted) > 1 {
 		prefix += "$" + blasted[1] + "$"
 	}
-	algo, ok := HashPrefixes[prefix]
+	return HashPrefixes[prefix]
+}
+
+func CheckPassword(realPassword, password, salt string) (err error) {
+	checker, ok := CheckPasswordFuncs[HashAlgo(realPassword)]
 	if !ok {
 		return ErrHashNotExist
 	}
-	checker := CheckPasswordFuncs[algo]
 	return checker(realPassword, password, salt)
 }
 
@@ -330,6 +346,56 @@ func BcryptGeneratePassword(password string) (hash, salt string, err error) {
 	return string(hashedPassword), salt, nil
 }
 
+var phpassItoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// PhpassCheckPassword checks the portable hashes from phpass, which phpBB and WordPress use, the salt is a part of the hash
+func PhpassCheckPassword(realPassword, password, salt string) (err error) {
+	if len(realPassword) != 34 {
+		return ErrTooFewHashParams
+	}
+	countLog2 := strings.IndexByte(phpassItoa64, realPassword[3])
+	if countLog2 < 7 || countLog2 > 30 {
+		return ErrTooFewHashParams
+	}
+	sum := md5.Sum([]byte(realPassword[4:12] + password))
+	hash := sum[:]
+	for count := 1 << uint(countLog2); count > 0; count-- {
+		sum = md5.Sum(append(hash, password...))
+		hash = sum[:]
+	}
+	if subtle.ConstantTimeCompare([]byte(realPassword[12:]), phpassEncode64(hash)) != 1 {
+		return ErrMismatchedHashAndPassword
+	}
+	return nil
+}
+
+func phpassEncode64(in []byte) []byte {
+	out := make([]byte, 0, 22)
+	for i := 0; i < len(in); {
+		value := int(in[i])
+		i++
+		out = append(out, phpassItoa64[value&0x3f])
+		if i < len(in) {
+			value |= int(in[i]) << 8
+		}
+		out = append(out, phpassItoa64[(value>>6)&0x3f])
+		if i >= len(in) {
+			break
+		}
+		i++
+		if i < len(in) {
+			value |= int(in[i]) << 16
+		}
+		out = append(out, phpassItoa64[(value>>12)&0x3f])
+		if i >= len(in) {
+			break
+		}
+		i++
+		out = append(out, phpassItoa64[(value>>18)&0x3f])
+	}
+	return out
+}
+
 /*const (
 	argon2Time    uint32 = 3
 	argon2Memory  uint32 = 32 * 1024
@@ -0,0 +1,548 @@
+package common
+
+import (
+	"archive/zip"
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// BackupFormat is bumped whenever the layout of the backups changes in a way older versions of Gosora won't understand
+const BackupFormat = 1
+
+var ErrBackupBusy = errors.New("a backup is already being made or restored")
+var ErrBadBackup = errors.New("this isn't a backup made by Gosora or it was made by a newer version")
+
+// The folders with user content which are bundled in with the database
+var backupDirs = []string{"uploads", "attachs"}
+var backupBusy int32
+
+// SchemaTable is one of the tables listed in schema/schema.json, the query generator keeps this up to date
+type SchemaTable struct {
+	Name    string
+	Serial  string // The auto-incrementing column if there is one
+	Columns []string
+}
+
+type SchemaFile struct {
+	DBVersion          string
+	DynamicFileVersion string
+	MinGoVersion       string
+	MinVersion         string
+	Tables             []SchemaTable // Ordered so that tables come after the ones they have foreign keys on
+}
+
+func LoadSchemaFile() (*SchemaFile, error) {
+	data, err := ioutil.ReadFile("./schema/schema.json")
+	if err != nil {
+		return nil, err
+	}
+	s := &SchemaFile{}
+	return s, json.Unmarshal(data, s)
+}
+
+// BackupManifest describes the contents of a backup, it's stored in manifest.json in the archive.
+// The rows of each table are stored in data/{table}.jsonl, one JSON array per row with every value as either a string or null, so that they can be fed into any of the adapters.
+// The files from the uploads and attachment folders are stored under files/.
+type BackupManifest struct {
+	Format    int
+	Adapter   string
+	DBVersion string
+	CreatedAt time.Time
+	Tables    []BackupTable
+	Files     int
+}
+
+type BackupTable struct {
+	Name    string
+	Columns []string
+	Rows    int
+}
+
+// ListBackups returns the backups in ./backups with the newest first
+func ListBackups() ([]BackupItem, error) {
+	files, err := ioutil.ReadDir("./backups")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var list []BackupItem
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if ext != ".sql" && ext != ".zip" {
+			continue
+		}
+		list = append(list, BackupItem{f.Name(), f.ModTime(), ext == ".zip"})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Timestamp.After(list[j].Timestamp)
+	})
+	return list, nil
+}
+
+// backupValue turns whatever the driver hands us into the string form every database will accept on the way back in
+func backupValue(v interface{}) *string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		s = string(val)
+	case string:
+		s = val
+	case int64:
+		s = strconv.FormatInt(val, 10)
+	case float64:
+		s = strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		s = "0"
+		if val {
+			s = "1"
+		}
+	case time.Time:
+		s = val.UTC().Format("2006-01-02 15:04:05")
+	default:
+		s = ""
+	}
+	return &s
+}
+
+type backupQueryer interface {
+	Query(q string, args ...interface{}) (*sql.Rows, error)
+}
+
+// CreateBackup dumps every table in the schema file along with the uploads and attachments into a zip in ./backups and returns it's name
+func CreateBackup() (name string, err error) {
+	if !atomic.CompareAndSwapInt32(&backupBusy, 0, 1) {
+		return "", ErrBackupBusy
+	}
+	defer atomic.StoreInt32(&backupBusy, 0)
+
+	schema, err := LoadSchemaFile()
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll("./backups", 0755)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	name = "gosora_backup_" + now.Format("2006-01-02_150405") + ".zip"
+	// It's written under another name first, so a half-finished backup won't show up in the list or be pruned in place of a good one
+	tmpName := "./backups/" + name + ".tmp"
+	f, err := os.Create(tmpName)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(tmpName)
+		}
+	}()
+
+	adapter := qgen.Builder.GetAdapter()
+	m := &BackupManifest{Format: BackupFormat, Adapter: adapter.GetName(), DBVersion: schema.DBVersion, CreatedAt: now}
+	zw := zip.NewWriter(f)
+
+	// A transaction gives us a consistent snapshot, but SQLite takes the write lock when one is opened, which would hold up the rest of the forum
+	var qr backupQueryer = qgen.Builder.GetConn()
+	if adapter.GetName() != "sqlite" {
+		tx, err := qgen.Builder.GetConn().Begin()
+		if err != nil {
+			return "", err
+		}
+		defer tx.Rollback()
+		qr = tx
+	}
+
+	for _, table := range schema.Tables {
+		bt, err := backupTable(qr, adapter, zw, table)
+		if err != nil {
+			return "", err
+		}
+		m.Tables = append(m.Tables, bt)
+	}
+
+	for _, dir := range backupDirs {
+		err = filepath.Walk("./"+dir, func(fpath string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			header, err := zip.FileInfoHeader(info)
+			if err != nil {
+				return err
+			}
+			header.Name = "files/" + filepath.ToSlash(filepath.Clean(fpath))
+			header.Method = zip.Deflate
+			w, err := zw.CreateHeader(header)
+			if err != nil {
+				return err
+			}
+			src, err := os.Open(fpath)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+			_, err = io.Copy(w, src)
+			if err != nil {
+				return err
+			}
+			m.Files++
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	w, err := zw.Create("manifest.json")
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return "", err
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return "", err
+	}
+	err = zw.Close()
+	if err != nil {
+		return "", err
+	}
+	err = f.Close()
+	if err != nil {
+		return "", err
+	}
+	err = os.Rename(tmpName, "./backups/"+name)
+	return name, err
+}
+
+func backupTable(qr backupQueryer, adapter qgen.Adapter, zw *zip.Writer, table SchemaTable) (bt BackupTable, err error) {
+	bt = BackupTable{Name: table.Name, Columns: table.Columns}
+	q, err := adapter.SimpleSelect("", table.Name, strings.Join(table.Columns, ","), "", "", "")
+	if err != nil {
+		return bt, err
+	}
+	w, err := zw.Create("data/" + table.Name + ".jsonl")
+	if err != nil {
+		return bt, err
+	}
+	rows, err := qr.Query(q)
+	if err != nil {
+		return bt, err
+	}
+	defer rows.Close()
+
+	vals := make([]interface{}, len(table.Columns))
+	ptrs := make([]interface{}, len(table.Columns))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	row := make([]*string, len(table.Columns))
+	for rows.Next() {
+		err = rows.Scan(ptrs...)
+		if err != nil {
+			return bt, err
+		}
+		for i, v := range vals {
+			row[i] = backupValue(v)
+		}
+		data, err := json.Marshal(row)
+		if err != nil {
+			return bt, err
+		}
+		_, err = w.Write(append(data, '\n'))
+		if err != nil {
+			return bt, err
+		}
+		bt.Rows++
+	}
+	return bt, rows.Err()
+}
+
+// RestoreBackup replaces the contents of the tables in the backup and puts back the files in it, the backup can be from any of the adapters.
+// The schema has to already be in place, so a backup can be restored on a fresh installation.
+// Columns which no longer exist are skipped and the ones which have been added since the backup was made are left with their defaults.
+func RestoreBackup(fpath string) (*BackupManifest, error) {
+	if !atomic.CompareAndSwapInt32(&backupBusy, 0, 1) {
+		return nil, ErrBackupBusy
+	}
+	defer atomic.StoreInt32(&backupBusy, 0)
+
+	zr, err := zip.OpenReader(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	mf, ok := files["manifest.json"]
+	if !ok {
+		return nil, ErrBadBackup
+	}
+	m := &BackupManifest{}
+	err = readZipJSON(mf, m)
+	if err != nil {
+		return nil, err
+	}
+	if m.Format < 1 || m.Format > BackupFormat {
+		return nil, ErrBadBackup
+	}
+	schema, err := LoadSchemaFile()
+	if err != nil {
+		return nil, err
+	}
+	inBackup := make(map[string]BackupTable)
+	for _, bt := range m.Tables {
+		inBackup[bt.Name] = bt
+	}
+
+	adapter := qgen.Builder.GetAdapter()
+	tx, err := qgen.Builder.GetConn().Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Clear them out the other way around, so nothing is left pointing at a row which is gone
+	for i := len(schema.Tables) - 1; i >= 0; i-- {
+		table := schema.Tables[i]
+		if _, ok := inBackup[table.Name]; !ok {
+			continue
+		}
+		q, err := adapter.Purge("", table.Name)
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(q)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, table := range schema.Tables {
+		bt, ok := inBackup[table.Name]
+		if !ok {
+			continue
+		}
+		f, ok := files["data/"+table.Name+".jsonl"]
+		if !ok {
+			return nil, ErrBadBackup
+		}
+		err = restoreTable(tx, adapter, f, table, bt)
+		if err != nil {
+			return nil, errors.New("unable to restore " + table.Name + ": " + err.Error())
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range zr.File {
+		if !strings.HasPrefix(f.Name, "files/") || strings.HasSuffix(f.Name, "/") {
+			continue
+		}
+		err = restoreFile(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ReloadAfterRestore throws away everything which was loaded from the database before a restore and loads it again, the other servers in the cluster are told to do the same.
+// The plugins and themes which were switched on or off in the backup won't change until Gosora is restarted.
+func ReloadAfterRestore() error {
+	err := HandleClusterEvent(&ClusterEvent{Origin: "this server", Type: ClusterResync})
+	if err != nil {
+		return err
+	}
+	ClusterPublish(ClusterResync, 0)
+	return nil
+}
+
+func restoreTable(tx *sql.Tx, adapter qgen.Adapter, f *zip.File, table SchemaTable, bt BackupTable) error {
+	current := make(map[string]bool)
+	for _, col := range table.Columns {
+		current[strings.ToLower(col)] = true
+	}
+	var cols []string
+	var idx []int
+	for i, col := range bt.Columns {
+		if current[strings.ToLower(col)] {
+			cols = append(cols, col)
+			idx = append(idx, i)
+		}
+	}
+	if len(cols) == 0 {
+		return nil
+	}
+	q, err := adapter.SimpleInsert("", table.Name, strings.Join(cols, ","), strings.TrimSuffix(strings.Repeat("?,", len(cols)), ","))
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(q)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	br := bufio.NewReader(rc)
+	args := make([]interface{}, len(cols))
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			var row []*string
+			if err := json.Unmarshal(line, &row); err != nil {
+				return err
+			}
+			if len(row) != len(bt.Columns) {
+				return ErrBadBackup
+			}
+			for i, ri := range idx {
+				if row[ri] == nil {
+					args[i] = nil
+				} else {
+					args[i] = *row[ri]
+				}
+			}
+			if _, err := stmt.Exec(args...); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	// PostgreSQL doesn't move the sequences along when we insert the IDs ourselves
+	if table.Serial != "" && adapter.GetName() == "pgsql" {
+		col := strings.ToLower(table.Serial)
+		_, err = tx.Exec("SELECT setval(pg_get_serial_sequence('" + table.Name + "','" + col + "'), COALESCE(MAX(\"" + col + "\"), 0) + 1, false) FROM \"" + table.Name + "\"")
+	}
+	return err
+}
+
+func restoreFile(f *zip.File) error {
+	// Backups might come from anywhere, so make sure this one isn't trying to write outside of the folders it's meant to
+	name := path.Clean(strings.TrimPrefix(f.Name, "files/"))
+	var allowed bool
+	for _, dir := range backupDirs {
+		if strings.HasPrefix(name, dir+"/") {
+			allowed = true
+			break
+		}
+	}
+	if !allowed || strings.Contains(name, "..") {
+		return ErrBadBackup
+	}
+	dest := filepath.FromSlash("./" + name)
+	err := os.MkdirAll(filepath.Dir(dest), 0755)
+	if err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, rc)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func readZipJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return json.NewDecoder(rc).Decode(v)
+}
+
+// PruneBackups deletes all but the newest keep backups made by Gosora, anything else in the folder is left alone
+func PruneBackups(keep int) error {
+	if keep < 0 {
+		return nil
+	}
+	list, err := ListBackups()
+	if err != nil {
+		return err
+	}
+	var kept int
+	for _, item := range list {
+		if !item.Restorable || !strings.HasPrefix(item.SQLURL, "gosora_backup_") {
+			continue
+		}
+		kept++
+		if kept <= keep {
+			continue
+		}
+		err = os.Remove("./backups/" + item.SQLURL)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScheduledBackup is run daily, it makes a backup when the newest one is older than Config.BackupInterval days and prunes the old ones
+func ScheduledBackup() error {
+	if Config.BackupInterval < 1 {
+		return nil
+	}
+	list, err := ListBackups()
+	if err != nil {
+		return err
+	}
+	// Give it an hour of leeway, so a backup which took a while last time doesn't push it back to the next day
+	if len(list) > 0 && time.Since(list[0].Timestamp) < time.Duration(Config.BackupInterval)*24*time.Hour-time.Hour {
+		return nil
+	}
+	_, err = CreateBackup()
+	if err != nil {
+		return err
+	}
+	return PruneBackups(Config.BackupRetention)
+}
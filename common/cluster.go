@@ -0,0 +1,266 @@
+package common
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// Cluster lets the servers in a cluster know when something has changed, so they can drop it from their caches. It does nothing when Config.ServerCount is 1.
+var Cluster ClusterBus = NewNullClusterBus()
+
+// ClusterHandlers maps the event types to the functions which apply them to this server's caches
+var ClusterHandlers = make(map[string]func(ev *ClusterEvent) error)
+
+// The events which are built into Gosora, an ID of zero applies to all of the items of that type
+const (
+	ClusterUser        = "user"
+	ClusterTopic       = "topic"
+	ClusterReply       = "reply"
+	ClusterForum       = "forum"
+	ClusterForumDelete = "forum_delete"
+	ClusterForumPerms  = "forum_perms"
+	ClusterForumMods   = "forum_mods"
+	ClusterGroup       = "group"
+	ClusterSettings    = "settings"
+	ClusterWordFilters = "word_filters"
+	ClusterResync      = "resync" // This server may have missed some events, so everything has to be reloaded
+
+	ClusterWsAlert    = "ws_alert"    // ID is the activity stream item to push to the target user
+	ClusterWsWatchers = "ws_watchers" // ID is the activity stream item to push to everyone watching it
+	ClusterWsMessage  = "ws_msg"      // ID is the user to send the message in Data to
+)
+
+var ErrClusterSecret = errors.New("The cluster secret has to be set to use the tcp cluster driver")
+var ErrClusterDriver = errors.New("That isn't a valid cluster driver")
+
+// ClusterEvent is a change made on one of the servers in the cluster
+type ClusterEvent struct {
+	Origin string // The server which made the change, the drivers fill this in
+	Type   string
+	ID     int
+	Data   string
+}
+
+// ClusterBus passes events between the servers in a cluster
+type ClusterBus interface {
+	// Publish sends ev to the other servers, it may be held back for a moment to batch it with the others
+	Publish(ev ClusterEvent) error
+	// Tick is called every second to pick up the events from the other servers, for the drivers which have to poll for them
+	Tick() error
+	Close() error
+}
+
+// NewClusterBus picks the cluster driver in the config, no events are sent when there's only one server
+func NewClusterBus(acc *qgen.Accumulator) (ClusterBus, error) {
+	if Config.ServerCount == 1 {
+		return NewNullClusterBus(), nil
+	}
+	origin, err := GenerateSafeString(20)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Joining the cluster as %s with the %s driver", origin, Config.ClusterDriver)
+	switch Config.ClusterDriver {
+	case "", "db":
+		return NewDBClusterBus(acc, origin)
+	case "tcp":
+		if Config.ClusterSecret == "" {
+			return nil, ErrClusterSecret
+		}
+		return NewTCPClusterBus(Config.ClusterListen, Config.ClusterPeers, Config.ClusterSecret, origin)
+	}
+	return nil, ErrClusterDriver
+}
+
+// AddClusterHandler is not concurrency safe
+func AddClusterHandler(typ string, h func(ev *ClusterEvent) error) {
+	ClusterHandlers[typ] = h
+}
+
+// HandleClusterEvent applies an event from another server to this one, the drivers call this
+func HandleClusterEvent(ev *ClusterEvent) error {
+	h, ok := ClusterHandlers[ev.Type]
+	if !ok {
+		// It might be from a plugin or a newer version of Gosora on a server which hasn't been updated yet
+		DebugLogf("Unknown cluster event %s from %s", ev.Type, ev.Origin)
+		return nil
+	}
+	return h(ev)
+}
+
+// ClusterPublish tells the other servers that the item id of type typ has changed.
+// The change has already been made here by the time this is called, so the errors are logged rather than returned.
+func ClusterPublish(typ string, id int) {
+	ClusterPublishData(typ, id, "")
+}
+
+func ClusterPublishData(typ string, id int, data string) {
+	if err := Cluster.Publish(ClusterEvent{Type: typ, ID: id, Data: data}); err != nil {
+		LogError(err, "Unable to publish cluster event "+typ+" #"+strconv.Itoa(id))
+	}
+}
+
+// NullClusterBus is used when there's only one server, so there's no one to tell
+type NullClusterBus struct {
+}
+
+func NewNullClusterBus() *NullClusterBus {
+	return &NullClusterBus{}
+}
+
+// nolint
+func (b *NullClusterBus) Publish(_ ClusterEvent) error {
+	return nil
+}
+func (b *NullClusterBus) Tick() error {
+	return nil
+}
+func (b *NullClusterBus) Close() error {
+	return nil
+}
+
+func init() {
+	AddClusterHandler(ClusterUser, func(ev *ClusterEvent) error {
+		if uc := localUserCache(); uc != nil {
+			if ev.ID == 0 {
+				uc.Flush()
+			} else {
+				_ = uc.Remove(ev.ID)
+			}
+		}
+		if s, ok := UserThemeSettings.(*DefaultUserThemeSettingStore); ok {
+			if ev.ID == 0 {
+				s.flush()
+			} else {
+				s.evict(ev.ID)
+			}
+		}
+		TopicListThaw.Thaw()
+		return nil
+	})
+	AddClusterHandler(ClusterTopic, func(ev *ClusterEvent) error {
+		if tc := localTopicCache(); tc != nil {
+			if ev.ID == 0 {
+				tc.Flush()
+			} else {
+				_ = tc.Remove(ev.ID)
+			}
+		}
+		TopicListThaw.Thaw()
+		return nil
+	})
+	AddClusterHandler(ClusterReply, func(ev *ClusterEvent) error {
+		if rc := localReplyCache(); rc != nil {
+			if ev.ID == 0 {
+				rc.Flush()
+			} else {
+				_ = rc.Remove(ev.ID)
+			}
+		}
+		return nil
+	})
+	AddClusterHandler(ClusterForum, func(ev *ClusterEvent) error {
+		if ev.ID == 0 {
+			return Forums.LoadForums()
+		}
+		if s, ok := Forums.(*MemoryForumStore); ok {
+			return s.reload(ev.ID)
+		}
+		return Forums.Reload(ev.ID)
+	})
+	AddClusterHandler(ClusterForumDelete, func(ev *ClusterEvent) error {
+		if fc, ok := Forums.(ForumCache); ok {
+			fc.CacheDelete(ev.ID)
+			return nil
+		}
+		return Forums.LoadForums()
+	})
+	AddClusterHandler(ClusterForumPerms, func(ev *ClusterEvent) error {
+		s, ok := FPStore.(*MemoryForumPermsStore)
+		switch {
+		case ev.ID == 0 && ok:
+			return s.reloadAll()
+		case ev.ID == 0:
+			return FPStore.ReloadAll()
+		case ok:
+			return s.reload(ev.ID)
+		}
+		return FPStore.Reload(ev.ID)
+	})
+	AddClusterHandler(ClusterForumMods, func(ev *ClusterEvent) error {
+		return ForumMods.ReloadAll()
+	})
+	AddClusterHandler(ClusterGroup, func(ev *ClusterEvent) error {
+		if ev.ID == 0 {
+			// New groups need their forum permissions too
+			err := Groups.LoadGroups()
+			if err != nil {
+				return err
+			}
+			if s, ok := FPStore.(*MemoryForumPermsStore); ok {
+				return s.reloadAll()
+			}
+			return FPStore.ReloadAll()
+		}
+		if s, ok := Groups.(*MemoryGroupStore); ok {
+			return s.reload(ev.ID)
+		}
+		return Groups.Reload(ev.ID)
+	})
+	AddClusterHandler(ClusterSettings, func(ev *ClusterEvent) error {
+		return LoadSettings()
+	})
+	AddClusterHandler(ClusterWordFilters, func(ev *ClusterEvent) error {
+		return WordFilters.ReloadAll()
+	})
+	AddClusterHandler(ClusterResync, func(ev *ClusterEvent) error {
+		log.Print("Resynchronising with the cluster at the request of " + ev.Origin)
+		for _, typ := range []string{ClusterUser, ClusterTopic, ClusterReply, ClusterGroup, ClusterForum, ClusterForumPerms, ClusterForumMods, ClusterSettings, ClusterWordFilters} {
+			err := HandleClusterEvent(&ClusterEvent{Origin: ev.Origin, Type: typ})
+			if err != nil {
+				return err
+			}
+		}
+		// These don't have events of their own, but they might've changed too, e.g. if a backup was restored
+		if err := ProfileFields.ReloadAll(); err != nil {
+			return err
+		}
+		if err := TopicPrefixes.ReloadAll(); err != nil {
+			return err
+		}
+		if pc := Polls.GetCache(); pc != nil {
+			pc.Flush()
+		}
+		return ReloadWidgets()
+	})
+
+	AddClusterHandler(ClusterWsAlert, func(ev *ClusterEvent) error {
+		if !EnableWebsockets {
+			return nil
+		}
+		alert, err := Activity.Get(ev.ID)
+		if err != nil {
+			return err
+		}
+		if !WsHub.HasUser(alert.TargetUserID) {
+			return nil
+		}
+		_ = WsHub.pushAlert(alert.TargetUserID, alert)
+		return nil
+	})
+	AddClusterHandler(ClusterWsWatchers, func(ev *ClusterEvent) error {
+		if EnableWebsockets {
+			go notifyWatchers(ev.ID)
+		}
+		return nil
+	})
+	AddClusterHandler(ClusterWsMessage, func(ev *ClusterEvent) error {
+		if EnableWebsockets && WsHub.HasUser(ev.ID) {
+			_ = WsHub.PushMessage(ev.ID, ev.Data)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,107 @@
+package common
+
+// ClusterUserCache tells the other servers in the cluster to drop the users which are removed from this cache, as that only happens when they've changed
+type ClusterUserCache struct {
+	UserCache
+}
+
+// NewClusterUserCache wraps uc, so the removals are passed onto the rest of the cluster
+func NewClusterUserCache(uc UserCache) *ClusterUserCache {
+	return &ClusterUserCache{uc}
+}
+
+func (c *ClusterUserCache) Remove(id int) error {
+	err := c.UserCache.Remove(id)
+	ClusterPublish(ClusterUser, id)
+	return err
+}
+
+func (c *ClusterUserCache) RemoveUnsafe(id int) error {
+	err := c.UserCache.RemoveUnsafe(id)
+	ClusterPublish(ClusterUser, id)
+	return err
+}
+
+func (c *ClusterUserCache) Flush() {
+	c.UserCache.Flush()
+	ClusterPublish(ClusterUser, 0)
+}
+
+// ClusterTopicCache tells the other servers in the cluster to drop the topics which are removed from this cache
+type ClusterTopicCache struct {
+	TopicCache
+}
+
+// NewClusterTopicCache wraps tc, so the removals are passed onto the rest of the cluster
+func NewClusterTopicCache(tc TopicCache) *ClusterTopicCache {
+	return &ClusterTopicCache{tc}
+}
+
+func (c *ClusterTopicCache) Remove(id int) error {
+	err := c.TopicCache.Remove(id)
+	ClusterPublish(ClusterTopic, id)
+	return err
+}
+
+func (c *ClusterTopicCache) RemoveUnsafe(id int) error {
+	err := c.TopicCache.RemoveUnsafe(id)
+	ClusterPublish(ClusterTopic, id)
+	return err
+}
+
+func (c *ClusterTopicCache) Flush() {
+	c.TopicCache.Flush()
+	ClusterPublish(ClusterTopic, 0)
+}
+
+// ClusterReplyCache tells the other servers in the cluster to drop the replies which are removed from this cache
+type ClusterReplyCache struct {
+	ReplyCache
+}
+
+// NewClusterReplyCache wraps rc, so the removals are passed onto the rest of the cluster
+func NewClusterReplyCache(rc ReplyCache) *ClusterReplyCache {
+	return &ClusterReplyCache{rc}
+}
+
+func (c *ClusterReplyCache) Remove(id int) error {
+	err := c.ReplyCache.Remove(id)
+	ClusterPublish(ClusterReply, id)
+	return err
+}
+
+func (c *ClusterReplyCache) RemoveUnsafe(id int) error {
+	err := c.ReplyCache.RemoveUnsafe(id)
+	ClusterPublish(ClusterReply, id)
+	return err
+}
+
+func (c *ClusterReplyCache) Flush() {
+	c.ReplyCache.Flush()
+	ClusterPublish(ClusterReply, 0)
+}
+
+// The events from the other servers are applied to the caches underneath the cluster ones, otherwise they'd bounce back and forth forever
+func localUserCache() UserCache {
+	uc := Users.GetCache()
+	if cc, ok := uc.(*ClusterUserCache); ok {
+		return cc.UserCache
+	}
+	return uc
+}
+
+func localTopicCache() TopicCache {
+	tc := Topics.GetCache()
+	if cc, ok := tc.(*ClusterTopicCache); ok {
+		return cc.TopicCache
+	}
+	return tc
+}
+
+func localReplyCache() ReplyCache {
+	rc := Rstore.GetCache()
+	if cc, ok := rc.(*ClusterReplyCache); ok {
+		return cc.ReplyCache
+	}
+	return rc
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"database/sql"
+	"sync"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// DBClusterBus passes the events through the cluster_events table, which every server polls once a second.
+// It's slower than the other drivers, but there's nothing to set up, as the servers already share a database.
+type DBClusterBus struct {
+	origin string
+	lastID int // Only touched by Tick
+
+	pending   []ClusterEvent
+	pendingAt map[ClusterEvent]bool
+	sync.Mutex
+
+	insert   *sql.Stmt
+	getAfter *sql.Stmt
+	getLast  *sql.Stmt
+	prune    *sql.Stmt
+}
+
+// NewDBClusterBus picks up from the newest event in the table, as this server's caches start out empty anyway
+func NewDBClusterBus(acc *qgen.Accumulator, origin string) (*DBClusterBus, error) {
+	ce := "cluster_events"
+	b := &DBClusterBus{
+		origin:    origin,
+		pendingAt: make(map[ClusterEvent]bool),
+
+		insert:   acc.Insert(ce).Columns("origin,type,eid,data,createdAt").Fields("?,?,?,?,UTC_TIMESTAMP()").Prepare(),
+		getAfter: acc.Select(ce).Columns("ceid,origin,type,eid,data").Where("ceid>?").Orderby("ceid ASC").Prepare(),
+		getLast:  acc.Select(ce).Columns("ceid").Orderby("ceid DESC").Limit("1").Prepare(),
+		// The events are only needed until the other servers have had a chance to poll for them
+		prune: acc.Delete(ce).DateOlderThan("createdAt", 1, "hour").Prepare(),
+	}
+	if err := acc.FirstError(); err != nil {
+		return nil, err
+	}
+	err := b.getLast.QueryRow().Scan(&b.lastID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	AddScheduledHourTask(b.Prune)
+	return b, nil
+}
+
+// Publish queues ev up until the next tick, an item which changes several times in a second only needs to be sent once
+func (b *DBClusterBus) Publish(ev ClusterEvent) error {
+	ev.Origin = b.origin
+	b.Lock()
+	if !b.pendingAt[ev] {
+		b.pending = append(b.pending, ev)
+		b.pendingAt[ev] = true
+	}
+	b.Unlock()
+	return nil
+}
+
+func (b *DBClusterBus) flush() error {
+	b.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.pendingAt = make(map[ClusterEvent]bool)
+	b.Unlock()
+	for _, ev := range pending {
+		_, err := b.insert.Exec(ev.Origin, ev.Type, ev.ID, ev.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tick sends the events which have been queued up, then applies the ones the other servers have sent since the last one
+// TODO: An insert which commits after one with a higher ID has been read will be missed, we might want to look back a little further
+func (b *DBClusterBus) Tick() error {
+	err := b.flush()
+	if err != nil {
+		return err
+	}
+
+	rows, err := b.getAfter.Query(b.lastID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var evs []*ClusterEvent
+	for rows.Next() {
+		ev := &ClusterEvent{}
+		err := rows.Scan(&b.lastID, &ev.Origin, &ev.Type, &ev.ID, &ev.Data)
+		if err != nil {
+			return err
+		}
+		if ev.Origin != b.origin {
+			evs = append(evs, ev)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	// Keep going when an event fails, so one bad one doesn't hold up the others
+	var ferr error
+	for _, ev := range evs {
+		if err := HandleClusterEvent(ev); err != nil && ferr == nil {
+			ferr = err
+		}
+	}
+	return ferr
+}
+
+// Prune deletes the events which are over an hour old
+func (b *DBClusterBus) Prune() error {
+	_, err := b.prune.Exec()
+	return err
+}
+
+// Close sends anything which is still queued up, so the other servers don't miss the last few changes
+func (b *DBClusterBus) Close() error {
+	return b.flush()
+}
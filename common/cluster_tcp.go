@@ -0,0 +1,242 @@
+package common
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrClusterSignature = errors.New("The cluster message doesn't have a valid signature")
+var ErrClusterStale = errors.New("The cluster message is too old, or the clocks on the servers are out of sync")
+
+// The most messages which can be waiting to be sent to a peer, any more than this and it'll be told to resync instead
+const tcpClusterQueueSize = 1000
+
+// The websocket messages are the only large ones and they're nowhere near this
+const tcpClusterMaxMessage = 1024 * 1024
+
+// How far apart the clocks on the servers can be, the messages are rejected outside of this to make it harder to replay them
+const tcpClusterMaxSkew = 30 * time.Second
+
+// TCPClusterBus sends the events straight to the other servers, this is faster than the database driver, but each server has to be listed in ClusterPeers.
+// The messages are signed with ClusterSecret, but they aren't encrypted, so keep them on a private network.
+type TCPClusterBus struct {
+	origin   string
+	secret   []byte
+	listener net.Listener
+	peers    []*tcpClusterPeer
+
+	closed bool
+	sync.RWMutex
+}
+
+type tcpClusterPeer struct {
+	addr  string
+	queue chan []byte
+	// dropped is set when a message couldn't be sent, so the peer has to be told to resync when it can be reached again
+	dropped int32
+}
+
+type tcpClusterMessage struct {
+	Time  int64
+	Event ClusterEvent
+}
+
+// NewTCPClusterBus listens for the other servers on listen and sends the events from this one to the peers, which are host:port pairs
+func NewTCPClusterBus(listen string, peers []string, secret, origin string) (*TCPClusterBus, error) {
+	if secret == "" {
+		return nil, ErrClusterSecret
+	}
+	b := &TCPClusterBus{origin: origin, secret: []byte(secret)}
+	if listen != "" {
+		l, err := net.Listen("tcp", listen)
+		if err != nil {
+			return nil, err
+		}
+		b.listener = l
+		go b.accept()
+	}
+	for _, addr := range peers {
+		p := &tcpClusterPeer{addr: addr, queue: make(chan []byte, tcpClusterQueueSize)}
+		b.peers = append(b.peers, p)
+		go b.send(p)
+	}
+	return b, nil
+}
+
+// Addr returns the address this server is listening for the others on
+func (b *TCPClusterBus) Addr() string {
+	if b.listener == nil {
+		return ""
+	}
+	return b.listener.Addr().String()
+}
+
+func (b *TCPClusterBus) Publish(ev ClusterEvent) error {
+	ev.Origin = b.origin
+	msg, err := b.sign(ev)
+	if err != nil {
+		return err
+	}
+	b.RLock()
+	defer b.RUnlock()
+	if b.closed {
+		return nil
+	}
+	for _, p := range b.peers {
+		select {
+		case p.queue <- msg:
+		default:
+			atomic.StoreInt32(&p.dropped, 1)
+		}
+	}
+	return nil
+}
+
+// Tick does nothing, as the events are pushed to us as they happen
+func (b *TCPClusterBus) Tick() error {
+	return nil
+}
+
+func (b *TCPClusterBus) Close() error {
+	b.Lock()
+	defer b.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for _, p := range b.peers {
+		close(p.queue)
+	}
+	if b.listener != nil {
+		return b.listener.Close()
+	}
+	return nil
+}
+
+func (b *TCPClusterBus) sign(ev ClusterEvent) ([]byte, error) {
+	body, err := json.Marshal(tcpClusterMessage{time.Now().Unix(), ev})
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, b.secret)
+	mac.Write(body)
+	sig := hex.EncodeToString(mac.Sum(nil))
+	return []byte(sig + " " + string(body) + "\n"), nil
+}
+
+func (b *TCPClusterBus) verify(line []byte) (*ClusterEvent, error) {
+	i := bytes.IndexByte(line, ' ')
+	if i == -1 {
+		return nil, ErrClusterSignature
+	}
+	sig, err := hex.DecodeString(string(line[:i]))
+	if err != nil {
+		return nil, ErrClusterSignature
+	}
+	body := line[i+1:]
+	mac := hmac.New(sha256.New, b.secret)
+	mac.Write(body)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, ErrClusterSignature
+	}
+
+	var msg tcpClusterMessage
+	err = json.Unmarshal(body, &msg)
+	if err != nil {
+		return nil, err
+	}
+	skew := time.Since(time.Unix(msg.Time, 0))
+	if skew > tcpClusterMaxSkew || skew < -tcpClusterMaxSkew {
+		return nil, ErrClusterStale
+	}
+	return &msg.Event, nil
+}
+
+// send holds a connection open to p, dialing it again whenever it drops
+func (b *TCPClusterBus) send(p *tcpClusterPeer) {
+	var conn net.Conn
+	var retryAt time.Time
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+	for msg := range p.queue {
+		if conn == nil {
+			// Don't hammer a peer which is down, the messages are dropped until it's back, then it's told to resync
+			if time.Now().Before(retryAt) {
+				atomic.StoreInt32(&p.dropped, 1)
+				continue
+			}
+			c, err := net.DialTimeout("tcp", p.addr, 5*time.Second)
+			if err != nil {
+				DebugLogf("Unable to reach cluster peer %s: %s", p.addr, err)
+				retryAt = time.Now().Add(time.Second)
+				atomic.StoreInt32(&p.dropped, 1)
+				continue
+			}
+			conn = c
+			if atomic.SwapInt32(&p.dropped, 0) == 1 {
+				resync, err := b.sign(ClusterEvent{Origin: b.origin, Type: ClusterResync})
+				if err != nil {
+					LogError(err)
+				}
+				msg = append(resync, msg...)
+			}
+		}
+		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write(msg); err != nil {
+			DebugLogf("Lost the connection to cluster peer %s: %s", p.addr, err)
+			conn.Close()
+			conn = nil
+			atomic.StoreInt32(&p.dropped, 1)
+		}
+	}
+}
+
+func (b *TCPClusterBus) accept() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			b.RLock()
+			closed := b.closed
+			b.RUnlock()
+			if closed {
+				return
+			}
+			LogError(err)
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+		go b.receive(conn)
+	}
+}
+
+func (b *TCPClusterBus) receive(conn net.Conn) {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), tcpClusterMaxMessage)
+	for scanner.Scan() {
+		ev, err := b.verify(scanner.Bytes())
+		if err != nil {
+			// Whoever is on the other end isn't one of ours, so there's no point in listening to anything else they have to say
+			LogWarning(err, "Cluster message from "+conn.RemoteAddr().String())
+			return
+		}
+		if ev.Origin == b.origin {
+			continue
+		}
+		if err := HandleClusterEvent(ev); err != nil {
+			LogError(err)
+		}
+	}
+}
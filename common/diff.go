@@ -0,0 +1,109 @@
+package common
+
+import "unicode"
+
+// DiffChunk is a run of text which is in both versions of a post, or only in one of them
+type DiffChunk struct {
+	Text   string
+	Insert bool // Only in the newer version
+	Delete bool // Only in the older version
+}
+
+// Posts which would need more cells than this to compare are shown as having been replaced wholesale, rather than eating up a ton of memory
+const maxDiffCells = 4000000
+
+// Diff compares two versions of a post word by word, the whitespace between the words is kept, so the chunks can be joined back into either version
+func Diff(old, new string) (chunks []DiffChunk) {
+	a, b := diffTokens(old), diffTokens(new)
+
+	// The start and end of a post are usually left alone, so there's no point in running them through the table
+	var pre int
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	var suf int
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	add := func(text string, ins, del bool) {
+		if text == "" {
+			return
+		}
+		if l := len(chunks) - 1; l >= 0 && chunks[l].Insert == ins && chunks[l].Delete == del {
+			chunks[l].Text += text
+			return
+		}
+		chunks = append(chunks, DiffChunk{text, ins, del})
+	}
+	for _, tok := range a[:pre] {
+		add(tok, false, false)
+	}
+
+	ma, mb := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	n, m := len(ma), len(mb)
+	if n*m > maxDiffCells {
+		for _, tok := range ma {
+			add(tok, false, true)
+		}
+		for _, tok := range mb {
+			add(tok, true, false)
+		}
+	} else {
+		// lcs[i*(m+1)+j] is the length of the longest common subsequence of ma[i:] and mb[j:]
+		lcs := make([]int32, (n+1)*(m+1))
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+				} else if lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1] {
+					lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j]
+				} else {
+					lcs[i*(m+1)+j] = lcs[i*(m+1)+j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < n && j < m {
+			switch {
+			case ma[i] == mb[j]:
+				add(ma[i], false, false)
+				i++
+				j++
+			case lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1]:
+				add(ma[i], false, true)
+				i++
+			default:
+				add(mb[j], true, false)
+				j++
+			}
+		}
+		for ; i < n; i++ {
+			add(ma[i], false, true)
+		}
+		for ; j < m; j++ {
+			add(mb[j], true, false)
+		}
+	}
+
+	for _, tok := range a[len(a)-suf:] {
+		add(tok, false, false)
+	}
+	return chunks
+}
+
+// diffTokens splits s up into words and the runs of whitespace between them
+func diffTokens(s string) (toks []string) {
+	start, space := 0, false
+	for i, ch := range s {
+		isSpace := unicode.IsSpace(ch)
+		if i > start && isSpace != space {
+			toks = append(toks, s[start:i])
+			start = i
+		}
+		space = isSpace
+	}
+	if start < len(s) {
+		toks = append(toks, s[start:])
+	}
+	return toks
+}
@@ -0,0 +1,154 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var Drafts DraftStore
+
+var ErrBadDraftType = errors.New("That isn't something you can save a draft of")
+
+// Draft is a post someone hasn't finished yet, there's one for each place someone can write something, e.g. a new topic in a forum or a reply to a topic
+type Draft struct {
+	ID          int
+	UID         int
+	ContextType string // forum for new topics, topic for replies or convo for replies to conversations
+	ContextID   int
+	Title       string // Only new topics have titles
+	Content     string
+	UpdatedAt   time.Time
+}
+
+// Link is where the draft can be picked up again
+func (d *Draft) Link() string {
+	switch d.ContextType {
+	case "forum":
+		return "/topics/create/" + strconv.Itoa(d.ContextID)
+	case "topic":
+		return BuildTopicURL("", d.ContextID)
+	}
+	return "/user/convo/" + strconv.Itoa(d.ContextID)
+}
+
+// ValidDraftType tells you whether typ is one of the places a draft can be saved for
+func ValidDraftType(typ string) bool {
+	return typ == "forum" || typ == "topic" || typ == "convo"
+}
+
+// DraftStore holds the unfinished posts of each user, they're saved as they type, so they aren't lost if something goes wrong before they submit them
+type DraftStore interface {
+	Get(uid int, typ string, id int) (*Draft, error)
+	GetByUser(uid, offset, perPage int) ([]*Draft, error)
+	CountUser(uid int) int
+	Save(uid int, typ string, id int, title, content string) error
+	Delete(uid int, typ string, id int) error
+	DeleteByID(uid, did int) error
+	DeleteAll(uid int) error
+	Prune(days int) error
+}
+
+type DefaultDraftStore struct {
+	get        *sql.Stmt
+	getByUser  *sql.Stmt
+	countUser  *sql.Stmt
+	insert     *sql.Stmt
+	update     *sql.Stmt
+	delete     *sql.Stmt
+	deleteByID *sql.Stmt
+	deleteAll  *sql.Stmt
+	prune      *sql.Stmt
+}
+
+func NewDefaultDraftStore(acc *qgen.Accumulator) (*DefaultDraftStore, error) {
+	dr := "drafts"
+	return &DefaultDraftStore{
+		get:        acc.Select(dr).Columns("draftID,title,content,updatedAt").Where("uid=? AND contextType=? AND contextID=?").Prepare(),
+		getByUser:  acc.Select(dr).Columns("draftID,contextType,contextID,title,content,updatedAt").Where("uid=?").Orderby("updatedAt DESC").Limit("?,?").Prepare(),
+		countUser:  acc.Count(dr).Where("uid=?").Prepare(),
+		insert:     acc.Insert(dr).Columns("uid,contextType,contextID,title,content,updatedAt").Fields("?,?,?,?,?,UTC_TIMESTAMP()").Prepare(),
+		update:     acc.Update(dr).Set("title=?,content=?,updatedAt=UTC_TIMESTAMP()").Where("uid=? AND contextType=? AND contextID=?").Prepare(),
+		delete:     acc.Delete(dr).Where("uid=? AND contextType=? AND contextID=?").Prepare(),
+		deleteByID: acc.Delete(dr).Where("uid=? AND draftID=?").Prepare(),
+		deleteAll:  acc.Delete(dr).Where("uid=?").Prepare(),
+		prune:      acc.Delete(dr).DateOlderThanQ("updatedAt", "day").Prepare(),
+	}, acc.FirstError()
+}
+
+func (s *DefaultDraftStore) Get(uid int, typ string, id int) (*Draft, error) {
+	d := &Draft{UID: uid, ContextType: typ, ContextID: id}
+	err := s.get.QueryRow(uid, typ, id).Scan(&d.ID, &d.Title, &d.Content, &d.UpdatedAt)
+	return d, err
+}
+
+// GetByUser returns the drafts uid has saved, the most recently updated ones come first
+func (s *DefaultDraftStore) GetByUser(uid, offset, perPage int) (list []*Draft, err error) {
+	rows, err := s.getByUser.Query(uid, offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		d := &Draft{UID: uid}
+		err := rows.Scan(&d.ID, &d.ContextType, &d.ContextID, &d.Title, &d.Content, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (s *DefaultDraftStore) CountUser(uid int) int {
+	return Countf(s.countUser, uid)
+}
+
+// Save creates or overwrites the draft uid has for a place, an empty draft is deleted instead as there's nothing worth keeping
+func (s *DefaultDraftStore) Save(uid int, typ string, id int, title, content string) error {
+	if !ValidDraftType(typ) {
+		return ErrBadDraftType
+	}
+	if title == "" && content == "" {
+		return s.Delete(uid, typ, id)
+	}
+	res, err := s.update.Exec(title, content, uid, typ, id)
+	if err != nil {
+		return err
+	}
+	// A draft which hasn't changed might not count as affected, so check it's there before adding another
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	_, err = s.Get(uid, typ, id)
+	if err != ErrNoRows {
+		return err
+	}
+	_, err = s.insert.Exec(uid, typ, id, title, content)
+	return err
+}
+
+func (s *DefaultDraftStore) Delete(uid int, typ string, id int) error {
+	_, err := s.delete.Exec(uid, typ, id)
+	return err
+}
+
+// DeleteByID deletes the draft did, as long as it belongs to uid
+func (s *DefaultDraftStore) DeleteByID(uid, did int) error {
+	_, err := s.deleteByID.Exec(uid, did)
+	return err
+}
+
+func (s *DefaultDraftStore) DeleteAll(uid int) error {
+	_, err := s.deleteAll.Exec(uid)
+	return err
+}
+
+// Prune deletes the drafts which haven't been touched for more than days days
+func (s *DefaultDraftStore) Prune(days int) error {
+	_, err := s.prune.Exec(days)
+	return err
+}
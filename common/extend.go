@@ -106,6 +106,17 @@ var hookTable = &HookTable{
 		"action_end_like_topic":    nil,
 		"action_end_unlike_topic":  nil,
 
+		"action_end_merge_topic":  nil,
+		"action_end_split_topic":  nil,
+		"action_end_move_replies": nil,
+
+		"action_end_restore_topic": nil,
+		"action_end_restore_reply": nil,
+		"action_end_purge_topic":   nil,
+		"action_end_purge_reply":   nil,
+		"action_end_revert_topic":  nil,
+		"action_end_revert_reply":  nil,
+
 		"action_end_create_reply":             nil,
 		"action_end_edit_reply":               nil,
 		"action_end_delete_reply":             nil,
@@ -115,6 +126,10 @@ var hookTable = &HookTable{
 		"action_end_like_reply":   nil,
 		"action_end_unlike_reply": nil,
 
+		"action_end_vote_reply":      nil,
+		"action_end_accept_answer":   nil,
+		"action_end_unaccept_answer": nil,
+
 		"action_end_ban_user":      nil,
 		"action_end_unban_user":    nil,
 		"action_end_activate_user": nil,
@@ -61,7 +61,8 @@ type SFile struct {
 }
 
 type CSSData struct {
-	Phrases map[string]string
+	Phrases  map[string]string
+	Settings map[string]string
 }
 
 func (l SFileList) JSTmplInit() error {
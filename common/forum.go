@@ -20,6 +20,8 @@ type ForumAdmin struct {
 	Preset     string
 	TopicCount int
 	PresetLang string
+	Category   bool
+	Subforums  PanelForumList
 }
 
 type Forum struct {
@@ -34,6 +36,8 @@ type Forum struct {
 	ParentID   int
 	ParentType string
 	TopicCount int
+	Questions  bool // Question mode lets the topic author or a moderator accept one of the replies as the answer
+	Category   bool // Categories only hold other forums, so topics can't be posted in them
 
 	LastTopic     *Topic
 	LastTopicID   int
@@ -52,8 +56,11 @@ type ForumSimple struct {
 }
 
 type ForumStmts struct {
-	update    *sql.Stmt
-	setPreset *sql.Stmt
+	update       *sql.Stmt
+	setPreset    *sql.Stmt
+	setQuestions *sql.Stmt
+	setParent    *sql.Stmt
+	setCategory  *sql.Stmt
 }
 
 var forumStmts ForumStmts
@@ -61,8 +68,11 @@ var forumStmts ForumStmts
 func init() {
 	DbInits.Add(func(acc *qgen.Accumulator) error {
 		forumStmts = ForumStmts{
-			update:    acc.Update("forums").Set("name=?,desc=?,active=?,preset=?").Where("fid=?").Prepare(),
-			setPreset: acc.Update("forums").Set("preset=?").Where("fid=?").Prepare(),
+			update:       acc.Update("forums").Set("name=?,desc=?,active=?,preset=?").Where("fid=?").Prepare(),
+			setPreset:    acc.Update("forums").Set("preset=?").Where("fid=?").Prepare(),
+			setQuestions: acc.Update("forums").Set("questions=?").Where("fid=?").Prepare(),
+			setParent:    acc.Update("forums").Set("parentID=?,parentType=?").Where("fid=?").Prepare(),
+			setCategory:  acc.Update("forums").Set("category=?").Where("fid=?").Prepare(),
 		}
 		return acc.FirstError()
 	})
@@ -85,7 +95,12 @@ func (f *Forum) Update(name, desc string, active bool, preset string) error {
 	if err != nil {
 		return err
 	}
-	if f.Preset != preset && preset != "custom" && preset != "" {
+	if f.Preset != preset && preset == "inherit" {
+		err = ClearForumPerms(f.ID)
+		if err != nil {
+			return err
+		}
+	} else if f.Preset != preset && preset != "custom" && preset != "" {
 		err = PermmapToQuery(PresetToPermmap(preset), f.ID)
 		if err != nil {
 			return err
@@ -95,6 +110,69 @@ func (f *Forum) Update(name, desc string, active bool, preset string) error {
 	return nil
 }
 
+// SetQuestions switches question mode on or off for this forum
+func (f *Forum) SetQuestions(questions bool) error {
+	_, err := forumStmts.setQuestions.Exec(questions, f.ID)
+	if err != nil {
+		return err
+	}
+	_ = Forums.Reload(f.ID)
+	TopicListThaw.Thaw()
+	return nil
+}
+
+// SetParent moves this forum under another one, a parentID of zero puts it back on the top level
+func (f *Forum) SetParent(parentID int) error {
+	err := checkForumParents(map[int]int{f.ID: parentID})
+	if err != nil {
+		return err
+	}
+	parentType := "forum"
+	if parentID == 0 {
+		parentType = ""
+	}
+	_, err = forumStmts.setParent.Exec(parentID, parentType, f.ID)
+	if err != nil {
+		return err
+	}
+	_ = Forums.Reload(f.ID)
+	// The forums under this one can only be seen by the groups which can see everything above them
+	return FPStore.Reload(f.ID)
+}
+
+// SetCategory turns this forum into a category or back again, only empty forums on the top level can become categories
+func (f *Forum) SetCategory(category bool) error {
+	if category && !f.Category {
+		if f.ParentType == "forum" && f.ParentID != 0 {
+			return ErrCategoryParent
+		}
+		if f.TopicCount > 0 {
+			return ErrCategoryTopics
+		}
+	}
+	_, err := forumStmts.setCategory.Exec(category, f.ID)
+	if err != nil {
+		return err
+	}
+	_ = Forums.Reload(f.ID)
+	TopicListThaw.Thaw()
+	return nil
+}
+
+// Parents returns the forums above this one, starting from the top level
+func (f *Forum) Parents() (parents []*Forum) {
+	seen := map[int]bool{f.ID: true}
+	for pf := f; pf.ParentType == "forum" && pf.ParentID != 0; {
+		pf = Forums.DirtyGet(pf.ParentID)
+		if pf.Name == "" || seen[pf.ID] {
+			break
+		}
+		seen[pf.ID] = true
+		parents = append([]*Forum{pf}, parents...)
+	}
+	return parents
+}
+
 func (f *Forum) SetPreset(preset string, gid int) error {
 	fp, changed := GroupForumPresetToForumPerms(preset)
 	if changed {
@@ -0,0 +1,197 @@
+package common
+
+import (
+	"database/sql"
+	"sync/atomic"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// ForumModPerms are the moderation permissions a user has been given in a forum
+type ForumModPerms struct {
+	PinTopic    bool
+	CloseTopic  bool
+	MoveTopic   bool
+	DeletePosts bool // Topics and replies
+	EditPosts   bool // Topics and replies
+}
+
+// Any tells you whether any of the permissions are set, a moderator without any isn't much of a moderator
+func (p ForumModPerms) Any() bool {
+	return p.PinTopic || p.CloseTopic || p.MoveTopic || p.DeletePosts || p.EditPosts
+}
+
+func (p ForumModPerms) merge(o ForumModPerms) ForumModPerms {
+	return ForumModPerms{
+		PinTopic:    p.PinTopic || o.PinTopic,
+		CloseTopic:  p.CloseTopic || o.CloseTopic,
+		MoveTopic:   p.MoveTopic || o.MoveTopic,
+		DeletePosts: p.DeletePosts || o.DeletePosts,
+		EditPosts:   p.EditPosts || o.EditPosts,
+	}
+}
+
+// Apply grants the permissions to u on top of the ones they already have
+func (p ForumModPerms) Apply(u *User) {
+	u.Perms.PinTopic = u.Perms.PinTopic || p.PinTopic
+	u.Perms.CloseTopic = u.Perms.CloseTopic || p.CloseTopic
+	u.Perms.MoveTopic = u.Perms.MoveTopic || p.MoveTopic
+	u.Perms.DeleteTopic = u.Perms.DeleteTopic || p.DeletePosts
+	u.Perms.DeleteReply = u.Perms.DeleteReply || p.DeletePosts
+	u.Perms.EditTopic = u.Perms.EditTopic || p.EditPosts
+	u.Perms.EditReply = u.Perms.EditReply || p.EditPosts
+}
+
+// ForumMod is a user who has been made a moderator of a single forum, along with the subforums under it
+type ForumMod struct {
+	ForumID int
+	UserID  int
+	ForumModPerms
+}
+
+var ForumMods ForumModStore
+
+type ForumModStore interface {
+	ReloadAll() error
+	Get(fid, uid int) (*ForumMod, error)
+	GetByForum(fid int) []*ForumMod
+	PermsFor(fid int, u *User) (perms ForumModPerms, ok bool)
+	Set(fid, uid int, perms ForumModPerms) error
+	Delete(fid, uid int) error
+	DeleteByUser(uid int) error
+	DeleteByForum(fid int) error
+}
+
+type DefaultForumModStore struct {
+	box atomic.Value // An atomic value holding a map[int][]*ForumMod, the moderators of each forum
+
+	getAll        *sql.Stmt
+	create        *sql.Stmt
+	update        *sql.Stmt
+	delete        *sql.Stmt
+	deleteByUser  *sql.Stmt
+	deleteByForum *sql.Stmt
+}
+
+func NewDefaultForumModStore(acc *qgen.Accumulator) (*DefaultForumModStore, error) {
+	fm := "forums_moderators"
+	s := &DefaultForumModStore{
+		getAll:        acc.Select(fm).Columns("fid,uid,pinTopic,closeTopic,moveTopic,deletePosts,editPosts").Orderby("fid ASC,uid ASC").Prepare(),
+		create:        acc.Insert(fm).Columns("fid,uid,pinTopic,closeTopic,moveTopic,deletePosts,editPosts").Fields("?,?,?,?,?,?,?").Prepare(),
+		update:        acc.Update(fm).Set("pinTopic=?,closeTopic=?,moveTopic=?,deletePosts=?,editPosts=?").Where("fid=? AND uid=?").Prepare(),
+		delete:        acc.Delete(fm).Where("fid=? AND uid=?").Prepare(),
+		deleteByUser:  acc.Delete(fm).Where("uid=?").Prepare(),
+		deleteByForum: acc.Delete(fm).Where("fid=?").Prepare(),
+	}
+	if acc.FirstError() == nil {
+		acc.RecordError(s.ReloadAll())
+	}
+	return s, acc.FirstError()
+}
+
+// ReloadAll drops all the moderators in the memory cache and replaces them with fresh copies from the database
+func (s *DefaultForumModStore) ReloadAll() error {
+	mods := make(map[int][]*ForumMod)
+	rows, err := s.getAll.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		m := &ForumMod{}
+		err := rows.Scan(&m.ForumID, &m.UserID, &m.PinTopic, &m.CloseTopic, &m.MoveTopic, &m.DeletePosts, &m.EditPosts)
+		if err != nil {
+			return err
+		}
+		mods[m.ForumID] = append(mods[m.ForumID], m)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	s.box.Store(mods)
+	return nil
+}
+
+// GetByForum returns the moderators of fid, not counting the ones who got it from a parent forum. Do not mutate the returned slice or the moderators within it.
+func (s *DefaultForumModStore) GetByForum(fid int) []*ForumMod {
+	return s.box.Load().(map[int][]*ForumMod)[fid]
+}
+
+func (s *DefaultForumModStore) Get(fid, uid int) (*ForumMod, error) {
+	for _, m := range s.GetByForum(fid) {
+		if m.UserID == uid {
+			return m, nil
+		}
+	}
+	return nil, ErrNoRows
+}
+
+// PermsFor returns the moderation permissions u has in fid, including the ones from the forums above it. Guests and banned users don't get any.
+func (s *DefaultForumModStore) PermsFor(fid int, u *User) (perms ForumModPerms, ok bool) {
+	if u.ID == 0 || u.IsBanned {
+		return perms, false
+	}
+	mods := s.box.Load().(map[int][]*ForumMod)
+	if len(mods) == 0 {
+		return perms, false
+	}
+	f := Forums.DirtyGet(fid)
+	for _, pf := range append(f.Parents(), f) {
+		for _, m := range mods[pf.ID] {
+			if m.UserID == u.ID {
+				perms = perms.merge(m.ForumModPerms)
+				ok = true
+			}
+		}
+	}
+	return perms, ok
+}
+
+// Set makes uid a moderator of fid with perms, or changes the permissions they have there, if they're already one
+func (s *DefaultForumModStore) Set(fid, uid int, perms ForumModPerms) (err error) {
+	if _, gerr := s.Get(fid, uid); gerr == ErrNoRows {
+		_, err = s.create.Exec(fid, uid, perms.PinTopic, perms.CloseTopic, perms.MoveTopic, perms.DeletePosts, perms.EditPosts)
+	} else {
+		_, err = s.update.Exec(perms.PinTopic, perms.CloseTopic, perms.MoveTopic, perms.DeletePosts, perms.EditPosts, fid, uid)
+	}
+	if err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+func (s *DefaultForumModStore) Delete(fid, uid int) error {
+	_, err := s.delete.Exec(fid, uid)
+	if err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// DeleteByUser takes uid off every forum they moderate, this is called when they're deleted
+func (s *DefaultForumModStore) DeleteByUser(uid int) error {
+	_, err := s.deleteByUser.Exec(uid)
+	if err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+func (s *DefaultForumModStore) DeleteByForum(fid int) error {
+	_, err := s.deleteByForum.Exec(fid)
+	if err != nil {
+		return err
+	}
+	return s.reload()
+}
+
+// reload refreshes the memory cache on this server and tells the others to do the same
+func (s *DefaultForumModStore) reload() error {
+	err := s.ReloadAll()
+	if err != nil {
+		return err
+	}
+	ClusterPublish(ClusterForumMods, 0)
+	return nil
+}
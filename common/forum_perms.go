@@ -11,36 +11,46 @@ import (
 
 var LocalPermList = []string{
 	"ViewTopic",
+	"ViewOwnTopic",
 	"LikeItem",
 	"CreateTopic",
 	"EditTopic",
+	"EditOwnTopic",
 	"DeleteTopic",
+	"DeleteOwnTopic",
 	"CreateReply",
+	"CreateReplyToOwn",
 	"EditReply",
+	"EditOwnReply",
 	"DeleteReply",
+	"DeleteOwnReply",
 	"PinTopic",
 	"CloseTopic",
+	"CloseOwnTopic",
 	"MoveTopic",
 }
 
 // TODO: Rename this to ForumPermSet?
 /* Inherit from group permissions for ones we don't have */
 type ForumPerms struct {
-	ViewTopic bool
-	//ViewOwnTopic bool
-	LikeItem    bool
-	CreateTopic bool
-	EditTopic   bool
-	DeleteTopic bool
-	CreateReply bool
-	//CreateReplyToOwn bool
-	EditReply bool
-	//EditOwnReply bool
-	DeleteReply bool
-	PinTopic    bool
-	CloseTopic  bool
-	//CloseOwnTopic bool
-	MoveTopic bool
+	ViewTopic        bool
+	ViewOwnTopic     bool
+	LikeItem         bool
+	CreateTopic      bool
+	EditTopic        bool
+	EditOwnTopic     bool
+	DeleteTopic      bool
+	DeleteOwnTopic   bool
+	CreateReply      bool
+	CreateReplyToOwn bool
+	EditReply        bool
+	EditOwnReply     bool
+	DeleteReply      bool
+	DeleteOwnReply   bool
+	PinTopic         bool
+	CloseTopic       bool
+	CloseOwnTopic    bool
+	MoveTopic        bool
 
 	Overrides bool
 	ExtData   map[string]bool
@@ -176,6 +186,20 @@ func PermmapToQuery(permmap map[string]*ForumPerms, fid int) error {
 	//return TopicList.RebuildPermTree()
 }
 
+// ClearForumPerms deletes the permissions set on a forum, so that it takes them from the forum above it, or from the groups when it's on the top level
+func ClearForumPerms(fid int) error {
+	stmt, err := qgen.Builder.SimpleDelete("forums_permissions", "fid=?")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	_, err = stmt.Exec(fid)
+	if err != nil {
+		return err
+	}
+	return FPStore.Reload(fid)
+}
+
 // TODO: FPStore.Reload?
 func ReplaceForumPermsForGroup(gid int, presetSet map[int]string, permSets map[int]*ForumPerms) error {
 	tx, err := qgen.Builder.Begin()
@@ -272,22 +296,28 @@ func BlankForumPerms() *ForumPerms {
 
 func ReadWriteForumPerms() *ForumPerms {
 	return &ForumPerms{
-		ViewTopic:   true,
-		LikeItem:    true,
-		CreateTopic: true,
-		CreateReply: true,
-		Overrides:   true,
-		ExtData:     make(map[string]bool),
+		ViewTopic:      true,
+		LikeItem:       true,
+		CreateTopic:    true,
+		EditOwnTopic:   true,
+		DeleteOwnTopic: true,
+		CreateReply:    true,
+		EditOwnReply:   true,
+		DeleteOwnReply: true,
+		Overrides:      true,
+		ExtData:        make(map[string]bool),
 	}
 }
 
 func ReadReplyForumPerms() *ForumPerms {
 	return &ForumPerms{
-		ViewTopic:   true,
-		LikeItem:    true,
-		CreateReply: true,
-		Overrides:   true,
-		ExtData:     make(map[string]bool),
+		ViewTopic:      true,
+		LikeItem:       true,
+		CreateReply:    true,
+		EditOwnReply:   true,
+		DeleteOwnReply: true,
+		Overrides:      true,
+		ExtData:        make(map[string]bool),
 	}
 }
 
@@ -302,17 +332,24 @@ func ReadForumPerms() *ForumPerms {
 // AllForumPerms is a set of forum local permissions with everything set to true
 func AllForumPerms() *ForumPerms {
 	return &ForumPerms{
-		ViewTopic:   true,
-		LikeItem:    true,
-		CreateTopic: true,
-		EditTopic:   true,
-		DeleteTopic: true,
-		CreateReply: true,
-		EditReply:   true,
-		DeleteReply: true,
-		PinTopic:    true,
-		CloseTopic:  true,
-		MoveTopic:   true,
+		ViewTopic:        true,
+		ViewOwnTopic:     true,
+		LikeItem:         true,
+		CreateTopic:      true,
+		EditTopic:        true,
+		EditOwnTopic:     true,
+		DeleteTopic:      true,
+		DeleteOwnTopic:   true,
+		CreateReply:      true,
+		CreateReplyToOwn: true,
+		EditReply:        true,
+		EditOwnReply:     true,
+		DeleteReply:      true,
+		DeleteOwnReply:   true,
+		PinTopic:         true,
+		CloseTopic:       true,
+		CloseOwnTopic:    true,
+		MoveTopic:        true,
 
 		Overrides: true,
 		ExtData:   make(map[string]bool),
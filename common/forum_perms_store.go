@@ -46,17 +46,27 @@ func NewMemoryForumPermsStore() (*MemoryForumPermsStore, error) {
 
 func (s *MemoryForumPermsStore) Init() error {
 	DebugLog("Initialising the forum perms store")
-	return s.ReloadAll()
+	return s.reloadAll()
 }
 
+// ReloadAll refreshes the permissions for every forum and has the other servers in the cluster do the same
 func (s *MemoryForumPermsStore) ReloadAll() error {
+	err := s.reloadAll()
+	if err != nil {
+		return err
+	}
+	ClusterPublish(ClusterForumPerms, 0)
+	return nil
+}
+
+func (s *MemoryForumPermsStore) reloadAll() error {
 	DebugLog("Reloading the forum perms")
 	fids, err := Forums.GetAllIDs()
 	if err != nil {
 		return err
 	}
 	for _, fid := range fids {
-		err := s.Reload(fid)
+		err := s.reload(fid)
 		if err != nil {
 			return err
 		}
@@ -73,8 +83,18 @@ func (s *MemoryForumPermsStore) parseForumPerm(perms []byte) (pperms *ForumPerms
 	return pperms, err
 }
 
-// TODO: Need a more thread-safe way of doing this. Possibly with sync.Map?
+// Reload refreshes the permissions for a forum and has the other servers in the cluster do the same
 func (s *MemoryForumPermsStore) Reload(fid int) error {
+	err := s.reload(fid)
+	if err != nil {
+		return err
+	}
+	ClusterPublish(ClusterForumPerms, fid)
+	return nil
+}
+
+// TODO: Need a more thread-safe way of doing this. Possibly with sync.Map?
+func (s *MemoryForumPermsStore) reload(fid int) error {
 	DebugLogf("Reloading the forum permissions for forum #%d", fid)
 	rows, err := s.getByForum.Query(fid)
 	if err != nil {
@@ -129,43 +149,38 @@ func (s *MemoryForumPermsStore) Reload(fid int) error {
 
 	for _, group := range groups {
 		DebugLogf("Updating the forum permissions for Group #%d", group.ID)
-		canSee := []int{}
+		visible := make(map[int]bool, len(fids))
 		for _, fid := range fids {
 			DebugDetailf("Forum #%+v\n", fid)
-			var forumPerms map[int]*ForumPerms
-			var ok bool
-			if fid%2 == 0 {
-				s.evenLock.RLock()
-				forumPerms, ok = s.evenForums[fid]
-				s.evenLock.RUnlock()
-			} else {
-				s.oddLock.RLock()
-				forumPerms, ok = s.oddForums[fid]
-				s.oddLock.RUnlock()
+			if _, ok := s.forumMap(fid); !ok {
+				continue
 			}
-
-			var forumPerm *ForumPerms
-			if !ok {
+			// The forums which were deleted can linger in the cache as blanks
+			if Forums.DirtyGet(fid).Name == "" {
 				continue
 			}
-			forumPerm, ok = forumPerms[group.ID]
-			if !ok {
-				if group.Perms.ViewTopic {
-					canSee = append(canSee, fid)
-				}
+			forumPerm, err := s.Get(fid, group.ID)
+			if err == ErrNoRows {
+				visible[fid] = group.Perms.ViewTopic
 				continue
+			} else if err != nil {
+				return err
 			}
-
 			if forumPerm.Overrides {
-				if forumPerm.ViewTopic {
-					canSee = append(canSee, fid)
-				}
-			} else if group.Perms.ViewTopic {
-				canSee = append(canSee, fid)
+				visible[fid] = forumPerm.ViewTopic
+			} else {
+				visible[fid] = group.Perms.ViewTopic
 			}
 			DebugDetail("group.ID: ", group.ID)
 			DebugDetailf("forumPerm: %+v\n", forumPerm)
-			DebugDetail("canSee: ", canSee)
+		}
+
+		canSee := []int{}
+		for _, fid := range fids {
+			// Subforums are hidden along with the forum they're in
+			if visible[fid] && s.parentsVisible(fid, visible) {
+				canSee = append(canSee, fid)
+			}
 		}
 		DebugDetailf("canSee (length %d): %+v \n", len(canSee), canSee)
 		gcache.SetCanSee(group.ID, canSee)
@@ -190,12 +205,7 @@ func (s *MemoryForumPermsStore) GetAllMap() (bigMap map[int]map[int]*ForumPerms)
 	return bigMap
 }
 
-// TODO: Add a hook here and have plugin_guilds use it
-// TODO: Check if the forum exists?
-// TODO: Fix the races
-func (s *MemoryForumPermsStore) Get(fid, gid int) (fp *ForumPerms, err error) {
-	var fmap map[int]*ForumPerms
-	var ok bool
+func (s *MemoryForumPermsStore) forumMap(fid int) (fmap map[int]*ForumPerms, ok bool) {
 	if fid%2 == 0 {
 		s.evenLock.RLock()
 		fmap, ok = s.evenForums[fid]
@@ -205,15 +215,42 @@ func (s *MemoryForumPermsStore) Get(fid, gid int) (fp *ForumPerms, err error) {
 		fmap, ok = s.oddForums[fid]
 		s.oddLock.RUnlock()
 	}
-	if !ok {
-		return fp, ErrNoRows
+	return fmap, ok
+}
+
+// parentsVisible checks if every forum above fid is in visible
+func (s *MemoryForumPermsStore) parentsVisible(fid int, visible map[int]bool) bool {
+	f := Forums.DirtyGet(fid)
+	for i := 0; i < maxForumDepth && f.ParentType == "forum" && f.ParentID != 0; i++ {
+		if !visible[f.ParentID] {
+			return false
+		}
+		f = Forums.DirtyGet(f.ParentID)
 	}
+	return true
+}
 
-	fp, ok = fmap[gid]
-	if !ok {
-		return fp, ErrNoRows
+// Get returns the permissions a group has in a forum, subforums which don't have any for the group take them from the forum above them
+// TODO: Add a hook here and have plugin_guilds use it
+// TODO: Check if the forum exists?
+// TODO: Fix the races
+func (s *MemoryForumPermsStore) Get(fid, gid int) (fp *ForumPerms, err error) {
+	for i := 0; i < maxForumDepth; i++ {
+		fmap, ok := s.forumMap(fid)
+		if !ok {
+			return fp, ErrNoRows
+		}
+		fp, ok = fmap[gid]
+		if ok {
+			return fp, nil
+		}
+		f := Forums.DirtyGet(fid)
+		if f.ParentType != "forum" || f.ParentID == 0 {
+			break
+		}
+		fid = f.ParentID
 	}
-	return fp, nil
+	return fp, ErrNoRows
 }
 
 // TODO: Check if the forum exists?
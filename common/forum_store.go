@@ -39,16 +39,19 @@ type ForumStore interface {
 	AddTopic(tid, uid, fid int) error
 	RemoveTopic(fid int) error
 	RemoveTopics(fid, count int) error
+	RefreshTopic(fid int) error
+	RestoreTopic(fid int) error
 	UpdateLastTopic(tid, uid, fid int) error
 	Exists(id int) bool
 	GetAll() ([]*Forum, error)
 	GetAllIDs() ([]int, error)
 	GetAllVisible() ([]*Forum, error)
 	GetAllVisibleIDs() ([]int, error)
-	//GetChildren(parentID int, parentType string) ([]*Forum,error)
+	GetChildren(parentID int, parentType string) ([]*Forum, error)
 	//GetFirstChild(parentID int, parentType string) (*Forum,error)
 	Create(name, desc string, active bool, preset string) (int, error)
 	UpdateOrder(updateMap map[int]int) error
+	UpdateTree(items []ForumTreeItem) error
 
 	Count() int
 }
@@ -75,6 +78,8 @@ type MemoryForumStore struct {
 	removeTopics *sql.Stmt
 	lastTopic    *sql.Stmt
 	updateOrder  *sql.Stmt
+	updateTree   *sql.Stmt
+	setParent    *sql.Stmt
 }
 
 // NewMemoryForumStore gives you a new instance of MemoryForumStore
@@ -83,26 +88,29 @@ func NewMemoryForumStore() (*MemoryForumStore, error) {
 	f := "forums"
 	// TODO: Do a proper delete
 	return &MemoryForumStore{
-		get:          acc.Select(f).Columns("name, desc, tmpl, active, order, preset, parentID, parentType, topicCount, lastTopicID, lastReplyerID").Where("fid=?").Prepare(),
-		getAll:       acc.Select(f).Columns("fid, name, desc, tmpl, active, order, preset, parentID, parentType, topicCount, lastTopicID, lastReplyerID").Orderby("order ASC, fid ASC").Prepare(),
+		get:          acc.Select(f).Columns("name, desc, tmpl, active, order, preset, parentID, parentType, topicCount, questions, category, lastTopicID, lastReplyerID").Where("fid=?").Prepare(),
+		getAll:       acc.Select(f).Columns("fid, name, desc, tmpl, active, order, preset, parentID, parentType, topicCount, questions, category, lastTopicID, lastReplyerID").Orderby("order ASC, fid ASC").Prepare(),
 		delete:       acc.Update(f).Set("name='',active=0").Where("fid=?").Prepare(),
 		create:       acc.Insert(f).Columns("name, desc, tmpl, active, preset").Fields("?,?,'',?,?").Prepare(),
 		count:        acc.Count(f).Where("name != ''").Prepare(),
 		updateCache:  acc.Update(f).Set("lastTopicID=?, lastReplyerID=?").Where("fid=?").Prepare(),
 		addTopics:    acc.Update(f).Set("topicCount=topicCount+?").Where("fid=?").Prepare(),
 		removeTopics: acc.Update(f).Set("topicCount=topicCount-?").Where("fid=?").Prepare(),
-		lastTopic:    acc.Select("topics").Columns("tid").Where("parentID=?").Orderby("lastReplyAt DESC, createdAt DESC").Limit("1").Prepare(),
+		lastTopic:    acc.Select("topics").Columns("tid").Where("parentID=? AND deleted=0").Orderby("lastReplyAt DESC, createdAt DESC").Limit("1").Prepare(),
 		updateOrder:  acc.Update(f).Set("order=?").Where("fid=?").Prepare(),
+		updateTree:   acc.Update(f).Set("order=?,parentID=?,parentType=?").Where("fid=?").Prepare(),
+		setParent:    acc.Update(f).Set("parentID=?,parentType=?").Where("parentID=? AND parentType='forum'").Prepare(),
 	}, acc.FirstError()
 }
 
 // TODO: Rename to ReloadAll?
-// TODO: Add support for subforums
 func (s *MemoryForumStore) LoadForums() error {
 	var forumView []*Forum
+	loaded := make(map[int]bool)
 	addForum := func(f *Forum) {
 		s.forums.Store(f.ID, f)
-		if f.Active && f.Name != "" && f.ParentType == "" {
+		loaded[f.ID] = true
+		if f.Active && f.Name != "" && (f.ParentType == "" || f.ParentType == "forum") {
 			forumView = append(forumView, f)
 		}
 	}
@@ -116,7 +124,7 @@ func (s *MemoryForumStore) LoadForums() error {
 	i := 0
 	for ; rows.Next(); i++ {
 		f := &Forum{ID: 0, Active: true, Preset: "all"}
-		err = rows.Scan(&f.ID, &f.Name, &f.Desc, &f.Tmpl, &f.Active, &f.Order, &f.Preset, &f.ParentID, &f.ParentType, &f.TopicCount, &f.LastTopicID, &f.LastReplyerID)
+		err = rows.Scan(&f.ID, &f.Name, &f.Desc, &f.Tmpl, &f.Active, &f.Order, &f.Preset, &f.ParentID, &f.ParentType, &f.TopicCount, &f.Questions, &f.Category, &f.LastTopicID, &f.LastReplyerID)
 		if err != nil {
 			return err
 		}
@@ -135,9 +143,19 @@ func (s *MemoryForumStore) LoadForums() error {
 		f.LastPage = lastPage
 		addForum(f)
 	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	// Anything left over was deleted behind our backs, e.g. by a backup being restored
+	s.forums.Range(func(id, _ interface{}) bool {
+		if !loaded[id.(int)] {
+			s.forums.Delete(id)
+		}
+		return true
+	})
 	s.forumView.Store(forumView)
 	TopicListThaw.Thaw()
-	return rows.Err()
+	return nil
 }
 
 // TODO: Hide social groups too
@@ -146,8 +164,8 @@ func (s *MemoryForumStore) rebuildView() {
 	var forumView []*Forum
 	s.forums.Range(func(_, val interface{}) bool {
 		f := val.(*Forum)
-		// ? - ParentType blank means that it doesn't have a parent
-		if f.Active && f.Name != "" && f.ParentType == "" {
+		// ? - ParentType blank means that it doesn't have a parent, the guild forums are left out
+		if f.Active && f.Name != "" && (f.ParentType == "" || f.ParentType == "forum") {
 			forumView = append(forumView, f)
 		}
 		return true
@@ -204,7 +222,7 @@ func (s *MemoryForumStore) Get(id int) (*Forum, error) {
 
 func (s *MemoryForumStore) BypassGet(id int) (*Forum, error) {
 	f := &Forum{ID: id}
-	err := s.get.QueryRow(id).Scan(&f.Name, &f.Desc, &f.Tmpl, &f.Active, &f.Order, &f.Preset, &f.ParentID, &f.ParentType, &f.TopicCount, &f.LastTopicID, &f.LastReplyerID)
+	err := s.get.QueryRow(id).Scan(&f.Name, &f.Desc, &f.Tmpl, &f.Active, &f.Order, &f.Preset, &f.ParentID, &f.ParentType, &f.TopicCount, &f.Questions, &f.Category, &f.LastTopicID, &f.LastReplyerID)
 	if err != nil {
 		return nil, err
 	}
@@ -235,7 +253,17 @@ func (s *MemoryForumStore) BulkGetCopy(ids []int) (forums []Forum, err error) {
 	return forums, nil
 }
 
+// Reload refreshes the forum from the database and has the other servers in the cluster do the same
 func (s *MemoryForumStore) Reload(id int) error {
+	err := s.reload(id)
+	if err != nil {
+		return err
+	}
+	ClusterPublish(ClusterForum, id)
+	return nil
+}
+
+func (s *MemoryForumStore) reload(id int) error {
 	forum, err := s.BypassGet(id)
 	if err != nil {
 		return err
@@ -284,11 +312,20 @@ func (s *MemoryForumStore) GetAllVisibleIDs() ([]int, error) {
 	return ids, nil
 }
 
-// TODO: Implement sub-forums.
-/*func (s *MemoryForumStore) GetChildren(parentID int, parentType string) ([]*Forum,error) {
-	return nil, nil
+// GetChildren returns the forums directly under a parent in the order they were put in, e.g. the subforums of forum 2 are under 2 and "forum"
+func (s *MemoryForumStore) GetChildren(parentID int, parentType string) (children []*Forum, err error) {
+	s.forums.Range(func(_, val interface{}) bool {
+		f := val.(*Forum)
+		if f.Name != "" && f.ParentID == parentID && f.ParentType == parentType {
+			children = append(children, f)
+		}
+		return true
+	})
+	sort.Sort(SortForum(children))
+	return children, nil
 }
-func (s *MemoryForumStore) GetFirstChild(parentID int, parentType string) (*Forum,error) {
+
+/*func (s *MemoryForumStore) GetFirstChild(parentID int, parentType string) (*Forum,error) {
 	return nil, nil
 }*/
 
@@ -312,9 +349,39 @@ func (s *MemoryForumStore) Delete(id int) error {
 	if id == ReportForumID {
 		return ErrNoDeleteReports
 	}
-	_, err := s.delete.Exec(id)
+	f, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	_, err = s.delete.Exec(id)
+	if err != nil {
+		return err
+	}
 	s.CacheDelete(id)
-	return err
+	ClusterPublish(ClusterForumDelete, id)
+	if err = ForumMods.DeleteByForum(id); err != nil {
+		return err
+	}
+
+	// Move the subforums up a level, so they don't disappear along with it
+	children, err := s.GetChildren(id, "forum")
+	if err != nil || len(children) == 0 {
+		return err
+	}
+	parentID, parentType := f.ParentID, f.ParentType
+	if parentType != "forum" {
+		parentID, parentType = 0, ""
+	}
+	_, err = s.setParent.Exec(parentID, parentType, id)
+	if err != nil {
+		return err
+	}
+	for _, cf := range children {
+		if err := s.Reload(cf.ID); err != nil {
+			return err
+		}
+	}
+	return FPStore.ReloadAll()
 }
 
 func (s *MemoryForumStore) AddTopic(tid, uid, fid int) error {
@@ -369,6 +436,16 @@ func (s *MemoryForumStore) RemoveTopic(fid int) error {
 	}
 	return s.RefreshTopic(fid)
 }
+
+// RestoreTopic adds a topic which has been pulled out of the trash back onto the forum's topic count
+func (s *MemoryForumStore) RestoreTopic(fid int) error {
+	_, err := s.addTopics.Exec(1, fid)
+	if err != nil {
+		return err
+	}
+	return s.RefreshTopic(fid)
+}
+
 func (s *MemoryForumStore) RemoveTopics(fid, count int) error {
 	_, err := s.removeTopics.Exec(count, fid)
 	if err != nil {
@@ -411,7 +488,10 @@ func (s *MemoryForumStore) Create(name, desc string, active bool, preset string)
 		return 0, err
 	}
 
-	PermmapToQuery(PresetToPermmap(preset), fid)
+	// A forum which inherits its permissions doesn't have any of its own
+	if preset != "inherit" {
+		PermmapToQuery(PresetToPermmap(preset), fid)
+	}
 	return fid, nil
 }
 
@@ -423,9 +503,40 @@ func (s *MemoryForumStore) UpdateOrder(updateMap map[int]int) error {
 			return err
 		}
 	}
+	ClusterPublish(ClusterForum, 0)
 	return s.LoadForums()
 }
 
+// UpdateTree moves the forums in items under new parents and puts them in a new order, the forums which aren't in items are left where they are
+// TODO: Make this atomic, maybe with a transaction?
+func (s *MemoryForumStore) UpdateTree(items []ForumTreeItem) error {
+	moves := make(map[int]int, len(items))
+	for _, it := range items {
+		moves[it.ID] = it.ParentID
+	}
+	err := checkForumParents(moves)
+	if err != nil {
+		return err
+	}
+	for _, it := range items {
+		parentType := "forum"
+		if it.ParentID == 0 {
+			parentType = ""
+		}
+		_, err := s.updateTree.Exec(it.Order, it.ParentID, parentType, it.ID)
+		if err != nil {
+			return err
+		}
+	}
+	ClusterPublish(ClusterForum, 0)
+	err = s.LoadForums()
+	if err != nil {
+		return err
+	}
+	// Which forums each group can see depends on the forums above them
+	return FPStore.ReloadAll()
+}
+
 // ! Might be slightly inaccurate, if the sync.Map is constantly shifting and churning, but it'll stabilise eventually. Also, slow. Don't use this on every request x.x
 // Length returns the number of forums in the memory cache
 func (s *MemoryForumStore) Length() (len int) {
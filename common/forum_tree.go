@@ -0,0 +1,167 @@
+package common
+
+import "errors"
+
+var ErrForumParentLoop = errors.New("A forum can't be put inside itself or one of its subforums")
+var ErrCategoryParent = errors.New("Categories can't be put inside other forums")
+var ErrCategoryTopics = errors.New("Only forums without any topics in them can be turned into categories")
+var ErrNoParentForum = errors.New("The forum you're trying to put it inside of doesn't exist")
+
+// Stops us from walking around in circles forever, if the tree in the database gets mangled somehow
+const maxForumDepth = 50
+
+// ForumTreeItem is where a forum is put in the tree by the reorderer in the Control Panel
+type ForumTreeItem struct {
+	ID       int
+	ParentID int // Zero for the top level
+	Order    int // The position amongst the other forums under the same parent
+}
+
+// ForumListItem is a forum as it's shown on a forum list, with the topic count and the last topic rolled up from the subforums under it
+type ForumListItem struct {
+	Forum
+	Subforums []*Forum // The ones directly under it which the user can see
+}
+
+// ForumCategory is a category on the forum list along with the forums in it, the top level forums which aren't in one are grouped into ones with a blank Forum
+type ForumCategory struct {
+	Forum
+	ItemList []ForumListItem
+}
+
+// checkForumParents makes sure the forums still form a tree when the ones in moves are put under the parents they're mapped to, [fid]parentID
+func checkForumParents(moves map[int]int) error {
+	parentOf := make(map[int]int)
+	cats := make(map[int]bool)
+	_ = Forums.Each(func(f *Forum) error {
+		if f.Name == "" {
+			return nil
+		}
+		switch f.ParentType {
+		case "":
+			parentOf[f.ID] = 0
+		case "forum":
+			parentOf[f.ID] = f.ParentID
+		default:
+			// The guild forums are managed by the plugin
+			return nil
+		}
+		cats[f.ID] = f.Category
+		return nil
+	})
+	for fid, pid := range moves {
+		if _, ok := parentOf[fid]; !ok {
+			return ErrNoRows
+		}
+		parentOf[fid] = pid
+	}
+
+	for fid, pid := range parentOf {
+		if pid == 0 {
+			continue
+		}
+		if cats[fid] {
+			return ErrCategoryParent
+		}
+		if _, ok := parentOf[pid]; !ok {
+			return ErrNoParentForum
+		}
+	}
+	for fid, pid := range parentOf {
+		seen := map[int]bool{fid: true}
+		for p := pid; p != 0; p = parentOf[p] {
+			if seen[p] {
+				return ErrForumParentLoop
+			}
+			seen[p] = true
+		}
+	}
+	return nil
+}
+
+// ForumTree is the forums someone can see arranged by their parents
+type ForumTree struct {
+	children map[int][]*Forum // [parentID], in the order they were put in from the Control Panel
+}
+
+// NewForumTree arranges the forums in canSee into a tree, the hidden forums are left out along with everything under them
+func NewForumTree(canSee []int) (*ForumTree, error) {
+	forums, err := Forums.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[int]bool, len(canSee))
+	for _, fid := range canSee {
+		visible[fid] = true
+	}
+	t := &ForumTree{make(map[int][]*Forum)}
+	for _, f := range forums {
+		if !visible[f.ID] || f.Name == "" || !f.Active {
+			continue
+		}
+		switch f.ParentType {
+		case "":
+			t.children[0] = append(t.children[0], f)
+		case "forum":
+			t.children[f.ParentID] = append(t.children[f.ParentID], f)
+		}
+	}
+	return t, nil
+}
+
+// Children returns the forums directly under fid, zero gives you the ones on the top level
+func (t *ForumTree) Children(fid int) []*Forum {
+	return t.children[fid]
+}
+
+// Item rolls the topic counts and the last topic of everything under f up into it
+func (t *ForumTree) Item(f *Forum) ForumListItem {
+	item := ForumListItem{f.Copy(), t.children[f.ID]}
+	t.rollup(&item.Forum, f.ID, 0)
+	if item.LastTopicID != 0 && item.LastTopic != nil && item.LastTopic.ID != 0 && item.LastReplyer != nil && item.LastReplyer.ID != 0 {
+		item.LastTopicTime = RelativeTime(item.LastTopic.LastReplyAt)
+	}
+	return item
+}
+
+func (t *ForumTree) rollup(into *Forum, fid, depth int) {
+	if depth > maxForumDepth {
+		return
+	}
+	for _, sf := range t.children[fid] {
+		into.TopicCount += sf.TopicCount
+		if sf.LastTopicID != 0 && sf.LastTopic != nil && (into.LastTopicID == 0 || into.LastTopic == nil || sf.LastTopic.LastReplyAt.After(into.LastTopic.LastReplyAt)) {
+			into.LastTopic, into.LastTopicID = sf.LastTopic, sf.LastTopicID
+			into.LastReplyer, into.LastReplyerID = sf.LastReplyer, sf.LastReplyerID
+			into.LastPage = sf.LastPage
+		}
+		t.rollup(into, sf.ID, depth+1)
+	}
+}
+
+// Items builds the forum list for the forums directly under parentID
+func (t *ForumTree) Items(parentID int) (items []ForumListItem) {
+	for _, f := range t.children[parentID] {
+		items = append(items, t.Item(f))
+	}
+	return items
+}
+
+// Categories builds the forum list for the top level, the forums between the categories are grouped together, so they stay where they were put
+func (t *ForumTree) Categories() (cats []ForumCategory) {
+	for _, f := range t.children[0] {
+		if !f.Category {
+			if len(cats) == 0 || cats[len(cats)-1].ID != 0 {
+				cats = append(cats, ForumCategory{})
+			}
+			last := &cats[len(cats)-1]
+			last.ItemList = append(last.ItemList, t.Item(f))
+			continue
+		}
+		// There's no point in showing a heading with nothing under it
+		if items := t.Items(f.ID); len(items) > 0 {
+			cats = append(cats, ForumCategory{f.Copy(), items})
+		}
+	}
+	return cats
+}
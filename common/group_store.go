@@ -63,7 +63,8 @@ func NewMemoryGroupStore() (*MemoryGroupStore, error) {
 func (s *MemoryGroupStore) LoadGroups() error {
 	s.Lock()
 	defer s.Unlock()
-	s.groups[0] = &Group{ID: 0, Name: "Unknown"}
+	// Start afresh, in case some of the groups were deleted behind our backs, e.g. by a backup being restored
+	s.groups = map[int]*Group{0: {ID: 0, Name: "Unknown"}}
 
 	rows, err := s.getAll.Query()
 	if err != nil {
@@ -139,7 +140,17 @@ func (s *MemoryGroupStore) GetCopy(id int) (Group, error) {
 	return *group, nil
 }
 
+// Reload refreshes the group from the database and has the other servers in the cluster do the same
 func (s *MemoryGroupStore) Reload(id int) error {
+	err := s.reload(id)
+	if err != nil {
+		return err
+	}
+	ClusterPublish(ClusterGroup, id)
+	return nil
+}
+
+func (s *MemoryGroupStore) reload(id int) error {
 	// TODO: Reload this data too
 	g, err := s.Get(id)
 	if err != nil {
@@ -304,6 +315,11 @@ func (s *MemoryGroupStore) Create(name, tag string, isAdmin, isMod, isBanned boo
 	s.Unlock()
 
 	TopicListThaw.Thaw()
+	// The forum permissions are reloaded on the other servers along with the groups
+	ClusterPublish(ClusterGroup, 0)
+	if fps, ok := FPStore.(*MemoryForumPermsStore); ok {
+		return gid, fps.reloadAll()
+	}
 	return gid, FPStore.ReloadAll()
 	//return gid, TopicList.RebuildPermTree()
 }
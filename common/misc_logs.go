@@ -148,12 +148,14 @@ type LoginLogStore interface {
 	Count() (count int)
 	CountUser(uid int) (count int)
 	GetOffset(uid, offset, perPage int) (logs []LoginLogItem, err error)
+	DeleteUser(uid int) error
 }
 
 type SQLLoginLogStore struct {
 	count           *sql.Stmt
 	countForUser    *sql.Stmt
 	getOffsetByUser *sql.Stmt
+	deleteByUser    *sql.Stmt
 }
 
 func NewLoginLogStore(acc *qgen.Accumulator) (*SQLLoginLogStore, error) {
@@ -162,6 +164,7 @@ func NewLoginLogStore(acc *qgen.Accumulator) (*SQLLoginLogStore, error) {
 		count:           acc.Count(ll).Prepare(),
 		countForUser:    acc.Count(ll).Where("uid=?").Prepare(),
 		getOffsetByUser: acc.Select(ll).Columns("lid,success,ipaddress,doneAt").Where("uid=?").Orderby("doneAt DESC").Limit("?,?").Prepare(),
+		deleteByUser:    acc.Delete(ll).Where("uid=?").Prepare(),
 	}, acc.FirstError()
 }
 
@@ -200,3 +203,9 @@ func (s *SQLLoginLogStore) GetOffset(uid, offset, perPage int) (logs []LoginLogI
 	}
 	return logs, rows.Err()
 }
+
+// DeleteUser removes every login attempt made against an account
+func (s *SQLLoginLogStore) DeleteUser(uid int) error {
+	_, err := s.deleteByUser.Exec(uid)
+	return err
+}
@@ -38,12 +38,15 @@ type Header struct {
 	ZoneID      int
 	ZoneData    interface{}
 	Path        string
+	RequestPath string // Path is used to highlight the menu items, this is the one the page was actually requested on
 	MetaDesc    string
 	//OGImage string
 	OGDesc         string
 	GoogSiteVerify string
 	IsoCode        string
+	Lang           *p.LanguagePack // The language the page is being shown in, the templates get their phrases from this
 	LooseCSP       bool
+	ThemeStyle     template.CSS // The CSS variables for the theme preferences the user has picked
 	ExternalMedia  bool
 	//StartedAt      time.Time
 	StartedAt int64
@@ -110,7 +113,15 @@ func (h *Header) AddXRes(names ...string) {
 }
 
 func (h *Header) AddNotice(name string) {
-	h.NoticeList = append(h.NoticeList, p.GetNoticePhrase(name))
+	h.NoticeList = append(h.NoticeList, h.LangPack().NoticePhrase(name))
+}
+
+// LangPack returns the language pack for the page, which is the site's one if the header wasn't built for a request
+func (h *Header) LangPack() *p.LanguagePack {
+	if h == nil || h.Lang == nil {
+		return p.GetLangPack()
+	}
+	return h.Lang
 }
 
 // TODO: Add this to routes which don't use templates. E.g. Json APIs.
@@ -189,6 +200,18 @@ type TopicPage struct {
 	Forum    *Forum
 	Poll     *Poll
 	Paginator
+
+	Prefixes []*TopicPrefix // The prefixes the current user can pick from when editing the topic
+	RawTags  string
+
+	Answer    *ReplyUser // The accepted answer, which is pinned below the opening post
+	CanAccept bool       // Whether the current user can accept or unaccept answers on this question
+	SortVotes bool
+
+	CanSeeDeleted bool // Whether the current user can see what's in the trash
+	ShowDeleted   bool // Whether the replies in the trash are being shown alongside the rest
+
+	Breadcrumbs []*Forum // The forums leading down to this topic, starting from the top
 }
 
 type TopicListSort struct {
@@ -211,6 +234,7 @@ type TopicListPage struct {
 	SelectedFids []int
 	QuickTools
 	Paginator
+	Tag TopicTag // Set when this is a tag page
 }
 
 type ForumPage struct {
@@ -220,11 +244,15 @@ type ForumPage struct {
 	CanLock  bool
 	CanMove  bool
 	Paginator
+
+	Breadcrumbs []*Forum        // The forums above this one, starting from the top
+	Subforums   []ForumListItem // The forums inside this one
+	Moderators  []*User         // The users who've been made moderators of this forum or one of the ones above it
 }
 
 type ForumsPage struct {
 	*Header
-	ItemList []Forum
+	ItemList []ForumCategory
 }
 
 type ProfilePage struct {
@@ -237,12 +265,14 @@ type ProfilePage struct {
 	CanMessage   bool
 	CanComment   bool
 	ShowComments bool
+	Fields       []*ProfileFieldShow
 }
 
 type CreateTopicPage struct {
 	*Header
 	ItemList []Forum
 	FID      int
+	Prefixes []*TopicPrefix
 }
 
 type IPSearchPage struct {
@@ -251,6 +281,46 @@ type IPSearchPage struct {
 	IP       string
 }
 
+type RevisionItem struct {
+	*Revision
+	Creator *User
+}
+
+type RevisionsPage struct {
+	*Header
+	ItemList   []RevisionItem // Newest first
+	Topic      *Topic         // The topic the post is in
+	From       *Revision
+	To         *Revision
+	TitleDiff  []DiffChunk
+	Diff       []DiffChunk
+	CanRevert  bool
+	RevertPath string
+}
+
+type ScheduledTopicItem struct {
+	*ScheduledTopic
+	Forum *Forum // Nil if the forum has gone
+}
+
+type ScheduledTopicsPage struct {
+	*Header
+	ItemList []ScheduledTopicItem // Soonest first
+}
+
+type TopicTimedActionItem struct {
+	*TopicTimedAction
+	Due time.Time
+}
+
+type TopicTimedActionsPage struct {
+	*Header
+	Topic    *Topic
+	ItemList []TopicTimedActionItem
+	CanUnpin bool
+	CanLock  bool
+}
+
 // WIP: Optional anti-bot methods
 type RegisterVerifyImageGridImage struct {
 	Src string
@@ -290,6 +360,17 @@ type AccountLoginsPage struct {
 	Paginator
 }
 
+type DraftItem struct {
+	*Draft
+	Where string // The name of the forum or topic it's for, empty if it no longer exists
+}
+
+type AccountDraftsPage struct {
+	*Header
+	ItemList []DraftItem
+	Paginator
+}
+
 type AccountBlocksPage struct {
 	*Header
 	Users []*User
@@ -303,6 +384,41 @@ type AccountPrivacyPage struct {
 	EnableEmbeds    bool
 }
 
+type AccountProfileFieldsPage struct {
+	*Header
+	Fields []ProfileFieldEdit
+}
+
+type AccountThemeSetting struct {
+	Name         string
+	FriendlyName string
+	Options      []ThemeUserOption
+	Value        string
+}
+
+type AccountThemePage struct {
+	*Header
+	Settings []AccountThemeSetting
+}
+
+type AccountLangItem struct {
+	Name         string
+	FriendlyName string
+}
+
+type AccountLangPage struct {
+	*Header
+	Langs   []AccountLangItem
+	Current string // Blank when the user hasn't picked one
+}
+
+type AccountDataPage struct {
+	*Header
+	CanDelete bool
+	GraceDays int
+	Pending   *SelfDeleteRequest // The user's request to have their account deleted, if they've made one
+}
+
 type AccountDashPage struct {
 	*Header
 	MFASetup     bool
@@ -470,6 +586,47 @@ type PanelUserEditPage struct {
 	ShowEmail bool
 }
 
+type PanelProfileFieldGroup struct {
+	*Group
+	Selected bool
+}
+type PanelProfileFieldsPage struct {
+	*BasePanelPage
+	ItemList []*ProfileField
+	Types    []string
+	Groups   []*Group
+}
+type PanelProfileFieldEditPage struct {
+	*BasePanelPage
+	Field  *ProfileField
+	Types  []string
+	Groups []PanelProfileFieldGroup
+}
+
+type PanelTrashItem struct {
+	*TrashItem
+	Creator *User
+	Deleter *User
+}
+type PanelTrashPage struct {
+	*BasePanelPage
+	ItemList []PanelTrashItem
+	Replies  bool // Whether we're looking at the replies rather than the topics
+	Paginator
+}
+type PanelTopicPrefixesPage struct {
+	*BasePanelPage
+	ItemList []*TopicPrefix
+	Forums   []*Forum
+	Groups   []*Group
+}
+type PanelTopicPrefixEditPage struct {
+	*BasePanelPage
+	Prefix *TopicPrefix
+	Forums []*Forum
+	Groups []*Group
+}
+
 type PanelCustomPagesPage struct {
 	*BasePanelPage
 	ItemList []*CustomPage
@@ -583,6 +740,29 @@ type PanelThemesPage struct {
 	VariantThemes []*Theme
 }
 
+type PanelThemeSetting struct {
+	Name         string
+	FriendlyName string
+	Options      []string
+	Value        string
+}
+
+type PanelThemeEditPage struct {
+	*BasePanelPage
+	Theme    *Theme
+	Files    []string
+	Settings []PanelThemeSetting
+	CanEdit  bool
+}
+
+type PanelThemeFilePage struct {
+	*BasePanelPage
+	Theme   *Theme
+	File    string
+	Content string
+	IsCSS   bool
+}
+
 type PanelMenuListItem struct {
 	Name      string
 	ID        int
@@ -626,6 +806,33 @@ type PanelUserPage struct {
 	PaginatorMod
 }
 
+type PanelPhrasePack struct {
+	Name    string
+	IsoCode string
+	Changed int // The number of phrases which have been changed or added from the Control Panel
+	Default bool
+}
+type PanelPhrasesPage struct {
+	*BasePanelPage
+	ItemList []PanelPhrasePack
+}
+
+type PanelPhrase struct {
+	Name    string
+	Phrase  string
+	Changed bool
+	Custom  bool
+}
+type PanelPhrasesEditPage struct {
+	*BasePanelPage
+	Pack     string
+	Groups   []string
+	Group    string
+	Search   string
+	ItemList []PanelPhrase
+	PaginatorMod
+}
+
 type PanelGroupPage struct {
 	*BasePanelPage
 	ItemList []GroupAdmin
@@ -647,14 +854,45 @@ type GroupForumPermPreset struct {
 	DefaultPreset bool
 }
 
+type PanelForumsPage struct {
+	*BasePanelPage
+	Forums  PanelForumList
+	Parents []PanelForumParent // The forums a new forum can be put inside of
+}
+
+// PanelForumList is a level of the forum tree in the Forum Manager
+type PanelForumList struct {
+	ParentID int
+	Session  string // The delete buttons need this and the template for each level can't reach back up to the page for it
+	ItemList []ForumAdmin
+}
+
 type PanelEditForumPage struct {
 	*BasePanelPage
+	ID        int
+	Name      string
+	Desc      string
+	Active    bool
+	Preset    string
+	Questions bool
+	Category  bool
+	ParentID  int
+	Parents   []PanelForumParent // The forums it can be put inside of
+	Groups    []GroupForumPermPreset
+	Mods      []PanelForumMod
+}
+
+// PanelForumMod is a moderator of the forum being edited
+type PanelForumMod struct {
+	User *User
+	ForumModPerms
+}
+
+// PanelForumParent is an option in the parent forum selects
+type PanelForumParent struct {
 	ID     int
 	Name   string
-	Desc   string
-	Active bool
-	Preset string
-	Groups []GroupForumPermPreset
+	Indent string // Shows how far down the tree it is
 }
 
 type NameLangToggle struct {
@@ -700,9 +938,8 @@ type PanelEditGroupPromotionsPage struct {
 type BackupItem struct {
 	SQLURL string
 
-	// TODO: Add an easier to parse format here for Gosora to be able to more easily reimport portions of the dump and to strip unnecessary data (e.g. table defs and parsed post data)
-
-	Timestamp time.Time
+	Timestamp  time.Time
+	Restorable bool // Only the zips made by Gosora can be restored, the SQL dumps have to be fed into the database by hand
 }
 
 type PanelBackupPage struct {
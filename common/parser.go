@@ -137,6 +137,9 @@ func tryStepBackward(i, step int, runes []rune) (int, bool) {
 	return i - 1, true
 }
 
+// PreparseKeepBackslashes stops PreparseMessage from dropping the backslashes which aren't escaping a tag or a mention, the Markdown plugin turns this on while it's active, as it uses them for escaping things
+var PreparseKeepBackslashes = false
+
 // TODO: Preparse Markdown and normalize it into HTML?
 // TODO: Use a string builder
 func PreparseMessage(msg string) string {
@@ -256,6 +259,8 @@ func PreparseMessage(msg string) string {
 			if peekMatch(i, "&lt;", runes) {
 				msg += "&"
 				i++
+			} else if PreparseKeepBackslashes && !peekMatch(i, "@", runes) {
+				msg += "\\"
 			}
 		} else if char == '&' && peekMatch(i, "lt;", runes) {
 			var ok bool
@@ -579,9 +584,8 @@ func ParseMessage2(msg string, sectionID int, sectionType string, settings *Pars
 				hashType := hashLinkTypes[msg[i+1]]
 				if hashType == "" {
 					//fmt.Println("uh1")
-					sb.WriteString(msg[lastItem:i])
+					// Leave the # where it is, it might be a heading in Markdown, but don't let the next character start a hash link either
 					i++
-					lastItem = i
 					continue
 				}
 				//fmt.Println("hashType:", hashType)
@@ -79,22 +79,24 @@ type Perms struct {
 	AutoLink             bool `json:",omitempty"`
 
 	// Forum permissions
-	ViewTopic bool `json:",omitempty"`
-	//ViewOwnTopic bool `json:",omitempty"`
-	LikeItem    bool `json:",omitempty"`
-	CreateTopic bool `json:",omitempty"`
-	EditTopic   bool `json:",omitempty"`
-	DeleteTopic bool `json:",omitempty"`
-	CreateReply bool `json:",omitempty"`
-	//CreateReplyToOwn bool `json:",omitempty"`
-	EditReply bool `json:",omitempty"`
-	//EditOwnReply bool `json:",omitempty"`
-	DeleteReply bool `json:",omitempty"`
-	//DeleteOwnReply bool `json:",omitempty"`
-	PinTopic   bool `json:",omitempty"`
-	CloseTopic bool `json:",omitempty"`
-	//CloseOwnTopic bool `json:",omitempty"`
-	MoveTopic bool `json:",omitempty"`
+	ViewTopic        bool `json:",omitempty"`
+	ViewOwnTopic     bool `json:",omitempty"` // Lets them see the topics they started in forums they can't otherwise see into
+	LikeItem         bool `json:",omitempty"`
+	CreateTopic      bool `json:",omitempty"`
+	EditTopic        bool `json:",omitempty"`
+	EditOwnTopic     bool `json:",omitempty"`
+	DeleteTopic      bool `json:",omitempty"`
+	DeleteOwnTopic   bool `json:",omitempty"`
+	CreateReply      bool `json:",omitempty"`
+	CreateReplyToOwn bool `json:",omitempty"`
+	EditReply        bool `json:",omitempty"`
+	EditOwnReply     bool `json:",omitempty"`
+	DeleteReply      bool `json:",omitempty"`
+	DeleteOwnReply   bool `json:",omitempty"`
+	PinTopic         bool `json:",omitempty"`
+	CloseTopic       bool `json:",omitempty"`
+	CloseOwnTopic    bool `json:",omitempty"`
+	MoveTopic        bool `json:",omitempty"`
 
 	//ExtData map[string]bool `json:",omitempty"`
 }
@@ -138,17 +140,24 @@ func init() {
 		AutoEmbed:            true,
 		AutoLink:             true,
 
-		ViewTopic:   true,
-		LikeItem:    true,
-		CreateTopic: true,
-		EditTopic:   true,
-		DeleteTopic: true,
-		CreateReply: true,
-		EditReply:   true,
-		DeleteReply: true,
-		PinTopic:    true,
-		CloseTopic:  true,
-		MoveTopic:   true,
+		ViewTopic:        true,
+		ViewOwnTopic:     true,
+		LikeItem:         true,
+		CreateTopic:      true,
+		EditTopic:        true,
+		EditOwnTopic:     true,
+		DeleteTopic:      true,
+		DeleteOwnTopic:   true,
+		CreateReply:      true,
+		CreateReplyToOwn: true,
+		EditReply:        true,
+		EditOwnReply:     true,
+		DeleteReply:      true,
+		DeleteOwnReply:   true,
+		PinTopic:         true,
+		CloseTopic:       true,
+		CloseOwnTopic:    true,
+		MoveTopic:        true,
 
 		//ExtData: make(map[string]bool),
 	}
@@ -168,7 +177,7 @@ func StripInvalidGroupForumPreset(preset string) string {
 
 func StripInvalidPreset(preset string) string {
 	switch preset {
-	case "all", "announce", "members", "staff", "admins", "archive", "custom":
+	case "all", "announce", "members", "staff", "admins", "archive", "inherit", "custom":
 		return preset
 	}
 	return ""
@@ -224,15 +233,22 @@ func OverridePerms(p *Perms, status bool) {
 // TODO: We need a better way of overriding forum perms rather than setting them one by one
 func OverrideForumPerms(p *Perms, status bool) {
 	p.ViewTopic = status
+	p.ViewOwnTopic = status
 	p.LikeItem = status
 	p.CreateTopic = status
 	p.EditTopic = status
+	p.EditOwnTopic = status
 	p.DeleteTopic = status
+	p.DeleteOwnTopic = status
 	p.CreateReply = status
+	p.CreateReplyToOwn = status
 	p.EditReply = status
+	p.EditOwnReply = status
 	p.DeleteReply = status
+	p.DeleteOwnReply = status
 	p.PinTopic = status
 	p.CloseTopic = status
+	p.CloseOwnTopic = status
 	p.MoveTopic = status
 }
 
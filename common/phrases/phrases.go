@@ -14,6 +14,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,11 +23,23 @@ import (
 )
 
 // TODO: Add a phrase store?
-// TODO: Let the admin edit phrases from inside the Control Panel? How should we persist these? Should we create a copy of the langpack or edit the primaries? Use the changeLangpack mutex for this?
 // nolint Be quiet megacheck, this *is* used
 var currentLangPack atomic.Value
 var langPackCount int // TODO: Use atomics for this
 
+var ErrNoLangPack = errors.New("That language pack doesn't exist")
+var ErrNoPhraseGroup = errors.New("Those phrases can't be edited")
+var ErrBlankPhraseName = errors.New("The name of the phrase can't be blank")
+var ErrPhraseExists = errors.New("That phrase already exists")
+var ErrNoPhrase = errors.New("That phrase doesn't exist")
+var ErrPhraseBuiltin = errors.New("That phrase is part of the language pack, it can be changed but not deleted")
+
+// PhraseGroups are the sets of phrases which can be edited from the Control Panel, in the order they're listed there
+var PhraseGroups = []string{"TmplPhrases", "PageTitles", "NoticePhrases", "Errors", "Accounts", "SettingPhrases", "Perms", "PermPresets", "HumanLanguages", "UserAgents", "OperatingSystems"}
+
+// OverrideDir is where the phrases changed from the Control Panel are kept, they're applied on top of the language packs, so they aren't lost when a pack is updated
+var OverrideDir = "./langs/overrides/"
+
 // TODO: We'll be implementing the level phrases in the software proper very very soon!
 type LevelPhrases struct {
 	Level    string
@@ -60,21 +73,37 @@ type LanguagePack struct {
 	TmplPhrasesPrefixes map[string]map[string]string // [prefix][name]phrase
 
 	TmplIndicesToPhrases [][][]byte // [tmplID][index]phrase
+
+	Overrides map[string]map[string]string `json:"-"` // [group][name]phrase, the ones changed from the Control Panel
+	base      *LanguagePack                // The pack as it is in the file
+	fallback  *LanguagePack                // The base of the default pack, which fills in the phrases which haven't been translated yet
 }
 
-// TODO: Add the ability to edit language JSON files from the Control Panel and automatically scan the files for changes
+// TODO: Add the ability to automatically scan the language files for changes
 var langPacks sync.Map                // nolint it is used
 var langTmplIndicesToNames [][]string // [tmplID][index]phraseName
 
+// These are only touched while holding langPackMutex, the built packs in langPacks are swapped out whenever they change
+var langPackMutex sync.Mutex
+var defaultLangPack string
+var basePacks = make(map[string]*LanguagePack)
+var langOverrides = make(map[string]map[string]map[string]string) // [pack][group][name]phrase
+var langOverrideTimes = make(map[string]time.Time)
+
 func InitPhrases(lang string) error {
 	log.Print("Loading the language packs")
+	langPackMutex.Lock()
+	defer langPackMutex.Unlock()
 	err := filepath.Walk("./langs", func(path string, f os.FileInfo, err error) error {
-		if f.IsDir() {
-			return nil
-		}
 		if err != nil {
 			return err
 		}
+		if f.IsDir() {
+			if filepath.Clean(path) == filepath.Clean(OverrideDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
 
 		ext := filepath.Ext("/langs/" + path)
 		if ext != ".json" {
@@ -94,73 +123,338 @@ func InitPhrases(lang string) error {
 		}
 		langPack.ModTime = f.ModTime()
 
-		langPack.ErrorsBytes = make(map[string][]byte)
-		for name, phrase := range langPack.Errors {
-			langPack.ErrorsBytes[name] = []byte(phrase)
+		log.Print("Adding the '" + langPack.Name + "' language pack")
+		basePacks[langPack.Name] = &langPack
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	langPackCount = len(basePacks)
+	if langPackCount == 0 {
+		return errors.New("You don't have any language packs")
+	}
+	if _, ok := basePacks[lang]; !ok {
+		return errors.New("Couldn't find the " + lang + " language pack")
+	}
+	defaultLangPack = lang
+
+	for name := range basePacks {
+		err = loadOverrides(name)
+		if err != nil {
+			return err
 		}
+	}
+	err = rebuildLangPacks()
+	if err != nil {
+		return err
+	}
+	langPack, _ := langPacks.Load(lang)
+	currentLangPack.Store(langPack)
+	return nil
+}
 
-		// [prefix][name]phrase
-		langPack.TmplPhrasesPrefixes = make(map[string]map[string]string)
-		conMap := make(map[string]string) // Cache phrase strings so we can de-dupe items to reduce memory use. There appear to be some minor improvements with this, although we would need a more thorough check to be sure.
-		for name, phrase := range langPack.TmplPhrases {
-			_, ok := conMap[phrase]
-			if !ok {
-				conMap[phrase] = phrase
+func overridePath(pack string) string {
+	return filepath.Join(OverrideDir, pack+".json")
+}
+
+func loadOverrides(pack string) error {
+	f, err := os.Stat(overridePath(pack))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(overridePath(pack))
+	if err != nil {
+		return err
+	}
+	var o map[string]map[string]string
+	err = json.Unmarshal(data, &o)
+	if err != nil {
+		return err
+	}
+	log.Print("Applying the changes made to the '" + pack + "' language pack")
+	langOverrides[pack] = o
+	langOverrideTimes[pack] = f.ModTime()
+	return nil
+}
+
+func saveOverrides(pack string, o map[string]map[string]string) error {
+	if len(o) == 0 {
+		err := os.Remove(overridePath(pack))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	err := os.MkdirAll(OverrideDir, 0755)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(o, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(overridePath(pack), data, 0644)
+}
+
+// rebuildLangPacks swaps in fresh copies of the packs with the overrides applied, the default goes first as the others fall back on it
+func rebuildLangPacks() error {
+	def, err := buildLangPack(basePacks[defaultLangPack], nil)
+	if err != nil {
+		return err
+	}
+	for name, base := range basePacks {
+		lp := def
+		if name != defaultLangPack {
+			lp, err = buildLangPack(base, def)
+			if err != nil {
+				return err
 			}
-			cItem := conMap[phrase]
-			prefix := strings.Split(name, ".")[0]
-			_, ok = langPack.TmplPhrasesPrefixes[prefix]
-			if !ok {
-				langPack.TmplPhrasesPrefixes[prefix] = make(map[string]string)
+		}
+		langPacks.Store(name, lp)
+	}
+
+	// ChangeLanguagePack may have switched to another pack
+	if cur := currentLangPack.Load(); cur != nil {
+		lp, _ := langPacks.Load(cur.(*LanguagePack).Name)
+		currentLangPack.Store(lp)
+	}
+	return nil
+}
+
+func buildLangPack(base, fallback *LanguagePack) (*LanguagePack, error) {
+	lp := *base
+	lp.base = base
+	lp.Overrides = langOverrides[base.Name]
+	if t := langOverrideTimes[base.Name]; t.After(lp.ModTime) {
+		lp.ModTime = t
+	}
+	if fallback != nil {
+		lp.fallback = fallback.base
+		if fallback.ModTime.After(lp.ModTime) {
+			lp.ModTime = fallback.ModTime
+		}
+		if lp.Levels.Level == "" {
+			lp.Levels = fallback.Levels
+		}
+	}
+
+	for _, group := range PhraseGroups {
+		m := make(map[string]string)
+		// Phrases which haven't been translated yet are better off in the default language than as placeholders
+		if fallback != nil {
+			for name, phrase := range *fallback.group(group) {
+				m[name] = phrase
 			}
-			langPack.TmplPhrasesPrefixes[prefix][name] = cItem
 		}
+		for name, phrase := range *base.group(group) {
+			m[name] = phrase
+		}
+		for name, phrase := range lp.Overrides[group] {
+			m[name] = phrase
+		}
+		*lp.group(group) = m
+	}
+
+	lp.ErrorsBytes = make(map[string][]byte)
+	for name, phrase := range lp.Errors {
+		lp.ErrorsBytes[name] = []byte(phrase)
+	}
+
+	// [prefix][name]phrase
+	lp.TmplPhrasesPrefixes = make(map[string]map[string]string)
+	conMap := make(map[string]string) // Cache phrase strings so we can de-dupe items to reduce memory use. There appear to be some minor improvements with this, although we would need a more thorough check to be sure.
+	for name, phrase := range lp.TmplPhrases {
+		_, ok := conMap[phrase]
+		if !ok {
+			conMap[phrase] = phrase
+		}
+		cItem := conMap[phrase]
+		prefix := strings.Split(name, ".")[0]
+		_, ok = lp.TmplPhrasesPrefixes[prefix]
+		if !ok {
+			lp.TmplPhrasesPrefixes[prefix] = make(map[string]string)
+		}
+		lp.TmplPhrasesPrefixes[prefix][name] = cItem
+	}
 
-		// [prefix][name]phrase
-		/*langPack.TmplPhrasesPrefixes = make(map[string]map[string]string)
-		for name, phrase := range langPack.TmplPhrases {
-			prefix := strings.Split(name, ".")[0]
-			_, ok := langPack.TmplPhrasesPrefixes[prefix]
+	lp.TmplIndicesToPhrases = make([][][]byte, len(langTmplIndicesToNames))
+	for tmplID, phraseNames := range langTmplIndicesToNames {
+		phraseSet := make([][]byte, len(phraseNames))
+		for index, phraseName := range phraseNames {
+			phrase, ok := lp.TmplPhrases[phraseName]
 			if !ok {
-				langPack.TmplPhrasesPrefixes[prefix] = make(map[string]string)
-			}
-			langPack.TmplPhrasesPrefixes[prefix][name] = phrase
-		}*/
-
-		langPack.TmplIndicesToPhrases = make([][][]byte, len(langTmplIndicesToNames))
-		for tmplID, phraseNames := range langTmplIndicesToNames {
-			phraseSet := make([][]byte, len(phraseNames))
-			for index, phraseName := range phraseNames {
-				phrase, ok := langPack.TmplPhrases[phraseName]
-				if !ok {
-					log.Printf("langPack.TmplPhrases: %+v\n", langPack.TmplPhrases)
-					panic("Couldn't find template phrase '" + phraseName + "'")
-				}
-				phraseSet[index] = []byte(phrase)
+				return nil, errors.New("Couldn't find template phrase '" + phraseName + "' in the " + lp.Name + " language pack")
 			}
-			langPack.TmplIndicesToPhrases[tmplID] = phraseSet
-			TmplIndexCallback(tmplID, phraseSet)
+			phraseSet[index] = []byte(phrase)
 		}
+		lp.TmplIndicesToPhrases[tmplID] = phraseSet
+	}
+	return &lp, nil
+}
+
+// group returns a pointer to the phrase map for group, so the builder can swap it out
+func (lp *LanguagePack) group(group string) *map[string]string {
+	switch group {
+	case "TmplPhrases":
+		return &lp.TmplPhrases
+	case "PageTitles":
+		return &lp.PageTitles
+	case "NoticePhrases":
+		return &lp.NoticePhrases
+	case "Errors":
+		return &lp.Errors
+	case "Accounts":
+		return &lp.Accounts
+	case "SettingPhrases":
+		return &lp.SettingPhrases
+	case "Perms":
+		return &lp.Perms
+	case "PermPresets":
+		return &lp.PermPresets
+	case "HumanLanguages":
+		return &lp.HumanLanguages
+	case "UserAgents":
+		return &lp.UserAgents
+	case "OperatingSystems":
+		return &lp.OperatingSystems
+	}
+	return nil
+}
 
-		log.Print("Adding the '" + langPack.Name + "' language pack")
-		langPacks.Store(langPack.Name, &langPack)
-		langPackCount++
-
+// Phrases returns the phrases in group, it's nil if that group doesn't exist
+func (lp *LanguagePack) Phrases(group string) map[string]string {
+	m := lp.group(group)
+	if m == nil {
 		return nil
+	}
+	return *m
+}
+
+// IsChanged is true when the phrase has been changed or added from the Control Panel
+func (lp *LanguagePack) IsChanged(group, name string) bool {
+	_, ok := lp.Overrides[group][name]
+	return ok
+}
+
+// IsCustom is true when the phrase was added from the Control Panel, rather than coming from a language pack
+func (lp *LanguagePack) IsCustom(group, name string) bool {
+	if !lp.IsChanged(group, name) {
+		return false
+	}
+	for _, b := range []*LanguagePack{lp.base, lp.fallback} {
+		if b == nil {
+			continue
+		}
+		if _, ok := (*b.group(group))[name]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// AddPhrase adds a phrase which isn't in the language pack, e.g. for a template in a custom theme
+func AddPhrase(pack, group, name, phrase string) error {
+	langPackMutex.Lock()
+	defer langPackMutex.Unlock()
+	lp, err := editablePack(pack, group, name)
+	if err != nil {
+		return err
+	}
+	if _, ok := lp.Phrases(group)[name]; ok {
+		return ErrPhraseExists
+	}
+	return changeOverrides(pack, func(o map[string]map[string]string) {
+		setOverride(o, group, name, phrase)
 	})
+}
+
+// UpdatePhrase changes a phrase, dropping the change if it's been changed back to the one in the language pack
+func UpdatePhrase(pack, group, name, phrase string) error {
+	langPackMutex.Lock()
+	defer langPackMutex.Unlock()
+	lp, err := editablePack(pack, group, name)
 	if err != nil {
 		return err
 	}
-	if langPackCount == 0 {
-		return errors.New("You don't have any language packs")
+	if _, ok := lp.Phrases(group)[name]; !ok {
+		return ErrNoPhrase
 	}
+	return changeOverrides(pack, func(o map[string]map[string]string) {
+		if orig, ok := (*basePacks[pack].group(group))[name]; ok && orig == phrase {
+			deleteOverride(o, group, name)
+		} else {
+			setOverride(o, group, name, phrase)
+		}
+	})
+}
 
-	langPack, ok := langPacks.Load(lang)
+// DeletePhrase removes a phrase added with AddPhrase or reverts one changed with UpdatePhrase back to the one in the language pack
+func DeletePhrase(pack, group, name string) error {
+	langPackMutex.Lock()
+	defer langPackMutex.Unlock()
+	lp, err := editablePack(pack, group, name)
+	if err != nil {
+		return err
+	}
+	if !lp.IsChanged(group, name) {
+		if _, ok := lp.Phrases(group)[name]; ok {
+			return ErrPhraseBuiltin
+		}
+		return ErrNoPhrase
+	}
+	return changeOverrides(pack, func(o map[string]map[string]string) {
+		deleteOverride(o, group, name)
+	})
+}
+
+func editablePack(pack, group, name string) (*LanguagePack, error) {
+	lp, ok := GetLanguagePackByName(pack)
 	if !ok {
-		return errors.New("Couldn't find the " + lang + " language pack")
+		return nil, ErrNoLangPack
+	}
+	if lp.group(group) == nil {
+		return nil, ErrNoPhraseGroup
+	}
+	if name == "" {
+		return nil, ErrBlankPhraseName
+	}
+	return lp, nil
+}
+
+// changeOverrides applies h to a copy of the pack's overrides, so the built packs which are still being used by requests aren't touched
+func changeOverrides(pack string, h func(o map[string]map[string]string)) error {
+	o := make(map[string]map[string]string)
+	for group, phrases := range langOverrides[pack] {
+		o[group] = make(map[string]string)
+		for name, phrase := range phrases {
+			o[group][name] = phrase
+		}
+	}
+	h(o)
+	err := saveOverrides(pack, o)
+	if err != nil {
+		return err
+	}
+	langOverrides[pack] = o
+	langOverrideTimes[pack] = time.Now()
+	return rebuildLangPacks()
+}
+
+func setOverride(o map[string]map[string]string, group, name, phrase string) {
+	if o[group] == nil {
+		o[group] = make(map[string]string)
+	}
+	o[group][name] = phrase
+}
+
+func deleteOverride(o map[string]map[string]string, group, name string) {
+	delete(o[group], name)
+	if len(o[group]) == 0 {
+		delete(o, group)
 	}
-	currentLangPack.Store(langPack)
-	return nil
 }
 
 // TODO: Implement this
@@ -179,12 +473,118 @@ func GetLangPack() *LanguagePack {
 	return currentLangPack.Load().(*LanguagePack)
 }
 
-func GetLevelPhrase(level int) string {
-	levelPhrases := currentLangPack.Load().(*LanguagePack).Levels
-	if len(levelPhrases.Levels) > 0 && level < len(levelPhrases.Levels) {
-		return strings.Replace(levelPhrases.Levels[level], "{0}", strconv.Itoa(level), -1)
+// GetLangPacks returns all of the language packs sorted by name
+func GetLangPacks() (packs []*LanguagePack) {
+	langPacks.Range(func(_, lp interface{}) bool {
+		packs = append(packs, lp.(*LanguagePack))
+		return true
+	})
+	sort.Slice(packs, func(i, j int) bool {
+		return packs[i].Name < packs[j].Name
+	})
+	return packs
+}
+
+// LangPacker is implemented by the page structs, so the templates know which language they're being rendered in
+type LangPacker interface {
+	LangPack() *LanguagePack
+}
+
+// LangPackOf returns the language pack for a page, falling back on the default one
+func LangPackOf(pi interface{}) *LanguagePack {
+	if lpr, ok := pi.(LangPacker); ok {
+		if lp := lpr.LangPack(); lp != nil {
+			return lp
+		}
+	}
+	return GetLangPack()
+}
+
+// MatchAcceptLanguage picks the language pack which best fits an Accept-Language header, it returns nil if none of them do
+func MatchAcceptLanguage(header string) *LanguagePack {
+	type langRange struct {
+		tag string
+		q   float64
+	}
+	var ranges []langRange
+	for _, part := range strings.Split(header, ",") {
+		bits := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(bits[0]))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range bits[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			f, err := strconv.ParseFloat(param[2:], 64)
+			if err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, langRange{tag, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	primary := func(tag string) string {
+		return strings.Split(tag, "-")[0]
+	}
+	packs := GetLangPacks()
+	for _, r := range ranges {
+		for _, lp := range packs {
+			if strings.ToLower(lp.IsoCode) == r.tag {
+				return lp
+			}
+		}
+		// en-GB is close enough for someone asking for en-US
+		for _, lp := range packs {
+			if primary(strings.ToLower(lp.IsoCode)) == primary(r.tag) {
+				return lp
+			}
+		}
+	}
+	return nil
+}
+
+func (lp *LanguagePack) LevelPhrase(level int) string {
+	if len(lp.Levels.Levels) > 0 && level < len(lp.Levels.Levels) {
+		return strings.Replace(lp.Levels.Levels[level], "{0}", strconv.Itoa(level), -1)
 	}
-	return strings.Replace(levelPhrases.Level, "{0}", strconv.Itoa(level), -1)
+	return strings.Replace(lp.Levels.Level, "{0}", strconv.Itoa(level), -1)
+}
+
+func (lp *LanguagePack) TmplPhrase(name string) string {
+	res, ok := lp.TmplPhrases[name]
+	if !ok {
+		return getPlaceholder("tmpl", name)
+	}
+	return res
+}
+
+func (lp *LanguagePack) TmplPhrasef(name string, params ...interface{}) string {
+	res, ok := lp.TmplPhrases[name]
+	if !ok {
+		return getPlaceholder("tmpl", name)
+	}
+	return fmt.Sprintf(res, params...)
+}
+
+func (lp *LanguagePack) NoticePhrase(name string) string {
+	res, ok := lp.NoticePhrases[name]
+	if !ok {
+		return getPlaceholder("notices", name)
+	}
+	return res
+}
+
+func GetLevelPhrase(level int) string {
+	return currentLangPack.Load().(*LanguagePack).LevelPhrase(level)
 }
 
 func GetPermPhrase(name string) string {
@@ -260,11 +660,7 @@ func GetErrorPhraseBytes(name string) []byte {
 }
 
 func GetNoticePhrase(name string) string {
-	res, ok := currentLangPack.Load().(*LanguagePack).NoticePhrases[name]
-	if !ok {
-		return getPlaceholder("notices", name)
-	}
-	return res
+	return currentLangPack.Load().(*LanguagePack).NoticePhrase(name)
 }
 
 func GetTitlePhrase(name string) string {
@@ -284,19 +680,11 @@ func GetTitlePhrasef(name string, params ...interface{}) string {
 }
 
 func GetTmplPhrase(name string) string {
-	res, ok := currentLangPack.Load().(*LanguagePack).TmplPhrases[name]
-	if !ok {
-		return getPlaceholder("tmpl", name)
-	}
-	return res
+	return currentLangPack.Load().(*LanguagePack).TmplPhrase(name)
 }
 
 func GetTmplPhrasef(name string, params ...interface{}) string {
-	res, ok := currentLangPack.Load().(*LanguagePack).TmplPhrases[name]
-	if !ok {
-		return getPlaceholder("tmpl", name)
-	}
-	return fmt.Sprintf(res, params...)
+	return currentLangPack.Load().(*LanguagePack).TmplPhrasef(name, params...)
 }
 
 func GetTmplPhrases() map[string]string {
@@ -320,18 +708,6 @@ func GetCurrentLangPack() *LanguagePack {
 	return currentLangPack.Load().(*LanguagePack)
 }
 
-// ? - Use runtime reflection for updating phrases?
-// TODO: Implement these
-func AddPhrase() {
-
-}
-func UpdatePhrase() {
-
-}
-func DeletePhrase() {
-
-}
-
 // TODO: Use atomics to store the pointer of the current active langpack?
 // nolint
 func ChangeLanguagePack(name string) (exists bool) {
@@ -365,15 +741,3 @@ func RegisterTmplPhraseNames(phraseNames []string) (tmplID int) {
 func GetTmplPhrasesBytes(tmplID int) [][]byte {
 	return currentLangPack.Load().(*LanguagePack).TmplIndicesToPhrases[tmplID]
 }
-
-// New
-
-var indexCallbacks []func([][]byte)
-
-func TmplIndexCallback(tmplID int, phraseSet [][]byte) {
-	indexCallbacks[tmplID](phraseSet)
-}
-
-func AddTmplIndexCallback(h func([][]byte)) {
-	indexCallbacks = append(indexCallbacks, h)
-}
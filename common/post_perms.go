@@ -0,0 +1,77 @@
+package common
+
+import "time"
+
+// These work off the permissions in u.Perms, so the forum permissions have to be applied to u first, e.g. with SimpleForumUserCheck.
+// The Own permissions let people do things to their own posts which they can't do to everyone else's, the edits and deletes are limited to the windows in the settings.
+
+// OwnPostEditWindow is how long people have to edit their own posts after making them, zero means there isn't a limit
+func OwnPostEditWindow() time.Duration {
+	return ownPostWindow("own_post_edit_window")
+}
+
+// OwnPostDeleteWindow is how long people have to delete their own posts after making them, zero means there isn't a limit
+func OwnPostDeleteWindow() time.Duration {
+	return ownPostWindow("own_post_delete_window")
+}
+
+func ownPostWindow(setting string) time.Duration {
+	mins, _ := SettingBox.Load().(SettingMap)[setting].(int)
+	if mins < 0 {
+		mins = 0
+	}
+	return time.Duration(mins) * time.Minute
+}
+
+// InOwnPostWindow tells you whether a post made at createdAt is still inside window
+func InOwnPostWindow(createdAt time.Time, window time.Duration) bool {
+	return window == 0 || time.Since(createdAt) < window
+}
+
+func (u *User) owns(createdBy int) bool {
+	return u.ID != 0 && u.ID == createdBy
+}
+
+// CanViewTopic tells you whether u can see a topic started by createdBy
+func (u *User) CanViewTopic(createdBy int) bool {
+	return u.Perms.ViewTopic || (u.Perms.ViewOwnTopic && u.owns(createdBy))
+}
+
+// CanReplyTo tells you whether u can reply to a topic started by createdBy, this doesn't cover whether the topic is closed
+func (u *User) CanReplyTo(createdBy int) bool {
+	return u.CanViewTopic(createdBy) && (u.Perms.CreateReply || (u.Perms.CreateReplyToOwn && u.owns(createdBy)))
+}
+
+// CanCloseTopic tells you whether u can lock or unlock a topic started by createdBy
+func (u *User) CanCloseTopic(createdBy int) bool {
+	return u.CanViewTopic(createdBy) && (u.Perms.CloseTopic || (u.Perms.CloseOwnTopic && u.owns(createdBy)))
+}
+
+func (u *User) CanEditTopic(createdBy int, createdAt time.Time) bool {
+	if !u.CanViewTopic(createdBy) {
+		return false
+	}
+	return u.Perms.EditTopic || (u.Perms.EditOwnTopic && u.owns(createdBy) && InOwnPostWindow(createdAt, OwnPostEditWindow()))
+}
+
+func (u *User) CanDeleteTopic(createdBy int, createdAt time.Time) bool {
+	if !u.CanViewTopic(createdBy) {
+		return false
+	}
+	return u.Perms.DeleteTopic || (u.Perms.DeleteOwnTopic && u.owns(createdBy) && InOwnPostWindow(createdAt, OwnPostDeleteWindow()))
+}
+
+// CanEditReply tells you whether u can edit a reply made by createdBy at createdAt, topicCreatedBy is the person who started the topic it's in
+func (u *User) CanEditReply(topicCreatedBy, createdBy int, createdAt time.Time) bool {
+	if !u.CanViewTopic(topicCreatedBy) {
+		return false
+	}
+	return u.Perms.EditReply || (u.Perms.EditOwnReply && u.owns(createdBy) && InOwnPostWindow(createdAt, OwnPostEditWindow()))
+}
+
+func (u *User) CanDeleteReply(topicCreatedBy, createdBy int, createdAt time.Time) bool {
+	if !u.CanViewTopic(topicCreatedBy) {
+		return false
+	}
+	return u.Perms.DeleteReply || (u.Perms.DeleteOwnReply && u.owns(createdBy) && InOwnPostWindow(createdAt, OwnPostDeleteWindow()))
+}
@@ -0,0 +1,433 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// ProfileFieldTypes is the list of field types which an admin can pick from when creating a profile field
+var ProfileFieldTypes = []string{"text", "textarea", "url", "select", "date", "number"}
+
+var ErrProfileFieldRequired = errors.New("This field is required")
+var ErrProfileFieldTooLong = errors.New("This field is too long")
+var ErrProfileFieldBadURL = errors.New("This field must be a valid http or https URL")
+var ErrProfileFieldBadOption = errors.New("That isn't one of the options for this field")
+var ErrProfileFieldBadDate = errors.New("This field must be a date in the format YYYY-MM-DD")
+var ErrProfileFieldBadNumber = errors.New("This field must be a number")
+
+// ProfileFieldMaxLength is the hard limit on the length of a field value, regardless of what the admin has set
+const ProfileFieldMaxLength = 2000
+
+// ProfileField is a custom field defined by an admin which users can fill out on their profiles
+type ProfileField struct {
+	ID          int
+	Name        string
+	Type        string   // text, textarea, url, select, date, number
+	Options     []string // The choices for select fields
+	Required    bool
+	MaxLength   int   // 0 = ProfileFieldMaxLength
+	Groups      []int // The groups which are able to see this field, an empty list means everyone
+	Privacy     int   // The default privacy for values of this field, 1 = public, 2 = registered, 3 = self
+	ShowOnPosts bool
+	Order       int
+}
+
+// ProfileFieldValue is the value a user has filled out for a particular field
+type ProfileFieldValue struct {
+	Field   *ProfileField
+	Value   string
+	Privacy int // 0 = field default, 1 = public, 2 = registered, 3 = self
+}
+
+// ProfileFieldShow is a field value which has passed the visibility checks and is ready to be shown
+type ProfileFieldShow struct {
+	ID    int
+	Name  string
+	Value string
+	URL   string
+}
+
+// ProfileFieldEdit is used for building the field list on the account page
+type ProfileFieldEdit struct {
+	*ProfileField
+	Value   string
+	Privacy int
+}
+
+func (f *ProfileField) getRawOptions() string {
+	return strings.Join(f.Options, "\n")
+}
+
+func (f *ProfileField) getRawGroups() (raw string) {
+	for _, gid := range f.Groups {
+		raw += strconv.Itoa(gid) + ","
+	}
+	if len(raw) > 0 {
+		raw = raw[:len(raw)-1]
+	}
+	return raw
+}
+
+// Validate normalises val and checks it against the rules for this field. An empty string is only an error if the field is required.
+func (f *ProfileField) Validate(val string) (string, error) {
+	val = strings.TrimSpace(val)
+	if f.Type != "textarea" {
+		val = strings.Replace(val, "\n", "", -1)
+		val = strings.Replace(val, "\r", "", -1)
+	}
+	if val == "" {
+		if f.Required {
+			return "", ErrProfileFieldRequired
+		}
+		return "", nil
+	}
+	max := f.MaxLength
+	if max <= 0 || max > ProfileFieldMaxLength {
+		max = ProfileFieldMaxLength
+	}
+	if utf8.RuneCountInString(val) > max {
+		return "", ErrProfileFieldTooLong
+	}
+
+	switch f.Type {
+	case "url":
+		u, err := url.Parse(val)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", ErrProfileFieldBadURL
+		}
+		val = u.String()
+	case "select":
+		for _, opt := range f.Options {
+			if opt == val {
+				return val, nil
+			}
+		}
+		return "", ErrProfileFieldBadOption
+	case "date":
+		if _, err := time.Parse("2006-01-02", val); err != nil {
+			return "", ErrProfileFieldBadDate
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(val, 64); err != nil {
+			return "", ErrProfileFieldBadNumber
+		}
+	}
+	return val, nil
+}
+
+// CanSee determines whether viewer is allowed to see the value owner has set for this field
+func (f *ProfileField) CanSee(owner, viewer *User, privacy int) bool {
+	if owner.ID == viewer.ID || viewer.IsSuperMod {
+		return true
+	}
+	if len(f.Groups) > 0 {
+		var found bool
+		for _, gid := range f.Groups {
+			if gid == viewer.Group {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if privacy == 0 {
+		privacy = f.Privacy
+	}
+	switch privacy {
+	case 3: // self
+		return false
+	case 2: // registered
+		return viewer.Loggedin
+	}
+	return true
+}
+
+func (f *ProfileField) show(val string) *ProfileFieldShow {
+	s := &ProfileFieldShow{ID: f.ID, Name: f.Name, Value: val}
+	if f.Type == "url" {
+		s.URL = val
+	}
+	return s
+}
+
+var ProfileFields ProfileFieldStore
+
+type ProfileFieldStore interface {
+	ReloadAll() error
+	GetAll() []*ProfileField
+	Get(id int) (*ProfileField, error)
+	Create(f *ProfileField) (int, error)
+	Update(f *ProfileField) error
+	Delete(id int) error
+	Length() int
+	Count() (count int)
+
+	Values(uid int) (map[int]*ProfileFieldValue, error)
+	SetValue(uid, pfid int, val string, privacy int) error
+	DeleteValues(uid int) error
+	Show(owner, viewer *User) ([]*ProfileFieldShow, error)
+	BulkShowOnPosts(uids []int, viewer *User) (map[int][]*ProfileFieldShow, error)
+}
+
+type profileFieldSet struct {
+	list []*ProfileField
+	m    map[int]*ProfileField
+}
+
+type DefaultProfileFieldStore struct {
+	box atomic.Value // An atomic value holding a *profileFieldSet
+
+	getAll *sql.Stmt
+	create *sql.Stmt
+	update *sql.Stmt
+	delete *sql.Stmt
+	count  *sql.Stmt
+
+	getValues     *sql.Stmt
+	insertValue   *sql.Stmt
+	deleteValue   *sql.Stmt
+	deleteValues  *sql.Stmt
+	deleteByField *sql.Stmt
+}
+
+func NewDefaultProfileFieldStore(acc *qgen.Accumulator) (*DefaultProfileFieldStore, error) {
+	pf, upf := "profile_fields", "users_profile_fields"
+	s := &DefaultProfileFieldStore{
+		getAll: acc.Select(pf).Columns("pfid,name,type,options,required,maxLength,allowedGroups,privacy,showOnPosts,order").Orderby("order ASC,pfid ASC").Prepare(),
+		create: acc.Insert(pf).Columns("name,type,options,required,maxLength,allowedGroups,privacy,showOnPosts,order").Fields("?,?,?,?,?,?,?,?,?").Prepare(),
+		update: acc.Update(pf).Set("name=?,type=?,options=?,required=?,maxLength=?,allowedGroups=?,privacy=?,showOnPosts=?,order=?").Where("pfid=?").Prepare(),
+		delete: acc.Delete(pf).Where("pfid=?").Prepare(),
+		count:  acc.Count(pf).Prepare(),
+
+		getValues:     acc.Select(upf).Columns("pfid,value,privacy").Where("uid=?").Prepare(),
+		insertValue:   acc.Insert(upf).Columns("uid,pfid,value,privacy").Fields("?,?,?,?").Prepare(),
+		deleteValue:   acc.Delete(upf).Where("uid=? AND pfid=?").Prepare(),
+		deleteValues:  acc.Delete(upf).Where("uid=?").Prepare(),
+		deleteByField: acc.Delete(upf).Where("pfid=?").Prepare(),
+	}
+	if acc.FirstError() == nil {
+		acc.RecordError(s.ReloadAll())
+	}
+	return s, acc.FirstError()
+}
+
+// ReloadAll drops all the fields in the memory cache and replaces them with fresh copies from the database
+func (s *DefaultProfileFieldStore) ReloadAll() error {
+	set := &profileFieldSet{m: make(map[int]*ProfileField)}
+	rows, err := s.getAll.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f := &ProfileField{}
+		var rawOptions, rawGroups string
+		err := rows.Scan(&f.ID, &f.Name, &f.Type, &rawOptions, &f.Required, &f.MaxLength, &rawGroups, &f.Privacy, &f.ShowOnPosts, &f.Order)
+		if err != nil {
+			return err
+		}
+		if rawOptions != "" {
+			f.Options = strings.Split(rawOptions, "\n")
+		}
+		if rawGroups != "" {
+			for _, sgid := range strings.Split(rawGroups, ",") {
+				gid, err := strconv.Atoi(sgid)
+				if err != nil {
+					return err
+				}
+				f.Groups = append(f.Groups, gid)
+			}
+		}
+		set.list = append(set.list, f)
+		set.m[f.ID] = f
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	s.box.Store(set)
+	return nil
+}
+
+// GetAll returns every field ordered by position. Do not mutate the returned slice or the fields within it.
+func (s *DefaultProfileFieldStore) GetAll() []*ProfileField {
+	return s.box.Load().(*profileFieldSet).list
+}
+
+func (s *DefaultProfileFieldStore) Get(id int) (*ProfileField, error) {
+	f, ok := s.box.Load().(*profileFieldSet).m[id]
+	if !ok {
+		return f, ErrNoRows
+	}
+	return f, nil
+}
+
+// Create adds a new profile field to the database and refreshes the memory cache
+func (s *DefaultProfileFieldStore) Create(f *ProfileField) (int, error) {
+	res, err := s.create.Exec(f.Name, f.Type, f.getRawOptions(), f.Required, f.MaxLength, f.getRawGroups(), f.Privacy, f.ShowOnPosts, f.Order)
+	if err != nil {
+		return 0, err
+	}
+	id64, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id64), s.ReloadAll()
+}
+
+func (s *DefaultProfileFieldStore) Update(f *ProfileField) error {
+	_, err := s.update.Exec(f.Name, f.Type, f.getRawOptions(), f.Required, f.MaxLength, f.getRawGroups(), f.Privacy, f.ShowOnPosts, f.Order, f.ID)
+	if err != nil {
+		return err
+	}
+	return s.ReloadAll()
+}
+
+// Delete removes a profile field along with every value users have set for it
+func (s *DefaultProfileFieldStore) Delete(id int) error {
+	_, err := s.delete.Exec(id)
+	if err != nil {
+		return err
+	}
+	_, err = s.deleteByField.Exec(id)
+	if err != nil {
+		return err
+	}
+	return s.ReloadAll()
+}
+
+// Length gets the number of profile fields currently in memory, for the DefaultProfileFieldStore, this should be all of them
+func (s *DefaultProfileFieldStore) Length() int {
+	return len(s.box.Load().(*profileFieldSet).list)
+}
+
+// Count gets the total number of profile fields directly from the database
+func (s *DefaultProfileFieldStore) Count() (count int) {
+	err := s.count.QueryRow().Scan(&count)
+	if err != nil {
+		LogError(err)
+	}
+	return count
+}
+
+// Values returns the values uid has set, keyed by the field ID. Values for fields which no longer exist are skipped.
+func (s *DefaultProfileFieldStore) Values(uid int) (map[int]*ProfileFieldValue, error) {
+	vals := make(map[int]*ProfileFieldValue)
+	set := s.box.Load().(*profileFieldSet)
+	rows, err := s.getValues.Query(uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pfid int
+		v := &ProfileFieldValue{}
+		if err := rows.Scan(&pfid, &v.Value, &v.Privacy); err != nil {
+			return nil, err
+		}
+		f, ok := set.m[pfid]
+		if !ok {
+			continue
+		}
+		v.Field = f
+		vals[pfid] = v
+	}
+	return vals, rows.Err()
+}
+
+// SetValue replaces the value uid has for pfid, an empty value removes it. The value is expected to have already gone through ProfileField.Validate.
+func (s *DefaultProfileFieldStore) SetValue(uid, pfid int, val string, privacy int) error {
+	_, err := s.deleteValue.Exec(uid, pfid)
+	if err != nil || val == "" {
+		return err
+	}
+	_, err = s.insertValue.Exec(uid, pfid, val, privacy)
+	return err
+}
+
+// DeleteValues removes every field value belonging to uid, e.g. when their account is deleted
+func (s *DefaultProfileFieldStore) DeleteValues(uid int) error {
+	_, err := s.deleteValues.Exec(uid)
+	return err
+}
+
+// Show returns the values on owner's profile which viewer is allowed to see, in field order
+func (s *DefaultProfileFieldStore) Show(owner, viewer *User) (out []*ProfileFieldShow, err error) {
+	if s.Length() == 0 {
+		return nil, nil
+	}
+	vals, err := s.Values(owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range s.GetAll() {
+		v, ok := vals[f.ID]
+		if !ok || !f.CanSee(owner, viewer, v.Privacy) {
+			continue
+		}
+		out = append(out, f.show(v.Value))
+	}
+	return out, nil
+}
+
+// BulkShowOnPosts returns the values flagged to be shown next to posts for each of the users in uids which viewer is allowed to see, keyed by user ID
+func (s *DefaultProfileFieldStore) BulkShowOnPosts(uids []int, viewer *User) (map[int][]*ProfileFieldShow, error) {
+	set := s.box.Load().(*profileFieldSet)
+	var any bool
+	for _, f := range set.list {
+		if f.ShowOnPosts {
+			any = true
+			break
+		}
+	}
+	if !any || len(uids) == 0 {
+		return nil, nil
+	}
+
+	vals := make(map[int]map[int]*ProfileFieldValue)
+	err := qgen.NewAcc().Select("users_profile_fields").Columns("uid,pfid,value,privacy").In("uid", uids).Each(func(rows *sql.Rows) error {
+		var uid, pfid int
+		v := &ProfileFieldValue{}
+		if err := rows.Scan(&uid, &pfid, &v.Value, &v.Privacy); err != nil {
+			return err
+		}
+		f, ok := set.m[pfid]
+		if !ok || !f.ShowOnPosts {
+			return nil
+		}
+		v.Field = f
+		if vals[uid] == nil {
+			vals[uid] = make(map[int]*ProfileFieldValue)
+		}
+		vals[uid][pfid] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[int][]*ProfileFieldShow)
+	for uid, uvals := range vals {
+		// CanSee only needs the owner ID, so we don't have to load every user here
+		owner := &User{ID: uid}
+		for _, f := range set.list {
+			v, ok := uvals[f.ID]
+			if !ok || !f.CanSee(owner, viewer, v.Privacy) {
+				continue
+			}
+			out[uid] = append(out[uid], f.show(v.Value))
+		}
+	}
+	return out, nil
+}
@@ -32,10 +32,10 @@ func NewDefaultRecalc(acc *qgen.Accumulator) (*DefaultRecalc, error) {
 		getActivitySubscriptions: acc.Select("activity_subscriptions").Columns("targetID,targetType").Prepare(),
 		getActivityStream:        acc.Select("activity_stream").Columns("asid,event,elementID,elementType,extra").Prepare(),
 		getAttachments:           acc.Select("attachments").Columns("attachID,originID,originTable").Prepare(),
-		getTopicCount:            acc.Count("topics").Where("parentID=?").Prepare(),
+		getTopicCount:            acc.Count("topics").Where("parentID=? AND deleted=0").Prepare(),
 		//resetTopicCount:          acc.SimpleUpdateSelect("forums", "topicCount = tc", "topics", "count(*) as tc", "parentID=?", "", ""),
 		// TODO: Avoid using RawPrepare
-		resetTopicCount: acc.RawPrepare("UPDATE forums, (SELECT COUNT(*) as tc FROM topics WHERE parentID=?) AS src SET forums.topicCount=src.tc WHERE forums.fid=?"),
+		resetTopicCount: acc.RawPrepare("UPDATE forums SET topicCount=(SELECT COUNT(*) FROM topics WHERE parentID=? AND deleted=0) WHERE fid=?"),
 	}, acc.FirstError()
 }
 
@@ -33,8 +33,14 @@ type ReplyUser struct {
 	Level      int
 	ActionIcon string
 
-	Attachments []*MiniAttachment
-	Deletable   bool
+	Attachments   []*MiniAttachment
+	Deletable     bool
+	Editable      bool
+	ProfileFields []*ProfileFieldShow
+
+	Upvoted   bool // Whether the current user has upvoted this reply
+	Downvoted bool
+	Accepted  bool // Whether this reply is the accepted answer to the topic
 }
 
 type Reply struct {
@@ -52,6 +58,8 @@ type Reply struct {
 	LikeCount    int
 	AttachCount  uint16
 	ActionType   string
+	Votes        int
+	Deleted      bool // Whether this reply has been moved into the trash
 }
 
 var ErrAlreadyLiked = errors.New("You already liked this!")
@@ -61,11 +69,13 @@ type ReplyStmts struct {
 	isLiked                *sql.Stmt
 	createLike             *sql.Stmt
 	edit                   *sql.Stmt
+	setEdited              *sql.Stmt
 	setPoll                *sql.Stmt
 	delete                 *sql.Stmt
 	addLikesToReply        *sql.Stmt
 	removeRepliesFromTopic *sql.Stmt
 	deleteLikesForReply    *sql.Stmt
+	clearAnswer            *sql.Stmt
 	deleteActivity         *sql.Stmt
 	deleteActivitySubs     *sql.Stmt
 
@@ -82,16 +92,18 @@ func init() {
 			isLiked:                acc.Select("likes").Columns("targetItem").Where("sentBy=? and targetItem=? and targetType='replies'").Prepare(),
 			createLike:             acc.Insert("likes").Columns("weight,targetItem,targetType,sentBy,createdAt").Fields("?,?,?,?,UTC_TIMESTAMP()").Prepare(),
 			edit:                   acc.Update(re).Set("content=?,parsed_content=?").Where("rid=? AND poll=0").Prepare(),
+			setEdited:              acc.Update(re).Set("lastEdit=?,lastEditBy=?").Where("rid=?").Prepare(),
 			setPoll:                acc.Update(re).Set("poll=?").Where("rid=? AND poll=0").Prepare(),
 			delete:                 acc.Delete(re).Where("rid=?").Prepare(),
 			addLikesToReply:        acc.Update(re).Set("likeCount=likeCount+?").Where("rid=?").Prepare(),
 			removeRepliesFromTopic: acc.Update("topics").Set("postCount=postCount-?").Where("tid=?").Prepare(),
 			deleteLikesForReply:    acc.Delete("likes").Where("targetItem=? AND targetType='replies'").Prepare(),
+			clearAnswer:            acc.Update("topics").Set("answer=0").Where("tid=? AND answer=?").Prepare(),
 			deleteActivity:         acc.Delete("activity_stream").Where("elementID=? AND elementType='post'").Prepare(),
 			deleteActivitySubs:     acc.Delete("activity_subscriptions").Where("targetID=? AND targetType='post'").Prepare(),
 
 			// TODO: Optimise this to avoid firing an update if it's not the last reply in a topic. We will need to set lastReplyID properly in other places and in the patcher first so we can use it here.
-			updateTopicReplies:  acc.RawPrepare("UPDATE topics t INNER JOIN replies r ON t.tid=r.tid SET t.lastReplyBy=r.createdBy, t.lastReplyAt=r.createdAt, t.lastReplyID=r.rid WHERE t.tid=?"),
+			updateTopicReplies:  acc.RawPrepare(updateTopicRepliesQ(acc)),
 			updateTopicReplies2: acc.Update("topics").Set("lastReplyAt=createdAt,lastReplyBy=createdBy,lastReplyID=0").Where("postCount=1 AND tid=?").Prepare(),
 
 			getAidsOfReply: acc.Select("attachments").Columns("attachID").Where("originID=? AND originTable='replies'").Prepare(),
@@ -100,6 +112,14 @@ func init() {
 	})
 }
 
+// SQLite and PostgreSQL don't have multi-table updates, so they have to pull the last reply out with a sub-query instead
+func updateTopicRepliesQ(acc *qgen.Accumulator) string {
+	if name := acc.GetAdapter().GetName(); name == "sqlite" || name == "pgsql" {
+		return "UPDATE topics SET (lastReplyBy,lastReplyAt,lastReplyID)=(SELECT createdBy,createdAt,rid FROM replies WHERE replies.tid=topics.tid ORDER BY rid DESC LIMIT 1) WHERE tid=? AND EXISTS(SELECT 1 FROM replies WHERE replies.tid=topics.tid)"
+	}
+	return "UPDATE topics t INNER JOIN replies r ON t.tid=r.tid SET t.lastReplyBy=r.createdBy, t.lastReplyAt=r.createdAt, t.lastReplyID=r.rid WHERE t.tid=?"
+}
+
 // TODO: Write tests for this
 // TODO: Wrap these queries in a transaction to make sure the state is consistent
 func (r *Reply) Like(uid int) (err error) {
@@ -142,29 +162,43 @@ func (r *Reply) Unlike(uid int) error {
 
 // TODO: Refresh topic list?
 func (r *Reply) Delete() error {
-	creator, err := Users.Get(r.CreatedBy)
-	if err == nil {
-		err = creator.DecreasePostStats(WordCount(r.Content), false)
-		if err != nil {
+	// Replies in the trash have already been taken off the post counts
+	if !r.Deleted {
+		creator, err := Users.Get(r.CreatedBy)
+		if err == nil {
+			err = creator.DecreasePostStats(WordCount(r.Content), false)
+			if err != nil {
+				return err
+			}
+		} else if err != ErrNoRows {
 			return err
 		}
-	} else if err != ErrNoRows {
-		return err
 	}
 
-	_, err = replyStmts.delete.Exec(r.ID)
+	_, err := replyStmts.delete.Exec(r.ID)
 	if err != nil {
 		return err
 	}
-	// TODO: Move this bit to *Topic
-	_, err = replyStmts.removeRepliesFromTopic.Exec(1, r.ParentID)
+	err = Revisions.DeleteByContent(r.ID, "replies")
 	if err != nil {
 		return err
 	}
+	// TODO: Move this bit to *Topic
+	if !r.Deleted {
+		_, err = replyStmts.removeRepliesFromTopic.Exec(1, r.ParentID)
+		if err != nil {
+			return err
+		}
+	}
 	_, err = replyStmts.updateTopicReplies.Exec(r.ParentID)
 	if err != nil {
 		return err
 	}
+	// An answer which doesn't exist anymore can't solve anything
+	_, err = replyStmts.clearAnswer.Exec(r.ParentID, r.ID)
+	if err != nil {
+		return err
+	}
 	_, err = replyStmts.updateTopicReplies2.Exec(r.ParentID)
 	tc := Topics.GetCache()
 	if tc != nil {
@@ -178,6 +212,10 @@ func (r *Reply) Delete() error {
 	if err != nil {
 		return err
 	}
+	err = Votes.DeleteAll(r.ID)
+	if err != nil {
+		return err
+	}
 	err = handleReplyAttachments(r.ID)
 	if err != nil {
 		return err
@@ -194,18 +232,41 @@ func (r *Reply) Delete() error {
 	return err
 }
 
-func (r *Reply) SetPost(content string) error {
+// SetPost changes the content of this reply, the old version is kept as a revision. uid is the person making the edit and ip is where they made it from.
+func (r *Reply) SetPost(content, ip string, uid int) error {
 	topic, err := r.Topic()
 	if err != nil {
 		return err
 	}
 	content = PreparseMessage(html.UnescapeString(content))
 	parsedContent := ParseMessage(content, topic.ParentID, "forums", nil, nil)
-	_, err = replyStmts.edit.Exec(content, parsedContent, r.ID) // TODO: Sniff if this changed anything to see if we hit an existing poll
+	res, err := replyStmts.edit.Exec(content, parsedContent, r.ID)
+	if err == nil && content != r.Content {
+		err = r.addRevision(res, content, ip, uid)
+	}
 	_ = Rstore.GetCache().Remove(r.ID)
 	return err
 }
 
+func (r *Reply) addRevision(res sql.Result, content, ip string, uid int) error {
+	// Replies with polls can't be edited, so there isn't anything to record
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	orig := &Revision{ContentID: r.ID, ContentType: "replies", Content: r.Content, CreatedBy: r.CreatedBy, CreatedAt: r.CreatedAt, IP: r.IP}
+	err := addRevision(orig, "", content, ip, uid)
+	if err != nil {
+		return err
+	}
+	_, err = replyStmts.setEdited.Exec(int(time.Now().Unix()), uid, r.ID)
+	return err
+}
+
+// Revert puts this reply back the way it was in rev, this is recorded as another edit, so it can be undone
+func (r *Reply) Revert(rev *Revision, ip string, uid int) error {
+	return r.SetPost(rev.Content, ip, uid)
+}
+
 // TODO: Write tests for this
 func (r *Reply) SetPoll(pollID int) error {
 	_, err := replyStmts.setPoll.Exec(pollID, r.ID) // TODO: Sniff if this changed anything to see if we hit a poll
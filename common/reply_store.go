@@ -42,13 +42,13 @@ func NewSQLReplyStore(acc *qgen.Accumulator, cache ReplyCache) (*SQLReplyStore,
 	re := "replies"
 	return &SQLReplyStore{
 		cache:         cache,
-		get:           acc.Select(re).Columns("tid,content,createdBy,createdAt,lastEdit,lastEditBy,ip,likeCount,attachCount,actionType").Where("rid=?").Prepare(),
-		getAll:        acc.Select(re).Columns("rid,tid,content,createdBy,createdAt,lastEdit,lastEditBy,ip,likeCount,attachCount,actionType").Prepare(),
+		get:           acc.Select(re).Columns("tid,content,createdBy,createdAt,lastEdit,lastEditBy,ip,likeCount,attachCount,actionType,votes,deleted").Where("rid=?").Prepare(),
+		getAll:        acc.Select(re).Columns("rid,tid,content,createdBy,createdAt,lastEdit,lastEditBy,ip,likeCount,attachCount,actionType,votes,deleted").Prepare(),
 		exists:        acc.Exists(re, "rid").Prepare(),
 		create:        acc.Insert(re).Columns("tid,content,parsed_content,createdAt,lastUpdated,ip,words,createdBy").Fields("?,?,?,UTC_TIMESTAMP(),UTC_TIMESTAMP(),?,?,?").Prepare(),
 		count:         acc.Count(re).Prepare(),
-		countUser:     acc.Count(re).Where("createdBy=?").Prepare(),
-		countWordUser: acc.Count(re).Where("createdBy=? AND words>=?").Prepare(),
+		countUser:     acc.Count(re).Where("createdBy=? AND deleted=0").Prepare(),
+		countWordUser: acc.Count(re).Where("createdBy=? AND words>=? AND deleted=0").Prepare(),
 	}, acc.FirstError()
 }
 
@@ -59,7 +59,7 @@ func (s *SQLReplyStore) Get(id int) (*Reply, error) {
 	}
 
 	r = &Reply{ID: id}
-	err = s.get.QueryRow(id).Scan(&r.ParentID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.IP, &r.LikeCount, &r.AttachCount, &r.ActionType)
+	err = s.get.QueryRow(id).Scan(&r.ParentID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.IP, &r.LikeCount, &r.AttachCount, &r.ActionType, &r.Votes, &r.Deleted)
 	if err == nil {
 		_ = s.cache.Set(r)
 	}
@@ -78,7 +78,7 @@ func (s *SQLReplyStore) Each(f func(*Reply) error) error {
 	defer rows.Close()
 	for rows.Next() {
 		r := new(Reply)
-		if err := rows.Scan(&r.ID, &r.ParentID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.IP, &r.LikeCount, &r.AttachCount, &r.ActionType); err != nil {
+		if err := rows.Scan(&r.ID, &r.ParentID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.IP, &r.LikeCount, &r.AttachCount, &r.ActionType, &r.Votes, &r.Deleted); err != nil {
 			return err
 		}
 		if err := f(r); err != nil {
@@ -0,0 +1,151 @@
+package common
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// Revision is a version of a topic or reply, the first one is the post as it was made and each edit adds another
+type Revision struct {
+	ID          int
+	ContentID   int
+	ContentType string // topics or replies
+	Title       string // Only topics have titles
+	Content     string
+	CreatedBy   int // The person who made this edit, or the author for the original
+	CreatedAt   time.Time
+	IP          string
+}
+
+// Link is the page where the revisions of the post this revision belongs to can be compared
+func (r *Revision) Link() string {
+	if r.ContentType == "topics" {
+		return "/topic/revisions/" + strconv.Itoa(r.ContentID)
+	}
+	return "/reply/revisions/" + strconv.Itoa(r.ContentID)
+}
+
+var Revisions RevisionStore
+
+// RevisionStore holds the past versions of topics and replies
+type RevisionStore interface {
+	Get(id int) (*Revision, error)
+	GetList(contentID int, contentType string) ([]*Revision, error)
+	Count(contentID int, contentType string) (count int)
+	Create(contentID int, contentType, title, content, ip string, createdBy int, createdAt time.Time) (int, error)
+	DeleteByContent(contentID int, contentType string) error
+	DeleteByTopic(tid int) error
+}
+
+type DefaultRevisionStore struct {
+	get             *sql.Stmt
+	getList         *sql.Stmt
+	count           *sql.Stmt
+	create          *sql.Stmt
+	deleteByContent *sql.Stmt
+	getTopicReplies *sql.Stmt
+}
+
+func NewDefaultRevisionStore(acc *qgen.Accumulator) (*DefaultRevisionStore, error) {
+	re := "revisions"
+	return &DefaultRevisionStore{
+		get:             acc.Select(re).Columns("contentID,contentType,title,content,createdBy,createdAt,ip").Where("reviseID=?").Prepare(),
+		getList:         acc.Select(re).Columns("reviseID,title,content,createdBy,createdAt,ip").Where("contentID=? AND contentType=?").Orderby("reviseID ASC").Prepare(),
+		count:           acc.Count(re).Where("contentID=? AND contentType=?").Prepare(),
+		create:          acc.Insert(re).Columns("contentID,contentType,title,content,createdBy,createdAt,ip").Fields("?,?,?,?,?,?,?").Prepare(),
+		deleteByContent: acc.Delete(re).Where("contentID=? AND contentType=?").Prepare(),
+		getTopicReplies: acc.Select("replies").Columns("rid").Where("tid=? AND lastEdit!=0").Prepare(),
+	}, acc.FirstError()
+}
+
+func (s *DefaultRevisionStore) Get(id int) (*Revision, error) {
+	r := &Revision{ID: id}
+	err := s.get.QueryRow(id).Scan(&r.ContentID, &r.ContentType, &r.Title, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.IP)
+	return r, err
+}
+
+// GetList returns the revisions of a post, oldest first
+func (s *DefaultRevisionStore) GetList(contentID int, contentType string) (list []*Revision, err error) {
+	rows, err := s.getList.Query(contentID, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		r := &Revision{ContentID: contentID, ContentType: contentType}
+		err := rows.Scan(&r.ID, &r.Title, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.IP)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, r)
+	}
+	return list, rows.Err()
+}
+
+func (s *DefaultRevisionStore) Count(contentID int, contentType string) (count int) {
+	err := s.count.QueryRow(contentID, contentType).Scan(&count)
+	if err != nil {
+		LogError(err)
+	}
+	return count
+}
+
+func (s *DefaultRevisionStore) Create(contentID int, contentType, title, content, ip string, createdBy int, createdAt time.Time) (int, error) {
+	if Config.DisablePostIP {
+		ip = ""
+	}
+	res, err := s.create.Exec(contentID, contentType, title, content, createdBy, createdAt.UTC().Format("2006-01-02 15:04:05"), ip)
+	if err != nil {
+		return 0, err
+	}
+	lastID, err := res.LastInsertId()
+	return int(lastID), err
+}
+
+func (s *DefaultRevisionStore) DeleteByContent(contentID int, contentType string) error {
+	_, err := s.deleteByContent.Exec(contentID, contentType)
+	return err
+}
+
+// DeleteByTopic deletes the revisions of the replies in the topic tid, the ones of the topic itself are left alone
+func (s *DefaultRevisionStore) DeleteByTopic(tid int) error {
+	rows, err := s.getTopicReplies.Query(tid)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var rids []int
+	for rows.Next() {
+		var rid int
+		if err := rows.Scan(&rid); err != nil {
+			return err
+		}
+		rids = append(rids, rid)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, rid := range rids {
+		if err := s.DeleteByContent(rid, "replies"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addRevision records an edit to a post, the first edit also records the original, so that there's always something to compare an edit against
+func addRevision(orig *Revision, title, content, ip string, uid int) error {
+	if Revisions.Count(orig.ContentID, orig.ContentType) == 0 {
+		_, err := Revisions.Create(orig.ContentID, orig.ContentType, orig.Title, orig.Content, orig.IP, orig.CreatedBy, orig.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := Revisions.Create(orig.ContentID, orig.ContentType, title, content, ip, uid, time.Now())
+	return err
+}
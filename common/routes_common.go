@@ -51,6 +51,8 @@ func simpleForumUserCheck(w http.ResponseWriter, r *http.Request, u *User, fid i
 		return h, InternalError(err, w, r)
 	}
 	cascadeForumPerms(fperms, u)
+	cascadeForumMod(fid, u)
+	categoryForumPerms(fid, u)
 	return h, nil
 }
 
@@ -79,6 +81,8 @@ func forumUserCheck(h *Header, w http.ResponseWriter, r *http.Request, u *User,
 		return InternalError(err, w, r)
 	}
 	cascadeForumPerms(fperms, u)
+	cascadeForumMod(fid, u)
+	categoryForumPerms(fid, u)
 	h.CurrentUser = u // TODO: Use a pointer instead for CurrentUser, so we don't have to do this
 	return rerr
 }
@@ -87,15 +91,22 @@ func forumUserCheck(h *Header, w http.ResponseWriter, r *http.Request, u *User,
 func cascadeForumPerms(fp *ForumPerms, u *User) {
 	if fp.Overrides && !u.IsSuperAdmin {
 		u.Perms.ViewTopic = fp.ViewTopic
+		u.Perms.ViewOwnTopic = fp.ViewOwnTopic
 		u.Perms.LikeItem = fp.LikeItem
 		u.Perms.CreateTopic = fp.CreateTopic
 		u.Perms.EditTopic = fp.EditTopic
+		u.Perms.EditOwnTopic = fp.EditOwnTopic
 		u.Perms.DeleteTopic = fp.DeleteTopic
+		u.Perms.DeleteOwnTopic = fp.DeleteOwnTopic
 		u.Perms.CreateReply = fp.CreateReply
+		u.Perms.CreateReplyToOwn = fp.CreateReplyToOwn
 		u.Perms.EditReply = fp.EditReply
+		u.Perms.EditOwnReply = fp.EditOwnReply
 		u.Perms.DeleteReply = fp.DeleteReply
+		u.Perms.DeleteOwnReply = fp.DeleteOwnReply
 		u.Perms.PinTopic = fp.PinTopic
 		u.Perms.CloseTopic = fp.CloseTopic
+		u.Perms.CloseOwnTopic = fp.CloseOwnTopic
 		u.Perms.MoveTopic = fp.MoveTopic
 
 		if len(fp.ExtData) != 0 {
@@ -106,6 +117,21 @@ func cascadeForumPerms(fp *ForumPerms, u *User) {
 	}
 }
 
+// Forum moderators get their moderation permissions on top of the ones their group has here
+func cascadeForumMod(fid int, u *User) {
+	if mp, ok := ForumMods.PermsFor(fid, u); ok {
+		mp.Apply(u)
+	}
+}
+
+// Categories only hold other forums, so there's nowhere for a topic to go, not even for superadmins
+func categoryForumPerms(fid int, u *User) {
+	if Forums.DirtyGet(fid).Category {
+		u.Perms.CreateTopic = false
+		u.Perms.MoveTopic = false
+	}
+}
+
 // Even if they have the right permissions, the control panel is only open to supermods+. There are many areas without subpermissions which assume that the current user is a supermod+ and admins are extremely unlikely to give these permissions to someone who isn't at-least a supermod to begin with
 // TODO: Do a panel specific theme?
 func panelUserCheck(w http.ResponseWriter, r *http.Request, u *User) (h *Header, stats PanelStats, rerr RouteError) {
@@ -118,11 +144,14 @@ func panelUserCheck(w http.ResponseWriter, r *http.Request, u *User) (h *Header,
 		CurrentUser: u,
 		Hooks:       GetHookTable(),
 		Zone:        "panel",
+		RequestPath: r.URL.Path,
 		Writer:      w,
-		IsoCode:     phrases.GetLangPack().IsoCode,
 		//StartedAt:   time.Now(),
 		StartedAt: uutils.Nanotime(),
 	}
+	h.ThemeStyle = theme.UserStyleFor(u)
+	h.Lang = GetLangPackByReq(r, u)
+	h.IsoCode = h.Lang.IsoCode
 	// TODO: We should probably initialise header.ExtData
 	// ? - Should we only show this in debug mode? It might be useful for detecting issues in production, if we show it there as-well
 	//if user.IsAdmin {
@@ -191,6 +220,23 @@ func simpleUserCheck(w http.ResponseWriter, r *http.Request, u *User) (lite *Hea
 	}, nil
 }
 
+// GetLangPackByReq picks the language the user chose for their account, then the one their browser asks for, falling back on the site's language
+func GetLangPackByReq(r *http.Request, u *User) *phrases.LanguagePack {
+	if u.Lang != "" {
+		if lp, ok := phrases.GetLanguagePackByName(u.Lang); ok {
+			return lp
+		}
+	}
+	if !Config.DisableAcceptLanguage {
+		if al := r.Header.Get("Accept-Language"); al != "" {
+			if lp := phrases.MatchAcceptLanguage(al); lp != nil {
+				return lp
+			}
+		}
+	}
+	return phrases.GetLangPack()
+}
+
 func GetThemeByReq(r *http.Request) *Theme {
 	theme := &Theme{Name: ""}
 	cookie, err := r.Cookie("current_theme")
@@ -200,6 +246,14 @@ func GetThemeByReq(r *http.Request) *Theme {
 			theme = inTheme
 		}
 	}
+	// The theme editor shows the theme it's working on in a frame, without switching the theme the admin is using
+	if r.URL.RawQuery != "" {
+		if name := r.URL.Query().Get("preview_theme"); name != "" {
+			if inTheme, ok := Themes[name]; ok {
+				theme = inTheme
+			}
+		}
+	}
 	if theme.Name == "" {
 		theme = Themes[DefaultThemeBox.Load().(string)]
 	}
@@ -223,14 +277,22 @@ func userCheck2(w http.ResponseWriter, r *http.Request, u *User, nano int64) (h
 		CurrentUser: u, // ! Some things rely on this being a pointer downstream from this function
 		Hooks:       GetHookTable(),
 		Zone:        "frontend",
+		RequestPath: r.URL.Path,
 		Writer:      w,
-		IsoCode:     phrases.GetLangPack().IsoCode,
 		StartedAt:   nano,
 	}
 	// TODO: Optimise this by avoiding accessing a map string index
 	if !u.Loggedin {
 		h.GoogSiteVerify = h.Settings["google_site_verify"].(string)
 	}
+	// The theme editor's preview frame is the only place our pages are meant to be framed, so only let it through there
+	if u.Perms.ManageThemes && r.URL.RawQuery != "" && r.URL.Query().Get("preview_theme") == theme.Name {
+		w.Header().Set("X-Frame-Options", "sameorigin")
+	}
+
+	h.ThemeStyle = theme.UserStyleFor(u)
+	h.Lang = GetLangPackByReq(r, u)
+	h.IsoCode = h.Lang.IsoCode
 
 	if u.IsBanned {
 		h.AddNotice("account_banned")
@@ -0,0 +1,229 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var ScheduledTopics ScheduledTopicStore
+var ErrCantPublish = errors.New("The author of this topic isn't allowed to publish it anymore")
+
+// TopicCreatedAlias bumps the counters and runs the hooks for a new topic, it lives in the routes package next to the topic creation route, as the counters can't be reached from here
+var TopicCreatedAlias func(tid int, u *User) (skip bool, rerr RouteError)
+
+// ScheduledTopic is a topic which has been written in advance, it's kept out of the topics table until it's published, so it can't turn up in any of the lists, searches or alerts before then
+type ScheduledTopic struct {
+	ID        int
+	ParentID  int // The forum it'll be published in
+	Title     string
+	Content   string
+	CreatedBy int
+	IP        string
+	Prefix    int
+	Tags      string // Comma separated
+	PublishAt time.Time
+	CreatedAt time.Time
+}
+
+// CanScheduleTopic tells you whether u can write topics to be published later, these tend to be announcements, so it's left to the people who can pin them.
+// The forum permissions have to be applied to u first, e.g. with SimpleForumUserCheck.
+func (u *User) CanScheduleTopic() bool {
+	return u.Perms.CreateTopic && u.Perms.PinTopic
+}
+
+// canPublish tells you whether u is still allowed to put a scheduled topic in the forum fid, they might've been banned or demoted since writing it
+func canPublish(u *User, fid int) (bool, error) {
+	if !Forums.Exists(fid) {
+		return false, nil
+	}
+	ucpy := u.Copy()
+	fp, err := FPStore.Get(fid, ucpy.Group)
+	if err == ErrNoRows {
+		fp = BlankForumPerms()
+	} else if err != nil {
+		return false, err
+	}
+	cascadeForumPerms(fp, &ucpy)
+	cascadeForumMod(fid, &ucpy)
+	categoryForumPerms(fid, &ucpy)
+	return !ucpy.IsBanned && ucpy.CanScheduleTopic(), nil
+}
+
+// ScheduledTopicStore holds the topics waiting to be published and publishes them once their time comes
+type ScheduledTopicStore interface {
+	Get(id int) (*ScheduledTopic, error)
+	GetList(uid int) ([]*ScheduledTopic, error)
+	Create(st *ScheduledTopic) (int, error)
+	Delete(id int) error
+	Publish(st *ScheduledTopic) (tid int, err error)
+	PublishDue() error
+}
+
+type DefaultScheduledTopicStore struct {
+	get       *sql.Stmt
+	getAll    *sql.Stmt
+	getByUser *sql.Stmt
+	getDue    *sql.Stmt
+	create    *sql.Stmt
+	delete    *sql.Stmt
+}
+
+func NewDefaultScheduledTopicStore(acc *qgen.Accumulator) (*DefaultScheduledTopicStore, error) {
+	ts := "topics_scheduled"
+	cols := "stid,parentID,title,content,createdBy,ip,prefix,tags,publishAt,createdAt"
+	s := &DefaultScheduledTopicStore{
+		get:       acc.Select(ts).Columns(cols).Where("stid=?").Prepare(),
+		getAll:    acc.Select(ts).Columns(cols).Orderby("publishAt ASC").Prepare(),
+		getByUser: acc.Select(ts).Columns(cols).Where("createdBy=?").Orderby("publishAt ASC").Prepare(),
+		getDue:    acc.Select(ts).Columns(cols).Where("UTC_TIMESTAMP() >= publishAt").Orderby("publishAt ASC").Prepare(),
+		create:    acc.Insert(ts).Columns("parentID,title,content,createdBy,ip,prefix,tags,publishAt,createdAt").Fields("?,?,?,?,?,?,?,?,UTC_TIMESTAMP()").Prepare(),
+		delete:    acc.Delete(ts).Where("stid=?").Prepare(),
+	}
+	if err := acc.FirstError(); err != nil {
+		return nil, err
+	}
+	AddScheduledFifteenMinuteTask(s.PublishDue)
+	return s, nil
+}
+
+func (s *DefaultScheduledTopicStore) scan(row interface{ Scan(...interface{}) error }) (*ScheduledTopic, error) {
+	st := &ScheduledTopic{}
+	err := row.Scan(&st.ID, &st.ParentID, &st.Title, &st.Content, &st.CreatedBy, &st.IP, &st.Prefix, &st.Tags, &st.PublishAt, &st.CreatedAt)
+	return st, err
+}
+
+func (s *DefaultScheduledTopicStore) list(stmt *sql.Stmt, args ...interface{}) (list []*ScheduledTopic, err error) {
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		st, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, st)
+	}
+	return list, rows.Err()
+}
+
+func (s *DefaultScheduledTopicStore) Get(id int) (*ScheduledTopic, error) {
+	return s.scan(s.get.QueryRow(id))
+}
+
+// GetList returns the topics uid has waiting to be published, the soonest first, zero gets everyone's
+func (s *DefaultScheduledTopicStore) GetList(uid int) ([]*ScheduledTopic, error) {
+	if uid == 0 {
+		return s.list(s.getAll)
+	}
+	return s.list(s.getByUser, uid)
+}
+
+func (s *DefaultScheduledTopicStore) Create(st *ScheduledTopic) (int, error) {
+	if st.Title == "" {
+		return 0, ErrNoTitle
+	}
+	if len(st.Title) > Config.MaxTopicTitleLength {
+		return 0, ErrLongTitle
+	}
+	if strings.TrimSpace(st.Content) == "" {
+		return 0, ErrNoBody
+	}
+	if Config.DisablePostIP {
+		st.IP = ""
+	}
+	res, err := s.create.Exec(st.ParentID, st.Title, st.Content, st.CreatedBy, st.IP, st.Prefix, st.Tags, st.PublishAt.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	lastID, err := res.LastInsertId()
+	return int(lastID), err
+}
+
+func (s *DefaultScheduledTopicStore) Delete(id int) error {
+	_, err := s.delete.Exec(id)
+	return err
+}
+
+// Publish turns st into a real topic, tid is zero if another server got to it first
+func (s *DefaultScheduledTopicStore) Publish(st *ScheduledTopic) (tid int, err error) {
+	// Claim it first, so that if several servers get to it at once, only one of them publishes it
+	res, err := s.delete.Exec(st.ID)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return 0, err
+	}
+
+	// There's no one to hand it back to, if the author has been deleted
+	u, err := Users.Get(st.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+	// The forum or the author's permissions might have gone in the meantime, so hand it back to them as a draft rather than losing it
+	draft := func() {
+		if derr := Drafts.Save(st.CreatedBy, "forum", st.ParentID, st.Title, st.Content); derr != nil {
+			LogError(derr)
+		}
+	}
+	ok, err := canPublish(u, st.ParentID)
+	if err != nil {
+		return 0, err
+	} else if !ok {
+		draft()
+		return 0, ErrCantPublish
+	}
+	tid, err = Topics.Create(st.ParentID, st.Title, st.Content, st.CreatedBy, st.IP)
+	if err != nil {
+		draft()
+		return 0, err
+	}
+	if st.Prefix != 0 {
+		t, err := Topics.Get(tid)
+		if err != nil {
+			return tid, err
+		}
+		if err = t.SetPrefix(st.Prefix); err != nil {
+			return tid, err
+		}
+	}
+	if tags := ParseTags(st.Tags); len(tags) > 0 {
+		if err = TopicTags.Set(tid, tags); err != nil {
+			return tid, err
+		}
+	}
+	if err = Subscriptions.Add(st.CreatedBy, tid, "topic"); err != nil {
+		return tid, err
+	}
+	if err = u.IncreasePostStats(WordCount(st.Content), true); err != nil {
+		return tid, err
+	}
+	if TopicCreatedAlias != nil {
+		// There isn't a page to skip, so only the error matters here
+		if _, rerr := TopicCreatedAlias(tid, u); rerr != nil {
+			return tid, rerr
+		}
+	}
+	return tid, nil
+}
+
+// PublishDue publishes the topics whose time has come
+func (s *DefaultScheduledTopicStore) PublishDue() error {
+	due, err := s.list(s.getDue)
+	if err != nil {
+		return err
+	}
+	for _, st := range due {
+		if _, err := s.Publish(st); err != nil {
+			// One bad topic shouldn't hold up the rest
+			LogError(err)
+		}
+	}
+	return nil
+}
@@ -23,21 +23,40 @@ type SQLSearcher struct {
 	queryTopicsZone *sql.Stmt
 	//queryZone    *sql.Stmt
 	fuzzyZone *sql.Stmt
+
+	match func(col string) string
 }
 
-// TODO: Support things other than MySQL
+// TODO: Support MSSQL
 // TODO: Use LIMIT?
 func NewSQLSearcher(acc *qgen.Accumulator) (*SQLSearcher, error) {
-	if acc.GetAdapter().GetName() != "mysql" {
-		return nil, errors.New("SQLSearcher only supports MySQL at this time")
+	var match func(col string) string
+	switch acc.GetAdapter().GetName() {
+	case "mysql":
+		match = func(col string) string {
+			return "MATCH(" + col + ") AGAINST (? IN BOOLEAN MODE)"
+		}
+	case "sqlite":
+		// SQLite doesn't have full-text indices outside of virtual tables, so fall back to looking for the query as a substring
+		match = func(col string) string {
+			return "instr(" + col + ",?)>0"
+		}
+	case "pgsql":
+		// This has to line up with the expression the fulltext indices are built on
+		match = func(col string) string {
+			return "to_tsvector('simple'," + col + ") @@ plainto_tsquery('simple',?)"
+		}
+	default:
+		return nil, errors.New("SQLSearcher only supports MySQL, PostgreSQL and SQLite at this time")
 	}
 	return &SQLSearcher{
-		queryReplies: acc.RawPrepare("SELECT tid FROM replies WHERE MATCH(content) AGAINST (? IN BOOLEAN MODE)"),
-		queryTopics:  acc.RawPrepare("SELECT tid FROM topics WHERE MATCH(title) AGAINST (? IN BOOLEAN MODE) OR MATCH(content) AGAINST (? IN BOOLEAN MODE)"),
-		queryRepliesZone: acc.RawPrepare("SELECT tid FROM replies WHERE MATCH(content) AGAINST (? IN BOOLEAN MODE) AND tid=?"),
-		queryTopicsZone:  acc.RawPrepare("SELECT tid FROM topics WHERE (MATCH(title) AGAINST (? IN BOOLEAN MODE) OR MATCH(content) AGAINST (? IN BOOLEAN MODE)) AND parentID=?"),
+		queryReplies:     acc.RawPrepare("SELECT tid FROM replies WHERE " + match("content") + " AND deleted=0"),
+		queryTopics:      acc.RawPrepare("SELECT tid FROM topics WHERE (" + match("title") + " OR " + match("content") + ") AND deleted=0"),
+		queryRepliesZone: acc.RawPrepare("SELECT tid FROM replies WHERE " + match("content") + " AND tid=? AND deleted=0"),
+		queryTopicsZone:  acc.RawPrepare("SELECT tid FROM topics WHERE (" + match("title") + " OR " + match("content") + ") AND parentID=? AND deleted=0"),
 		//queryZone:    acc.RawPrepare("SELECT topics.tid FROM topics INNER JOIN replies ON topics.tid = replies.tid WHERE (topics.title=? OR (MATCH(topics.title) AGAINST (? IN BOOLEAN MODE) OR MATCH(topics.content) AGAINST (? IN BOOLEAN MODE) OR MATCH(replies.content) AGAINST (? IN BOOLEAN MODE)) OR topics.content=? OR replies.content=?) AND topics.parentID=?"),
-		fuzzyZone:    acc.RawPrepare("SELECT topics.tid FROM topics INNER JOIN replies ON topics.tid = replies.tid WHERE (topics.title LIKE ? OR topics.content LIKE ? OR replies.content LIKE ?) AND topics.parentID=?"),
+		fuzzyZone: acc.RawPrepare("SELECT topics.tid FROM topics INNER JOIN replies ON topics.tid = replies.tid WHERE (topics.title LIKE ? OR topics.content LIKE ? OR replies.content LIKE ?) AND topics.parentID=? AND topics.deleted=0 AND replies.deleted=0"),
+		match:     match,
 	}, acc.FirstError()
 }
 
@@ -121,7 +140,7 @@ func (s *SQLSearcher) Query(q string, zones []int) (ids []int, err error) {
 			return nil, err
 		}*/
 		// TODO: Cache common IN counts
-		stmt := acc.RawPrepare("SELECT tid FROM topics WHERE (MATCH(topics.title) AGAINST (? IN BOOLEAN MODE) OR MATCH(topics.content) AGAINST (? IN BOOLEAN MODE)) AND parentID IN(" + zList + ")")
+		stmt := acc.RawPrepare("SELECT tid FROM topics WHERE (" + s.match("topics.title") + " OR " + s.match("topics.content") + ") AND parentID IN(" + zList + ") AND deleted=0")
 		err = acc.FirstError()
 		if err != nil {
 			return nil, err
@@ -130,7 +149,7 @@ func (s *SQLSearcher) Query(q string, zones []int) (ids []int, err error) {
 		if err != nil {
 			return nil, err
 		}
-		stmt = acc.RawPrepare("SELECT tid FROM replies WHERE MATCH(replies.content) AGAINST (? IN BOOLEAN MODE) AND tid IN(" + zList + ")")
+		stmt = acc.RawPrepare("SELECT tid FROM replies WHERE " + s.match("replies.content") + " AND tid IN(" + zList + ") AND deleted=0")
 		err = acc.FirstError()
 		if err != nil {
 			return nil, err
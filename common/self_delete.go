@@ -0,0 +1,295 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var SelfDeletes SelfDeleteStore
+var selfDeleteStmts SelfDeleteStmts
+
+// The two ways an account can be closed by it's owner. Anonymising leaves the posts where they are under a blank account, so threads still make sense, while purging takes everything with it.
+const (
+	SelfDeleteAnonymise = "anonymise"
+	SelfDeletePurge     = "purge"
+)
+
+var ErrSelfDeleteMode = errors.New("That isn't a valid way of deleting an account.")
+var ErrSelfDeleteAdmin = errors.New("Administrators can't delete their own accounts, another administrator will have to take away their powers first.")
+
+type SelfDeleteStmts struct {
+	anonymise *sql.Stmt
+	stripIPs  []*sql.Stmt
+
+	deleteEmails      *sql.Stmt
+	deleteResets      *sql.Stmt
+	deleteBlocks      *sql.Stmt
+	deleteSubs        *sql.Stmt
+	deleteScheduled   *sql.Stmt
+	deleteConvoPosts  *sql.Stmt
+	deleteConvoMember *sql.Stmt
+}
+
+func init() {
+	DbInits.Add(func(acc *qgen.Accumulator) error {
+		stripIP := func(tbl, col string) *sql.Stmt {
+			return acc.Update(tbl).Set("ip=''").Where(col + "=? AND ip!=''").Prepare()
+		}
+		selfDeleteStmts = SelfDeleteStmts{
+			anonymise: acc.Update("users").Set("name=?,email='',password=?,salt='',group=?,active=0,session='',last_ip='',avatar='',message='',temp_group=0").Where("uid=?").Prepare(),
+			stripIPs: []*sql.Stmt{
+				stripIP("topics", "createdBy"),
+				stripIP("replies", "createdBy"),
+				stripIP("users_replies", "createdBy"),
+				stripIP("polls_votes", "uid"),
+			},
+
+			deleteEmails:      acc.Delete("emails").Where("uid=?").Prepare(),
+			deleteResets:      acc.Delete("password_resets").Where("uid=?").Prepare(),
+			deleteBlocks:      acc.Delete("users_blocks").Where("blocker=? OR blockedUser=?").Prepare(),
+			deleteSubs:        acc.Delete("activity_subscriptions").Where("user=?").Prepare(),
+			deleteScheduled:   acc.Delete("users_groups_scheduler").Where("uid=?").Prepare(),
+			deleteConvoPosts:  acc.Delete("conversations_posts").Where("createdBy=?").Prepare(),
+			deleteConvoMember: acc.Delete("conversations_participants").Where("uid=?").Prepare(),
+		}
+		return acc.FirstError()
+	})
+}
+
+// SelfDeleteGrace returns the number of days an account is kept around after it's owner asks for it to be deleted, so they have a chance to change their mind
+func SelfDeleteGrace() int {
+	if Config.SelfDeleteGracePeriod < 0 {
+		return 0
+	}
+	return Config.SelfDeleteGracePeriod
+}
+
+// Anonymise strips everything which could identify the user from their account and locks it, but leaves their posts and conversations where they are
+func (u *User) Anonymise() error {
+	if u.IsAdmin {
+		return ErrSelfDeleteAdmin
+	}
+	// Nothing can be hashed into this, so the account can't be logged into anymore
+	junk, err := GenerateSafeString(32)
+	if err != nil {
+		return err
+	}
+	_, err = selfDeleteStmts.anonymise.Exec("Deleted User "+strconv.Itoa(u.ID), "!"+junk, Config.ActivationGroup, u.ID)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range selfDeleteStmts.stripIPs {
+		_, err = stmt.Exec(u.ID)
+		if err != nil {
+			return err
+		}
+	}
+	err = u.deletePersonalData()
+	u.CacheRemove()
+	return err
+}
+
+// Purge deletes the user along with everything they've posted, including their side of the conversations they're in
+func (u *User) Purge() error {
+	if u.IsAdmin {
+		return ErrSelfDeleteAdmin
+	}
+	err := u.DeletePosts()
+	if err != nil {
+		return err
+	}
+	_, err = selfDeleteStmts.deleteConvoPosts.Exec(u.ID)
+	if err != nil {
+		return err
+	}
+	_, err = selfDeleteStmts.deleteConvoMember.Exec(u.ID)
+	if err != nil {
+		return err
+	}
+	err = u.deletePersonalData()
+	if err != nil {
+		return err
+	}
+	return u.Delete()
+}
+
+// deletePersonalData clears out the things which hang off of an account and only matter to the person who owns it
+func (u *User) deletePersonalData() error {
+	for _, stmt := range []*sql.Stmt{selfDeleteStmts.deleteEmails, selfDeleteStmts.deleteResets, selfDeleteStmts.deleteSubs, selfDeleteStmts.deleteScheduled} {
+		_, err := stmt.Exec(u.ID)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := selfDeleteStmts.deleteBlocks.Exec(u.ID, u.ID)
+	if err != nil {
+		return err
+	}
+	err = LoginLogs.DeleteUser(u.ID)
+	if err != nil {
+		return err
+	}
+	err = ProfileFields.DeleteValues(u.ID)
+	if err != nil {
+		return err
+	}
+	err = UserThemeSettings.DeleteAll(u.ID)
+	if err != nil {
+		return err
+	}
+	err = Drafts.DeleteAll(u.ID)
+	if err != nil {
+		return err
+	}
+	err = (&MFAItem{UID: u.ID}).Delete()
+	if err != nil {
+		return err
+	}
+	return removeAvatarFiles(u.ID)
+}
+
+// avatarFiles returns the avatar the user uploaded along with the resized copies made of it
+func avatarFiles(uid int) (files []string, err error) {
+	for _, pattern := range []string{".*", "_*"} {
+		matches, err := filepath.Glob("./uploads/avatar_" + strconv.Itoa(uid) + pattern)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+func removeAvatarFiles(uid int) error {
+	files, err := avatarFiles(uid)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err = os.Remove(file)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// SelfDeleteRequest is an account which is waiting out the grace period before it's anonymised or purged
+type SelfDeleteRequest struct {
+	UID         int
+	Mode        string
+	RequestedAt time.Time
+}
+
+// DueAt returns the time the account will be deleted after, it's processed with the other daily tasks, so it might be up to a day later
+func (d *SelfDeleteRequest) DueAt() time.Time {
+	return d.RequestedAt.AddDate(0, 0, SelfDeleteGrace())
+}
+
+// Run carries out the request right away
+func (d *SelfDeleteRequest) Run() error {
+	u, err := Users.Get(d.UID)
+	if err == ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if d.Mode == SelfDeletePurge {
+		return u.Purge()
+	}
+	return u.Anonymise()
+}
+
+// SelfDeleteStore keeps track of the users who've asked for their accounts to be deleted
+type SelfDeleteStore interface {
+	Get(uid int) (*SelfDeleteRequest, error)
+	Schedule(uid int, mode string) error
+	Cancel(uid int) error
+	Process(days int) (count int, err error)
+}
+
+type DefaultSelfDeleteStore struct {
+	get    *sql.Stmt
+	add    *sql.Stmt
+	delete *sql.Stmt
+	due    *sql.Stmt
+}
+
+func NewDefaultSelfDeleteStore(acc *qgen.Accumulator) (*DefaultSelfDeleteStore, error) {
+	sd := "users_self_deletes"
+	return &DefaultSelfDeleteStore{
+		get:    acc.Select(sd).Columns("mode,requestedAt").Where("uid=?").Prepare(),
+		add:    acc.Insert(sd).Columns("uid,mode,requestedAt").Fields("?,?,UTC_TIMESTAMP()").Prepare(),
+		delete: acc.Delete(sd).Where("uid=?").Prepare(),
+		due:    acc.Select(sd).Columns("uid,mode,requestedAt").DateOlderThanQ("requestedAt", "day").Prepare(),
+	}, acc.FirstError()
+}
+
+// Get returns the pending request for the user, or ErrNoRows if they haven't asked for their account to be deleted
+func (s *DefaultSelfDeleteStore) Get(uid int) (*SelfDeleteRequest, error) {
+	d := &SelfDeleteRequest{UID: uid}
+	err := s.get.QueryRow(uid).Scan(&d.Mode, &d.RequestedAt)
+	return d, err
+}
+
+// Schedule asks for the user's account to be deleted once the grace period is over, asking again replaces the earlier request and starts the clock over
+func (s *DefaultSelfDeleteStore) Schedule(uid int, mode string) error {
+	if mode != SelfDeleteAnonymise && mode != SelfDeletePurge {
+		return ErrSelfDeleteMode
+	}
+	err := s.Cancel(uid)
+	if err != nil {
+		return err
+	}
+	_, err = s.add.Exec(uid, mode)
+	return err
+}
+
+func (s *DefaultSelfDeleteStore) Cancel(uid int) error {
+	_, err := s.delete.Exec(uid)
+	return err
+}
+
+// Process runs the requests which were made more than days days ago
+func (s *DefaultSelfDeleteStore) Process(days int) (count int, err error) {
+	rows, err := s.due.Query(days)
+	if err != nil {
+		return 0, err
+	}
+	var reqs []*SelfDeleteRequest
+	for rows.Next() {
+		d := &SelfDeleteRequest{}
+		err = rows.Scan(&d.UID, &d.Mode, &d.RequestedAt)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		reqs = append(reqs, d)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, d := range reqs {
+		// Someone might have been made an administrator during the grace period, they'll have to ask again once they're a normal user
+		runErr := d.Run()
+		if runErr != nil && runErr != ErrSelfDeleteAdmin {
+			return count, runErr
+		}
+		err = s.Cancel(d.UID)
+		if err != nil {
+			return count, err
+		}
+		if runErr == nil {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -178,5 +178,6 @@ func (sBox SettingMap) Update(name, content string) RouteError {
 	if err != nil {
 		return SysError(err.Error())
 	}
+	ClusterPublish(ClusterSettings, 0)
 	return nil
 }
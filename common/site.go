@@ -83,6 +83,11 @@ type config struct {
 
 	Search string
 
+	ClusterDriver string   // How the servers tell each other about their changes when ServerCount is over one, either db or tcp. Defaults to db.
+	ClusterListen string   // The address the tcp driver listens for the other servers on, e.g. 10.0.0.1:9091
+	ClusterPeers  []string // The addresses the other servers are listening on for the tcp driver
+	ClusterSecret string   // The messages sent by the tcp driver are signed with this
+
 	DefaultPath     string
 	DefaultGroup    int    // Should be a setting in the database
 	ActivationGroup int    // Should be a setting in the database
@@ -91,18 +96,24 @@ type config struct {
 	MinifyTemplates bool
 	BuildSlugs      bool // TODO: Make this a setting?
 
-	PrimaryServer  bool
-	ServerCount    int
-	LastIPCutoff   int // Currently just -1, non--1, but will accept the number of months a user's last IP should be retained for in the future before being purged. Please note that the other two cutoffs below operate off the numbers of days instead.
-	PostIPCutoff   int
-	PollIPCutoff   int
-	LogPruneCutoff int
+	PrimaryServer         bool
+	ServerCount           int
+	LastIPCutoff          int // Currently just -1, non--1, but will accept the number of months a user's last IP should be retained for in the future before being purged. Please note that the other two cutoffs below operate off the numbers of days instead.
+	PostIPCutoff          int
+	PollIPCutoff          int
+	LogPruneCutoff        int
+	TrashPurgeCutoff      int
+	DraftExpiry           int // The number of days a draft which hasn't been touched is kept for
+	BackupInterval        int // The number of days between the automatic backups, they're off when this is zero
+	BackupRetention       int
+	SelfDeleteGracePeriod int // The number of days before an account the user asked to be deleted is deleted
 	//SelfDeleteTruncCutoff int // Personal data is stripped from the mod action rows only leaving the TID and the action for later investigation.
 
-	DisableLastIP bool
-	DisablePostIP bool
-	DisablePollIP bool
-	DisableRegLog bool
+	DisableLastIP     bool
+	DisablePostIP     bool
+	DisablePollIP     bool
+	DisableRegLog     bool
+	DisableSelfDelete bool
 	//DisableSelfDeleteLog bool
 
 	DisableLiveTopicList bool
@@ -121,6 +132,8 @@ type config struct {
 	RefNoRef   bool
 	NoEmbed    bool
 
+	DisableAcceptLanguage bool // Only use the languages the users pick on their accounts, rather than the ones their browsers ask for
+
 	ExtraCSPOrigins string
 	StaticResBase   string // /s/
 	//DynStaticResBase string
@@ -143,9 +156,10 @@ type devConfig struct {
 	Profiling     bool
 	TestDB        bool
 
-	NoFsnotify bool // Super Experimental!
-	FullReqLog bool
-	ExtraTmpls string // Experimental flag for adding compiled templates, we'll likely replace this with a better mechanism
+	NoFsnotify   bool // Super Experimental!
+	HotTemplates bool
+	FullReqLog   bool
+	ExtraTmpls   string // Experimental flag for adding compiled templates, we'll likely replace this with a better mechanism
 
 	//QuicPort int // Experimental!
 }
@@ -300,6 +314,18 @@ func ProcessConfig() (err error) {
 	if Config.LogPruneCutoff == 0 {
 		Config.LogPruneCutoff = 180 // Default cutoff
 	}
+	if Config.TrashPurgeCutoff == 0 {
+		Config.TrashPurgeCutoff = 30 // Default cutoff
+	}
+	if Config.DraftExpiry == 0 {
+		Config.DraftExpiry = 30
+	}
+	if Config.BackupRetention == 0 {
+		Config.BackupRetention = 7
+	}
+	if Config.SelfDeleteGracePeriod == 0 {
+		Config.SelfDeleteGracePeriod = 14
+	}
 	if Config.LastIPCutoff == 0 {
 		Config.LastIPCutoff = 3 // Default cutoff
 	}
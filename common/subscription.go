@@ -13,12 +13,15 @@ type SubscriptionStore interface {
 	Add(uid, elementID int, elementType string) error
 	Delete(uid, targetID int, targetType string) error
 	DeleteResource(targetID int, targetType string) error
+	MoveResource(fromID, toID int, targetType string) error
 }
 
 type DefaultSubscriptionStore struct {
 	add            *sql.Stmt
 	delete         *sql.Stmt
 	deleteResource *sql.Stmt
+	users          *sql.Stmt
+	move           *sql.Stmt
 }
 
 func NewDefaultSubscriptionStore() (*DefaultSubscriptionStore, error) {
@@ -28,6 +31,8 @@ func NewDefaultSubscriptionStore() (*DefaultSubscriptionStore, error) {
 		add:            acc.Insert(ast).Columns("user,targetID,targetType,level").Fields("?,?,?,2").Prepare(),
 		delete:         acc.Delete(ast).Where("user=? AND targetID=? AND targetType=?").Prepare(),
 		deleteResource: acc.Delete(ast).Where("targetID=? AND targetType=?").Prepare(),
+		users:          acc.Select(ast).Columns("user").Where("targetID=? AND targetType=?").Prepare(),
+		move:           acc.Update(ast).Set("targetID=?").Where("user=? AND targetID=? AND targetType=?").Prepare(),
 	}, acc.FirstError()
 }
 
@@ -46,3 +51,51 @@ func (s *DefaultSubscriptionStore) DeleteResource(targetID int, targetType strin
 	_, err := s.deleteResource.Exec(targetID, targetType)
 	return err
 }
+
+// MoveResource shifts the subscriptions on one resource over to another, e.g. when a topic is merged into another. Users who were subscribed to both only end up with the one subscription.
+func (s *DefaultSubscriptionStore) MoveResource(fromID, toID int, targetType string) error {
+	already := make(map[int]bool)
+	rows, err := s.users.Query(toID, targetType)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var uid int
+		if err := rows.Scan(&uid); err != nil {
+			return err
+		}
+		already[uid] = true
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = s.users.Query(fromID, targetType)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var uids []int
+	for rows.Next() {
+		var uid int
+		if err := rows.Scan(&uid); err != nil {
+			return err
+		}
+		uids = append(uids, uid)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	for _, uid := range uids {
+		if already[uid] {
+			_, err = s.delete.Exec(uid, fromID, targetType)
+		} else {
+			_, err = s.move.Exec(toID, uid, fromID, targetType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -8,15 +8,12 @@ package common
 
 import (
 	"database/sql"
-	"log"
-	"time"
 
 	"github.com/Azareal/Gosora/query_gen"
 )
 
 type TaskStmts struct {
 	getExpiredScheduledGroups *sql.Stmt
-	getSync                   *sql.Stmt
 }
 
 var ScheduledHalfSecondTasks []func() error
@@ -25,15 +22,12 @@ var ScheduledFifteenMinuteTasks []func() error
 var ScheduledHourTasks []func() error
 var ShutdownTasks []func() error
 var taskStmts TaskStmts
-var lastSync time.Time
 
 // TODO: Add a TaskInits.Add
 func init() {
-	lastSync = time.Now()
 	DbInits.Add(func(acc *qgen.Accumulator) error {
 		taskStmts = TaskStmts{
 			getExpiredScheduledGroups: acc.Select("users_groups_scheduler").Columns("uid").Where("UTC_TIMESTAMP() > revert_at AND temporary = 1").Prepare(),
-			getSync:                   acc.Select("sync").Columns("last_update").Prepare(),
 		}
 		return acc.FirstError()
 	})
@@ -111,40 +105,11 @@ func HandleExpiredScheduledGroups() error {
 	return rows.Err()
 }
 
-// TODO: Use AddScheduledSecondTask
-// TODO: Be a little more granular with the synchronisation
-// TODO: Synchronise more things
-// TODO: Does this even work?
+// HandleServerSync applies the changes the other servers in the cluster have made, for the cluster drivers which have to poll for them
 func HandleServerSync() error {
 	// We don't want to run any unnecessary queries when there is nothing to synchronise
 	if Config.ServerCount == 1 {
 		return nil
 	}
-
-	var lastUpdate time.Time
-	err := taskStmts.getSync.QueryRow().Scan(&lastUpdate)
-	if err != nil {
-		return err
-	}
-
-	if lastUpdate.After(lastSync) {
-		err = Forums.LoadForums()
-		if err != nil {
-			log.Print("Unable to reload the forums")
-			return err
-		}
-		// TODO: Resync the groups
-		// TODO: Resync the permissions
-		err = LoadSettings()
-		if err != nil {
-			log.Print("Unable to reload the settings")
-			return err
-		}
-		err = WordFilters.ReloadAll()
-		if err != nil {
-			log.Print("Unable to reload the word filters")
-			return err
-		}
-	}
-	return nil
+	return Cluster.Tick()
 }
@@ -52,7 +52,7 @@ func genIntTmpl(name string) func(pi interface{}, w io.Writer) error {
 		if !ok {
 			mapping = name
 		}
-		return DefaultTemplates.ExecuteTemplate(w, mapping+".html", pi)
+		return langTemplates(DefaultTemplates, "", pi).ExecuteTemplate(w, mapping+".html", pi)
 	}
 }
 
@@ -95,14 +95,14 @@ var Template_account_handle = genIntTmpl("account")
 
 func tmplInitUsers() (*User, *User, *User) {
 	avatar, microAvatar := BuildAvatar(62, "")
-	u := User{62, BuildProfileURL("fake-user", 62), "Fake User", "compiler@localhost", 0, false, false, false, false, false, false, GuestPerms, make(map[string]bool), "", false, "", avatar, microAvatar, "", "", 0, 0, 0, 0, StartTime, "0.0.0.0.0", 0, 0, nil, UserPrivacy{}}
+	u := User{62, BuildProfileURL("fake-user", 62), "Fake User", "compiler@localhost", 0, false, false, false, false, false, false, GuestPerms, make(map[string]bool), "", false, "", avatar, microAvatar, "", "", 0, 0, 0, 0, StartTime, "0.0.0.0.0", 0, 0, nil, UserPrivacy{}, ""}
 
 	// TODO: Do a more accurate level calculation for this?
 	avatar, microAvatar = BuildAvatar(1, "")
-	u2 := User{1, BuildProfileURL("admin-alice", 1), "Admin Alice", "alice@localhost", 1, true, true, true, true, false, false, AllPerms, make(map[string]bool), "", true, "", avatar, microAvatar, "", "", 58, 1000, 0, 1000, StartTime, "127.0.0.1", 0, 0, nil, UserPrivacy{}}
+	u2 := User{1, BuildProfileURL("admin-alice", 1), "Admin Alice", "alice@localhost", 1, true, true, true, true, false, false, AllPerms, make(map[string]bool), "", true, "", avatar, microAvatar, "", "", 58, 1000, 0, 1000, StartTime, "127.0.0.1", 0, 0, nil, UserPrivacy{}, ""}
 
 	avatar, microAvatar = BuildAvatar(2, "")
-	u3 := User{2, BuildProfileURL("admin-fred", 62), "Admin Fred", "fred@localhost", 1, true, true, true, true, false, false, AllPerms, make(map[string]bool), "", true, "", avatar, microAvatar, "", "", 42, 900, 0, 900, StartTime, "::1", 0, 0, nil, UserPrivacy{}}
+	u3 := User{2, BuildProfileURL("admin-fred", 62), "Admin Fred", "fred@localhost", 1, true, true, true, true, false, false, AllPerms, make(map[string]bool), "", true, "", avatar, microAvatar, "", "", 42, 900, 0, 900, StartTime, "::1", 0, 0, nil, UserPrivacy{}, ""}
 	return &u, &u2, &u3
 }
 
@@ -227,16 +227,17 @@ func compileCommons(c *tmpl.CTemplateSet, head, head2 *Header, forumList []Forum
 	}*/
 
 	var topicsList []TopicsRowMut
-	topic := Topic{1, "/topic/topic-title.1", "Topic Title", "The topic content.", 1, false, false, now, now, user3.ID, 1, 1, "", "::1", 1, 0, 1, 1, 1, "classname", 0, "", nil}
-	topicsList = append(topicsList, TopicsRowMut{&TopicsRow{topic, 1, user2, "", 0, user3, "General", "/forum/general.2"}, false})
-	topicListPage := TopicListPage{htitle("Topic List"), topicsList, forumList, Config.DefaultForum, TopicListSort{"lastupdated", false}, []int{1}, QuickTools{false, false, false}, Paginator{[]int{1}, 1, 1}}
+	topic := Topic{1, "/topic/topic-title.1", "Topic Title", "The topic content.", 1, false, false, now, now, user3.ID, 1, 1, "", "::1", 1, 0, 1, 1, 1, "classname", 0, "", 0, 0, false, 0, 0, nil}
+	topicsList = append(topicsList, TopicsRowMut{&TopicsRow{topic, 1, user2, "", 0, user3, "General", "/forum/general.2", "", ""}, false})
+	topicListPage := TopicListPage{htitle("Topic List"), topicsList, forumList, Config.DefaultForum, TopicListSort{"lastupdated", false}, []int{1}, QuickTools{false, false, false}, Paginator{[]int{1}, 1, 1}, TopicTag{}}
 	o.Add("topics", "c.TopicListPage", topicListPage)
 	o.Add("topics_mini", "c.TopicListPage", topicListPage)
 
 	forumItem := BlankForum(1, "general-forum.1", "General Forum", "Where the general stuff happens", true, "all", 0, "", 0)
-	forumPage := ForumPage{htitle("General Forum"), topicsList, forumItem, false, false, Paginator{[]int{1}, 1, 1}}
+	forumItems := []ForumListItem{{forumList[0], []*Forum{&forumList[0]}}}
+	forumPage := ForumPage{htitle("General Forum"), topicsList, forumItem, false, false, Paginator{[]int{1}, 1, 1}, []*Forum{forumItem}, forumItems, []*User{user2}}
 	o.Add("forum", "c.ForumPage", forumPage)
-	o.Add("forums", "c.ForumsPage", ForumsPage{htitle("Forum List"), forumList})
+	o.Add("forums", "c.ForumsPage", ForumsPage{htitle("Forum List"), []ForumCategory{{forumList[0], forumItems}}})
 
 	poll := Poll{ID: 1, Type: 0, Options: map[int]string{0: "Nothing", 1: "Something"}, Results: map[int]int{0: 5, 1: 2}, QuickOptions: []PollOption{
 		{0, "Nothing"},
@@ -244,15 +245,17 @@ func compileCommons(c *tmpl.CTemplateSet, head, head2 *Header, forumList []Forum
 	}, VoteCount: 7}
 	avatar, microAvatar := BuildAvatar(62, "")
 	miniAttach := []*MiniAttachment{{Path: "/"}}
-	tu := TopicUser{1, "blah", "Blah", "Hey there!", 0, false, false, now, now, 1, 1, 0, "", "127.0.0.1", 1, 0, 1, 0, "classname", poll.ID, "weird-data", BuildProfileURL("fake-user", 62), "Fake User", Config.DefaultGroup, avatar, microAvatar, 0, "", "", "", 58, false, miniAttach, nil, false}
+	sampleFields := []*ProfileFieldShow{{1, "Website", "example.com", "https://example.com"}}
+	tu := TopicUser{1, "blah", "Blah", "Hey there!", 0, false, false, now, now, 1, 1, 0, "", "127.0.0.1", 1, 0, 1, 0, "classname", poll.ID, "weird-data", BuildProfileURL("fake-user", 62), "Fake User", Config.DefaultGroup, avatar, microAvatar, 0, "", "", "", 58, false, miniAttach, nil, false, false, false, sampleFields, 0, "", "", TagsToTopicTags([]string{"example"}), 0, false, 0, 0}
 
 	var replyList []*ReplyUser
-	reply := Reply{1, 1, "Yo!", 1 /*, Config.DefaultGroup*/, now, 0, 0, 1, "::1", true, 1, 1, ""}
-	ru := &ReplyUser{ClassName: "", Reply: reply, CreatedByName: "Alice", Avatar: avatar, Group: Config.DefaultGroup, Level: 0, Attachments: miniAttach}
+	reply := Reply{1, 1, "Yo!", 1 /*, Config.DefaultGroup*/, now, 0, 0, 1, "::1", true, 1, 1, "", 0, false}
+	ru := &ReplyUser{ClassName: "", Reply: reply, CreatedByName: "Alice", Avatar: avatar, Group: Config.DefaultGroup, Level: 0, Attachments: miniAttach, ProfileFields: sampleFields}
 	ru.Init(user2)
 	replyList = append(replyList, ru)
-	tpage := TopicPage{htitle("Topic Name"), replyList, tu, &Forum{ID: 1, Name: "Hahaha"}, &poll, Paginator{[]int{1}, 1, 1}}
+	tpage := TopicPage{htitle("Topic Name"), replyList, tu, &Forum{ID: 1, Name: "Hahaha"}, &poll, Paginator{[]int{1}, 1, 1}, []*TopicPrefix{{ID: 1, Name: "Solved", CSSClass: "prefix_solved"}}, "", ru, false, false, true, true, nil}
 	tpage.Forum.Link = BuildForumURL(NameToSlug(tpage.Forum.Name), tpage.Forum.ID)
+	tpage.Breadcrumbs = []*Forum{tpage.Forum}
 	o.Add("topic", "c.TopicPage", tpage)
 	o.Add("topic_mini", "c.TopicPage", tpage)
 	o.Add("topic_alt", "c.TopicPage", tpage)
@@ -273,12 +276,13 @@ func compileTemplates(wg *sync.WaitGroup, c *tmpl.CTemplateSet, themeName string
 	}, VoteCount: 7}*/
 	//avatar, microAvatar := BuildAvatar(62, "")
 	miniAttach := []*MiniAttachment{{Path: "/"}}
+	sampleFields := []*ProfileFieldShow{{1, "Website", "example.com", "https://example.com"}}
 	var replyList []*ReplyUser
 	//topic := TopicUser{1, "blah", "Blah", "Hey there!", 0, false, false, now, now, 1, 1, 0, "", "127.0.0.1", 1, 0, 1, 0, "classname", poll.ID, "weird-data", BuildProfileURL("fake-user", 62), "Fake User", Config.DefaultGroup, avatar, microAvatar, 0, "", "", "", "", "", 58, false, miniAttach, nil}
 	// TODO: Do we want the UID on this to be 0?
 	//avatar, microAvatar = BuildAvatar(0, "")
-	reply := Reply{1, 1, "Yo!", 1 /*, Config.DefaultGroup*/, now, 0, 0, 1, "::1", true, 1, 1, ""}
-	ru := &ReplyUser{ClassName: "", Reply: reply, CreatedByName: "Alice", Avatar: "", Group: Config.DefaultGroup, Level: 0, Attachments: miniAttach}
+	reply := Reply{1, 1, "Yo!", 1 /*, Config.DefaultGroup*/, now, 0, 0, 1, "::1", true, 1, 1, "", 0, false}
+	ru := &ReplyUser{ClassName: "", Reply: reply, CreatedByName: "Alice", Avatar: "", Group: Config.DefaultGroup, Level: 0, Attachments: miniAttach, ProfileFields: sampleFields}
 	ru.Init(user)
 	replyList = append(replyList, ru)
 
@@ -307,16 +311,16 @@ func compileTemplates(wg *sync.WaitGroup, c *tmpl.CTemplateSet, themeName string
 		return err
 	}
 
-	ppage := ProfilePage{htitle("User 526"), replyList, *user, 0, 0, false, false, false, false} // TODO: Use the score from user to generate the currentScore and nextScore
+	ppage := ProfilePage{htitle("User 526"), replyList, *user, 0, 0, false, false, false, false, sampleFields} // TODO: Use the score from user to generate the currentScore and nextScore
 	t.Add("profile", "c.ProfilePage", ppage)
 
 	var topicsList []TopicsRowMut
-	topic := Topic{1, "topic-title", "Topic Title", "The topic content.", 1, false, false, now, now, user3.ID, 1, 1, "", "::1", 1, 0, 1, 1, 1, "classname", 0, "", nil}
-	topicsList = append(topicsList, TopicsRowMut{&TopicsRow{topic, 0, user2, "", 0, user3, "General", "/forum/general.2"}, false})
-	topicListPage := TopicListPage{htitle("Topic List"), topicsList, forumList, Config.DefaultForum, TopicListSort{"lastupdated", false}, []int{1}, QuickTools{false, false, false}, Paginator{[]int{1}, 1, 1}}
+	topic := Topic{1, "topic-title", "Topic Title", "The topic content.", 1, false, false, now, now, user3.ID, 1, 1, "", "::1", 1, 0, 1, 1, 1, "classname", 0, "", 0, 0, false, 0, 0, nil}
+	topicsList = append(topicsList, TopicsRowMut{&TopicsRow{topic, 0, user2, "", 0, user3, "General", "/forum/general.2", "", ""}, false})
+	topicListPage := TopicListPage{htitle("Topic List"), topicsList, forumList, Config.DefaultForum, TopicListSort{"lastupdated", false}, []int{1}, QuickTools{false, false, false}, Paginator{[]int{1}, 1, 1}, TopicTag{}}
 
 	forumItem := BlankForum(1, "general-forum.1", "General Forum", "Where the general stuff happens", true, "all", 0, "", 0)
-	forumPage := ForumPage{htitle("General Forum"), topicsList, forumItem, false, false, Paginator{[]int{1}, 1, 1}}
+	forumPage := ForumPage{htitle("General Forum"), topicsList, forumItem, false, false, Paginator{[]int{1}, 1, 1}, []*Forum{forumItem}, []ForumListItem{{forumList[0], []*Forum{&forumList[0]}}}, []*User{user2}}
 
 	// Experimental!
 	for _, tmpl := range strings.Split(Dev.ExtraTmpls, ",") {
@@ -540,8 +544,8 @@ func compileJSTemplates(wg *sync.WaitGroup, c *tmpl.CTemplateSet, themeName stri
 
 	t := TItemHold(make(map[string]TItem))
 
-	topic := Topic{1, "topic-title", "Topic Title", "The topic content.", 1, false, false, now, now, user3.ID, 1, 1, "", "::1", 1, 0, 1, 0, 1, "classname", 1, "", nil}
-	topicsRow := TopicsRowMut{&TopicsRow{topic, 0, user2, "", 0, user3, "General", "/forum/general.2"}, false}
+	topic := Topic{1, "topic-title", "Topic Title", "The topic content.", 1, false, false, now, now, user3.ID, 1, 1, "", "::1", 1, 0, 1, 0, 1, "classname", 1, "", 0, 0, false, 0, 0, nil}
+	topicsRow := TopicsRowMut{&TopicsRow{topic, 0, user2, "", 0, user3, "General", "/forum/general.2", "", ""}, false}
 	t.AddStd("topics_topic", "c.TopicsRowMut", topicsRow)
 
 	poll := Poll{ID: 1, Type: 0, Options: map[int]string{0: "Nothing", 1: "Something"}, Results: map[int]int{0: 5, 1: 2}, QuickOptions: []PollOption{
@@ -550,19 +554,21 @@ func compileJSTemplates(wg *sync.WaitGroup, c *tmpl.CTemplateSet, themeName stri
 	}, VoteCount: 7}
 	avatar, microAvatar := BuildAvatar(62, "")
 	miniAttach := []*MiniAttachment{{Path: "/"}}
-	tu := TopicUser{1, "blah", "Blah", "Hey there!", 62, false, false, now, now, 1, 1, 0, "", "::1", 1, 0, 1, 0, "classname", poll.ID, "weird-data", BuildProfileURL("fake-user", 62), "Fake User", Config.DefaultGroup, avatar, microAvatar, 0, "", "", "", 58, false, miniAttach, nil, false}
+	sampleFields := []*ProfileFieldShow{{1, "Website", "example.com", "https://example.com"}}
+	tu := TopicUser{1, "blah", "Blah", "Hey there!", 62, false, false, now, now, 1, 1, 0, "", "::1", 1, 0, 1, 0, "classname", poll.ID, "weird-data", BuildProfileURL("fake-user", 62), "Fake User", Config.DefaultGroup, avatar, microAvatar, 0, "", "", "", 58, false, miniAttach, nil, false, false, false, sampleFields, 0, "", "", TagsToTopicTags([]string{"example"}), 0, false, 0, 0}
 	var replyList []*ReplyUser
 	// TODO: Do we really want the UID here to be zero?
 	avatar, microAvatar = BuildAvatar(0, "")
-	reply := Reply{1, 1, "Yo!", 1 /*, Config.DefaultGroup*/, now, 0, 0, 1, "::1", true, 1, 1, ""}
-	ru := &ReplyUser{ClassName: "", Reply: reply, CreatedByName: "Alice", Avatar: avatar, Group: Config.DefaultGroup, Level: 0, Attachments: miniAttach}
+	reply := Reply{1, 1, "Yo!", 1 /*, Config.DefaultGroup*/, now, 0, 0, 1, "::1", true, 1, 1, "", 0, false}
+	ru := &ReplyUser{ClassName: "", Reply: reply, CreatedByName: "Alice", Avatar: avatar, Group: Config.DefaultGroup, Level: 0, Attachments: miniAttach, ProfileFields: sampleFields}
 	ru.Init(user)
 	replyList = append(replyList, ru)
 
 	varList = make(map[string]tmpl.VarItem)
 	header.Title = "Topic Name"
-	tpage := TopicPage{header, replyList, tu, &Forum{ID: 1, Name: "Hahaha"}, &poll, Paginator{[]int{1}, 1, 1}}
+	tpage := TopicPage{header, replyList, tu, &Forum{ID: 1, Name: "Hahaha"}, &poll, Paginator{[]int{1}, 1, 1}, []*TopicPrefix{{ID: 1, Name: "Solved", CSSClass: "prefix_solved"}}, "", ru, false, false, true, true, nil}
 	tpage.Forum.Link = BuildForumURL(NameToSlug(tpage.Forum.Name), tpage.Forum.ID)
+	tpage.Breadcrumbs = []*Forum{tpage.Forum}
 	t.AddStd("topic_posts", "c.TopicPage", tpage)
 	t.AddStd("topic_alt_posts", "c.TopicPage", tpage)
 
@@ -789,32 +795,8 @@ func initDefaultTmplFuncMap() {
 		return time.Duration(uutils.Nanotime() - startedAtInt.(int64)).String()
 	}
 
-	fmap["lang"] = func(phraseNameInt interface{}) interface{} {
-		phraseName, ok := phraseNameInt.(string)
-		if !ok {
-			panic("phraseNameInt is not a string")
-		}
-		// TODO: Log non-existent phrases?
-		return template.HTML(p.GetTmplPhrase(phraseName))
-	}
-
-	// TODO: Implement this in the template generator too
-	fmap["langf"] = func(phraseNameInt interface{}, args ...interface{}) interface{} {
-		phraseName, ok := phraseNameInt.(string)
-		if !ok {
-			panic("phraseNameInt is not a string")
-		}
-		// TODO: Log non-existent phrases?
-		// TODO: Optimise TmplPhrasef so we don't use slow Sprintf there
-		return template.HTML(p.GetTmplPhrasef(phraseName, args...))
-	}
-
-	fmap["level"] = func(levelInt interface{}) interface{} {
-		level, ok := levelInt.(int)
-		if !ok {
-			panic("levelInt is not an integer")
-		}
-		return template.HTML(p.GetLevelPhrase(level))
+	for name, f := range langTmplFuncs(p.GetLangPack) {
+		fmap[name] = f
 	}
 
 	fmap["bunit"] = func(byteInt interface{}) interface{} {
@@ -895,8 +877,81 @@ func initDefaultTmplFuncMap() {
 	DefaultTemplateFuncMap = fmap
 }
 
+// langTmplFuncs are the phrase functions for the interpreted templates, they get their phrases from the pack lp returns
+func langTmplFuncs(lp func() *p.LanguagePack) map[string]interface{} {
+	fmap := make(map[string]interface{})
+	fmap["lang"] = func(phraseNameInt interface{}) interface{} {
+		phraseName, ok := phraseNameInt.(string)
+		if !ok {
+			panic("phraseNameInt is not a string")
+		}
+		// TODO: Log non-existent phrases?
+		return template.HTML(lp().TmplPhrase(phraseName))
+	}
+
+	// TODO: Implement this in the template generator too
+	fmap["langf"] = func(phraseNameInt interface{}, args ...interface{}) interface{} {
+		phraseName, ok := phraseNameInt.(string)
+		if !ok {
+			panic("phraseNameInt is not a string")
+		}
+		// TODO: Log non-existent phrases?
+		// TODO: Optimise TmplPhrasef so we don't use slow Sprintf there
+		return template.HTML(lp().TmplPhrasef(phraseName, args...))
+	}
+
+	fmap["level"] = func(levelInt interface{}) interface{} {
+		level, ok := levelInt.(int)
+		if !ok {
+			panic("levelInt is not an integer")
+		}
+		return template.HTML(lp().LevelPhrase(level))
+	}
+	return fmap
+}
+
+type langTmplSet struct {
+	base *template.Template
+	lp   *p.LanguagePack
+	tmpl *template.Template
+}
+
+// The phrase functions are baked into a template set and can't be swapped out while it's running, so the interpreted templates are parsed again for each of the other languages
+var langTmplSets sync.Map // map[themeName:langName]*langTmplSet
+
+// langTemplates returns the copy of the interpreted template set base for the language the page pi is being shown in, base is used as-is for the site's language
+func langTemplates(base *template.Template, themeName string, pi interface{}) *template.Template {
+	lp := p.LangPackOf(pi)
+	if lp == p.GetLangPack() {
+		return base
+	}
+	key := themeName + ":" + lp.Name
+	if set, ok := langTmplSets.Load(key); ok {
+		// The set is stale if the phrases or the theme's overrides have changed since it was made
+		if set := set.(*langTmplSet); set.base == base && set.lp == lp {
+			return set.tmpl
+		}
+	}
+	t := template.New("")
+	err := loadTemplates(t, themeName)
+	if err != nil {
+		LogError(err, "Unable to load the templates for the "+lp.Name+" language pack")
+		return base
+	}
+	t.Funcs(langTmplFuncs(func() *p.LanguagePack {
+		return lp
+	}))
+	langTmplSets.Store(key, &langTmplSet{base, lp, t})
+	return t
+}
+
 func loadTemplates(t *template.Template, themeName string) error {
-	t.Funcs(DefaultTemplateFuncMap)
+	// The themes get a pared down set of functions, as their overrides are interpreted at runtime
+	if themeName != "" {
+		t.Funcs(SafeTemplateFuncMap)
+	} else {
+		t.Funcs(DefaultTemplateFuncMap)
+	}
 	tFiles, err := filepath.Glob("templates/*.html")
 	if err != nil {
 		return err
@@ -967,6 +1022,12 @@ func loadTemplates(t *template.Template, themeName string) error {
 		}
 		s := tmpl.Minify(string(b))
 		name := filepath.Base(fname)
+		if strings.HasPrefix(fname, "themes/") {
+			err = vetOverride(name, s)
+			if err != nil {
+				return err
+			}
+		}
 		var tmpl *template.Template
 		if name == t.Name() {
 			tmpl = t
@@ -985,6 +1046,7 @@ func loadTemplates(t *template.Template, themeName string) error {
 func InitTemplates() error {
 	DebugLog("Initialising the template system")
 	initDefaultTmplFuncMap()
+	initSafeTmplFuncMap()
 
 	// The interpreted templates...
 	DebugLog("Loading the template files...")
@@ -472,11 +472,6 @@ func (c *CTemplateSet) compile(name, content, expects string, expectsInt interfa
 	} else if !c.config.SkipInitBlock {
 		if len(c.langIndexToName) > 0 {
 			fout += "var " + fname + "_tmpl_phrase_id int\n\n"
-			if len(c.langIndexToName) > 1 {
-				fout += "var " + fname + "_phrase_arr [" + strconv.Itoa(len(c.langIndexToName)) + "][]byte\n\n"
-			} else {
-				fout += "var " + fname + "_phrase []byte\n\n"
-			}
 		}
 		fout += "// nolint\nfunc init() {\n"
 
@@ -496,18 +491,6 @@ func (c *CTemplateSet) compile(name, content, expects string, expectsInt interfa
 				fout += "\t\t" + `"` + name + `"` + ",\n"
 			}
 			fout += "\t})\n"
-
-			if len(c.langIndexToName) > 1 {
-				fout += `	phrases.AddTmplIndexCallback(func(phraseSet [][]byte) {
-		copy(` + fname + `_phrase_arr[:], phraseSet)
-	})
-`
-			} else {
-				fout += `	phrases.AddTmplIndexCallback(func(phraseSet [][]byte) {
-		` + fname + `_phrase = phraseSet[0]
-	})
-`
-			}
 		}
 		fout += "}\n\n"
 	}
@@ -533,12 +516,19 @@ if !ok {
 	var tmp []byte
 	_ = tmp
 `
+		// The phrases come from the language pack for the request, rather than the default one
+		if _, ok := c.importMap[langPkg]; ok {
+			fout += "plang := phrases.LangPackOf(tmpl_i)\n_ = plang\n"
+		}
+		if len(c.langIndexToName) > 0 {
+			fout += "plist := plang.TmplIndicesToPhrases[" + fname + "_tmpl_phrase_id]\n"
+		}
 	} else {
 		fout += "// nolint\nfunc Tmpl_" + fname + "(tmpl_" + fname + "_vars interface{}, w io.Writer) error {\n"
 		//fout += "// nolint\nfunc Tmpl_" + fname + "(tmpl_vars interface{}, w io.Writer) error {\n"
 	}
 
-	if len(c.langIndexToName) > 0 {
+	if len(c.langIndexToName) > 0 && c.lang == "js" {
 		fout += "//var plist = phrases.GetTmplPhrasesBytes(" + fname + "_tmpl_phrase_id)\n"
 		//fout += "if len(plist) > 0 {\n_ = plist[len(plist)-1]\n}\n"
 		//fout += "var plist = " + fname + "_phrase_arr\n"
@@ -587,8 +577,9 @@ if !ok {
 		case fr.Type == "varsub" || fr.Type == "cvarsub":
 			fout += "w.Write(" + fr.Body + ")\n"
 		case fr.Type == "lang":
-			//fout += "w.Write(plist[" + strconv.Itoa(fr.Extra.(int)) + "])\n"
-			if len(c.langIndexToName) == 1 {
+			if c.lang == "normal" {
+				fout += "w.Write(plist[" + strconv.Itoa(fr.Extra.(int)) + "])\n"
+			} else if len(c.langIndexToName) == 1 {
 				fout += "w.Write(" + fname + "_phrase)\n"
 			} else {
 				fout += "w.Write(" + fname + "_phrase_arr[" + strconv.Itoa(fr.Extra.(int)) + "])\n"
@@ -1252,7 +1243,7 @@ ArgLoop:
 					leftParam, _ = c.compileIfVarSub(con, leftParam)
 				}
 				// TODO: Add an optimisation if it's a string literal passsed in from a parent template rather than a true dynamic
-				litString("phrases.GetTmplPhrasef("+leftParam+")", false)
+				litString(c.langCall("TmplPhrasef")+"("+leftParam+")", false)
 				c.importMap[langPkg] = langPkg
 			}
 			break ArgLoop
@@ -1311,7 +1302,7 @@ ArgLoop:
 
 			// TODO: Implement string literals properly
 			// ! Slightly crude but it does the job
-			litString("phrases.GetTmplPhrasef("+leftOp+ob+")", false)
+			litString(c.langCall("TmplPhrasef")+"("+leftOp+ob+")", false)
 			c.importMap[langPkg] = langPkg
 			break ArgLoop
 		case "level":
@@ -1322,7 +1313,7 @@ ArgLoop:
 			}
 			leftParam, _ := c.compileIfVarSub(con, leftOp)
 			// TODO: Refactor this
-			litString("phrases.GetLevelPhrase("+leftParam+")", false)
+			litString(c.langCall("LevelPhrase")+"("+leftParam+")", false)
 			c.importMap[langPkg] = langPkg
 			break ArgLoop
 		case "bunit":
@@ -1467,6 +1458,14 @@ func (c *CTemplateSet) compileReflectSwitch(con CContext, node *parse.CommandNod
 	return out, outVal
 }
 
+// langCall returns the phrase function fn for the page's language pack, the JS templates have their own versions of the global ones
+func (c *CTemplateSet) langCall(fn string) string {
+	if c.lang == "js" {
+		return "phrases.Get" + fn
+	}
+	return "plang." + fn
+}
+
 func (c *CTemplateSet) compileIfVarSubN(con CContext, varname string) (out string) {
 	c.dumpCall("compileIfVarSubN", con, varname)
 	out, _ = c.compileIfVarSub(con, varname)
@@ -46,10 +46,15 @@ type Theme struct {
 	Docks          []string // Allowed Values: leftSidebar, rightSidebar, footer
 	DocksID        []int    // Integer versions of Docks to try to get a speed boost in BuildWidget()
 	Settings       map[string]ThemeSetting
+	SettingValues  map[string]string // Set by the system from settings.json, rather than the theme meta file
+	UserSettings   map[string]ThemeUserSetting
 	IntTmplHandle  *htmpl.Template
+	// Set by the system, the CSS variables for guests and users who haven't picked any preferences
+	DefaultUserStyle htmpl.CSS
 	// TODO: Do we really need both OverridenTemplates AND OverridenMap?
 	OverridenTemplates []string
 	OverridenMap       map[string]bool
+	RuntimeOverrides   bool // Set by the system, the overrides are interpreted, rather than using the compiled templates
 	Templates          []TemplateMapping
 	TemplatesMap       map[string]string
 	TmplPtr            map[string]interface{}
@@ -69,7 +74,7 @@ type Theme struct {
 
 type ThemeSetting struct {
 	FriendlyName string
-	Options      []string
+	Options      []string // The values this setting can be set to, if this is empty, then it can be set to anything
 }
 
 type TemplateMapping struct {
@@ -106,7 +111,37 @@ type ThemeMapTmplToDock struct {
 
 // TODO: It might be unsafe to call the template parsing functions with fsnotify, do something more concurrent
 func (t *Theme) LoadStaticFiles() error {
-	t.ResourceTemplates = template.New("")
+	t.ResourceTemplates = newResourceTemplates()
+	// TODO: Minify these
+	//template.Must(t.ResourceTemplates.ParseGlob("./themes/" + t.Name + "/public/*.css"))
+	fnames, err := filepath.Glob("./themes/" + t.Name + "/public/*.css")
+	if err != nil {
+		return err
+	}
+	for _, fname := range fnames {
+		b, err := ioutil.ReadFile(fname)
+		if err != nil {
+			return err
+		}
+		name := filepath.Base(fname)
+		t := t.ResourceTemplates
+		var tmpl *template.Template
+		/*if name == t.Name() {
+			tmpl = t
+		} else {*/
+		tmpl = t.New(name)
+		//}
+		_, err = tmpl.Parse(prepCSSTmpl(b))
+		if err != nil {
+			return err
+		}
+	}
+
+	// It should be safe for us to load the files for all the themes in memory, as-long as the admin hasn't setup a ridiculous number of themes
+	return t.AddThemeStaticFiles()
+}
+
+func newResourceTemplates() *template.Template {
 	fmap := make(map[string]interface{})
 	fmap["lang"] = func(phraseNameInt, tmplInt interface{}) interface{} {
 		phraseName, ok := phraseNameInt.(string)
@@ -134,65 +169,65 @@ func (t *Theme) LoadStaticFiles() error {
 		}
 		return out
 	}
-	t.ResourceTemplates.Funcs(fmap)
-	// TODO: Minify these
-	//template.Must(t.ResourceTemplates.ParseGlob("./themes/" + t.Name + "/public/*.css"))
-	fnames, err := filepath.Glob("./themes/" + t.Name + "/public/*.css")
-	if err != nil {
-		return err
+	return template.New("").Funcs(fmap)
+}
+
+// prepCSSTmpl squashes a stylesheet down before it's parsed as a template
+func prepCSSTmpl(b []byte) string {
+	//b := []byte("trolololol")
+	//b = bytes.ReplaceAll(b, []byte{10}, []byte(""))
+	//b = bytes.Replace(b, []byte("\n\n"), []byte(""), -1)
+	//b = bytes.Replace(b, []byte("}\n."), []byte("}."), -1)
+	//b = bytes.Replace(b, []byte("}\n:"), []byte("}:"), -1)
+	s := string(b)
+	rep := func(from, to string) {
+		s = strings.Replace(s, from, to, -1)
 	}
-	for _, fname := range fnames {
-		b, err := ioutil.ReadFile(fname)
-		if err != nil {
-			return err
-		}
-		//b := []byte("trolololol")
-		//b = bytes.ReplaceAll(b, []byte{10}, []byte(""))
-		//b = bytes.Replace(b, []byte("\n\n"), []byte(""), -1)
-		//b = bytes.Replace(b, []byte("}\n."), []byte("}."), -1)
-		//b = bytes.Replace(b, []byte("}\n:"), []byte("}:"), -1)
-		s := func() string {
-			s := string(b)
-			rep := func(from, to string) {
-				s = strings.Replace(s, from, to, -1)
-			}
-			rep("\r", "")
-			rep("}\n", "}")
-			rep("\n{", "{")
-			rep("\n", "")
-			rep(`
+	rep("\r", "")
+	rep("}\n", "}")
+	rep("\n{", "{")
+	rep("\n", "")
+	rep(`
 `, "")
-			rep(": {{", ":{{")
-			rep("display: ", "display:")
-			rep("float: ", "float:")
-			rep("-left: ", "-left:")
-			rep("-right: ", "-right:")
-			rep("-top: ", "-top:")
-			rep("-bottom: ", "-bottom:")
-			rep("border: ", "border:")
-			rep("radius: ", "radius:")
-			rep("content: ", "content:")
-			rep("width: ", "width:")
-			rep("padding: ", "padding:")
-			rep("-size: ", "-size:")
-			return s
-		}()
-		name := filepath.Base(fname)
-		t := t.ResourceTemplates
-		var tmpl *template.Template
-		/*if name == t.Name() {
-			tmpl = t
-		} else {*/
-		tmpl = t.New(name)
-		//}
-		_, err = tmpl.Parse(s)
-		if err != nil {
-			return err
-		}
-	}
+	rep(": {{", ":{{")
+	rep("display: ", "display:")
+	rep("float: ", "float:")
+	rep("-left: ", "-left:")
+	rep("-right: ", "-right:")
+	rep("-top: ", "-top:")
+	rep("-bottom: ", "-bottom:")
+	rep("border: ", "border:")
+	rep("radius: ", "radius:")
+	rep("content: ", "content:")
+	rep("width: ", "width:")
+	rep("padding: ", "padding:")
+	rep("-size: ", "-size:")
+	return s
+}
 
-	// It should be safe for us to load the files for all the themes in memory, as-long as the admin hasn't setup a ridiculous number of themes
-	return t.AddThemeStaticFiles()
+// minifyCSS finishes off the stylesheet once the template has been run
+func minifyCSS(data []byte) []byte {
+	rep := func(from, to string) {
+		data = bytes.Replace(data, []byte(from), []byte(to), -1)
+	}
+	rep("\t", "")
+	//rep("\n\n", "")
+	rep("\n", "")
+	rep("\n", "")
+	rep(`
+`, "")
+	rep("}\n.", "}.")
+	rep("}\n:", "}:")
+	rep(": #", ":#")
+	rep(" {", "{")
+	rep("{\n", "{")
+	rep(",\n", ",")
+	rep(";\n", ";")
+	rep(";\n}", ";}")
+	rep(": 0px;", ":0;")
+	rep("; }", ";}")
+	rep(", #", ",#")
+	return data
 }
 
 func (t *Theme) AddThemeStaticFiles() error {
@@ -222,32 +257,12 @@ func (t *Theme) AddThemeStaticFiles() error {
 			pieces := strings.Split(path, "/")
 			filename := pieces[len(pieces)-1]
 			// TODO: Prepare resource templates for each loaded langpack?
-			err = t.ResourceTemplates.ExecuteTemplate(&b, filename, CSSData{Phrases: phraseMap})
+			err = t.ResourceTemplates.ExecuteTemplate(&b, filename, CSSData{Phrases: phraseMap, Settings: t.SettingValues})
 			if err != nil {
 				log.Print("Failed in adding static file '" + path + "' for default theme '" + t.Name + "'")
 				return err
 			}
-			data = b.Bytes()
-			rep := func(from, to string) {
-				data = bytes.Replace(data, []byte(from), []byte(to), -1)
-			}
-			rep("\t", "")
-			//rep("\n\n", "")
-			rep("\n", "")
-			rep("\n", "")
-			rep(`
-`, "")
-			rep("}\n.", "}.")
-			rep("}\n:", "}:")
-			rep(": #", ":#")
-			rep(" {", "{")
-			rep("{\n", "{")
-			rep(",\n", ",")
-			rep(";\n", ";")
-			rep(";\n}", ";}")
-			rep(": 0px;", ":0;")
-			rep("; }", ";}")
-			rep(", #", ",#")
+			data = minifyCSS(b.Bytes())
 		}
 
 		path = strings.TrimPrefix(path, "themes/"+t.Name+"/public")
@@ -437,6 +452,12 @@ func (t *Theme) RunTmpl(template string, pi interface{}, w io.Writer) error {
 		gzw.Header().Set("Content-Type", "text/html;charset=utf-8")
 	}
 
+	// The overrides have changed since they were compiled, if they ever were
+	handle, runtime := t.intTmpls(template)
+	if runtime {
+		return t.langTmpls(handle, pi).ExecuteTemplate(w, template+".html", pi)
+	}
+
 	getTmpl := t.GetTmpl(template)
 	switch tmplO := getTmpl.(type) {
 	case *func(interface{}, io.Writer) error:
@@ -450,10 +471,10 @@ func (t *Theme) RunTmpl(template string, pi interface{}, w io.Writer) error {
 		if !ok {
 			mapping = template
 		}
-		if t.IntTmplHandle.Lookup(mapping+".html") == nil {
+		if handle.Lookup(mapping+".html") == nil {
 			return ErrBadDefaultTemplate
 		}
-		return t.IntTmplHandle.ExecuteTemplate(w, mapping+".html", pi)
+		return t.langTmpls(handle, pi).ExecuteTemplate(w, mapping+".html", pi)
 	default:
 		log.Print("theme ", t)
 		log.Print("template ", template)
@@ -0,0 +1,458 @@
+package common
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	p "github.com/Azareal/Gosora/common/phrases"
+)
+
+var ErrThemeName = errors.New("Theme names can only have lowercase letters, numbers and underscores in them, and can't be longer than 50 characters.")
+var ErrThemeExists = errors.New("There's already a theme with this name.")
+var ErrThemeFile = errors.New("This file can't be edited from the theme editor.")
+var ErrThemeNotChild = errors.New("Only child themes can be edited, otherwise the changes would be lost whenever the theme is updated. Create a child theme from it first.")
+var ErrThemeSetting = errors.New("That isn't a valid value for this setting.")
+var ErrBadThemeZip = errors.New("This isn't a theme. It either doesn't have a theme.json file, redirects to another folder or has files which would end up outside of the theme's folder.")
+var ErrThemeZipTooBig = errors.New("This theme is too big.")
+
+// The biggest a theme can be once it's been unzipped, to stop someone from filling up the disk with a small zip
+const maxThemeSize = 50 * 1024 * 1024
+
+var themeNameRegex = regexp.MustCompile("^[a-z0-9_]{1,50}$")
+
+// themeListLock stops two themes from being added at the same time, the readers don't need it, as the list is swapped out, rather than modified
+var themeListLock sync.Mutex
+
+// The kinds of files which can be changed with the theme editor
+var themeEditableExts = map[string]bool{".css": true, ".js": true, ".html": true}
+
+func ValidThemeName(name string) bool {
+	return themeNameRegex.MatchString(name)
+}
+
+// AddTheme loads the theme's static files and adds it to the theme list
+func AddTheme(theme *Theme) error {
+	themeListLock.Lock()
+	defer themeListLock.Unlock()
+	if _, ok := Themes[theme.Name]; ok {
+		return ErrThemeExists
+	}
+	err := theme.LoadStaticFiles()
+	if err != nil {
+		return err
+	}
+	list := make(ThemeList, len(Themes)+1)
+	for name, th := range Themes {
+		list[name] = th
+	}
+	list[theme.Name] = theme
+	Themes = list
+	return nil
+}
+
+func (t *Theme) dir() string {
+	return "./themes/" + t.Name + "/"
+}
+
+// EditableFiles returns the files in the theme which can be changed from the theme editor, relative to the theme's folder
+func (t *Theme) EditableFiles() (files []string, err error) {
+	for _, sub := range []string{"public", "overrides"} {
+		err = filepath.Walk(t.dir()+sub, func(fpath string, f os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if f.IsDir() || !themeEditableExts[filepath.Ext(fpath)] {
+				return nil
+			}
+			// Only the overrides can be templates, a .html file in public is just a file
+			if sub == "public" && filepath.Ext(fpath) == ".html" {
+				return nil
+			}
+			fpath = strings.Replace(fpath, "\\", "/", -1)
+			files = append(files, strings.TrimPrefix(fpath, strings.TrimPrefix(t.dir(), "./")))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// editable makes sure the file is one of the ones listed by EditableFiles, so that the theme editor can't be pointed anywhere else
+func (t *Theme) editable(name string) error {
+	files, err := t.EditableFiles()
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file == name {
+			return nil
+		}
+	}
+	return ErrThemeFile
+}
+
+func (t *Theme) ReadFile(name string) ([]byte, error) {
+	err := t.editable(name)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(t.dir() + name)
+}
+
+// WriteFile replaces one of the theme's files and reloads it, if it won't load, then it's put back the way it was and the error is returned
+func (t *Theme) WriteFile(name string, data []byte) error {
+	if t.ForkOf == "" {
+		return ErrThemeNotChild
+	}
+	err := t.editable(name)
+	if err != nil {
+		return err
+	}
+	fpath := t.dir() + name
+	old, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	reload := func() error {
+		if strings.HasPrefix(name, "overrides/") {
+			return t.ReloadOverrides()
+		}
+		return t.LoadStaticFiles()
+	}
+
+	err = ioutil.WriteFile(fpath, data, 0644)
+	if err != nil {
+		return err
+	}
+	if lerr := reload(); lerr != nil {
+		err = ioutil.WriteFile(fpath, old, 0644)
+		if err != nil {
+			return err
+		}
+		if err = reload(); err != nil {
+			LogError(err)
+		}
+		return lerr
+	}
+	return nil
+}
+
+// PreviewCSS runs a stylesheet through the same steps it'd go through if it were saved, without saving it
+func (t *Theme) PreviewCSS(name string, data []byte) ([]byte, error) {
+	tmpl, err := newResourceTemplates().New(filepath.Base(name)).Parse(prepCSSTmpl(data))
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	err = tmpl.Execute(&b, CSSData{Phrases: p.GetTmplPhrases(), Settings: t.SettingValues})
+	if err != nil {
+		return nil, err
+	}
+	return minifyCSS(b.Bytes()), nil
+}
+
+// loadSettingValues reads in the values for the settings declared in theme.json, the settings which haven't been set are given their first option
+func (t *Theme) loadSettingValues() error {
+	saved := make(map[string]string)
+	data, err := ioutil.ReadFile(t.dir() + "settings.json")
+	if err == nil {
+		err = json.Unmarshal(data, &saved)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	vals := make(map[string]string)
+	for name, setting := range t.Settings {
+		if val, ok := saved[name]; ok && setting.Valid(val) {
+			vals[name] = val
+		} else if len(setting.Options) > 0 {
+			vals[name] = setting.Options[0]
+		} else {
+			vals[name] = ""
+		}
+	}
+	t.SettingValues = vals
+	return nil
+}
+
+// Valid checks if the setting can be set to val
+func (s ThemeSetting) Valid(val string) bool {
+	if len(s.Options) == 0 {
+		return true
+	}
+	for _, opt := range s.Options {
+		if opt == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Setting returns the value of one of the settings declared by the theme
+func (t *Theme) Setting(name string) string {
+	return t.SettingValues[name]
+}
+
+// SetSettings changes the values of the theme's settings and rebuilds the stylesheets with them. The settings which aren't in vals are left as they are.
+func (t *Theme) SetSettings(vals map[string]string) error {
+	nvals := make(map[string]string)
+	for name, setting := range t.Settings {
+		val, ok := vals[name]
+		if !ok {
+			nvals[name] = t.SettingValues[name]
+			continue
+		}
+		if !setting.Valid(val) {
+			return ErrThemeSetting
+		}
+		nvals[name] = val
+	}
+	data, err := json.MarshalIndent(nvals, "", "\t")
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(t.dir()+"settings.json", data, 0644)
+	if err != nil {
+		return err
+	}
+	t.SettingValues = nvals
+	return t.LoadStaticFiles()
+}
+
+// CreateChildTheme copies parent into a new theme which can be changed without touching the original
+func CreateChildTheme(parent *Theme, name, friendlyName string) (*Theme, error) {
+	if !ValidThemeName(name) {
+		return nil, ErrThemeName
+	}
+	if _, ok := Themes[name]; ok {
+		return nil, ErrThemeExists
+	}
+	dest := "./themes/" + name
+	if _, err := os.Stat(dest); err == nil {
+		return nil, ErrThemeExists
+	}
+	if friendlyName == "" {
+		friendlyName = name
+	}
+
+	theme, err := func() (*Theme, error) {
+		err := copyDir(parent.Path, dest)
+		if err != nil {
+			return nil, err
+		}
+		err = rewriteThemeMeta(dest+"/theme.json", func(meta map[string]interface{}) {
+			meta["Name"] = name
+			meta["FriendlyName"] = friendlyName
+			meta["ForkOf"] = parent.Name
+			delete(meta, "Path")
+			// The scripts and stylesheets prefixed with the parent's name are in the copy of the parent's public folder
+			if res, ok := meta["Resources"].([]interface{}); ok {
+				for _, r := range res {
+					rmap, ok := r.(map[string]interface{})
+					if !ok {
+						continue
+					}
+					if rname, ok := rmap["Name"].(string); ok && strings.HasPrefix(rname, parent.Name+"/") {
+						rmap["Name"] = name + "/" + strings.TrimPrefix(rname, parent.Name+"/")
+					}
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		theme, err := LoadTheme(name)
+		if err != nil {
+			return nil, err
+		}
+		return theme, AddTheme(theme)
+	}()
+	if err != nil {
+		os.RemoveAll(dest)
+		return nil, err
+	}
+	return theme, nil
+}
+
+// rewriteThemeMeta changes theme.json without dropping any of the fields we don't know about
+func rewriteThemeMeta(fpath string, f func(meta map[string]interface{})) error {
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		return err
+	}
+	meta := make(map[string]interface{})
+	err = json.Unmarshal(data, &meta)
+	if err != nil {
+		return err
+	}
+	f(meta)
+	data, err = json.MarshalIndent(meta, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fpath, data, 0644)
+}
+
+func copyDir(src, dest string) error {
+	return filepath.Walk(src, func(fpath string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, fpath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if f.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := ioutil.ReadFile(fpath)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, data, 0644)
+	})
+}
+
+// Export writes the theme's folder to w as a zip which can be imported into another site
+func (t *Theme) Export(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	src := t.Path
+	err := filepath.Walk(src, func(fpath string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, fpath)
+		if err != nil {
+			return err
+		}
+		return exportFile(zw, fpath, t.Name+"/"+filepath.ToSlash(rel))
+	})
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ImportTheme unpacks a theme made by Export, or any zip with a theme.json in it's root or in a single folder, into the themes folder and adds it to the theme list
+func ImportTheme(zr *zip.Reader) (*Theme, error) {
+	var prefix string
+	var meta *zip.File
+	for _, f := range zr.File {
+		if f.Name == "theme.json" || (strings.Count(f.Name, "/") == 1 && path.Base(f.Name) == "theme.json") {
+			meta = f
+			prefix = strings.TrimSuffix(f.Name, "theme.json")
+			break
+		}
+	}
+	if meta == nil {
+		return nil, ErrBadThemeZip
+	}
+
+	var head struct {
+		Name string
+		Path string
+	}
+	r, err := meta.Open()
+	if err != nil {
+		return nil, err
+	}
+	err = json.NewDecoder(io.LimitReader(r, 1024*1024)).Decode(&head)
+	r.Close()
+	if err != nil {
+		return nil, ErrBadThemeZip
+	}
+	// A redirect could point the theme at anything on the server
+	if head.Path != "" {
+		return nil, ErrBadThemeZip
+	}
+	if !ValidThemeName(head.Name) {
+		return nil, ErrThemeName
+	}
+	if _, ok := Themes[head.Name]; ok {
+		return nil, ErrThemeExists
+	}
+	dest := "./themes/" + head.Name
+	if _, err := os.Stat(dest); err == nil {
+		return nil, ErrThemeExists
+	}
+
+	theme, err := func() (*Theme, error) {
+		var total int64
+		for _, f := range zr.File {
+			if strings.HasSuffix(f.Name, "/") {
+				continue
+			}
+			if !strings.HasPrefix(f.Name, prefix) {
+				return nil, ErrBadThemeZip
+			}
+			rel := strings.TrimPrefix(f.Name, prefix)
+			if rel == "" || strings.Contains(rel, "\\") || path.IsAbs(rel) || path.Clean(rel) != rel || strings.HasPrefix(rel, "../") || rel == ".." {
+				return nil, ErrBadThemeZip
+			}
+			target := filepath.Join(dest, filepath.FromSlash(rel))
+			err := os.MkdirAll(filepath.Dir(target), 0755)
+			if err != nil {
+				return nil, err
+			}
+			n, err := unzipFile(f, target, maxThemeSize-total)
+			if err != nil {
+				return nil, err
+			}
+			total += n
+		}
+		theme, err := LoadTheme(head.Name)
+		if err != nil {
+			return nil, err
+		}
+		return theme, AddTheme(theme)
+	}()
+	if err != nil {
+		os.RemoveAll(dest)
+		return nil, err
+	}
+	return theme, nil
+}
+
+func unzipFile(f *zip.File, target string, left int64) (int64, error) {
+	r, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	out, err := os.Create(target)
+	if err != nil {
+		return 0, err
+	}
+	defer out.Close()
+	n, err := io.Copy(out, io.LimitReader(r, left+1))
+	if err != nil {
+		return n, err
+	}
+	if n > left {
+		return n, ErrThemeZipTooBig
+	}
+	return n, nil
+}
@@ -4,14 +4,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
-	"html/template"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -68,151 +66,134 @@ func NewThemeList() (themes ThemeList, err error) {
 			continue
 		}
 
-		themeName := themeFile.Name()
-		log.Printf("Adding theme '%s'", themeName)
-		themePath := "./themes/" + themeName
-		themeFile, err := ioutil.ReadFile(themePath + "/theme.json")
+		theme, err := LoadTheme(themeFile.Name())
 		if err != nil {
 			return themes, err
 		}
-
-		theme := &Theme{}
-		err = json.Unmarshal(themeFile, theme)
-		if err != nil {
-			return themes, err
-		}
-
-		if theme.Name == "" {
-			return themes, errors.New("Theme " + themePath + " doesn't have a name set in theme.json")
-		}
 		if theme.Name == fallbackTheme {
 			defaultTheme = fallbackTheme
 		}
 		lastTheme = theme.Name
+		themes[theme.Name] = theme
+	}
+	if defaultTheme == "" {
+		defaultTheme = lastTheme
+	}
+	DefaultThemeBox.Store(defaultTheme)
 
-		// TODO: Implement the static file part of this and fsnotify
-		if theme.Path != "" {
-			log.Print("Resolving redirect to " + theme.Path)
-			themeFile, err := ioutil.ReadFile(theme.Path + "/theme.json")
-			if err != nil {
-				return themes, err
-			}
-			theme = &Theme{Path: theme.Path}
-			err = json.Unmarshal(themeFile, theme)
-			if err != nil {
-				return themes, err
-			}
-		} else {
-			theme.Path = themePath
-		}
+	return themes, nil
+}
 
-		theme.Active = false // Set this to false, just in case someone explicitly overrode this value in the JSON file
+// LoadTheme reads in the theme in ./themes/themeName, it doesn't add it to the theme list
+func LoadTheme(themeName string) (*Theme, error) {
+	log.Printf("Adding theme '%s'", themeName)
+	themePath := "./themes/" + themeName
+	themeFile, err := ioutil.ReadFile(themePath + "/theme.json")
+	if err != nil {
+		return nil, err
+	}
 
-		// TODO: Let the theme specify where it's resources are via the JSON file?
-		// TODO: Let the theme inherit CSS from another theme?
-		// ? - This might not be too helpful, as it only searches for /public/ and not if /public/ is empty. Still, it might help some people with a slightly less cryptic error
-		log.Print(theme.Path + "/public/")
-		_, err = os.Stat(theme.Path + "/public/")
+	theme := &Theme{}
+	err = json.Unmarshal(themeFile, theme)
+	if err != nil {
+		return nil, err
+	}
+
+	if theme.Name == "" {
+		return nil, errors.New("Theme " + themePath + " doesn't have a name set in theme.json")
+	}
+	// TODO: Implement the static file part of this and fsnotify
+	if theme.Path != "" {
+		log.Print("Resolving redirect to " + theme.Path)
+		themeFile, err := ioutil.ReadFile(theme.Path + "/theme.json")
 		if err != nil {
-			if os.IsNotExist(err) {
-				return themes, errors.New("We couldn't find this theme's resources. E.g. the /public/ folder.")
-			} else {
-				log.Print("We weren't able to access this theme's resources due to a permissions issue or some other problem")
-				return themes, err
-			}
+			return nil, err
 		}
-
-		if theme.FullImage != "" {
-			DebugLog("Adding theme image")
-			err = StaticFiles.Add(theme.Path+"/"+theme.FullImage, themePath)
-			if err != nil {
-				return themes, err
-			}
+		theme = &Theme{Path: theme.Path}
+		err = json.Unmarshal(themeFile, theme)
+		if err != nil {
+			return nil, err
 		}
+	} else {
+		theme.Path = themePath
+	}
 
-		theme.TemplatesMap = make(map[string]string)
-		theme.TmplPtr = make(map[string]interface{})
-		if theme.Templates != nil {
-			for _, themeTmpl := range theme.Templates {
-				theme.TemplatesMap[themeTmpl.Name] = themeTmpl.Source
-				theme.TmplPtr[themeTmpl.Name] = TmplPtrMap["o_"+themeTmpl.Source]
-			}
-		}
+	theme.Active = false // Set this to false, just in case someone explicitly overrode this value in the JSON file
 
-		theme.IntTmplHandle = DefaultTemplates
-		overrides, err := ioutil.ReadDir(theme.Path + "/overrides/")
-		if err != nil && !os.IsNotExist(err) {
-			return themes, err
-		}
-		if len(overrides) > 0 {
-			overCount := 0
-			theme.OverridenMap = make(map[string]bool)
-			for _, override := range overrides {
-				if override.IsDir() {
-					continue
-				}
-				ext := filepath.Ext(themePath + "/overrides/" + override.Name())
-				log.Print("attempting to add " + themePath + "/overrides/" + override.Name())
-				if ext != ".html" {
-					log.Print("not a html file")
-					continue
-				}
-				overCount++
-				nosuf := strings.TrimSuffix(override.Name(), ext)
-				theme.OverridenTemplates = append(theme.OverridenTemplates, nosuf)
-				theme.OverridenMap[nosuf] = true
-				//theme.TmplPtr[nosuf] = TmplPtrMap["o_"+nosuf]
-				log.Print("succeeded")
-			}
-
-			localTmpls := template.New("")
-			err = loadTemplates(localTmpls, theme.Name)
-			if err != nil {
-				return themes, err
-			}
-			theme.IntTmplHandle = localTmpls
-			log.Printf("theme.OverridenTemplates: %+v\n", theme.OverridenTemplates)
-			log.Printf("theme.IntTmplHandle: %+v\n", theme.IntTmplHandle)
+	// TODO: Let the theme specify where it's resources are via the JSON file?
+	// TODO: Let the theme inherit CSS from another theme?
+	// ? - This might not be too helpful, as it only searches for /public/ and not if /public/ is empty. Still, it might help some people with a slightly less cryptic error
+	log.Print(theme.Path + "/public/")
+	_, err = os.Stat(theme.Path + "/public/")
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("We couldn't find this theme's resources. E.g. the /public/ folder.")
 		} else {
-			log.Print("no overrides for " + theme.Name)
+			log.Print("We weren't able to access this theme's resources due to a permissions issue or some other problem")
+			return nil, err
 		}
+	}
 
-		for i, res := range theme.Resources {
-			ext := filepath.Ext(res.Name)
-			switch ext {
-			case ".css":
-				res.Type = ResTypeSheet
-			case ".js":
-				res.Type = ResTypeScript
-			}
-			switch res.Location {
-			case "global":
-				res.LocID = LocGlobal
-			case "frontend":
-				res.LocID = LocFront
-			case "panel":
-				res.LocID = LocPanel
-			}
-			theme.Resources[i] = res
+	if theme.FullImage != "" {
+		DebugLog("Adding theme image")
+		err = StaticFiles.Add(theme.Path+"/"+theme.FullImage, themePath)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		for _, dock := range theme.Docks {
-			id, ok := DockToID[dock]
-			if ok {
-				theme.DocksID = append(theme.DocksID, id)
-			}
+	theme.TemplatesMap = make(map[string]string)
+	theme.TmplPtr = make(map[string]interface{})
+	if theme.Templates != nil {
+		for _, themeTmpl := range theme.Templates {
+			theme.TemplatesMap[themeTmpl.Name] = themeTmpl.Source
+			theme.TmplPtr[themeTmpl.Name] = TmplPtrMap["o_"+themeTmpl.Source]
 		}
+	}
+
+	err = theme.loadSettingValues()
+	if err != nil {
+		return nil, err
+	}
+	err = theme.prepUserSettings()
+	if err != nil {
+		return nil, err
+	}
 
-		// TODO: Bind the built template, or an interpreted one for any dock overrides this theme has
+	err = theme.LoadOverrides()
+	if err != nil {
+		return nil, err
+	}
 
-		themes[theme.Name] = theme
+	for i, res := range theme.Resources {
+		ext := filepath.Ext(res.Name)
+		switch ext {
+		case ".css":
+			res.Type = ResTypeSheet
+		case ".js":
+			res.Type = ResTypeScript
+		}
+		switch res.Location {
+		case "global":
+			res.LocID = LocGlobal
+		case "frontend":
+			res.LocID = LocFront
+		case "panel":
+			res.LocID = LocPanel
+		}
+		theme.Resources[i] = res
 	}
-	if defaultTheme == "" {
-		defaultTheme = lastTheme
+
+	for _, dock := range theme.Docks {
+		id, ok := DockToID[dock]
+		if ok {
+			theme.DocksID = append(theme.DocksID, id)
+		}
 	}
-	DefaultThemeBox.Store(defaultTheme)
 
-	return themes, nil
+	// TODO: Bind the built template, or an interpreted one for any dock overrides this theme has
+
+	return theme, nil
 }
 
 // TODO: Make the initThemes and LoadThemes functions less confusing
@@ -307,7 +288,7 @@ func CreateThemeTemplate(theme, name string) {
 		if !ok {
 			mapping = name
 		}
-		return DefaultTemplates.ExecuteTemplate(w, mapping+".html", pi)
+		return langTemplates(DefaultTemplates, "", pi).ExecuteTemplate(w, mapping+".html", pi)
 	}
 }
 
@@ -0,0 +1,282 @@
+package common
+
+import (
+	"errors"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template/parse"
+)
+
+// SafeTemplateFuncMap is the function map the theme overrides are interpreted with.
+// Themes might come from anywhere, so they only get the functions which can't be turned against the site.
+var SafeTemplateFuncMap map[string]interface{}
+
+// The functions from DefaultTemplateFuncMap which theme overrides can use
+var safeTmplFuncs = []string{"add", "subtract", "multiply", "divide", "dock", "hasWidgets", "elapsed", "lang", "langf", "level", "bunit", "abstime", "reltime", "scope", "dyntmpl", "ptmpl", "js", "flush", "res"}
+
+var ErrTmplUnsafeCall = errors.New("call isn't available to theme overrides")
+
+// overrideLock guards the interpreted template sets of the themes, which might be swapped out from under a request when an override is reloaded
+var overrideLock sync.RWMutex
+
+func initSafeTmplFuncMap() {
+	fmap := make(map[string]interface{})
+	for _, name := range safeTmplFuncs {
+		if f, ok := DefaultTemplateFuncMap[name]; ok {
+			fmap[name] = f
+		}
+	}
+	// This shadows the builtin, which would let a template run any function it can reach in the page data, such as Theme.RunOnDock
+	fmap["call"] = func(fn interface{}, args ...interface{}) (interface{}, error) {
+		return nil, ErrTmplUnsafeCall
+	}
+	SafeTemplateFuncMap = fmap
+}
+
+// The methods on the page data which the theme overrides are allowed to call, everything else could change something, such as User.Delete, so it's off limits
+var safeTmplMethods = map[string]bool{
+	"HasDock":     true,
+	"HasDockByID": true,
+	"BuildDock":   true,
+	"GetIP":       true,
+	"DueAt":       true,
+	// These only look things up, and they share their names with fields on the other types
+	"Author":  true,
+	"Creator": true,
+	"Posts":   true,
+	"Replies": true,
+	"Topic":   true,
+	"Setting": true,
+	"Valid":   true,
+}
+
+var unsafeTmplMethods map[string]bool
+var unsafeTmplMethodsOnce sync.Once
+
+// tmplDataRoots are the types the rest of the page data hangs off of
+var tmplDataRoots = []interface{}{&Header{}, &User{}, &TopicUser{}, &ReplyUser{}, &TopicsRow{}, &Forum{}, &Group{}, &Poll{}, &Conversation{}, &ProfileReply{}, &Widget{}}
+
+// findTmplMethods walks through the types reachable from the roots and lists the methods on them which the overrides shouldn't be calling
+func findTmplMethods() map[string]bool {
+	methods := make(map[string]bool)
+	seen := make(map[reflect.Type]bool)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		if seen[t] {
+			return
+		}
+		seen[t] = true
+		if t.PkgPath() == reflect.TypeOf(User{}).PkgPath() || (t.Kind() == reflect.Ptr && t.Elem().PkgPath() == reflect.TypeOf(User{}).PkgPath()) {
+			for i := 0; i < t.NumMethod(); i++ {
+				if name := t.Method(i).Name; !safeTmplMethods[name] {
+					methods[name] = true
+				}
+			}
+		}
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			walk(t.Elem())
+		case reflect.Struct:
+			walk(reflect.PtrTo(t))
+			for i := 0; i < t.NumField(); i++ {
+				walk(t.Field(i).Type)
+			}
+		}
+	}
+	for _, root := range tmplDataRoots {
+		walk(reflect.TypeOf(root))
+	}
+	return methods
+}
+
+// vetOverride makes sure the templates in an override don't call any of the methods which could change something
+func vetOverride(fname, src string) error {
+	unsafeTmplMethodsOnce.Do(func() {
+		unsafeTmplMethods = findTmplMethods()
+	})
+	set, err := template.New(fname).Funcs(SafeTemplateFuncMap).Parse(src)
+	if err != nil {
+		return err
+	}
+	for _, tmpl := range set.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+		err = vetNode(fname, tmpl.Tree.Root)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func vetNode(fname string, node parse.Node) error {
+	if node == nil || reflect.ValueOf(node).IsNil() {
+		return nil
+	}
+	checkIdents := func(idents []string) error {
+		for _, ident := range idents {
+			if unsafeTmplMethods[ident] {
+				return errors.New(fname + ": theme overrides can't call " + ident)
+			}
+		}
+		return nil
+	}
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, sub := range n.Nodes {
+			if err := vetNode(fname, sub); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return vetNode(fname, n.Pipe)
+	case *parse.PipeNode:
+		for _, cmd := range n.Cmds {
+			if err := vetNode(fname, cmd); err != nil {
+				return err
+			}
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := vetNode(fname, arg); err != nil {
+				return err
+			}
+		}
+	case *parse.FieldNode:
+		return checkIdents(n.Ident)
+	case *parse.VariableNode:
+		return checkIdents(n.Ident[1:])
+	case *parse.ChainNode:
+		if err := checkIdents(n.Field); err != nil {
+			return err
+		}
+		return vetNode(fname, n.Node)
+	case *parse.IfNode:
+		return vetBranch(fname, &n.BranchNode)
+	case *parse.RangeNode:
+		return vetBranch(fname, &n.BranchNode)
+	case *parse.WithNode:
+		return vetBranch(fname, &n.BranchNode)
+	case *parse.TemplateNode:
+		return vetNode(fname, n.Pipe)
+	}
+	return nil
+}
+
+func vetBranch(fname string, b *parse.BranchNode) error {
+	for _, node := range []parse.Node{b.Pipe, b.List, b.ElseList} {
+		if err := vetNode(fname, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadOverrides reads in the templates in the theme's overrides folder and interprets them on top of the default ones.
+// The overrides are run through the interpreter rather than the compiled templates when they haven't been compiled in, or when Dev.HotTemplates is on.
+func (t *Theme) LoadOverrides() error {
+	dir := "./themes/" + t.Name + "/overrides/"
+	overrides, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var tmpls []string
+	overMap := make(map[string]bool)
+	for _, override := range overrides {
+		if override.IsDir() || filepath.Ext(override.Name()) != ".html" {
+			continue
+		}
+		nosuf := strings.TrimSuffix(override.Name(), ".html")
+		tmpls = append(tmpls, nosuf)
+		overMap[nosuf] = true
+	}
+
+	handle := DefaultTemplates
+	if len(tmpls) > 0 {
+		handle = template.New("")
+		err = loadTemplates(handle, t.Name)
+		if err != nil {
+			return err
+		}
+	} else {
+		DebugLog("no overrides for " + t.Name)
+	}
+
+	runtime := len(tmpls) > 0 && (Dev.HotTemplates || !t.overridesCompiled(dir, tmpls))
+	overrideLock.Lock()
+	t.OverridenTemplates = tmpls
+	t.OverridenMap = overMap
+	t.IntTmplHandle = handle
+	t.RuntimeOverrides = runtime
+	overrideLock.Unlock()
+	if t.RuntimeOverrides {
+		log.Printf("The overrides for %s will be interpreted at runtime", t.Name)
+	}
+	return nil
+}
+
+// ReloadOverrides picks up the changes to the overrides, the old ones are kept if the new ones don't parse, so a typo won't take the site down
+func (t *Theme) ReloadOverrides() error {
+	err := t.LoadOverrides()
+	if err != nil {
+		return err
+	}
+	overrideLock.Lock()
+	// The compiled templates won't have these changes in them
+	t.RuntimeOverrides = len(t.OverridenTemplates) > 0
+	overrideLock.Unlock()
+	return nil
+}
+
+// overridesCompiled checks if the templates this theme overrides were compiled in and haven't changed since
+func (t *Theme) overridesCompiled(dir string, tmpls []string) bool {
+	var found bool
+	for name := range TmplPtrMap {
+		if strings.HasSuffix(name, "_"+t.Name) && !strings.HasPrefix(name, "o_") {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return true
+	}
+	exeInfo, err := os.Stat(exe)
+	if err != nil {
+		return true
+	}
+	for _, name := range tmpls {
+		info, err := os.Stat(dir + name + ".html")
+		if err == nil && info.ModTime().After(exeInfo.ModTime()) {
+			return false
+		}
+	}
+	return true
+}
+
+// intTmpls returns the interpreted template set for the theme and whether it should be used for this template rather than the compiled one.
+// It's grabbed under the lock, but the lock isn't held while the template runs, as it might run others.
+func (t *Theme) intTmpls(name string) (handle *template.Template, runtime bool) {
+	overrideLock.RLock()
+	handle, runtime = t.IntTmplHandle, t.RuntimeOverrides && t.OverridenMap[name]
+	overrideLock.RUnlock()
+	return handle, runtime
+}
+
+// langTmpls swaps handle for the copy of it in the language of the page pi
+func (t *Theme) langTmpls(handle *template.Template, pi interface{}) *template.Template {
+	if handle == DefaultTemplates {
+		return langTemplates(handle, "", pi)
+	}
+	return langTemplates(handle, t.Name, pi)
+}
@@ -0,0 +1,284 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	htmpl "html/template"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var UserThemeSettings UserThemeSettingStore
+
+var ErrThemeUserSetting = errors.New("That isn't one of the options for this preference")
+
+// ThemeUserSetting is a preference a theme lets each user pick for themselves, such as a colour scheme or the font size
+type ThemeUserSetting struct {
+	FriendlyName string
+	Options      []ThemeUserOption // The first option is the one guests and users who haven't picked anything get
+}
+
+// ThemeUserOption is one of the choices for a ThemeUserSetting, it's applied by overriding the CSS variables in the theme's stylesheets
+type ThemeUserOption struct {
+	Name         string
+	FriendlyName string
+	Vars         map[string]string // map[--var-name]value
+	// Media is a media query, such as (prefers-color-scheme: dark), which applies this option when the default option is in use and the browser matches it
+	Media string
+}
+
+var themeVarName = regexp.MustCompile(`^--[a-zA-Z0-9-]+$`)
+
+// The values end up in a style tag, so they mustn't be able to break out of the declaration they're in
+func validThemeVarValue(val string) bool {
+	return !strings.ContainsAny(val, "<>{};\\")
+}
+
+// prepUserSettings checks over the preferences declared in theme.json and builds the style the guests get
+func (t *Theme) prepUserSettings() error {
+	for name, setting := range t.UserSettings {
+		if !ValidThemeName(name) || len(setting.Options) == 0 {
+			return errors.New("The user setting '" + name + "' in theme " + t.Name + " isn't valid")
+		}
+		seen := make(map[string]bool)
+		for _, opt := range setting.Options {
+			if !ValidThemeName(opt.Name) || seen[opt.Name] {
+				return errors.New("The option '" + opt.Name + "' for the user setting '" + name + "' in theme " + t.Name + " isn't valid")
+			}
+			seen[opt.Name] = true
+			if opt.Media != "" && (opt.Media[0] != '(' || !validThemeVarValue(opt.Media)) {
+				return errors.New("The media query for the option '" + opt.Name + "' in theme " + t.Name + " isn't valid")
+			}
+			for vname, val := range opt.Vars {
+				if !themeVarName.MatchString(vname) || !validThemeVarValue(val) {
+					return errors.New("The variable '" + vname + "' for the option '" + opt.Name + "' in theme " + t.Name + " isn't valid")
+				}
+			}
+		}
+	}
+	t.DefaultUserStyle = t.UserStyle(nil)
+	return nil
+}
+
+// UserSettingNames returns the names of the user settings this theme has in a stable order
+func (t *Theme) UserSettingNames() []string {
+	names := make([]string, 0, len(t.UserSettings))
+	for name := range t.UserSettings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UserOption returns the option the user has picked for a setting, falling back to the default one if they haven't picked a valid one
+func (t *Theme) UserOption(name string, vals map[string]string) (opt ThemeUserOption, isDefault bool) {
+	setting := t.UserSettings[name]
+	if val, ok := vals[name]; ok {
+		for i, o := range setting.Options {
+			if o.Name == val {
+				return o, i == 0
+			}
+		}
+	}
+	return setting.Options[0], true
+}
+
+// ValidUserSetting checks whether val is one of the options for the user setting name
+func (t *Theme) ValidUserSetting(name, val string) bool {
+	setting, ok := t.UserSettings[name]
+	if !ok {
+		return false
+	}
+	for _, opt := range setting.Options {
+		if opt.Name == val {
+			return true
+		}
+	}
+	return false
+}
+
+// UserStyle builds the CSS which overrides the theme's variables with the ones in the options the user has picked.
+// The media options only kick in on the settings which are on their default, so a user who explicitly picks light mode won't get dark mode at night.
+func (t *Theme) UserStyle(vals map[string]string) htmpl.CSS {
+	if len(t.UserSettings) == 0 {
+		return ""
+	}
+	var root, media strings.Builder
+	writeVars := func(sb *strings.Builder, vars map[string]string) {
+		vnames := make([]string, 0, len(vars))
+		for vname := range vars {
+			vnames = append(vnames, vname)
+		}
+		sort.Strings(vnames)
+		for _, vname := range vnames {
+			sb.WriteString(vname + ":" + vars[vname] + ";")
+		}
+	}
+	for _, name := range t.UserSettingNames() {
+		opt, isDefault := t.UserOption(name, vals)
+		writeVars(&root, opt.Vars)
+		if !isDefault {
+			continue
+		}
+		for _, mopt := range t.UserSettings[name].Options {
+			if mopt.Media == "" || len(mopt.Vars) == 0 {
+				continue
+			}
+			media.WriteString("@media " + mopt.Media + "{:root{")
+			writeVars(&media, mopt.Vars)
+			media.WriteString("}}")
+		}
+	}
+
+	var out string
+	if root.Len() > 0 {
+		out = ":root{" + root.String() + "}"
+	}
+	// ? - Is there a way of getting this into the stylesheets without losing the ability to cache them?
+	return htmpl.CSS(out + media.String())
+}
+
+// UserStyleFor returns the style for the options u has picked on this theme, guests get the default one
+func (t *Theme) UserStyleFor(u *User) htmpl.CSS {
+	if !u.Loggedin || len(t.UserSettings) == 0 || UserThemeSettings == nil {
+		return t.DefaultUserStyle
+	}
+	vals, err := UserThemeSettings.Get(u.ID, t.Name)
+	if err != nil {
+		LogError(err)
+		return t.DefaultUserStyle
+	}
+	if len(vals) == 0 {
+		return t.DefaultUserStyle
+	}
+	return t.UserStyle(vals)
+}
+
+// UserThemeSettingStore holds the preferences each user has picked for each theme
+type UserThemeSettingStore interface {
+	Get(uid int, theme string) (map[string]string, error)
+	GetAll(uid int) (map[string]map[string]string, error)
+	Set(uid int, theme string, vals map[string]string) error
+	DeleteAll(uid int) error
+	Length() int
+}
+
+// DefaultUserThemeSettingStore keeps the preferences of the users who've been around recently in memory, as we'd otherwise have to hit the database on every page
+type DefaultUserThemeSettingStore struct {
+	cache    map[int]map[string]map[string]string // map[uid]map[theme]map[name]value
+	capacity int
+	sync.RWMutex
+
+	getAll    *sql.Stmt
+	insert    *sql.Stmt
+	delete    *sql.Stmt
+	deleteAll *sql.Stmt
+}
+
+func NewDefaultUserThemeSettingStore(acc *qgen.Accumulator, capacity int) (*DefaultUserThemeSettingStore, error) {
+	uts := "users_theme_settings"
+	return &DefaultUserThemeSettingStore{
+		cache:    make(map[int]map[string]map[string]string),
+		capacity: capacity,
+
+		getAll:    acc.Select(uts).Columns("theme,name,value").Where("uid=?").Prepare(),
+		insert:    acc.Insert(uts).Columns("uid,theme,name,value").Fields("?,?,?,?").Prepare(),
+		delete:    acc.Delete(uts).Where("uid=? AND theme=?").Prepare(),
+		deleteAll: acc.Delete(uts).Where("uid=?").Prepare(),
+	}, acc.FirstError()
+}
+
+// Get returns the preferences uid has picked for a theme. Do not mutate the returned map.
+func (s *DefaultUserThemeSettingStore) Get(uid int, theme string) (map[string]string, error) {
+	all, err := s.GetAll(uid)
+	if err != nil {
+		return nil, err
+	}
+	return all[theme], nil
+}
+
+// GetAll returns the preferences uid has picked for every theme keyed by the theme name. Do not mutate the returned map.
+func (s *DefaultUserThemeSettingStore) GetAll(uid int) (map[string]map[string]string, error) {
+	s.RLock()
+	all, ok := s.cache[uid]
+	s.RUnlock()
+	if ok {
+		return all, nil
+	}
+
+	all = make(map[string]map[string]string)
+	rows, err := s.getAll.Query(uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var theme, name, val string
+		if err := rows.Scan(&theme, &name, &val); err != nil {
+			return nil, err
+		}
+		if all[theme] == nil {
+			all[theme] = make(map[string]string)
+		}
+		all[theme][name] = val
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Users with nothing set are cached too, as they're the ones most likely to be around
+	s.Lock()
+	if len(s.cache) < s.capacity {
+		s.cache[uid] = all
+	}
+	s.Unlock()
+	return all, nil
+}
+
+// Set replaces the preferences uid has for a theme with vals, the values are expected to have already been checked with Theme.ValidUserSetting
+func (s *DefaultUserThemeSettingStore) Set(uid int, theme string, vals map[string]string) error {
+	// Drop it first, so we don't serve the old ones if something goes wrong part of the way through
+	s.evict(uid)
+	defer ClusterPublish(ClusterUser, uid)
+	_, err := s.delete.Exec(uid, theme)
+	if err != nil {
+		return err
+	}
+	for name, val := range vals {
+		_, err = s.insert.Exec(uid, theme, name, val)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteAll removes every preference belonging to uid, e.g. when their account is deleted
+func (s *DefaultUserThemeSettingStore) DeleteAll(uid int) error {
+	s.evict(uid)
+	_, err := s.deleteAll.Exec(uid)
+	return err
+}
+
+func (s *DefaultUserThemeSettingStore) evict(uid int) {
+	s.Lock()
+	delete(s.cache, uid)
+	s.Unlock()
+}
+
+func (s *DefaultUserThemeSettingStore) flush() {
+	s.Lock()
+	s.cache = make(map[int]map[string]map[string]string)
+	s.Unlock()
+}
+
+// Length returns the number of users who's preferences are in the memory cache
+func (s *DefaultUserThemeSettingStore) Length() int {
+	s.RLock()
+	defer s.RUnlock()
+	return len(s.cache)
+}
@@ -49,6 +49,11 @@ type Topic struct {
 	ClassName   string // CSS Class Name
 	Poll        int
 	Data        string // Used for report metadata
+	Prefix      int
+	Answer      int  // The ID of the reply which was accepted as the answer, if this is a question
+	Deleted     bool // Whether this topic has been moved into the trash
+	LastEdit    int  // A unix timestamp, zero if it has never been edited
+	LastEditBy  int
 
 	Rids []int
 }
@@ -90,9 +95,21 @@ type TopicUser struct {
 	Level int
 	Liked bool
 
-	Attachments []*MiniAttachment
-	Rids        []int
-	Deletable   bool
+	Attachments   []*MiniAttachment
+	Rids          []int
+	Deletable     bool
+	Editable      bool
+	Closable      bool // Whether the current user can lock or unlock it
+	ProfileFields []*ProfileFieldShow
+
+	Prefix      int
+	PrefixName  string
+	PrefixClass string
+	Tags        []TopicTag
+	Answer      int
+	Deleted     bool
+	LastEdit    int
+	LastEditBy  int
 }
 
 type TopicsRowMut struct {
@@ -112,6 +129,9 @@ type TopicsRow struct {
 
 	ForumName string //TopicsRow
 	ForumLink string
+
+	PrefixName  string
+	PrefixClass string
 }
 
 type WsTopicsRow struct {
@@ -137,16 +157,19 @@ type WsTopicsRow struct {
 	ForumName           string
 	ForumLink           string
 	CanMod              bool
+	PrefixName          string
+	PrefixClass         string
+	Answer              int
 }
 
 // TODO: Can we get the client side to render the relative times instead?
 func (r *TopicsRow) WebSockets() *WsTopicsRow {
-	return &WsTopicsRow{r.ID, r.Link, r.Title, r.CreatedBy, r.IsClosed, r.Sticky, r.CreatedAt, r.LastReplyAt, RelativeTime(r.LastReplyAt), r.LastReplyBy, r.LastReplyID, r.ParentID, r.ViewCount, r.PostCount, r.LikeCount, r.AttachCount, r.ClassName, r.Creator.WebSockets(), r.LastUser.WebSockets(), r.ForumName, r.ForumLink, false}
+	return &WsTopicsRow{r.ID, r.Link, r.Title, r.CreatedBy, r.IsClosed, r.Sticky, r.CreatedAt, r.LastReplyAt, RelativeTime(r.LastReplyAt), r.LastReplyBy, r.LastReplyID, r.ParentID, r.ViewCount, r.PostCount, r.LikeCount, r.AttachCount, r.ClassName, r.Creator.WebSockets(), r.LastUser.WebSockets(), r.ForumName, r.ForumLink, false, r.PrefixName, r.PrefixClass, r.Answer}
 }
 
 // TODO: Can we get the client side to render the relative times instead?
 func (r *TopicsRow) WebSockets2(canMod bool) *WsTopicsRow {
-	return &WsTopicsRow{r.ID, r.Link, r.Title, r.CreatedBy, r.IsClosed, r.Sticky, r.CreatedAt, r.LastReplyAt, RelativeTime(r.LastReplyAt), r.LastReplyBy, r.LastReplyID, r.ParentID, r.ViewCount, r.PostCount, r.LikeCount, r.AttachCount, r.ClassName, r.Creator.WebSockets(), r.LastUser.WebSockets(), r.ForumName, r.ForumLink, canMod}
+	return &WsTopicsRow{r.ID, r.Link, r.Title, r.CreatedBy, r.IsClosed, r.Sticky, r.CreatedAt, r.LastReplyAt, RelativeTime(r.LastReplyAt), r.LastReplyBy, r.LastReplyID, r.ParentID, r.ViewCount, r.PostCount, r.LikeCount, r.AttachCount, r.ClassName, r.Creator.WebSockets(), r.LastUser.WebSockets(), r.ForumName, r.ForumLink, canMod, r.PrefixName, r.PrefixClass, r.Answer}
 }
 
 // TODO: Stop relying on so many struct types?
@@ -160,7 +183,20 @@ func (t *Topic) TopicsRow() *TopicsRow {
 	forumLink := ""
 
 	//return &TopicsRow{t.ID, t.Link, t.Title, t.Content, t.CreatedBy, t.IsClosed, t.Sticky, t.CreatedAt, t.LastReplyAt, t.LastReplyBy, t.LastReplyID, t.ParentID, t.Status, t.IP, t.ViewCount, t.PostCount, t.LikeCount, t.AttachCount, lastPage, t.ClassName, t.Poll, t.Data, creator, "", contentLines, lastUser, forumName, forumLink, t.Rids}
-	return &TopicsRow{*t, lastPage, creator, "", contentLines, lastUser, forumName, forumLink}
+	row := &TopicsRow{*t, lastPage, creator, "", contentLines, lastUser, forumName, forumLink, "", ""}
+	row.setPrefix()
+	return row
+}
+
+// setPrefix fills in the name and class of the prefix on this topic, if it has one
+func (r *TopicsRow) setPrefix() {
+	if r.Prefix == 0 {
+		return
+	}
+	p, err := TopicPrefixes.Get(r.Prefix)
+	if err == nil {
+		r.PrefixName, r.PrefixClass = p.Name, p.CSSClass
+	}
 }
 
 // ! Some data may be lost in the conversion
@@ -183,6 +219,10 @@ type TopicStmts struct {
 	getReplies          *sql.Stmt
 	getReplies2         *sql.Stmt
 	getReplies3         *sql.Stmt
+	getRepliesV         *sql.Stmt // The same as getReplies, getReplies2 and getReplies3, just ordered by votes
+	getReplies2V        *sql.Stmt
+	getReplies3V        *sql.Stmt
+	setAnswer           *sql.Stmt
 	addReplies          *sql.Stmt
 	updateLastReply     *sql.Stmt
 	lock                *sql.Stmt
@@ -198,7 +238,9 @@ type TopicStmts struct {
 	deleteLikesForTopic *sql.Stmt
 	deleteActivity      *sql.Stmt
 	edit                *sql.Stmt
+	setEdited           *sql.Stmt
 	setPoll             *sql.Stmt
+	setPrefix           *sql.Stmt
 	createAction        *sql.Stmt
 
 	getTopicUser *sql.Stmt // TODO: Can we get rid of this?
@@ -211,10 +253,14 @@ func init() {
 	DbInits.Add(func(acc *qgen.Accumulator) error {
 		t := "topics"
 		topicStmts = TopicStmts{
-			getRids:             acc.Select("replies").Columns("rid").Where("tid=?").Orderby("rid ASC").Limit("?,?").Prepare(),
-			getReplies:          acc.SimpleLeftJoin("replies AS r", "users AS u", "r.rid, r.content, r.createdBy, r.createdAt, r.lastEdit, r.lastEditBy, u.avatar, u.name, u.group, u.level, r.ip, r.likeCount, r.attachCount, r.actionType", "r.createdBy=u.uid", "r.tid=?", "r.rid ASC", "?,?"),
-			getReplies2:         acc.SimpleLeftJoin("replies AS r", "users AS u", "r.rid, r.content, r.createdBy, r.createdAt, r.lastEdit, r.lastEditBy, u.avatar, u.name, u.group, u.level, r.likeCount, r.attachCount, r.actionType", "r.createdBy=u.uid", "r.tid=?", "r.rid ASC", "?,?"),
-			getReplies3:         acc.Select("replies").Columns("rid, content, createdBy, createdAt, lastEdit, lastEditBy, likeCount, attachCount, actionType").Where("tid=?").Orderby("rid ASC").Limit("?,?").Prepare(),
+			getRids:             acc.Select("replies").Columns("rid").Where("tid=? AND deleted=0").Orderby("rid ASC").Limit("?,?").Prepare(),
+			getReplies:          acc.SimpleLeftJoin("replies AS r", "users AS u", "r.rid, r.content, r.createdBy, r.createdAt, r.lastEdit, r.lastEditBy, u.avatar, u.name, u.group, u.level, r.ip, r.likeCount, r.attachCount, r.actionType, r.votes, r.deleted", "r.createdBy=u.uid", "r.tid=? AND r.deleted<=?", "r.rid ASC", "?,?"),
+			getReplies2:         acc.SimpleLeftJoin("replies AS r", "users AS u", "r.rid, r.content, r.createdBy, r.createdAt, r.lastEdit, r.lastEditBy, u.avatar, u.name, u.group, u.level, r.likeCount, r.attachCount, r.actionType, r.votes, r.deleted", "r.createdBy=u.uid", "r.tid=? AND r.deleted<=?", "r.rid ASC", "?,?"),
+			getReplies3:         acc.Select("replies").Columns("rid, content, createdBy, createdAt, lastEdit, lastEditBy, likeCount, attachCount, actionType, votes, deleted").Where("tid=? AND deleted<=?").Orderby("rid ASC").Limit("?,?").Prepare(),
+			getRepliesV:         acc.SimpleLeftJoin("replies AS r", "users AS u", "r.rid, r.content, r.createdBy, r.createdAt, r.lastEdit, r.lastEditBy, u.avatar, u.name, u.group, u.level, r.ip, r.likeCount, r.attachCount, r.actionType, r.votes, r.deleted", "r.createdBy=u.uid", "r.tid=? AND r.deleted<=?", "r.votes DESC, r.rid ASC", "?,?"),
+			getReplies2V:        acc.SimpleLeftJoin("replies AS r", "users AS u", "r.rid, r.content, r.createdBy, r.createdAt, r.lastEdit, r.lastEditBy, u.avatar, u.name, u.group, u.level, r.likeCount, r.attachCount, r.actionType, r.votes, r.deleted", "r.createdBy=u.uid", "r.tid=? AND r.deleted<=?", "r.votes DESC, r.rid ASC", "?,?"),
+			getReplies3V:        acc.Select("replies").Columns("rid, content, createdBy, createdAt, lastEdit, lastEditBy, likeCount, attachCount, actionType, votes, deleted").Where("tid=? AND deleted<=?").Orderby("votes DESC, rid ASC").Limit("?,?").Prepare(),
+			setAnswer:           acc.Update(t).Set("answer=?").Where("tid=?").Prepare(),
 			addReplies:          acc.Update(t).Set("postCount=postCount+?, lastReplyBy=?, lastReplyAt=UTC_TIMESTAMP()").Where("tid=?").Prepare(),
 			updateLastReply:     acc.Update(t).Set("lastReplyID=?").Where("lastReplyID > ? AND tid=?").Prepare(),
 			lock:                acc.Update(t).Set("is_closed=1").Where("tid=?").Prepare(),
@@ -231,9 +277,11 @@ func init() {
 			deleteActivity:      acc.Delete("activity_stream").Where("elementID=? AND elementType='topic'").Prepare(),
 			edit:                acc.Update(t).Set("title=?,content=?,parsed_content=?").Where("tid=?").Prepare(), // TODO: Only run the content update bits on non-polls, does this matter?
 			setPoll:             acc.Update(t).Set("poll=?").Where("tid=? AND poll=0").Prepare(),
+			setEdited:           acc.Update(t).Set("lastEdit=?,lastEditBy=?").Where("tid=?").Prepare(),
+			setPrefix:           acc.Update(t).Set("prefix=?").Where("tid=?").Prepare(),
 			createAction:        acc.Insert("replies").Columns("tid, actionType, ip, createdBy, createdAt, lastUpdated, content, parsed_content").Fields("?,?,?,?,UTC_TIMESTAMP(),UTC_TIMESTAMP(),'',''").Prepare(),
 
-			getTopicUser: acc.SimpleLeftJoin("topics AS t", "users AS u", "t.title, t.content, t.createdBy, t.createdAt, t.lastReplyAt, t.lastReplyBy, t.lastReplyID, t.is_closed, t.sticky, t.parentID, t.ip, t.views, t.postCount, t.likeCount, t.attachCount,t.poll, u.name, u.avatar, u.group, u.level", "t.createdBy=u.uid", "tid=?", "", ""),
+			getTopicUser: acc.SimpleLeftJoin("topics AS t", "users AS u", "t.title, t.content, t.createdBy, t.createdAt, t.lastReplyAt, t.lastReplyBy, t.lastReplyID, t.is_closed, t.sticky, t.parentID, t.ip, t.views, t.postCount, t.likeCount, t.attachCount,t.poll,t.prefix,t.answer,t.deleted,t.lastEdit,t.lastEditBy, u.name, u.avatar, u.group, u.level", "t.createdBy=u.uid", "tid=?", "", ""),
 			getByReplyID: acc.SimpleLeftJoin("replies AS r", "topics AS t", "t.tid, t.title, t.content, t.createdBy, t.createdAt, t.is_closed, t.sticky, t.parentID, t.ip, t.views, t.postCount, t.likeCount, t.poll, t.data", "r.tid=t.tid", "rid=?", "", ""),
 		}
 		return acc.FirstError()
@@ -438,10 +486,17 @@ func (t *Topic) Delete() error {
 	if err != nil {
 		return err
 	}
-	err = Forums.RemoveTopic(t.ParentID)
-	if err != nil && err != ErrNoRows {
+	err = Revisions.DeleteByContent(t.ID, "topics")
+	if err != nil {
 		return err
 	}
+	// Topics in the trash have already been taken off the forum's topic count
+	if !t.Deleted {
+		err = Forums.RemoveTopic(t.ParentID)
+		if err != nil && err != ErrNoRows {
+			return err
+		}
+	}
 	_, err = topicStmts.deleteLikesForTopic.Exec(t.ID)
 	if err != nil {
 		return err
@@ -457,10 +512,18 @@ func (t *Topic) Delete() error {
 		if err != nil {
 			return err
 		}
+		err = Revisions.DeleteByTopic(t.ID)
+		if err != nil {
+			return err
+		}
 		_, err = topicStmts.deleteReplies.Exec(t.ID)
 		if err != nil {
 			return err
 		}
+		err = Votes.DeleteByTopic(t.ID)
+		if err != nil {
+			return err
+		}
 		for uid := range umap {
 			err = (&User{ID: uid}).RecalcPostStats()
 			if err != nil {
@@ -485,6 +548,14 @@ func (t *Topic) Delete() error {
 	if err != nil {
 		return err
 	}
+	err = TopicTags.DeleteAll(t.ID)
+	if err != nil {
+		return err
+	}
+	err = TopicTimedActions.DeleteByTopic(t.ID)
+	if err != nil {
+		return err
+	}
 	if t.Poll > 0 {
 		err = (&Poll{ID: t.Poll}).Delete()
 		if err != nil {
@@ -495,7 +566,8 @@ func (t *Topic) Delete() error {
 }
 
 // TODO: Write tests for this
-func (t *Topic) Update(name, content string) error {
+// Update changes the title and content of this topic, the old version is kept as a revision. uid is the person making the edit and ip is where they made it from.
+func (t *Topic) Update(name, content, ip string, uid int) error {
 	name = SanitiseSingleLine(html.UnescapeString(name))
 	if name == "" {
 		return ErrNoTitle
@@ -508,16 +580,42 @@ func (t *Topic) Update(name, content string) error {
 	content = PreparseMessage(html.UnescapeString(content))
 	parsedContent := ParseMessage(content, t.ParentID, "forums", nil, nil)
 	_, err := topicStmts.edit.Exec(name, content, parsedContent, t.ID)
+	if err == nil && (name != t.Title || content != t.Content) {
+		orig := &Revision{ContentID: t.ID, ContentType: "topics", Title: t.Title, Content: t.Content, CreatedBy: t.CreatedBy, CreatedAt: t.CreatedAt, IP: t.IP}
+		err = addRevision(orig, name, content, ip, uid)
+		if err == nil {
+			_, err = topicStmts.setEdited.Exec(int(time.Now().Unix()), uid, t.ID)
+		}
+	}
 	t.cacheRemove()
 	return err
 }
 
+// Revert puts this topic back the way it was in rev, this is recorded as another edit, so it can be undone
+func (t *Topic) Revert(rev *Revision, ip string, uid int) error {
+	return t.Update(rev.Title, rev.Content, ip, uid)
+}
+
 func (t *Topic) SetPoll(pollID int) error {
 	_, err := topicStmts.setPoll.Exec(pollID, t.ID) // TODO: Sniff if this changed anything to see if we hit an existing poll
 	t.cacheRemove()
 	return err
 }
 
+// SetPrefix changes the prefix on this topic, zero removes it
+func (t *Topic) SetPrefix(prid int) error {
+	_, err := topicStmts.setPrefix.Exec(prid, t.ID)
+	t.cacheRemove()
+	return err
+}
+
+// SetAnswer marks the reply rid as the accepted answer to this question, zero unmarks it
+func (t *Topic) SetAnswer(rid int) error {
+	_, err := topicStmts.setAnswer.Exec(rid, t.ID)
+	t.cacheRemove()
+	return err
+}
+
 // TODO: Have this go through the ReplyStore?
 func (t *Topic) CreateActionReply(action, ip string, uid int) (err error) {
 	if Config.DisablePostIP {
@@ -566,6 +664,18 @@ var lockai = "&#x1F512" + aipost
 var unlockai = "&#x1F513"
 var stickai = "&#x1F4CC"
 var unstickai = "&#x1F4CC" + aipost
+var splitai = "&#x2702" + aipost
+var mergeai = "&#x1F517"
+
+// otherTopicAction renders the actions which point at another topic, e.g. the one some replies were split off into
+func otherTopicAction(action, stid string, ru *ReplyUser) (string, bool) {
+	tid, _ := strconv.Atoi(stid)
+	t, err := Topics.Get(tid)
+	if err != nil {
+		return "", false
+	}
+	return p.GetTmplPhrasef("topic.action_topic_"+action+"_dest", t.Link, t.Title, ru.UserLink, ru.CreatedByName), true
+}
 
 func (ru *ReplyUser) Init(u *User) (group *Group, err error) {
 	ru.ContentLines = strings.Count(ru.Content, "\n")
@@ -610,6 +720,16 @@ func (ru *ReplyUser) Init(u *User) (group *Group, err error) {
 					return postGroup, nil
 				}
 			}
+		case "merge":
+			ru.ActionIcon = mergeai
+		case "split", "movereplies":
+			ru.ActionIcon = splitai
+			if len(aarr) == 2 {
+				if at, ok := otherTopicAction(action, aarr[1], ru); ok {
+					ru.ActionType = at
+					return postGroup, nil
+				}
+			}
 		default:
 			// TODO: Only fire this off if a corresponding phrase for the ActionType doesn't exist? Or maybe have some sort of action registry?
 			ru.ActionType = p.GetTmplPhrasef("topic.action_topic_default", ru.ActionType)
@@ -656,6 +776,16 @@ func (ru *ReplyUser) Init2() (group *Group, err error) {
 					return postGroup, nil
 				}
 			}
+		case "merge":
+			ru.ActionIcon = mergeai
+		case "split", "movereplies":
+			ru.ActionIcon = splitai
+			if len(aarr) == 2 {
+				if at, ok := otherTopicAction(action, aarr[1], ru); ok {
+					ru.ActionType = at
+					return postGroup, nil
+				}
+			}
 		default:
 			// TODO: Only fire this off if a corresponding phrase for the ActionType doesn't exist? Or maybe have some sort of action registry?
 			ru.ActionType = p.GetTmplPhrasef("topic.action_topic_default", ru.ActionType)
@@ -717,6 +847,18 @@ func (ru *ReplyUser) Init3(u *User, tu *TopicUser) (group *Group, err error) {
 					return postGroup, nil
 				}
 			}
+		case "merge":
+			ru.ActionIcon = mergeai
+			action = "topic.action_topic_merge"
+		case "split", "movereplies":
+			ru.ActionIcon = splitai
+			if len(aarr) == 2 {
+				if at, ok := otherTopicAction(action, aarr[1], ru); ok {
+					ru.ActionType = at
+					return postGroup, nil
+				}
+			}
+			action = "topic.action_topic_" + action
 		default:
 			// TODO: Only fire this off if a corresponding phrase for the ActionType doesn't exist? Or maybe have some sort of action registry?
 			ru.ActionType = p.GetTmplPhrasef("topic.action_topic_default", ru.ActionType)
@@ -728,10 +870,48 @@ func (ru *ReplyUser) Init3(u *User, tu *TopicUser) (group *Group, err error) {
 	return postGroup, nil
 }
 
+// AnswerReply fetches the accepted answer to this question, so that it can be pinned below the opening post
+func (t *TopicUser) AnswerReply(user *User) (*ReplyUser, error) {
+	re, err := Rstore.Get(t.Answer)
+	if err != nil {
+		return nil, err
+	}
+	if re.Deleted {
+		return nil, ErrNoRows
+	}
+	ru := &ReplyUser{Reply: *re, Accepted: true}
+	creator, err := Users.Get(re.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	ru.CreatedByName, ru.Avatar, ru.Group, ru.Level = creator.Name, creator.RawAvatar, creator.Group, creator.Level
+	postGroup, err := ru.Init3(user, t)
+	if err != nil {
+		return nil, err
+	}
+
+	var parseSettings *ParseSettings
+	if (Config.NoEmbed || !postGroup.Perms.AutoEmbed) && (user.ParseSettings == nil || !user.ParseSettings.NoEmbed) {
+		parseSettings = DefaultParseSettings.CopyPtr()
+		parseSettings.NoEmbed = true
+	} else {
+		parseSettings = user.ParseSettings
+	}
+	ru.ContentHtml = ParseMessage(ru.Content, t.ParentID, "forums", parseSettings, user)
+	ru.Deletable = user.CanDeleteReply(t.CreatedBy, ru.CreatedBy, ru.CreatedAt)
+	ru.Editable = user.CanEditReply(t.CreatedBy, ru.CreatedBy, ru.CreatedAt)
+	return ru, nil
+}
+
 // TODO: Factor TopicUser into a *Topic and *User, as this starting to become overly complicated x.x
-func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*ReplyUser /*, ogdesc string*/, externalHead bool, err error) {
+// The replies in the trash are only included when showDeleted is set, that should be limited to those who can see the trash
+func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User, byVotes, showDeleted bool) (rlist []*ReplyUser /*, ogdesc string*/, externalHead bool, err error) {
 	var likedMap, attachMap map[int]int
 	var likedQueryList, attachQueryList []int
+	getReplies, getReplies2, getReplies3 := topicStmts.getReplies, topicStmts.getReplies2, topicStmts.getReplies3
+	if byVotes {
+		getReplies, getReplies2, getReplies3 = topicStmts.getRepliesV, topicStmts.getReplies2V, topicStmts.getReplies3V
+	}
 
 	var rid int
 	if len(t.Rids) > 0 {
@@ -739,6 +919,14 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 		rid = t.Rids[0]
 	}
 	re, err := Rstore.GetCache().Get(rid)
+	if err == nil && re.Deleted && !showDeleted {
+		err = ErrNoRows
+	}
+	// Used as a query parameter, a deleted flag of 1 only shows up when we're looking for them
+	var deleted int
+	if showDeleted {
+		deleted = 1
+	}
 	ucache := Users.GetCache()
 	var ruser *User
 	if ucache != nil {
@@ -776,7 +964,8 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 		if r.ContentHtml == r.Content {
 			r.ContentHtml = r.Content
 		}
-		r.Deletable = user.Perms.DeleteReply || r.CreatedBy == user.ID
+		r.Deletable = user.CanDeleteReply(t.CreatedBy, r.CreatedBy, r.CreatedAt)
+		r.Editable = user.CanEditReply(t.CreatedBy, r.CreatedBy, r.CreatedAt)
 
 		// TODO: This doesn't work properly so pick the first one instead?
 		/*if r.ID == pFrag {
@@ -813,7 +1002,8 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 		if r.ContentHtml == r.Content {
 			r.ContentHtml = r.Content
 		}
-		r.Deletable = user.Perms.DeleteReply || r.CreatedBy == user.ID
+		r.Deletable = user.CanDeleteReply(t.CreatedBy, r.CreatedBy, r.CreatedAt)
+		r.Editable = user.CanEditReply(t.CreatedBy, r.CreatedBy, r.CreatedAt)
 
 		return nil
 	}
@@ -873,14 +1063,14 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 			}
 		}
 		if !user.Perms.ViewIPs && ruser != nil {
-			rows, e := topicStmts.getReplies3.Query(t.ID, offset, Config.ItemsPerPage)
+			rows, e := getReplies3.Query(t.ID, deleted, offset, Config.ItemsPerPage)
 			if err != nil {
 				return nil, externalHead, e
 			}
 			defer rows.Close()
 			for rows.Next() {
 				r := &ReplyUser{Avatar: ruser.Avatar, MicroAvatar: ruser.MicroAvatar, UserLink: ruser.Link, CreatedByName: ruser.Name, Group: ruser.Group, Level: ruser.Level}
-				e := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.LikeCount, &r.AttachCount, &r.ActionType)
+				e := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.LikeCount, &r.AttachCount, &r.ActionType, &r.Votes, &r.Deleted)
 				if e != nil {
 					return nil, externalHead, e
 				}
@@ -894,14 +1084,14 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 				return nil, externalHead, e
 			}
 		} else if user.Perms.ViewIPs {
-			rows, err := topicStmts.getReplies.Query(t.ID, offset, Config.ItemsPerPage)
+			rows, err := getReplies.Query(t.ID, deleted, offset, Config.ItemsPerPage)
 			if err != nil {
 				return nil, externalHead, err
 			}
 			defer rows.Close()
 			for rows.Next() {
 				r := &ReplyUser{}
-				err := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.Avatar, &r.CreatedByName, &r.Group /*&r.URLPrefix, &r.URLName,*/, &r.Level, &r.IP, &r.LikeCount, &r.AttachCount, &r.ActionType)
+				err := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.Avatar, &r.CreatedByName, &r.Group /*&r.URLPrefix, &r.URLName,*/, &r.Level, &r.IP, &r.LikeCount, &r.AttachCount, &r.ActionType, &r.Votes, &r.Deleted)
 				if err != nil {
 					return nil, externalHead, err
 				}
@@ -916,7 +1106,7 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 			}
 		} else if t.PostCount >= 20 {
 			//log.Print("t.PostCount >= 20")
-			rows, err := topicStmts.getReplies3.Query(t.ID, offset, Config.ItemsPerPage)
+			rows, err := getReplies3.Query(t.ID, deleted, offset, Config.ItemsPerPage)
 			if err != nil {
 				return nil, externalHead, err
 			}
@@ -924,7 +1114,7 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 			reqUserList := make(map[int]bool)
 			for rows.Next() {
 				r := &ReplyUser{}
-				err := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy /*&r.URLPrefix, &r.URLName,*/, &r.LikeCount, &r.AttachCount, &r.ActionType)
+				err := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy /*&r.URLPrefix, &r.URLName,*/, &r.LikeCount, &r.AttachCount, &r.ActionType, &r.Votes, &r.Deleted)
 				if err != nil {
 					return nil, externalHead, err
 				}
@@ -1017,14 +1207,14 @@ func (t *TopicUser) Replies(offset int /*pFrag int, */, user *User) (rlist []*Re
 			}
 		} else {
 			//log.Print("reply fallback")
-			rows, err := topicStmts.getReplies2.Query(t.ID, offset, Config.ItemsPerPage)
+			rows, err := getReplies2.Query(t.ID, deleted, offset, Config.ItemsPerPage)
 			if err != nil {
 				return nil, externalHead, err
 			}
 			defer rows.Close()
 			for rows.Next() {
 				r := &ReplyUser{}
-				err := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.Avatar, &r.CreatedByName, &r.Group /*&r.URLPrefix, &r.URLName,*/, &r.Level, &r.LikeCount, &r.AttachCount, &r.ActionType)
+				err := rows.Scan(&r.ID, &r.Content, &r.CreatedBy, &r.CreatedAt, &r.LastEdit, &r.LastEditBy, &r.Avatar, &r.CreatedByName, &r.Group /*&r.URLPrefix, &r.URLName,*/, &r.Level, &r.LikeCount, &r.AttachCount, &r.ActionType, &r.Votes, &r.Deleted)
 				if err != nil {
 					return nil, externalHead, err
 				}
@@ -1131,7 +1321,7 @@ func GetTopicUser(user *User, tid int) (tu TopicUser, err error) {
 
 	tu = TopicUser{ID: tid}
 	// TODO: This misses some important bits...
-	err = topicStmts.getTopicUser.QueryRow(tid).Scan(&tu.Title, &tu.Content, &tu.CreatedBy, &tu.CreatedAt, &tu.LastReplyAt, &tu.LastReplyBy, &tu.LastReplyID, &tu.IsClosed, &tu.Sticky, &tu.ParentID, &tu.IP, &tu.ViewCount, &tu.PostCount, &tu.LikeCount, &tu.AttachCount, &tu.Poll, &tu.CreatedByName, &tu.Avatar, &tu.Group, &tu.Level)
+	err = topicStmts.getTopicUser.QueryRow(tid).Scan(&tu.Title, &tu.Content, &tu.CreatedBy, &tu.CreatedAt, &tu.LastReplyAt, &tu.LastReplyBy, &tu.LastReplyID, &tu.IsClosed, &tu.Sticky, &tu.ParentID, &tu.IP, &tu.ViewCount, &tu.PostCount, &tu.LikeCount, &tu.AttachCount, &tu.Poll, &tu.Prefix, &tu.Answer, &tu.Deleted, &tu.LastEdit, &tu.LastEditBy, &tu.CreatedByName, &tu.Avatar, &tu.Group, &tu.Level)
 	tu.Avatar, tu.MicroAvatar = BuildAvatar(tu.CreatedBy, tu.Avatar)
 	tu.Link = BuildTopicURL(NameToSlug(tu.Title), tu.ID)
 	tu.UserLink = BuildProfileURL(NameToSlug(tu.CreatedByName), tu.CreatedBy)
@@ -1139,7 +1329,7 @@ func GetTopicUser(user *User, tid int) (tu TopicUser, err error) {
 
 	if tcache != nil {
 		// TODO: weekly views
-		theTopic := Topic{ID: tu.ID, Link: tu.Link, Title: tu.Title, Content: tu.Content, CreatedBy: tu.CreatedBy, IsClosed: tu.IsClosed, Sticky: tu.Sticky, CreatedAt: tu.CreatedAt, LastReplyAt: tu.LastReplyAt, LastReplyID: tu.LastReplyID, ParentID: tu.ParentID, IP: tu.IP, ViewCount: tu.ViewCount, PostCount: tu.PostCount, LikeCount: tu.LikeCount, AttachCount: tu.AttachCount, Poll: tu.Poll}
+		theTopic := Topic{ID: tu.ID, Link: tu.Link, Title: tu.Title, Content: tu.Content, CreatedBy: tu.CreatedBy, IsClosed: tu.IsClosed, Sticky: tu.Sticky, CreatedAt: tu.CreatedAt, LastReplyAt: tu.LastReplyAt, LastReplyID: tu.LastReplyID, ParentID: tu.ParentID, IP: tu.IP, ViewCount: tu.ViewCount, PostCount: tu.PostCount, LikeCount: tu.LikeCount, AttachCount: tu.AttachCount, Poll: tu.Poll, Prefix: tu.Prefix, Answer: tu.Answer, Deleted: tu.Deleted, LastEdit: tu.LastEdit, LastEditBy: tu.LastEditBy}
 		//log.Printf("theTopic: %+v\n", theTopic)
 		_ = tcache.Set(&theTopic)
 	}
@@ -1174,6 +1364,11 @@ func copyTopicToTopicUser(t *Topic, u *User) (tu TopicUser) {
 	tu.AttachCount = t.AttachCount
 	tu.Poll = t.Poll
 	tu.Data = t.Data
+	tu.Prefix = t.Prefix
+	tu.Answer = t.Answer
+	tu.Deleted = t.Deleted
+	tu.LastEdit = t.LastEdit
+	tu.LastEditBy = t.LastEditBy
 	tu.Rids = t.Rids
 
 	return tu
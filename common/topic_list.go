@@ -17,6 +17,20 @@ const (
 	TopicListWeekViews
 )
 
+const (
+	TopicListSolvedAny = iota
+	TopicListSolvedOnly
+	TopicListUnsolvedOnly
+)
+
+// TopicListFilter narrows a topic list down to the topics which match it, the zero value lets everything through
+type TopicListFilter struct {
+	Tag       string
+	Prefix    int
+	Solved    int // One of the TopicListSolved* constants, anything other than TopicListSolvedAny limits the list to question forums
+	CreatedBy int // Only the topics started by this user, for the forums where people can only see their own topics
+}
+
 type TopicListHolder struct {
 	List      []*TopicsRow
 	ForumList []Forum
@@ -29,7 +43,7 @@ type ForumTopicListHolder struct {
 }
 
 type TopicListInt interface {
-	GetListByCanSee(canSee []int, page, orderby int, filterIDs []int) (topicList []*TopicsRow, forumList []Forum, pagi Paginator, err error)
+	GetListByCanSee(canSee []int, page, orderby int, filterIDs []int, filter TopicListFilter) (topicList []*TopicsRow, forumList []Forum, pagi Paginator, err error)
 	GetListByGroup(g *Group, page, orderby int, filterIDs []int) (topicList []*TopicsRow, forumList []Forum, pagi Paginator, err error)
 	GetListByForum(f *Forum, page, orderby int) (topicList []*TopicsRow, pagi Paginator, err error)
 	GetList(page, orderby int, filterIDs []int) (topicList []*TopicsRow, forumList []Forum, pagi Paginator, err error)
@@ -67,7 +81,7 @@ func NewDefaultTopicList(acc *qgen.Accumulator) (*DefaultTopicList, error) {
 		forums:           make(map[int]*ForumTopicListHolder),
 		qcounts:          make(map[int]*sql.Stmt),
 		qcounts2:         make(map[int]*sql.Stmt),
-		getTopicsByForum: acc.Select("topics").Columns("tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,views,postCount,likeCount").Where("parentID=?").Orderby("sticky DESC,lastReplyAt DESC,createdBy DESC").Limit("?,?").Prepare(),
+		getTopicsByForum: acc.Select("topics").Columns("tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,views,postCount,likeCount,prefix,answer").Where("parentID=? AND deleted=0").Orderby("sticky DESC,lastReplyAt DESC,createdBy DESC").Limit("?,?").Prepare(),
 		//getTidsByForum: acc.Select("topics").Columns("tid").Where("parentID=?").Orderby("sticky DESC,lastReplyAt DESC,createdBy DESC").Limit("?,?").Prepare(),
 	}
 	if err := acc.FirstError(); err != nil {
@@ -129,11 +143,11 @@ func (tList *DefaultTopicList) Tick() error {
 	canSeeHolders := make(map[string][2]*TopicListHolder)
 	forumCounts := make(map[int]int)
 	for name, canSee := range permTree {
-		topicList, forumList, pagi, err := tList.GetListByCanSee(canSee, 1, 0, nil)
+		topicList, forumList, pagi, err := tList.GetListByCanSee(canSee, 1, 0, nil, TopicListFilter{})
 		if err != nil {
 			return err
 		}
-		topicList2, forumList2, pagi2, err := tList.GetListByCanSee(canSee, 2, 0, nil)
+		topicList2, forumList2, pagi2, err := tList.GetListByCanSee(canSee, 2, 0, nil, TopicListFilter{})
 		if err != nil {
 			return err
 		}
@@ -188,15 +202,15 @@ func (tList *DefaultTopicList) Tick() error {
 			}
 			qlist += "?"
 		}
-		cols := "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data"
+		cols := "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,prefix,answer"
 
-		stmt, err := qgen.Builder.SimpleSelect("topics", cols, "parentID IN("+qlist+")", "views DESC,lastReplyAt DESC,createdBy DESC", "?,?")
+		stmt, err := qgen.Builder.SimpleSelect("topics", cols, "parentID IN("+qlist+") AND deleted=0", "views DESC,lastReplyAt DESC,createdBy DESC", "?,?")
 		if err != nil {
 			return err
 		}
 		qcounts[top] = stmt
 
-		stmt, err = qgen.Builder.SimpleSelect("topics", cols, "parentID IN("+qlist+")", "sticky DESC,lastReplyAt DESC,createdBy DESC", "?,?")
+		stmt, err = qgen.Builder.SimpleSelect("topics", cols, "parentID IN("+qlist+") AND deleted=0", "sticky DESC,lastReplyAt DESC,createdBy DESC", "?,?")
 		if err != nil {
 			return err
 		}
@@ -358,7 +372,7 @@ func (tList *DefaultTopicList) GetListByForum(f *Forum, page, orderby int) (topi
 }
 
 func (tList *DefaultTopicList) getListByForum(f *Forum, page, orderby int) (topicList []*TopicsRow, pagi Paginator, err error) {
-	// TODO: Does forum.TopicCount take the deleted items into consideration for guests? We don't have soft-delete yet, only hard-delete
+	// forum.TopicCount leaves out the topics in the trash, so it lines up with what getTopicsByForum returns
 	offset, page, lastPage := PageOffset(f.TopicCount, page, Config.ItemsPerPage)
 
 	rows, err := tList.getTopicsByForum.Query(f.ID, offset, Config.ItemsPerPage)
@@ -371,12 +385,13 @@ func (tList *DefaultTopicList) getListByForum(f *Forum, page, orderby int) (topi
 	reqUserList := make(map[int]bool)
 	for rows.Next() {
 		t := TopicsRow{Topic: Topic{ParentID: f.ID}}
-		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.CreatedBy, &t.IsClosed, &t.Sticky, &t.CreatedAt, &t.LastReplyAt, &t.LastReplyBy, &t.LastReplyID, &t.ViewCount, &t.PostCount, &t.LikeCount)
+		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.CreatedBy, &t.IsClosed, &t.Sticky, &t.CreatedAt, &t.LastReplyAt, &t.LastReplyBy, &t.LastReplyID, &t.ViewCount, &t.PostCount, &t.LikeCount, &t.Prefix, &t.Answer)
 		if err != nil {
 			return nil, Paginator{nil, 1, 1}, err
 		}
 
 		t.Link = BuildTopicURL(NameToSlug(t.Title), t.ID)
+		t.setPrefix()
 		// TODO: Create a specialised function with a bit less overhead for getting the last page for a post count
 		_, _, lastPage := PageOffset(t.PostCount, 1, Config.ItemsPerPage)
 		t.LastPage = lastPage
@@ -440,16 +455,17 @@ func (tList *DefaultTopicList) GetListByGroup(g *Group, page, orderby int, filte
 
 	// TODO: Make CanSee a method on *Group with a canSee field? Have a CanSee method on *User to cover the case of superadmins?
 	//log.Printf("deoptimising for %d on page %d\n", g.ID, page)
-	return tList.GetListByCanSee(g.CanSee, page, orderby, filterIDs)
+	return tList.GetListByCanSee(g.CanSee, page, orderby, filterIDs, TopicListFilter{})
 }
 
-func (tList *DefaultTopicList) GetListByCanSee(canSee []int, page, orderby int, filterIDs []int) (topicList []*TopicsRow, forumList []Forum, pagi Paginator, err error) {
+// GetListByCanSee fetches a page of the topics in the forums in canSee. A non-zero filter narrows the list down to the topics which match it.
+func (tList *DefaultTopicList) GetListByCanSee(canSee []int, page, orderby int, filterIDs []int, filter TopicListFilter) (topicList []*TopicsRow, forumList []Forum, pagi Paginator, err error) {
 	// TODO: Optimise this by filtering canSee and then fetching the forums?
 	// We need a list of the visible forums for Quick Topic
 	// ? - Would it be useful, if we could post in social groups from /topics/?
 	for _, fid := range canSee {
 		f := Forums.DirtyGet(fid)
-		if f.Name != "" && f.Active && (f.ParentType == "" || f.ParentType == "forum") /*&& f.TopicCount != 0*/ {
+		if f.Name != "" && f.Active && !f.Category && (f.ParentType == "" || f.ParentType == "forum") /*&& f.TopicCount != 0*/ {
 			fcopy := f.Copy()
 			// TODO: Add a hook here for plugin_guilds !!
 			forumList = append(forumList, fcopy)
@@ -466,16 +482,22 @@ func (tList *DefaultTopicList) GetListByCanSee(canSee []int, page, orderby int,
 	}
 
 	var filteredForums []Forum
-	if len(filterIDs) > 0 {
+	if len(filterIDs) > 0 || filter.Solved != TopicListSolvedAny {
 		for _, f := range forumList {
-			if inSlice(filterIDs, f.ID) {
-				filteredForums = append(filteredForums, f)
+			if len(filterIDs) > 0 && !inSlice(filterIDs, f.ID) {
+				continue
 			}
+			// Only questions can be solved, so there is no point in looking anywhere else
+			if filter.Solved != TopicListSolvedAny && !f.Questions {
+				continue
+			}
+			filteredForums = append(filteredForums, f)
 		}
 	} else {
 		filteredForums = forumList
 	}
-	if len(filteredForums) == 1 && orderby == 0 {
+	filtered := filter != (TopicListFilter{})
+	if len(filteredForums) == 1 && orderby == 0 && !filtered {
 		topicList, pagi, err = tList.GetListByForum(&filteredForums[0], page, orderby)
 		return topicList, forumList, pagi, err
 	}
@@ -491,6 +513,10 @@ func (tList *DefaultTopicList) GetListByCanSee(canSee []int, page, orderby int,
 		// We don't want to kill the page, so pass an empty slice and nil error
 		return topicList, filteredForums, Paginator{[]int{}, 1, 1}, nil
 	}
+	if filtered {
+		topicList, pagi, err = tList.getFilteredList(page, orderby, argList, qlist, filter)
+		return topicList, filteredForums, pagi, err
+	}
 
 	topicList, pagi, err = tList.getList(page, orderby, topicCount, argList, qlist)
 	return topicList, filteredForums, pagi, err
@@ -530,7 +556,7 @@ func (tList *DefaultTopicList) GetList(page, orderby int, filterIDs []int) (topi
 	var topicCount int
 	for _, fid := range canSee {
 		f := Forums.DirtyGet(fid)
-		if f.Name != "" && f.Active && (f.ParentType == "" || f.ParentType == "forum") /*&& f.TopicCount != 0*/ {
+		if f.Name != "" && f.Active && !f.Category && (f.ParentType == "" || f.ParentType == "forum") /*&& f.TopicCount != 0*/ {
 			fcopy := f.Copy()
 			// TODO: Add a hook here for plugin_guilds
 			forumList = append(forumList, fcopy)
@@ -553,9 +579,49 @@ func (tList *DefaultTopicList) GetList(page, orderby int, filterIDs []int) (topi
 	return topicList, forumList, pagi, err
 }
 
+// getFilteredList narrows a topic list down to the topics which match filter. The cached statements can't be used for these, as the number of parameters varies.
+func (tList *DefaultTopicList) getFilteredList(page, orderby int, argList []interface{}, qlist string, filter TopicListFilter) (topicList []*TopicsRow, pagi Paginator, err error) {
+	where := "parentID IN(" + qlist + ") AND deleted=0"
+	if filter.Prefix != 0 {
+		where += " AND prefix=?"
+		argList = append(argList, strconv.Itoa(filter.Prefix))
+	}
+	if filter.CreatedBy != 0 {
+		where += " AND createdBy=?"
+		argList = append(argList, strconv.Itoa(filter.CreatedBy))
+	}
+	switch filter.Solved {
+	case TopicListSolvedOnly:
+		where += " AND answer!=0"
+	case TopicListUnsolvedOnly:
+		where += " AND answer=0"
+	}
+	if filter.Tag != "" {
+		tagArgs, tagQ, err := tagFilterQ(filter.Tag)
+		if err != nil {
+			return nil, Paginator{nil, 1, 1}, err
+		}
+		if tagQ == "" {
+			return topicList, Paginator{[]int{}, 1, 1}, nil
+		}
+		where += " AND " + tagQ
+		argList = append(argList, tagArgs...)
+	}
+
+	topicCount, err := whereToTopicCount(argList, where)
+	if err != nil {
+		return nil, Paginator{nil, 1, 1}, err
+	}
+	return tList.getListWhere(page, orderby, topicCount, argList, where, true)
+}
+
 // TODO: Rename this to TopicListStore and pass back a TopicList instance holding the pagination data and topic list rather than passing them back one argument at a time
 // TODO: Make orderby an enum of sorts
 func (tList *DefaultTopicList) getList(page, orderby, topicCount int, argList []interface{}, qlist string) (topicList []*TopicsRow, paginator Paginator, err error) {
+	return tList.getListWhere(page, orderby, topicCount, argList, "parentID IN("+qlist+") AND deleted=0", false)
+}
+
+func (tList *DefaultTopicList) getListWhere(page, orderby, topicCount int, argList []interface{}, where string, filtered bool) (topicList []*TopicsRow, paginator Paginator, err error) {
 	if topicCount == 0 {
 		return nil, Paginator{nil, 1, 1}, err
 	}
@@ -565,52 +631,58 @@ func (tList *DefaultTopicList) getList(page, orderby, topicCount int, argList []
 	var stmt *sql.Stmt
 	switch orderby {
 	case TopicListWeekViews:
-		tList.qLock.RLock()
-		stmt = tList.qcounts[len(argList)-2]
-		tList.qLock.RUnlock()
+		if !filtered {
+			tList.qLock.RLock()
+			stmt = tList.qcounts[len(argList)-2]
+			tList.qLock.RUnlock()
+		}
 		if stmt == nil {
 			orderq = "weekViews DESC,lastReplyAt DESC,createdBy DESC"
 			now := time.Now()
 			_, week := now.ISOWeek()
 			day := int(now.Weekday()) + 1
 			if week%2 == 0 { // is even?
-				cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,FLOOR(weekEvenViews+((weekOddViews/7)*" + strconv.Itoa(day) + ")) AS weekViews"
+				cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,prefix,answer,FLOOR(weekEvenViews+((weekOddViews/7)*" + strconv.Itoa(day) + ")) AS weekViews"
 			} else {
-				cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,FLOOR(weekOddViews+((weekEvenViews/7)*" + strconv.Itoa(day) + ")) AS weekViews"
+				cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,prefix,answer,FLOOR(weekOddViews+((weekEvenViews/7)*" + strconv.Itoa(day) + ")) AS weekViews"
 			}
-			topicCount, err = ArgQToWeekViewTopicCount(argList, qlist)
+			topicCount, err = whereToTopicCount(argList, where+" AND (weekEvenViews!=0 OR weekOddViews!=0)")
 			if err != nil {
 				return nil, Paginator{nil, 1, 1}, err
 			}
 			acc := qgen.NewAcc()
-			stmt = acc.Select("topics").Columns(cols).Where("parentID IN(" + qlist + ") AND (weekEvenViews!=0 OR weekOddViews!=0)").Orderby(orderq).Limit("?,?").ComplexPrepare()
+			stmt = acc.Select("topics").Columns(cols).Where(where + " AND (weekEvenViews!=0 OR weekOddViews!=0)").Orderby(orderq).Limit("?,?").ComplexPrepare()
 			if e := acc.FirstError(); e != nil {
 				return nil, Paginator{nil, 1, 1}, e
 			}
 			defer stmt.Close()
 		}
 	case TopicListMostViewed:
-		tList.qLock.RLock()
-		stmt = tList.qcounts[len(argList)-2]
-		tList.qLock.RUnlock()
+		if !filtered {
+			tList.qLock.RLock()
+			stmt = tList.qcounts[len(argList)-2]
+			tList.qLock.RUnlock()
+		}
 		if stmt == nil {
 			orderq = "views DESC,lastReplyAt DESC,createdBy DESC"
-			cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,weekEvenViews"
+			cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,prefix,answer,weekEvenViews"
 		}
 	default:
-		tList.qLock2.RLock()
-		stmt = tList.qcounts2[len(argList)-2]
-		tList.qLock2.RUnlock()
+		if !filtered {
+			tList.qLock2.RLock()
+			stmt = tList.qcounts2[len(argList)-2]
+			tList.qLock2.RUnlock()
+		}
 		if stmt == nil {
 			orderq = "sticky DESC,lastReplyAt DESC,createdBy DESC"
-			cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,weekEvenViews"
+			cols = "tid,title,content,createdBy,is_closed,sticky,createdAt,lastReplyAt,lastReplyBy,lastReplyID,parentID,views,postCount,likeCount,attachCount,poll,data,prefix,answer,weekEvenViews"
 		}
 	}
 	offset, page, lastPage := PageOffset(topicCount, page, Config.ItemsPerPage)
 
 	// TODO: Prepare common qlist lengths to speed this up in common cases, prepared statements are prepared lazily anyway, so it probably doesn't matter if we do ten or so
 	if stmt == nil {
-		stmt, err = qgen.Builder.SimpleSelect("topics", cols, "parentID IN("+qlist+")", orderq, "?,?")
+		stmt, err = qgen.Builder.SimpleSelect("topics", cols, where, orderq, "?,?")
 		if err != nil {
 			return nil, Paginator{nil, 1, 1}, err
 		}
@@ -635,7 +707,7 @@ func (tList *DefaultTopicList) getList(page, orderby, topicCount int, argList []
 		// TODO: Embed Topic structs in TopicsRow to make it easier for us to reuse this work in the topic cache
 		t := TopicsRow{}
 		//var weekViews []uint8
-		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.CreatedBy, &t.IsClosed, &t.Sticky, &t.CreatedAt, &t.LastReplyAt, &t.LastReplyBy, &t.LastReplyID, &t.ParentID, &t.ViewCount, &t.PostCount, &t.LikeCount, &t.AttachCount, &t.Poll, &t.Data, &t.WeekViews)
+		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.CreatedBy, &t.IsClosed, &t.Sticky, &t.CreatedAt, &t.LastReplyAt, &t.LastReplyBy, &t.LastReplyID, &t.ParentID, &t.ViewCount, &t.PostCount, &t.LikeCount, &t.AttachCount, &t.Poll, &t.Data, &t.Prefix, &t.Answer, &t.WeekViews)
 		if err != nil {
 			return nil, Paginator{nil, 1, 1}, err
 		}
@@ -648,6 +720,7 @@ func (tList *DefaultTopicList) getList(page, orderby, topicCount int, argList []
 		forum := Forums.DirtyGet(t.ParentID)
 		t.ForumName = forum.Name
 		t.ForumLink = forum.Link
+		t.setPrefix()
 
 		// TODO: Create a specialised function with a bit less overhead for getting the last page for a post count
 		_, _, lastPage := PageOffset(t.PostCount, 1, Config.ItemsPerPage)
@@ -781,6 +854,20 @@ func ArgQToWeekViewTopicCount(argList []interface{}, qlist string) (topicCount i
 	return topicCount, err
 }
 
+func whereToTopicCount(argList []interface{}, where string) (topicCount int, err error) {
+	topicCountStmt, err := qgen.Builder.SimpleCount("topics", where, "")
+	if err != nil {
+		return 0, err
+	}
+	defer topicCountStmt.Close()
+
+	err = topicCountStmt.QueryRow(argList...).Scan(&topicCount)
+	if err != nil && err != ErrNoRows {
+		return 0, err
+	}
+	return topicCount, err
+}
+
 func TopicCountInForums(forums []Forum) (topicCount int, err error) {
 	for _, f := range forums {
 		topicCount += f.TopicCount
@@ -0,0 +1,436 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var ErrNoRepliesPicked = errors.New("You haven't picked any replies")
+var ErrReplyNotInTopic = errors.New("One of those replies isn't in this topic")
+var ErrMoveActionReply = errors.New("Action replies can't be moved to another topic")
+var ErrMoveDeletedReply = errors.New("Replies in the trash have to be restored before they can be moved to another topic")
+var ErrSameTopic = errors.New("You can't move posts into the topic they're already in")
+
+type TopicMergeStmts struct {
+	moveReply         *sql.Stmt
+	moveReplies       *sql.Stmt
+	moveReplyAttachs  *sql.Stmt
+	moveRepliesAttach *sql.Stmt
+	moveReplyVotes    *sql.Stmt
+	moveRepliesVotes  *sql.Stmt
+	moveReplyAlert    *sql.Stmt
+	moveRepliesAlerts *sql.Stmt
+
+	countReplies    *sql.Stmt
+	lastReply       *sql.Stmt
+	setReplyStats   *sql.Stmt
+	resetReplyStats *sql.Stmt
+
+	topicToReply        *sql.Stmt
+	topicLikesToReply   *sql.Stmt
+	topicAttachsToReply *sql.Stmt
+	replyPoll           *sql.Stmt
+	replyToTopic        *sql.Stmt
+	replyLikesToTopic   *sql.Stmt
+	replyAttachsToTopic *sql.Stmt
+	movePoll            *sql.Stmt
+
+	createTopic      *sql.Stmt
+	deleteReplyVotes *sql.Stmt
+	deleteReplyAlert *sql.Stmt
+	forumTopics      *sql.Stmt
+}
+
+var topicMergeStmts TopicMergeStmts
+
+func init() {
+	DbInits.Add(func(acc *qgen.Accumulator) error {
+		a, rv, as := "attachments", "reply_votes", "activity_stream"
+		topicMergeStmts = TopicMergeStmts{
+			moveReply:         acc.Update("replies").Set("tid=?").Where("rid=?").Prepare(),
+			moveReplies:       acc.Update("replies").Set("tid=?").Where("tid=?").Prepare(),
+			moveReplyAttachs:  acc.Update(a).Set("sectionID=?,extra=?").Where("originID=? AND originTable='replies'").Prepare(),
+			moveRepliesAttach: acc.Update(a).Set("sectionID=?,extra=?").Where("extra=? AND originTable='replies'").Prepare(),
+			moveReplyVotes:    acc.Update(rv).Set("tid=?").Where("rid=?").Prepare(),
+			moveRepliesVotes:  acc.Update(rv).Set("tid=?").Where("tid=?").Prepare(),
+			moveReplyAlert:    acc.Update(as).Set("elementID=?").Where("event='reply' AND elementType='topic' AND elementID=? AND extra=?").Prepare(),
+			moveRepliesAlerts: acc.Update(as).Set("elementID=?").Where("event='reply' AND elementType='topic' AND elementID=?").Prepare(),
+
+			countReplies:    acc.Count("replies").Where("tid=? AND deleted=0").Prepare(),
+			lastReply:       acc.Select("replies").Columns("rid,createdBy,createdAt").Where("tid=? AND deleted=0").Orderby("createdAt DESC,rid DESC").Limit("1").Prepare(),
+			setReplyStats:   acc.Update("topics").Set("postCount=?,lastReplyID=?,lastReplyBy=?,lastReplyAt=?").Where("tid=?").Prepare(),
+			resetReplyStats: acc.Update("topics").Set("postCount=1,lastReplyID=0,lastReplyBy=createdBy,lastReplyAt=createdAt").Where("tid=?").Prepare(),
+
+			topicToReply:        acc.Insert("replies").Columns("tid,content,parsed_content,createdAt,createdBy,lastUpdated,ip,words,likeCount,attachCount,poll").Fields("?,?,?,?,?,UTC_TIMESTAMP(),?,?,?,?,?").Prepare(),
+			topicLikesToReply:   acc.Update("likes").Set("targetItem=?,targetType='replies'").Where("targetItem=? AND targetType='topics'").Prepare(),
+			topicAttachsToReply: acc.Update(a).Set("sectionID=?,originID=?,originTable='replies',extra=?").Where("originID=? AND originTable='topics'").Prepare(),
+			replyPoll:           acc.Select("replies").Columns("poll").Where("rid=?").Prepare(),
+			replyToTopic:        acc.Update("topics").Set("createdAt=?,likeCount=?,attachCount=?,poll=?").Where("tid=?").Prepare(),
+			replyLikesToTopic:   acc.Update("likes").Set("targetItem=?,targetType='topics'").Where("targetItem=? AND targetType='replies'").Prepare(),
+			replyAttachsToTopic: acc.Update(a).Set("sectionID=?,originID=?,originTable='topics',extra=''").Where("originID=? AND originTable='replies'").Prepare(),
+			movePoll:            acc.Update("polls").Set("parentID=?,parentTable=?").Where("pollID=?").Prepare(),
+
+			createTopic:      acc.Insert("topics").Columns("parentID, title, content, parsed_content, createdAt, lastReplyAt, lastReplyBy, ip, words, createdBy").Fields("?,?,?,?,UTC_TIMESTAMP(),UTC_TIMESTAMP(),?,?,?,?").Prepare(),
+			deleteReplyVotes: acc.Delete(rv).Where("rid=?").Prepare(),
+			deleteReplyAlert: acc.Delete(as).Where("event='reply' AND elementType='topic' AND elementID=? AND extra=?").Prepare(),
+			forumTopics:      acc.Update("forums").Set("topicCount=topicCount+?").Where("fid=?").Prepare(),
+		}
+		return acc.FirstError()
+	})
+}
+
+// RecalcReplies brings the post count and the last reply details of this topic back in line with the replies which are actually in it
+func (t *Topic) RecalcReplies() error {
+	tx, err := qgen.Builder.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = t.recalcRepliesTx(tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	t.cacheRemove()
+	return err
+}
+
+func (t *Topic) recalcRepliesTx(tx *sql.Tx) error {
+	count := Countf(tx.Stmt(topicMergeStmts.countReplies), t.ID)
+	var rid, uid int
+	var createdAt time.Time
+	err := tx.Stmt(topicMergeStmts.lastReply).QueryRow(t.ID).Scan(&rid, &uid, &createdAt)
+	if err == ErrNoRows {
+		_, err = tx.Stmt(topicMergeStmts.resetReplyStats).Exec(t.ID)
+		return err
+	} else if err != nil {
+		return err
+	}
+	_, err = tx.Stmt(topicMergeStmts.setReplyStats).Exec(count+1, rid, uid, createdAt, t.ID)
+	return err
+}
+
+// pickReplies loads the replies rids and makes sure that they're all normal replies in this topic which aren't in the trash. They're handed back in the order they were posted.
+func (t *Topic) pickReplies(rids []int) (replies []*Reply, err error) {
+	if len(rids) == 0 {
+		return nil, ErrNoRepliesPicked
+	}
+	sort.Ints(rids)
+	for i, rid := range rids {
+		if i > 0 && rids[i-1] == rid {
+			continue
+		}
+		r, err := Rstore.Get(rid)
+		if err == ErrNoRows || (err == nil && r.ParentID != t.ID) {
+			return nil, ErrReplyNotInTopic
+		} else if err != nil {
+			return nil, err
+		}
+		if r.ActionType != "" {
+			return nil, ErrMoveActionReply
+		}
+		if r.Deleted {
+			return nil, ErrMoveDeletedReply
+		}
+		replies = append(replies, r)
+	}
+	return replies, nil
+}
+
+func (t *Topic) moveRepliesTx(tx *sql.Tx, replies []*Reply, dest *Topic) error {
+	stid := strconv.Itoa(dest.ID)
+	for _, r := range replies {
+		_, err := tx.Stmt(topicMergeStmts.moveReply).Exec(dest.ID, r.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Stmt(topicMergeStmts.moveReplyAttachs).Exec(dest.ParentID, stid, r.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Stmt(topicMergeStmts.moveReplyVotes).Exec(dest.ID, r.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Stmt(topicMergeStmts.moveReplyAlert).Exec(dest.ID, t.ID, strconv.Itoa(r.ID))
+		if err != nil {
+			return err
+		}
+		// The answer to one question isn't going to be the answer to another
+		_, err = tx.Stmt(replyStmts.clearAnswer).Exec(t.ID, r.ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshForums fixes up the last topic on the forums these topics are in, after their last replies have shifted around
+func refreshForums(topics ...*Topic) error {
+	seen := make(map[int]bool)
+	for _, t := range topics {
+		if seen[t.ParentID] {
+			continue
+		}
+		seen[t.ParentID] = true
+		err := Forums.RefreshTopic(t.ParentID)
+		if err != nil && err != ErrNoRows {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveReplies moves the replies rids out of this topic and into dest. Their likes, votes and attachments go along with them.
+func (t *Topic) MoveReplies(rids []int, dest *Topic) error {
+	if t.ID == dest.ID {
+		return ErrSameTopic
+	}
+	replies, err := t.pickReplies(rids)
+	if err != nil {
+		return err
+	}
+
+	tx, err := qgen.Builder.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	err = t.moveRepliesTx(tx, replies, dest)
+	if err != nil {
+		return err
+	}
+	err = t.recalcRepliesTx(tx)
+	if err != nil {
+		return err
+	}
+	err = dest.recalcRepliesTx(tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	for _, r := range replies {
+		_ = Rstore.GetCache().Remove(r.ID)
+	}
+	t.cacheRemove()
+	dest.cacheRemove()
+	return refreshForums(t, dest)
+}
+
+// Split moves the replies rids out of this topic and into a brand new topic called title in the forum fid. The earliest of those replies becomes the opening post of the new topic.
+func (t *Topic) Split(rids []int, title string, fid int) (tid int, err error) {
+	replies, err := t.pickReplies(rids)
+	if err != nil {
+		return 0, err
+	}
+	first := replies[0]
+	parsed, err := prepTopic(fid, title, first.Content)
+	if err != nil {
+		return 0, err
+	}
+	ip := first.IP
+	if Config.DisablePostIP {
+		ip = ""
+	}
+
+	tx, err := qgen.Builder.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Topics.Create would go around the transaction, so the topic is put together here
+	res, err := tx.Stmt(topicMergeStmts.createTopic).Exec(fid, title, first.Content, parsed, first.CreatedBy, ip, WordCount(first.Content), first.CreatedBy)
+	if err != nil {
+		return 0, err
+	}
+	lid, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	tid = int(lid)
+	_, err = tx.Stmt(topicMergeStmts.forumTopics).Exec(1, fid)
+	if err != nil {
+		return 0, err
+	}
+	var poll int
+	err = tx.Stmt(topicMergeStmts.replyPoll).QueryRow(first.ID).Scan(&poll)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Stmt(topicMergeStmts.replyToTopic).Exec(first.CreatedAt, first.LikeCount, first.AttachCount, poll, tid)
+	if err != nil {
+		return 0, err
+	}
+	if poll != 0 {
+		_, err = tx.Stmt(topicMergeStmts.movePoll).Exec(tid, "topics", poll)
+		if err != nil {
+			return 0, err
+		}
+	}
+	_, err = tx.Stmt(topicMergeStmts.replyLikesToTopic).Exec(tid, first.ID)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Stmt(topicMergeStmts.replyAttachsToTopic).Exec(fid, tid, first.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	// The first reply lives on as the opening post, so we can drop the original without touching anything which now belongs to the topic
+	_, err = tx.Stmt(replyStmts.delete).Exec(first.ID)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Stmt(replyStmts.clearAnswer).Exec(t.ID, first.ID)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Stmt(topicMergeStmts.deleteReplyVotes).Exec(first.ID)
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Stmt(topicMergeStmts.deleteReplyAlert).Exec(t.ID, strconv.Itoa(first.ID))
+	if err != nil {
+		return 0, err
+	}
+
+	dest := &Topic{ID: tid, ParentID: fid}
+	err = t.moveRepliesTx(tx, replies[1:], dest)
+	if err != nil {
+		return 0, err
+	}
+	err = t.recalcRepliesTx(tx)
+	if err != nil {
+		return 0, err
+	}
+	err = dest.recalcRepliesTx(tx)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, r := range replies {
+		_ = Rstore.GetCache().Remove(r.ID)
+	}
+	t.cacheRemove()
+	err = refreshForums(t, dest)
+	if err != nil {
+		return tid, err
+	}
+	// The author of the first reply is now the author of a topic rather than a reply
+	err = (&User{ID: first.CreatedBy}).RecalcPostStats()
+	if err != nil {
+		return tid, err
+	}
+	return tid, Subscriptions.Add(first.CreatedBy, tid, "topic")
+}
+
+// MergeInto turns the opening post of this topic into a reply on dest, moves all of it's replies over there and then gets rid of this topic.
+func (t *Topic) MergeInto(dest *Topic) error {
+	if t.ID == dest.ID {
+		return ErrSameTopic
+	}
+	stid := strconv.Itoa(dest.ID)
+	parsed := ParseMessage(t.Content, dest.ParentID, "forums", nil, nil)
+
+	tx, err := qgen.Builder.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Bring the replies over first, so the opening post doesn't get swept up twice
+	_, err = tx.Stmt(topicMergeStmts.moveReplies).Exec(dest.ID, t.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Stmt(topicMergeStmts.moveRepliesAttach).Exec(dest.ParentID, stid, strconv.Itoa(t.ID))
+	if err != nil {
+		return err
+	}
+	_, err = tx.Stmt(topicMergeStmts.moveRepliesVotes).Exec(dest.ID, t.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Stmt(topicMergeStmts.moveRepliesAlerts).Exec(dest.ID, t.ID)
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Stmt(topicMergeStmts.topicToReply).Exec(dest.ID, t.Content, parsed, t.CreatedAt, t.CreatedBy, t.IP, WordCount(t.Content), t.LikeCount, t.AttachCount, t.Poll)
+	if err != nil {
+		return err
+	}
+	lid, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	rid := int(lid)
+	_, err = tx.Stmt(topicMergeStmts.topicLikesToReply).Exec(rid, t.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Stmt(topicMergeStmts.topicAttachsToReply).Exec(dest.ParentID, rid, stid, t.ID)
+	if err != nil {
+		return err
+	}
+	if t.Poll != 0 {
+		_, err = tx.Stmt(topicMergeStmts.movePoll).Exec(rid, "replies", t.Poll)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Stmt(topicStmts.delete).Exec(t.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Stmt(topicMergeStmts.forumTopics).Exec(-1, t.ParentID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Stmt(topicStmts.deleteActivity).Exec(t.ID)
+	if err != nil {
+		return err
+	}
+	err = dest.recalcRepliesTx(tx)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	// The rest are on the edges of the topic rather than in it, they're tidied up once the merge itself has gone through
+	Rstore.GetCache().Flush()
+	t.cacheRemove()
+	dest.cacheRemove()
+	err = TopicTags.DeleteAll(t.ID)
+	if err != nil {
+		return err
+	}
+	err = Subscriptions.MoveResource(t.ID, dest.ID, "topic")
+	if err != nil {
+		return err
+	}
+	err = refreshForums(t, dest)
+	if err != nil {
+		return err
+	}
+	// The author of the old topic now has a reply in it's place
+	return (&User{ID: t.CreatedBy}).RecalcPostStats()
+}
@@ -0,0 +1,228 @@
+package common
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// TopicPrefix is an admin-defined label such as [Solved] or [Bug] which can be attached to a topic
+type TopicPrefix struct {
+	ID       int
+	Name     string
+	CSSClass string
+	Forums   []int // The forums this prefix can be used in, an empty list means all of them
+	Groups   []int // The groups which are allowed to use this prefix, an empty list means everyone who can create topics
+	Order    int
+}
+
+// AppliesTo tells you whether this prefix can be used on topics in the forum fid
+func (p *TopicPrefix) AppliesTo(fid int) bool {
+	return len(p.Forums) == 0 || inIntList(p.Forums, fid)
+}
+
+// CanUse tells you whether u is allowed to put this prefix on a topic. Super mods can use any prefix.
+func (p *TopicPrefix) CanUse(u *User) bool {
+	return u.IsSuperMod || len(p.Groups) == 0 || inIntList(p.Groups, u.Group)
+}
+
+// HasForum and HasGroup are here for the benefit of the templates
+func (p *TopicPrefix) HasForum(fid int) bool {
+	return inIntList(p.Forums, fid)
+}
+
+func (p *TopicPrefix) HasGroup(gid int) bool {
+	return inIntList(p.Groups, gid)
+}
+
+func inIntList(list []int, needle int) bool {
+	for _, item := range list {
+		if item == needle {
+			return true
+		}
+	}
+	return false
+}
+
+func joinIntList(list []int) (raw string) {
+	for _, item := range list {
+		raw += strconv.Itoa(item) + ","
+	}
+	if len(raw) > 0 {
+		raw = raw[:len(raw)-1]
+	}
+	return raw
+}
+
+func splitIntList(raw string) (list []int, err error) {
+	if raw == "" {
+		return nil, nil
+	}
+	for _, sitem := range strings.Split(raw, ",") {
+		item, err := strconv.Atoi(sitem)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+var TopicPrefixes TopicPrefixStore
+
+type TopicPrefixStore interface {
+	ReloadAll() error
+	GetAll() []*TopicPrefix
+	Get(id int) (*TopicPrefix, error)
+	GetUsable(fid int, u *User) []*TopicPrefix
+	Create(p *TopicPrefix) (int, error)
+	Update(p *TopicPrefix) error
+	Delete(id int) error
+	Length() int
+	Count() (count int)
+}
+
+type topicPrefixSet struct {
+	list []*TopicPrefix
+	m    map[int]*TopicPrefix
+}
+
+type DefaultTopicPrefixStore struct {
+	box atomic.Value // An atomic value holding a *topicPrefixSet
+
+	getAll      *sql.Stmt
+	create      *sql.Stmt
+	update      *sql.Stmt
+	delete      *sql.Stmt
+	count       *sql.Stmt
+	clearTopics *sql.Stmt
+}
+
+func NewDefaultTopicPrefixStore(acc *qgen.Accumulator) (*DefaultTopicPrefixStore, error) {
+	tp := "topic_prefixes"
+	s := &DefaultTopicPrefixStore{
+		getAll:      acc.Select(tp).Columns("prid,name,cssClass,forums,allowedGroups,order").Orderby("order ASC,prid ASC").Prepare(),
+		create:      acc.Insert(tp).Columns("name,cssClass,forums,allowedGroups,order").Fields("?,?,?,?,?").Prepare(),
+		update:      acc.Update(tp).Set("name=?,cssClass=?,forums=?,allowedGroups=?,order=?").Where("prid=?").Prepare(),
+		delete:      acc.Delete(tp).Where("prid=?").Prepare(),
+		count:       acc.Count(tp).Prepare(),
+		clearTopics: acc.Update("topics").Set("prefix=0").Where("prefix=?").Prepare(),
+	}
+	if acc.FirstError() == nil {
+		acc.RecordError(s.ReloadAll())
+	}
+	return s, acc.FirstError()
+}
+
+// ReloadAll drops all the prefixes in the memory cache and replaces them with fresh copies from the database
+func (s *DefaultTopicPrefixStore) ReloadAll() error {
+	set := &topicPrefixSet{m: make(map[int]*TopicPrefix)}
+	rows, err := s.getAll.Query()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &TopicPrefix{}
+		var rawForums, rawGroups string
+		err := rows.Scan(&p.ID, &p.Name, &p.CSSClass, &rawForums, &rawGroups, &p.Order)
+		if err != nil {
+			return err
+		}
+		p.Forums, err = splitIntList(rawForums)
+		if err != nil {
+			return err
+		}
+		p.Groups, err = splitIntList(rawGroups)
+		if err != nil {
+			return err
+		}
+		set.list = append(set.list, p)
+		set.m[p.ID] = p
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	s.box.Store(set)
+	return nil
+}
+
+// GetAll returns every prefix ordered by position. Do not mutate the returned slice or the prefixes within it.
+func (s *DefaultTopicPrefixStore) GetAll() []*TopicPrefix {
+	return s.box.Load().(*topicPrefixSet).list
+}
+
+func (s *DefaultTopicPrefixStore) Get(id int) (*TopicPrefix, error) {
+	p, ok := s.box.Load().(*topicPrefixSet).m[id]
+	if !ok {
+		return p, ErrNoRows
+	}
+	return p, nil
+}
+
+// GetUsable returns the prefixes u is allowed to put on topics in the forum fid
+func (s *DefaultTopicPrefixStore) GetUsable(fid int, u *User) (out []*TopicPrefix) {
+	for _, p := range s.GetAll() {
+		if p.AppliesTo(fid) && p.CanUse(u) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Create adds a new prefix to the database and refreshes the memory cache
+func (s *DefaultTopicPrefixStore) Create(p *TopicPrefix) (int, error) {
+	res, err := s.create.Exec(p.Name, p.CSSClass, joinIntList(p.Forums), joinIntList(p.Groups), p.Order)
+	if err != nil {
+		return 0, err
+	}
+	id64, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	return int(id64), s.ReloadAll()
+}
+
+func (s *DefaultTopicPrefixStore) Update(p *TopicPrefix) error {
+	_, err := s.update.Exec(p.Name, p.CSSClass, joinIntList(p.Forums), joinIntList(p.Groups), p.Order, p.ID)
+	if err != nil {
+		return err
+	}
+	TopicListThaw.Thaw()
+	return s.ReloadAll()
+}
+
+// Delete removes a prefix and takes it off any topics which were using it
+func (s *DefaultTopicPrefixStore) Delete(id int) error {
+	_, err := s.delete.Exec(id)
+	if err != nil {
+		return err
+	}
+	_, err = s.clearTopics.Exec(id)
+	if err != nil {
+		return err
+	}
+	if tc := Topics.GetCache(); tc != nil {
+		tc.Flush()
+	}
+	TopicListThaw.Thaw()
+	return s.ReloadAll()
+}
+
+// Length gets the number of prefixes currently in memory, for the DefaultTopicPrefixStore, this should be all of them
+func (s *DefaultTopicPrefixStore) Length() int {
+	return len(s.box.Load().(*topicPrefixSet).list)
+}
+
+// Count gets the total number of prefixes directly from the database
+func (s *DefaultTopicPrefixStore) Count() (count int) {
+	err := s.count.QueryRow().Scan(&count)
+	if err != nil {
+		LogError(err)
+	}
+	return count
+}
@@ -64,11 +64,11 @@ func NewDefaultTopicStore(cache TopicCache) (*DefaultTopicStore, error) {
 	t := "topics"
 	return &DefaultTopicStore{
 		cache:         cache,
-		get:           acc.Select(t).Columns("title,content,createdBy,createdAt,lastReplyBy,lastReplyAt,lastReplyID,is_closed,sticky,parentID,ip,views,postCount,likeCount,attachCount,poll,data").Where("tid=?").Prepare(),
+		get:           acc.Select(t).Columns("title,content,createdBy,createdAt,lastReplyBy,lastReplyAt,lastReplyID,is_closed,sticky,parentID,ip,views,postCount,likeCount,attachCount,poll,data,prefix,answer,deleted,lastEdit,lastEditBy").Where("tid=?").Prepare(),
 		exists:        acc.Exists(t, "tid").Prepare(),
 		count:         acc.Count(t).Prepare(),
-		countUser:     acc.Count(t).Where("createdBy=?").Prepare(),
-		countWordUser: acc.Count(t).Where("createdBy=? AND words>=?").Prepare(),
+		countUser:     acc.Count(t).Where("createdBy=? AND deleted=0").Prepare(),
+		countWordUser: acc.Count(t).Where("createdBy=? AND words>=? AND deleted=0").Prepare(),
 		create:        acc.Insert(t).Columns("parentID, title, content, parsed_content, createdAt, lastReplyAt, lastReplyBy, ip, words, createdBy").Fields("?,?,?,?,UTC_TIMESTAMP(),UTC_TIMESTAMP(),?,?,?,?").Prepare(),
 	}, acc.FirstError()
 }
@@ -102,7 +102,7 @@ func (s *DefaultTopicStore) Get(id int) (t *Topic, err error) {
 // BypassGet will always bypass the cache and pull the topic directly from the database
 func (s *DefaultTopicStore) BypassGet(id int) (*Topic, error) {
 	t := &Topic{ID: id}
-	err := s.get.QueryRow(id).Scan(&t.Title, &t.Content, &t.CreatedBy, &t.CreatedAt, &t.LastReplyBy, &t.LastReplyAt, &t.LastReplyID, &t.IsClosed, &t.Sticky, &t.ParentID, &t.IP, &t.ViewCount, &t.PostCount, &t.LikeCount, &t.AttachCount, &t.Poll, &t.Data)
+	err := s.get.QueryRow(id).Scan(&t.Title, &t.Content, &t.CreatedBy, &t.CreatedAt, &t.LastReplyBy, &t.LastReplyAt, &t.LastReplyID, &t.IsClosed, &t.Sticky, &t.ParentID, &t.IP, &t.ViewCount, &t.PostCount, &t.LikeCount, &t.AttachCount, &t.Poll, &t.Data, &t.Prefix, &t.Answer, &t.Deleted, &t.LastEdit, &t.LastEditBy)
 	if err == nil {
 		t.Link = BuildTopicURL(NameToSlug(t.Title), id)
 	}
@@ -152,7 +152,7 @@ func (s *DefaultTopicStore) BulkGetMap(ids []int) (list map[int]*Topic, err erro
 	}
 
 	idList, q := inqbuild(ids)
-	rows, err := qgen.NewAcc().Select("topics").Columns("tid,title,content,createdBy,createdAt,lastReplyBy,lastReplyAt,lastReplyID,is_closed,sticky,parentID,ip,views,postCount,likeCount,attachCount,poll,data").Where("tid IN(" + q + ")").Query(idList...)
+	rows, err := qgen.NewAcc().Select("topics").Columns("tid,title,content,createdBy,createdAt,lastReplyBy,lastReplyAt,lastReplyID,is_closed,sticky,parentID,ip,views,postCount,likeCount,attachCount,poll,data,prefix,answer,deleted,lastEdit,lastEditBy").Where("tid IN(" + q + ")").Query(idList...)
 	if err != nil {
 		return list, err
 	}
@@ -160,7 +160,7 @@ func (s *DefaultTopicStore) BulkGetMap(ids []int) (list map[int]*Topic, err erro
 
 	for rows.Next() {
 		t := &Topic{}
-		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.CreatedBy, &t.CreatedAt, &t.LastReplyBy, &t.LastReplyAt, &t.LastReplyID, &t.IsClosed, &t.Sticky, &t.ParentID, &t.IP, &t.ViewCount, &t.PostCount, &t.LikeCount, &t.AttachCount, &t.Poll, &t.Data)
+		err := rows.Scan(&t.ID, &t.Title, &t.Content, &t.CreatedBy, &t.CreatedAt, &t.LastReplyBy, &t.LastReplyAt, &t.LastReplyID, &t.IsClosed, &t.Sticky, &t.ParentID, &t.IP, &t.ViewCount, &t.PostCount, &t.LikeCount, &t.AttachCount, &t.Poll, &t.Data, &t.Prefix, &t.Answer, &t.Deleted, &t.LastEdit, &t.LastEditBy)
 		if err != nil {
 			return list, err
 		}
@@ -198,6 +198,7 @@ func (s *DefaultTopicStore) Reload(id int) error {
 		_ = s.cache.Remove(id)
 	}
 	TopicListThaw.Thaw()
+	ClusterPublish(ClusterTopic, id)
 	return err
 }
 
@@ -206,17 +207,9 @@ func (s *DefaultTopicStore) Exists(id int) bool {
 }
 
 func (s *DefaultTopicStore) Create(fid int, name, content string, uid int, ip string) (tid int, err error) {
-	if name == "" {
-		return 0, ErrNoTitle
-	}
-	// ? This number might be a little screwy with Unicode, but it's the only consistent thing we have, as Unicode characters can be any number of bytes in theory?
-	if len(name) > Config.MaxTopicTitleLength {
-		return 0, ErrLongTitle
-	}
-
-	parsedContent := strings.TrimSpace(ParseMessage(content, fid, "forums", nil, nil))
-	if parsedContent == "" {
-		return 0, ErrNoBody
+	parsedContent, err := prepTopic(fid, name, content)
+	if err != nil {
+		return 0, err
 	}
 
 	// TODO: Move this statement into the topic store
@@ -237,6 +230,22 @@ func (s *DefaultTopicStore) Create(fid int, name, content string, uid int, ip st
 	return tid, Forums.AddTopic(tid, uid, fid)
 }
 
+// prepTopic checks the title and the body of a new topic, the body is handed back parsed
+func prepTopic(fid int, name, content string) (parsed string, err error) {
+	if name == "" {
+		return "", ErrNoTitle
+	}
+	// ? This number might be a little screwy with Unicode, but it's the only consistent thing we have, as Unicode characters can be any number of bytes in theory?
+	if len(name) > Config.MaxTopicTitleLength {
+		return "", ErrLongTitle
+	}
+	parsed = strings.TrimSpace(ParseMessage(content, fid, "forums", nil, nil))
+	if parsed == "" {
+		return "", ErrNoBody
+	}
+	return parsed, nil
+}
+
 // ? - What is this? Do we need it? Should it be in the main store interface?
 func (s *DefaultTopicStore) AddLastTopic(t *Topic, fid int) error {
 	// Coming Soon...
@@ -0,0 +1,268 @@
+package common
+
+import (
+	"database/sql"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// TopicTagMaxLength is the maximum number of characters in a tag, anything past this is cut off
+const TopicTagMaxLength = 30
+
+// TopicTagMaxCount is the maximum number of tags which can be attached to a single topic
+const TopicTagMaxCount = 10
+
+// NormaliseTag lower-cases tag, replaces runs of whitespace with dashes and strips anything which isn't a letter, a digit, a dash or an underscore
+func NormaliseTag(tag string) string {
+	var sb strings.Builder
+	var dash bool
+	var n int
+	for _, ch := range strings.ToLower(strings.TrimSpace(tag)) {
+		if n >= TopicTagMaxLength {
+			break
+		}
+		switch {
+		case unicode.IsSpace(ch) || ch == '-':
+			dash = true
+			continue
+		case unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_':
+		default:
+			continue
+		}
+		if dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			n++
+		}
+		dash = false
+		sb.WriteRune(ch)
+		n++
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
+// ParseTags turns a comma separated list of tags submitted by a user into a de-duplicated list of normalised tags
+func ParseTags(raw string) (tags []string) {
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(raw, ",") {
+		tag = NormaliseTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) >= TopicTagMaxCount {
+			break
+		}
+	}
+	return tags
+}
+
+func BuildTagURL(tag string) string {
+	return "/tags/" + url.PathEscape(tag)
+}
+
+// TopicTag is a tag with a link to it's tag page for the templates
+type TopicTag struct {
+	Name string
+	Link string
+}
+
+func TagsToTopicTags(tags []string) []TopicTag {
+	out := make([]TopicTag, len(tags))
+	for i, tag := range tags {
+		out[i] = TopicTag{tag, BuildTagURL(tag)}
+	}
+	return out
+}
+
+var TopicTags TopicTagStore
+
+type TopicTagStore interface {
+	Get(tid int) ([]string, error)
+	BulkGetMap(tids []int) (map[int][]string, error)
+	Set(tid int, tags []string) error
+	DeleteAll(tid int) error
+	Tids(tag string) ([]int, error)
+	Suggest(prefix string, limit int) ([]string, error)
+	InForums(fids []int, limit int) ([]string, error)
+	Exists(tag string) bool
+}
+
+type DefaultTopicTagStore struct {
+	get       *sql.Stmt
+	insert    *sql.Stmt
+	deleteAll *sql.Stmt
+	tids      *sql.Stmt
+	suggest   *sql.Stmt
+	exists    *sql.Stmt
+}
+
+func NewDefaultTopicTagStore(acc *qgen.Accumulator) (*DefaultTopicTagStore, error) {
+	tt := "topic_tags"
+	return &DefaultTopicTagStore{
+		get:       acc.Select(tt).Columns("tag").Where("tid=?").Orderby("tag ASC").Prepare(),
+		insert:    acc.Insert(tt).Columns("tid,tag").Fields("?,?").Prepare(),
+		deleteAll: acc.Delete(tt).Where("tid=?").Prepare(),
+		tids:      acc.Select(tt).Columns("tid").Where("tag=?").Prepare(),
+		suggest:   acc.RawPrepare("SELECT tag FROM topic_tags WHERE tag LIKE ? ESCAPE '!' GROUP BY tag ORDER BY COUNT(*) DESC, tag ASC LIMIT ?"),
+		exists:    acc.Select(tt).Columns("tid").Where("tag=?").Limit("1").Prepare(),
+	}, acc.FirstError()
+}
+
+// Get returns the tags on the topic tid in alphabetical order
+func (s *DefaultTopicTagStore) Get(tid int) (tags []string, err error) {
+	rows, err := s.get.Query(tid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// BulkGetMap returns the tags for each of the topics in tids, topics without any tags are left out of the map
+func (s *DefaultTopicTagStore) BulkGetMap(tids []int) (map[int][]string, error) {
+	list := make(map[int][]string)
+	if len(tids) == 0 {
+		return list, nil
+	}
+	err := qgen.NewAcc().Select("topic_tags").Columns("tid,tag").In("tid", tids).Orderby("tag ASC").Each(func(rows *sql.Rows) error {
+		var tid int
+		var tag string
+		if err := rows.Scan(&tid, &tag); err != nil {
+			return err
+		}
+		list[tid] = append(list[tid], tag)
+		return nil
+	})
+	return list, err
+}
+
+// Set replaces the tags on the topic tid. The tags are expected to have already gone through NormaliseTag.
+func (s *DefaultTopicTagStore) Set(tid int, tags []string) error {
+	_, err := s.deleteAll.Exec(tid)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		_, err = s.insert.Exec(tid, tag)
+		if err != nil {
+			return err
+		}
+	}
+	TopicListThaw.Thaw()
+	return nil
+}
+
+// DeleteAll removes every tag from the topic tid, e.g. when it is deleted
+func (s *DefaultTopicTagStore) DeleteAll(tid int) error {
+	_, err := s.deleteAll.Exec(tid)
+	return err
+}
+
+// Tids returns the IDs of every topic with the given tag
+func (s *DefaultTopicTagStore) Tids(tag string) (tids []int, err error) {
+	rows, err := s.tids.Query(tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tid int
+		if err := rows.Scan(&tid); err != nil {
+			return nil, err
+		}
+		tids = append(tids, tid)
+	}
+	return tids, rows.Err()
+}
+
+// Suggest returns up to limit of the most popular tags starting with prefix, for autocompletion
+func (s *DefaultTopicTagStore) Suggest(prefix string, limit int) (tags []string, err error) {
+	prefix = NormaliseTag(prefix)
+	if prefix == "" {
+		return nil, nil
+	}
+	// Escape the wildcards, so that underscores in tags don't match everything. Not every database treats backslashes as the escape character, so we use our own
+	prefix = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(prefix)
+	rows, err := s.suggest.Query(prefix+"%", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// InForums returns up to limit of the distinct tags used on topics in the forums fids, e.g. for building the sitemap
+func (s *DefaultTopicTagStore) InForums(fids []int, limit int) (tags []string, err error) {
+	if len(fids) == 0 {
+		return nil, nil
+	}
+	idList, q := inqbuild(fids)
+	stmt, err := qgen.Builder.SimpleInnerJoin("topic_tags", "topics", "topic_tags.tag", "topic_tags.tid=topics.tid", "topics.parentID IN("+q+")", "topic_tags.tag ASC", "")
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	rows, err := stmt.Query(idList...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The join gives us a row for every topic, so we have to de-duplicate them here
+	var last string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		if tag == last {
+			continue
+		}
+		last = tag
+		tags = append(tags, tag)
+		if limit > 0 && len(tags) >= limit {
+			break
+		}
+	}
+	return tags, rows.Err()
+}
+
+func (s *DefaultTopicTagStore) Exists(tag string) bool {
+	var tid int
+	return s.exists.QueryRow(tag).Scan(&tid) == nil
+}
+
+// tagFilterQ turns the topics with tag into a where clause fragment which can be tacked onto a topic list query
+func tagFilterQ(tag string) (argList []interface{}, q string, err error) {
+	tids, err := TopicTags.Tids(tag)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(tids) == 0 {
+		return nil, "", nil
+	}
+	for _, tid := range tids {
+		argList = append(argList, strconv.Itoa(tid))
+		q += "?,"
+	}
+	return argList, "tid IN(" + q[:len(q)-1] + ")", nil
+}
@@ -0,0 +1,204 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var TopicTimedActions TopicTimedActionStore
+
+var ErrBadTimedAction = errors.New("That isn't an action which can be done to a topic later")
+
+// TopicTimedAction is something a moderator has asked to be done to a topic later on, e.g. unpinning an announcement after a week
+type TopicTimedAction struct {
+	ID        int
+	TopicID   int
+	Action    string // unpin or lock
+	Days      int
+	Inactive  bool // Counts the days from the last post in the topic rather than from when the action was set
+	CreatedBy int
+	CreatedAt time.Time
+}
+
+// DueAt is when the action should be done, t is the topic it's for
+func (a *TopicTimedAction) DueAt(t *Topic) time.Time {
+	from := a.CreatedAt
+	if a.Inactive && t.LastReplyAt.After(from) {
+		from = t.LastReplyAt
+	}
+	return from.AddDate(0, 0, a.Days)
+}
+
+// ValidTimedAction tells you whether action is one of the actions which can be done to a topic later
+func ValidTimedAction(action string) bool {
+	return action == "unpin" || action == "lock"
+}
+
+// TopicTimedActionStore holds the actions waiting to be done to topics, there can be one of each action for a topic, setting it again replaces it
+type TopicTimedActionStore interface {
+	Get(id int) (*TopicTimedAction, error)
+	GetByTopic(tid int) ([]*TopicTimedAction, error)
+	Set(tid int, action string, days int, inactive bool, uid int) error
+	Delete(id int) error
+	DeleteByTopic(tid int) error
+	Run(a *TopicTimedAction) error
+	RunDue() error
+}
+
+type DefaultTopicTimedActionStore struct {
+	get           *sql.Stmt
+	getAll        *sql.Stmt
+	getByTopic    *sql.Stmt
+	create        *sql.Stmt
+	deleteByKey   *sql.Stmt
+	delete        *sql.Stmt
+	deleteByTopic *sql.Stmt
+}
+
+func NewDefaultTopicTimedActionStore(acc *qgen.Accumulator) (*DefaultTopicTimedActionStore, error) {
+	ta := "topics_timed_actions"
+	cols := "taid,tid,action,days,inactive,createdBy,createdAt"
+	s := &DefaultTopicTimedActionStore{
+		get:           acc.Select(ta).Columns(cols).Where("taid=?").Prepare(),
+		getAll:        acc.Select(ta).Columns(cols).Prepare(),
+		getByTopic:    acc.Select(ta).Columns(cols).Where("tid=?").Orderby("taid ASC").Prepare(),
+		create:        acc.Insert(ta).Columns("tid,action,days,inactive,createdBy,createdAt").Fields("?,?,?,?,?,UTC_TIMESTAMP()").Prepare(),
+		deleteByKey:   acc.Delete(ta).Where("tid=? AND action=?").Prepare(),
+		delete:        acc.Delete(ta).Where("taid=?").Prepare(),
+		deleteByTopic: acc.Delete(ta).Where("tid=?").Prepare(),
+	}
+	if err := acc.FirstError(); err != nil {
+		return nil, err
+	}
+	AddScheduledFifteenMinuteTask(s.RunDue)
+	return s, nil
+}
+
+func (s *DefaultTopicTimedActionStore) scan(row interface{ Scan(...interface{}) error }) (*TopicTimedAction, error) {
+	a := &TopicTimedAction{}
+	err := row.Scan(&a.ID, &a.TopicID, &a.Action, &a.Days, &a.Inactive, &a.CreatedBy, &a.CreatedAt)
+	return a, err
+}
+
+func (s *DefaultTopicTimedActionStore) list(stmt *sql.Stmt, args ...interface{}) (list []*TopicTimedAction, err error) {
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a, err := s.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+func (s *DefaultTopicTimedActionStore) Get(id int) (*TopicTimedAction, error) {
+	return s.scan(s.get.QueryRow(id))
+}
+
+func (s *DefaultTopicTimedActionStore) GetByTopic(tid int) ([]*TopicTimedAction, error) {
+	return s.list(s.getByTopic, tid)
+}
+
+// Set replaces any action of the same kind already set for tid
+func (s *DefaultTopicTimedActionStore) Set(tid int, action string, days int, inactive bool, uid int) error {
+	if !ValidTimedAction(action) || days < 1 {
+		return ErrBadTimedAction
+	}
+	_, err := s.deleteByKey.Exec(tid, action)
+	if err != nil {
+		return err
+	}
+	_, err = s.create.Exec(tid, action, days, inactive, uid)
+	return err
+}
+
+func (s *DefaultTopicTimedActionStore) Delete(id int) error {
+	_, err := s.delete.Exec(id)
+	return err
+}
+
+func (s *DefaultTopicTimedActionStore) DeleteByTopic(tid int) error {
+	_, err := s.deleteByTopic.Exec(tid)
+	return err
+}
+
+// Run does a to it's topic right away, it's logged as the moderator who set it
+func (s *DefaultTopicTimedActionStore) Run(a *TopicTimedAction) error {
+	// Claim it first, so that if several servers get to it at once, only one of them does it
+	res, err := s.delete.Exec(a.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	t, err := Topics.Get(a.TopicID)
+	if err == ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var action string
+	switch a.Action {
+	case "unpin":
+		if !t.Sticky {
+			return nil
+		}
+		action = "unstick"
+		err = t.Unstick()
+	case "lock":
+		if t.IsClosed {
+			return nil
+		}
+		action = "lock"
+		err = t.Lock()
+	default:
+		return ErrBadTimedAction
+	}
+	if err != nil {
+		return err
+	}
+	err = ModLogs.Create(action, t.ID, "topic", "", a.CreatedBy)
+	if err != nil {
+		return err
+	}
+	return t.CreateActionReply(action, "", a.CreatedBy)
+}
+
+// RunDue does the actions whose time has come
+func (s *DefaultTopicTimedActionStore) RunDue() error {
+	all, err := s.list(s.getAll)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, a := range all {
+		t, err := Topics.Get(a.TopicID)
+		if err == ErrNoRows {
+			// The topic has gone, so there's nothing left to do
+			if err = s.Delete(a.ID); err != nil {
+				return err
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		if a.DueAt(t).After(now) {
+			continue
+		}
+		if err = s.Run(a); err != nil {
+			// One bad action shouldn't hold up the rest
+			LogError(err)
+		}
+	}
+	return nil
+}
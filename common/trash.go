@@ -0,0 +1,251 @@
+package common
+
+import (
+	"database/sql"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var Trash TrashStore
+var trashStmts TrashStmts
+
+type TrashStmts struct {
+	trashTopic   *sql.Stmt
+	restoreTopic *sql.Stmt
+	trashReply   *sql.Stmt
+	restoreReply *sql.Stmt
+}
+
+func init() {
+	DbInits.Add(func(acc *qgen.Accumulator) error {
+		trashStmts = TrashStmts{
+			trashTopic:   acc.Update("topics").Set("deleted=1,deletedAt=UTC_TIMESTAMP(),deletedBy=?").Where("tid=?").Prepare(),
+			restoreTopic: acc.Update("topics").Set("deleted=0,deletedBy=0").Where("tid=?").Prepare(),
+			trashReply:   acc.Update("replies").Set("deleted=1,deletedAt=UTC_TIMESTAMP(),deletedBy=?").Where("rid=?").Prepare(),
+			restoreReply: acc.Update("replies").Set("deleted=0,deletedBy=0").Where("rid=?").Prepare(),
+		}
+		return acc.FirstError()
+	})
+}
+
+// SoftDelete moves the topic into the trash, it'll be hidden from everyone who can't see the trash until it's either restored or purged
+func (t *Topic) SoftDelete(uid int) error {
+	if t.Deleted {
+		return nil
+	}
+	_, err := trashStmts.trashTopic.Exec(uid, t.ID)
+	t.cacheRemove()
+	if err != nil {
+		return err
+	}
+	t.Deleted = true
+	err = Forums.RemoveTopic(t.ParentID)
+	if err != nil && err != ErrNoRows {
+		return err
+	}
+	// The replies stay where they are, so they come back in one piece if the topic is restored
+	return (&User{ID: t.CreatedBy}).RecalcPostStats()
+}
+
+// Restore pulls the topic back out of the trash
+func (t *Topic) Restore() error {
+	if !t.Deleted {
+		return nil
+	}
+	_, err := trashStmts.restoreTopic.Exec(t.ID)
+	t.cacheRemove()
+	if err != nil {
+		return err
+	}
+	t.Deleted = false
+	err = Forums.RestoreTopic(t.ParentID)
+	if err != nil && err != ErrNoRows {
+		return err
+	}
+	return (&User{ID: t.CreatedBy}).RecalcPostStats()
+}
+
+// SoftDelete moves the reply into the trash, the topic's post count and last reply are worked out again without it
+func (r *Reply) SoftDelete(uid int) error {
+	if r.Deleted {
+		return nil
+	}
+	_, err := trashStmts.trashReply.Exec(uid, r.ID)
+	_ = Rstore.GetCache().Remove(r.ID)
+	if err != nil {
+		return err
+	}
+	r.Deleted = true
+	// An answer which is in the trash can't solve anything
+	_, err = replyStmts.clearAnswer.Exec(r.ParentID, r.ID)
+	if err != nil {
+		return err
+	}
+	return r.recalcTrash()
+}
+
+// Restore pulls the reply back out of the trash
+func (r *Reply) Restore() error {
+	if !r.Deleted {
+		return nil
+	}
+	_, err := trashStmts.restoreReply.Exec(r.ID)
+	_ = Rstore.GetCache().Remove(r.ID)
+	if err != nil {
+		return err
+	}
+	r.Deleted = false
+	return r.recalcTrash()
+}
+
+func (r *Reply) recalcTrash() error {
+	err := (&Topic{ID: r.ParentID}).RecalcReplies()
+	if err != nil {
+		return err
+	}
+	return (&User{ID: r.CreatedBy}).RecalcPostStats()
+}
+
+// TrashItem is a topic or reply sitting in the trash
+type TrashItem struct {
+	ID        int
+	TopicID   int
+	Title     string // The title of the topic, or the one the reply is in
+	Content   string
+	CreatedBy int
+	DeletedAt time.Time
+	DeletedBy int
+}
+
+// TrashStore lists the topics and replies which have been soft deleted and purges the ones which have been in there for too long
+type TrashStore interface {
+	Topics(offset, perPage int) ([]*TrashItem, error)
+	Replies(offset, perPage int) ([]*TrashItem, error)
+	CountTopics() int
+	CountReplies() int
+	CountRepliesIn(tid int) int
+	Purge(days int) (count int, err error)
+}
+
+type DefaultTrashStore struct {
+	topics       *sql.Stmt
+	replies      *sql.Stmt
+	countTopics  *sql.Stmt
+	countReplies *sql.Stmt
+	countIn      *sql.Stmt
+	oldTopics    *sql.Stmt
+	oldReplies   *sql.Stmt
+}
+
+func NewDefaultTrashStore(acc *qgen.Accumulator) (*DefaultTrashStore, error) {
+	return &DefaultTrashStore{
+		topics:       acc.Select("topics").Columns("tid,title,content,createdBy,deletedAt,deletedBy").Where("deleted=1").Orderby("deletedAt DESC").Limit("?,?").Prepare(),
+		replies:      acc.SimpleLeftJoin("replies AS r", "topics AS t", "r.rid, r.tid, t.title, r.content, r.createdBy, r.deletedAt, r.deletedBy", "r.tid=t.tid", "r.deleted=1", "r.deletedAt DESC", "?,?"),
+		countTopics:  acc.Count("topics").Where("deleted=1").Prepare(),
+		countReplies: acc.Count("replies").Where("deleted=1").Prepare(),
+		countIn:      acc.Count("replies").Where("tid=? AND deleted=1").Prepare(),
+		oldTopics:    acc.Select("topics").Columns("tid").Where("deleted=1").DateOlderThanQ("deletedAt", "day").Prepare(),
+		oldReplies:   acc.Select("replies").Columns("rid").Where("deleted=1").DateOlderThanQ("deletedAt", "day").Prepare(),
+	}, acc.FirstError()
+}
+
+func (s *DefaultTrashStore) list(stmt *sql.Stmt, offset, perPage int, topics bool) (items []*TrashItem, err error) {
+	rows, err := stmt.Query(offset, perPage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		i := &TrashItem{}
+		if topics {
+			err = rows.Scan(&i.ID, &i.Title, &i.Content, &i.CreatedBy, &i.DeletedAt, &i.DeletedBy)
+			i.TopicID = i.ID
+		} else {
+			err = rows.Scan(&i.ID, &i.TopicID, &i.Title, &i.Content, &i.CreatedBy, &i.DeletedAt, &i.DeletedBy)
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	return items, rows.Err()
+}
+
+// Topics returns the topics in the trash, the most recently deleted ones come first
+func (s *DefaultTrashStore) Topics(offset, perPage int) ([]*TrashItem, error) {
+	return s.list(s.topics, offset, perPage, true)
+}
+
+// Replies returns the replies in the trash, the most recently deleted ones come first
+func (s *DefaultTrashStore) Replies(offset, perPage int) ([]*TrashItem, error) {
+	return s.list(s.replies, offset, perPage, false)
+}
+
+func (s *DefaultTrashStore) CountTopics() int {
+	return Countf(s.countTopics)
+}
+
+func (s *DefaultTrashStore) CountReplies() int {
+	return Countf(s.countReplies)
+}
+
+// CountRepliesIn returns the number of replies in the trash which belong to the topic tid
+func (s *DefaultTrashStore) CountRepliesIn(tid int) int {
+	return Countf(s.countIn, tid)
+}
+
+func (s *DefaultTrashStore) ids(stmt *sql.Stmt, days int) (ids []int, err error) {
+	rows, err := stmt.Query(days)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Purge permanently deletes the topics and replies which were moved into the trash more than days days ago
+func (s *DefaultTrashStore) Purge(days int) (count int, err error) {
+	tids, err := s.ids(s.oldTopics, days)
+	if err != nil {
+		return 0, err
+	}
+	for _, tid := range tids {
+		t, err := Topics.Get(tid)
+		if err == ErrNoRows {
+			continue
+		} else if err != nil {
+			return count, err
+		}
+		if err = t.Delete(); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	// The replies in those topics are gone now, so look these up afterwards
+	rids, err := s.ids(s.oldReplies, days)
+	if err != nil {
+		return count, err
+	}
+	for _, rid := range rids {
+		r, err := Rstore.Get(rid)
+		if err == ErrNoRows {
+			continue
+		} else if err != nil {
+			return count, err
+		}
+		if err = r.Delete(); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
@@ -15,6 +15,7 @@ import (
 
 	//"log"
 
+	p "github.com/Azareal/Gosora/common/phrases"
 	qgen "github.com/Azareal/Gosora/query_gen"
 	"github.com/go-sql-driver/mysql"
 )
@@ -63,6 +64,7 @@ type User struct {
 
 	ParseSettings *ParseSettings
 	Privacy       UserPrivacy
+	Lang          string // The language pack the user picked, blank for the site's one
 }
 
 type UserPrivacy struct {
@@ -151,6 +153,7 @@ type UserStmts struct {
 	decLiked      *sql.Stmt
 	updateLastIP  *sql.Stmt
 	updatePrivacy *sql.Stmt
+	setLang       *sql.Stmt
 
 	setPassword *sql.Stmt
 
@@ -198,6 +201,7 @@ func init() {
 			//recalcLastLiked: acc...
 			updateLastIP:  acc.SimpleUpdate(u, "last_ip=?", w),
 			updatePrivacy: acc.Update(u).Set("profile_comments=?,enable_embeds=?").Where(w).Prepare(),
+			setLang:       acc.Update(u).Set("lang=?").Where(w).Prepare(),
 
 			setPassword: acc.Update(u).Set("password=?,salt=?").Where(w).Prepare(),
 
@@ -341,6 +345,15 @@ func (u *User) Delete() error {
 	if e != nil {
 		return e
 	}
+	if e = ProfileFields.DeleteValues(u.ID); e != nil {
+		return e
+	}
+	if e = ForumMods.DeleteByUser(u.ID); e != nil {
+		return e
+	}
+	if e = Drafts.DeleteAll(u.ID); e != nil {
+		return e
+	}
 	u.CacheRemove()
 	return nil
 }
@@ -568,6 +581,20 @@ func (u *User) UpdateIP(ip string) error {
 var ErrProfileCommentsOutOfBounds = errors.New("profile_comments must be an integer between -1 and 4")
 var ErrEnableEmbedsOutOfBounds = errors.New("enable_embeds must be -1, 0 or 1")
 
+// SetLang changes the language pack the user sees the site in, a blank one goes back to the site's language
+func (u *User) SetLang(lang string) error {
+	if lang != "" {
+		if _, ok := p.GetLanguagePackByName(lang); !ok {
+			return p.ErrNoLangPack
+		}
+	}
+	_, e := userStmts.setLang.Exec(lang, u.ID)
+	if uc := Users.GetCache(); uc != nil {
+		uc.Remove(u.ID)
+	}
+	return e
+}
+
 /*func (u *User) UpdatePrivacyS(sProfileComments, sEnableEmbeds string) error {
 	return u.UpdatePrivacy(profileComments, enableEmbeds)
 }*/
@@ -822,6 +849,15 @@ func SetPassword(uid int, password string) error {
 	return err
 }
 
+// SetPasswordHash is for bringing over hashes from other software, CheckPassword has to recognise them
+func SetPasswordHash(uid int, hash, salt string) error {
+	if HashAlgo(hash) == "" {
+		return ErrHashNotExist
+	}
+	_, err := userStmts.setPassword.Exec(hash, salt, uid)
+	return err
+}
+
 // TODO: Write units tests for this
 func wordsToScore(wcount int, topic bool) (score int) {
 	if topic {
@@ -0,0 +1,374 @@
+package common
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var userExportStmts UserExportStmts
+
+type UserExportStmts struct {
+	topics          *sql.Stmt
+	replies         *sql.Stmt
+	profileComments *sql.Stmt
+	likes           *sql.Stmt
+	attachments     *sql.Stmt
+	convos          *sql.Stmt
+	lastActive      *sql.Stmt
+}
+
+func init() {
+	DbInits.Add(func(acc *qgen.Accumulator) error {
+		userExportStmts = UserExportStmts{
+			topics:          acc.Select("topics").Columns("tid,title,content,createdAt,parentID,ip").Where("createdBy=?").Orderby("tid ASC").Prepare(),
+			replies:         acc.Select("replies").Columns("rid,tid,content,createdAt,ip").Where("createdBy=?").Orderby("rid ASC").Prepare(),
+			profileComments: acc.Select("users_replies").Columns("rid,uid,content,createdAt,ip").Where("createdBy=?").Orderby("rid ASC").Prepare(),
+			likes:           acc.Select("likes").Columns("targetItem,targetType,createdAt").Where("sentBy=?").Prepare(),
+			attachments:     acc.Select("attachments").Columns("attachID,originID,originTable,path").Where("uploadedBy=?").Orderby("attachID ASC").Prepare(),
+			convos:          acc.Select("conversations_participants").Columns("cid").Where("uid=?").Prepare(),
+			lastActive:      acc.Select("users").Columns("lastActiveAt").Where("uid=?").Prepare(),
+		}
+		return acc.FirstError()
+	})
+}
+
+// The shapes of the files in a data export, they only hold what the user put in or what we know about them, not things like the parsed copies of their posts
+type ExportProfile struct {
+	ID            int
+	Name          string
+	Email         string
+	Group         string
+	CreatedAt     time.Time
+	LastActiveAt  time.Time
+	LastIP        string `json:",omitempty"`
+	Level         int
+	Score         int
+	Posts         int
+	Liked         int
+	Privacy       UserPrivacy
+	Lang          string `json:",omitempty"`
+	ProfileFields map[string]string
+	ThemeSettings map[string]map[string]string `json:",omitempty"`
+}
+
+type ExportEmail struct {
+	Email     string
+	Validated bool
+	Primary   bool
+}
+
+type ExportPost struct {
+	ID        int
+	TopicID   int    `json:",omitempty"`
+	ForumID   int    `json:",omitempty"`
+	ProfileID int    `json:",omitempty"`
+	Title     string `json:",omitempty"`
+	Content   string
+	CreatedAt time.Time
+	IP        string `json:",omitempty"`
+}
+
+type ExportLike struct {
+	TargetID   int
+	TargetType string
+	CreatedAt  time.Time
+}
+
+type ExportAttachment struct {
+	ID         int
+	OriginID   int
+	OriginType string
+	File       string // Where it is in the export
+}
+
+type ExportConvo struct {
+	ID           int
+	CreatedAt    time.Time
+	Participants []string
+	Posts        []ExportConvoPost
+}
+
+type ExportConvoPost struct {
+	ID     int
+	Sender string
+	Body   string
+}
+
+// ExportUserData writes a zip to w with everything the user has put into the forum, along with what we've recorded about them, such as their logins.
+// It's streamed straight out as it's built, so an error midway will leave w with a truncated archive.
+func ExportUserData(u *User, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	writeJSON := func(name string, v interface{}) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(v, "", "\t")
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	// The IPs are only handed back if we're still holding onto them, the daily tasks will have emptied them out otherwise
+	exportIP := func(ip string) string {
+		if Config.DisablePostIP {
+			return ""
+		}
+		return ip
+	}
+
+	p := ExportProfile{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt, Level: u.Level, Score: u.Score, Posts: u.Posts, Liked: u.Liked, Privacy: u.Privacy, Lang: u.Lang, ProfileFields: make(map[string]string)}
+	if g, err := Groups.Get(u.Group); err == nil {
+		p.Group = g.Name
+	}
+	if !Config.DisableLastIP {
+		p.LastIP = u.GetIP()
+	}
+	err := userExportStmts.lastActive.QueryRow(u.ID).Scan(&p.LastActiveAt)
+	if err != nil {
+		return err
+	}
+	vals, err := ProfileFields.Values(u.ID)
+	if err != nil {
+		return err
+	}
+	for _, v := range vals {
+		p.ProfileFields[v.Field.Name] = v.Value
+	}
+	p.ThemeSettings, err = UserThemeSettings.GetAll(u.ID)
+	if err != nil {
+		return err
+	}
+	if err = writeJSON("profile.json", p); err != nil {
+		return err
+	}
+
+	emails, err := Emails.GetEmailsByUser(u)
+	if err != nil {
+		return err
+	}
+	eemails := make([]ExportEmail, len(emails))
+	for i, e := range emails {
+		eemails[i] = ExportEmail{e.Email, e.Validated, e.Primary}
+	}
+	if err = writeJSON("emails.json", eemails); err != nil {
+		return err
+	}
+
+	posts := func(stmt *sql.Stmt, scan func(rows *sql.Rows, p *ExportPost) error) (out []ExportPost, err error) {
+		rows, err := stmt.Query(u.ID)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var p ExportPost
+			if err = scan(rows, &p); err != nil {
+				return nil, err
+			}
+			p.IP = exportIP(p.IP)
+			out = append(out, p)
+		}
+		return out, rows.Err()
+	}
+	topics, err := posts(userExportStmts.topics, func(rows *sql.Rows, p *ExportPost) error {
+		err := rows.Scan(&p.ID, &p.Title, &p.Content, &p.CreatedAt, &p.ForumID, &p.IP)
+		p.TopicID = p.ID
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err = writeJSON("topics.json", topics); err != nil {
+		return err
+	}
+	replies, err := posts(userExportStmts.replies, func(rows *sql.Rows, p *ExportPost) error {
+		return rows.Scan(&p.ID, &p.TopicID, &p.Content, &p.CreatedAt, &p.IP)
+	})
+	if err != nil {
+		return err
+	}
+	if err = writeJSON("replies.json", replies); err != nil {
+		return err
+	}
+	comments, err := posts(userExportStmts.profileComments, func(rows *sql.Rows, p *ExportPost) error {
+		return rows.Scan(&p.ID, &p.ProfileID, &p.Content, &p.CreatedAt, &p.IP)
+	})
+	if err != nil {
+		return err
+	}
+	if err = writeJSON("profile_comments.json", comments); err != nil {
+		return err
+	}
+
+	likes, err := exportLikes(u.ID)
+	if err != nil {
+		return err
+	}
+	if err = writeJSON("likes.json", likes); err != nil {
+		return err
+	}
+
+	convos, err := exportConvos(u.ID)
+	if err != nil {
+		return err
+	}
+	if err = writeJSON("conversations.json", convos); err != nil {
+		return err
+	}
+
+	var logs []LoginLogItem
+	for offset, perPage := 0, 100; ; offset += perPage {
+		page, err := LoginLogs.GetOffset(u.ID, offset, perPage)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, page...)
+		if len(page) < perPage {
+			break
+		}
+	}
+	if err = writeJSON("login_logs.json", logs); err != nil {
+		return err
+	}
+
+	attachs, err := exportAttachments(u.ID, zw)
+	if err != nil {
+		return err
+	}
+	if err = writeJSON("attachments.json", attachs); err != nil {
+		return err
+	}
+	avatars, err := avatarFiles(u.ID)
+	if err != nil {
+		return err
+	}
+	for _, avatar := range avatars {
+		if err = exportFile(zw, avatar, "avatar/"+filepath.Base(avatar)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func exportLikes(uid int) (likes []ExportLike, err error) {
+	rows, err := userExportStmts.likes.Query(uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var l ExportLike
+		if err = rows.Scan(&l.TargetID, &l.TargetType, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		likes = append(likes, l)
+	}
+	return likes, rows.Err()
+}
+
+// exportConvos returns the conversations the user is in, with everyone's posts in them, as the user could see all of those anyway
+func exportConvos(uid int) (convos []ExportConvo, err error) {
+	var cids []int
+	rows, err := userExportStmts.convos.Query(uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cid int
+		if err = rows.Scan(&cid); err != nil {
+			return nil, err
+		}
+		cids = append(cids, cid)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string)
+	name := func(uid int) string {
+		if n, ok := names[uid]; ok {
+			return n
+		}
+		n := strconv.Itoa(uid)
+		if u, err := Users.Get(uid); err == nil {
+			n = u.Name
+		}
+		names[uid] = n
+		return n
+	}
+	for _, cid := range cids {
+		co, err := Convos.Get(cid)
+		if err == ErrNoRows {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		ec := ExportConvo{ID: co.ID, CreatedAt: co.CreatedAt}
+		uids, err := co.Uids()
+		if err != nil {
+			return nil, err
+		}
+		for _, uid := range uids {
+			ec.Participants = append(ec.Participants, name(uid))
+		}
+		posts, err := co.Posts(0, co.PostsCount())
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range posts {
+			ec.Posts = append(ec.Posts, ExportConvoPost{p.ID, name(p.CreatedBy), p.Body})
+		}
+		convos = append(convos, ec)
+	}
+	return convos, nil
+}
+
+// exportAttachments copies the files the user uploaded into the attachments folder of the export
+func exportAttachments(uid int, zw *zip.Writer) (attachs []ExportAttachment, err error) {
+	rows, err := userExportStmts.attachments.Query(uid)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var a ExportAttachment
+		var path string
+		if err = rows.Scan(&a.ID, &a.OriginID, &a.OriginType, &path); err != nil {
+			return nil, err
+		}
+		a.File = "attachments/" + strconv.Itoa(a.ID) + "_" + filepath.Base(path)
+		err = exportFile(zw, "./attachs/"+filepath.Base(path), a.File)
+		if os.IsNotExist(err) {
+			a.File = ""
+		} else if err != nil {
+			return nil, err
+		}
+		attachs = append(attachs, a)
+	}
+	return attachs, rows.Err()
+}
+
+func exportFile(zw *zip.Writer, fpath, name string) error {
+	src, err := os.Open(fpath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	return err
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"database/sql"
+	"errors"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var ErrBadVoteWeight = errors.New("Votes have to be either 1 or -1")
+
+var Votes VoteStore
+
+// VoteStore holds the up and down votes on the replies in question forums
+type VoteStore interface {
+	Vote(r *Reply, uid, weight int) error
+	Get(rid, uid int) (weight int, err error)
+	BulkGetMap(rids []int, uid int) (map[int]int, error)
+	DeleteAll(rid int) error
+	DeleteByTopic(tid int) error
+	Count() (count int)
+}
+
+type DefaultVoteStore struct {
+	get           *sql.Stmt
+	create        *sql.Stmt
+	update        *sql.Stmt
+	delete        *sql.Stmt
+	deleteAll     *sql.Stmt
+	deleteByTopic *sql.Stmt
+	addVotes      *sql.Stmt
+	count         *sql.Stmt
+}
+
+func NewDefaultVoteStore(acc *qgen.Accumulator) (*DefaultVoteStore, error) {
+	rv := "reply_votes"
+	return &DefaultVoteStore{
+		get:           acc.Select(rv).Columns("weight").Where("rid=? AND uid=?").Prepare(),
+		create:        acc.Insert(rv).Columns("rid,tid,uid,weight,createdAt").Fields("?,?,?,?,UTC_TIMESTAMP()").Prepare(),
+		update:        acc.Update(rv).Set("weight=?").Where("rid=? AND uid=?").Prepare(),
+		delete:        acc.Delete(rv).Where("rid=? AND uid=?").Prepare(),
+		deleteAll:     acc.Delete(rv).Where("rid=?").Prepare(),
+		deleteByTopic: acc.Delete(rv).Where("tid=?").Prepare(),
+		addVotes:      acc.Update("replies").Set("votes=votes+?").Where("rid=?").Prepare(),
+		count:         acc.Count(rv).Prepare(),
+	}, acc.FirstError()
+}
+
+// Vote casts the vote uid gave the reply r. Voting the same way twice takes the vote back and voting the other way flips it.
+// TODO: Use a transaction
+func (s *DefaultVoteStore) Vote(r *Reply, uid, weight int) error {
+	if weight != 1 && weight != -1 {
+		return ErrBadVoteWeight
+	}
+	prev, err := s.Get(r.ID, uid)
+	if err != nil {
+		return err
+	}
+
+	var delta int
+	switch prev {
+	case 0:
+		_, err = s.create.Exec(r.ID, r.ParentID, uid, weight)
+		delta = weight
+	case weight:
+		_, err = s.delete.Exec(r.ID, uid)
+		delta = -weight
+	default:
+		_, err = s.update.Exec(weight, r.ID, uid)
+		delta = weight - prev
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.addVotes.Exec(delta, r.ID)
+	_ = Rstore.GetCache().Remove(r.ID)
+	return err
+}
+
+// Get returns the weight of the vote uid gave the reply rid, or zero if they haven't voted on it
+func (s *DefaultVoteStore) Get(rid, uid int) (weight int, err error) {
+	err = s.get.QueryRow(rid, uid).Scan(&weight)
+	if err == ErrNoRows {
+		return 0, nil
+	}
+	return weight, err
+}
+
+// BulkGetMap returns the votes uid gave each of the replies in rids, replies they haven't voted on are left out of the map
+func (s *DefaultVoteStore) BulkGetMap(rids []int, uid int) (map[int]int, error) {
+	list := make(map[int]int)
+	if len(rids) == 0 {
+		return list, nil
+	}
+	err := qgen.NewAcc().Select("reply_votes").Columns("rid,weight").Where("uid=?").In("rid", rids).EachP(func(rows *sql.Rows) error {
+		var rid, weight int
+		if err := rows.Scan(&rid, &weight); err != nil {
+			return err
+		}
+		list[rid] = weight
+		return nil
+	}, uid)
+	return list, err
+}
+
+// DeleteAll removes every vote on the reply rid, e.g. when it is deleted
+func (s *DefaultVoteStore) DeleteAll(rid int) error {
+	_, err := s.deleteAll.Exec(rid)
+	return err
+}
+
+// DeleteByTopic removes every vote on the replies in the topic tid, e.g. when it is deleted
+func (s *DefaultVoteStore) DeleteByTopic(tid int) error {
+	_, err := s.deleteByTopic.Exec(tid)
+	return err
+}
+
+// Count returns the total number of votes globally
+func (s *DefaultVoteStore) Count() (count int) {
+	err := s.count.QueryRow().Scan(&count)
+	if err != nil {
+		LogError(err)
+	}
+	return count
+}
@@ -47,9 +47,11 @@ type Widget struct {
 	Body     string
 	Side     string
 	Type     string
+	Rules    WidgetRules
 
 	Literal      bool
 	TickMask     atomic.Value
+	TickCache    atomic.Value // Holds a *widgetCache for the widgets which only refresh their data every so often
 	InitFunc     func(w *Widget, sched *WidgetScheduler) error
 	ShutdownFunc func(w *Widget) error
 	BuildFunc    func(w *Widget, hvars interface{}) (string, error)
@@ -106,6 +108,11 @@ func (w *Widget) Allowed(zone string, zoneid int) bool {
 	return false
 }
 
+// Visible checks both the Location and the visibility rules to see if this widget should be shown on the page in h
+func (w *Widget) Visible(h *Header) bool {
+	return w.Allowed(h.Zone, h.ZoneID) && w.Rules.Match(h)
+}
+
 // TODO: Refactor
 func (w *Widget) Build(hvars interface{}) (string, error) {
 	if w.Literal {
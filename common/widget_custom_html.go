@@ -0,0 +1,145 @@
+package common
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The tags which can be used in the custom html widgets along with the attributes they're allowed to have
+var widgetHTMLTags = map[string][]string{
+	"a":          {"href", "title"},
+	"b":          nil,
+	"strong":     nil,
+	"i":          nil,
+	"em":         nil,
+	"u":          nil,
+	"s":          nil,
+	"del":        nil,
+	"small":      nil,
+	"p":          nil,
+	"br":         nil,
+	"hr":         nil,
+	"span":       nil,
+	"div":        nil,
+	"ul":         nil,
+	"ol":         nil,
+	"li":         nil,
+	"h2":         nil,
+	"h3":         nil,
+	"h4":         nil,
+	"blockquote": nil,
+	"code":       nil,
+	"pre":        nil,
+	"img":        {"src", "alt", "width", "height"},
+}
+
+// Every tag is allowed to have these
+var widgetHTMLAttrs = []string{"class", "title"}
+
+var widgetHTMLVoid = map[string]bool{"br": true, "hr": true, "img": true}
+
+// The contents of these are dropped along with the tag, rather than being shown as text
+var widgetHTMLDrop = map[string]bool{"script": true, "style": true, "iframe": true, "object": true, "embed": true, "noscript": true, "template": true, "textarea": true, "title": true, "svg": true, "math": true}
+
+func widgetHTMLAttrAllowed(tag, attr string) bool {
+	for _, a := range widgetHTMLAttrs {
+		if a == attr {
+			return true
+		}
+	}
+	for _, a := range widgetHTMLTags[tag] {
+		if a == attr {
+			return true
+		}
+	}
+	return false
+}
+
+// widgetHTMLSafeURL makes sure a link or image can't run a script, e.g. with javascript:
+func widgetHTMLSafeURL(attr, val string) bool {
+	u, err := url.Parse(strings.TrimSpace(val))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https":
+		return true
+	case "mailto":
+		return attr == "href"
+	}
+	return false
+}
+
+// SanitiseWidgetHTML strips out everything but a few harmless tags and attributes from in. Tags which are left open are closed, so a widget can't mangle the rest of the page.
+func SanitiseWidgetHTML(in string) string {
+	var sb strings.Builder
+	var open []string
+	var drop string
+	z := html.NewTokenizer(strings.NewReader(in))
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				sb.WriteString("</" + open[i] + ">")
+			}
+			return sb.String()
+		case html.TextToken:
+			if drop == "" {
+				sb.WriteString(html.EscapeString(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			t := z.Token()
+			if drop != "" {
+				continue
+			}
+			if widgetHTMLDrop[t.Data] {
+				if tt == html.StartTagToken {
+					drop = t.Data
+				}
+				continue
+			}
+			if _, ok := widgetHTMLTags[t.Data]; !ok {
+				continue
+			}
+			sb.WriteString("<" + t.Data)
+			for _, a := range t.Attr {
+				if a.Namespace != "" || !widgetHTMLAttrAllowed(t.Data, a.Key) {
+					continue
+				}
+				if (a.Key == "href" || a.Key == "src") && !widgetHTMLSafeURL(a.Key, a.Val) {
+					continue
+				}
+				sb.WriteString(" " + a.Key + "=\"" + html.EscapeString(a.Val) + "\"")
+			}
+			if t.Data == "a" {
+				sb.WriteString(" rel=\"nofollow noopener\"")
+			}
+			sb.WriteString(">")
+			if !widgetHTMLVoid[t.Data] && tt == html.StartTagToken {
+				open = append(open, t.Data)
+			}
+		case html.EndTagToken:
+			t := z.Token()
+			if drop != "" {
+				if t.Data == drop {
+					drop = ""
+				}
+				continue
+			}
+			// Close everything which was opened inside of this tag, stray end tags are ignored
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] != t.Data {
+					continue
+				}
+				for j := len(open) - 1; j >= i; j-- {
+					sb.WriteString("</" + open[j] + ">")
+				}
+				open = open[:i]
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package common
+
+import "errors"
+
+type recentTopics struct {
+	*Header
+	Name   string
+	Topics []*TopicsRow
+}
+
+// recentTopicsRender leans on the topic list, which already keeps the first page for each group in memory
+func recentTopicsRender(w *Widget, hvars interface{}) (string, error) {
+	h := hvars.(*Header)
+	u := h.CurrentUser
+	name, count := widgetNameCount(w, "widget.recent_topics_name")
+
+	var topicList []*TopicsRow
+	var err error
+	if u.IsSuperAdmin {
+		canSee, err := Forums.GetAllVisibleIDs()
+		if err != nil {
+			return "", err
+		}
+		topicList, _, _, err = TopicList.GetListByCanSee(canSee, 1, 0, nil, TopicListFilter{})
+	} else {
+		g, gerr := Groups.Get(u.Group)
+		if gerr != nil {
+			// TODO: Revisit this
+			return "", errors.New("Something weird happened")
+		}
+		topicList, _, _, err = TopicList.GetListByGroup(g, 1, 0, nil)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// The stickies would otherwise crowd out the topics which are actually recent
+	var topics []*TopicsRow
+	for _, t := range topicList {
+		if t.Sticky {
+			continue
+		}
+		topics = append(topics, t)
+		if len(topics) == count {
+			break
+		}
+	}
+
+	rt := &recentTopics{h, name, topics}
+	return "", h.Theme.RunTmpl("widget_recent_topics", rt, h.Writer)
+}
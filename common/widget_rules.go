@@ -0,0 +1,169 @@
+package common
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrWidgetRuleGroup = errors.New("One of the groups in the visibility rules doesn't exist.")
+var ErrWidgetRuleForum = errors.New("One of the forums in the visibility rules doesn't exist.")
+var ErrWidgetRuleRoute = errors.New("The routes in the visibility rules need to start with / or !/")
+var ErrWidgetRuleLoggedin = errors.New("That isn't a valid logged in state for the visibility rules.")
+
+const (
+	WidgetLoggedinAny = iota
+	WidgetLoggedinMembers
+	WidgetLoggedinGuests
+)
+
+// WidgetRules narrows down who can see a widget on top of it's Location. An empty rule lets everyone through.
+// They're stored in the widget's data under the Vis prefix, so they don't get in the way of the data for the individual widget types.
+type WidgetRules struct {
+	Groups   []int
+	Loggedin int      // WidgetLoggedinAny, WidgetLoggedinMembers or WidgetLoggedinGuests
+	Routes   []string // Path prefixes, e.g. /topics/, a ! in front hides the widget on that path instead
+	Forums   []int    // The forums and the topics in them which the widget shows up in
+}
+
+// ParseWidgetRules pulls the visibility rules out of the data for a widget, checking they make sense as it goes
+func ParseWidgetRules(data map[string]string) (rules WidgetRules, err error) {
+	return parseWidgetRules(data, true)
+}
+
+// When the widgets are loaded, the rules are parsed loosely, so that deleting a forum or group doesn't stop the widgets from loading
+func parseWidgetRules(data map[string]string, strict bool) (rules WidgetRules, err error) {
+	parseIDs := func(key string, exists func(int) bool, existErr error) ([]int, error) {
+		var ids []int
+		for _, sid := range strings.Split(data[key], ",") {
+			sid = strings.TrimSpace(sid)
+			if sid == "" {
+				continue
+			}
+			id, err := strconv.Atoi(sid)
+			if err != nil || !exists(id) {
+				if !strict {
+					continue
+				}
+				return nil, existErr
+			}
+			ids = append(ids, id)
+		}
+		return ids, nil
+	}
+	rules.Groups, err = parseIDs("VisGroups", Groups.Exists, ErrWidgetRuleGroup)
+	if err != nil {
+		return rules, err
+	}
+	rules.Forums, err = parseIDs("VisForums", Forums.Exists, ErrWidgetRuleForum)
+	if err != nil {
+		return rules, err
+	}
+
+	if sloggedin := data["VisLoggedin"]; sloggedin != "" {
+		rules.Loggedin, err = strconv.Atoi(sloggedin)
+		if err != nil || rules.Loggedin < WidgetLoggedinAny || rules.Loggedin > WidgetLoggedinGuests {
+			if strict {
+				return rules, ErrWidgetRuleLoggedin
+			}
+			rules.Loggedin = WidgetLoggedinAny
+		}
+	}
+
+	for _, route := range strings.Split(data["VisRoutes"], "\n") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		if !strings.HasPrefix(strings.TrimPrefix(route, "!"), "/") {
+			if !strict {
+				continue
+			}
+			return rules, ErrWidgetRuleRoute
+		}
+		rules.Routes = append(rules.Routes, route)
+	}
+	return rules, nil
+}
+
+// Data turns the rules back into the form they're stored in
+func (r *WidgetRules) Data(data map[string]string) {
+	joinIDs := func(ids []int) string {
+		sids := make([]string, len(ids))
+		for i, id := range ids {
+			sids[i] = strconv.Itoa(id)
+		}
+		return strings.Join(sids, ",")
+	}
+	data["VisGroups"] = joinIDs(r.Groups)
+	data["VisForums"] = joinIDs(r.Forums)
+	data["VisLoggedin"] = strconv.Itoa(r.Loggedin)
+	data["VisRoutes"] = strings.Join(r.Routes, "\n")
+}
+
+// Match checks whether the page in h is allowed to show a widget with these rules
+func (r *WidgetRules) Match(h *Header) bool {
+	u := h.CurrentUser
+	switch {
+	case r.Loggedin == WidgetLoggedinMembers && !u.Loggedin:
+		return false
+	case r.Loggedin == WidgetLoggedinGuests && u.Loggedin:
+		return false
+	}
+
+	if len(r.Groups) > 0 && !containsInt(r.Groups, u.Group) {
+		return false
+	}
+
+	if len(r.Forums) > 0 {
+		fid := widgetZoneForum(h)
+		if fid == 0 || !containsInt(r.Forums, fid) {
+			return false
+		}
+	}
+
+	if len(r.Routes) > 0 {
+		var positive, matched bool
+		for _, route := range r.Routes {
+			if route[0] == '!' {
+				if strings.HasPrefix(h.RequestPath, route[1:]) {
+					return false
+				}
+				continue
+			}
+			positive = true
+			if strings.HasPrefix(h.RequestPath, route) {
+				matched = true
+			}
+		}
+		if positive && !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// widgetZoneForum works out which forum the page in h belongs to, if any
+func widgetZoneForum(h *Header) int {
+	switch h.Zone {
+	case "view_forum", "topics":
+		return h.ZoneID
+	case "view_topic":
+		switch topic := h.ZoneData.(type) {
+		case TopicUser:
+			return topic.ParentID
+		case *TopicUser:
+			return topic.ParentID
+		}
+	}
+	return 0
+}
+
+func containsInt(ids []int, id int) bool {
+	for _, iid := range ids {
+		if iid == id {
+			return true
+		}
+	}
+	return false
+}
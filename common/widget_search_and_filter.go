@@ -33,7 +33,7 @@ func widgetSearchAndFilter(widget *Widget, hvars interface{}) (out string, err e
 
 	for _, fid := range canSee {
 		f := Forums.DirtyGet(fid)
-		if f.ParentID == 0 && f.Name != "" && f.Active {
+		if (f.ParentID == 0 || f.ParentType == "forum") && f.Name != "" && f.Active && !f.Category {
 			forums = append(forums, filterForum{f, (header.Zone == "view_forum" || header.Zone == "topics") && header.ZoneID == f.ID})
 		}
 	}
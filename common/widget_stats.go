@@ -0,0 +1,190 @@
+package common
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	p "github.com/Azareal/Gosora/common/phrases"
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// The widgets which are built from queries hang onto their data for this long before it's refreshed
+const widgetCacheTTL = time.Minute
+
+// WidgetMaxCount is the most items the list widgets can show
+const WidgetMaxCount = 20
+
+type widgetCache struct {
+	At   time.Time
+	Data interface{}
+}
+
+type WidgetStatStmts struct {
+	topPosters    *sql.Stmt
+	newestMembers *sql.Stmt
+}
+
+var widgetStatStmts WidgetStatStmts
+
+func init() {
+	DbInits.Add(func(acc *qgen.Accumulator) error {
+		widgetStatStmts = WidgetStatStmts{
+			topPosters:    acc.Select("users").Columns("uid").Where("active=1").Orderby("posts DESC,uid ASC").Limit("?").Prepare(),
+			newestMembers: acc.Select("users").Columns("uid").Where("active=1").Orderby("createdAt DESC,uid DESC").Limit("?").Prepare(),
+		}
+		return acc.FirstError()
+	})
+}
+
+func widgetCacheInit(w *Widget, sched *WidgetScheduler) error {
+	sched.Add(w)
+	return nil
+}
+
+func widgetCacheBuild(w *Widget, build func(w *Widget) (interface{}, error)) (interface{}, error) {
+	data, err := build(w)
+	if err != nil {
+		return nil, err
+	}
+	w.TickCache.Store(&widgetCache{time.Now(), data})
+	return data, nil
+}
+
+// widgetCacheGet returns the data for w, building it when it's missing.
+// The scheduler normally keeps it fresh, but not every dock has one, so it's rebuilt here if it's been left to go stale.
+func widgetCacheGet(w *Widget, build func(w *Widget) (interface{}, error)) (interface{}, error) {
+	if c, ok := w.TickCache.Load().(*widgetCache); ok && time.Since(c.At) < widgetCacheTTL*2 {
+		return c.Data, nil
+	}
+	return widgetCacheBuild(w, build)
+}
+
+func widgetCacheTick(w *Widget, build func(w *Widget) (interface{}, error)) error {
+	if c, ok := w.TickCache.Load().(*widgetCache); ok && time.Since(c.At) < widgetCacheTTL {
+		return nil
+	}
+	_, err := widgetCacheBuild(w, build)
+	return err
+}
+
+// widgetNameCount pulls the title and the number of items to show out of the data for a list widget
+func widgetNameCount(w *Widget, defaultName string) (name string, count int) {
+	var data map[string]string
+	_ = json.Unmarshal([]byte(w.RawBody), &data)
+	name = data["Name"]
+	if name == "" {
+		name = p.GetTmplPhrase(defaultName)
+	}
+	count, err := strconv.Atoi(data["Count"])
+	if err != nil || count <= 0 || count > WidgetMaxCount {
+		count = 5
+	}
+	return name, count
+}
+
+type widgetUsers struct {
+	*Header
+	Name  string
+	Type  string
+	Users []*User
+}
+
+// Banned users are weeded out after they're loaded, so we grab a few extra to fill in the gaps
+func widgetLoadUsers(stmt *sql.Stmt, count int) (users []*User, err error) {
+	rows, err := stmt.Query(count * 2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	umap, err := Users.BulkGetMap(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		u, ok := umap[id]
+		if !ok || u.IsBanned {
+			continue
+		}
+		users = append(users, u)
+		if len(users) == count {
+			break
+		}
+	}
+	return users, nil
+}
+
+func widgetUsersBuild(w *Widget) (interface{}, error) {
+	stmt := widgetStatStmts.topPosters
+	if w.Type == "newest_members" {
+		stmt = widgetStatStmts.newestMembers
+	}
+	name, count := widgetNameCount(w, "widget."+w.Type+"_name")
+	users, err := widgetLoadUsers(stmt, count)
+	if err != nil {
+		return nil, err
+	}
+	return &widgetUsers{Name: name, Type: w.Type, Users: users}, nil
+}
+
+func widgetUsersRender(w *Widget, hvars interface{}) (string, error) {
+	data, err := widgetCacheGet(w, widgetUsersBuild)
+	if err != nil {
+		return "", err
+	}
+	wu := *data.(*widgetUsers)
+	wu.Header = hvars.(*Header)
+	return "", wu.Header.Theme.RunTmpl("widget_users", &wu, wu.Header.Writer)
+}
+
+func widgetUsersTick(w *Widget) error {
+	return widgetCacheTick(w, widgetUsersBuild)
+}
+
+type forumStats struct {
+	*Header
+	Name    string
+	Topics  int
+	Replies int
+	Users   int
+	Newest  *User
+}
+
+func forumStatsBuild(w *Widget) (interface{}, error) {
+	name, _ := widgetNameCount(w, "widget.forum_stats_name")
+	s := &forumStats{Name: name, Topics: Topics.Count(), Replies: Rstore.Count(), Users: Users.Count()}
+	newest, err := widgetLoadUsers(widgetStatStmts.newestMembers, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(newest) > 0 {
+		s.Newest = newest[0]
+	}
+	return s, nil
+}
+
+func forumStatsRender(w *Widget, hvars interface{}) (string, error) {
+	data, err := widgetCacheGet(w, forumStatsBuild)
+	if err != nil {
+		return "", err
+	}
+	s := *data.(*forumStats)
+	s.Header = hvars.(*Header)
+	return "", s.Header.Theme.RunTmpl("widget_forum_stats", &s, s.Header.Writer)
+}
+
+func forumStatsTick(w *Widget) error {
+	return widgetCacheTick(w, forumStatsBuild)
+}
@@ -82,9 +82,38 @@ func preparseWidget(w *Widget, wdata string) (err error) {
 	case "wol_context":
 		w.Literal = false
 		w.BuildFunc = wolContextRender
+	case "recent_topics":
+		w.Literal = false
+		w.BuildFunc = recentTopicsRender
+	case "top_posters", "newest_members":
+		w.Literal = false
+		w.InitFunc = widgetCacheInit
+		w.BuildFunc = widgetUsersRender
+		w.TickFunc = widgetUsersTick
+	case "forum_stats":
+		w.Literal = false
+		w.InitFunc = widgetCacheInit
+		w.BuildFunc = forumStatsRender
+		w.TickFunc = forumStatsTick
+	case "custom_html":
+		var tmp map[string]string
+		err = json.Unmarshal(sbytes, &tmp)
+		if err != nil {
+			return err
+		}
+		w.Body, err = prebuildWidget("widget_custom_html", NameTextPair{tmp["Name"], template.HTML(SanitiseWidgetHTML(tmp["Text"]))})
 	default:
 		w.Body = wdata
 	}
+	if err != nil {
+		return err
+	}
+
+	// The bodies of some of the older widgets aren't JSON, so they can't have any rules
+	var data map[string]string
+	if json.Unmarshal(sbytes, &data) == nil {
+		w.Rules, err = parseWidgetRules(data, false)
+	}
 
 	// TODO: Test this
 	// TODO: Should we toss this through a proper parser rather than crudely replacing it?
@@ -168,7 +197,7 @@ func HasWidgets(dock string, h *Header) bool {
 		if !widget.Enabled {
 			continue
 		}
-		if widget.Allowed(h.Zone, h.ZoneID) {
+		if widget.Visible(h) {
 			wcount++
 		}
 	}
@@ -211,7 +240,7 @@ func BuildWidget(dock string, h *Header) (sbody string) {
 		if !widget.Enabled {
 			continue
 		}
-		if widget.Allowed(h.Zone, h.ZoneID) {
+		if widget.Visible(h) {
 			item, err := widget.Build(h)
 			if err != nil {
 				LogError(err)
@@ -266,7 +295,7 @@ func BuildWidget2(dock int, h *Header) (sbody string) {
 		if !w.Enabled {
 			continue
 		}
-		if w.Allowed(h.Zone, h.ZoneID) {
+		if w.Visible(h) {
 			item, err := w.Build(h)
 			if err != nil {
 				LogError(err)
@@ -313,7 +342,7 @@ func BuildWidget3(dock int, h *Header) {
 		if !w.Enabled {
 			continue
 		}
-		if w.Allowed(h.Zone, h.ZoneID) {
+		if w.Visible(h) {
 			item, err := w.Build(h)
 			if err != nil {
 				LogError(err)
@@ -355,7 +384,7 @@ func HasWidgets2(dock int, h *Header) bool {
 		if !widget.Enabled {
 			continue
 		}
-		if widget.Allowed(h.Zone, h.ZoneID) {
+		if widget.Visible(h) {
 			wcount++
 		}
 	}
@@ -387,7 +416,18 @@ func getDockWidgets(dock string) (widgets []*Widget, err error) {
 }
 
 // TODO: Make a store for this?
-func InitWidgets() (fi error) {
+func InitWidgets() error {
+	if err := ReloadWidgets(); err != nil {
+		return err
+	}
+	AddScheduledSecondTask(Docks.LeftSidebar.Scheduler.Tick)
+	AddScheduledSecondTask(Docks.RightSidebar.Scheduler.Tick)
+	AddScheduledSecondTask(Docks.Footer.Scheduler.Tick)
+	return nil
+}
+
+// ReloadWidgets loads the widgets in each of the docks from the database again
+func ReloadWidgets() (fi error) {
 	// TODO: Let themes set default values for widget docks, and let them lock in particular places with their stuff, e.g. leftOfNav and rightOfNav
 	f := func(name string) {
 		if fi != nil {
@@ -406,15 +446,7 @@ func InitWidgets() (fi error) {
 	f("leftSidebar")
 	f("rightSidebar")
 	f("footer")
-	if fi != nil {
-		return fi
-	}
-
-	AddScheduledSecondTask(Docks.LeftSidebar.Scheduler.Tick)
-	AddScheduledSecondTask(Docks.RightSidebar.Scheduler.Tick)
-	AddScheduledSecondTask(Docks.Footer.Scheduler.Tick)
-
-	return nil
+	return fi
 }
 
 func releaseWidgets(ws []*Widget) {
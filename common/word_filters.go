@@ -118,7 +118,7 @@ func (s *DefaultWordFilterStore) Create(find, replace string) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	return int(id64), s.ReloadAll()
+	return int(id64), s.changed()
 }
 
 // Delete removes a word filter from the database and refreshes the memory cache
@@ -127,7 +127,7 @@ func (s *DefaultWordFilterStore) Delete(id int) error {
 	if err != nil {
 		return err
 	}
-	return s.ReloadAll()
+	return s.changed()
 }
 
 func (s *DefaultWordFilterStore) Update(id int, find, replace string) error {
@@ -135,7 +135,17 @@ func (s *DefaultWordFilterStore) Update(id int, find, replace string) error {
 	if err != nil {
 		return err
 	}
-	return s.ReloadAll()
+	return s.changed()
+}
+
+// changed refreshes the memory cache after the filters have been changed and has the other servers in the cluster do the same
+func (s *DefaultWordFilterStore) changed() error {
+	err := s.ReloadAll()
+	if err != nil {
+		return err
+	}
+	ClusterPublish(ClusterWordFilters, 0)
+	return nil
 }
 
 // Length gets the number of word filters currently in memory, for the DefaultWordFilterStore, this should be all of them
@@ -153,7 +153,7 @@ func wsTopicListTick(h *WsHubImpl) error {
 	canSeeRenders := make(map[string][]byte)
 	canSeeLists := make(map[string][]*WsTopicsRow)
 	for name, canSee := range canSeeMap {
-		topicList, forumList, _, err := TopicList.GetListByCanSee(canSee, 1, 0, nil)
+		topicList, forumList, _, err := TopicList.GetListByCanSee(canSee, 1, 0, nil, TopicListFilter{})
 		if err != nil {
 			return err // TODO: Do we get ErrNoRows here?
 		}
@@ -229,6 +229,11 @@ func wsTopicListTick(h *WsHubImpl) error {
 					ccanMove = u.Perms.MoveTopic
 					ccanMod = t.CreatedBy == u.ID || u.Perms.DeleteTopic || ccanLock || ccanMove
 				}
+				if mp, ok := ForumMods.PermsFor(t.ParentID, u); ok {
+					ccanLock = ccanLock || mp.CloseTopic
+					ccanMove = ccanMove || mp.MoveTopic
+					ccanMod = ccanMod || mp.DeletePosts || ccanLock || ccanMove
+				}
 				if ccanLock {
 					anyLock = true
 				}
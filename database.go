@@ -55,6 +55,15 @@ func InitDatabase() (err error) {
 	if c.Config.TopicCache == "static" {
 		tcache = c.NewMemoryTopicCache(c.Config.TopicCacheCapacity)
 	}
+	// The other servers need to know when something is dropped from these, as they'll have their own copies
+	if c.Config.ServerCount > 1 {
+		if ucache != nil {
+			ucache = c.NewClusterUserCache(ucache)
+		}
+		if tcache != nil {
+			tcache = c.NewClusterTopicCache(tcache)
+		}
+	}
 
 	c.Users, err = c.NewDefaultUserStore(ucache)
 	if err != nil {
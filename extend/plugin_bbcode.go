@@ -5,6 +5,7 @@ import (
 	"math/rand"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	c "github.com/Azareal/Gosora/common"
@@ -59,12 +60,12 @@ func deactivateBbcode(pl *c.Plugin) {
 	pl.RemoveHook("topic_ogdesc_assign", BbcodeStripTags)
 }
 
+// BbcodeStripTags leaves the text and drops the tags, e.g. for the descriptions shown in link previews
 func BbcodeStripTags(msg string) string {
-	msg = bbcodeBold.ReplaceAllString(msg, "$1")
-	msg = bbcodeItalic.ReplaceAllString(msg, "$1")
-	msg = bbcodeUnderline.ReplaceAllString(msg, "$1")
-	msg = bbcodeStrike.ReplaceAllString(msg, "$1")
-	return msg
+	var sb strings.Builder
+	sb.Grow(len(msg))
+	bbcodeParseTree(msg).render(&sb, false, true)
+	return sb.String()
 }
 
 func BbcodeRegexParse(msg string) string {
@@ -222,201 +223,492 @@ func BbcodeParseWithoutCode(msg string) string {
 	return string(mbytes)
 }
 
-// Does every type of BBCode
-func BbcodeFullParse(msg string) string {
-	var hasU, hasB, hasI, hasS, hasC bool
-	var complexBbc bool
+// bbcodeMaxDepth is how deeply tags can be nested, anything past this is left as it was written
+const bbcodeMaxDepth = 32
+
+// bbcodeTag describes how one of the tags is turned into HTML
+type bbcodeTag struct {
+	raw       bool     // The content is taken as it is rather than being parsed for other tags, e.g. [code]
+	autoClose bool     // Closed for them if they forget to, rather than being shown as it was written
+	parents   []string // The tags this one has to be directly inside of, e.g. [*] has to be in a [list]
+	siblings  []string // Open tags which are closed by this one, e.g. a [*] closes the one before it
+	// open returns the opening HTML for the tag, ok is false if arg isn't valid, in which case the tag is shown as it was written
+	open  func(arg string) (html string, ok bool)
+	close string
+}
 
-	mbytes := []byte(msg)
-	mbytes = append(mbytes, c.SpaceGap...)
-	for i := 0; i < len(mbytes); i++ {
-		if mbytes[i] == '[' {
-			if mbytes[i+2] != ']' {
-				if mbytes[i+1] == '/' {
-					if mbytes[i+3] == ']' {
-						if !hasC {
-							switch mbytes[i+2] {
-							case 'b':
-								mbytes[i] = '<'
-								mbytes[i+3] = '>'
-								hasB = false
-							case 'i':
-								mbytes[i] = '<'
-								mbytes[i+3] = '>'
-								hasI = false
-							case 'u':
-								mbytes[i] = '<'
-								mbytes[i+3] = '>'
-								hasU = false
-							case 's':
-								mbytes[i] = '<'
-								mbytes[i+3] = '>'
-								hasS = false
-							}
-							i += 3
-						}
-					} else {
-						if mbytes[i+6] == ']' && mbytes[i+2] == 'c' && mbytes[i+3] == 'o' && mbytes[i+4] == 'd' && mbytes[i+5] == 'e' {
-							hasC = false
-							i += 7
-						}
-						complexBbc = true
-					}
-				} else {
-					// Put the biggest index first to avoid unnecessary bounds checks
-					if mbytes[i+5] == ']' && mbytes[i+1] == 'c' && mbytes[i+2] == 'o' && mbytes[i+3] == 'd' && mbytes[i+4] == 'e' {
-						hasC = true
-						i += 6
-					}
-					complexBbc = true
-				}
-			} else if !hasC {
-				ch := mbytes[i+1]
-				if ch == 'b' && !hasB {
-					mbytes[i] = '<'
-					mbytes[i+2] = '>'
-					hasB = true
-				} else if ch == 'i' && !hasI {
-					mbytes[i] = '<'
-					mbytes[i+2] = '>'
-					hasI = true
-				} else if ch == 'u' && !hasU {
-					mbytes[i] = '<'
-					mbytes[i+2] = '>'
-					hasU = true
-				} else if ch == 's' && !hasS {
-					mbytes[i] = '<'
-					mbytes[i+2] = '>'
-					hasS = true
-				}
-				i += 2
+func bbcodeLit(open, close string) *bbcodeTag {
+	return &bbcodeTag{open: func(arg string) (string, bool) {
+		return open, arg == ""
+	}, close: close}
+}
+
+func bbcodeInline(open, close string) *bbcodeTag {
+	t := bbcodeLit(open, close)
+	t.autoClose = true
+	return t
+}
+
+var bbcodeTags = bbcodeBuildTags()
+
+func bbcodeBuildTags() map[string]*bbcodeTag {
+	bbcodeSizes := []string{"", "63", "82", "100", "113", "150", "200", "300"}
+	return map[string]*bbcodeTag{
+		"b":       bbcodeInline("<b>", "</b>"),
+		"i":       bbcodeInline("<i>", "</i>"),
+		"u":       bbcodeInline("<u>", "</u>"),
+		"s":       bbcodeInline("<s>", "</s>"),
+		"h1":      bbcodeLit("<h2>", "</h2>"),
+		"spoiler": bbcodeLit("<spoiler>", "</spoiler>"),
+		"quote": {open: func(arg string) (string, bool) {
+			if arg == "" {
+				return "<blockquote>", true
+			}
+			// [quote=name;postid], the post is optional
+			name, pid := arg, ""
+			if semi := strings.LastIndexByte(arg, ';'); semi != -1 && bbcodeDigits(arg[semi+1:]) {
+				name, pid = arg[:semi], arg[semi+1:]
+			}
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return "", false
+			}
+			if pid == "" {
+				return "<blockquote><span class='quote_by'>" + name + "</span>", true
 			}
+			return "<blockquote><a class='quote_by'href='#post-" + pid + "'>" + name + "</a>", true
+		}, close: "</blockquote>"},
+		"list": {open: func(arg string) (string, bool) {
+			switch arg {
+			case "":
+				return "<ul>", true
+			case "1":
+				return "<ol>", true
+			case "a", "A", "i", "I":
+				return "<ol type='" + arg + "'>", true
+			}
+			return "", false
+		}, close: "</ul>"},
+		"*":     {autoClose: true, parents: []string{"list"}, siblings: []string{"*"}, open: bbcodeLit("<li>", "").open, close: "</li>"},
+		"table": bbcodeLit("<table class='bbcode_table'>", "</table>"),
+		"tr":    {autoClose: true, parents: []string{"table"}, siblings: []string{"td", "th", "tr"}, open: bbcodeLit("<tr>", "").open, close: "</tr>"},
+		"td":    {autoClose: true, parents: []string{"tr"}, siblings: []string{"td", "th"}, open: bbcodeLit("<td>", "").open, close: "</td>"},
+		"th":    {autoClose: true, parents: []string{"tr"}, siblings: []string{"td", "th"}, open: bbcodeLit("<th>", "").open, close: "</th>"},
+		"size": {autoClose: true, open: func(arg string) (string, bool) {
+			// The same scale as the old font tag, from 1 to 7 with 3 being the normal size
+			if len(arg) != 1 || arg[0] < '1' || arg[0] > '7' {
+				return "", false
+			}
+			return "<span style='font-size:" + bbcodeSizes[arg[0]-'0'] + "%'>", true
+		}, close: "</span>"},
+		"color": {autoClose: true, open: func(arg string) (string, bool) {
+			if !bbcodeValidColour(arg) {
+				return "", false
+			}
+			return "<span style='color:" + arg + "'>", true
+		}, close: "</span>"},
+		// [url] without a label is handled as a raw tag, see bbcodeNode.render
+		"url": {open: func(arg string) (string, bool) {
+			if !bbcodeValidURL(arg, false) {
+				return "", false
+			}
+			return string(c.URLOpenUser) + arg + string(c.URLOpen2), true
+		}, close: string(c.URLClose)},
+		"code": {raw: true},
+		"img":  {raw: true},
+		"rand": {raw: true},
+	}
+}
+
+// bbcodeNode is either a piece of text or a tag with the nodes inside of it
+type bbcodeNode struct {
+	name     string // Empty for text
+	arg      string
+	src      string // The opening tag as it was written, so it can be put back if it turns out to be invalid
+	closeSrc string
+	text     string // The text or the content of a raw tag
+	closed   bool
+	children []*bbcodeNode
+}
+
+func (n *bbcodeNode) addText(s string) {
+	if s == "" {
+		return
+	}
+	if l := len(n.children); l > 0 && n.children[l-1].name == "" {
+		n.children[l-1].text += s
+		return
+	}
+	n.children = append(n.children, &bbcodeNode{text: s})
+}
+
+func bbcodeIsAlnum(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+}
+
+func bbcodeDigits(s string) bool {
+	if s == "" || len(s) > 12 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
 		}
 	}
+	return true
+}
 
-	// There's an unclosed tag in there somewhere x.x
-	if hasI || hasU || hasB || hasS {
-		closeUnder := []byte("</u>")
-		closeItalic := []byte("</i>")
-		closeBold := []byte("</b>")
-		closeStrike := []byte("</s>")
-		if hasI {
-			mbytes = append(bytes.TrimSpace(mbytes), closeItalic...)
+// bbcodeLower is a byte-wise strings.ToLower, tag names are always ASCII and this keeps the offsets into the message intact
+func bbcodeLower(ch byte) byte {
+	if ch >= 'A' && ch <= 'Z' {
+		return ch + 32
+	}
+	return ch
+}
+
+// bbcodeReadTag reads the tag starting at the [ at i, end is -1 if it isn't a tag
+func bbcodeReadTag(msg string, i int) (name, arg string, closing bool, end int) {
+	j := i + 1
+	if j < len(msg) && msg[j] == '/' {
+		closing = true
+		j++
+	}
+	start := j
+	if j < len(msg) && msg[j] == '*' {
+		j++
+	} else {
+		for j < len(msg) && j-start < 8 && bbcodeIsAlnum(msg[j]) {
+			j++
 		}
-		if hasU {
-			mbytes = append(bytes.TrimSpace(mbytes), closeUnder...)
+	}
+	if j == start || j >= len(msg) {
+		return "", "", false, -1
+	}
+	nb := make([]byte, j-start)
+	for k := range nb {
+		nb[k] = bbcodeLower(msg[start+k])
+	}
+	name = string(nb)
+	if msg[j] == '=' && !closing {
+		j++
+		astart := j
+		for j < len(msg) && msg[j] != ']' && msg[j] != '[' && msg[j] != '\n' && j-astart < 256 {
+			j++
 		}
-		if hasB {
-			mbytes = append(bytes.TrimSpace(mbytes), closeBold...)
+		if j >= len(msg) {
+			return "", "", false, -1
 		}
-		if hasS {
-			mbytes = append(bytes.TrimSpace(mbytes), closeStrike...)
+		arg = msg[astart:j]
+	}
+	if msg[j] != ']' {
+		return "", "", false, -1
+	}
+	return name, arg, closing, j + 1
+}
+
+// bbcodeFindClose finds the [/name] closing the raw tag whose content starts at i, it returns -1 if there isn't one
+func bbcodeFindClose(msg string, i int, name string) int {
+	for ; i+3+len(name) <= len(msg); i++ {
+		if msg[i] != '[' || msg[i+1] != '/' || msg[i+2+len(name)] != ']' {
+			continue
+		}
+		match := true
+		for k := 0; k < len(name); k++ {
+			if bbcodeLower(msg[i+2+k]) != name[k] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
 		}
-		mbytes = append(mbytes, c.SpaceGap...)
 	}
+	return -1
+}
 
-	if complexBbc {
-		i := 0
-		var start, lastTag int
-		var outbytes []byte
-		for ; i < len(mbytes); i++ {
-			if mbytes[i] == '[' {
-				if mbytes[i+1] == 'u' {
-					if mbytes[i+4] == ']' && mbytes[i+2] == 'r' && mbytes[i+3] == 'l' {
-						i, start, lastTag, outbytes = bbcodeParseURL(i, start, lastTag, mbytes, outbytes)
-						continue
-					}
-				} else if mbytes[i+1] == 'r' {
-					if bytes.Equal(mbytes[i+2:i+6], []byte("and]")) {
-						i, start, lastTag, outbytes = bbcodeParseRand(i, start, lastTag, mbytes, outbytes)
-					}
+func bbcodeInList(name string, list []string) bool {
+	for _, item := range list {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
+
+// bbcodeParseTree turns msg into a tree of nodes, tags which don't match up are left as text or marked as unclosed
+func bbcodeParseTree(msg string) *bbcodeNode {
+	root := &bbcodeNode{name: "root", closed: true}
+	stack := []*bbcodeNode{root}
+	last := 0
+	for i := 0; i < len(msg); {
+		if msg[i] != '[' {
+			i++
+			continue
+		}
+		name, arg, closing, end := bbcodeReadTag(msg, i)
+		tag, ok := bbcodeTags[name]
+		if end == -1 || !ok {
+			i++
+			continue
+		}
+		top := stack[len(stack)-1]
+
+		if closing {
+			// Find the tag this closes, anything left open inside of it is closed for them if it can be, otherwise it's shown as it was written
+			j := len(stack) - 1
+			for ; j > 0; j-- {
+				if stack[j].name == name {
+					break
 				}
 			}
-		}
-		if lastTag != i {
-			outbytes = append(outbytes, mbytes[lastTag:]...)
+			if j == 0 {
+				i = end
+				continue
+			}
+			top.addText(msg[last:i])
+			stack[j].closed = true
+			stack[j].closeSrc = msg[i:end]
+			stack = stack[:j]
+			i, last = end, end
+			continue
 		}
 
-		if len(outbytes) != 0 {
-			msg = string(outbytes[0 : len(outbytes)-10])
-		} else {
-			msg = string(mbytes[0 : len(mbytes)-10])
+		if tag.raw || (name == "url" && arg == "") {
+			cend := bbcodeFindClose(msg, end, name)
+			if cend == -1 {
+				if name == "rand" {
+					top.addText(msg[last:i])
+					top.children = append(top.children, &bbcodeNode{name: name, src: msg[i:end]})
+					last = end
+				}
+				i = end
+				continue
+			}
+			top.addText(msg[last:i])
+			closeEnd := cend + len(name) + 3
+			top.children = append(top.children, &bbcodeNode{name: name, arg: arg, src: msg[i:end], closeSrc: msg[cend:closeEnd], text: msg[end:cend], closed: true})
+			i, last = closeEnd, closeEnd
+			continue
 		}
 
-		// TODO: Optimise these
-		//msg = bbcode_url.ReplaceAllString(msg,"<a href=\"$1$2//$3\" rel=\"ugc\">$1$2//$3</i>")
-		msg = bbcodeURLLabel.ReplaceAllString(msg, "<a href='$1$2//$3' rel='ugc'>$4</i>")
-		msg = bbcodeQuotes.ReplaceAllString(msg, "<blockquote>$1</blockquote>")
-		msg = bbcodeCode.ReplaceAllString(msg, "<span class='codequotes'>$1</span>")
-		msg = bbcodeSpoiler.ReplaceAllString(msg, "<spoiler>$1</spoiler>")
-		msg = bbcodeH1.ReplaceAllString(msg, "<h2>$1</h2>")
-	} else {
-		msg = string(mbytes[0 : len(mbytes)-10])
+		// Links inside links aren't valid HTML
+		nested := false
+		if name == "url" {
+			for _, n := range stack {
+				if n.name == "url" {
+					nested = true
+				}
+			}
+		}
+		if nested || len(stack) > bbcodeMaxDepth {
+			i = end
+			continue
+		}
+		top.addText(msg[last:i])
+		for len(stack) > 1 && bbcodeInList(top.name, tag.siblings) {
+			top.closed = true
+			stack = stack[:len(stack)-1]
+			top = stack[len(stack)-1]
+		}
+		if len(tag.parents) > 0 && !bbcodeInList(top.name, tag.parents) {
+			// It's out of place, so it's just text
+			top.addText(msg[i:end])
+			i, last = end, end
+			continue
+		}
+		n := &bbcodeNode{name: name, arg: arg, src: msg[i:end]}
+		top.children = append(top.children, n)
+		stack = append(stack, n)
+		i, last = end, end
 	}
+	stack[len(stack)-1].addText(msg[last:])
+	return root
+}
 
-	return msg
+// bbcodeBlank tells you whether s is nothing but whitespace and line breaks, which are dropped between the rows and items of tables and lists
+func bbcodeBlank(s string) bool {
+	s = strings.Replace(s, "<br>", "", -1)
+	return strings.TrimSpace(s) == ""
 }
 
-// TODO: Strip the containing [url] so the media parser can work it's magic instead? Or do we want to allow something like [url=]label[/url] here?
-func bbcodeParseURL(i int, start int, lastTag int, mbytes []byte, outbytes []byte) (int, int, int, []byte) {
-	start = i + 5
-	outbytes = append(outbytes, mbytes[lastTag:i]...)
-	i = start
-	i += c.PartialURLStringLen2(string(mbytes[start:]))
-	if !bytes.Equal(mbytes[i:i+6], []byte("[/url]")) {
-		outbytes = append(outbytes, c.InvalidURL...)
-		return i, start, lastTag, outbytes
-	}
-
-	outbytes = append(outbytes, c.URLOpen...)
-	outbytes = append(outbytes, mbytes[start:i]...)
-	outbytes = append(outbytes, c.URLOpen2...)
-	outbytes = append(outbytes, mbytes[start:i]...)
-	outbytes = append(outbytes, c.URLClose...)
-	i += 6
-	lastTag = i
-
-	return i, start, lastTag, outbytes
+// bbcodeValidURL makes sure u is a link which can't break out of the attribute or run scripts, images are limited to the web
+func bbcodeValidURL(u string, img bool) bool {
+	switch {
+	case strings.HasPrefix(u, "https://"), strings.HasPrefix(u, "http://"), strings.HasPrefix(u, "//"):
+	case strings.HasPrefix(u, "/"):
+	case !img && (strings.HasPrefix(u, "ftp://") || strings.HasPrefix(u, "mailto:")):
+	default:
+		return false
+	}
+	for i := 0; i < len(u); i++ {
+		switch ch := u[i]; {
+		case ch <= ' ', ch == 127, ch == '\'', ch == '"', ch == '<', ch == '>', ch == '`', ch == '\\':
+			return false
+		}
+	}
+	return true
 }
 
-func bbcodeParseRand(i int, start int, lastTag int, msgbytes []byte, outbytes []byte) (int, int, int, []byte) {
-	outbytes = append(outbytes, msgbytes[lastTag:i]...)
-	start = i + 6
-	i = start
-	for ; ; i++ {
-		if msgbytes[i] == '[' {
-			if !bytes.Equal(msgbytes[i+1:i+7], []byte("/rand]")) {
-				outbytes = append(outbytes, bbcodeMissingTag...)
-				return i, start, lastTag, outbytes
+// bbcodeValidColour accepts the named colours and hex ones like #fff and #ff0000
+func bbcodeValidColour(s string) bool {
+	if s == "" || len(s) > 20 {
+		return false
+	}
+	if s[0] == '#' {
+		if len(s) != 4 && len(s) != 7 {
+			return false
+		}
+		for i := 1; i < len(s); i++ {
+			ch := s[i]
+			if !(ch >= '0' && ch <= '9') && !(ch >= 'a' && ch <= 'f') && !(ch >= 'A' && ch <= 'F') {
+				return false
 			}
-			break
-		} else if (len(msgbytes) - 1) < (i + 10) {
-			outbytes = append(outbytes, bbcodeMissingTag...)
-			return i, start, lastTag, outbytes
+		}
+		return true
+	}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if !(ch >= 'a' && ch <= 'z') && !(ch >= 'A' && ch <= 'Z') {
+			return false
 		}
 	}
+	return true
+}
 
-	number, err := strconv.ParseInt(string(msgbytes[start:i]), 10, 64)
-	if err != nil {
-		outbytes = append(outbytes, bbcodeInvalidNumber...)
-		return i, start, lastTag, outbytes
+// bbcodeValidLang accepts things like go, c++, c# and objective-c as the language of a code block
+func bbcodeValidLang(s string) bool {
+	if s == "" || len(s) > 20 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if !bbcodeIsAlnum(ch) && ch != '+' && ch != '#' && ch != '-' && ch != '.' && ch != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *bbcodeNode) renderLiteral(sb *strings.Builder, strip bool) {
+	sb.WriteString(n.src)
+	n.renderChildren(sb, true, strip)
+	sb.WriteString(n.closeSrc)
+}
+
+func (n *bbcodeNode) renderChildren(sb *strings.Builder, literal, strip bool) {
+	structural := !literal && !strip && (n.name == "list" || n.name == "table" || n.name == "tr")
+	for _, child := range n.children {
+		if structural && child.name == "" && bbcodeBlank(child.text) {
+			continue
+		}
+		child.render(sb, literal, strip)
 	}
+}
 
+// render writes the node out as HTML, literal is set when a parent was shown as it was written, so the tags which only work inside of it are too.
+// strip leaves out the tags and only writes the text.
+func (n *bbcodeNode) render(sb *strings.Builder, literal, strip bool) {
+	if n.name == "" {
+		sb.WriteString(n.text)
+		return
+	} else if n.name == "root" {
+		n.renderChildren(sb, literal, strip)
+		return
+	}
+	tag := bbcodeTags[n.name]
+	switch {
+	case n.name == "code":
+		if strip {
+			sb.WriteString(n.text)
+			return
+		}
+		sb.WriteString("<span class='codequotes'")
+		if bbcodeValidLang(n.arg) {
+			sb.WriteString("data-lang='" + n.arg + "'")
+		}
+		sb.WriteString(">" + n.text + "</span>")
+		return
+	case n.name == "url" && n.arg == "":
+		if strip {
+			sb.WriteString(n.text)
+			return
+		}
+		if n.text != "" && !bbcodeValidURL(n.text, false) {
+			sb.Write(c.InvalidURL)
+			return
+		}
+		sb.Write(c.URLOpen)
+		sb.WriteString(n.text)
+		sb.Write(c.URLOpen2)
+		sb.WriteString(n.text)
+		sb.Write(c.URLClose)
+		return
+	case n.name == "img":
+		if strip {
+			return
+		}
+		if !bbcodeValidURL(n.text, true) {
+			sb.Write(c.InvalidURL)
+			return
+		}
+		sb.WriteString("<img src='" + n.text + "'class='bbcode_img'>")
+		return
+	case n.name == "rand":
+		if strip {
+			return
+		}
+		if !n.closed {
+			sb.Write(bbcodeMissingTag)
+			sb.WriteString(n.src)
+			return
+		}
+		sb.Write(bbcodeRand(n))
+		return
+	}
+
+	structural := len(tag.parents) > 0
+	if (!n.closed && !tag.autoClose) || (structural && literal) {
+		n.renderLiteral(sb, strip)
+		return
+	}
+	if strip {
+		n.renderChildren(sb, false, strip)
+		return
+	}
+	open, ok := tag.open(n.arg)
+	if !ok {
+		n.renderLiteral(sb, strip)
+		return
+	}
+	sb.WriteString(open)
+	n.renderChildren(sb, false, strip)
+	if n.name == "list" && n.arg != "" {
+		sb.WriteString("</ol>")
+		return
+	}
+	sb.WriteString(tag.close)
+}
+
+func bbcodeRand(n *bbcodeNode) []byte {
+	number, err := strconv.ParseInt(n.text, 10, 64)
+	if err != nil {
+		return []byte(string(bbcodeInvalidNumber) + n.src + n.text + n.closeSrc)
+	}
 	// TODO: Add support for negative numbers?
 	if number < 0 {
-		outbytes = append(outbytes, bbcodeNoNegative...)
-		return i, start, lastTag, outbytes
+		return []byte(string(bbcodeNoNegative) + n.src + n.text + n.closeSrc)
 	}
-
-	var dat []byte
 	if number == 0 {
-		dat = []byte("0")
-	} else {
-		dat = []byte(strconv.FormatInt((bbcodeRandom.Int63n(number)), 10))
+		return []byte("0")
 	}
+	return []byte(strconv.FormatInt(bbcodeRandom.Int63n(number), 10))
+}
 
-	outbytes = append(outbytes, dat...)
-	i += 7
-	lastTag = i
-	return i, start, lastTag, outbytes
+// BbcodeFullParse does every type of BBCode, tags can be nested inside each other and anything which doesn't add up is left as it was written.
+// The message has been escaped by the time it gets here, so only the tags and attributes this adds need to be made safe.
+func BbcodeFullParse(msg string) string {
+	var sb strings.Builder
+	sb.Grow(len(msg))
+	bbcodeParseTree(msg).render(&sb, false, false)
+	return sb.String()
 }
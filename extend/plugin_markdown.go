@@ -1,398 +1,527 @@
 package extend
 
 import (
+	"html"
+	"strconv"
 	"strings"
 
 	c "github.com/Azareal/Gosora/common"
 )
 
-var markdownMaxDepth = 25 // How deep the parser will go when parsing Markdown strings
-var markdownUnclosedElement []byte
-
-var markdownBoldTagOpen []byte
-var markdownBoldTagClose []byte
-var markdownItalicTagOpen []byte
-var markdownItalicTagClose []byte
-var markdownUnderlineTagOpen []byte
-var markdownUnderlineTagClose []byte
-var markdownStrikeTagOpen []byte
-var markdownStrikeTagClose []byte
-var markdownQuoteTagOpen []byte
-var markdownQuoteTagClose []byte
-var markdownSpoilerTagOpen []byte
-var markdownSpoilerTagClose []byte
-var markdownH1TagOpen []byte
-var markdownH1TagClose []byte
+// Markdown support, following CommonMark along with the GitHub flavoured tables, task lists and strikethrough.
+// The parser is in plugin_markdown_block.go and plugin_markdown_inline.go, this file has the plugin and the HTML renderer.
 
 func init() {
 	c.Plugins.Add(&c.Plugin{UName: "markdown", Name: "Markdown", Author: "Azareal", URL: "https://github.com/Azareal", Init: InitMarkdown, Deactivate: deactivateMarkdown})
 }
 
 func InitMarkdown(pl *c.Plugin) error {
-	markdownUnclosedElement = []byte("<red>[Unclosed Element]</red>")
-
-	markdownBoldTagOpen = []byte("<b>")
-	markdownBoldTagClose = []byte("</b>")
-	markdownItalicTagOpen = []byte("<i>")
-	markdownItalicTagClose = []byte("</i>")
-	markdownUnderlineTagOpen = []byte("<u>")
-	markdownUnderlineTagClose = []byte("</u>")
-	markdownStrikeTagOpen = []byte("<s>")
-	markdownStrikeTagClose = []byte("</s>")
-	markdownQuoteTagOpen = []byte("<blockquote>")
-	markdownQuoteTagClose = []byte("</blockquote>")
-	markdownSpoilerTagOpen = []byte("<spoiler>")
-	markdownSpoilerTagClose = []byte("</spoiler>")
-	markdownH1TagOpen = []byte("<h2>")
-	markdownH1TagClose = []byte("</h2>")
-
 	pl.AddHook("parse_assign", MarkdownParse)
+	c.PreparseKeepBackslashes = true
 	return nil
 }
 
 func deactivateMarkdown(pl *c.Plugin) {
 	pl.RemoveHook("parse_assign", MarkdownParse)
+	c.PreparseKeepBackslashes = false
 }
 
-// An adapter for the parser, so that the parser can call itself recursively.
-// This is less for the simple Markdown elements like bold and italics and more for the really complicated ones I plan on adding at some point.
-func MarkdownParse(msg string) string {
-	msg = _markdownParse(msg+" ", 0)
-	if msg[len(msg)-1] == ' ' {
-		msg = msg[:len(msg)-1]
-	}
-	return msg
-}
-
-// Under Construction!
-func _markdownParse(msg string, n int) string {
-	if n > markdownMaxDepth {
-		return "<red>[Markdown Error: Overflowed the max depth of 20]</red>"
-	}
+// MarkdownOptions changes how MarkdownRender turns Markdown into HTML, the zero value follows the spec, apart from raw HTML being escaped
+type MarkdownOptions struct {
+	HTML          bool // Lets raw HTML through, this should never be turned on for anything a user wrote
+	Breaks        bool // Turns the line breaks inside paragraphs into <br>s
+	UGC           bool // Marks links with rel="ugc"
+	Autolink      bool // Turns URLs, www. addresses and email addresses into links, like on GitHub
+	HeadingOffset int  // Pushes headings down, e.g. so the ones in posts aren't as big as the page title
 
-	var outbytes []byte
-	var lastElement int
-	breaking := false
-	//c.DebugLogf("Initial Msg: %+v\n", strings.Replace(msg, "\r", "\\r", -1))
-
-	for index := 0; index < len(msg); index++ {
-		simpleMatch := func(char byte, o []byte, c []byte) {
-			startIndex := index
-			if (index + 1) >= len(msg) {
-				breaking = true
-				return
-			}
+	frags []string // The HTML which was already in the message, see MarkdownParse
+}
 
-			index++
-			index = markdownSkipUntilChar(msg, index, char)
-			if (index-(startIndex+1)) < 1 || index >= len(msg) {
-				breaking = true
-				return
-			}
+// The HTML which was already in a message is swapped out for one of these with it's index in between, noncharacters can't come out of an entity, so they can't be forged
+const (
+	markdownFragOpen  = "\uFDD0"
+	markdownFragClose = "\uFDD1"
+)
 
-			sIndex := startIndex + 1
-			lIndex := index
-			index++
+// plain swaps the placeholders in s for the text in the HTML they stand in for, this is for places which can't have HTML in them, like attributes
+func (o *MarkdownOptions) plain(s string) string {
+	if len(o.frags) == 0 || !strings.Contains(s, markdownFragOpen) {
+		return s
+	}
+	return markdownRestore(s, o.frags, func(frag string) string {
+		return html.UnescapeString(markdownStripTags(frag))
+	})
+}
 
-			outbytes = append(outbytes, msg[lastElement:startIndex]...)
-			outbytes = append(outbytes, o...)
-			// TODO: Implement this without as many type conversions
-			outbytes = append(outbytes, []byte(_markdownParse(msg[sIndex:lIndex], n+1))...)
-			outbytes = append(outbytes, c...)
+// MarkdownRender turns the Markdown in src into HTML
+func MarkdownRender(src string, o *MarkdownOptions) string {
+	if o == nil {
+		o = &MarkdownOptions{}
+	}
+	r := &mdRenderer{o: o}
+	r.block(mdParse(src, o), false)
+	return string(r.buf)
+}
 
-			lastElement = index
-			index--
+// MarkdownParse renders the Markdown in a message which has been through the rest of the parser, as that's where the parse_assign hook is.
+// By then, the message has been escaped, it's line breaks have become <br>s and it's links, mentions and hash links have become HTML, so that HTML is swapped out for placeholders while the Markdown is parsed and swapped back in afterwards.
+func MarkdownParse(msg string) string {
+	src, frags := markdownExtract(msg)
+	out := MarkdownRender(src, &MarkdownOptions{Breaks: true, UGC: true, HeadingOffset: 1, frags: frags})
+	for i, frag := range frags {
+		name, closing := markdownTagName(frag)
+		ph := markdownFragOpen + strconv.Itoa(i) + markdownFragClose
+		switch {
+		case markdownBlockTags[name] == 1:
+			out = strings.Replace(out, "<p>"+ph+"</p>", ph, 1)
+		case markdownBlockTags[name] == 2 && closing:
+			out = strings.Replace(out, ph+"</p>", ph, 1)
+		case markdownBlockTags[name] == 2:
+			out = strings.Replace(out, "<p>"+ph, ph, 1)
 		}
+	}
+	return markdownRestore(strings.TrimSuffix(out, "\n"), frags, nil)
+}
 
-		startLine := func() {
-			startIndex := index
-			if (index + 1) >= len(msg) /*|| (index + 2) >= len(msg)*/ {
-				breaking = true
-				return
-			}
-			index++
+var markdownUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&", "&#39;", "'", "&#34;", "\"", "&quot;", "\"")
 
-			index = markdownSkipUntilNotChar(msg, index, 32)
-			if (index + 1) >= len(msg) {
-				breaking = true
-				return
-			}
-			//index++
+// The tags PreparseMessage lets through, these wrap text which is still Markdown, so they're swapped out on their own rather than along with everything up to the closing tag
+var markdownFormatTags = map[string]bool{"strong": true, "em": true, "del": true, "u": true, "b": true, "i": true, "s": true, "spoiler": true, "blockquote": true, "h2": true, "h3": true, "h4": true, "span": true}
 
-			index = markdownSkipUntilStrongSpace(msg, index)
-			sIndex := startIndex + 1
-			lIndex := index
-			index++
+// The format tags which are blocks rather than inline, 1 if they can have paragraphs in them and 2 if they can't
+var markdownBlockTags = map[string]int{"blockquote": 1, "h2": 2, "h3": 2, "h4": 2}
 
-			outbytes = append(outbytes, msg[lastElement:startIndex]...)
-			outbytes = append(outbytes, markdownH1TagOpen...)
-			// TODO: Implement this without as many type conversions
-			//fmt.Println("msg[sIndex:lIndex]:", string(msg[sIndex:lIndex]))
-			// TODO: Quick hack to eliminate trailing spaces...
-			outbytes = append(outbytes, []byte(strings.TrimSpace(_markdownParse(msg[sIndex:lIndex], n+1)))...)
-			outbytes = append(outbytes, markdownH1TagClose...)
+// markdownExtract turns a message back into the Markdown it came from, the HTML in it is swapped out for placeholders
+func markdownExtract(msg string) (string, []string) {
+	var sb strings.Builder
+	var frags []string
+	text := func(s string) {
+		s = strings.Replace(s, markdownFragOpen, "\uFFFD", -1)
+		s = strings.Replace(s, markdownFragClose, "\uFFFD", -1)
+		sb.WriteString(markdownUnescaper.Replace(s))
+	}
 
-			lastElement = index
-			index--
+	for i := 0; i < len(msg); {
+		lt := strings.IndexByte(msg[i:], '<')
+		if lt == -1 {
+			text(msg[i:])
+			break
 		}
-
-		uniqueWord := func(i int) bool {
-			if i == 0 {
-				return true
-			}
-			return msg[i-1] <= 32
+		text(msg[i : i+lt])
+		i += lt
+
+		name, closing := markdownTagName(msg[i:])
+		gt := strings.IndexByte(msg[i:], '>')
+		if name == "" || gt == -1 {
+			text("<")
+			i++
+			continue
 		}
-
-		switch msg[index] {
-		// TODO: Do something slightly less hacky for skipping URLs
-		case '/':
-			if len(msg) > (index+2) && msg[index+1] == '/' {
-				for ; index < len(msg) && msg[index] != ' '; index++ {
-				}
-				index--
-				continue
-			}
-		case '_':
-			if !uniqueWord(index) {
-				break
-			}
-			simpleMatch('_', markdownUnderlineTagOpen, markdownUnderlineTagClose)
-			if breaking {
-				break
-			}
-		case '~':
-			simpleMatch('~', markdownStrikeTagOpen, markdownStrikeTagClose)
-			if breaking {
-				break
-			}
-		case '*':
-			startIndex := index
-			italic := true
-			bold := false
-			if (index + 2) < len(msg) {
-				if msg[index+1] == '*' {
-					bold = true
-					index++
-					if msg[index+1] != '*' {
-						italic = false
-					} else {
-						index++
-					}
-				}
-			}
-
-			// Does the string terminate abruptly?
-			if (index + 1) >= len(msg) {
-				break
-			}
-			index++
-
-			index = markdownSkipUntilAsterisk(msg, index)
-			if index >= len(msg) {
-				break
-			}
-
-			preBreak := func() {
-				outbytes = append(outbytes, msg[lastElement:startIndex]...)
-				lastElement = startIndex
-			}
-
-			sIndex := startIndex
-			lIndex := index
-			if bold && italic {
-				if (index + 3) >= len(msg) {
-					preBreak()
-					break
-				}
-				index += 3
-				sIndex += 3
-			} else if bold {
-				if (index + 2) >= len(msg) {
-					preBreak()
-					break
-				}
-				index += 2
-				sIndex += 2
+		if name == "br" {
+			sb.WriteByte('\n')
+			i += gt + 1
+			continue
+		}
+		end := i + gt + 1
+		if !closing && !markdownFormatTags[name] {
+			// Links and media aren't Markdown on the inside, so they're kept whole
+			end = markdownElementEnd(msg, end, name)
+		}
+		ph := markdownFragOpen + strconv.Itoa(len(frags)) + markdownFragClose
+		// These can't go inside of paragraphs, so they're split off into their own, which MarkdownParse unwraps
+		switch markdownBlockTags[name] {
+		case 1:
+			ph = "\n\n" + ph + "\n\n"
+		case 2:
+			if closing {
+				ph += "\n\n"
 			} else {
-				if (index + 1) >= len(msg) {
-					preBreak()
-					break
-				}
-				index++
-				sIndex++
-			}
-
-			if lIndex <= sIndex {
-				preBreak()
-				break
-			}
-			if sIndex < 0 || lIndex < 0 {
-				preBreak()
-				break
-			}
-			outbytes = append(outbytes, msg[lastElement:startIndex]...)
-
-			if bold {
-				outbytes = append(outbytes, markdownBoldTagOpen...)
-			}
-			if italic {
-				outbytes = append(outbytes, markdownItalicTagOpen...)
+				ph = "\n\n" + ph
 			}
+		}
+		sb.WriteString(ph)
+		frags = append(frags, msg[i:end])
+		i = end
+	}
+	return sb.String(), frags
+}
 
-			// TODO: Implement this without as many type conversions
-			outbytes = append(outbytes, []byte(_markdownParse(msg[sIndex:lIndex], n+1))...)
+// markdownTagName returns the name of the tag at the start of s, if there is one
+func markdownTagName(s string) (name string, closing bool) {
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		closing = true
+		i++
+	}
+	start := i
+	for i < len(s) && ((s[i] >= 'a' && s[i] <= 'z') || (s[i] >= 'A' && s[i] <= 'Z') || (i > start && s[i] >= '0' && s[i] <= '9')) {
+		i++
+	}
+	return strings.ToLower(s[start:i]), closing
+}
 
-			if italic {
-				outbytes = append(outbytes, markdownItalicTagClose...)
-			}
-			if bold {
-				outbytes = append(outbytes, markdownBoldTagClose...)
-			}
+// markdownElementEnd finds the end of the closing tag for the element called name, which has an opening tag ending at openEnd, if it's never closed, it's just the opening tag
+func markdownElementEnd(msg string, openEnd int, name string) int {
+	depth := 1
+	for i := openEnd; i < len(msg); {
+		lt := strings.IndexByte(msg[i:], '<')
+		if lt == -1 {
+			break
+		}
+		i += lt
+		tname, closing := markdownTagName(msg[i:])
+		gt := strings.IndexByte(msg[i:], '>')
+		if gt == -1 {
+			break
+		}
+		i += gt + 1
+		if tname != name {
+			continue
+		}
+		if !closing {
+			depth++
+		} else if depth--; depth == 0 {
+			return i
+		}
+	}
+	return openEnd
+}
 
-			lastElement = index
-			index--
-		case '\\':
-			if (index + 1) < len(msg) {
-				if isMarkdownStartChar(msg[index+1]) && msg[index+1] != '\\' {
-					outbytes = append(outbytes, msg[lastElement:index]...)
-					index++
-					lastElement = index
-				}
-			}
-		// TODO: Add a inline quote variant
-		case '`':
-			simpleMatch('`', markdownQuoteTagOpen, markdownQuoteTagClose)
-			if breaking {
-				break
-			}
-		// TODO: Might need to be double pipe
-		case '|':
-			simpleMatch('|', markdownSpoilerTagOpen, markdownSpoilerTagClose)
-			if breaking {
-				break
-			}
-		case 10: // newline
-			if (index + 1) >= len(msg) {
-				break
-			}
-			index++
+// markdownRestore swaps the placeholders in s back for the HTML they stand in for, or for what f turns it into
+func markdownRestore(s string, frags []string, f func(string) string) string {
+	if len(frags) == 0 || !strings.Contains(s, markdownFragOpen) {
+		return s
+	}
+	var sb strings.Builder
+	for {
+		start := strings.Index(s, markdownFragOpen)
+		if start == -1 {
+			break
+		}
+		sb.WriteString(s[:start])
+		s = s[start+len(markdownFragOpen):]
+		end := strings.Index(s, markdownFragClose)
+		if end == -1 {
+			sb.WriteString(markdownFragOpen)
+			continue
+		}
+		id, err := strconv.Atoi(s[:end])
+		if err != nil || id < 0 || id >= len(frags) {
+			sb.WriteString(markdownFragOpen)
+			continue
+		}
+		if f != nil {
+			sb.WriteString(f(frags[id]))
+		} else {
+			sb.WriteString(frags[id])
+		}
+		s = s[end+len(markdownFragClose):]
+	}
+	sb.WriteString(s)
+	return sb.String()
+}
 
-			if msg[index] != '#' {
-				continue
-			}
-			startLine()
-			if breaking {
-				break
-			}
-		case '#':
-			if index != 0 {
-				continue
-			}
-			startLine()
-			if breaking {
-				break
-			}
+func markdownStripTags(s string) string {
+	var sb strings.Builder
+	inTag := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '<':
+			inTag = true
+		case s[i] == '>' && inTag:
+			inTag = false
+		case !inTag:
+			sb.WriteByte(s[i])
 		}
 	}
+	return sb.String()
+}
 
-	if len(outbytes) == 0 {
-		return msg
-	} else if lastElement < (len(msg) - 1) {
-		msg = string(outbytes) + msg[lastElement:]
-		return msg
+// mdRenderer turns a tree from mdParse into HTML, the output is laid out the same way as the examples in the CommonMark spec
+type mdRenderer struct {
+	o     *MarkdownOptions
+	buf   []byte
+	links int // How many links we're inside of
+}
+
+// cr starts a new line, unless we're already at the start of one
+func (r *mdRenderer) cr() {
+	if len(r.buf) > 0 && r.buf[len(r.buf)-1] != '\n' {
+		r.buf = append(r.buf, '\n')
 	}
-	return string(outbytes)
 }
 
-func isMarkdownStartChar(ch byte) bool { // char
-	return ch == '\\' || ch == '~' || ch == '_' || ch == 10 || ch == '`' || ch == '*' || ch == '|'
+func (r *mdRenderer) out(s string) {
+	r.buf = append(r.buf, s...)
 }
 
-func markdownFindChar(data string, index int, char byte) bool {
-	for ; index < len(data); index++ {
-		item := data[index]
-		if item > 32 {
-			return (item == char)
+var mdEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
+
+// esc writes s escaped for both text and attributes, the same as mdEscaper
+func (r *mdRenderer) esc(s string) {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '&':
+			r.out("&amp;")
+		case '<':
+			r.out("&lt;")
+		case '>':
+			r.out("&gt;")
+		case '"':
+			r.out("&quot;")
+		default:
+			r.buf = append(r.buf, c)
 		}
 	}
-	return false
 }
 
-func markdownSkipUntilChar(data string, index int, char byte) int {
-	for ; index < len(data); index++ {
-		if data[index] == char {
-			break
+func (r *mdRenderer) children(n *mdNode, tight bool) {
+	for ch := n.first; ch != nil; ch = ch.next {
+		r.block(ch, tight)
+	}
+}
+
+// block renders n, the paragraphs in tight lists don't get wrapped in <p>s
+func (r *mdRenderer) block(n *mdNode, tight bool) {
+	switch n.typ {
+	case mdDocument:
+		r.children(n, false)
+	case mdBlockQuote:
+		r.cr()
+		r.out("<blockquote>\n")
+		r.children(n, false)
+		r.cr()
+		r.out("</blockquote>")
+		r.cr()
+	case mdList:
+		tag := "ul"
+		r.cr()
+		if n.list.ordered {
+			tag = "ol"
+			if n.list.start != 1 {
+				r.out("<ol start=\"" + strconv.Itoa(n.list.start) + "\">\n")
+			} else {
+				r.out("<ol>\n")
+			}
+		} else {
+			r.out("<ul>\n")
+		}
+		r.children(n, n.list.tight)
+		r.cr()
+		r.out("</" + tag + ">")
+		r.cr()
+	case mdItem:
+		r.cr()
+		r.out("<li>")
+		r.children(n, tight)
+		r.out("</li>")
+		r.cr()
+	case mdParagraph:
+		if !tight {
+			r.cr()
+			r.out("<p>")
+		}
+		switch n.task {
+		case 1:
+			r.out("<input disabled=\"\" type=\"checkbox\"> ")
+		case 2:
+			r.out("<input checked=\"\" disabled=\"\" type=\"checkbox\"> ")
+		}
+		r.inlines(n)
+		if !tight {
+			r.out("</p>")
+			r.cr()
+		}
+	case mdHeading:
+		level := n.level + r.o.HeadingOffset
+		if level > 6 {
+			level = 6
+		}
+		tag := "h" + strconv.Itoa(level)
+		r.cr()
+		r.out("<" + tag + ">")
+		r.inlines(n)
+		r.out("</" + tag + ">")
+		r.cr()
+	case mdThematicBreak:
+		r.cr()
+		r.out("<hr />")
+		r.cr()
+	case mdCodeBlock:
+		r.cr()
+		r.out("<pre><code")
+		if info := strings.Fields(r.o.plain(n.info)); len(info) > 0 {
+			r.out(" class=\"language-")
+			r.esc(info[0])
+			r.out("\"")
 		}
+		r.out(">")
+		r.esc(r.o.plain(n.literal))
+		r.out("</code></pre>")
+		r.cr()
+	case mdHTMLBlock:
+		// These only come out of the parser when raw HTML is allowed
+		r.cr()
+		r.out(n.literal)
+		r.cr()
+	case mdTable:
+		r.cr()
+		r.out("<table>\n")
+		for row := n.first; row != nil; row = row.next {
+			if row == n.first {
+				r.out("<thead>\n")
+			} else if row == n.first.next {
+				r.out("<tbody>\n")
+			}
+			r.tableRow(row, n.aligns)
+			if row == n.first {
+				r.out("</thead>\n")
+			}
+		}
+		if n.first != nil && n.first.next != nil {
+			r.out("</tbody>\n")
+		}
+		r.out("</table>")
+		r.cr()
 	}
-	return index
 }
 
-func markdownSkipUntilNotChar(data string, index int, char byte) int {
-	for ; index < len(data); index++ {
-		if data[index] != char {
-			break
+func (r *mdRenderer) tableRow(row *mdNode, aligns []byte) {
+	tag := "td"
+	if row.header {
+		tag = "th"
+	}
+	r.out("<tr>\n")
+	i := 0
+	for cell := row.first; cell != nil; cell = cell.next {
+		r.out("<" + tag)
+		if i < len(aligns) {
+			switch aligns[i] {
+			case 'l':
+				r.out(" align=\"left\"")
+			case 'c':
+				r.out(" align=\"center\"")
+			case 'r':
+				r.out(" align=\"right\"")
+			}
 		}
+		r.out(">")
+		r.inlines(cell)
+		r.out("</" + tag + ">\n")
+		i++
 	}
-	return index
+	r.out("</tr>\n")
 }
 
-func markdownSkipUntilStrongSpace(data string, index int) int {
-	inSpace := false
-	for ; index < len(data); index++ {
-		if inSpace && data[index] == 32 {
-			index--
-			break
-		} else if data[index] == 32 {
-			inSpace = true
-		} else if data[index] < 32 {
+func (r *mdRenderer) inlines(n *mdNode) {
+	for ch := n.first; ch != nil; ch = ch.next {
+		r.inline(ch)
+	}
+}
+
+func (r *mdRenderer) inline(n *mdNode) {
+	switch n.typ {
+	case mdTextNode:
+		if r.links == 0 || len(r.o.frags) == 0 {
+			r.esc(n.literal)
 			break
+		}
+		// Links can't go inside of other links, so the mentions and hash links in here are cut down to their text
+		r.out(markdownRestore(mdEscaper.Replace(n.literal), r.o.frags, func(frag string) string {
+			if name, _ := markdownTagName(frag); name == "a" {
+				return markdownStripTags(frag)
+			}
+			return frag
+		}))
+	case mdSoftBreak:
+		if r.o.Breaks {
+			r.out("<br />\n")
 		} else {
-			inSpace = false
+			r.out("\n")
+		}
+	case mdHardBreak:
+		r.out("<br />\n")
+	case mdCode:
+		r.out("<code>")
+		r.esc(r.o.plain(n.literal))
+		r.out("</code>")
+	case mdEmph:
+		r.out("<em>")
+		r.inlines(n)
+		r.out("</em>")
+	case mdStrong:
+		r.out("<strong>")
+		r.inlines(n)
+		r.out("</strong>")
+	case mdStrike:
+		r.out("<del>")
+		r.inlines(n)
+		r.out("</del>")
+	case mdHTMLInline:
+		r.out(n.literal)
+	case mdLink:
+		r.out("<a href=\"")
+		r.esc(mdSafeURL(n.dest))
+		r.out("\"")
+		if n.title != "" {
+			r.out(" title=\"")
+			r.esc(r.o.plain(n.title))
+			r.out("\"")
 		}
+		if r.o.UGC {
+			r.out(" rel=\"ugc\"")
+		}
+		r.out(">")
+		r.links++
+		r.inlines(n)
+		r.links--
+		r.out("</a>")
+	case mdImage:
+		r.out("<img src=\"")
+		r.esc(mdSafeURL(n.dest))
+		r.out("\" alt=\"")
+		var sb strings.Builder
+		mdPlainText(n, &sb)
+		r.esc(r.o.plain(sb.String()))
+		r.out("\"")
+		if n.title != "" {
+			r.out(" title=\"")
+			r.esc(r.o.plain(n.title))
+			r.out("\"")
+		}
+		r.out(" />")
 	}
-	return index
 }
 
-func markdownSkipUntilAsterisk(data string, index int) int {
-SwitchLoop:
-	for ; index < len(data); index++ {
-		switch data[index] {
-		case 10:
-			if ((index + 1) < len(data)) && markdownFindChar(data, index, '*') {
-				index = markdownSkipList(data, index)
-			}
-		case '*':
-			break SwitchLoop
+// mdPlainText writes the text inside n without any of the formatting, for the alt text of images
+func mdPlainText(n *mdNode, sb *strings.Builder) {
+	for ch := n.first; ch != nil; ch = ch.next {
+		switch ch.typ {
+		case mdTextNode, mdCode:
+			sb.WriteString(ch.literal)
+		case mdSoftBreak, mdHardBreak:
+			sb.WriteByte('\n')
+		default:
+			mdPlainText(ch, sb)
 		}
 	}
-	return index
 }
 
-// plugin_markdown doesn't support lists yet, but I want it to be easy to have nested lists when we do have them
-func markdownSkipList(data string, index int) int {
-	var lastNewline int
-	datalen := len(data)
-	for ; index < datalen; index++ {
-	SkipListInnerLoop:
-		if data[index] == 10 {
-			lastNewline = index
-			for ; index < datalen; index++ {
-				if data[index] > 32 {
-					break
-				} else if data[index] == 10 {
-					goto SkipListInnerLoop
-				}
-			}
-			if index >= datalen {
-				if data[index] != '*' && data[index] != '-' {
-					if (lastNewline + 1) < datalen {
-						return lastNewline + 1
-					}
-					return lastNewline
-				}
+// mdSafeURL blanks out the kinds of URL which can run scripts, data URLs are only allowed for images
+func mdSafeURL(url string) string {
+	lower := strings.ToLower(strings.TrimSpace(url))
+	for _, scheme := range []string{"javascript:", "vbscript:", "file:"} {
+		if strings.HasPrefix(lower, scheme) {
+			return ""
+		}
+	}
+	if strings.HasPrefix(lower, "data:") {
+		for _, typ := range []string{"gif", "png", "jpeg", "webp"} {
+			if strings.HasPrefix(lower, "data:image/"+typ+";") {
+				return url
 			}
 		}
+		return ""
 	}
-
-	return index
+	return url
 }
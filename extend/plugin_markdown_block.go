@@ -0,0 +1,857 @@
+package extend
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The block half of the Markdown parser, this works out the structure of the document a line at a time, e.g. which lines are in which block quotes, lists and code blocks.
+// It follows the parsing strategy in the appendix of the CommonMark spec, the inlines are parsed once the whole structure is known, as they might use link reference definitions from anywhere in it.
+
+type mdNodeType uint8
+
+const (
+	mdDocument mdNodeType = iota
+	mdBlockQuote
+	mdList
+	mdItem
+	mdParagraph
+	mdHeading
+	mdThematicBreak
+	mdCodeBlock
+	mdHTMLBlock
+	mdTable
+	mdTableRow
+	mdTableCell
+
+	mdTextNode
+	mdSoftBreak
+	mdHardBreak
+	mdCode
+	mdEmph
+	mdStrong
+	mdStrike
+	mdLink
+	mdImage
+	mdHTMLInline
+)
+
+type mdListData struct {
+	ordered      bool
+	bullet       byte
+	start        int
+	delim        byte
+	padding      int
+	markerOffset int
+	tight        bool
+}
+
+type mdNode struct {
+	typ    mdNodeType
+	parent *mdNode
+	first  *mdNode
+	last   *mdNode
+	prev   *mdNode
+	next   *mdNode
+
+	open      bool
+	startLine int
+	endLine   int
+	content   []byte // The raw text of a block, before it's parsed for inlines
+
+	literal string
+	level   int // Headings
+	list    mdListData
+	task    int // Task list items, 1 is unchecked and 2 is checked
+
+	fenced      bool
+	fenceChar   byte
+	fenceLen    int
+	fenceOffset int
+	info        string
+
+	htmlType int
+
+	aligns []byte // Tables, l, c, r or zero for each column
+	header bool   // Table rows
+
+	dest  string
+	title string
+}
+
+func mdText(s string) *mdNode {
+	return &mdNode{typ: mdTextNode, literal: s}
+}
+
+func (n *mdNode) appendChild(child *mdNode) *mdNode {
+	child.unlink()
+	child.parent = n
+	if n.last != nil {
+		n.last.next = child
+		child.prev = n.last
+		n.last = child
+	} else {
+		n.first = child
+		n.last = child
+	}
+	return child
+}
+
+func (n *mdNode) insertAfter(sibling *mdNode) {
+	sibling.unlink()
+	sibling.next = n.next
+	if sibling.next != nil {
+		sibling.next.prev = sibling
+	}
+	sibling.prev = n
+	n.next = sibling
+	sibling.parent = n.parent
+	if sibling.next == nil && sibling.parent != nil {
+		sibling.parent.last = sibling
+	}
+}
+
+func (n *mdNode) unlink() {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else if n.parent != nil {
+		n.parent.first = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else if n.parent != nil {
+		n.parent.last = n.prev
+	}
+	n.parent = nil
+	n.next = nil
+	n.prev = nil
+}
+
+// canContain tells you whether a block of type t can go directly inside n
+func (n *mdNode) canContain(t mdNodeType) bool {
+	switch n.typ {
+	case mdDocument, mdBlockQuote, mdItem:
+		return t != mdItem
+	case mdList:
+		return t == mdItem
+	}
+	return false
+}
+
+// acceptsLines tells you whether the text on a line goes into n rather than starting a new paragraph
+func (n *mdNode) acceptsLines() bool {
+	return n.typ == mdParagraph || n.typ == mdCodeBlock || n.typ == mdHTMLBlock || n.typ == mdTable
+}
+
+const mdCodeIndent = 4
+
+var mdMaybeSpecial = [256]bool{'#': true, '`': true, '~': true, '*': true, '+': true, '_': true, '=': true, '<': true, '>': true, '-': true, '|': true, ':': true, '0': true, '1': true, '2': true, '3': true, '4': true, '5': true, '6': true, '7': true, '8': true, '9': true}
+
+var mdATXHeading = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+var mdATXClosing = regexp.MustCompile(`(?:^|[ \t]+)#+[ \t]*$`)
+var mdCodeFence = regexp.MustCompile("^(?:`{3,}|~{3,})")
+var mdClosingFence = regexp.MustCompile("^(?:`{3,}|~{3,})[ \t]*$")
+var mdSetextHeading = regexp.MustCompile(`^(?:=+|-+)[ \t]*$`)
+var mdThematicBreakLine = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:_[ \t]*){3,}|(?:-[ \t]*){3,})$`)
+var mdBulletMarker = regexp.MustCompile(`^[*+-]`)
+var mdOrderedMarker = regexp.MustCompile(`^(\d{1,9})([.)])`)
+
+var mdHTMLBlockNames = `address|article|aside|base|basefont|blockquote|body|caption|center|col|colgroup|dd|details|dialog|dir|div|dl|dt|fieldset|figcaption|figure|footer|form|frame|frameset|h1|h2|h3|h4|h5|h6|head|header|hr|html|iframe|legend|li|link|main|menu|menuitem|nav|noframes|ol|optgroup|option|p|param|section|source|summary|table|tbody|td|tfoot|th|thead|title|tr|track|ul`
+
+// The conditions which start and end each kind of HTML block, the index is the kind
+var mdHTMLBlockOpen = []*regexp.Regexp{
+	nil,
+	regexp.MustCompile(`^(?i:<(?:script|pre|style|textarea)(?:\s|>|$))`),
+	regexp.MustCompile(`^<!--`),
+	regexp.MustCompile(`^<[?]`),
+	regexp.MustCompile(`^<![A-Z]`),
+	regexp.MustCompile(`^<!\[CDATA\[`),
+	regexp.MustCompile(`^(?i:</?(?:` + mdHTMLBlockNames + `)(?:\s|/?>|$))`),
+	regexp.MustCompile(`^(?:` + mdOpenTag + `|` + mdCloseTag + `)\s*$`),
+}
+var mdHTMLBlockClose = []*regexp.Regexp{
+	nil,
+	regexp.MustCompile(`(?i:</(?:script|pre|style|textarea)>)`),
+	regexp.MustCompile(`-->`),
+	regexp.MustCompile(`\?>`),
+	regexp.MustCompile(`>`),
+	regexp.MustCompile(`\]\]>`),
+}
+
+type mdBlockParser struct {
+	o      *MarkdownOptions
+	doc    *mdNode
+	tip    *mdNode
+	oldTip *mdNode
+	inline *mdInlineParser
+
+	line                 string
+	lineNumber           int
+	offset               int
+	column               int
+	nextNonspace         int
+	nextNonspaceColumn   int
+	indent               int
+	indented             bool
+	blank                bool
+	partiallyConsumedTab bool
+	allClosed            bool
+	lastMatched          *mdNode
+}
+
+// mdParse turns src into a tree of blocks and inlines
+func mdParse(src string, o *MarkdownOptions) *mdNode {
+	doc := &mdNode{typ: mdDocument, open: true, startLine: 1}
+	p := &mdBlockParser{o: o, doc: doc, tip: doc, oldTip: doc, lastMatched: doc}
+	p.inline = &mdInlineParser{o: o, refs: make(map[string]mdRef)}
+
+	src = strings.Replace(src, "\x00", "\uFFFD", -1)
+	src = strings.Replace(src, "\r\n", "\n", -1)
+	src = strings.Replace(src, "\r", "\n", -1)
+	lines := strings.Split(src, "\n")
+	// A line ending at the end doesn't start another line
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		p.incorporateLine(line)
+	}
+	for p.tip != nil {
+		p.finalize(p.tip, len(lines))
+	}
+	p.processInlines(doc)
+	return doc
+}
+
+func (p *mdBlockParser) findNextNonspace() {
+	i, cols := p.offset, p.column
+	for i < len(p.line) {
+		if c := p.line[i]; c == ' ' {
+			i++
+			cols++
+		} else if c == '\t' {
+			i++
+			cols += 4 - (cols % 4)
+		} else {
+			break
+		}
+	}
+	p.blank = i == len(p.line)
+	p.nextNonspace = i
+	p.nextNonspaceColumn = cols
+	p.indent = cols - p.column
+	p.indented = p.indent >= mdCodeIndent
+}
+
+func (p *mdBlockParser) advanceNextNonspace() {
+	p.offset = p.nextNonspace
+	p.column = p.nextNonspaceColumn
+	p.partiallyConsumedTab = false
+}
+
+// advanceOffset moves forward count characters, or count columns, which matters when there are tabs
+func (p *mdBlockParser) advanceOffset(count int, columns bool) {
+	for count > 0 && p.offset < len(p.line) {
+		if p.line[p.offset] != '\t' {
+			p.partiallyConsumedTab = false
+			p.offset++
+			p.column++
+			count--
+			continue
+		}
+		toTab := 4 - (p.column % 4)
+		if !columns {
+			p.partiallyConsumedTab = false
+			p.column += toTab
+			p.offset++
+			count--
+			continue
+		}
+		p.partiallyConsumedTab = toTab > count
+		advance := toTab
+		if toTab > count {
+			advance = count
+		}
+		p.column += advance
+		if !p.partiallyConsumedTab {
+			p.offset++
+		}
+		count -= advance
+	}
+}
+
+func (p *mdBlockParser) peekAt(i int) byte {
+	if i < len(p.line) {
+		return p.line[i]
+	}
+	return 0
+}
+
+// addLine adds the rest of the line to the tip
+func (p *mdBlockParser) addLine() {
+	if p.partiallyConsumedTab {
+		// Skip over the tab, but keep the columns of it which weren't used
+		p.offset++
+		p.tip.content = append(p.tip.content, strings.Repeat(" ", 4-(p.column%4))...)
+	}
+	p.tip.content = append(p.tip.content, p.line[p.offset:]...)
+	p.tip.content = append(p.tip.content, '\n')
+}
+
+// addChild adds a block of type t to the tip, closing any blocks which can't contain it
+func (p *mdBlockParser) addChild(t mdNodeType) *mdNode {
+	for !p.tip.canContain(t) {
+		p.finalize(p.tip, p.lineNumber-1)
+	}
+	n := &mdNode{typ: t, open: true, startLine: p.lineNumber}
+	p.tip.appendChild(n)
+	p.tip = n
+	return n
+}
+
+func (p *mdBlockParser) closeUnmatchedBlocks() {
+	if p.allClosed {
+		return
+	}
+	for p.oldTip != p.lastMatched {
+		parent := p.oldTip.parent
+		p.finalize(p.oldTip, p.lineNumber-1)
+		p.oldTip = parent
+	}
+	p.allClosed = true
+}
+
+func (p *mdBlockParser) incorporateLine(line string) {
+	container := p.doc
+	p.oldTip = p.tip
+	p.offset = 0
+	p.column = 0
+	p.blank = false
+	p.partiallyConsumedTab = false
+	p.lineNumber++
+	p.line = line
+
+	// Find out how many of the open blocks this line continues
+	allMatched := true
+	for container.last != nil && container.last.open {
+		container = container.last
+		p.findNextNonspace()
+		res := p.continueBlock(container)
+		if res == 2 {
+			// The line closed a fenced code block
+			return
+		}
+		if res == 1 {
+			allMatched = false
+			container = container.parent
+			break
+		}
+	}
+	_ = allMatched
+	p.allClosed = container == p.oldTip
+	p.lastMatched = container
+
+	matchedLeaf := container.typ != mdParagraph && container.typ != mdTable && container.acceptsLines()
+	for !matchedLeaf {
+		p.findNextNonspace()
+		if !p.indented && !mdMaybeSpecial[p.peekAt(p.nextNonspace)] {
+			p.advanceNextNonspace()
+			break
+		}
+		res := p.blockStart(container)
+		if res == 0 {
+			p.advanceNextNonspace()
+			break
+		}
+		container = p.tip
+		if res == 2 {
+			matchedLeaf = true
+		}
+	}
+
+	// What's left of the line is text, so it either continues a paragraph lazily, goes into the block we've got or starts a new paragraph
+	if !p.allClosed && !p.blank && p.tip.typ == mdParagraph {
+		p.addLine()
+		return
+	}
+	p.closeUnmatchedBlocks()
+
+	t := container.typ
+	if container.acceptsLines() {
+		p.addLine()
+		if t == mdHTMLBlock && container.htmlType >= 1 && container.htmlType <= 5 && mdHTMLBlockClose[container.htmlType].MatchString(p.line[p.offset:]) {
+			p.finalize(container, p.lineNumber)
+		}
+	} else if p.offset < len(p.line) && !p.blank {
+		p.addChild(mdParagraph)
+		p.advanceNextNonspace()
+		p.addLine()
+	}
+}
+
+// continueBlock checks whether the line continues the open block n, it returns 0 if it does, 1 if it doesn't and 2 if the line has been dealt with
+func (p *mdBlockParser) continueBlock(n *mdNode) int {
+	switch n.typ {
+	case mdBlockQuote:
+		if p.indented || p.peekAt(p.nextNonspace) != '>' {
+			return 1
+		}
+		p.advanceNextNonspace()
+		p.advanceOffset(1, false)
+		if mdIsSpaceOrTab(p.peekAt(p.offset)) {
+			p.advanceOffset(1, true)
+		}
+	case mdItem:
+		if p.blank {
+			// A list item can only start with one blank line
+			if n.first == nil {
+				return 1
+			}
+			p.advanceNextNonspace()
+		} else if p.indent >= n.list.markerOffset+n.list.padding {
+			p.advanceOffset(n.list.markerOffset+n.list.padding, true)
+		} else {
+			return 1
+		}
+	case mdHeading, mdThematicBreak:
+		return 1
+	case mdCodeBlock:
+		if n.fenced {
+			if !p.indented && p.peekAt(p.nextNonspace) == n.fenceChar {
+				if m := mdClosingFence.FindString(p.line[p.nextNonspace:]); m != "" && len(strings.TrimRight(m, " \t")) >= n.fenceLen {
+					p.finalize(n, p.lineNumber)
+					return 2
+				}
+			}
+			// Skip as much of the indentation as the opening fence had
+			for i := n.fenceOffset; i > 0 && mdIsSpaceOrTab(p.peekAt(p.offset)); i-- {
+				p.advanceOffset(1, true)
+			}
+		} else if p.indent >= mdCodeIndent {
+			p.advanceOffset(mdCodeIndent, true)
+		} else if p.blank {
+			p.advanceNextNonspace()
+		} else {
+			return 1
+		}
+	case mdHTMLBlock:
+		if p.blank && (n.htmlType == 6 || n.htmlType == 7) {
+			return 1
+		}
+	case mdParagraph, mdTable:
+		if p.blank {
+			return 1
+		}
+	}
+	return 0
+}
+
+// blockStart tries to start a new block at the current position, it returns 0 if nothing started, 1 if a container started and 2 if a leaf did
+func (p *mdBlockParser) blockStart(container *mdNode) int {
+	rest := p.line[p.nextNonspace:]
+	if !p.indented {
+		switch rest[0] {
+		case '>':
+			p.advanceNextNonspace()
+			p.advanceOffset(1, false)
+			if mdIsSpaceOrTab(p.peekAt(p.offset)) {
+				p.advanceOffset(1, true)
+			}
+			p.closeUnmatchedBlocks()
+			p.addChild(mdBlockQuote)
+			return 1
+		case '#':
+			if m := mdATXHeading.FindString(rest); m != "" {
+				p.advanceNextNonspace()
+				p.advanceOffset(len(m), false)
+				p.closeUnmatchedBlocks()
+				h := p.addChild(mdHeading)
+				h.level = len(strings.TrimRight(m, " \t"))
+				h.content = []byte(mdATXClosing.ReplaceAllString(p.line[p.offset:], ""))
+				p.advanceOffset(len(p.line)-p.offset, false)
+				return 2
+			}
+		case '`', '~':
+			if m := mdCodeFence.FindString(rest); m != "" && (m[0] == '~' || !strings.Contains(rest[len(m):], "`")) {
+				p.closeUnmatchedBlocks()
+				c := p.addChild(mdCodeBlock)
+				c.fenced = true
+				c.fenceLen = len(m)
+				c.fenceChar = m[0]
+				c.fenceOffset = p.indent
+				p.advanceNextNonspace()
+				p.advanceOffset(len(m), false)
+				return 2
+			}
+		case '<':
+			if !p.o.HTML {
+				break
+			}
+			for t := 1; t <= 7; t++ {
+				if !mdHTMLBlockOpen[t].MatchString(rest) {
+					continue
+				}
+				// The last kind can't interrupt a paragraph
+				if t == 7 && (container.typ == mdParagraph || (!p.allClosed && !p.blank && p.tip.typ == mdParagraph)) {
+					continue
+				}
+				p.closeUnmatchedBlocks()
+				// The spaces are part of the block, so the offset stays where it is
+				b := p.addChild(mdHTMLBlock)
+				b.htmlType = t
+				return 2
+			}
+		}
+
+		if container.typ == mdParagraph {
+			if mdSetextHeading.MatchString(rest) {
+				p.closeUnmatchedBlocks()
+				p.parseReferences(container)
+				if len(container.content) > 0 {
+					h := &mdNode{typ: mdHeading, open: true, startLine: container.startLine, content: container.content}
+					h.level = 2
+					if rest[0] == '=' {
+						h.level = 1
+					}
+					container.insertAfter(h)
+					container.unlink()
+					p.tip = h
+					p.advanceOffset(len(p.line)-p.offset, false)
+					return 2
+				}
+			}
+			if p.tableStart(container, rest) {
+				return 2
+			}
+		}
+
+		if mdThematicBreakLine.MatchString(rest) {
+			p.closeUnmatchedBlocks()
+			p.addChild(mdThematicBreak)
+			p.advanceOffset(len(p.line)-p.offset, false)
+			return 2
+		}
+	}
+
+	if !p.indented || container.typ == mdList {
+		if data, ok := p.parseListMarker(container); ok {
+			p.closeUnmatchedBlocks()
+			if p.tip.typ != mdList || !mdListsMatch(container.list, data) {
+				l := p.addChild(mdList)
+				l.list = data
+			}
+			item := p.addChild(mdItem)
+			item.list = data
+			return 1
+		}
+	}
+
+	if p.indented && p.tip.typ != mdParagraph && p.tip.typ != mdTable && !p.blank {
+		p.advanceOffset(mdCodeIndent, true)
+		p.closeUnmatchedBlocks()
+		p.addChild(mdCodeBlock)
+		return 2
+	}
+	return 0
+}
+
+func (p *mdBlockParser) parseListMarker(container *mdNode) (data mdListData, ok bool) {
+	if p.indent >= mdCodeIndent {
+		return data, false
+	}
+	rest := p.line[p.nextNonspace:]
+	data = mdListData{tight: true, markerOffset: p.indent}
+	var marker string
+	if m := mdBulletMarker.FindString(rest); m != "" {
+		marker = m
+		data.bullet = m[0]
+	} else if m := mdOrderedMarker.FindStringSubmatch(rest); m != nil && (container.typ != mdParagraph || m[1] == "1") {
+		marker = m[0]
+		data.ordered = true
+		data.start, _ = strconv.Atoi(m[1])
+		data.delim = m[2][0]
+	} else {
+		return data, false
+	}
+
+	// There has to be a space after the marker
+	afterMarker := p.nextNonspace + len(marker)
+	if c := p.peekAt(afterMarker); c != 0 && !mdIsSpaceOrTab(c) {
+		return data, false
+	}
+	// An empty list item can't interrupt a paragraph
+	if container.typ == mdParagraph && strings.Trim(p.line[afterMarker:], " \t") == "" {
+		return data, false
+	}
+
+	p.advanceNextNonspace()
+	p.advanceOffset(len(marker), true)
+	spacesStartCol, spacesStartOffset := p.column, p.offset
+	for {
+		p.advanceOffset(1, true)
+		if p.column-spacesStartCol >= 5 || !mdIsSpaceOrTab(p.peekAt(p.offset)) {
+			break
+		}
+	}
+	blankItem := p.offset >= len(p.line)
+	spaces := p.column - spacesStartCol
+	if spaces >= 5 || spaces < 1 || blankItem {
+		// The content is indented code or the item is empty, so only one space is part of the marker
+		data.padding = len(marker) + 1
+		p.column = spacesStartCol
+		p.offset = spacesStartOffset
+		if mdIsSpaceOrTab(p.peekAt(p.offset)) {
+			p.advanceOffset(1, true)
+		}
+	} else {
+		data.padding = len(marker) + spaces
+	}
+	return data, true
+}
+
+func mdListsMatch(a, b mdListData) bool {
+	return a.ordered == b.ordered && a.delim == b.delim && a.bullet == b.bullet
+}
+
+// tableStart turns the last line of the paragraph into the header row of a table, if the current line is a delimiter row with as many cells
+func (p *mdBlockParser) tableStart(para *mdNode, rest string) bool {
+	aligns, ok := mdTableDelimiterRow(rest)
+	if !ok {
+		return false
+	}
+	content := strings.TrimSuffix(string(para.content), "\n")
+	headerStart := strings.LastIndexByte(content, '\n') + 1
+	header := content[headerStart:]
+	if !strings.Contains(header, "|") || len(mdTableCells(header)) != len(aligns) {
+		return false
+	}
+
+	p.closeUnmatchedBlocks()
+	t := &mdNode{typ: mdTable, open: true, startLine: p.lineNumber - 1, aligns: aligns}
+	t.content = append([]byte(header), '\n')
+	para.insertAfter(t)
+	para.content = para.content[:headerStart]
+	if headerStart == 0 {
+		para.unlink()
+	} else {
+		p.finalizeParagraph(para)
+		para.open = false
+		para.endLine = p.lineNumber - 2
+	}
+	p.tip = t
+	return true
+}
+
+// mdTableDelimiterRow parses a line like | :--- | ---: | into the alignments of the columns
+func mdTableDelimiterRow(line string) ([]byte, bool) {
+	line = strings.Trim(line, " \t")
+	if line == "" || strings.Trim(line, "|-: \t") != "" {
+		return nil, false
+	}
+	// A line like - - - is a list or a thematic break rather than a table
+	if line[0] == '-' && len(line) > 1 && mdIsSpaceOrTab(line[1]) {
+		return nil, false
+	}
+	cols := strings.Split(line, "|")
+	var aligns []byte
+	for i, col := range cols {
+		col = strings.Trim(col, " \t")
+		if col == "" {
+			// The pipes on the ends are optional
+			if i == 0 || i == len(cols)-1 {
+				continue
+			}
+			return nil, false
+		}
+		l, r := col[0] == ':', col[len(col)-1] == ':'
+		if strings.Trim(col, ":") == "" || strings.Trim(strings.TrimSuffix(strings.TrimPrefix(col, ":"), ":"), "-") != "" {
+			return nil, false
+		}
+		switch {
+		case l && r:
+			aligns = append(aligns, 'c')
+		case r:
+			aligns = append(aligns, 'r')
+		case l:
+			aligns = append(aligns, 'l')
+		default:
+			aligns = append(aligns, 0)
+		}
+	}
+	return aligns, len(aligns) > 0
+}
+
+// mdTableCells splits a table row into cells, a pipe can be escaped with a backslash to put it in a cell
+func mdTableCells(line string) []string {
+	line = strings.Trim(line, " \t")
+	var cells []string
+	var sb strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			sb.WriteByte('|')
+			i++
+		case c == '\\' && i+1 < len(line):
+			sb.WriteString(line[i : i+2])
+			i++
+		case c == '|':
+			cells = append(cells, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	cells = append(cells, sb.String())
+	if len(cells) > 1 && strings.Trim(cells[0], " \t") == "" && line[0] == '|' {
+		cells = cells[1:]
+	}
+	if len(cells) > 1 && strings.Trim(cells[len(cells)-1], " \t") == "" && line[len(line)-1] == '|' && !strings.HasSuffix(line, "\\|") {
+		cells = cells[:len(cells)-1]
+	}
+	for i, cell := range cells {
+		cells[i] = strings.Trim(cell, " \t")
+	}
+	return cells
+}
+
+// parseReferences takes the link reference definitions off the start of a paragraph
+func (p *mdBlockParser) parseReferences(para *mdNode) {
+	s := string(para.content)
+	for len(s) > 0 && s[0] == '[' {
+		n := p.inline.parseReference(s)
+		if n == 0 {
+			break
+		}
+		s = s[n:]
+	}
+	para.content = para.content[len(para.content)-len(s):]
+}
+
+func (p *mdBlockParser) finalizeParagraph(para *mdNode) {
+	// It might have been nothing but link reference definitions, they could've been taken off already, if it was almost a setext heading
+	p.parseReferences(para)
+	if strings.Trim(string(para.content), " \t\n") == "" {
+		para.unlink()
+		return
+	}
+	// A task list item, e.g. - [x] done
+	if item := para.parent; item != nil && item.typ == mdItem && item.first == para && len(para.content) > 3 && para.content[0] == '[' && para.content[2] == ']' && mdIsSpaceOrTab(para.content[3]) && strings.Trim(string(para.content[4:]), " \t\n") != "" {
+		switch para.content[1] {
+		case ' ':
+			para.task = 1
+		case 'x', 'X':
+			para.task = 2
+		default:
+			return
+		}
+		para.content = para.content[4:]
+	}
+}
+
+func (p *mdBlockParser) finalize(n *mdNode, line int) {
+	above := n.parent
+	n.open = false
+	n.endLine = line
+
+	switch n.typ {
+	case mdParagraph:
+		p.finalizeParagraph(n)
+	case mdCodeBlock:
+		content := string(n.content)
+		if n.fenced {
+			// The first line is the info string
+			nl := strings.IndexByte(content, '\n')
+			n.info = mdUnescape(strings.Trim(content[:nl], " \t\n"))
+			n.literal = content[nl+1:]
+		} else {
+			n.literal = mdTrimBlankLines(content)
+			// The blank lines after it aren't part of it, which matters for whether a list is loose
+			n.endLine = n.startLine + strings.Count(n.literal, "\n")
+			if n.literal != "" {
+				n.literal += "\n"
+			}
+		}
+		n.content = nil
+	case mdHTMLBlock:
+		n.literal = mdTrimBlankLines(string(n.content))
+		n.content = nil
+	case mdItem:
+		if n.last != nil {
+			n.endLine = n.last.endLine
+		} else {
+			n.endLine = n.startLine
+		}
+	case mdList:
+		p.finalizeList(n)
+	case mdTable:
+		p.finalizeTable(n)
+	}
+	p.tip = above
+}
+
+// mdTrimBlankLines takes the blank lines off the end of a code or HTML block
+func mdTrimBlankLines(s string) string {
+	lines := strings.Split(s, "\n")
+	for len(lines) > 0 && strings.Trim(lines[len(lines)-1], " ") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// finalizeList works out whether a list is tight or loose, it's loose if there are blank lines between any of it's items or the blocks in them
+func (p *mdBlockParser) finalizeList(l *mdNode) {
+	endsWithBlank := func(n *mdNode) bool {
+		return n.next != nil && n.endLine != n.next.startLine-1
+	}
+	for item := l.first; item != nil; item = item.next {
+		if endsWithBlank(item) {
+			l.list.tight = false
+			break
+		}
+		for sub := item.first; sub != nil; sub = sub.next {
+			if endsWithBlank(sub) {
+				l.list.tight = false
+				break
+			}
+		}
+		if !l.list.tight {
+			break
+		}
+	}
+	if l.last != nil {
+		l.endLine = l.last.endLine
+	}
+}
+
+// finalizeTable splits the lines of a table into rows and cells, the first line is the header and the second the delimiter row
+func (p *mdBlockParser) finalizeTable(t *mdNode) {
+	lines := strings.Split(strings.TrimSuffix(string(t.content), "\n"), "\n")
+	t.content = nil
+	for i, line := range lines {
+		if i == 1 {
+			continue
+		}
+		row := t.appendChild(&mdNode{typ: mdTableRow, header: i == 0})
+		cells := mdTableCells(line)
+		for j := range t.aligns {
+			cell := row.appendChild(&mdNode{typ: mdTableCell, header: i == 0})
+			if j < len(cells) {
+				cell.content = []byte(cells[j])
+			}
+		}
+	}
+}
+
+// processInlines parses the inlines in every block which has them, now that all the link reference definitions are known
+func (p *mdBlockParser) processInlines(n *mdNode) {
+	switch n.typ {
+	case mdParagraph, mdHeading, mdTableCell:
+		p.inline.parse(n, string(n.content))
+		n.content = nil
+		return
+	}
+	for c := n.first; c != nil; c = c.next {
+		p.processInlines(c)
+	}
+}
@@ -0,0 +1,1015 @@
+package extend
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The inline half of the Markdown parser, this turns the text in paragraphs, headings and table cells into emphasis, links, code spans and so on.
+// It follows the algorithm in the appendix of the CommonMark spec, with a delimiter stack for emphasis and a bracket stack for links.
+
+// mdRef is a link reference definition, e.g. [label]: /url "title"
+type mdRef struct {
+	dest  string
+	title string
+}
+
+// mdDelim is an entry on the delimiter stack, a run of *, _ or ~ which might turn out to open or close emphasis
+type mdDelim struct {
+	ch        byte
+	count     int
+	origCount int
+	node      *mdNode
+	prev      *mdDelim
+	next      *mdDelim
+	canOpen   bool
+	canClose  bool
+}
+
+// mdBracket is an entry on the bracket stack, a [ or ![ which might turn out to open a link or image
+type mdBracket struct {
+	node         *mdNode
+	prev         *mdBracket
+	prevDelim    *mdDelim
+	index        int // Where the [ is in the subject
+	image        bool
+	active       bool
+	bracketAfter bool
+}
+
+type mdInlineParser struct {
+	o        *MarkdownOptions
+	refs     map[string]mdRef
+	subject  string
+	pos      int
+	delims   *mdDelim
+	brackets *mdBracket
+
+	// Where the last run of backticks of each length is, so an unclosed code span doesn't have to scan the rest of the subject again
+	ticks        map[int]int
+	ticksScanned bool
+}
+
+var mdSpecialChars = [256]bool{'\n': true, '\\': true, '`': true, '*': true, '_': true, '~': true, '[': true, ']': true, '!': true, '<': true, '&': true}
+
+var mdEmailAutolink = regexp.MustCompile("^<([a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>")
+var mdAutolink = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9.+-]{1,31}:[^<>\x00-\x20]*>`)
+var mdEntity = regexp.MustCompile(`^&(?:#[xX][0-9a-fA-F]{1,6}|#[0-9]{1,7}|[a-zA-Z][a-zA-Z0-9]{1,31});`)
+
+const (
+	mdAttrName   = `[a-zA-Z_:][a-zA-Z0-9:._-]*`
+	mdAttrValue  = `(?:[^"'=<>` + "`" + `\x00-\x20]+|'[^']*'|"[^"]*")`
+	mdAttribute  = `(?:\s+` + mdAttrName + `(?:\s*=\s*` + mdAttrValue + `)?)`
+	mdOpenTag    = `<[A-Za-z][A-Za-z0-9-]*` + mdAttribute + `*\s*/?>`
+	mdCloseTag   = `</[A-Za-z][A-Za-z0-9-]*\s*>`
+	mdComment    = `<!---->|<!--(?:-?[^>-])(?:-?[^-])*-->`
+	mdProcessing = `<[?](?s:.)*?[?]>`
+	mdDecl       = `<![A-Z]+\s+[^>]*>`
+	mdCDATA      = `<!\[CDATA\[(?s:.)*?\]\]>`
+)
+
+var mdHTMLTag = regexp.MustCompile(`^(?:` + mdOpenTag + `|` + mdCloseTag + `|` + mdComment + `|` + mdProcessing + `|` + mdDecl + `|` + mdCDATA + `)`)
+
+func (p *mdInlineParser) peek() byte {
+	if p.pos < len(p.subject) {
+		return p.subject[p.pos]
+	}
+	return 0
+}
+
+// parse turns s into inlines which are appended to block
+func (p *mdInlineParser) parse(block *mdNode, s string) {
+	p.subject = strings.Trim(s, " \t\n")
+	p.pos = 0
+	p.delims = nil
+	p.brackets = nil
+	p.ticksScanned = false
+	for p.pos < len(p.subject) {
+		if !p.parseInline(block) {
+			// Nothing wanted it, so it's just text
+			block.appendChild(mdText(p.subject[p.pos : p.pos+1]))
+			p.pos++
+		}
+	}
+	p.processEmphasis(nil)
+	if p.o.Autolink {
+		mdExtendedAutolinks(block)
+	}
+}
+
+func (p *mdInlineParser) parseInline(block *mdNode) bool {
+	switch ch := p.subject[p.pos]; ch {
+	case '\n':
+		return p.parseNewline(block)
+	case '\\':
+		return p.parseBackslash(block)
+	case '`':
+		return p.parseBackticks(block)
+	case '*', '_', '~':
+		return p.handleDelim(ch, block)
+	case '[':
+		p.addBracket(block.appendChild(mdText("[")), p.pos, false)
+		p.pos++
+		return true
+	case '!':
+		if p.pos+1 < len(p.subject) && p.subject[p.pos+1] == '[' {
+			p.addBracket(block.appendChild(mdText("![")), p.pos+1, true)
+			p.pos += 2
+			return true
+		}
+		return false
+	case ']':
+		return p.parseCloseBracket(block)
+	case '<':
+		return p.parseAutolink(block) || p.parseHTMLTag(block)
+	case '&':
+		return p.parseEntity(block)
+	}
+	start := p.pos
+	for p.pos < len(p.subject) && !mdSpecialChars[p.subject[p.pos]] {
+		p.pos++
+	}
+	if start == p.pos {
+		return false
+	}
+	block.appendChild(mdText(p.subject[start:p.pos]))
+	return true
+}
+
+// parseNewline turns a line ending into a soft break, or a hard one if the line ended with two or more spaces
+func (p *mdInlineParser) parseNewline(block *mdNode) bool {
+	p.pos++
+	typ := mdSoftBreak
+	if last := block.last; last != nil && last.typ == mdTextNode && strings.HasSuffix(last.literal, " ") {
+		if strings.HasSuffix(last.literal, "  ") {
+			typ = mdHardBreak
+		}
+		last.literal = strings.TrimRight(last.literal, " ")
+	}
+	block.appendChild(&mdNode{typ: typ})
+	for p.pos < len(p.subject) && p.subject[p.pos] == ' ' {
+		p.pos++
+	}
+	return true
+}
+
+func (p *mdInlineParser) parseBackslash(block *mdNode) bool {
+	p.pos++
+	switch ch := p.peek(); {
+	case ch == '\n':
+		p.pos++
+		block.appendChild(&mdNode{typ: mdHardBreak})
+	case mdIsEscapable(ch):
+		block.appendChild(mdText(p.subject[p.pos : p.pos+1]))
+		p.pos++
+	default:
+		block.appendChild(mdText("\\"))
+	}
+	return true
+}
+
+func (p *mdInlineParser) parseBackticks(block *mdNode) bool {
+	start := p.pos
+	for p.pos < len(p.subject) && p.subject[p.pos] == '`' {
+		p.pos++
+	}
+	n, afterOpen := p.pos-start, p.pos
+
+	if !p.ticksScanned {
+		p.ticksScanned = true
+		p.ticks = make(map[int]int)
+		for i := 0; i < len(p.subject); {
+			if p.subject[i] != '`' {
+				i++
+				continue
+			}
+			j := i
+			for j < len(p.subject) && p.subject[j] == '`' {
+				j++
+			}
+			p.ticks[j-i] = i
+			i = j
+		}
+	}
+	if last, ok := p.ticks[n]; ok && last > start {
+		for i := afterOpen; i < len(p.subject); {
+			if p.subject[i] != '`' {
+				i++
+				continue
+			}
+			j := i
+			for j < len(p.subject) && p.subject[j] == '`' {
+				j++
+			}
+			if j-i != n {
+				i = j
+				continue
+			}
+			code := strings.Replace(p.subject[afterOpen:i], "\n", " ", -1)
+			if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+			block.appendChild(&mdNode{typ: mdCode, literal: code})
+			p.pos = j
+			return true
+		}
+	}
+	// There's nothing to close it, so the backticks are just backticks
+	block.appendChild(mdText(p.subject[start:afterOpen]))
+	return true
+}
+
+// handleDelim pushes a run of *, _ or ~ onto the delimiter stack, if it's able to open or close anything
+func (p *mdInlineParser) handleDelim(ch byte, block *mdNode) bool {
+	start := p.pos
+	for p.pos < len(p.subject) && p.subject[p.pos] == ch {
+		p.pos++
+	}
+	n := p.pos - start
+	node := block.appendChild(mdText(p.subject[start:p.pos]))
+	// Strikethrough only comes in ~ and ~~
+	if ch == '~' && n > 2 {
+		return true
+	}
+
+	before, after := '\n', '\n'
+	if start > 0 {
+		before, _ = utf8.DecodeLastRuneInString(p.subject[:start])
+	}
+	if p.pos < len(p.subject) {
+		after, _ = utf8.DecodeRuneInString(p.subject[p.pos:])
+	}
+	beforeSpace, beforePunct := mdIsSpace(before), mdIsPunct(before)
+	afterSpace, afterPunct := mdIsSpace(after), mdIsPunct(after)
+	left := !afterSpace && (!afterPunct || beforeSpace || beforePunct)
+	right := !beforeSpace && (!beforePunct || afterSpace || afterPunct)
+
+	canOpen, canClose := left, right
+	if ch == '_' {
+		canOpen = left && (!right || beforePunct)
+		canClose = right && (!left || afterPunct)
+	}
+	if canOpen || canClose {
+		d := &mdDelim{ch: ch, count: n, origCount: n, node: node, prev: p.delims, canOpen: canOpen, canClose: canClose}
+		if p.delims != nil {
+			p.delims.next = d
+		}
+		p.delims = d
+	}
+	return true
+}
+
+func (p *mdInlineParser) removeDelim(d *mdDelim) {
+	if d.prev != nil {
+		d.prev.next = d.next
+	}
+	if d.next == nil {
+		p.delims = d.prev
+	} else {
+		d.next.prev = d.prev
+	}
+}
+
+// processEmphasis matches up the openers and closers above bottom on the delimiter stack and turns what's between them into emphasis
+func (p *mdInlineParser) processEmphasis(bottom *mdDelim) {
+	// The lowest point to search for an opener for each kind of closer, so runs of closers without openers don't make this quadratic
+	var openersBottom [14]*mdDelim
+	for i := range openersBottom {
+		openersBottom[i] = bottom
+	}
+	closer := p.delims
+	for closer != nil && closer.prev != bottom {
+		closer = closer.prev
+	}
+
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+		var bi int
+		switch closer.ch {
+		case '_':
+			bi = closer.origCount % 3
+		case '*':
+			bi = 6 + closer.origCount%3
+		case '~':
+			bi = 11 + closer.count
+		}
+		if closer.ch != '~' && closer.canOpen {
+			bi += 3
+		}
+
+		opener := closer.prev
+		found := false
+		for opener != nil && opener != bottom && opener != openersBottom[bi] {
+			if opener.ch == closer.ch && opener.canOpen {
+				if closer.ch == '~' {
+					found = opener.count == closer.count
+				} else {
+					// Rule of three, e.g. *foo**bar* is one piece of emphasis, not two
+					found = !((closer.canOpen || opener.canClose) && closer.origCount%3 != 0 && (opener.origCount+closer.origCount)%3 == 0)
+				}
+				if found {
+					break
+				}
+			}
+			opener = opener.prev
+		}
+
+		if !found {
+			openersBottom[bi] = closer.prev
+			next := closer.next
+			if !closer.canOpen {
+				p.removeDelim(closer)
+			}
+			closer = next
+			continue
+		}
+
+		use, typ := 1, mdEmph
+		if closer.ch == '~' {
+			use, typ = closer.count, mdStrike
+		} else if closer.count >= 2 && opener.count >= 2 {
+			use, typ = 2, mdStrong
+		}
+		on, cn := opener.node, closer.node
+		opener.count -= use
+		closer.count -= use
+		on.literal = on.literal[:len(on.literal)-use]
+		cn.literal = cn.literal[:len(cn.literal)-use]
+
+		emph := &mdNode{typ: typ}
+		for tmp := on.next; tmp != nil && tmp != cn; {
+			next := tmp.next
+			tmp.unlink()
+			emph.appendChild(tmp)
+			tmp = next
+		}
+		on.insertAfter(emph)
+
+		// Anything between the two can't be matched with anything outside them anymore
+		opener.next = closer
+		closer.prev = opener
+
+		if opener.count == 0 {
+			on.unlink()
+			p.removeDelim(opener)
+		}
+		if closer.count == 0 {
+			cn.unlink()
+			next := closer.next
+			p.removeDelim(closer)
+			closer = next
+		}
+	}
+
+	for p.delims != nil && p.delims != bottom {
+		p.removeDelim(p.delims)
+	}
+}
+
+func (p *mdInlineParser) addBracket(node *mdNode, index int, image bool) {
+	if p.brackets != nil {
+		p.brackets.bracketAfter = true
+	}
+	p.brackets = &mdBracket{node: node, prev: p.brackets, prevDelim: p.delims, index: index, image: image, active: true}
+}
+
+// parseCloseBracket looks for a link or image ending at the ] under pos
+func (p *mdInlineParser) parseCloseBracket(block *mdNode) bool {
+	p.pos++
+	startPos := p.pos
+
+	opener := p.brackets
+	if opener == nil {
+		block.appendChild(mdText("]"))
+		return true
+	}
+	if !opener.active {
+		block.appendChild(mdText("]"))
+		p.brackets = opener.prev
+		return true
+	}
+
+	var dest, title string
+	matched := false
+
+	// An inline link, e.g. [text](/url "title")
+	if p.peek() == '(' {
+		p.pos++
+		p.spnl()
+		if d, ok := p.parseLinkDestination(); ok {
+			dest = d
+			p.spnl()
+			// There has to be some whitespace between the destination and the title
+			if mdIsSpaceOrTab(p.subject[p.pos-1]) || p.subject[p.pos-1] == '\n' {
+				title, _ = p.parseLinkTitle()
+			}
+			p.spnl()
+			if p.peek() == ')' {
+				p.pos++
+				matched = true
+			}
+		}
+		if !matched {
+			p.pos = startPos
+		}
+	}
+
+	// A reference link, e.g. [text][label], [label][] or [label]
+	if !matched {
+		beforeLabel := p.pos
+		n := p.parseLinkLabel()
+		var label string
+		if n > 2 {
+			label = p.subject[beforeLabel : beforeLabel+n]
+		} else if !opener.bracketAfter {
+			// The first label is used as the reference, it can't contain brackets, if we know there's a bracket in there, we don't bother looking
+			label = p.subject[opener.index:startPos]
+		}
+		if n == 0 {
+			p.pos = startPos
+		}
+		if label != "" {
+			if ref, ok := p.refs[mdNormalizeLabel(label)]; ok {
+				dest, title = ref.dest, ref.title
+				matched = true
+			}
+		}
+	}
+
+	if !matched {
+		p.brackets = opener.prev
+		p.pos = startPos
+		block.appendChild(mdText("]"))
+		return true
+	}
+
+	typ := mdLink
+	if opener.image {
+		typ = mdImage
+	}
+	node := &mdNode{typ: typ, dest: dest, title: title}
+	for tmp := opener.node.next; tmp != nil; {
+		next := tmp.next
+		tmp.unlink()
+		node.appendChild(tmp)
+		tmp = next
+	}
+	block.appendChild(node)
+	p.processEmphasis(opener.prevDelim)
+	p.brackets = opener.prev
+	opener.node.unlink()
+
+	// Links can't contain other links, so the openers before this one can't be links anymore
+	if !opener.image {
+		for b := p.brackets; b != nil; b = b.prev {
+			if !b.image {
+				b.active = false
+			}
+		}
+	}
+	return true
+}
+
+// spnl skips over spaces, tabs and up to one line ending
+func (p *mdInlineParser) spnl() {
+	for p.pos < len(p.subject) && mdIsSpaceOrTab(p.subject[p.pos]) {
+		p.pos++
+	}
+	if p.peek() == '\n' {
+		p.pos++
+		for p.pos < len(p.subject) && mdIsSpaceOrTab(p.subject[p.pos]) {
+			p.pos++
+		}
+	}
+}
+
+// parseLinkLabel returns the length of the link label under pos, including the brackets, or zero if there isn't one
+func (p *mdInlineParser) parseLinkLabel() int {
+	s := p.subject
+	if p.pos >= len(s) || s[p.pos] != '[' {
+		return 0
+	}
+	runes := 0
+	for i := p.pos + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && s[i+1] != '\n' {
+				i++
+			}
+		case '[':
+			return 0
+		case ']':
+			n := i + 1 - p.pos
+			p.pos = i + 1
+			return n
+		}
+		// Count runes rather than bytes
+		if s[i] < utf8.RuneSelf || utf8.RuneStart(s[i]) {
+			runes++
+		}
+		if runes > 999 {
+			return 0
+		}
+	}
+	return 0
+}
+
+func (p *mdInlineParser) parseLinkDestination() (string, bool) {
+	s := p.subject
+	if p.peek() == '<' {
+		for i := p.pos + 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				if i+1 >= len(s) || s[i+1] == '\n' {
+					return "", false
+				}
+				i++
+			case '>':
+				dest := s[p.pos+1 : i]
+				p.pos = i + 1
+				return mdNormalizeURL(mdUnescape(p.o.plain(dest))), true
+			case '<', '\n':
+				return "", false
+			}
+		}
+		return "", false
+	}
+
+	start, parens := p.pos, 0
+	i := p.pos
+loop:
+	for i < len(s) {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && mdIsEscapable(s[i+1]):
+			i += 2
+		case c == '(':
+			// Deeply nested parentheses would make this quadratic, so they're cut off like in other implementations
+			if parens++; parens > 32 {
+				return "", false
+			}
+			i++
+		case c == ')':
+			if parens < 1 {
+				break loop
+			}
+			parens--
+			i++
+		case c <= ' ' || c == 0x7f:
+			break loop
+		default:
+			i++
+		}
+	}
+	if (i == start && (i >= len(s) || s[i] != ')')) || parens != 0 {
+		return "", false
+	}
+	p.pos = i
+	return mdNormalizeURL(mdUnescape(p.o.plain(s[start:i]))), true
+}
+
+func (p *mdInlineParser) parseLinkTitle() (string, bool) {
+	s := p.subject
+	var end byte
+	switch p.peek() {
+	case '"':
+		end = '"'
+	case '\'':
+		end = '\''
+	case '(':
+		end = ')'
+	default:
+		return "", false
+	}
+	for i := p.pos + 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == end:
+			title := s[p.pos+1 : i]
+			p.pos = i + 1
+			return mdUnescape(p.o.plain(title)), true
+		case c == '(' && end == ')':
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// parseReference reads a link reference definition from the start of s, it returns how much of s it used, zero if there wasn't one
+func (p *mdInlineParser) parseReference(s string) int {
+	p.subject = s
+	p.pos = 0
+
+	n := p.parseLinkLabel()
+	if n == 0 || p.peek() != ':' {
+		return 0
+	}
+	label := s[:n]
+	p.pos++
+
+	p.spnl()
+	dest, ok := p.parseLinkDestination()
+	if !ok {
+		return 0
+	}
+
+	beforeTitle := p.pos
+	p.spnl()
+	title, hasTitle := "", false
+	if p.pos != beforeTitle {
+		title, hasTitle = p.parseLinkTitle()
+	}
+	if !hasTitle {
+		p.pos = beforeTitle
+	}
+
+	// It has to end the line
+	if !p.atLineEnd() {
+		if !hasTitle {
+			return 0
+		}
+		// The title isn't at the end of the line, but the destination might be, so it's still a definition without the title
+		title = ""
+		p.pos = beforeTitle
+		if !p.atLineEnd() {
+			return 0
+		}
+	}
+
+	key := mdNormalizeLabel(label)
+	if key == "" {
+		return 0
+	}
+	if _, ok := p.refs[key]; !ok {
+		p.refs[key] = mdRef{dest, title}
+	}
+	return p.pos
+}
+
+// atLineEnd skips over the spaces at the end of the line and the line ending, if that's all there is before the next line
+func (p *mdInlineParser) atLineEnd() bool {
+	i := p.pos
+	for i < len(p.subject) && mdIsSpaceOrTab(p.subject[i]) {
+		i++
+	}
+	if i < len(p.subject) && p.subject[i] != '\n' {
+		return false
+	}
+	if i < len(p.subject) {
+		i++
+	}
+	p.pos = i
+	return true
+}
+
+func (p *mdInlineParser) parseAutolink(block *mdNode) bool {
+	rest := p.subject[p.pos:]
+	var dest, label string
+	if m := mdEmailAutolink.FindString(rest); m != "" {
+		label = m[1 : len(m)-1]
+		dest = "mailto:" + label
+	} else if m := mdAutolink.FindString(rest); m != "" {
+		label = m[1 : len(m)-1]
+		dest = label
+	} else {
+		return false
+	}
+	p.pos += len(label) + 2
+	node := block.appendChild(&mdNode{typ: mdLink, dest: mdNormalizeURL(p.o.plain(dest))})
+	node.appendChild(mdText(label))
+	return true
+}
+
+// mdExtendedAutolinks turns the URLs, www. addresses and email addresses in the text under n into links like GitHub does, the text in links and images is left alone
+func mdExtendedAutolinks(n *mdNode) {
+	for ch := n.first; ch != nil; ch = ch.next {
+		switch ch.typ {
+		case mdLink, mdImage:
+			continue
+		case mdTextNode:
+		default:
+			mdExtendedAutolinks(ch)
+			continue
+		}
+		// The delimiters which didn't turn into emphasis are in text nodes of their own, so put the text back together first
+		if ch.next != nil && ch.next.typ == mdTextNode {
+			var sb strings.Builder
+			sb.WriteString(ch.literal)
+			for ch.next != nil && ch.next.typ == mdTextNode {
+				sb.WriteString(ch.next.literal)
+				ch.next.unlink()
+			}
+			ch.literal = sb.String()
+		}
+		ch = mdAutolinkText(ch)
+	}
+}
+
+// mdAutolinkText splits the links out of the text node n and returns the last of the nodes it was split into
+func mdAutolinkText(n *mdNode) *mdNode {
+	s := n.literal
+	last, done := n, 0
+	for i := 0; i < len(s); i++ {
+		start, end, dest := i, -1, ""
+		switch c := s[i]; {
+		case c == 'w' && strings.HasPrefix(s[i:], "www.") && mdAutolinkBoundary(s, i):
+			end = mdAutolinkURL(s, i, i, false)
+			dest = "http://"
+		case c == 'h' && (strings.HasPrefix(s[i:], "http://") || strings.HasPrefix(s[i:], "https://")) && mdAutolinkBoundary(s, i):
+			end = mdAutolinkURL(s, i, i+strings.Index(s[i:], "//")+2, true)
+		case c == '@':
+			start, end = mdAutolinkEmail(s, done, i)
+			dest = "mailto:"
+		}
+		if end == -1 {
+			continue
+		}
+
+		link := &mdNode{typ: mdLink, dest: mdNormalizeURL(dest + s[start:end])}
+		link.appendChild(mdText(s[start:end]))
+		if done == 0 {
+			n.literal = s[:start]
+		} else {
+			last.insertAfter(mdText(s[done:start]))
+			last = last.next
+		}
+		last.insertAfter(link)
+		last = link
+		done = end
+		i = end - 1
+	}
+	if done > 0 && done < len(s) {
+		last.insertAfter(mdText(s[done:]))
+		last = last.next
+	}
+	return last
+}
+
+// mdAutolinkBoundary tells you whether a www. or http:// link can start at i, they have to be at the start of a word, although they can be emphasised or in brackets
+func mdAutolinkBoundary(s string, i int) bool {
+	return i == 0 || strings.IndexByte(" \t\n\v\f\r*_~(", s[i-1]) != -1
+}
+
+// mdAutolinkURL returns where the link starting at start ends or -1 if there isn't one, the domain starts at domain, it has to have a period in it unless short is true and the last two parts of it can't have underscores
+func mdAutolinkURL(s string, start, domain int, short bool) int {
+	end := domain
+	under1, under2 := false, false
+	for ; end < len(s); end++ {
+		// Domains can't be this long anyway and this stops a long run of failed links being quadratic
+		if end-domain > 255 {
+			return -1
+		}
+		c := s[end]
+		if c == '_' {
+			under2 = true
+		} else if c == '.' {
+			under1, under2 = under2, false
+		} else if c != '-' && !mdIsAlnum(c) {
+			break
+		}
+	}
+	if end == domain || under1 || under2 || (!short && !strings.Contains(strings.TrimRight(s[domain:end], "."), ".")) {
+		return -1
+	}
+	// The path runs up to the next space or <
+	if i := strings.IndexAny(s[end:], " \t\n\v\f\r<"); i != -1 {
+		end += i
+	} else {
+		end = len(s)
+	}
+	end = start + len(mdAutolinkTrim(s[start:end]))
+	if end <= domain {
+		return -1
+	}
+	return end
+}
+
+// mdAutolinkTrim takes the punctuation off the end of a link, as that's more likely to be a part of the sentence around it
+func mdAutolinkTrim(link string) string {
+	opens, closes := strings.Count(link, "("), strings.Count(link, ")")
+	for len(link) > 0 {
+		switch c := link[len(link)-1]; {
+		case strings.IndexByte("?!.,:*_~'\"", c) != -1:
+			link = link[:len(link)-1]
+		case c == ';':
+			// Something which looks like an entity is left out entirely
+			i := len(link) - 2
+			for i >= 0 && mdIsAlnum(link[i]) {
+				i--
+			}
+			if i >= 0 && i < len(link)-2 && link[i] == '&' {
+				link = link[:i]
+			} else {
+				link = link[:len(link)-1]
+			}
+		case c == ')' && closes > opens:
+			link = link[:len(link)-1]
+			closes--
+		default:
+			return link
+		}
+	}
+	return link
+}
+
+// mdAutolinkEmail finds the email address with it's @ at at, it can't start before min
+func mdAutolinkEmail(s string, min, at int) (start, end int) {
+	start = at
+	for start > min {
+		c := s[start-1]
+		if c == '/' {
+			return -1, -1
+		}
+		if !mdIsAlnum(c) && strings.IndexByte(".+-_", c) == -1 {
+			break
+		}
+		start--
+	}
+	if start == at {
+		return -1, -1
+	}
+	periods := 0
+	for end = at + 1; end < len(s); end++ {
+		c := s[end]
+		if c == '.' && end+1 < len(s) && mdIsAlnum(s[end+1]) {
+			periods++
+		} else if c != '-' && c != '_' && !mdIsAlnum(c) {
+			break
+		}
+	}
+	// Only a period can be at the end of the address and that's left out of it
+	if periods == 0 || !mdIsAlpha(s[end-1]) {
+		return -1, -1
+	}
+	return start, end
+}
+
+func (p *mdInlineParser) parseHTMLTag(block *mdNode) bool {
+	if !p.o.HTML {
+		return false
+	}
+	m := mdHTMLTag.FindString(p.subject[p.pos:])
+	if m == "" {
+		return false
+	}
+	p.pos += len(m)
+	block.appendChild(&mdNode{typ: mdHTMLInline, literal: m})
+	return true
+}
+
+func (p *mdInlineParser) parseEntity(block *mdNode) bool {
+	m := mdEntity.FindString(p.subject[p.pos:])
+	if m == "" {
+		return false
+	}
+	p.pos += len(m)
+	block.appendChild(mdText(mdDecodeEntity(m)))
+	return true
+}
+
+// mdDecodeEntity turns an entity like &amp; or &#35; into the character it stands for, it's left as is, if it isn't one
+func mdDecodeEntity(ent string) string {
+	name := ent[1 : len(ent)-1]
+	if name[0] != '#' {
+		s := html.UnescapeString(ent)
+		// The html package also accepts prefixes of entities, e.g. &ampx; is &x; to it, so make sure the whole name was used
+		if s == ent || (strings.HasSuffix(s, ";") && name != "semi") {
+			return ent
+		}
+		return s
+	}
+	var code int64
+	var err error
+	if name[1] == 'x' || name[1] == 'X' {
+		code, err = strconv.ParseInt(name[2:], 16, 32)
+	} else {
+		code, err = strconv.ParseInt(name[1:], 10, 32)
+	}
+	if err != nil || !mdValidEntityCode(code) {
+		return "\uFFFD"
+	}
+	return string(rune(code))
+}
+
+// mdValidEntityCode weeds out the code points which aren't safe to put in a page, like surrogates, non-characters and control codes
+func mdValidEntityCode(c int64) bool {
+	switch {
+	case c >= 0xD800 && c <= 0xDFFF:
+		return false
+	case c >= 0xFDD0 && c <= 0xFDEF:
+		return false
+	case c&0xFFFF == 0xFFFF || c&0xFFFF == 0xFFFE:
+		return false
+	case c <= 0x08 || c == 0x0B || (c >= 0x0E && c <= 0x1F) || (c >= 0x7F && c <= 0x9F):
+		return false
+	}
+	return c <= unicode.MaxRune
+}
+
+// mdUnescape resolves the backslash escapes and entities in things like link destinations and titles, which aren't parsed for inlines
+func mdUnescape(s string) string {
+	if strings.IndexByte(s, '\\') == -1 && strings.IndexByte(s, '&') == -1 {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && mdIsEscapable(s[i+1]):
+			i++
+			sb.WriteByte(s[i])
+		case c == '&':
+			if m := mdEntity.FindString(s[i:]); m != "" {
+				sb.WriteString(mdDecodeEntity(m))
+				i += len(m) - 1
+				continue
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// mdNormalizeLabel case folds a link label and collapses the whitespace in it, so labels can be matched regardless of how they were written
+func mdNormalizeLabel(label string) string {
+	label = strings.TrimSpace(label[1 : len(label)-1])
+	label = strings.Join(strings.Fields(label), " ")
+	label = strings.ToLower(label)
+	// Go doesn't do the full case folding, this is the one case the spec cares about
+	return strings.Replace(label, "ß", "ss", -1)
+}
+
+const mdHex = "0123456789ABCDEF"
+
+// mdNormalizeURL percent-encodes the characters which shouldn't be in a URL, leaving the ones which are already encoded alone
+func mdNormalizeURL(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '%' && i+2 < len(s) && mdIsHex(s[i+1]) && mdIsHex(s[i+2]) {
+			sb.WriteString(s[i : i+3])
+			i += 2
+			continue
+		}
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || strings.IndexByte(";/?:@&=+$,-_.!~*'()#", c) != -1 {
+			sb.WriteByte(c)
+			continue
+		}
+		if c >= utf8.RuneSelf {
+			// Make sure the bytes are valid UTF-8, before encoding them
+			r, size := utf8.DecodeRuneInString(s[i:])
+			var buf [utf8.UTFMax]byte
+			for _, b := range buf[:utf8.EncodeRune(buf[:], r)] {
+				sb.WriteByte('%')
+				sb.WriteByte(mdHex[b>>4])
+				sb.WriteByte(mdHex[b&15])
+			}
+			i += size - 1
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte(mdHex[c>>4])
+		sb.WriteByte(mdHex[c&15])
+	}
+	return sb.String()
+}
+
+func mdIsAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func mdIsAlnum(c byte) bool {
+	return mdIsAlpha(c) || (c >= '0' && c <= '9')
+}
+
+func mdIsHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// mdIsEscapable tells you whether c is an ASCII punctuation character, these are the ones which can be backslash escaped
+func mdIsEscapable(c byte) bool {
+	return (c >= '!' && c <= '/') || (c >= ':' && c <= '@') || (c >= '[' && c <= '`') || (c >= '{' && c <= '~')
+}
+
+func mdIsSpaceOrTab(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func mdIsSpace(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\f' || r == '\r' || unicode.Is(unicode.Zs, r)
+}
+
+func mdIsPunct(r rune) bool {
+	if r < utf8.RuneSelf {
+		return mdIsEscapable(byte(r))
+	}
+	return unicode.IsPunct(r)
+}
@@ -1,4 +1,4 @@
-// +build !pgsql,!mssql
+// +build !pgsql,!mssql,!sqlite
 
 /* This file was generated by Gosora's Query Generator. Please try to avoid modifying this file, as it might change at any time. */
 
@@ -32,17 +32,24 @@ var RouteMap = map[string]interface{}{
 	"routes.CustomPage": routes.CustomPage,
 	"routes.ForumList": routes.ForumList,
 	"routes.ViewForum": routes.ViewForum,
+	"routes.TopicListByTag": routes.TopicListByTag,
 	"routes.ChangeTheme": routes.ChangeTheme,
 	"routes.ShowAttachment": routes.ShowAttachment,
 	"common.RouteWebsockets": c.RouteWebsockets,
 	"routeAPIPhrases": routeAPIPhrases,
 	"routes.APIMe": routes.APIMe,
+	"routes.APIUserFields": routes.APIUserFields,
+	"routes.APITagSuggest": routes.APITagSuggest,
+	"routes.APIDraft": routes.APIDraft,
 	"routeJSAntispam": routeJSAntispam,
 	"routeAPI": routeAPI,
 	"routes.ReportSubmit": routes.ReportSubmit,
 	"routes.TopicListMostViewed": routes.TopicListMostViewed,
 	"routes.TopicListWeekViews": routes.TopicListWeekViews,
 	"routes.CreateTopic": routes.CreateTopic,
+	"routes.ScheduledTopics": routes.ScheduledTopics,
+	"routes.ScheduledTopicDeleteSubmit": routes.ScheduledTopicDeleteSubmit,
+	"routes.ScheduledTopicPublishSubmit": routes.ScheduledTopicPublishSubmit,
 	"routes.TopicList": routes.TopicList,
 	"panel.Forums": panel.Forums,
 	"panel.ForumsCreateSubmit": panel.ForumsCreateSubmit,
@@ -52,6 +59,8 @@ var RouteMap = map[string]interface{}{
 	"panel.ForumsEdit": panel.ForumsEdit,
 	"panel.ForumsEditSubmit": panel.ForumsEditSubmit,
 	"panel.ForumsEditPermsSubmit": panel.ForumsEditPermsSubmit,
+	"panel.ForumsEditModSubmit": panel.ForumsEditModSubmit,
+	"panel.ForumsEditModDeleteSubmit": panel.ForumsEditModDeleteSubmit,
 	"panel.ForumsEditPermsAdvance": panel.ForumsEditPermsAdvance,
 	"panel.ForumsEditPermsAdvanceSubmit": panel.ForumsEditPermsAdvanceSubmit,
 	"panel.Settings": panel.Settings,
@@ -62,6 +71,25 @@ var RouteMap = map[string]interface{}{
 	"panel.WordFiltersEdit": panel.WordFiltersEdit,
 	"panel.WordFiltersEditSubmit": panel.WordFiltersEditSubmit,
 	"panel.WordFiltersDeleteSubmit": panel.WordFiltersDeleteSubmit,
+	"panel.Phrases": panel.Phrases,
+	"panel.PhrasesEdit": panel.PhrasesEdit,
+	"panel.PhrasesCreateSubmit": panel.PhrasesCreateSubmit,
+	"panel.PhrasesEditSubmit": panel.PhrasesEditSubmit,
+	"panel.PhrasesDeleteSubmit": panel.PhrasesDeleteSubmit,
+	"panel.ProfileFields": panel.ProfileFields,
+	"panel.ProfileFieldsCreateSubmit": panel.ProfileFieldsCreateSubmit,
+	"panel.ProfileFieldsEdit": panel.ProfileFieldsEdit,
+	"panel.ProfileFieldsEditSubmit": panel.ProfileFieldsEditSubmit,
+	"panel.ProfileFieldsDeleteSubmit": panel.ProfileFieldsDeleteSubmit,
+	"panel.TopicPrefixes": panel.TopicPrefixes,
+	"panel.TopicPrefixesCreateSubmit": panel.TopicPrefixesCreateSubmit,
+	"panel.TopicPrefixesEdit": panel.TopicPrefixesEdit,
+	"panel.TopicPrefixesEditSubmit": panel.TopicPrefixesEditSubmit,
+	"panel.TopicPrefixesDeleteSubmit": panel.TopicPrefixesDeleteSubmit,
+	"panel.Trash": panel.Trash,
+	"panel.TrashReplies": panel.TrashReplies,
+	"panel.TrashPurgeTopicSubmit": panel.TrashPurgeTopicSubmit,
+	"panel.TrashPurgeReplySubmit": panel.TrashPurgeReplySubmit,
 	"panel.Pages": panel.Pages,
 	"panel.PagesCreateSubmit": panel.PagesCreateSubmit,
 	"panel.PagesEdit": panel.PagesEdit,
@@ -69,6 +97,14 @@ var RouteMap = map[string]interface{}{
 	"panel.PagesDeleteSubmit": panel.PagesDeleteSubmit,
 	"panel.Themes": panel.Themes,
 	"panel.ThemesSetDefault": panel.ThemesSetDefault,
+	"panel.ThemesEdit": panel.ThemesEdit,
+	"panel.ThemesSettingsSubmit": panel.ThemesSettingsSubmit,
+	"panel.ThemesCreateChildSubmit": panel.ThemesCreateChildSubmit,
+	"panel.ThemesExport": panel.ThemesExport,
+	"panel.ThemesImportSubmit": panel.ThemesImportSubmit,
+	"panel.ThemesFileEdit": panel.ThemesFileEdit,
+	"panel.ThemesFileEditSubmit": panel.ThemesFileEditSubmit,
+	"panel.ThemesFilePreviewSubmit": panel.ThemesFilePreviewSubmit,
 	"panel.ThemesMenus": panel.ThemesMenus,
 	"panel.ThemesMenusEdit": panel.ThemesMenusEdit,
 	"panel.ThemesMenuItemEdit": panel.ThemesMenuItemEdit,
@@ -118,6 +154,8 @@ var RouteMap = map[string]interface{}{
 	"panel.GroupsEditPermsSubmit": panel.GroupsEditPermsSubmit,
 	"panel.GroupsCreateSubmit": panel.GroupsCreateSubmit,
 	"panel.Backups": panel.Backups,
+	"panel.BackupsCreateSubmit": panel.BackupsCreateSubmit,
+	"panel.BackupsRestoreSubmit": panel.BackupsRestoreSubmit,
 	"panel.LogsRegs": panel.LogsRegs,
 	"panel.LogsMod": panel.LogsMod,
 	"panel.LogsAdmin": panel.LogsAdmin,
@@ -132,6 +170,16 @@ var RouteMap = map[string]interface{}{
 	"routes.AccountEditUsernameSubmit": routes.AccountEditUsernameSubmit,
 	"routes.AccountEditPrivacy": routes.AccountEditPrivacy,
 	"routes.AccountEditPrivacySubmit": routes.AccountEditPrivacySubmit,
+	"routes.AccountEditFields": routes.AccountEditFields,
+	"routes.AccountEditFieldsSubmit": routes.AccountEditFieldsSubmit,
+	"routes.AccountEditTheme": routes.AccountEditTheme,
+	"routes.AccountEditThemeSubmit": routes.AccountEditThemeSubmit,
+	"routes.AccountEditLang": routes.AccountEditLang,
+	"routes.AccountEditLangSubmit": routes.AccountEditLangSubmit,
+	"routes.AccountEditData": routes.AccountEditData,
+	"routes.AccountEditDataExportSubmit": routes.AccountEditDataExportSubmit,
+	"routes.AccountEditDataDeleteSubmit": routes.AccountEditDataDeleteSubmit,
+	"routes.AccountEditDataDeleteCancelSubmit": routes.AccountEditDataDeleteCancelSubmit,
 	"routes.AccountEditMFA": routes.AccountEditMFA,
 	"routes.AccountEditMFASetup": routes.AccountEditMFASetup,
 	"routes.AccountEditMFASetupSubmit": routes.AccountEditMFASetupSubmit,
@@ -140,6 +188,9 @@ var RouteMap = map[string]interface{}{
 	"routes.AccountEditEmailTokenSubmit": routes.AccountEditEmailTokenSubmit,
 	"routes.AccountLogins": routes.AccountLogins,
 	"routes.AccountBlocked": routes.AccountBlocked,
+	"routes.AccountDrafts": routes.AccountDrafts,
+	"routes.AccountDraftsDeleteSubmit": routes.AccountDraftsDeleteSubmit,
+	"routes.DraftSaveSubmit": routes.DraftSaveSubmit,
 	"routes.LevelList": routes.LevelList,
 	"routes.Convos": routes.Convos,
 	"routes.ConvosCreate": routes.ConvosCreate,
@@ -161,23 +212,39 @@ var RouteMap = map[string]interface{}{
 	"routes.CreateTopicSubmit": routes.CreateTopicSubmit,
 	"routes.EditTopicSubmit": routes.EditTopicSubmit,
 	"routes.DeleteTopicSubmit": routes.DeleteTopicSubmit,
+	"routes.RestoreTopicSubmit": routes.RestoreTopicSubmit,
 	"routes.StickTopicSubmit": routes.StickTopicSubmit,
 	"routes.UnstickTopicSubmit": routes.UnstickTopicSubmit,
 	"routes.LockTopicSubmit": routes.LockTopicSubmit,
 	"routes.UnlockTopicSubmit": routes.UnlockTopicSubmit,
 	"routes.MoveTopicSubmit": routes.MoveTopicSubmit,
+	"routes.MergeTopicSubmit": routes.MergeTopicSubmit,
+	"routes.SplitTopicSubmit": routes.SplitTopicSubmit,
+	"routes.MoveRepliesSubmit": routes.MoveRepliesSubmit,
 	"routes.LikeTopicSubmit": routes.LikeTopicSubmit,
 	"routes.UnlikeTopicSubmit": routes.UnlikeTopicSubmit,
 	"routes.AddAttachToTopicSubmit": routes.AddAttachToTopicSubmit,
 	"routes.RemoveAttachFromTopicSubmit": routes.RemoveAttachFromTopicSubmit,
+	"routes.TopicRevisions": routes.TopicRevisions,
+	"routes.TopicRevertSubmit": routes.TopicRevertSubmit,
+	"routes.TopicTimedActions": routes.TopicTimedActions,
+	"routes.TopicTimedActionCreateSubmit": routes.TopicTimedActionCreateSubmit,
+	"routes.TopicTimedActionDeleteSubmit": routes.TopicTimedActionDeleteSubmit,
 	"routes.ViewTopic": routes.ViewTopic,
 	"routes.CreateReplySubmit": routes.CreateReplySubmit,
 	"routes.ReplyEditSubmit": routes.ReplyEditSubmit,
 	"routes.ReplyDeleteSubmit": routes.ReplyDeleteSubmit,
+	"routes.ReplyRestoreSubmit": routes.ReplyRestoreSubmit,
 	"routes.ReplyLikeSubmit": routes.ReplyLikeSubmit,
 	"routes.ReplyUnlikeSubmit": routes.ReplyUnlikeSubmit,
+	"routes.ReplyUpvoteSubmit": routes.ReplyUpvoteSubmit,
+	"routes.ReplyDownvoteSubmit": routes.ReplyDownvoteSubmit,
+	"routes.ReplyAcceptSubmit": routes.ReplyAcceptSubmit,
+	"routes.ReplyUnacceptSubmit": routes.ReplyUnacceptSubmit,
 	"routes.AddAttachToReplySubmit": routes.AddAttachToReplySubmit,
 	"routes.RemoveAttachFromReplySubmit": routes.RemoveAttachFromReplySubmit,
+	"routes.ReplyRevisions": routes.ReplyRevisions,
+	"routes.ReplyRevertSubmit": routes.ReplyRevertSubmit,
 	"routes.ProfileReplyCreateSubmit": routes.ProfileReplyCreateSubmit,
 	"routes.ProfileReplyEditSubmit": routes.ProfileReplyEditSubmit,
 	"routes.ProfileReplyDeleteSubmit": routes.ProfileReplyDeleteSubmit,
@@ -212,177 +279,244 @@ var routeMapEnum = map[string]int{
 	"routes.CustomPage": 2,
 	"routes.ForumList": 3,
 	"routes.ViewForum": 4,
-	"routes.ChangeTheme": 5,
-	"routes.ShowAttachment": 6,
-	"common.RouteWebsockets": 7,
-	"routeAPIPhrases": 8,
-	"routes.APIMe": 9,
-	"routeJSAntispam": 10,
-	"routeAPI": 11,
-	"routes.ReportSubmit": 12,
-	"routes.TopicListMostViewed": 13,
-	"routes.TopicListWeekViews": 14,
-	"routes.CreateTopic": 15,
-	"routes.TopicList": 16,
-	"panel.Forums": 17,
-	"panel.ForumsCreateSubmit": 18,
-	"panel.ForumsDelete": 19,
-	"panel.ForumsDeleteSubmit": 20,
-	"panel.ForumsOrderSubmit": 21,
-	"panel.ForumsEdit": 22,
-	"panel.ForumsEditSubmit": 23,
-	"panel.ForumsEditPermsSubmit": 24,
-	"panel.ForumsEditPermsAdvance": 25,
-	"panel.ForumsEditPermsAdvanceSubmit": 26,
-	"panel.Settings": 27,
-	"panel.SettingEdit": 28,
-	"panel.SettingEditSubmit": 29,
-	"panel.WordFilters": 30,
-	"panel.WordFiltersCreateSubmit": 31,
-	"panel.WordFiltersEdit": 32,
-	"panel.WordFiltersEditSubmit": 33,
-	"panel.WordFiltersDeleteSubmit": 34,
-	"panel.Pages": 35,
-	"panel.PagesCreateSubmit": 36,
-	"panel.PagesEdit": 37,
-	"panel.PagesEditSubmit": 38,
-	"panel.PagesDeleteSubmit": 39,
-	"panel.Themes": 40,
-	"panel.ThemesSetDefault": 41,
-	"panel.ThemesMenus": 42,
-	"panel.ThemesMenusEdit": 43,
-	"panel.ThemesMenuItemEdit": 44,
-	"panel.ThemesMenuItemEditSubmit": 45,
-	"panel.ThemesMenuItemCreateSubmit": 46,
-	"panel.ThemesMenuItemDeleteSubmit": 47,
-	"panel.ThemesMenuItemOrderSubmit": 48,
-	"panel.ThemesWidgets": 49,
-	"panel.ThemesWidgetsEditSubmit": 50,
-	"panel.ThemesWidgetsCreateSubmit": 51,
-	"panel.ThemesWidgetsDeleteSubmit": 52,
-	"panel.Plugins": 53,
-	"panel.PluginsActivate": 54,
-	"panel.PluginsDeactivate": 55,
-	"panel.PluginsInstall": 56,
-	"panel.Users": 57,
-	"panel.UsersEdit": 58,
-	"panel.UsersEditSubmit": 59,
-	"panel.UsersAvatarSubmit": 60,
-	"panel.UsersAvatarRemoveSubmit": 61,
-	"panel.AnalyticsViews": 62,
-	"panel.AnalyticsRoutes": 63,
-	"panel.AnalyticsRoutesPerf": 64,
-	"panel.AnalyticsAgents": 65,
-	"panel.AnalyticsSystems": 66,
-	"panel.AnalyticsLanguages": 67,
-	"panel.AnalyticsReferrers": 68,
-	"panel.AnalyticsRouteViews": 69,
-	"panel.AnalyticsAgentViews": 70,
-	"panel.AnalyticsForumViews": 71,
-	"panel.AnalyticsSystemViews": 72,
-	"panel.AnalyticsLanguageViews": 73,
-	"panel.AnalyticsReferrerViews": 74,
-	"panel.AnalyticsPosts": 75,
-	"panel.AnalyticsMemory": 76,
-	"panel.AnalyticsActiveMemory": 77,
-	"panel.AnalyticsTopics": 78,
-	"panel.AnalyticsForums": 79,
-	"panel.AnalyticsPerf": 80,
-	"panel.Groups": 81,
-	"panel.GroupsEdit": 82,
-	"panel.GroupsEditPromotions": 83,
-	"panel.GroupsPromotionsCreateSubmit": 84,
-	"panel.GroupsPromotionsDeleteSubmit": 85,
-	"panel.GroupsEditPerms": 86,
-	"panel.GroupsEditSubmit": 87,
-	"panel.GroupsEditPermsSubmit": 88,
-	"panel.GroupsCreateSubmit": 89,
-	"panel.Backups": 90,
-	"panel.LogsRegs": 91,
-	"panel.LogsMod": 92,
-	"panel.LogsAdmin": 93,
-	"panel.Debug": 94,
-	"panel.DebugTasks": 95,
-	"panel.Dashboard": 96,
-	"routes.AccountEdit": 97,
-	"routes.AccountEditPassword": 98,
-	"routes.AccountEditPasswordSubmit": 99,
-	"routes.AccountEditAvatarSubmit": 100,
-	"routes.AccountEditRevokeAvatarSubmit": 101,
-	"routes.AccountEditUsernameSubmit": 102,
-	"routes.AccountEditPrivacy": 103,
-	"routes.AccountEditPrivacySubmit": 104,
-	"routes.AccountEditMFA": 105,
-	"routes.AccountEditMFASetup": 106,
-	"routes.AccountEditMFASetupSubmit": 107,
-	"routes.AccountEditMFADisableSubmit": 108,
-	"routes.AccountEditEmail": 109,
-	"routes.AccountEditEmailTokenSubmit": 110,
-	"routes.AccountLogins": 111,
-	"routes.AccountBlocked": 112,
-	"routes.LevelList": 113,
-	"routes.Convos": 114,
-	"routes.ConvosCreate": 115,
-	"routes.Convo": 116,
-	"routes.ConvosCreateSubmit": 117,
-	"routes.ConvosCreateReplySubmit": 118,
-	"routes.ConvosDeleteReplySubmit": 119,
-	"routes.ConvosEditReplySubmit": 120,
-	"routes.RelationsBlockCreate": 121,
-	"routes.RelationsBlockCreateSubmit": 122,
-	"routes.RelationsBlockRemove": 123,
-	"routes.RelationsBlockRemoveSubmit": 124,
-	"routes.ViewProfile": 125,
-	"routes.BanUserSubmit": 126,
-	"routes.UnbanUser": 127,
-	"routes.ActivateUser": 128,
-	"routes.IPSearch": 129,
-	"routes.DeletePostsSubmit": 130,
-	"routes.CreateTopicSubmit": 131,
-	"routes.EditTopicSubmit": 132,
-	"routes.DeleteTopicSubmit": 133,
-	"routes.StickTopicSubmit": 134,
-	"routes.UnstickTopicSubmit": 135,
-	"routes.LockTopicSubmit": 136,
-	"routes.UnlockTopicSubmit": 137,
-	"routes.MoveTopicSubmit": 138,
-	"routes.LikeTopicSubmit": 139,
-	"routes.UnlikeTopicSubmit": 140,
-	"routes.AddAttachToTopicSubmit": 141,
-	"routes.RemoveAttachFromTopicSubmit": 142,
-	"routes.ViewTopic": 143,
-	"routes.CreateReplySubmit": 144,
-	"routes.ReplyEditSubmit": 145,
-	"routes.ReplyDeleteSubmit": 146,
-	"routes.ReplyLikeSubmit": 147,
-	"routes.ReplyUnlikeSubmit": 148,
-	"routes.AddAttachToReplySubmit": 149,
-	"routes.RemoveAttachFromReplySubmit": 150,
-	"routes.ProfileReplyCreateSubmit": 151,
-	"routes.ProfileReplyEditSubmit": 152,
-	"routes.ProfileReplyDeleteSubmit": 153,
-	"routes.PollVote": 154,
-	"routes.PollResults": 155,
-	"routes.AccountLogin": 156,
-	"routes.AccountRegister": 157,
-	"routes.AccountLogout": 158,
-	"routes.AccountLoginSubmit": 159,
-	"routes.AccountLoginMFAVerify": 160,
-	"routes.AccountLoginMFAVerifySubmit": 161,
-	"routes.AccountRegisterSubmit": 162,
-	"routes.AccountPasswordReset": 163,
-	"routes.AccountPasswordResetSubmit": 164,
-	"routes.AccountPasswordResetToken": 165,
-	"routes.AccountPasswordResetTokenSubmit": 166,
-	"routes.DynamicRoute": 167,
-	"routes.UploadedFile": 168,
-	"routes.StaticFile": 169,
-	"routes.RobotsTxt": 170,
-	"routes.SitemapXml": 171,
-	"routes.OpenSearchXml": 172,
-	"routes.Favicon": 173,
-	"routes.BadRoute": 174,
-	"routes.HTTPSRedirect": 175,
+	"routes.TopicListByTag": 5,
+	"routes.ChangeTheme": 6,
+	"routes.ShowAttachment": 7,
+	"common.RouteWebsockets": 8,
+	"routeAPIPhrases": 9,
+	"routes.APIMe": 10,
+	"routes.APIUserFields": 11,
+	"routes.APITagSuggest": 12,
+	"routes.APIDraft": 13,
+	"routeJSAntispam": 14,
+	"routeAPI": 15,
+	"routes.ReportSubmit": 16,
+	"routes.TopicListMostViewed": 17,
+	"routes.TopicListWeekViews": 18,
+	"routes.CreateTopic": 19,
+	"routes.ScheduledTopics": 20,
+	"routes.ScheduledTopicDeleteSubmit": 21,
+	"routes.ScheduledTopicPublishSubmit": 22,
+	"routes.TopicList": 23,
+	"panel.Forums": 24,
+	"panel.ForumsCreateSubmit": 25,
+	"panel.ForumsDelete": 26,
+	"panel.ForumsDeleteSubmit": 27,
+	"panel.ForumsOrderSubmit": 28,
+	"panel.ForumsEdit": 29,
+	"panel.ForumsEditSubmit": 30,
+	"panel.ForumsEditPermsSubmit": 31,
+	"panel.ForumsEditModSubmit": 32,
+	"panel.ForumsEditModDeleteSubmit": 33,
+	"panel.ForumsEditPermsAdvance": 34,
+	"panel.ForumsEditPermsAdvanceSubmit": 35,
+	"panel.Settings": 36,
+	"panel.SettingEdit": 37,
+	"panel.SettingEditSubmit": 38,
+	"panel.WordFilters": 39,
+	"panel.WordFiltersCreateSubmit": 40,
+	"panel.WordFiltersEdit": 41,
+	"panel.WordFiltersEditSubmit": 42,
+	"panel.WordFiltersDeleteSubmit": 43,
+	"panel.Phrases": 44,
+	"panel.PhrasesEdit": 45,
+	"panel.PhrasesCreateSubmit": 46,
+	"panel.PhrasesEditSubmit": 47,
+	"panel.PhrasesDeleteSubmit": 48,
+	"panel.ProfileFields": 49,
+	"panel.ProfileFieldsCreateSubmit": 50,
+	"panel.ProfileFieldsEdit": 51,
+	"panel.ProfileFieldsEditSubmit": 52,
+	"panel.ProfileFieldsDeleteSubmit": 53,
+	"panel.TopicPrefixes": 54,
+	"panel.TopicPrefixesCreateSubmit": 55,
+	"panel.TopicPrefixesEdit": 56,
+	"panel.TopicPrefixesEditSubmit": 57,
+	"panel.TopicPrefixesDeleteSubmit": 58,
+	"panel.Trash": 59,
+	"panel.TrashReplies": 60,
+	"panel.TrashPurgeTopicSubmit": 61,
+	"panel.TrashPurgeReplySubmit": 62,
+	"panel.Pages": 63,
+	"panel.PagesCreateSubmit": 64,
+	"panel.PagesEdit": 65,
+	"panel.PagesEditSubmit": 66,
+	"panel.PagesDeleteSubmit": 67,
+	"panel.Themes": 68,
+	"panel.ThemesSetDefault": 69,
+	"panel.ThemesEdit": 70,
+	"panel.ThemesSettingsSubmit": 71,
+	"panel.ThemesCreateChildSubmit": 72,
+	"panel.ThemesExport": 73,
+	"panel.ThemesImportSubmit": 74,
+	"panel.ThemesFileEdit": 75,
+	"panel.ThemesFileEditSubmit": 76,
+	"panel.ThemesFilePreviewSubmit": 77,
+	"panel.ThemesMenus": 78,
+	"panel.ThemesMenusEdit": 79,
+	"panel.ThemesMenuItemEdit": 80,
+	"panel.ThemesMenuItemEditSubmit": 81,
+	"panel.ThemesMenuItemCreateSubmit": 82,
+	"panel.ThemesMenuItemDeleteSubmit": 83,
+	"panel.ThemesMenuItemOrderSubmit": 84,
+	"panel.ThemesWidgets": 85,
+	"panel.ThemesWidgetsEditSubmit": 86,
+	"panel.ThemesWidgetsCreateSubmit": 87,
+	"panel.ThemesWidgetsDeleteSubmit": 88,
+	"panel.Plugins": 89,
+	"panel.PluginsActivate": 90,
+	"panel.PluginsDeactivate": 91,
+	"panel.PluginsInstall": 92,
+	"panel.Users": 93,
+	"panel.UsersEdit": 94,
+	"panel.UsersEditSubmit": 95,
+	"panel.UsersAvatarSubmit": 96,
+	"panel.UsersAvatarRemoveSubmit": 97,
+	"panel.AnalyticsViews": 98,
+	"panel.AnalyticsRoutes": 99,
+	"panel.AnalyticsRoutesPerf": 100,
+	"panel.AnalyticsAgents": 101,
+	"panel.AnalyticsSystems": 102,
+	"panel.AnalyticsLanguages": 103,
+	"panel.AnalyticsReferrers": 104,
+	"panel.AnalyticsRouteViews": 105,
+	"panel.AnalyticsAgentViews": 106,
+	"panel.AnalyticsForumViews": 107,
+	"panel.AnalyticsSystemViews": 108,
+	"panel.AnalyticsLanguageViews": 109,
+	"panel.AnalyticsReferrerViews": 110,
+	"panel.AnalyticsPosts": 111,
+	"panel.AnalyticsMemory": 112,
+	"panel.AnalyticsActiveMemory": 113,
+	"panel.AnalyticsTopics": 114,
+	"panel.AnalyticsForums": 115,
+	"panel.AnalyticsPerf": 116,
+	"panel.Groups": 117,
+	"panel.GroupsEdit": 118,
+	"panel.GroupsEditPromotions": 119,
+	"panel.GroupsPromotionsCreateSubmit": 120,
+	"panel.GroupsPromotionsDeleteSubmit": 121,
+	"panel.GroupsEditPerms": 122,
+	"panel.GroupsEditSubmit": 123,
+	"panel.GroupsEditPermsSubmit": 124,
+	"panel.GroupsCreateSubmit": 125,
+	"panel.Backups": 126,
+	"panel.BackupsCreateSubmit": 127,
+	"panel.BackupsRestoreSubmit": 128,
+	"panel.LogsRegs": 129,
+	"panel.LogsMod": 130,
+	"panel.LogsAdmin": 131,
+	"panel.Debug": 132,
+	"panel.DebugTasks": 133,
+	"panel.Dashboard": 134,
+	"routes.AccountEdit": 135,
+	"routes.AccountEditPassword": 136,
+	"routes.AccountEditPasswordSubmit": 137,
+	"routes.AccountEditAvatarSubmit": 138,
+	"routes.AccountEditRevokeAvatarSubmit": 139,
+	"routes.AccountEditUsernameSubmit": 140,
+	"routes.AccountEditPrivacy": 141,
+	"routes.AccountEditPrivacySubmit": 142,
+	"routes.AccountEditFields": 143,
+	"routes.AccountEditFieldsSubmit": 144,
+	"routes.AccountEditTheme": 145,
+	"routes.AccountEditThemeSubmit": 146,
+	"routes.AccountEditLang": 147,
+	"routes.AccountEditLangSubmit": 148,
+	"routes.AccountEditData": 149,
+	"routes.AccountEditDataExportSubmit": 150,
+	"routes.AccountEditDataDeleteSubmit": 151,
+	"routes.AccountEditDataDeleteCancelSubmit": 152,
+	"routes.AccountEditMFA": 153,
+	"routes.AccountEditMFASetup": 154,
+	"routes.AccountEditMFASetupSubmit": 155,
+	"routes.AccountEditMFADisableSubmit": 156,
+	"routes.AccountEditEmail": 157,
+	"routes.AccountEditEmailTokenSubmit": 158,
+	"routes.AccountLogins": 159,
+	"routes.AccountBlocked": 160,
+	"routes.AccountDrafts": 161,
+	"routes.AccountDraftsDeleteSubmit": 162,
+	"routes.DraftSaveSubmit": 163,
+	"routes.LevelList": 164,
+	"routes.Convos": 165,
+	"routes.ConvosCreate": 166,
+	"routes.Convo": 167,
+	"routes.ConvosCreateSubmit": 168,
+	"routes.ConvosCreateReplySubmit": 169,
+	"routes.ConvosDeleteReplySubmit": 170,
+	"routes.ConvosEditReplySubmit": 171,
+	"routes.RelationsBlockCreate": 172,
+	"routes.RelationsBlockCreateSubmit": 173,
+	"routes.RelationsBlockRemove": 174,
+	"routes.RelationsBlockRemoveSubmit": 175,
+	"routes.ViewProfile": 176,
+	"routes.BanUserSubmit": 177,
+	"routes.UnbanUser": 178,
+	"routes.ActivateUser": 179,
+	"routes.IPSearch": 180,
+	"routes.DeletePostsSubmit": 181,
+	"routes.CreateTopicSubmit": 182,
+	"routes.EditTopicSubmit": 183,
+	"routes.DeleteTopicSubmit": 184,
+	"routes.RestoreTopicSubmit": 185,
+	"routes.StickTopicSubmit": 186,
+	"routes.UnstickTopicSubmit": 187,
+	"routes.LockTopicSubmit": 188,
+	"routes.UnlockTopicSubmit": 189,
+	"routes.MoveTopicSubmit": 190,
+	"routes.MergeTopicSubmit": 191,
+	"routes.SplitTopicSubmit": 192,
+	"routes.MoveRepliesSubmit": 193,
+	"routes.LikeTopicSubmit": 194,
+	"routes.UnlikeTopicSubmit": 195,
+	"routes.AddAttachToTopicSubmit": 196,
+	"routes.RemoveAttachFromTopicSubmit": 197,
+	"routes.TopicRevisions": 198,
+	"routes.TopicRevertSubmit": 199,
+	"routes.TopicTimedActions": 200,
+	"routes.TopicTimedActionCreateSubmit": 201,
+	"routes.TopicTimedActionDeleteSubmit": 202,
+	"routes.ViewTopic": 203,
+	"routes.CreateReplySubmit": 204,
+	"routes.ReplyEditSubmit": 205,
+	"routes.ReplyDeleteSubmit": 206,
+	"routes.ReplyRestoreSubmit": 207,
+	"routes.ReplyLikeSubmit": 208,
+	"routes.ReplyUnlikeSubmit": 209,
+	"routes.ReplyUpvoteSubmit": 210,
+	"routes.ReplyDownvoteSubmit": 211,
+	"routes.ReplyAcceptSubmit": 212,
+	"routes.ReplyUnacceptSubmit": 213,
+	"routes.AddAttachToReplySubmit": 214,
+	"routes.RemoveAttachFromReplySubmit": 215,
+	"routes.ReplyRevisions": 216,
+	"routes.ReplyRevertSubmit": 217,
+	"routes.ProfileReplyCreateSubmit": 218,
+	"routes.ProfileReplyEditSubmit": 219,
+	"routes.ProfileReplyDeleteSubmit": 220,
+	"routes.PollVote": 221,
+	"routes.PollResults": 222,
+	"routes.AccountLogin": 223,
+	"routes.AccountRegister": 224,
+	"routes.AccountLogout": 225,
+	"routes.AccountLoginSubmit": 226,
+	"routes.AccountLoginMFAVerify": 227,
+	"routes.AccountLoginMFAVerifySubmit": 228,
+	"routes.AccountRegisterSubmit": 229,
+	"routes.AccountPasswordReset": 230,
+	"routes.AccountPasswordResetSubmit": 231,
+	"routes.AccountPasswordResetToken": 232,
+	"routes.AccountPasswordResetTokenSubmit": 233,
+	"routes.DynamicRoute": 234,
+	"routes.UploadedFile": 235,
+	"routes.StaticFile": 236,
+	"routes.RobotsTxt": 237,
+	"routes.SitemapXml": 238,
+	"routes.OpenSearchXml": 239,
+	"routes.Favicon": 240,
+	"routes.BadRoute": 241,
+	"routes.HTTPSRedirect": 242,
 }
 var reverseRouteMapEnum = map[int]string{ 
 	0: "routes.Error",
@@ -390,177 +524,244 @@ var reverseRouteMapEnum = map[int]string{
 	2: "routes.CustomPage",
 	3: "routes.ForumList",
 	4: "routes.ViewForum",
-	5: "routes.ChangeTheme",
-	6: "routes.ShowAttachment",
-	7: "common.RouteWebsockets",
-	8: "routeAPIPhrases",
-	9: "routes.APIMe",
-	10: "routeJSAntispam",
-	11: "routeAPI",
-	12: "routes.ReportSubmit",
-	13: "routes.TopicListMostViewed",
-	14: "routes.TopicListWeekViews",
-	15: "routes.CreateTopic",
-	16: "routes.TopicList",
-	17: "panel.Forums",
-	18: "panel.ForumsCreateSubmit",
-	19: "panel.ForumsDelete",
-	20: "panel.ForumsDeleteSubmit",
-	21: "panel.ForumsOrderSubmit",
-	22: "panel.ForumsEdit",
-	23: "panel.ForumsEditSubmit",
-	24: "panel.ForumsEditPermsSubmit",
-	25: "panel.ForumsEditPermsAdvance",
-	26: "panel.ForumsEditPermsAdvanceSubmit",
-	27: "panel.Settings",
-	28: "panel.SettingEdit",
-	29: "panel.SettingEditSubmit",
-	30: "panel.WordFilters",
-	31: "panel.WordFiltersCreateSubmit",
-	32: "panel.WordFiltersEdit",
-	33: "panel.WordFiltersEditSubmit",
-	34: "panel.WordFiltersDeleteSubmit",
-	35: "panel.Pages",
-	36: "panel.PagesCreateSubmit",
-	37: "panel.PagesEdit",
-	38: "panel.PagesEditSubmit",
-	39: "panel.PagesDeleteSubmit",
-	40: "panel.Themes",
-	41: "panel.ThemesSetDefault",
-	42: "panel.ThemesMenus",
-	43: "panel.ThemesMenusEdit",
-	44: "panel.ThemesMenuItemEdit",
-	45: "panel.ThemesMenuItemEditSubmit",
-	46: "panel.ThemesMenuItemCreateSubmit",
-	47: "panel.ThemesMenuItemDeleteSubmit",
-	48: "panel.ThemesMenuItemOrderSubmit",
-	49: "panel.ThemesWidgets",
-	50: "panel.ThemesWidgetsEditSubmit",
-	51: "panel.ThemesWidgetsCreateSubmit",
-	52: "panel.ThemesWidgetsDeleteSubmit",
-	53: "panel.Plugins",
-	54: "panel.PluginsActivate",
-	55: "panel.PluginsDeactivate",
-	56: "panel.PluginsInstall",
-	57: "panel.Users",
-	58: "panel.UsersEdit",
-	59: "panel.UsersEditSubmit",
-	60: "panel.UsersAvatarSubmit",
-	61: "panel.UsersAvatarRemoveSubmit",
-	62: "panel.AnalyticsViews",
-	63: "panel.AnalyticsRoutes",
-	64: "panel.AnalyticsRoutesPerf",
-	65: "panel.AnalyticsAgents",
-	66: "panel.AnalyticsSystems",
-	67: "panel.AnalyticsLanguages",
-	68: "panel.AnalyticsReferrers",
-	69: "panel.AnalyticsRouteViews",
-	70: "panel.AnalyticsAgentViews",
-	71: "panel.AnalyticsForumViews",
-	72: "panel.AnalyticsSystemViews",
-	73: "panel.AnalyticsLanguageViews",
-	74: "panel.AnalyticsReferrerViews",
-	75: "panel.AnalyticsPosts",
-	76: "panel.AnalyticsMemory",
-	77: "panel.AnalyticsActiveMemory",
-	78: "panel.AnalyticsTopics",
-	79: "panel.AnalyticsForums",
-	80: "panel.AnalyticsPerf",
-	81: "panel.Groups",
-	82: "panel.GroupsEdit",
-	83: "panel.GroupsEditPromotions",
-	84: "panel.GroupsPromotionsCreateSubmit",
-	85: "panel.GroupsPromotionsDeleteSubmit",
-	86: "panel.GroupsEditPerms",
-	87: "panel.GroupsEditSubmit",
-	88: "panel.GroupsEditPermsSubmit",
-	89: "panel.GroupsCreateSubmit",
-	90: "panel.Backups",
-	91: "panel.LogsRegs",
-	92: "panel.LogsMod",
-	93: "panel.LogsAdmin",
-	94: "panel.Debug",
-	95: "panel.DebugTasks",
-	96: "panel.Dashboard",
-	97: "routes.AccountEdit",
-	98: "routes.AccountEditPassword",
-	99: "routes.AccountEditPasswordSubmit",
-	100: "routes.AccountEditAvatarSubmit",
-	101: "routes.AccountEditRevokeAvatarSubmit",
-	102: "routes.AccountEditUsernameSubmit",
-	103: "routes.AccountEditPrivacy",
-	104: "routes.AccountEditPrivacySubmit",
-	105: "routes.AccountEditMFA",
-	106: "routes.AccountEditMFASetup",
-	107: "routes.AccountEditMFASetupSubmit",
-	108: "routes.AccountEditMFADisableSubmit",
-	109: "routes.AccountEditEmail",
-	110: "routes.AccountEditEmailTokenSubmit",
-	111: "routes.AccountLogins",
-	112: "routes.AccountBlocked",
-	113: "routes.LevelList",
-	114: "routes.Convos",
-	115: "routes.ConvosCreate",
-	116: "routes.Convo",
-	117: "routes.ConvosCreateSubmit",
-	118: "routes.ConvosCreateReplySubmit",
-	119: "routes.ConvosDeleteReplySubmit",
-	120: "routes.ConvosEditReplySubmit",
-	121: "routes.RelationsBlockCreate",
-	122: "routes.RelationsBlockCreateSubmit",
-	123: "routes.RelationsBlockRemove",
-	124: "routes.RelationsBlockRemoveSubmit",
-	125: "routes.ViewProfile",
-	126: "routes.BanUserSubmit",
-	127: "routes.UnbanUser",
-	128: "routes.ActivateUser",
-	129: "routes.IPSearch",
-	130: "routes.DeletePostsSubmit",
-	131: "routes.CreateTopicSubmit",
-	132: "routes.EditTopicSubmit",
-	133: "routes.DeleteTopicSubmit",
-	134: "routes.StickTopicSubmit",
-	135: "routes.UnstickTopicSubmit",
-	136: "routes.LockTopicSubmit",
-	137: "routes.UnlockTopicSubmit",
-	138: "routes.MoveTopicSubmit",
-	139: "routes.LikeTopicSubmit",
-	140: "routes.UnlikeTopicSubmit",
-	141: "routes.AddAttachToTopicSubmit",
-	142: "routes.RemoveAttachFromTopicSubmit",
-	143: "routes.ViewTopic",
-	144: "routes.CreateReplySubmit",
-	145: "routes.ReplyEditSubmit",
-	146: "routes.ReplyDeleteSubmit",
-	147: "routes.ReplyLikeSubmit",
-	148: "routes.ReplyUnlikeSubmit",
-	149: "routes.AddAttachToReplySubmit",
-	150: "routes.RemoveAttachFromReplySubmit",
-	151: "routes.ProfileReplyCreateSubmit",
-	152: "routes.ProfileReplyEditSubmit",
-	153: "routes.ProfileReplyDeleteSubmit",
-	154: "routes.PollVote",
-	155: "routes.PollResults",
-	156: "routes.AccountLogin",
-	157: "routes.AccountRegister",
-	158: "routes.AccountLogout",
-	159: "routes.AccountLoginSubmit",
-	160: "routes.AccountLoginMFAVerify",
-	161: "routes.AccountLoginMFAVerifySubmit",
-	162: "routes.AccountRegisterSubmit",
-	163: "routes.AccountPasswordReset",
-	164: "routes.AccountPasswordResetSubmit",
-	165: "routes.AccountPasswordResetToken",
-	166: "routes.AccountPasswordResetTokenSubmit",
-	167: "routes.DynamicRoute",
-	168: "routes.UploadedFile",
-	169: "routes.StaticFile",
-	170: "routes.RobotsTxt",
-	171: "routes.SitemapXml",
-	172: "routes.OpenSearchXml",
-	173: "routes.Favicon",
-	174: "routes.BadRoute",
-	175: "routes.HTTPSRedirect",
+	5: "routes.TopicListByTag",
+	6: "routes.ChangeTheme",
+	7: "routes.ShowAttachment",
+	8: "common.RouteWebsockets",
+	9: "routeAPIPhrases",
+	10: "routes.APIMe",
+	11: "routes.APIUserFields",
+	12: "routes.APITagSuggest",
+	13: "routes.APIDraft",
+	14: "routeJSAntispam",
+	15: "routeAPI",
+	16: "routes.ReportSubmit",
+	17: "routes.TopicListMostViewed",
+	18: "routes.TopicListWeekViews",
+	19: "routes.CreateTopic",
+	20: "routes.ScheduledTopics",
+	21: "routes.ScheduledTopicDeleteSubmit",
+	22: "routes.ScheduledTopicPublishSubmit",
+	23: "routes.TopicList",
+	24: "panel.Forums",
+	25: "panel.ForumsCreateSubmit",
+	26: "panel.ForumsDelete",
+	27: "panel.ForumsDeleteSubmit",
+	28: "panel.ForumsOrderSubmit",
+	29: "panel.ForumsEdit",
+	30: "panel.ForumsEditSubmit",
+	31: "panel.ForumsEditPermsSubmit",
+	32: "panel.ForumsEditModSubmit",
+	33: "panel.ForumsEditModDeleteSubmit",
+	34: "panel.ForumsEditPermsAdvance",
+	35: "panel.ForumsEditPermsAdvanceSubmit",
+	36: "panel.Settings",
+	37: "panel.SettingEdit",
+	38: "panel.SettingEditSubmit",
+	39: "panel.WordFilters",
+	40: "panel.WordFiltersCreateSubmit",
+	41: "panel.WordFiltersEdit",
+	42: "panel.WordFiltersEditSubmit",
+	43: "panel.WordFiltersDeleteSubmit",
+	44: "panel.Phrases",
+	45: "panel.PhrasesEdit",
+	46: "panel.PhrasesCreateSubmit",
+	47: "panel.PhrasesEditSubmit",
+	48: "panel.PhrasesDeleteSubmit",
+	49: "panel.ProfileFields",
+	50: "panel.ProfileFieldsCreateSubmit",
+	51: "panel.ProfileFieldsEdit",
+	52: "panel.ProfileFieldsEditSubmit",
+	53: "panel.ProfileFieldsDeleteSubmit",
+	54: "panel.TopicPrefixes",
+	55: "panel.TopicPrefixesCreateSubmit",
+	56: "panel.TopicPrefixesEdit",
+	57: "panel.TopicPrefixesEditSubmit",
+	58: "panel.TopicPrefixesDeleteSubmit",
+	59: "panel.Trash",
+	60: "panel.TrashReplies",
+	61: "panel.TrashPurgeTopicSubmit",
+	62: "panel.TrashPurgeReplySubmit",
+	63: "panel.Pages",
+	64: "panel.PagesCreateSubmit",
+	65: "panel.PagesEdit",
+	66: "panel.PagesEditSubmit",
+	67: "panel.PagesDeleteSubmit",
+	68: "panel.Themes",
+	69: "panel.ThemesSetDefault",
+	70: "panel.ThemesEdit",
+	71: "panel.ThemesSettingsSubmit",
+	72: "panel.ThemesCreateChildSubmit",
+	73: "panel.ThemesExport",
+	74: "panel.ThemesImportSubmit",
+	75: "panel.ThemesFileEdit",
+	76: "panel.ThemesFileEditSubmit",
+	77: "panel.ThemesFilePreviewSubmit",
+	78: "panel.ThemesMenus",
+	79: "panel.ThemesMenusEdit",
+	80: "panel.ThemesMenuItemEdit",
+	81: "panel.ThemesMenuItemEditSubmit",
+	82: "panel.ThemesMenuItemCreateSubmit",
+	83: "panel.ThemesMenuItemDeleteSubmit",
+	84: "panel.ThemesMenuItemOrderSubmit",
+	85: "panel.ThemesWidgets",
+	86: "panel.ThemesWidgetsEditSubmit",
+	87: "panel.ThemesWidgetsCreateSubmit",
+	88: "panel.ThemesWidgetsDeleteSubmit",
+	89: "panel.Plugins",
+	90: "panel.PluginsActivate",
+	91: "panel.PluginsDeactivate",
+	92: "panel.PluginsInstall",
+	93: "panel.Users",
+	94: "panel.UsersEdit",
+	95: "panel.UsersEditSubmit",
+	96: "panel.UsersAvatarSubmit",
+	97: "panel.UsersAvatarRemoveSubmit",
+	98: "panel.AnalyticsViews",
+	99: "panel.AnalyticsRoutes",
+	100: "panel.AnalyticsRoutesPerf",
+	101: "panel.AnalyticsAgents",
+	102: "panel.AnalyticsSystems",
+	103: "panel.AnalyticsLanguages",
+	104: "panel.AnalyticsReferrers",
+	105: "panel.AnalyticsRouteViews",
+	106: "panel.AnalyticsAgentViews",
+	107: "panel.AnalyticsForumViews",
+	108: "panel.AnalyticsSystemViews",
+	109: "panel.AnalyticsLanguageViews",
+	110: "panel.AnalyticsReferrerViews",
+	111: "panel.AnalyticsPosts",
+	112: "panel.AnalyticsMemory",
+	113: "panel.AnalyticsActiveMemory",
+	114: "panel.AnalyticsTopics",
+	115: "panel.AnalyticsForums",
+	116: "panel.AnalyticsPerf",
+	117: "panel.Groups",
+	118: "panel.GroupsEdit",
+	119: "panel.GroupsEditPromotions",
+	120: "panel.GroupsPromotionsCreateSubmit",
+	121: "panel.GroupsPromotionsDeleteSubmit",
+	122: "panel.GroupsEditPerms",
+	123: "panel.GroupsEditSubmit",
+	124: "panel.GroupsEditPermsSubmit",
+	125: "panel.GroupsCreateSubmit",
+	126: "panel.Backups",
+	127: "panel.BackupsCreateSubmit",
+	128: "panel.BackupsRestoreSubmit",
+	129: "panel.LogsRegs",
+	130: "panel.LogsMod",
+	131: "panel.LogsAdmin",
+	132: "panel.Debug",
+	133: "panel.DebugTasks",
+	134: "panel.Dashboard",
+	135: "routes.AccountEdit",
+	136: "routes.AccountEditPassword",
+	137: "routes.AccountEditPasswordSubmit",
+	138: "routes.AccountEditAvatarSubmit",
+	139: "routes.AccountEditRevokeAvatarSubmit",
+	140: "routes.AccountEditUsernameSubmit",
+	141: "routes.AccountEditPrivacy",
+	142: "routes.AccountEditPrivacySubmit",
+	143: "routes.AccountEditFields",
+	144: "routes.AccountEditFieldsSubmit",
+	145: "routes.AccountEditTheme",
+	146: "routes.AccountEditThemeSubmit",
+	147: "routes.AccountEditLang",
+	148: "routes.AccountEditLangSubmit",
+	149: "routes.AccountEditData",
+	150: "routes.AccountEditDataExportSubmit",
+	151: "routes.AccountEditDataDeleteSubmit",
+	152: "routes.AccountEditDataDeleteCancelSubmit",
+	153: "routes.AccountEditMFA",
+	154: "routes.AccountEditMFASetup",
+	155: "routes.AccountEditMFASetupSubmit",
+	156: "routes.AccountEditMFADisableSubmit",
+	157: "routes.AccountEditEmail",
+	158: "routes.AccountEditEmailTokenSubmit",
+	159: "routes.AccountLogins",
+	160: "routes.AccountBlocked",
+	161: "routes.AccountDrafts",
+	162: "routes.AccountDraftsDeleteSubmit",
+	163: "routes.DraftSaveSubmit",
+	164: "routes.LevelList",
+	165: "routes.Convos",
+	166: "routes.ConvosCreate",
+	167: "routes.Convo",
+	168: "routes.ConvosCreateSubmit",
+	169: "routes.ConvosCreateReplySubmit",
+	170: "routes.ConvosDeleteReplySubmit",
+	171: "routes.ConvosEditReplySubmit",
+	172: "routes.RelationsBlockCreate",
+	173: "routes.RelationsBlockCreateSubmit",
+	174: "routes.RelationsBlockRemove",
+	175: "routes.RelationsBlockRemoveSubmit",
+	176: "routes.ViewProfile",
+	177: "routes.BanUserSubmit",
+	178: "routes.UnbanUser",
+	179: "routes.ActivateUser",
+	180: "routes.IPSearch",
+	181: "routes.DeletePostsSubmit",
+	182: "routes.CreateTopicSubmit",
+	183: "routes.EditTopicSubmit",
+	184: "routes.DeleteTopicSubmit",
+	185: "routes.RestoreTopicSubmit",
+	186: "routes.StickTopicSubmit",
+	187: "routes.UnstickTopicSubmit",
+	188: "routes.LockTopicSubmit",
+	189: "routes.UnlockTopicSubmit",
+	190: "routes.MoveTopicSubmit",
+	191: "routes.MergeTopicSubmit",
+	192: "routes.SplitTopicSubmit",
+	193: "routes.MoveRepliesSubmit",
+	194: "routes.LikeTopicSubmit",
+	195: "routes.UnlikeTopicSubmit",
+	196: "routes.AddAttachToTopicSubmit",
+	197: "routes.RemoveAttachFromTopicSubmit",
+	198: "routes.TopicRevisions",
+	199: "routes.TopicRevertSubmit",
+	200: "routes.TopicTimedActions",
+	201: "routes.TopicTimedActionCreateSubmit",
+	202: "routes.TopicTimedActionDeleteSubmit",
+	203: "routes.ViewTopic",
+	204: "routes.CreateReplySubmit",
+	205: "routes.ReplyEditSubmit",
+	206: "routes.ReplyDeleteSubmit",
+	207: "routes.ReplyRestoreSubmit",
+	208: "routes.ReplyLikeSubmit",
+	209: "routes.ReplyUnlikeSubmit",
+	210: "routes.ReplyUpvoteSubmit",
+	211: "routes.ReplyDownvoteSubmit",
+	212: "routes.ReplyAcceptSubmit",
+	213: "routes.ReplyUnacceptSubmit",
+	214: "routes.AddAttachToReplySubmit",
+	215: "routes.RemoveAttachFromReplySubmit",
+	216: "routes.ReplyRevisions",
+	217: "routes.ReplyRevertSubmit",
+	218: "routes.ProfileReplyCreateSubmit",
+	219: "routes.ProfileReplyEditSubmit",
+	220: "routes.ProfileReplyDeleteSubmit",
+	221: "routes.PollVote",
+	222: "routes.PollResults",
+	223: "routes.AccountLogin",
+	224: "routes.AccountRegister",
+	225: "routes.AccountLogout",
+	226: "routes.AccountLoginSubmit",
+	227: "routes.AccountLoginMFAVerify",
+	228: "routes.AccountLoginMFAVerifySubmit",
+	229: "routes.AccountRegisterSubmit",
+	230: "routes.AccountPasswordReset",
+	231: "routes.AccountPasswordResetSubmit",
+	232: "routes.AccountPasswordResetToken",
+	233: "routes.AccountPasswordResetTokenSubmit",
+	234: "routes.DynamicRoute",
+	235: "routes.UploadedFile",
+	236: "routes.StaticFile",
+	237: "routes.RobotsTxt",
+	238: "routes.SitemapXml",
+	239: "routes.OpenSearchXml",
+	240: "routes.Favicon",
+	241: "routes.BadRoute",
+	242: "routes.HTTPSRedirect",
 }
 var osMapEnum = map[string]int{ 
 	"unknown": 0,
@@ -915,7 +1116,7 @@ type HTTPSRedirect struct {}
 
 func (red *HTTPSRedirect) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	w.Header().Set("Connection", "close")
-	co.RouteViewCounter.Bump(175)
+	co.RouteViewCounter.Bump(242)
 	dest := "https://" + req.Host + req.URL.String()
 	http.Redirect(w, req, dest, http.StatusTemporaryRedirect)
 }
@@ -1154,7 +1355,7 @@ func (r *GenRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	
 	if prefix == "/s" { //old prefix: /static
 		if !c.Config.DisableAnalytics {
-			co.RouteViewCounter.Bump(169)
+			co.RouteViewCounter.Bump(236)
 		}
 		routes.StaticFile(w, req)
 		return
@@ -1465,6 +1666,13 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 			}
 			err = routes.ViewForum(w,req,user,h,extraData)
 			co.RouteViewCounter.Bump3(4, cn)
+		case "/tags":
+			h, err := c.UserCheckNano(w,req,user,cn)
+			if err != nil {
+				return err
+			}
+			err = routes.TopicListByTag(w,req,user,h,extraData)
+			co.RouteViewCounter.Bump3(5, cn)
 		case "/theme":
 				err = c.ParseForm(w,req,user)
 				if err != nil {
@@ -1472,7 +1680,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 				}
 				
 			err = routes.ChangeTheme(w,req,user)
-			co.RouteViewCounter.Bump3(5, cn)
+			co.RouteViewCounter.Bump3(6, cn)
 		case "/attachs":
 				err = c.ParseForm(w,req,user)
 				if err != nil {
@@ -1481,7 +1689,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 				
 					w = r.responseWriter(w)
 			err = routes.ShowAttachment(w,req,user,extraData)
-			co.RouteViewCounter.Bump3(6, cn)
+			co.RouteViewCounter.Bump3(7, cn)
 		case "/ws":
 					req.URL.Path += extraData
 			err = c.RouteWebsockets(w,req,user)
@@ -1489,16 +1697,25 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 			switch(req.URL.Path) {
 				case "/api/phrases/":
 					err = routeAPIPhrases(w,req,user)
-					co.RouteViewCounter.Bump3(8, cn)
+					co.RouteViewCounter.Bump3(9, cn)
 				case "/api/me/":
 					err = routes.APIMe(w,req,user)
-					co.RouteViewCounter.Bump3(9, cn)
+					co.RouteViewCounter.Bump3(10, cn)
+				case "/api/user/fields/":
+					err = routes.APIUserFields(w,req,user)
+					co.RouteViewCounter.Bump3(11, cn)
+				case "/api/tags/":
+					err = routes.APITagSuggest(w,req,user)
+					co.RouteViewCounter.Bump3(12, cn)
+				case "/api/draft/":
+					err = routes.APIDraft(w,req,user)
+					co.RouteViewCounter.Bump3(13, cn)
 				case "/api/watches/":
 					err = routeJSAntispam(w,req,user)
-					co.RouteViewCounter.Bump3(10, cn)
+					co.RouteViewCounter.Bump3(14, cn)
 				default:
 					err = routeAPI(w,req,user)
-			co.RouteViewCounter.Bump3(11, cn)
+			co.RouteViewCounter.Bump3(15, cn)
 			}
 		case "/report":
 			err = c.NoBanned(w,req,user)
@@ -1519,7 +1736,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ReportSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(12, cn)
+					co.RouteViewCounter.Bump3(16, cn)
 			}
 		case "/topics":
 			switch(req.URL.Path) {
@@ -1529,14 +1746,14 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.TopicListMostViewed(w,req,user,h)
-					co.RouteViewCounter.Bump3(13, cn)
+					co.RouteViewCounter.Bump3(17, cn)
 				case "/topics/week-views/":
 				h, err := c.UserCheckNano(w,req,user,cn)
 				if err != nil {
 					return err
 				}
 					err = routes.TopicListWeekViews(w,req,user,h)
-					co.RouteViewCounter.Bump3(14, cn)
+					co.RouteViewCounter.Bump3(18, cn)
 				case "/topics/create/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -1548,14 +1765,52 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.CreateTopic(w,req,user,h,extraData)
-					co.RouteViewCounter.Bump3(15, cn)
+					co.RouteViewCounter.Bump3(19, cn)
+				case "/topics/scheduled/":
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.ScheduledTopics(w,req,user,h)
+					co.RouteViewCounter.Bump3(20, cn)
+				case "/topics/scheduled/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ScheduledTopicDeleteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(21, cn)
+				case "/topics/scheduled/publish/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ScheduledTopicPublishSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(22, cn)
 				default:
 					h, err := c.UserCheckNano(w,req,user,cn)
 					if err != nil {
 						return err
 					}
 					err = routes.TopicList(w,req,user, h)
-			co.RouteViewCounter.Bump3(16, cn)
+			co.RouteViewCounter.Bump3(23, cn)
 			}
 		case "/panel":
 			err = c.SuperModOnly(w,req,user)
@@ -1566,7 +1821,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 			switch(req.URL.Path) {
 				case "/panel/forums/":
 					err = panel.Forums(w,req,user)
-					co.RouteViewCounter.Bump3(17, cn)
+					co.RouteViewCounter.Bump3(24, cn)
 				case "/panel/forums/create/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1574,7 +1829,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ForumsCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(18, cn)
+					co.RouteViewCounter.Bump3(25, cn)
 				case "/panel/forums/delete/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1582,7 +1837,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ForumsDelete(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(19, cn)
+					co.RouteViewCounter.Bump3(26, cn)
 				case "/panel/forums/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1590,7 +1845,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ForumsDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(20, cn)
+					co.RouteViewCounter.Bump3(27, cn)
 				case "/panel/forums/order/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1598,10 +1853,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ForumsOrderSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(21, cn)
+					co.RouteViewCounter.Bump3(28, cn)
 				case "/panel/forums/edit/":
 					err = panel.ForumsEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(22, cn)
+					co.RouteViewCounter.Bump3(29, cn)
 				case "/panel/forums/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1609,7 +1864,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ForumsEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(23, cn)
+					co.RouteViewCounter.Bump3(30, cn)
 				case "/panel/forums/edit/perms/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1617,10 +1872,26 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ForumsEditPermsSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(24, cn)
+					co.RouteViewCounter.Bump3(31, cn)
+				case "/panel/forums/edit/mods/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ForumsEditModSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(32, cn)
+				case "/panel/forums/edit/mods/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ForumsEditModDeleteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(33, cn)
 				case "/panel/forums/edit/perms/":
 					err = panel.ForumsEditPermsAdvance(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(25, cn)
+					co.RouteViewCounter.Bump3(34, cn)
 				case "/panel/forums/edit/perms/adv/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1628,13 +1899,13 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ForumsEditPermsAdvanceSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(26, cn)
+					co.RouteViewCounter.Bump3(35, cn)
 				case "/panel/settings/":
 					err = panel.Settings(w,req,user)
-					co.RouteViewCounter.Bump3(27, cn)
+					co.RouteViewCounter.Bump3(36, cn)
 				case "/panel/settings/edit/":
 					err = panel.SettingEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(28, cn)
+					co.RouteViewCounter.Bump3(37, cn)
 				case "/panel/settings/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1642,10 +1913,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.SettingEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(29, cn)
+					co.RouteViewCounter.Bump3(38, cn)
 				case "/panel/settings/word-filters/":
 					err = panel.WordFilters(w,req,user)
-					co.RouteViewCounter.Bump3(30, cn)
+					co.RouteViewCounter.Bump3(39, cn)
 				case "/panel/settings/word-filters/create/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1653,10 +1924,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.WordFiltersCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(31, cn)
+					co.RouteViewCounter.Bump3(40, cn)
 				case "/panel/settings/word-filters/edit/":
 					err = panel.WordFiltersEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(32, cn)
+					co.RouteViewCounter.Bump3(41, cn)
 				case "/panel/settings/word-filters/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1664,7 +1935,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.WordFiltersEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(33, cn)
+					co.RouteViewCounter.Bump3(42, cn)
 				case "/panel/settings/word-filters/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1672,7 +1943,119 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.WordFiltersDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(34, cn)
+					co.RouteViewCounter.Bump3(43, cn)
+				case "/panel/phrases/":
+					err = panel.Phrases(w,req,user)
+					co.RouteViewCounter.Bump3(44, cn)
+				case "/panel/phrases/edit/":
+					err = panel.PhrasesEdit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(45, cn)
+				case "/panel/phrases/create/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.PhrasesCreateSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(46, cn)
+				case "/panel/phrases/edit/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.PhrasesEditSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(47, cn)
+				case "/panel/phrases/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.PhrasesDeleteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(48, cn)
+				case "/panel/profile-fields/":
+					err = panel.ProfileFields(w,req,user)
+					co.RouteViewCounter.Bump3(49, cn)
+				case "/panel/profile-fields/create/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ProfileFieldsCreateSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(50, cn)
+				case "/panel/profile-fields/edit/":
+					err = panel.ProfileFieldsEdit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(51, cn)
+				case "/panel/profile-fields/edit/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ProfileFieldsEditSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(52, cn)
+				case "/panel/profile-fields/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ProfileFieldsDeleteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(53, cn)
+				case "/panel/topic-prefixes/":
+					err = panel.TopicPrefixes(w,req,user)
+					co.RouteViewCounter.Bump3(54, cn)
+				case "/panel/topic-prefixes/create/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.TopicPrefixesCreateSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(55, cn)
+				case "/panel/topic-prefixes/edit/":
+					err = panel.TopicPrefixesEdit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(56, cn)
+				case "/panel/topic-prefixes/edit/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.TopicPrefixesEditSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(57, cn)
+				case "/panel/topic-prefixes/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.TopicPrefixesDeleteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(58, cn)
+				case "/panel/trash/":
+					err = panel.Trash(w,req,user)
+					co.RouteViewCounter.Bump3(59, cn)
+				case "/panel/trash/replies/":
+					err = panel.TrashReplies(w,req,user)
+					co.RouteViewCounter.Bump3(60, cn)
+				case "/panel/trash/purge/topic/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.TrashPurgeTopicSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(61, cn)
+				case "/panel/trash/purge/reply/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.TrashPurgeReplySubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(62, cn)
 				case "/panel/pages/":
 					err = c.AdminOnly(w,req,user)
 					if err != nil {
@@ -1680,7 +2063,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.Pages(w,req,user)
-					co.RouteViewCounter.Bump3(35, cn)
+					co.RouteViewCounter.Bump3(63, cn)
 				case "/panel/pages/create/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1693,7 +2076,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.PagesCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(36, cn)
+					co.RouteViewCounter.Bump3(64, cn)
 				case "/panel/pages/edit/":
 					err = c.AdminOnly(w,req,user)
 					if err != nil {
@@ -1701,7 +2084,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.PagesEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(37, cn)
+					co.RouteViewCounter.Bump3(65, cn)
 				case "/panel/pages/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1714,7 +2097,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.PagesEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(38, cn)
+					co.RouteViewCounter.Bump3(66, cn)
 				case "/panel/pages/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1727,10 +2110,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.PagesDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(39, cn)
+					co.RouteViewCounter.Bump3(67, cn)
 				case "/panel/themes/":
 					err = panel.Themes(w,req,user)
-					co.RouteViewCounter.Bump3(40, cn)
+					co.RouteViewCounter.Bump3(68, cn)
 				case "/panel/themes/default/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1738,16 +2121,89 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesSetDefault(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(41, cn)
+					co.RouteViewCounter.Bump3(69, cn)
+				case "/panel/themes/edit/":
+					err = panel.ThemesEdit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(70, cn)
+				case "/panel/themes/settings/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ThemesSettingsSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(71, cn)
+				case "/panel/themes/create/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ThemesCreateChildSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(72, cn)
+				case "/panel/themes/export/":
+					err = panel.ThemesExport(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(73, cn)
+				case "/panel/themes/import/submit/":
+					err = c.HandleUploadRoute(w,req,user,int(c.Config.MaxRequestSize))
+					if err != nil {
+					return err
+					}
+					err = c.NoUploadSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.AdminOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ThemesImportSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(74, cn)
+				case "/panel/themes/file/edit/":
+					err = c.AdminOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ThemesFileEdit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(75, cn)
+				case "/panel/themes/file/edit/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.AdminOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ThemesFileEditSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(76, cn)
+				case "/panel/themes/file/preview/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.AdminOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.ThemesFilePreviewSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(77, cn)
 				case "/panel/themes/menus/":
 					err = panel.ThemesMenus(w,req,user)
-					co.RouteViewCounter.Bump3(42, cn)
+					co.RouteViewCounter.Bump3(78, cn)
 				case "/panel/themes/menus/edit/":
 					err = panel.ThemesMenusEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(43, cn)
+					co.RouteViewCounter.Bump3(79, cn)
 				case "/panel/themes/menus/item/edit/":
 					err = panel.ThemesMenuItemEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(44, cn)
+					co.RouteViewCounter.Bump3(80, cn)
 				case "/panel/themes/menus/item/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1755,7 +2211,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesMenuItemEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(45, cn)
+					co.RouteViewCounter.Bump3(81, cn)
 				case "/panel/themes/menus/item/create/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1763,7 +2219,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesMenuItemCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(46, cn)
+					co.RouteViewCounter.Bump3(82, cn)
 				case "/panel/themes/menus/item/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1771,7 +2227,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesMenuItemDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(47, cn)
+					co.RouteViewCounter.Bump3(83, cn)
 				case "/panel/themes/menus/item/order/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1779,10 +2235,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesMenuItemOrderSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(48, cn)
+					co.RouteViewCounter.Bump3(84, cn)
 				case "/panel/themes/widgets/":
 					err = panel.ThemesWidgets(w,req,user)
-					co.RouteViewCounter.Bump3(49, cn)
+					co.RouteViewCounter.Bump3(85, cn)
 				case "/panel/themes/widgets/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1790,7 +2246,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesWidgetsEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(50, cn)
+					co.RouteViewCounter.Bump3(86, cn)
 				case "/panel/themes/widgets/create/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1798,7 +2254,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesWidgetsCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(51, cn)
+					co.RouteViewCounter.Bump3(87, cn)
 				case "/panel/themes/widgets/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1806,10 +2262,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.ThemesWidgetsDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(52, cn)
+					co.RouteViewCounter.Bump3(88, cn)
 				case "/panel/plugins/":
 					err = panel.Plugins(w,req,user)
-					co.RouteViewCounter.Bump3(53, cn)
+					co.RouteViewCounter.Bump3(89, cn)
 				case "/panel/plugins/activate/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1817,7 +2273,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.PluginsActivate(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(54, cn)
+					co.RouteViewCounter.Bump3(90, cn)
 				case "/panel/plugins/deactivate/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1825,7 +2281,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.PluginsDeactivate(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(55, cn)
+					co.RouteViewCounter.Bump3(91, cn)
 				case "/panel/plugins/install/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1833,13 +2289,13 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.PluginsInstall(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(56, cn)
+					co.RouteViewCounter.Bump3(92, cn)
 				case "/panel/users/":
 					err = panel.Users(w,req,user)
-					co.RouteViewCounter.Bump3(57, cn)
+					co.RouteViewCounter.Bump3(93, cn)
 				case "/panel/users/edit/":
 					err = panel.UsersEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(58, cn)
+					co.RouteViewCounter.Bump3(94, cn)
 				case "/panel/users/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1847,7 +2303,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.UsersEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(59, cn)
+					co.RouteViewCounter.Bump3(95, cn)
 				case "/panel/users/avatar/submit/":
 					err = c.HandleUploadRoute(w,req,user,int(c.Config.MaxRequestSize))
 					if err != nil {
@@ -1859,7 +2315,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.UsersAvatarSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(60, cn)
+					co.RouteViewCounter.Bump3(96, cn)
 				case "/panel/users/avatar/remove/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -1867,7 +2323,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.UsersAvatarRemoveSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(61, cn)
+					co.RouteViewCounter.Bump3(97, cn)
 				case "/panel/analytics/views/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1875,7 +2331,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsViews(w,req,user)
-					co.RouteViewCounter.Bump3(62, cn)
+					co.RouteViewCounter.Bump3(98, cn)
 				case "/panel/analytics/routes/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1883,7 +2339,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsRoutes(w,req,user)
-					co.RouteViewCounter.Bump3(63, cn)
+					co.RouteViewCounter.Bump3(99, cn)
 				case "/panel/analytics/routes-perf/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1891,7 +2347,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsRoutesPerf(w,req,user)
-					co.RouteViewCounter.Bump3(64, cn)
+					co.RouteViewCounter.Bump3(100, cn)
 				case "/panel/analytics/agents/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1899,7 +2355,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsAgents(w,req,user)
-					co.RouteViewCounter.Bump3(65, cn)
+					co.RouteViewCounter.Bump3(101, cn)
 				case "/panel/analytics/systems/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1907,7 +2363,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsSystems(w,req,user)
-					co.RouteViewCounter.Bump3(66, cn)
+					co.RouteViewCounter.Bump3(102, cn)
 				case "/panel/analytics/langs/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1915,7 +2371,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsLanguages(w,req,user)
-					co.RouteViewCounter.Bump3(67, cn)
+					co.RouteViewCounter.Bump3(103, cn)
 				case "/panel/analytics/referrers/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1923,25 +2379,25 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsReferrers(w,req,user)
-					co.RouteViewCounter.Bump3(68, cn)
+					co.RouteViewCounter.Bump3(104, cn)
 				case "/panel/analytics/route/":
 					err = panel.AnalyticsRouteViews(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(69, cn)
+					co.RouteViewCounter.Bump3(105, cn)
 				case "/panel/analytics/agent/":
 					err = panel.AnalyticsAgentViews(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(70, cn)
+					co.RouteViewCounter.Bump3(106, cn)
 				case "/panel/analytics/forum/":
 					err = panel.AnalyticsForumViews(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(71, cn)
+					co.RouteViewCounter.Bump3(107, cn)
 				case "/panel/analytics/system/":
 					err = panel.AnalyticsSystemViews(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(72, cn)
+					co.RouteViewCounter.Bump3(108, cn)
 				case "/panel/analytics/lang/":
 					err = panel.AnalyticsLanguageViews(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(73, cn)
+					co.RouteViewCounter.Bump3(109, cn)
 				case "/panel/analytics/referrer/":
 					err = panel.AnalyticsReferrerViews(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(74, cn)
+					co.RouteViewCounter.Bump3(110, cn)
 				case "/panel/analytics/posts/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1949,7 +2405,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsPosts(w,req,user)
-					co.RouteViewCounter.Bump3(75, cn)
+					co.RouteViewCounter.Bump3(111, cn)
 				case "/panel/analytics/memory/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1957,7 +2413,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsMemory(w,req,user)
-					co.RouteViewCounter.Bump3(76, cn)
+					co.RouteViewCounter.Bump3(112, cn)
 				case "/panel/analytics/active-memory/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1965,7 +2421,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsActiveMemory(w,req,user)
-					co.RouteViewCounter.Bump3(77, cn)
+					co.RouteViewCounter.Bump3(113, cn)
 				case "/panel/analytics/topics/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1973,7 +2429,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsTopics(w,req,user)
-					co.RouteViewCounter.Bump3(78, cn)
+					co.RouteViewCounter.Bump3(114, cn)
 				case "/panel/analytics/forums/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1981,7 +2437,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsForums(w,req,user)
-					co.RouteViewCounter.Bump3(79, cn)
+					co.RouteViewCounter.Bump3(115, cn)
 				case "/panel/analytics/perf/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -1989,16 +2445,16 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.AnalyticsPerf(w,req,user)
-					co.RouteViewCounter.Bump3(80, cn)
+					co.RouteViewCounter.Bump3(116, cn)
 				case "/panel/groups/":
 					err = panel.Groups(w,req,user)
-					co.RouteViewCounter.Bump3(81, cn)
+					co.RouteViewCounter.Bump3(117, cn)
 				case "/panel/groups/edit/":
 					err = panel.GroupsEdit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(82, cn)
+					co.RouteViewCounter.Bump3(118, cn)
 				case "/panel/groups/edit/promotions/":
 					err = panel.GroupsEditPromotions(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(83, cn)
+					co.RouteViewCounter.Bump3(119, cn)
 				case "/panel/groups/promotions/create/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2006,7 +2462,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.GroupsPromotionsCreateSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(84, cn)
+					co.RouteViewCounter.Bump3(120, cn)
 				case "/panel/groups/promotions/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2014,10 +2470,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.GroupsPromotionsDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(85, cn)
+					co.RouteViewCounter.Bump3(121, cn)
 				case "/panel/groups/edit/perms/":
 					err = panel.GroupsEditPerms(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(86, cn)
+					co.RouteViewCounter.Bump3(122, cn)
 				case "/panel/groups/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2025,7 +2481,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.GroupsEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(87, cn)
+					co.RouteViewCounter.Bump3(123, cn)
 				case "/panel/groups/edit/perms/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2033,7 +2489,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.GroupsEditPermsSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(88, cn)
+					co.RouteViewCounter.Bump3(124, cn)
 				case "/panel/groups/create/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2041,7 +2497,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.GroupsCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(89, cn)
+					co.RouteViewCounter.Bump3(125, cn)
 				case "/panel/backups/":
 					err = c.SuperAdminOnly(w,req,user)
 					if err != nil {
@@ -2050,16 +2506,42 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					
 					w = r.responseWriter(w)
 					err = panel.Backups(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(90, cn)
+					co.RouteViewCounter.Bump3(126, cn)
+				case "/panel/backups/create/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.SuperAdminOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.BackupsCreateSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(127, cn)
+				case "/panel/backups/restore/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.SuperAdminOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = panel.BackupsRestoreSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(128, cn)
 				case "/panel/logs/regs/":
 					err = panel.LogsRegs(w,req,user)
-					co.RouteViewCounter.Bump3(91, cn)
+					co.RouteViewCounter.Bump3(129, cn)
 				case "/panel/logs/mod/":
 					err = panel.LogsMod(w,req,user)
-					co.RouteViewCounter.Bump3(92, cn)
+					co.RouteViewCounter.Bump3(130, cn)
 				case "/panel/logs/admin/":
 					err = panel.LogsAdmin(w,req,user)
-					co.RouteViewCounter.Bump3(93, cn)
+					co.RouteViewCounter.Bump3(131, cn)
 				case "/panel/debug/":
 					err = c.AdminOnly(w,req,user)
 					if err != nil {
@@ -2067,7 +2549,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.Debug(w,req,user)
-					co.RouteViewCounter.Bump3(94, cn)
+					co.RouteViewCounter.Bump3(132, cn)
 				case "/panel/debug/tasks/":
 					err = c.AdminOnly(w,req,user)
 					if err != nil {
@@ -2075,10 +2557,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = panel.DebugTasks(w,req,user)
-					co.RouteViewCounter.Bump3(95, cn)
+					co.RouteViewCounter.Bump3(133, cn)
 				default:
 					err = panel.Dashboard(w,req,user)
-			co.RouteViewCounter.Bump3(96, cn)
+			co.RouteViewCounter.Bump3(134, cn)
 			}
 		case "/user":
 			switch(req.URL.Path) {
@@ -2093,7 +2575,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountEdit(w,req,user,h)
-					co.RouteViewCounter.Bump3(97, cn)
+					co.RouteViewCounter.Bump3(135, cn)
 				case "/user/edit/password/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2105,7 +2587,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountEditPassword(w,req,user,h)
-					co.RouteViewCounter.Bump3(98, cn)
+					co.RouteViewCounter.Bump3(136, cn)
 				case "/user/edit/password/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2118,7 +2600,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountEditPasswordSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(99, cn)
+					co.RouteViewCounter.Bump3(137, cn)
 				case "/user/edit/avatar/submit/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2135,7 +2617,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountEditAvatarSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(100, cn)
+					co.RouteViewCounter.Bump3(138, cn)
 				case "/user/edit/avatar/revoke/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2148,7 +2630,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountEditRevokeAvatarSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(101, cn)
+					co.RouteViewCounter.Bump3(139, cn)
 				case "/user/edit/username/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2161,7 +2643,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountEditUsernameSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(102, cn)
+					co.RouteViewCounter.Bump3(140, cn)
 				case "/user/edit/privacy/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2173,7 +2655,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountEditPrivacy(w,req,user,h)
-					co.RouteViewCounter.Bump3(103, cn)
+					co.RouteViewCounter.Bump3(141, cn)
 				case "/user/edit/privacy/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2186,7 +2668,133 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountEditPrivacySubmit(w,req,user)
-					co.RouteViewCounter.Bump3(104, cn)
+					co.RouteViewCounter.Bump3(142, cn)
+				case "/user/edit/fields/":
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.AccountEditFields(w,req,user,h)
+					co.RouteViewCounter.Bump3(143, cn)
+				case "/user/edit/fields/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.AccountEditFieldsSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(144, cn)
+				case "/user/edit/theme/":
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.AccountEditTheme(w,req,user,h)
+					co.RouteViewCounter.Bump3(145, cn)
+				case "/user/edit/theme/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.AccountEditThemeSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(146, cn)
+				case "/user/edit/lang/":
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.AccountEditLang(w,req,user,h)
+					co.RouteViewCounter.Bump3(147, cn)
+				case "/user/edit/lang/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.AccountEditLangSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(148, cn)
+				case "/user/edit/data/":
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.AccountEditData(w,req,user,h)
+					co.RouteViewCounter.Bump3(149, cn)
+				case "/user/edit/data/export/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.AccountEditDataExportSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(150, cn)
+				case "/user/edit/data/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.AccountEditDataDeleteSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(151, cn)
+				case "/user/edit/data/delete/cancel/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.AccountEditDataDeleteCancelSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(152, cn)
 				case "/user/edit/mfa/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2198,7 +2806,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountEditMFA(w,req,user,h)
-					co.RouteViewCounter.Bump3(105, cn)
+					co.RouteViewCounter.Bump3(153, cn)
 				case "/user/edit/mfa/setup/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2210,7 +2818,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountEditMFASetup(w,req,user,h)
-					co.RouteViewCounter.Bump3(106, cn)
+					co.RouteViewCounter.Bump3(154, cn)
 				case "/user/edit/mfa/setup/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2223,7 +2831,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountEditMFASetupSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(107, cn)
+					co.RouteViewCounter.Bump3(155, cn)
 				case "/user/edit/mfa/disable/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2236,7 +2844,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountEditMFADisableSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(108, cn)
+					co.RouteViewCounter.Bump3(156, cn)
 				case "/user/edit/email/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2248,10 +2856,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountEditEmail(w,req,user,h)
-					co.RouteViewCounter.Bump3(109, cn)
+					co.RouteViewCounter.Bump3(157, cn)
 				case "/user/edit/token/":
 					err = routes.AccountEditEmailTokenSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(110, cn)
+					co.RouteViewCounter.Bump3(158, cn)
 				case "/user/edit/logins/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2263,7 +2871,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountLogins(w,req,user,h)
-					co.RouteViewCounter.Bump3(111, cn)
+					co.RouteViewCounter.Bump3(159, cn)
 				case "/user/edit/blocked/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2275,7 +2883,45 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountBlocked(w,req,user,h)
-					co.RouteViewCounter.Bump3(112, cn)
+					co.RouteViewCounter.Bump3(160, cn)
+				case "/user/edit/drafts/":
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.AccountDrafts(w,req,user,h)
+					co.RouteViewCounter.Bump3(161, cn)
+				case "/user/edit/drafts/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.AccountDraftsDeleteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(162, cn)
+				case "/user/drafts/save/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.DraftSaveSubmit(w,req,user)
+					co.RouteViewCounter.Bump3(163, cn)
 				case "/user/levels/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2287,7 +2933,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.LevelList(w,req,user,h)
-					co.RouteViewCounter.Bump3(113, cn)
+					co.RouteViewCounter.Bump3(164, cn)
 				case "/user/convos/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2299,7 +2945,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.Convos(w,req,user,h)
-					co.RouteViewCounter.Bump3(114, cn)
+					co.RouteViewCounter.Bump3(165, cn)
 				case "/user/convos/create/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2311,7 +2957,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.ConvosCreate(w,req,user,h)
-					co.RouteViewCounter.Bump3(115, cn)
+					co.RouteViewCounter.Bump3(166, cn)
 				case "/user/convo/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2323,7 +2969,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.Convo(w,req,user,h,extraData)
-					co.RouteViewCounter.Bump3(116, cn)
+					co.RouteViewCounter.Bump3(167, cn)
 				case "/user/convos/create/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2336,7 +2982,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ConvosCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(117, cn)
+					co.RouteViewCounter.Bump3(168, cn)
 				case "/user/convo/create/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2349,7 +2995,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ConvosCreateReplySubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(118, cn)
+					co.RouteViewCounter.Bump3(169, cn)
 				case "/user/convo/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2362,7 +3008,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ConvosDeleteReplySubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(119, cn)
+					co.RouteViewCounter.Bump3(170, cn)
 				case "/user/convo/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2375,7 +3021,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ConvosEditReplySubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(120, cn)
+					co.RouteViewCounter.Bump3(171, cn)
 				case "/user/block/create/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2387,7 +3033,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.RelationsBlockCreate(w,req,user,h,extraData)
-					co.RouteViewCounter.Bump3(121, cn)
+					co.RouteViewCounter.Bump3(172, cn)
 				case "/user/block/create/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2400,7 +3046,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.RelationsBlockCreateSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(122, cn)
+					co.RouteViewCounter.Bump3(173, cn)
 				case "/user/block/remove/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2412,7 +3058,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.RelationsBlockRemove(w,req,user,h,extraData)
-					co.RouteViewCounter.Bump3(123, cn)
+					co.RouteViewCounter.Bump3(174, cn)
 				case "/user/block/remove/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2425,7 +3071,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.RelationsBlockRemoveSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(124, cn)
+					co.RouteViewCounter.Bump3(175, cn)
 				default:
 					req.URL.Path += extraData
 					h, err := c.UserCheckNano(w,req,user,cn)
@@ -2433,7 +3079,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 						return err
 					}
 					err = routes.ViewProfile(w,req,user, h)
-			co.RouteViewCounter.Bump3(125, cn)
+			co.RouteViewCounter.Bump3(176, cn)
 			}
 		case "/users":
 			switch(req.URL.Path) {
@@ -2449,7 +3095,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.BanUserSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(126, cn)
+					co.RouteViewCounter.Bump3(177, cn)
 				case "/users/unban/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2462,7 +3108,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.UnbanUser(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(127, cn)
+					co.RouteViewCounter.Bump3(178, cn)
 				case "/users/activate/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2475,7 +3121,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ActivateUser(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(128, cn)
+					co.RouteViewCounter.Bump3(179, cn)
 				case "/users/ips/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2487,7 +3133,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.IPSearch(w,req,user,h)
-					co.RouteViewCounter.Bump3(129, cn)
+					co.RouteViewCounter.Bump3(180, cn)
 				case "/users/delete-posts/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2500,7 +3146,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.DeletePostsSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(130, cn)
+					co.RouteViewCounter.Bump3(181, cn)
 			}
 		case "/topic":
 			switch(req.URL.Path) {
@@ -2520,7 +3166,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.CreateTopicSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(131, cn)
+					co.RouteViewCounter.Bump3(182, cn)
 				case "/topic/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2533,7 +3179,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.EditTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(132, cn)
+					co.RouteViewCounter.Bump3(183, cn)
 				case "/topic/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2547,7 +3193,20 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					
 					req.URL.Path += extraData
 					err = routes.DeleteTopicSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(133, cn)
+					co.RouteViewCounter.Bump3(184, cn)
+				case "/topic/restore/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.RestoreTopicSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(185, cn)
 				case "/topic/stick/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2560,7 +3219,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.StickTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(134, cn)
+					co.RouteViewCounter.Bump3(186, cn)
 				case "/topic/unstick/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2573,7 +3232,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.UnstickTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(135, cn)
+					co.RouteViewCounter.Bump3(187, cn)
 				case "/topic/lock/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2587,7 +3246,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					
 					req.URL.Path += extraData
 					err = routes.LockTopicSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(136, cn)
+					co.RouteViewCounter.Bump3(188, cn)
 				case "/topic/unlock/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2600,7 +3259,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.UnlockTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(137, cn)
+					co.RouteViewCounter.Bump3(189, cn)
 				case "/topic/move/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2613,7 +3272,46 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.MoveTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(138, cn)
+					co.RouteViewCounter.Bump3(190, cn)
+				case "/topic/merge/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.MergeTopicSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(191, cn)
+				case "/topic/split/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.SplitTopicSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(192, cn)
+				case "/topic/move-replies/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.MoveRepliesSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(193, cn)
 				case "/topic/like/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2626,7 +3324,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.LikeTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(139, cn)
+					co.RouteViewCounter.Bump3(194, cn)
 				case "/topic/unlike/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2639,7 +3337,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.UnlikeTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(140, cn)
+					co.RouteViewCounter.Bump3(195, cn)
 				case "/topic/attach/add/submit/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2656,7 +3354,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AddAttachToTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(141, cn)
+					co.RouteViewCounter.Bump3(196, cn)
 				case "/topic/attach/remove/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2669,14 +3367,72 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.RemoveAttachFromTopicSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(142, cn)
+					co.RouteViewCounter.Bump3(197, cn)
+				case "/topic/revisions/":
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.TopicRevisions(w,req,user,h,extraData)
+					co.RouteViewCounter.Bump3(198, cn)
+				case "/topic/revert/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.TopicRevertSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(199, cn)
+				case "/topic/timed/":
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.TopicTimedActions(w,req,user,h,extraData)
+					co.RouteViewCounter.Bump3(200, cn)
+				case "/topic/timed/create/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.TopicTimedActionCreateSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(201, cn)
+				case "/topic/timed/delete/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.TopicTimedActionDeleteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(202, cn)
 				default:
 					h, err := c.UserCheckNano(w,req,user,cn)
 					if err != nil {
 						return err
 					}
 					err = routes.ViewTopic(w,req,user, h, extraData)
-			co.RouteViewCounter.Bump3(143, cn)
+			co.RouteViewCounter.Bump3(203, cn)
 			}
 		case "/reply":
 			switch(req.URL.Path) {
@@ -2696,7 +3452,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.CreateReplySubmit(w,req,user)
-					co.RouteViewCounter.Bump3(144, cn)
+					co.RouteViewCounter.Bump3(204, cn)
 				case "/reply/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2709,7 +3465,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ReplyEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(145, cn)
+					co.RouteViewCounter.Bump3(205, cn)
 				case "/reply/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2722,7 +3478,20 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ReplyDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(146, cn)
+					co.RouteViewCounter.Bump3(206, cn)
+				case "/reply/restore/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ReplyRestoreSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(207, cn)
 				case "/reply/like/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2735,7 +3504,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ReplyLikeSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(147, cn)
+					co.RouteViewCounter.Bump3(208, cn)
 				case "/reply/unlike/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2748,7 +3517,59 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ReplyUnlikeSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(148, cn)
+					co.RouteViewCounter.Bump3(209, cn)
+				case "/reply/upvote/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ReplyUpvoteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(210, cn)
+				case "/reply/downvote/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ReplyDownvoteSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(211, cn)
+				case "/reply/accept/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ReplyAcceptSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(212, cn)
+				case "/reply/unaccept/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ReplyUnacceptSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(213, cn)
 				case "/reply/attach/add/submit/":
 					err = c.MemberOnly(w,req,user)
 					if err != nil {
@@ -2765,7 +3586,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AddAttachToReplySubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(149, cn)
+					co.RouteViewCounter.Bump3(214, cn)
 				case "/reply/attach/remove/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2778,7 +3599,27 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.RemoveAttachFromReplySubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(150, cn)
+					co.RouteViewCounter.Bump3(215, cn)
+				case "/reply/revisions/":
+				h, err := c.UserCheckNano(w,req,user,cn)
+				if err != nil {
+					return err
+				}
+					err = routes.ReplyRevisions(w,req,user,h,extraData)
+					co.RouteViewCounter.Bump3(216, cn)
+				case "/reply/revert/submit/":
+					err = c.NoSessionMismatch(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = c.MemberOnly(w,req,user)
+					if err != nil {
+						return err
+					}
+					
+					err = routes.ReplyRevertSubmit(w,req,user,extraData)
+					co.RouteViewCounter.Bump3(217, cn)
 			}
 		case "/profile":
 			switch(req.URL.Path) {
@@ -2794,7 +3635,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ProfileReplyCreateSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(151, cn)
+					co.RouteViewCounter.Bump3(218, cn)
 				case "/profile/reply/edit/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2807,7 +3648,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ProfileReplyEditSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(152, cn)
+					co.RouteViewCounter.Bump3(219, cn)
 				case "/profile/reply/delete/submit/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2820,7 +3661,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.ProfileReplyDeleteSubmit(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(153, cn)
+					co.RouteViewCounter.Bump3(220, cn)
 			}
 		case "/poll":
 			switch(req.URL.Path) {
@@ -2836,10 +3677,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.PollVote(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(154, cn)
+					co.RouteViewCounter.Bump3(221, cn)
 				case "/poll/results/":
 					err = routes.PollResults(w,req,user,extraData)
-					co.RouteViewCounter.Bump3(155, cn)
+					co.RouteViewCounter.Bump3(222, cn)
 			}
 		case "/accounts":
 			switch(req.URL.Path) {
@@ -2849,14 +3690,14 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					return err
 				}
 					err = routes.AccountLogin(w,req,user,h)
-					co.RouteViewCounter.Bump3(156, cn)
+					co.RouteViewCounter.Bump3(223, cn)
 				case "/accounts/create/":
 				h, err := c.UserCheckNano(w,req,user,cn)
 				if err != nil {
 					return err
 				}
 					err = routes.AccountRegister(w,req,user,h)
-					co.RouteViewCounter.Bump3(157, cn)
+					co.RouteViewCounter.Bump3(224, cn)
 				case "/accounts/logout/":
 					err = c.NoSessionMismatch(w,req,user)
 					if err != nil {
@@ -2869,7 +3710,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountLogout(w,req,user)
-					co.RouteViewCounter.Bump3(158, cn)
+					co.RouteViewCounter.Bump3(225, cn)
 				case "/accounts/login/submit/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -2877,14 +3718,14 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountLoginSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(159, cn)
+					co.RouteViewCounter.Bump3(226, cn)
 				case "/accounts/mfa_verify/":
 				h, err := c.UserCheckNano(w,req,user,cn)
 				if err != nil {
 					return err
 				}
 					err = routes.AccountLoginMFAVerify(w,req,user,h)
-					co.RouteViewCounter.Bump3(160, cn)
+					co.RouteViewCounter.Bump3(227, cn)
 				case "/accounts/mfa_verify/submit/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -2892,7 +3733,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountLoginMFAVerifySubmit(w,req,user)
-					co.RouteViewCounter.Bump3(161, cn)
+					co.RouteViewCounter.Bump3(228, cn)
 				case "/accounts/create/submit/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -2900,14 +3741,14 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountRegisterSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(162, cn)
+					co.RouteViewCounter.Bump3(229, cn)
 				case "/accounts/password-reset/":
 				h, err := c.UserCheckNano(w,req,user,cn)
 				if err != nil {
 					return err
 				}
 					err = routes.AccountPasswordReset(w,req,user,h)
-					co.RouteViewCounter.Bump3(163, cn)
+					co.RouteViewCounter.Bump3(230, cn)
 				case "/accounts/password-reset/submit/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -2915,14 +3756,14 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountPasswordResetSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(164, cn)
+					co.RouteViewCounter.Bump3(231, cn)
 				case "/accounts/password-reset/token/":
 				h, err := c.UserCheckNano(w,req,user,cn)
 				if err != nil {
 					return err
 				}
 					err = routes.AccountPasswordResetToken(w,req,user,h)
-					co.RouteViewCounter.Bump3(165, cn)
+					co.RouteViewCounter.Bump3(232, cn)
 				case "/accounts/password-reset/token/submit/":
 					err = c.ParseForm(w,req,user)
 					if err != nil {
@@ -2930,7 +3771,7 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 					}
 					
 					err = routes.AccountPasswordResetTokenSubmit(w,req,user)
-					co.RouteViewCounter.Bump3(166, cn)
+					co.RouteViewCounter.Bump3(233, cn)
 			}
 		/*case "/sitemaps": // TODO: Count these views
 			req.URL.Path += extraData
@@ -2941,33 +3782,33 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 			http.Redirect(w, req, "/s/"+extraData, http.StatusTemporaryRedirect)
 		case "/uploads":
 			if extraData == "" {
-				co.RouteViewCounter.Bump3(168, cn)
+				co.RouteViewCounter.Bump3(235, cn)
 				return c.NotFound(w,req,nil)
 			}
 			w = r.responseWriter(w)
 			req.URL.Path += extraData
 			// TODO: Find a way to propagate errors up from this?
 			r.UploadHandler(w,req) // TODO: Count these views
-			co.RouteViewCounter.Bump3(168, cn)
+			co.RouteViewCounter.Bump3(235, cn)
 			return nil
 		case "":
 			// Stop the favicons, robots.txt file, etc. resolving to the topics list
 			// TODO: Add support for favicons and robots.txt files
 			switch(extraData) {
 				case "robots.txt":
-					co.RouteViewCounter.Bump3(170, cn)
+					co.RouteViewCounter.Bump3(237, cn)
 					return routes.RobotsTxt(w,req)
 				case "favicon.ico":
 					w = r.responseWriter(w)
 					req.URL.Path = "/s/favicon.ico"
 					routes.StaticFile(w,req)
-					co.RouteViewCounter.Bump3(173, cn)
+					co.RouteViewCounter.Bump3(240, cn)
 					return nil
 				case "opensearch.xml":
-					co.RouteViewCounter.Bump3(172, cn)
+					co.RouteViewCounter.Bump3(239, cn)
 					return routes.OpenSearchXml(w,req)
 				/*case "sitemap.xml":
-					co.RouteViewCounter.Bump3(171, cn)
+					co.RouteViewCounter.Bump3(238, cn)
 					return routes.SitemapXml(w,req)*/
 			}
 			co.RouteViewCounter.Bump(0)
@@ -2981,10 +3822,10 @@ func (r *GenRouter) routeSwitch(w http.ResponseWriter, req *http.Request, user *
 			
 			if ok {
 				// TODO: Be more specific about *which* dynamic route it is
-				co.RouteViewCounter.Bump(167)
+				co.RouteViewCounter.Bump(234)
 				return h(w,req,user)
 			}
-			co.RouteViewCounter.Bump3(174, cn)
+			co.RouteViewCounter.Bump3(241, cn)
 
 			lp := strings.ToLower(req.URL.Path)
 			if strings.Contains(lp,"admin") || strings.Contains(lp,"sql") || strings.Contains(lp,"manage") || strings.Contains(lp,"//") || strings.Contains(lp,"\\\\") || strings.Contains(lp,"wp") || strings.Contains(lp,"wordpress") || strings.Contains(lp,"config") || strings.Contains(lp,"setup") || strings.Contains(lp,"install") || strings.Contains(lp,"update") || strings.Contains(lp,"php") || strings.Contains(lp,"pl") || strings.Contains(lp,"wget") || strings.Contains(lp,"wp-") || strings.Contains(lp,"include") || strings.Contains(lp,"vendor") || strings.Contains(lp,"bin") || strings.Contains(lp,"system") || strings.Contains(lp,"eval") || strings.Contains(lp,"config") {
@@ -8,6 +8,8 @@ var dbTablePrimaryKeys = map[string]string{
 	"topics":"tid",
 	"attachments":"attachID",
 	"word_filters":"wfid",
+	"profile_fields":"pfid",
+	"topic_prefixes":"prid",
 	"menu_items":"miid",
 	"users_groups":"gid",
 	"users_2fa_keys":"uid",
@@ -25,4 +27,6 @@ var dbTablePrimaryKeys = map[string]string{
 	"users":"uid",
 	"users_groups_scheduler":"uid",
 	"users_avatar_queue":"uid",
+	"users_self_deletes":"uid",
+	"cluster_events":"ceid",
 }
@@ -13,6 +13,7 @@ require (
 	github.com/gorilla/websocket v1.4.2
 	github.com/lib/pq v1.0.0
 	github.com/mailru/easyjson v0.7.0
+	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/olivere/elastic v6.2.16+incompatible // indirect
 	github.com/oschwald/geoip2-golang v1.2.1
 	github.com/oschwald/maxminddb-golang v1.3.0 // indirect
@@ -20,6 +21,7 @@ require (
 	github.com/robertkrimen/otto v0.0.0-20180617131154-15f95af6e78d
 	golang.org/x/crypto v0.0.0-20200429183012-4b2356b1ed79
 	golang.org/x/image v0.0.0-20200430140353-33d19683fad8
+	golang.org/x/net v0.0.0-20200324143707-d3edc9973b7e
 	google.golang.org/appengine v1.2.0 // indirect
 	gopkg.in/olivere/elastic.v6 v6.2.16
 	gopkg.in/sourcemap.v1 v1.0.5 // indirect
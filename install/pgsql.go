@@ -1,20 +1,22 @@
 /*
 *
 * Gosora PostgreSQL Interface
-* Under heavy development
-* Copyright Azareal 2017 - 2019
+* Copyright Azareal 2017 - 2020
 *
  */
 package install
 
 import (
+	"bytes"
 	"database/sql"
-	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
-	"github.com/Azareal/Gosora/query_gen"
-	_ "github.com/go-sql-driver/mysql"
+	qgen "github.com/Azareal/Gosora/query_gen"
 )
 
 // We don't need SSL to run an installer... Do we?
@@ -50,36 +52,100 @@ func (ins *PgsqlInstaller) DefaultPort() string {
 }
 
 func (ins *PgsqlInstaller) InitDatabase() (err error) {
-	_dbPassword := ins.dbPassword
-	if _dbPassword != "" {
-		_dbPassword = " password=" + pgEscapeBit(_dbPassword)
-	}
-	db, err := sql.Open("postgres", "host='"+pgEscapeBit(ins.dbHost)+"' port='"+pgEscapeBit(ins.dbPort)+"' user='"+pgEscapeBit(ins.dbUsername)+"' dbname='"+pgEscapeBit(ins.dbName)+"'"+_dbPassword+" sslmode='"+dbSslmode+"'")
+	err = qgen.Builder.Init("pgsql", map[string]string{
+		"host":     ins.dbHost,
+		"port":     ins.dbPort,
+		"name":     ins.dbName,
+		"username": ins.dbUsername,
+		"password": ins.dbPassword,
+		"sslmode":  dbSslmode,
+	})
 	if err != nil {
 		return err
 	}
+	fmt.Println("Successfully connected to the database")
+
+	// TODO: Create the database, if it doesn't exist
+	ins.db = qgen.Builder.GetConn()
+	return nil
+}
+
+func (ins *PgsqlInstaller) createTable(f os.FileInfo) error {
+	table := strings.TrimPrefix(f.Name(), "query_")
+	ext := filepath.Ext(table)
+	if ext != ".sql" {
+		return nil
+	}
+	table = strings.TrimSuffix(table, ext)
 
-	// Make sure that the connection is alive..
-	err = db.Ping()
+	// ? - This is mainly here for tests, although it might allow the installer to overwrite a production database, so we might want to proceed with caution
+	q := "DROP TABLE IF EXISTS \"" + table + "\" CASCADE;"
+	_, err := ins.db.Exec(q)
 	if err != nil {
+		fmt.Println("Failed query:", q)
+		fmt.Println("e:", err)
 		return err
 	}
-	fmt.Println("Successfully connected to the database")
 
-	// TODO: Create the database, if it doesn't exist
+	data, err := ioutil.ReadFile("./schema/pgsql/" + f.Name())
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
 
-	// Ready the query builder
-	ins.db = db
-	qgen.Builder.SetConn(db)
-	return qgen.Builder.SetAdapter("pgsql")
+	// The fulltext indices live in the same file, so this has to go through as one unparameterised query
+	q = string(data)
+	_, err = ins.db.Exec(q)
+	if err != nil {
+		fmt.Println("Failed query:", q)
+		fmt.Println("e:", err)
+		return err
+	}
+	fmt.Printf("Created table '%s'\n", table)
+	return nil
 }
 
 func (ins *PgsqlInstaller) TableDefs() (err error) {
-	return errors.New("TableDefs() not implemented")
+	fmt.Println("Creating the tables")
+	files, err := ioutil.ReadDir("./schema/pgsql/")
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), "query_") {
+			continue
+		}
+		err := ins.createTable(f)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = ins.db.Exec(qgen.PgsqlLastvalFunc)
+	return err
 }
 
-func (ins *PgsqlInstaller) InitialData() (err error) {
-	return errors.New("InitialData() not implemented")
+func (ins *PgsqlInstaller) InitialData() error {
+	fmt.Println("Seeding the tables")
+	data, err := ioutil.ReadFile("./schema/pgsql/inserts.sql")
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
+
+	statements := bytes.Split(data, []byte(";\n"))
+	for key, sBytes := range statements {
+		statement := string(bytes.TrimSpace(sBytes))
+		if statement == "" {
+			continue
+		}
+
+		fmt.Println("Executing query #" + strconv.Itoa(key) + " " + statement)
+		_, err = ins.db.Exec(statement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (ins *PgsqlInstaller) CreateAdmin() error {
@@ -105,8 +171,3 @@ func (ins *PgsqlInstaller) DBName() string {
 func (ins *PgsqlInstaller) DBPort() string {
 	return ins.dbPort
 }
-
-func pgEscapeBit(bit string) string {
-	// TODO: Write a custom parser, so that backslashes work properly in the sql.Open string. Do something similar for the database driver, if possible?
-	return strings.Replace(bit, "'", "\\'", -1)
-}
@@ -0,0 +1,174 @@
+// +build sqlite
+
+/*
+*
+* Gosora SQLite Interface
+* Copyright Azareal 2020
+*
+ */
+package install
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+func init() {
+	adapters["sqlite"] = &SqliteInstaller{}
+}
+
+// SqliteInstaller sets up a database file, the database name is used as the path to it and the rest of the connection details are ignored
+type SqliteInstaller struct {
+	db         *sql.DB
+	dbHost     string
+	dbUsername string
+	dbPassword string
+	dbName     string
+	dbPort     string
+}
+
+func (ins *SqliteInstaller) SetConfig(dbHost string, dbUsername string, dbPassword string, dbName string, dbPort string) {
+	ins.dbHost = dbHost
+	ins.dbUsername = dbUsername
+	ins.dbPassword = dbPassword
+	ins.dbName = dbName
+	ins.dbPort = dbPort
+}
+
+func (ins *SqliteInstaller) Name() string {
+	return "sqlite"
+}
+
+func (ins *SqliteInstaller) DefaultPort() string {
+	return ""
+}
+
+func (ins *SqliteInstaller) InitDatabase() (err error) {
+	// The file is created, if it doesn't exist
+	err = qgen.Builder.Init("sqlite", map[string]string{"name": ins.dbName})
+	if err != nil {
+		return err
+	}
+	fmt.Println("Successfully opened the database")
+	ins.db = qgen.Builder.GetConn()
+	return nil
+}
+
+func (ins *SqliteInstaller) createTable(f os.FileInfo) error {
+	table := strings.TrimPrefix(f.Name(), "query_")
+	ext := filepath.Ext(table)
+	if ext != ".sql" {
+		return nil
+	}
+	table = strings.TrimSuffix(table, ext)
+
+	// ? - This is mainly here for tests, although it might allow the installer to overwrite a production database, so we might want to proceed with caution
+	q := "DROP TABLE IF EXISTS \"" + table + "\";"
+	_, err := ins.db.Exec(q)
+	if err != nil {
+		fmt.Println("Failed query:", q)
+		fmt.Println("e:", err)
+		return err
+	}
+
+	data, err := ioutil.ReadFile("./schema/sqlite/" + f.Name())
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
+
+	q = string(data)
+	_, err = ins.db.Exec(q)
+	if err != nil {
+		fmt.Println("Failed query:", q)
+		fmt.Println("e:", err)
+		return err
+	}
+	fmt.Printf("Created table '%s'\n", table)
+	return nil
+}
+
+func (ins *SqliteInstaller) TableDefs() (err error) {
+	fmt.Println("Creating the tables")
+	files, err := ioutil.ReadDir("./schema/sqlite/")
+	if err != nil {
+		return err
+	}
+
+	// The foreign key checks are a per-connection setting, so we need to make sure they're all on the same one
+	ins.db.SetMaxOpenConns(1)
+	defer ins.db.SetMaxOpenConns(0)
+	_, err = ins.db.Exec("PRAGMA foreign_keys = OFF;")
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), "query_") {
+			continue
+		}
+		err := ins.createTable(f)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = ins.db.Exec("PRAGMA foreign_keys = ON;")
+	return err
+}
+
+func (ins *SqliteInstaller) InitialData() error {
+	fmt.Println("Seeding the tables")
+	data, err := ioutil.ReadFile("./schema/sqlite/inserts.sql")
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimSpace(data)
+
+	statements := bytes.Split(data, []byte(";\n"))
+	for key, sBytes := range statements {
+		statement := string(bytes.TrimSpace(sBytes))
+		if statement == "" {
+			continue
+		}
+
+		fmt.Println("Executing query #" + strconv.Itoa(key) + " " + statement)
+		_, err = ins.db.Exec(statement)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ins *SqliteInstaller) CreateAdmin() error {
+	return createAdmin()
+}
+
+func (ins *SqliteInstaller) DBHost() string {
+	return ins.dbHost
+}
+
+func (ins *SqliteInstaller) DBUsername() string {
+	return ins.dbUsername
+}
+
+func (ins *SqliteInstaller) DBPassword() string {
+	return ins.dbPassword
+}
+
+func (ins *SqliteInstaller) DBName() string {
+	return ins.dbName
+}
+
+func (ins *SqliteInstaller) DBPort() string {
+	return ins.dbPort
+}
@@ -50,6 +50,7 @@ type Globs struct {
 // Temporary alias for renderTemplate
 func init() {
 	c.RenderTemplateAlias = routes.RenderTemplate
+	c.TopicCreatedAlias = routes.TopicCreated
 }
 
 func afterDBInit() (err error) {
@@ -78,14 +79,14 @@ func afterDBInit() (err error) {
 		}
 
 		// TODO: Use the same cached data for both the topic list and the topic fetches...
-		tList, _, _, err := c.TopicList.GetListByCanSee(group.CanSee, 1, 0, nil)
+		tList, _, _, err := c.TopicList.GetListByCanSee(group.CanSee, 1, 0, nil, c.TopicListFilter{})
 		if err != nil {
 			return err
 		}
 		ctList := make([]*c.TopicsRow, len(tList))
 		copy(ctList, tList)
 
-		tList, _, _, err = c.TopicList.GetListByCanSee(group.CanSee, 2, 0, nil)
+		tList, _, _, err = c.TopicList.GetListByCanSee(group.CanSee, 2, 0, nil, c.TopicListFilter{})
 		if err != nil {
 			return err
 		}
@@ -93,7 +94,7 @@ func afterDBInit() (err error) {
 			ctList = append(ctList, tItem)
 		}
 
-		tList, _, _, err = c.TopicList.GetListByCanSee(group.CanSee, 3, 0, nil)
+		tList, _, _, err = c.TopicList.GetListByCanSee(group.CanSee, 3, 0, nil, c.TopicListFilter{})
 		if err != nil {
 			return err
 		}
@@ -128,6 +129,9 @@ func storeInit() (err error) {
 	var rcache c.ReplyCache
 	if c.Config.ReplyCache == "static" {
 		rcache = c.NewMemoryReplyCache(c.Config.ReplyCacheCapacity)
+		if c.Config.ServerCount > 1 {
+			rcache = c.NewClusterReplyCache(rcache)
+		}
 	}
 	c.Rstore, err = c.NewSQLReplyStore(acc, rcache)
 	if err != nil {
@@ -201,11 +205,63 @@ func storeInit() (err error) {
 		return errors.WithStack(err)
 	}
 
+	log.Print("Initialising the cluster bus")
+	c.Cluster, err = c.NewClusterBus(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.AddShutdownTask(c.Cluster.Close)
+
 	log.Print("Initialising the stores")
 	c.WordFilters, err = c.NewDefaultWordFilterStore(acc)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	c.ProfileFields, err = c.NewDefaultProfileFieldStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// The same people are likely to be around, so the user cache capacity is a good enough fit here
+	c.UserThemeSettings, err = c.NewDefaultUserThemeSettingStore(acc, c.Config.UserCacheCapacity)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.TopicPrefixes, err = c.NewDefaultTopicPrefixStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.ForumMods, err = c.NewDefaultForumModStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.TopicTags, err = c.NewDefaultTopicTagStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Revisions, err = c.NewDefaultRevisionStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Drafts, err = c.NewDefaultDraftStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.ScheduledTopics, err = c.NewDefaultScheduledTopicStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.TopicTimedActions, err = c.NewDefaultTopicTimedActionStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Votes, err = c.NewDefaultVoteStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.Trash, err = c.NewDefaultTrashStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
 	c.MFAstore, err = c.NewSQLMFAStore(acc)
 	if err != nil {
 		return errors.WithStack(err)
@@ -226,6 +282,10 @@ func storeInit() (err error) {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	c.SelfDeletes, err = c.NewDefaultSelfDeleteStore(acc)
+	if err != nil {
+		return errors.WithStack(err)
+	}
 	c.RegLogs, err = c.NewRegLogStore(acc)
 	if err != nil {
 		return errors.WithStack(err)
@@ -413,6 +473,8 @@ func main() {
 	defer db.Close()
 
 	buildTemplates := flag.Bool("build-templates", false, "build the templates")
+	createBackup := flag.Bool("backup", false, "make a backup of the database, uploads and attachments in the backups folder")
+	restoreBackup := flag.String("restore-backup", "", "restore the backup at this path, it can be from any of the database adapters")
 	flag.Parse()
 	if *buildTemplates {
 		err = c.CompileTemplates()
@@ -425,6 +487,22 @@ func main() {
 		}
 		return
 	}
+	if *createBackup {
+		name, err := c.CreateBackup()
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Print("Created the backup ./backups/" + name)
+		return
+	}
+	if *restoreBackup != "" {
+		m, err := c.RestoreBackup(*restoreBackup)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Restored %d tables and %d files from a %s backup made on %s", len(m.Tables), m.Files, m.Adapter, m.CreatedAt.Format("2006-01-02 15:04:05"))
+		return
+	}
 
 	err = afterDBInit()
 	if err != nil {
@@ -447,7 +525,8 @@ func main() {
 		go func() {
 			var ErrFileSkip = errors.New("skip mod file")
 			modifiedFileEvent := func(path string) error {
-				pathBits := strings.Split(path, "\\")
+				path = strings.TrimPrefix(strings.Replace(path, "\\", "/", -1), "./")
+				pathBits := strings.Split(path, "/")
 				if len(pathBits) == 0 {
 					return nil
 				}
@@ -456,13 +535,29 @@ func main() {
 					if len(pathBits) >= 2 {
 						themeName = pathBits[1]
 					}
-					if len(pathBits) >= 3 && pathBits[2] == "public" {
-						// TODO: Handle new themes freshly plopped into the folder?
-						theme, ok := c.Themes[themeName]
-						if ok {
-							return theme.LoadStaticFiles()
+					// TODO: Handle new themes freshly plopped into the folder?
+					theme, ok := c.Themes[themeName]
+					if !ok || len(pathBits) < 3 {
+						return ErrFileSkip
+					}
+					switch pathBits[2] {
+					case "public":
+						return theme.LoadStaticFiles()
+					case "overrides":
+						return theme.ReloadOverrides()
+					}
+				}
+				// The overrides are interpreted on top of these, so they'll need to be reloaded to pick up the changes
+				if pathBits[0] == "templates" && c.Dev.HotTemplates {
+					for _, theme := range c.Themes {
+						if !theme.RuntimeOverrides {
+							continue
+						}
+						if err := theme.ReloadOverrides(); err != nil {
+							return err
 						}
 					}
+					return nil
 				}
 				return ErrFileSkip
 			}
@@ -510,6 +605,12 @@ func main() {
 			if err != nil {
 				log.Fatal(err)
 			}
+			if len(theme.OverridenTemplates) > 0 {
+				err = watcher.Add("./themes/" + theme.Name + "/overrides")
+				if err != nil {
+					log.Fatal(err)
+				}
+			}
 		}
 	}
 
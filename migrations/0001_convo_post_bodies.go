@@ -0,0 +1,15 @@
+package migrations
+
+// The bodies of conversation posts were too short to hold much of anything, which came to light when bringing in private messages from other software
+func init() {
+	Add(&Migration{
+		Version: 1,
+		Name:    "convo_post_bodies",
+		Up: []Step{
+			Except{[]string{"sqlite"}, ChangeColumn{"conversations_posts", "body", tC{"body", "text", 0, false, false, ""}}},
+		},
+		Down: []Step{
+			Except{[]string{"sqlite"}, ChangeColumn{"conversations_posts", "body", tC{"body", "varchar", 50, false, false, ""}}},
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package migrations
+
+// Users can ask for their accounts to be anonymised or purged, which happens once a grace period has passed
+func init() {
+	Add(&Migration{
+		Version: 2,
+		Name:    "self_deletes",
+		Up: []Step{
+			CreateTable{"users_self_deletes", "", "",
+				[]tC{
+					{"uid", "int", 0, false, false, ""},
+					{"mode", "varchar", 20, false, false, ""},
+					{"requestedAt", "createdAt", 0, false, false, ""},
+				},
+				[]tK{
+					{"uid", "primary", "", false},
+				},
+			},
+		},
+	})
+}
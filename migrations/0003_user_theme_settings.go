@@ -0,0 +1,22 @@
+package migrations
+
+// Themes can declare preferences, such as a dark mode, which each user can pick for themselves
+func init() {
+	Add(&Migration{
+		Version: 3,
+		Name:    "user_theme_settings",
+		Up: []Step{
+			CreateTable{"users_theme_settings", "", "",
+				[]tC{
+					{"uid", "int", 0, false, false, ""},
+					{"theme", "varchar", 50, false, false, ""},
+					{"name", "varchar", 50, false, false, ""},
+					{"value", "varchar", 50, false, false, ""},
+				},
+				[]tK{
+					{"uid,theme,name", "unique", "", false},
+				},
+			},
+		},
+	})
+}
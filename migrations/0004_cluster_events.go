@@ -0,0 +1,24 @@
+package migrations
+
+// The servers in a cluster tell each other what they've changed through this table, so they can drop the stale items from their caches
+func init() {
+	Add(&Migration{
+		Version: 4,
+		Name:    "cluster_events",
+		Up: []Step{
+			CreateTable{"cluster_events", "", "",
+				[]tC{
+					{"ceid", "int", 0, false, true, ""},
+					{"origin", "varchar", 50, false, false, ""},
+					{"type", "varchar", 50, false, false, ""},
+					{"eid", "int", 0, false, false, "0"},
+					{"data", "text", 0, false, false, ""},
+					{"createdAt", "createdAt", 0, false, false, ""},
+				},
+				[]tK{
+					{"ceid", "primary", "", false},
+				},
+			},
+		},
+	})
+}
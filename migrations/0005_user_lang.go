@@ -0,0 +1,12 @@
+package migrations
+
+// Users can pick the language the site is shown to them in, it's blank when they haven't picked one
+func init() {
+	Add(&Migration{
+		Version: 5,
+		Name:    "user_lang",
+		Up: []Step{
+			AddColumn{"users", tC{"lang", "varchar", 50, false, false, "''"}, nil},
+		},
+	})
+}
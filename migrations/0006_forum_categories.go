@@ -0,0 +1,12 @@
+package migrations
+
+// Categories are forums which only hold other forums, they're shown as headings on the forum list
+func init() {
+	Add(&Migration{
+		Version: 6,
+		Name:    "forum_categories",
+		Up: []Step{
+			AddColumn{"forums", tC{"category", "boolean", 0, false, false, "0"}, nil},
+		},
+	})
+}
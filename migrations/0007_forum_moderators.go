@@ -0,0 +1,25 @@
+package migrations
+
+// Individual users can be made moderators of a forum with a chosen set of moderation permissions, without having to make a group for them
+func init() {
+	Add(&Migration{
+		Version: 7,
+		Name:    "forum_moderators",
+		Up: []Step{
+			CreateTable{"forums_moderators", "", "",
+				[]tC{
+					{"fid", "int", 0, false, false, ""},
+					{"uid", "int", 0, false, false, ""},
+					{"pinTopic", "boolean", 0, false, false, "0"},
+					{"closeTopic", "boolean", 0, false, false, "0"},
+					{"moveTopic", "boolean", 0, false, false, "0"},
+					{"deletePosts", "boolean", 0, false, false, "0"},
+					{"editPosts", "boolean", 0, false, false, "0"},
+				},
+				[]tK{
+					{"fid,uid", "primary", "", false},
+				},
+			},
+		},
+	})
+}
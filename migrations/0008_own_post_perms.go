@@ -0,0 +1,184 @@
+package migrations
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+var ownPostPerms = []string{"EditOwnTopic", "DeleteOwnTopic", "EditOwnReply", "DeleteOwnReply"}
+
+// People could always delete their own posts, now that's a permission and they can also edit them, so the groups and forums which let people reply get both to keep things working the way they did
+func init() {
+	Add(&Migration{
+		Version: 8,
+		Name:    "own_post_perms",
+		Up: []Step{
+			Insert{"settings", "name, content, type", "'own_post_edit_window','0','int'"},
+			Insert{"settings", "name, content, type", "'own_post_delete_window','0','int'"},
+			Func{"grant_own_post_perms", grantOwnPostPerms},
+		},
+		Down: []Step{
+			Func{"strip_own_post_perms", stripOwnPostPerms},
+			Delete{"settings", "name='own_post_delete_window'"},
+			Delete{"settings", "name='own_post_edit_window'"},
+		},
+	})
+}
+
+// ownPostPermsMeta is where the permissions grantOwnPostPerms added to each row are noted down, so that a rollback takes those away without touching the ones an admin had already given out
+const ownPostPermsMeta = "own_post_perms:"
+
+// grantOwnPostPerms gives the own post permissions to everyone who can reply, unless an admin has already decided on them
+func grantOwnPostPerms(tx *sql.Tx, a qgen.Adapter) error {
+	granted := make(map[string][]string)
+	err := editPerms(tx, a, func(row string, perms map[string]json.RawMessage) bool {
+		if string(perms["CreateReply"]) != "true" {
+			return false
+		}
+		for _, name := range ownPostPerms {
+			if _, ok := perms[name]; !ok {
+				perms[name] = json.RawMessage("true")
+				granted[row] = append(granted[row], name)
+			}
+		}
+		return len(granted[row]) > 0
+	})
+	if err != nil {
+		return err
+	}
+	ins, err := a.SimpleInsert("", "meta", "name,value", "?,?")
+	if err != nil {
+		return err
+	}
+	for row, names := range granted {
+		_, err = tx.Exec(ins, ownPostPermsMeta+row, strings.Join(names, ","))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stripOwnPostPerms takes away the permissions grantOwnPostPerms added. Installations which came with them to begin with don't have anything noted down, so nothing is taken away there.
+func stripOwnPostPerms(tx *sql.Tx, a qgen.Adapter) error {
+	sel, err := a.SimpleSelect("", "meta", "name,value", "", "", "")
+	if err != nil {
+		return err
+	}
+	granted := make(map[string][]string)
+	rows, err := tx.Query(sel)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var name, value string
+		if err = rows.Scan(&name, &value); err != nil {
+			rows.Close()
+			return err
+		}
+		if strings.HasPrefix(name, ownPostPermsMeta) {
+			granted[strings.TrimPrefix(name, ownPostPermsMeta)] = strings.Split(value, ",")
+		}
+	}
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return err
+	}
+
+	err = editPerms(tx, a, func(row string, perms map[string]json.RawMessage) (changed bool) {
+		for _, name := range granted[row] {
+			if _, ok := perms[name]; ok {
+				delete(perms, name)
+				changed = true
+			}
+		}
+		return changed
+	})
+	if err != nil {
+		return err
+	}
+	del, err := a.SimpleDelete("", "meta", "name=?")
+	if err != nil {
+		return err
+	}
+	for row := range granted {
+		_, err = tx.Exec(del, ownPostPermsMeta+row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// editPerms runs edit over the permissions of every group and every forum override, the rows it says it changed are written back.
+// Each row is identified by it's table and it's keys, e.g. users_groups:3 or forums_permissions:2,3
+func editPerms(tx *sql.Tx, a qgen.Adapter, edit func(row string, perms map[string]json.RawMessage) (changed bool)) error {
+	for table, keys := range map[string][]string{"users_groups": {"gid"}, "forums_permissions": {"fid", "gid"}} {
+		sel, err := a.SimpleSelect("", table, strings.Join(keys, ",")+",permissions", "", "", "")
+		if err != nil {
+			return err
+		}
+		upd, err := a.Builder().Update().Table(table).Set("permissions=?").Where(strings.Join(keys, "=? AND ") + "=?").Text()
+		if err != nil {
+			return err
+		}
+
+		// The rows have to be closed before anything else can be done with the transaction
+		var updates [][]interface{}
+		rows, err := tx.Query(sel)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			ids := make([]int, len(keys))
+			dest := make([]interface{}, 0, len(keys)+1)
+			for i := range ids {
+				dest = append(dest, &ids[i])
+			}
+			var data string
+			if err = rows.Scan(append(dest, &data)...); err != nil {
+				rows.Close()
+				return err
+			}
+			var perms map[string]json.RawMessage
+			if err = json.Unmarshal([]byte(data), &perms); err != nil {
+				rows.Close()
+				return err
+			}
+			sids := make([]string, len(ids))
+			for i, id := range ids {
+				sids[i] = strconv.Itoa(id)
+			}
+			if perms == nil || !edit(table+":"+strings.Join(sids, ","), perms) {
+				continue
+			}
+			out, err := json.Marshal(perms)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			args := []interface{}{string(out)}
+			for _, id := range ids {
+				args = append(args, id)
+			}
+			updates = append(updates, args)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, args := range updates {
+			if _, err = tx.Exec(upd, args...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package migrations
+
+// Every edit to a topic or reply is kept as a revision, along with who made it, so that the changes can be compared and rolled back
+func init() {
+	Add(&Migration{
+		Version: 9,
+		Name:    "post_revisions",
+		Up: []Step{
+			AddColumn{"revisions", tC{"title", "varchar", 100, false, false, "''"}, nil},
+			AddColumn{"revisions", tC{"createdBy", "int", 0, false, false, "0"}, nil},
+			AddColumn{"revisions", tC{"ip", "varchar", 200, false, false, "''"}, nil},
+			AddColumn{"topics", tC{"lastEdit", "int", 0, false, false, "0"}, nil},
+			AddColumn{"topics", tC{"lastEditBy", "int", 0, false, false, "0"}, nil},
+		},
+	})
+}
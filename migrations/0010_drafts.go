@@ -0,0 +1,26 @@
+package migrations
+
+// People's unfinished posts are saved as they type, so they can pick them up again if they lose the page
+func init() {
+	Add(&Migration{
+		Version: 10,
+		Name:    "drafts",
+		Up: []Step{
+			CreateTable{"drafts", "", "",
+				[]tC{
+					{"draftID", "int", 0, false, true, ""},
+					{"uid", "int", 0, false, false, ""},
+					{"contextType", "varchar", 50, false, false, ""},
+					{"contextID", "int", 0, false, false, ""},
+					{"title", "varchar", 100, false, false, "''"},
+					{"content", "text", 0, false, false, ""},
+					{"updatedAt", "datetime", 0, false, false, ""},
+				},
+				[]tK{
+					{"draftID", "primary", "", false},
+					{"uid,contextType,contextID", "unique", "", false},
+				},
+			},
+		},
+	})
+}
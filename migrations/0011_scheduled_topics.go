@@ -0,0 +1,43 @@
+package migrations
+
+// Topics can be written in advance to be published later and moderators can ask for things to be done to topics later on, e.g. unpinning them after a week
+func init() {
+	Add(&Migration{
+		Version: 11,
+		Name:    "scheduled_topics",
+		Up: []Step{
+			CreateTable{"topics_scheduled", "", "",
+				[]tC{
+					{"stid", "int", 0, false, true, ""},
+					{"parentID", "int", 0, false, false, ""},
+					{"title", "varchar", 100, false, false, ""},
+					{"content", "text", 0, false, false, ""},
+					{"createdBy", "int", 0, false, false, ""},
+					{"ip", "varchar", 200, false, false, "''"},
+					{"prefix", "int", 0, false, false, "0"},
+					{"tags", "text", 0, false, false, ""},
+					{"publishAt", "datetime", 0, false, false, ""},
+					{"createdAt", "createdAt", 0, false, false, ""},
+				},
+				[]tK{
+					{"stid", "primary", "", false},
+				},
+			},
+			CreateTable{"topics_timed_actions", "", "",
+				[]tC{
+					{"taid", "int", 0, false, true, ""},
+					{"tid", "int", 0, false, false, ""},
+					{"action", "varchar", 50, false, false, ""},
+					{"days", "int", 0, false, false, ""},
+					{"inactive", "boolean", 0, false, false, "0"},
+					{"createdBy", "int", 0, false, false, ""},
+					{"createdAt", "createdAt", 0, false, false, ""},
+				},
+				[]tK{
+					{"taid", "primary", "", false},
+					{"tid,action", "unique", "", false},
+				},
+			},
+		},
+	})
+}
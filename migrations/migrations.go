@@ -0,0 +1,134 @@
+/*
+*
+*	Gosora Schema Migrations
+*	Copyright Azareal 2020
+*
+ */
+// Package migrations holds versioned changes to the database schema, which are declared with the query generator's table model, so that the same migration works on every adapter.
+// New migrations go in their own file in this folder and register themselves in init(), they should also be reflected in cmd/query_gen/tables.go, as that's what fresh installations are built from.
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// Migration is a single versioned change to the schema.
+// Down may be left empty when every step in Up knows how to undo itself.
+type Migration struct {
+	Version int
+	Name    string
+	Up      []Step
+	Down    []Step
+}
+
+var migrations = make(map[int]*Migration)
+
+// Add registers a migration, it's meant to be called from init() and panics on a duplicate version as that is a programming error
+func Add(m *Migration) {
+	if m.Version < 1 {
+		panic("migration versions start at 1")
+	}
+	if _, ok := migrations[m.Version]; ok {
+		panic("migration " + strconv.Itoa(m.Version) + " has already been registered")
+	}
+	migrations[m.Version] = m
+}
+
+// Get returns the migration with this version, or nil if there isn't one
+func Get(version int) *Migration {
+	return migrations[version]
+}
+
+// List returns every registered migration ordered by version
+func List() []*Migration {
+	list := make([]*Migration, 0, len(migrations))
+	for _, m := range migrations {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+	return list
+}
+
+// Latest returns the version of the newest migration, zero if there aren't any
+func Latest() (version int) {
+	for v := range migrations {
+		if v > version {
+			version = v
+		}
+	}
+	return version
+}
+
+// Checksum identifies what a migration does, so that we can tell when a migration which has already been applied has been changed afterwards.
+// It's built from the declared steps rather than the SQL, so it's the same for every adapter.
+func (m *Migration) Checksum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d:%s\n", m.Version, m.Name)
+	write := func(dir string, steps []Step) {
+		for _, step := range steps {
+			// Pointers are followed by json unlike fmt, so the address of a key won't leak into the checksum
+			data, err := json.Marshal(step)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Fprintf(h, "%s:%T:%s\n", dir, step, data)
+		}
+	}
+	write("up", m.Up)
+	write("down", m.Down)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// DownSteps returns Down, or the reversal of Up if Down wasn't given
+func (m *Migration) DownSteps() ([]Step, error) {
+	if len(m.Down) > 0 {
+		return m.Down, nil
+	}
+	steps := make([]Step, len(m.Up))
+	for i, step := range m.Up {
+		rev, ok := step.(Reverser)
+		if !ok {
+			return nil, errors.New("migration " + strconv.Itoa(m.Version) + " can't be rolled back, step " + strconv.Itoa(i+1) + " can't be reversed and no down steps were given")
+		}
+		// The last thing done has to be the first thing undone
+		steps[len(m.Up)-1-i] = rev.Reverse()
+	}
+	return steps, nil
+}
+
+// UpSQL builds the queries for applying this migration on the adapter, Func steps don't have any, so they're left out
+func (m *Migration) UpSQL(a qgen.Adapter) ([]string, error) {
+	return buildSteps(a, m.Up)
+}
+
+// DownSQL builds the queries for rolling this migration back on the adapter
+func (m *Migration) DownSQL(a qgen.Adapter) ([]string, error) {
+	steps, err := m.DownSteps()
+	if err != nil {
+		return nil, err
+	}
+	return buildSteps(a, steps)
+}
+
+func buildSteps(a qgen.Adapter, steps []Step) (qs []string, err error) {
+	for i, step := range steps {
+		q, err := step.SQL(a)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %s", i+1, err)
+		}
+		if q != "" {
+			qs = append(qs, q)
+		}
+	}
+	return qs, nil
+}
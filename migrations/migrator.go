@@ -0,0 +1,278 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// ErrChecksum is returned when a migration has been changed after it was applied to the database
+var ErrChecksum = errors.New("a migration has been changed since it was applied")
+
+// Applied is the record of a migration which has been run on the database
+type Applied struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator applies and rolls back migrations on the database the query builder is connected to.
+// When DryRun is set, the SQL is written to Out instead of being run and nothing is recorded.
+type Migrator struct {
+	DryRun bool
+	Out    io.Writer
+
+	adapter qgen.Adapter
+	db      *sql.DB
+	from    int
+}
+
+// NewMigrator uses the connection and the adapter the query builder has been set up with
+func NewMigrator(out io.Writer) *Migrator {
+	return &Migrator{Out: out, adapter: qgen.Builder.GetAdapter(), db: qgen.Builder.GetConn()}
+}
+
+// NewOfflineMigrator is for printing the SQL for an adapter without a database to hand, it acts as if every migration up to and including the version from has been applied
+func NewOfflineMigrator(adapter qgen.Adapter, from int, out io.Writer) *Migrator {
+	return &Migrator{DryRun: true, Out: out, adapter: adapter, from: from}
+}
+
+// EnsureTable creates the table we keep track of the applied migrations in for installations which predate it
+func (mg *Migrator) EnsureTable() error {
+	if mg.db == nil {
+		return nil
+	}
+	_, err := qgen.NewAcc().Count("schema_migrations").Total()
+	if err == nil {
+		return nil
+	}
+	q, err := mg.adapter.CreateTable("", "schema_migrations", "", "",
+		[]tC{
+			{"version", "int", 0, false, false, ""},
+			{"name", "varchar", 200, false, false, ""},
+			{"checksum", "varchar", 100, false, false, ""},
+			{"appliedAt", "datetime", 0, false, false, ""},
+		},
+		[]tK{
+			{"version", "primary", "", false},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	_, err = mg.db.Exec(q)
+	return err
+}
+
+// Applied returns the migrations recorded in the database by version
+func (mg *Migrator) Applied() (map[int]Applied, error) {
+	applied := make(map[int]Applied)
+	if mg.db == nil {
+		for _, m := range List() {
+			if m.Version <= mg.from {
+				applied[m.Version] = Applied{m.Version, m.Name, m.Checksum(), time.Time{}}
+			}
+		}
+		return applied, nil
+	}
+	err := qgen.NewAcc().Select("schema_migrations").Columns("version,name,checksum,appliedAt").Each(func(rows *sql.Rows) error {
+		var a Applied
+		err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt)
+		if err != nil {
+			return err
+		}
+		applied[a.Version] = a
+		return nil
+	})
+	return applied, err
+}
+
+// Version is the newest migration which has been applied, zero if none of them have been
+func (mg *Migrator) Version() (int, error) {
+	applied, err := mg.Applied()
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// Verify checks that the applied migrations haven't been changed since they were run and that we know about all of them.
+// Every problem is written to Out and ErrChecksum is returned if there were any.
+func (mg *Migrator) Verify() error {
+	applied, err := mg.Applied()
+	if err != nil {
+		return err
+	}
+	var bad bool
+	for _, a := range applied {
+		m := Get(a.Version)
+		if m == nil {
+			fmt.Fprintf(mg.Out, "Migration %d '%s' was applied, but this version of Gosora doesn't know about it\n", a.Version, a.Name)
+			bad = true
+		} else if m.Checksum() != a.Checksum {
+			fmt.Fprintf(mg.Out, "Migration %d '%s' has been changed since it was applied\n", a.Version, a.Name)
+			bad = true
+		}
+	}
+	if bad {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// Up applies every migration which hasn't been applied yet, up to and including the version to, or all of them if to is zero
+func (mg *Migrator) Up(to int) error {
+	err := mg.Verify()
+	if err != nil {
+		return err
+	}
+	applied, err := mg.Applied()
+	if err != nil {
+		return err
+	}
+	for _, m := range List() {
+		if to > 0 && m.Version > to {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err = mg.run(m, "up", m.Up, func(tx *sql.Tx) error {
+			q, err := mg.adapter.Builder().Insert().Table("schema_migrations").Columns("version,name,checksum,appliedAt").Fields("?,?,?,UTC_TIMESTAMP()").Text()
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(q, m.Version, m.Name, m.Checksum())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down rolls back the applied migrations which are newer than the version to, newest first
+func (mg *Migrator) Down(to int) error {
+	err := mg.Verify()
+	if err != nil {
+		return err
+	}
+	applied, err := mg.Applied()
+	if err != nil {
+		return err
+	}
+	list := List()
+	for i := len(list) - 1; i >= 0; i-- {
+		m := list[i]
+		if m.Version <= to {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		steps, err := m.DownSteps()
+		if err != nil {
+			return fmt.Errorf("migration %d '%s': %s", m.Version, m.Name, err)
+		}
+		err = mg.run(m, "down", steps, func(tx *sql.Tx) error {
+			q, err := mg.adapter.SimpleDelete("", "schema_migrations", "version=?")
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(q, m.Version)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// run executes the steps of a migration in a transaction along with the change to it's record, MySQL commits implicitly on most schema changes, but the others can back out of a half-finished migration.
+// Every query is built before anything is run, so a step which doesn't work on this adapter doesn't leave the migration half done.
+func (mg *Migrator) run(m *Migration, dir string, steps []Step, record func(tx *sql.Tx) error) error {
+	qs := make([]string, len(steps))
+	for i, step := range steps {
+		q, err := step.SQL(mg.adapter)
+		if err != nil {
+			return fmt.Errorf("migration %d '%s': step %d: %s", m.Version, m.Name, i+1, err)
+		}
+		qs[i] = q
+	}
+
+	fmt.Fprintf(mg.Out, "-- Migration %d '%s' (%s)\n", m.Version, m.Name, dir)
+	if mg.DryRun {
+		for i, q := range qs {
+			if f, ok := steps[i].(Func); ok {
+				fmt.Fprintf(mg.Out, "-- '%s' can't be written as SQL, it has to be run by Gosora's migrator\n", f.Name)
+			} else if q != "" {
+				fmt.Fprintln(mg.Out, q+";")
+			}
+		}
+		return nil
+	}
+
+	tx, err := mg.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i, q := range qs {
+		if f, ok := steps[i].(Func); ok {
+			err = f.Run(tx, mg.adapter)
+			if err != nil {
+				return fmt.Errorf("migration %d '%s' failed on '%s': %s", m.Version, m.Name, f.Name, err)
+			}
+			continue
+		}
+		if q == "" {
+			continue
+		}
+		_, err = tx.Exec(q)
+		if err != nil {
+			return fmt.Errorf("migration %d '%s' failed on '%s': %s", m.Version, m.Name, q, err)
+		}
+	}
+	err = record(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Status writes out every migration and whether it has been applied
+func (mg *Migrator) Status() error {
+	applied, err := mg.Applied()
+	if err != nil {
+		return err
+	}
+	list := List()
+	if len(list) == 0 {
+		fmt.Fprintln(mg.Out, "There aren't any migrations")
+	}
+	for _, m := range list {
+		state := "pending"
+		if a, ok := applied[m.Version]; ok {
+			state = "applied " + a.AppliedAt.Format("2006-01-02 15:04:05")
+			if a.Checksum != m.Checksum() {
+				state += ", changed since"
+			}
+		}
+		fmt.Fprintf(mg.Out, "%5s  %-30s  %s\n", strconv.Itoa(m.Version), m.Name, state)
+	}
+	return nil
+}
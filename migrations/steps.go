@@ -0,0 +1,216 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+type tC = qgen.DBTableColumn
+type tK = qgen.DBTableKey
+
+// Step is a single change within a migration, it turns itself into SQL for whichever adapter it's given
+type Step interface {
+	SQL(a qgen.Adapter) (string, error)
+}
+
+// Reverser is implemented by the steps which can work out how to undo themselves
+type Reverser interface {
+	Reverse() Step
+}
+
+type CreateTable struct {
+	Table     string
+	Charset   string
+	Collation string
+	Columns   []tC
+	Keys      []tK
+}
+
+func (s CreateTable) SQL(a qgen.Adapter) (string, error) {
+	return a.CreateTable("", s.Table, s.Charset, s.Collation, s.Columns, s.Keys)
+}
+
+func (s CreateTable) Reverse() Step {
+	return DropTable{s.Table}
+}
+
+type DropTable struct {
+	Table string
+}
+
+func (s DropTable) SQL(a qgen.Adapter) (string, error) {
+	return a.DropTable("", s.Table)
+}
+
+type AddColumn struct {
+	Table  string
+	Column tC
+	Key    *tK
+}
+
+func (s AddColumn) SQL(a qgen.Adapter) (string, error) {
+	return a.AddColumn("", s.Table, s.Column, s.Key)
+}
+
+func (s AddColumn) Reverse() Step {
+	return DropColumn{s.Table, s.Column.Name}
+}
+
+type DropColumn struct {
+	Table  string
+	Column string
+}
+
+func (s DropColumn) SQL(a qgen.Adapter) (string, error) {
+	return a.DropColumn("", s.Table, s.Column)
+}
+
+type RenameColumn struct {
+	Table string
+	From  string
+	To    string
+}
+
+func (s RenameColumn) SQL(a qgen.Adapter) (string, error) {
+	return a.RenameColumn("", s.Table, s.From, s.To)
+}
+
+func (s RenameColumn) Reverse() Step {
+	return RenameColumn{s.Table, s.To, s.From}
+}
+
+// ChangeColumn replaces the definition of Column with To, which may have a different name
+type ChangeColumn struct {
+	Table  string
+	Column string
+	To     tC
+}
+
+func (s ChangeColumn) SQL(a qgen.Adapter) (string, error) {
+	return a.ChangeColumn("", s.Table, s.Column, s.To)
+}
+
+type SetDefault struct {
+	Table   string
+	Column  string
+	Type    string
+	Default string
+}
+
+func (s SetDefault) SQL(a qgen.Adapter) (string, error) {
+	return a.SetDefaultColumn("", s.Table, s.Column, s.Type, s.Default)
+}
+
+type AddIndex struct {
+	Table  string
+	Index  string
+	Column string
+}
+
+func (s AddIndex) SQL(a qgen.Adapter) (string, error) {
+	return a.AddIndex("", s.Table, s.Index, s.Column)
+}
+
+func (s AddIndex) Reverse() Step {
+	return RemoveIndex{s.Table, s.Index}
+}
+
+type RemoveIndex struct {
+	Table string
+	Index string
+}
+
+func (s RemoveIndex) SQL(a qgen.Adapter) (string, error) {
+	return a.RemoveIndex("", s.Table, s.Index)
+}
+
+type AddKey struct {
+	Table   string
+	Columns string
+	Key     tK
+}
+
+func (s AddKey) SQL(a qgen.Adapter) (string, error) {
+	return a.AddKey("", s.Table, s.Columns, s.Key)
+}
+
+type AddForeignKey struct {
+	Table   string
+	Column  string
+	FTable  string
+	FColumn string
+	Cascade bool
+}
+
+func (s AddForeignKey) SQL(a qgen.Adapter) (string, error) {
+	return a.AddForeignKey("", s.Table, s.Column, s.FTable, s.FColumn, s.Cascade)
+}
+
+// Insert seeds a row, Fields uses the same syntax as the query generator, so string literals need to be quoted
+type Insert struct {
+	Table   string
+	Columns string
+	Fields  string
+}
+
+func (s Insert) SQL(a qgen.Adapter) (string, error) {
+	return a.SimpleInsert("", s.Table, s.Columns, s.Fields)
+}
+
+type Update struct {
+	Table string
+	Set   string
+	Where string
+}
+
+func (s Update) SQL(a qgen.Adapter) (string, error) {
+	return a.Builder().Update().Table(s.Table).Set(s.Set).Where(s.Where).Text()
+}
+
+type Delete struct {
+	Table string
+	Where string
+}
+
+func (s Delete) SQL(a qgen.Adapter) (string, error) {
+	return a.SimpleDelete("", s.Table, s.Where)
+}
+
+// Except leaves Step out on the adapters named in Adapters, for changes which don't mean anything there, such as the size of a column on SQLite
+type Except struct {
+	Adapters []string
+	Step     Step
+}
+
+func (s Except) SQL(a qgen.Adapter) (string, error) {
+	for _, name := range s.Adapters {
+		if name == a.GetName() {
+			return "", nil
+		}
+	}
+	return s.Step.SQL(a)
+}
+
+// Raw is a last resort for things the query generator can't express, there has to be a query for every adapter the migration is run on
+type Raw map[string]string
+
+func (s Raw) SQL(a qgen.Adapter) (string, error) {
+	q, ok := s[a.GetName()]
+	if !ok {
+		return "", errors.New("there isn't a raw query for the " + a.GetName() + " adapter")
+	}
+	return q, nil
+}
+
+// Func is for changes which have to be worked out in Go, such as editing the JSON kept in a column, Run is called inside the migration's transaction.
+// There isn't any SQL for it, so dry runs can only point out that it's there, the Name goes into the checksum in it's place.
+type Func struct {
+	Name string
+	Run  func(tx *sql.Tx, a qgen.Adapter) error `json:"-"`
+}
+
+func (s Func) SQL(a qgen.Adapter) (string, error) {
+	return "", nil
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"archive/zip"
 	"bytes"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http/httptest"
 	"os"
+	"regexp"
 	"runtime/debug"
 	"strconv"
 	"strings"
@@ -16,6 +19,8 @@ import (
 	c "github.com/Azareal/Gosora/common"
 	"github.com/Azareal/Gosora/common/gauth"
 	"github.com/Azareal/Gosora/common/phrases"
+	"github.com/Azareal/Gosora/migrations"
+	qgen "github.com/Azareal/Gosora/query_gen"
 	"github.com/pkg/errors"
 )
 
@@ -778,6 +783,271 @@ func TestForumPermsStore(t *testing.T) {
 	initialState()
 }
 
+func TestForumTree(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+	getForum := func(fid int) *c.Forum {
+		f, err := c.Forums.Get(fid)
+		expectNilErr(t, err)
+		return f
+	}
+	canSee := func(gid, fid int) bool {
+		g, err := c.Groups.Get(gid)
+		expectNilErr(t, err)
+		for _, cfid := range g.CanSee {
+			if cfid == fid {
+				return true
+			}
+		}
+		return false
+	}
+
+	catID, err := c.Forums.Create("Tree Category", "", true, "all")
+	expectNilErr(t, err)
+	aID, err := c.Forums.Create("Tree Forum", "", true, "members")
+	expectNilErr(t, err)
+	bID, err := c.Forums.Create("Tree Subforum", "", true, "inherit")
+	expectNilErr(t, err)
+	cID, err := c.Forums.Create("Tree Subsubforum", "", true, "inherit")
+	expectNilErr(t, err)
+
+	expectNilErr(t, getForum(catID).SetCategory(true))
+	expectNilErr(t, getForum(aID).SetParent(catID))
+	expectNilErr(t, getForum(bID).SetParent(aID))
+	expectNilErr(t, getForum(cID).SetParent(bID))
+	expect(t, getForum(catID).Category, "the category should be a category")
+	f := getForum(cID)
+	expectf(t, f.ParentID == bID && f.ParentType == "forum", "forum #%d should be in #%d not %s #%d", cID, bID, f.ParentType, f.ParentID)
+
+	parents := f.Parents()
+	expectf(t, len(parents) == 3, "forum #%d should have 3 parents not %d", cID, len(parents))
+	if len(parents) == 3 {
+		expect(t, parents[0].ID == catID && parents[1].ID == aID && parents[2].ID == bID, "the parents should start from the top")
+	}
+
+	// The subforums don't have any permissions of their own, so they should take them from the forum above them
+	for _, fid := range []int{bID, cID} {
+		fp, err := c.FPStore.Get(fid, 3)
+		expectNilErr(t, err)
+		expectf(t, fp.ViewTopic && fp.CreateTopic, "members should be able to see and post in forum #%d", fid)
+		fp, err = c.FPStore.Get(fid, 6)
+		expectNilErr(t, err)
+		expectf(t, !fp.ViewTopic, "guests shouldn't be able to see forum #%d", fid)
+		expectf(t, canSee(3, fid), "forum #%d should be in the members' CanSee", fid)
+		expectf(t, !canSee(6, fid), "forum #%d shouldn't be in the guests' CanSee", fid)
+	}
+	// Opening a subforum up doesn't do anything when the forum it's in is hidden
+	f = getForum(bID)
+	expectNilErr(t, f.Update(f.Name, f.Desc, f.Active, "all"))
+	fp, err := c.FPStore.Get(bID, 6)
+	expectNilErr(t, err)
+	expect(t, fp.ViewTopic, "guests should be able to see the subforum on its own")
+	expect(t, !canSee(6, bID), "the subforum shouldn't be in the guests' CanSee while the forum it's in is hidden")
+	expect(t, canSee(6, catID), "the category should be in the guests' CanSee")
+
+	// Only the members can see anything in the category, so the guests shouldn't get an empty heading
+	g, err := c.Groups.Get(6)
+	expectNilErr(t, err)
+	tree, err := c.NewForumTree(g.CanSee)
+	expectNilErr(t, err)
+	for _, cat := range tree.Categories() {
+		expect(t, cat.ID != catID, "the guests shouldn't see the empty category")
+	}
+	g, err = c.Groups.Get(3)
+	expectNilErr(t, err)
+	tree, err = c.NewForumTree(g.CanSee)
+	expectNilErr(t, err)
+	var found bool
+	for _, cat := range tree.Categories() {
+		if cat.ID != catID {
+			continue
+		}
+		found = true
+		expectf(t, len(cat.ItemList) == 1 && cat.ItemList[0].ID == aID, "forum #%d should be the only one in the category", aID)
+		if len(cat.ItemList) == 1 {
+			subs := cat.ItemList[0].Subforums
+			expectf(t, len(subs) == 1 && subs[0].ID == bID, "forum #%d should be the only subforum in #%d", bID, aID)
+		}
+	}
+	expect(t, found, "the members should see the category")
+
+	// Topics can't go in categories, or categories inside other forums
+	expectNilErr(t, getForum(catID).SetCategory(true))
+	expect(t, getForum(catID).SetParent(aID) == c.ErrCategoryParent, "categories shouldn't be put inside other forums")
+	expect(t, getForum(bID).SetCategory(true) == c.ErrCategoryParent, "forums inside other forums shouldn't be turned into categories")
+	expect(t, c.Forums.UpdateTree([]c.ForumTreeItem{{aID, cID, 0}}) == c.ErrForumParentLoop, "a forum shouldn't be put inside one of its subforums")
+	expect(t, c.Forums.UpdateTree([]c.ForumTreeItem{{aID, aID, 0}}) == c.ErrForumParentLoop, "a forum shouldn't be put inside itself")
+	expect(t, c.Forums.UpdateTree([]c.ForumTreeItem{{aID, 9999, 0}}) == c.ErrNoParentForum, "a forum shouldn't be put inside one which doesn't exist")
+	f = getForum(aID)
+	expectf(t, f.ParentID == catID, "forum #%d should still be in #%d not #%d", aID, catID, f.ParentID)
+
+	// Move the subsubforum up to the top level and the subforum to the front of the category
+	expectNilErr(t, c.Forums.UpdateTree([]c.ForumTreeItem{{cID, 0, 5}, {bID, catID, 0}, {aID, catID, 1}}))
+	f = getForum(cID)
+	expectf(t, f.ParentID == 0 && f.ParentType == "", "forum #%d should be on the top level not in %s #%d", cID, f.ParentType, f.ParentID)
+	expect(t, len(f.Parents()) == 0, "a forum on the top level shouldn't have any parents")
+	f = getForum(bID)
+	expectf(t, f.ParentID == catID && f.Order == 0, "forum #%d should be at the front of #%d not %d in #%d", bID, catID, f.Order, f.ParentID)
+	expect(t, canSee(6, bID), "the subforum should be in the guests' CanSee now that it isn't in a hidden forum")
+	children, err := c.Forums.GetChildren(catID, "forum")
+	expectNilErr(t, err)
+	expectf(t, len(children) == 2 && children[0].ID == bID && children[1].ID == aID, "the category should have #%d then #%d in it", bID, aID)
+
+	// The forums inside a deleted one move up a level
+	expectNilErr(t, getForum(bID).SetParent(aID))
+	expectNilErr(t, c.Forums.Delete(aID))
+	f = getForum(bID)
+	expectf(t, f.ParentID == catID, "forum #%d should have moved up into #%d not #%d", bID, catID, f.ParentID)
+
+	for _, fid := range []int{bID, cID, catID} {
+		expectNilErr(t, c.Forums.Delete(fid))
+	}
+}
+
+func TestForumModStore(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+
+	aID, err := c.Forums.Create("Modded Forum", "", true, "all")
+	expectNilErr(t, err)
+	bID, err := c.Forums.Create("Modded Subforum", "", true, "inherit")
+	expectNilErr(t, err)
+	f, err := c.Forums.Get(bID)
+	expectNilErr(t, err)
+	expectNilErr(t, f.SetParent(aID))
+	otherID, err := c.Forums.Create("Unmodded Forum", "", true, "all")
+	expectNilErr(t, err)
+
+	uid, err := c.Users.Create("Forum Mod", "ReallyBadPassword", "forummod@localhost.loc", 3, true)
+	expectNilErr(t, err)
+	u, err := c.Users.Get(uid)
+	expectNilErr(t, err)
+	expect(t, !u.Perms.PinTopic && !u.Perms.DeleteReply, "members shouldn't be able to pin topics or delete replies")
+
+	_, err = c.ForumMods.Get(aID, uid)
+	recordMustNotExist(t, err, "the user shouldn't moderate the forum yet")
+	_, ok := c.ForumMods.PermsFor(aID, u)
+	expect(t, !ok, "the user shouldn't have any moderation permissions yet")
+
+	expectNilErr(t, c.ForumMods.Set(aID, uid, c.ForumModPerms{PinTopic: true, DeletePosts: true}))
+	m, err := c.ForumMods.Get(aID, uid)
+	expectNilErr(t, err)
+	expect(t, m.PinTopic && m.DeletePosts && !m.CloseTopic && !m.MoveTopic && !m.EditPosts, "the moderator should only be able to pin and delete")
+	mods := c.ForumMods.GetByForum(aID)
+	expectf(t, len(mods) == 1 && mods[0].UserID == uid, "user #%d should be the only moderator of forum #%d", uid, aID)
+
+	// The subforums are moderated by the same people as the forum above them
+	for _, fid := range []int{aID, bID} {
+		mp, ok := c.ForumMods.PermsFor(fid, u)
+		expectf(t, ok && mp.PinTopic && mp.DeletePosts && !mp.MoveTopic, "the user should be able to pin and delete in forum #%d", fid)
+	}
+	_, ok = c.ForumMods.PermsFor(otherID, u)
+	expectf(t, !ok, "the user shouldn't have any moderation permissions in forum #%d", otherID)
+
+	mu := *u
+	mp, _ := c.ForumMods.PermsFor(bID, u)
+	mp.Apply(&mu)
+	expect(t, mu.Perms.PinTopic && mu.Perms.DeleteTopic && mu.Perms.DeleteReply, "the moderator should be able to pin topics and delete posts")
+	expect(t, !mu.Perms.CloseTopic && !mu.Perms.MoveTopic && !mu.Perms.EditReply, "the moderator shouldn't get the permissions they weren't given")
+	expect(t, !u.Perms.PinTopic, "applying the permissions to a copy of the user shouldn't touch the original")
+
+	// Permissions in a subforum are added on top of the ones from the forum above it
+	expectNilErr(t, c.ForumMods.Set(bID, uid, c.ForumModPerms{MoveTopic: true}))
+	mp, ok = c.ForumMods.PermsFor(bID, u)
+	expect(t, ok && mp.PinTopic && mp.DeletePosts && mp.MoveTopic, "the user should be able to pin, delete and move in the subforum")
+	mp, _ = c.ForumMods.PermsFor(aID, u)
+	expect(t, !mp.MoveTopic, "the user shouldn't be able to move topics in the forum above the subforum")
+	expectNilErr(t, c.ForumMods.Set(aID, uid, c.ForumModPerms{CloseTopic: true}))
+	mp, _ = c.ForumMods.PermsFor(aID, u)
+	expect(t, mp.CloseTopic && !mp.PinTopic && !mp.DeletePosts, "the permissions should've been replaced")
+
+	// Guests and banned users don't moderate anything
+	_, ok = c.ForumMods.PermsFor(aID, &c.User{ID: 0})
+	expect(t, !ok, "guests shouldn't have any moderation permissions")
+	banned := *u
+	banned.IsBanned = true
+	_, ok = c.ForumMods.PermsFor(aID, &banned)
+	expect(t, !ok, "banned users shouldn't have any moderation permissions")
+
+	expectNilErr(t, c.ForumMods.Delete(bID, uid))
+	_, err = c.ForumMods.Get(bID, uid)
+	recordMustNotExist(t, err, "the user shouldn't moderate the subforum anymore")
+	expectNilErr(t, c.ForumMods.ReloadAll())
+	_, err = c.ForumMods.Get(aID, uid)
+	expectNilErr(t, err)
+
+	// Deleting the user takes them off the forums they moderate
+	expectNilErr(t, c.ForumMods.Set(otherID, uid, c.ForumModPerms{EditPosts: true}))
+	expectNilErr(t, u.Delete())
+	for _, fid := range []int{aID, otherID} {
+		_, err = c.ForumMods.Get(fid, uid)
+		recordMustNotExist(t, err, "the deleted user shouldn't moderate any forums")
+	}
+
+	expectNilErr(t, c.ForumMods.Set(aID, 1, c.ForumModPerms{PinTopic: true}))
+	for _, fid := range []int{bID, aID, otherID} {
+		expectNilErr(t, c.Forums.Delete(fid))
+	}
+	expect(t, len(c.ForumMods.GetByForum(aID)) == 0, "the deleted forum shouldn't have any moderators")
+}
+
+func TestOwnPostPerms(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+	sBox := c.SettingBox.Load().(c.SettingMap)
+	setWindows := func(edit, del string) {
+		expect(t, sBox.Update("own_post_edit_window", edit) == nil, "the edit window should've been updated")
+		expect(t, sBox.Update("own_post_delete_window", del) == nil, "the delete window should've been updated")
+		sBox = c.SettingBox.Load().(c.SettingMap)
+	}
+	setWindows("0", "0")
+	expect(t, c.OwnPostEditWindow() == 0 && c.OwnPostDeleteWindow() == 0, "there shouldn't be any windows by default")
+
+	u := &c.User{ID: 5, Loggedin: true}
+	u.Perms = c.Perms{ViewTopic: true, CreateReply: true, EditOwnTopic: true, DeleteOwnTopic: true, EditOwnReply: true, DeleteOwnReply: true}
+	now, old := time.Now(), time.Now().Add(-time.Hour)
+
+	expect(t, u.CanViewTopic(1) && u.CanViewTopic(5), "the user should be able to see every topic")
+	expect(t, u.CanEditTopic(5, old) && u.CanDeleteTopic(5, old), "the user should be able to change their own topics without a window")
+	expect(t, !u.CanEditTopic(1, now) && !u.CanDeleteTopic(1, now), "the user shouldn't be able to change other people's topics")
+	expect(t, u.CanEditReply(1, 5, old) && u.CanDeleteReply(1, 5, old), "the user should be able to change their own replies in other people's topics")
+	expect(t, !u.CanEditReply(5, 1, now) && !u.CanDeleteReply(5, 1, now), "the user shouldn't be able to change other people's replies in their own topics")
+	expect(t, !u.CanCloseTopic(5), "the user shouldn't be able to lock their own topics")
+
+	// The windows only apply to people changing their own posts
+	setWindows("30", "10")
+	expect(t, c.OwnPostEditWindow() == 30*time.Minute && c.OwnPostDeleteWindow() == 10*time.Minute, "the windows should be 30 and 10 minutes")
+	expect(t, c.InOwnPostWindow(now, c.OwnPostEditWindow()) && !c.InOwnPostWindow(old, c.OwnPostEditWindow()), "a post made an hour ago shouldn't be in a 30 minute window")
+	expect(t, u.CanEditTopic(5, now) && u.CanEditReply(1, 5, now), "the user should be able to edit their new posts")
+	expect(t, !u.CanEditTopic(5, old) && !u.CanEditReply(1, 5, old), "the user shouldn't be able to edit their old posts")
+	twenty := time.Now().Add(-20 * time.Minute)
+	expect(t, u.CanEditReply(1, 5, twenty) && !u.CanDeleteReply(1, 5, twenty), "the user should be able to edit but not delete a reply from twenty minutes ago")
+	mod := *u
+	mod.Perms.EditTopic, mod.Perms.DeleteReply = true, true
+	expect(t, mod.CanEditTopic(1, old) && mod.CanDeleteReply(1, 1, old), "the windows shouldn't apply to people who can change everyone's posts")
+
+	// Guests don't own anything, even the posts which have lost their owner
+	guest := &c.User{ID: 0}
+	guest.Perms = u.Perms
+	expect(t, !guest.CanEditReply(0, 0, now) && !guest.CanDeleteTopic(0, now), "guests shouldn't be able to change posts without an owner")
+
+	// People who can only see their own topics
+	own := &c.User{ID: 5, Loggedin: true}
+	own.Perms = c.Perms{ViewOwnTopic: true, CreateReplyToOwn: true, CloseOwnTopic: true, EditOwnReply: true}
+	expect(t, own.CanViewTopic(5) && !own.CanViewTopic(1), "the user should only be able to see their own topics")
+	expect(t, own.CanReplyTo(5) && !own.CanReplyTo(1), "the user should only be able to reply to their own topics")
+	expect(t, own.CanCloseTopic(5) && !own.CanCloseTopic(1), "the user should only be able to lock their own topics")
+	expect(t, !own.CanEditReply(1, 5, now), "the user shouldn't be able to edit their replies in topics they can't see")
+
+	setWindows("0", "0")
+}
+
 // TODO: Test the group permissions
 // TODO: Test group.CanSee for forum presets + group perms
 func TestGroupStore(t *testing.T) {
@@ -1052,7 +1322,7 @@ func testReplyStore(t *testing.T, newID, newPostCount int, ip string) {
 
 	reply, err := c.Rstore.Get(rid)
 	expectNilErr(t, err)
-	expectNilErr(t, reply.SetPost("huuu"))
+	expectNilErr(t, reply.SetPost("huuu", ip, 1))
 	expectf(t, reply.Content == "hiii", "topic.Content should be hiii, not %s", reply.Content)
 	reply, err = c.Rstore.Get(rid)
 	expectNilErr(t, err)
@@ -1831,6 +2101,56 @@ func TestPhrases(t *testing.T) {
 	// TODO: Cover the other phrase types, also try switching between languages to see if anything strange happens
 }
 
+func TestLangPacks(t *testing.T) {
+	mal := func(header, expects string) {
+		lp := phrases.MatchAcceptLanguage(header)
+		name := ""
+		if lp != nil {
+			name = lp.Name
+		}
+		expectf(t, name == expects, "Accept-Language %q should match %q not %q", header, expects, name)
+	}
+	mal("en", "english")
+	mal("EN-us", "english")
+	mal("fr-FR,fr;q=0.9,en;q=0.8", "english")
+	mal("fr-FR,en;q=0", "")
+	mal("*", "")
+	mal("", "")
+
+	name := phrases.GetLangPack().Name
+	tp := func(phrase string) {
+		lp := phrases.GetLangPack()
+		expectf(t, lp.TmplPhrase("test_phrase") == phrase, "test_phrase should be %q not %q", phrase, lp.TmplPhrase("test_phrase"))
+	}
+	expect(t, phrases.AddPhrase("nope", "TmplPhrases", "test_phrase", "a") == phrases.ErrNoLangPack, "the nope pack shouldn't exist")
+	expect(t, phrases.AddPhrase(name, "Nope", "test_phrase", "a") == phrases.ErrNoPhraseGroup, "the Nope group shouldn't exist")
+	expect(t, phrases.AddPhrase(name, "TmplPhrases", "", "a") == phrases.ErrBlankPhraseName, "the phrase name shouldn't be blank")
+	expect(t, phrases.AddPhrase(name, "TmplPhrases", "login_head", "a") == phrases.ErrPhraseExists, "login_head should already exist")
+	expect(t, phrases.UpdatePhrase(name, "TmplPhrases", "test_phrase", "a") == phrases.ErrNoPhrase, "test_phrase shouldn't exist yet")
+
+	expectNilErr(t, phrases.AddPhrase(name, "TmplPhrases", "test_phrase", "a"))
+	tp("a")
+	lp := phrases.GetLangPack()
+	expect(t, lp.IsChanged("TmplPhrases", "test_phrase") && lp.IsCustom("TmplPhrases", "test_phrase"), "test_phrase should be a custom phrase")
+	expectNilErr(t, phrases.UpdatePhrase(name, "TmplPhrases", "test_phrase", "b"))
+	tp("b")
+	expectNilErr(t, phrases.DeletePhrase(name, "TmplPhrases", "test_phrase"))
+	tp("{lang.tmpl[test_phrase]}")
+	expect(t, phrases.DeletePhrase(name, "TmplPhrases", "test_phrase") == phrases.ErrNoPhrase, "test_phrase shouldn't exist anymore")
+
+	// Changing a built-in phrase back to how it was drops the override
+	orig := phrases.GetTmplPhrase("login_head")
+	expect(t, phrases.DeletePhrase(name, "TmplPhrases", "login_head") == phrases.ErrPhraseBuiltin, "login_head shouldn't be deletable")
+	expectNilErr(t, phrases.UpdatePhrase(name, "TmplPhrases", "login_head", "Sign in"))
+	lp = phrases.GetLangPack()
+	expect(t, lp.TmplPhrase("login_head") == "Sign in", "login_head should have changed")
+	expect(t, lp.IsChanged("TmplPhrases", "login_head") && !lp.IsCustom("TmplPhrases", "login_head"), "login_head should be changed, but not custom")
+	expectNilErr(t, phrases.UpdatePhrase(name, "TmplPhrases", "login_head", orig))
+	lp = phrases.GetLangPack()
+	expect(t, !lp.IsChanged("TmplPhrases", "login_head"), "login_head shouldn't be changed anymore")
+	expect(t, len(lp.Overrides) == 0, "there shouldn't be any overrides left")
+}
+
 func TestMetaStore(t *testing.T) {
 	m, err := c.Meta.Get("magic")
 	expect(t, m == "", "meta var magic should be empty")
@@ -1977,6 +2297,437 @@ func TestWordFilters(t *testing.T) {
 	// TODO: Any more tests we could do?
 }
 
+func TestProfileFields(t *testing.T) {
+	expect(t, c.ProfileFields.Length() == 0, "Profile field list should be empty")
+	expect(t, c.ProfileFields.Count() == 0, "Profile field list should be empty")
+	expect(t, len(c.ProfileFields.GetAll()) == 0, "Profile field list should be empty")
+	_, err := c.ProfileFields.Get(1)
+	recordMustNotExist(t, err, "profile field 1 should not exist")
+
+	pfid, err := c.ProfileFields.Create(&c.ProfileField{Name: "Website", Type: "url", Privacy: 1, ShowOnPosts: true})
+	expectNilErr(t, err)
+	expect(t, pfid == 1, "The first profile field should have an ID of 1")
+	expect(t, c.ProfileFields.Length() == 1, "Profile field list should not be empty")
+	expect(t, c.ProfileFields.Count() == 1, "Profile field list should not be empty")
+	f, err := c.ProfileFields.Get(1)
+	expectNilErr(t, err)
+	expect(t, f.Name == "Website", "Profile field name should be Website")
+	expect(t, f.Type == "url", "Profile field type should be url")
+
+	pfid2, err := c.ProfileFields.Create(&c.ProfileField{Name: "Colour", Type: "select", Options: []string{"Red", "Blue"}, Required: true, Privacy: 2, Order: 1})
+	expectNilErr(t, err)
+	f2, err := c.ProfileFields.Get(pfid2)
+	expectNilErr(t, err)
+	expect(t, len(f2.Options) == 2, "There should be two options")
+	expect(t, c.ProfileFields.GetAll()[1].ID == pfid2, "Colour should be after Website")
+
+	// Validation
+	val, err := f.Validate(" https://example.com ")
+	expectNilErr(t, err)
+	expect(t, val == "https://example.com", "val should be https://example.com not "+val)
+	_, err = f.Validate("javascript:alert(1)")
+	expect(t, err == c.ErrProfileFieldBadURL, "javascript: should not be a valid URL")
+	val, err = f.Validate("")
+	expect(t, err == nil && val == "", "Website isn't required")
+	_, err = f2.Validate("")
+	expect(t, err == c.ErrProfileFieldRequired, "Colour is required")
+	_, err = f2.Validate("Green")
+	expect(t, err == c.ErrProfileFieldBadOption, "Green isn't an option")
+	_, err = f2.Validate("Blue")
+	expectNilErr(t, err)
+	_, err = (&c.ProfileField{Type: "date"}).Validate("2020-13-01")
+	expect(t, err == c.ErrProfileFieldBadDate, "2020-13-01 isn't a valid date")
+	_, err = (&c.ProfileField{Type: "text", MaxLength: 3}).Validate("abcd")
+	expect(t, err == c.ErrProfileFieldTooLong, "abcd should be too long")
+
+	// Values and visibility
+	owner := &c.User{ID: 1, Group: 1, Loggedin: true}
+	guest := &c.GuestUser
+	member := &c.User{ID: 2, Group: 3, Loggedin: true}
+	expectNilErr(t, c.ProfileFields.SetValue(1, pfid, "https://example.com", 0))
+	expectNilErr(t, c.ProfileFields.SetValue(1, pfid2, "Blue", 0))
+	vals, err := c.ProfileFields.Values(1)
+	expectNilErr(t, err)
+	expect(t, len(vals) == 2, "uid 1 should have two values")
+	expect(t, vals[pfid].Value == "https://example.com", "uid 1 should have https://example.com for Website")
+
+	shown, err := c.ProfileFields.Show(owner, guest)
+	expectNilErr(t, err)
+	expect(t, len(shown) == 1, "guests should only see Website")
+	expect(t, shown[0].URL == "https://example.com", "Website should be a link")
+	shown, err = c.ProfileFields.Show(owner, member)
+	expectNilErr(t, err)
+	expect(t, len(shown) == 2, "members should see both fields")
+
+	expectNilErr(t, c.ProfileFields.SetValue(1, pfid, "https://example.com", 3))
+	shown, err = c.ProfileFields.Show(owner, member)
+	expectNilErr(t, err)
+	expect(t, len(shown) == 1, "Website should be hidden from members now")
+	shown, err = c.ProfileFields.Show(owner, owner)
+	expectNilErr(t, err)
+	expect(t, len(shown) == 2, "owners should always see their own fields")
+
+	postFields, err := c.ProfileFields.BulkShowOnPosts([]int{1, 2}, owner)
+	expectNilErr(t, err)
+	expect(t, len(postFields[1]) == 1, "only Website is shown on posts")
+
+	expectNilErr(t, c.ProfileFields.SetValue(1, pfid2, "", 0))
+	vals, err = c.ProfileFields.Values(1)
+	expectNilErr(t, err)
+	expect(t, len(vals) == 1, "uid 1 should have one value")
+
+	// Clean up
+	expectNilErr(t, c.ProfileFields.Delete(pfid))
+	expectNilErr(t, c.ProfileFields.Delete(pfid2))
+	expect(t, c.ProfileFields.Length() == 0, "Profile field list should be empty")
+	expect(t, c.ProfileFields.Count() == 0, "Profile field list should be empty")
+	vals, err = c.ProfileFields.Values(1)
+	expectNilErr(t, err)
+	expect(t, len(vals) == 0, "uid 1 shouldn't have any values left")
+}
+
+func TestTopicPrefixes(t *testing.T) {
+	expect(t, c.TopicPrefixes.Length() == 0, "Topic prefix list should be empty")
+	expect(t, c.TopicPrefixes.Count() == 0, "Topic prefix list should be empty")
+	_, err := c.TopicPrefixes.Get(1)
+	recordMustNotExist(t, err, "topic prefix 1 should not exist")
+
+	prid, err := c.TopicPrefixes.Create(&c.TopicPrefix{Name: "Solved", CSSClass: "prefix_solved"})
+	expectNilErr(t, err)
+	expect(t, prid == 1, "The first topic prefix should have an ID of 1")
+	prid2, err := c.TopicPrefixes.Create(&c.TopicPrefix{Name: "Bug", Forums: []int{2}, Groups: []int{1, 2}, Order: 1})
+	expectNilErr(t, err)
+	expect(t, c.TopicPrefixes.Length() == 2, "There should be two topic prefixes")
+	expect(t, c.TopicPrefixes.Count() == 2, "There should be two topic prefixes")
+	p2, err := c.TopicPrefixes.Get(prid2)
+	expectNilErr(t, err)
+	expect(t, len(p2.Forums) == 1 && p2.Forums[0] == 2, "Bug should only apply to forum 2")
+	expect(t, len(p2.Groups) == 2, "Bug should have two groups")
+	expect(t, c.TopicPrefixes.GetAll()[1].ID == prid2, "Bug should be after Solved")
+
+	member := &c.User{ID: 2, Group: 3, Loggedin: true}
+	admin := &c.User{ID: 1, Group: 1, Loggedin: true, IsSuperMod: true}
+	expect(t, len(c.TopicPrefixes.GetUsable(1, admin)) == 1, "only Solved applies to forum 1")
+	expect(t, len(c.TopicPrefixes.GetUsable(2, admin)) == 2, "both prefixes apply to forum 2")
+	expect(t, len(c.TopicPrefixes.GetUsable(2, member)) == 1, "members shouldn't be able to use Bug")
+
+	tid, err := c.Topics.Create(2, "Prefix Test", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	topic, err := c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expectNilErr(t, topic.SetPrefix(prid2))
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.Prefix == prid2, "The topic should have the Bug prefix")
+
+	p2.Name = "Bugs"
+	expectNilErr(t, c.TopicPrefixes.Update(p2))
+	p2, err = c.TopicPrefixes.Get(prid2)
+	expectNilErr(t, err)
+	expect(t, p2.Name == "Bugs", "The prefix should have been renamed to Bugs")
+
+	expectNilErr(t, c.TopicPrefixes.Delete(prid2))
+	_, err = c.TopicPrefixes.Get(prid2)
+	recordMustNotExist(t, err, "the Bugs prefix should not exist")
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.Prefix == 0, "Deleting a prefix should take it off topics")
+
+	// Clean up
+	expectNilErr(t, topic.Delete())
+	expectNilErr(t, c.TopicPrefixes.Delete(prid))
+	expect(t, c.TopicPrefixes.Length() == 0, "Topic prefix list should be empty")
+	expect(t, c.TopicPrefixes.Count() == 0, "Topic prefix list should be empty")
+}
+
+func TestTopicTags(t *testing.T) {
+	expect(t, c.NormaliseTag("  Go Lang ") == "go-lang", "Go Lang should be normalised to go-lang")
+	expect(t, c.NormaliseTag("c++") == "c", "c++ should be normalised to c")
+	expect(t, c.NormaliseTag("--a--b--") == "a-b", "--a--b-- should be normalised to a-b")
+	expect(t, c.NormaliseTag("<script>") == "script", "<script> should be normalised to script")
+	expect(t, len(c.NormaliseTag(strings.Repeat("a", 50))) == c.TopicTagMaxLength, "long tags should be truncated")
+	tags := c.ParseTags("go, Go ,, rust,!!")
+	expect(t, len(tags) == 2, "there should be two tags")
+	expect(t, tags[0] == "go" && tags[1] == "rust", "the tags should be go and rust")
+	expect(t, len(c.ParseTags(strings.Repeat("a,b,c,d,e,f,g,h,i,j,k,l,", 2))) == c.TopicTagMaxCount, "there shouldn't be more than TopicTagMaxCount tags")
+	expect(t, c.BuildTagURL("go-lang") == "/tags/go-lang", "the tag URL should be /tags/go-lang")
+
+	tid, err := c.Topics.Create(2, "Tag Test", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	tid2, err := c.Topics.Create(2, "Tag Test 2", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	expect(t, !c.TopicTags.Exists("go"), "the go tag should not exist yet")
+	expectNilErr(t, c.TopicTags.Set(tid, []string{"rust", "go"}))
+	expectNilErr(t, c.TopicTags.Set(tid2, []string{"go", "go_lang"}))
+	expect(t, c.TopicTags.Exists("go"), "the go tag should exist")
+
+	tags, err = c.TopicTags.Get(tid)
+	expectNilErr(t, err)
+	expect(t, len(tags) == 2 && tags[0] == "go" && tags[1] == "rust", "the tags on the topic should be go and rust")
+	tmap, err := c.TopicTags.BulkGetMap([]int{tid, tid2})
+	expectNilErr(t, err)
+	expect(t, len(tmap[tid2]) == 2, "the second topic should have two tags")
+	tids, err := c.TopicTags.Tids("go")
+	expectNilErr(t, err)
+	expect(t, len(tids) == 2, "two topics should have the go tag")
+
+	tags, err = c.TopicTags.Suggest("g", 5)
+	expectNilErr(t, err)
+	expect(t, len(tags) == 2 && tags[0] == "go", "go should be the top suggestion")
+	tags, err = c.TopicTags.Suggest("go_", 5)
+	expectNilErr(t, err)
+	expect(t, len(tags) == 1 && tags[0] == "go_lang", "underscores shouldn't be wildcards")
+	tags, err = c.TopicTags.InForums([]int{2}, 0)
+	expectNilErr(t, err)
+	expect(t, len(tags) == 3, "there should be three distinct tags in forum 2")
+
+	expectNilErr(t, c.TopicTags.Set(tid, nil))
+	tags, err = c.TopicTags.Get(tid)
+	expectNilErr(t, err)
+	expect(t, len(tags) == 0, "the first topic shouldn't have any tags")
+
+	// Clean up
+	topic, err := c.Topics.Get(tid2)
+	expectNilErr(t, err)
+	expectNilErr(t, topic.Delete())
+	expect(t, !c.TopicTags.Exists("go"), "deleting the topic should delete it's tags")
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expectNilErr(t, topic.Delete())
+}
+
+func TestAnswers(t *testing.T) {
+	forum, err := c.Forums.Get(2)
+	expectNilErr(t, err)
+	expect(t, !forum.Questions, "forum 2 shouldn't be in question mode yet")
+	expectNilErr(t, forum.SetQuestions(true))
+	forum, err = c.Forums.Get(2)
+	expectNilErr(t, err)
+	expect(t, forum.Questions, "forum 2 should be in question mode")
+
+	tid, err := c.Topics.Create(2, "Question Test", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	topic, err := c.Topics.Get(tid)
+	expectNilErr(t, err)
+	rid, err := c.Rstore.Create(topic, "Answer One", "", 1)
+	expectNilErr(t, err)
+	rid2, err := c.Rstore.Create(topic, "Answer Two", "", 1)
+	expectNilErr(t, err)
+	reply, err := c.Rstore.Get(rid2)
+	expectNilErr(t, err)
+
+	solved := c.TopicListFilter{Solved: c.TopicListSolvedOnly}
+	tlist, _, _, err := c.TopicList.GetListByCanSee([]int{2}, 1, 0, nil, solved)
+	expectNilErr(t, err)
+	expect(t, len(tlist) == 0, "there shouldn't be any solved questions yet")
+	expectNilErr(t, topic.SetAnswer(rid))
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.Answer == rid, "the first reply should be the answer")
+	tlist, _, _, err = c.TopicList.GetListByCanSee([]int{2}, 1, 0, nil, solved)
+	expectNilErr(t, err)
+	expect(t, len(tlist) == 1 && tlist[0].ID == tid, "the question should be in the solved list")
+	tlist, _, _, err = c.TopicList.GetListByCanSee([]int{2}, 1, 0, nil, c.TopicListFilter{Solved: c.TopicListUnsolvedOnly})
+	expectNilErr(t, err)
+	for _, row := range tlist {
+		expect(t, row.ID != tid, "the question shouldn't be in the unsolved list")
+	}
+
+	expect(t, c.Votes.Count() == 0, "there shouldn't be any votes yet")
+	expectNilErr(t, c.Votes.Vote(reply, 2, 1))
+	expectNilErr(t, c.Votes.Vote(reply, 3, 1))
+	reply, err = c.Rstore.Get(rid2)
+	expectNilErr(t, err)
+	expect(t, reply.Votes == 2, fmt.Sprintf("the reply should have two votes, not %d", reply.Votes))
+	expectNilErr(t, c.Votes.Vote(reply, 2, -1))
+	reply, err = c.Rstore.Get(rid2)
+	expectNilErr(t, err)
+	expect(t, reply.Votes == 0, fmt.Sprintf("flipping a vote should bring the reply to zero, not %d", reply.Votes))
+	weight, err := c.Votes.Get(rid2, 2)
+	expectNilErr(t, err)
+	expect(t, weight == -1, "user 2 should have downvoted the reply")
+	expectNilErr(t, c.Votes.Vote(reply, 3, 1))
+	reply, err = c.Rstore.Get(rid2)
+	expectNilErr(t, err)
+	expect(t, reply.Votes == -1, "voting the same way twice should take the vote back")
+	vmap, err := c.Votes.BulkGetMap([]int{rid, rid2}, 2)
+	expectNilErr(t, err)
+	expect(t, len(vmap) == 1 && vmap[rid2] == -1, "user 2 should only have voted on the second reply")
+	expect(t, c.Votes.Vote(reply, 2, 2) == c.ErrBadVoteWeight, "votes should be either 1 or -1")
+
+	answer, err := c.Rstore.Get(rid)
+	expectNilErr(t, err)
+	expectNilErr(t, answer.Delete())
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.Answer == 0, "deleting the answer should unset it")
+
+	// Clean up
+	expectNilErr(t, topic.Delete())
+	expect(t, c.Votes.Count() == 0, "deleting the topic should delete the votes on it's replies")
+	expectNilErr(t, forum.SetQuestions(false))
+}
+
+func TestTopicMerge(t *testing.T) {
+	getTopic := func(tid int) *c.Topic {
+		topic, err := c.Topics.Get(tid)
+		expectNilErr(t, err)
+		return topic
+	}
+	topicCount := func() int {
+		forum, err := c.Forums.Get(2)
+		expectNilErr(t, err)
+		return forum.TopicCount
+	}
+	tid, err := c.Topics.Create(2, "Merge Test", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	startCount := topicCount()
+	topic := getTopic(tid)
+	var rids []int
+	for i := 0; i < 4; i++ {
+		rid, err := c.Rstore.Create(topic, "Reply "+strconv.Itoa(i), "", 1)
+		expectNilErr(t, err)
+		rids = append(rids, rid)
+	}
+	reply, err := c.Rstore.Get(rids[1])
+	expectNilErr(t, err)
+	expectNilErr(t, reply.Like(2))
+
+	topic = getTopic(tid)
+	expect(t, topic.PostCount == 5, fmt.Sprintf("the topic should have 5 posts, not %d", topic.PostCount))
+	_, err = topic.Split(nil, "Split Test", 2)
+	expect(t, err == c.ErrNoRepliesPicked, "splitting nothing off should fail")
+	_, err = topic.Split([]int{tid + 1000}, "Split Test", 2)
+	expect(t, err == c.ErrReplyNotInTopic, "replies from elsewhere shouldn't be split off")
+
+	// The second reply becomes the opening post of the new topic, taking it's like with it
+	tid2, err := topic.Split([]int{rids[2], rids[1]}, "Split Test", 2)
+	expectNilErr(t, err)
+	topic, topic2 := getTopic(tid), getTopic(tid2)
+	expect(t, topic.PostCount == 3, fmt.Sprintf("the old topic should have 3 posts, not %d", topic.PostCount))
+	expect(t, topic2.PostCount == 2, fmt.Sprintf("the new topic should have 2 posts, not %d", topic2.PostCount))
+	expect(t, topic2.Content == "Reply 1", "the first reply should be the opening post of the new topic")
+	expect(t, topic2.LikeCount == 1, "the opening post should have kept it's like")
+	expectf(t, topicCount() == startCount+1, "the forum should have %d topics after the split, not %d", startCount+1, topicCount())
+	_, err = c.Rstore.Get(rids[1])
+	recordMustNotExist(t, err, "the reply which became the opening post shouldn't exist anymore")
+	reply, err = c.Rstore.Get(rids[2])
+	expectNilErr(t, err)
+	expect(t, reply.ParentID == tid2, "the third reply should be in the new topic")
+
+	expect(t, topic.MoveReplies([]int{rids[3]}, topic) == c.ErrSameTopic, "replies can't be moved into the topic they're in")
+	expectNilErr(t, topic.MoveReplies([]int{rids[3]}, topic2))
+	topic, topic2 = getTopic(tid), getTopic(tid2)
+	expect(t, topic.PostCount == 2, fmt.Sprintf("the old topic should have 2 posts, not %d", topic.PostCount))
+	expect(t, topic2.PostCount == 3, fmt.Sprintf("the new topic should have 3 posts, not %d", topic2.PostCount))
+	expect(t, topic2.LastReplyID == rids[3], "the moved reply should be the last one in the new topic")
+
+	expectNilErr(t, topic2.MergeInto(topic))
+	_, err = c.Topics.Get(tid2)
+	recordMustNotExist(t, err, "the merged topic shouldn't exist anymore")
+	expectf(t, topicCount() == startCount, "the forum should have %d topics after the merge, not %d", startCount, topicCount())
+	topic = getTopic(tid)
+	expect(t, topic.PostCount == 5, fmt.Sprintf("the merged topic should have 5 posts, not %d", topic.PostCount))
+	reply, err = c.Rstore.Get(rids[3])
+	expectNilErr(t, err)
+	expect(t, reply.ParentID == tid, "the replies should have come back to the first topic")
+
+	// Replies in the trash should stay where they are until they're restored
+	expectNilErr(t, reply.SoftDelete(1))
+	reply, err = c.Rstore.Get(rids[3])
+	expectNilErr(t, err)
+	expect(t, reply.Deleted, "the reply should be in the trash")
+	_, err = topic.Split([]int{rids[3]}, "Split Test", 2)
+	expect(t, err == c.ErrMoveDeletedReply, "replies in the trash shouldn't be split off")
+	tid3, err := c.Topics.Create(2, "Merge Test 2", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	topic3 := getTopic(tid3)
+	expect(t, topic.MoveReplies([]int{rids[2], rids[3]}, topic3) == c.ErrMoveDeletedReply, "replies in the trash shouldn't be moved")
+	reply, err = c.Rstore.Get(rids[2])
+	expectNilErr(t, err)
+	expect(t, reply.ParentID == tid, "none of the replies should have been moved")
+	expectf(t, topicCount() == startCount+1, "the forum should have %d topics, not %d", startCount+1, topicCount())
+
+	// Clean up
+	expectNilErr(t, topic3.Delete())
+	expectNilErr(t, topic.Delete())
+}
+
+func TestTrash(t *testing.T) {
+	getTopic := func(tid int) *c.Topic {
+		topic, err := c.Topics.Get(tid)
+		expectNilErr(t, err)
+		return topic
+	}
+	topicCount := func() int {
+		forum, err := c.Forums.Get(2)
+		expectNilErr(t, err)
+		return forum.TopicCount
+	}
+	startCount := topicCount()
+	tid, err := c.Topics.Create(2, "Trash Test", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	topic := getTopic(tid)
+	expect(t, !topic.Deleted, "a new topic shouldn't be in the trash")
+	expect(t, topicCount() == startCount+1, "the forum should have one more topic")
+
+	expectNilErr(t, topic.SoftDelete(1))
+	topic = getTopic(tid)
+	expect(t, topic.Deleted, "the topic should be in the trash")
+	expect(t, topicCount() == startCount, "topics in the trash shouldn't be counted")
+	expect(t, c.Trash.CountTopics() > 0, "the trash shouldn't be empty")
+	items, err := c.Trash.Topics(0, 100)
+	expectNilErr(t, err)
+	var found bool
+	for _, item := range items {
+		if item.ID == tid {
+			found = true
+			expect(t, item.DeletedBy == 1, "the topic should have been deleted by user #1")
+		}
+	}
+	expect(t, found, "the topic should be listed in the trash")
+	expectNilErr(t, topic.Restore())
+	topic = getTopic(tid)
+	expect(t, !topic.Deleted, "the topic should have been restored")
+	expect(t, topicCount() == startCount+1, "the restored topic should be counted again")
+
+	rid, err := c.Rstore.Create(topic, "Trash Reply", "", 1)
+	expectNilErr(t, err)
+	reply, err := c.Rstore.Get(rid)
+	expectNilErr(t, err)
+	expectNilErr(t, reply.SoftDelete(1))
+	reply, err = c.Rstore.Get(rid)
+	expectNilErr(t, err)
+	expect(t, reply.Deleted, "the reply should be in the trash")
+	topic = getTopic(tid)
+	expect(t, topic.PostCount == 1, fmt.Sprintf("replies in the trash shouldn't be counted, the topic has %d posts", topic.PostCount))
+	expect(t, c.Trash.CountRepliesIn(tid) == 1, "the topic should have one reply in the trash")
+	expectNilErr(t, reply.Restore())
+	topic = getTopic(tid)
+	expect(t, topic.PostCount == 2, fmt.Sprintf("the restored reply should be counted again, the topic has %d posts", topic.PostCount))
+	expect(t, c.Trash.CountRepliesIn(tid) == 0, "the topic shouldn't have any replies in the trash")
+
+	// Purging something in the trash shouldn't take it off the counts a second time
+	reply, err = c.Rstore.Get(rid)
+	expectNilErr(t, err)
+	expectNilErr(t, reply.SoftDelete(1))
+	expectNilErr(t, reply.Delete())
+	_, err = c.Rstore.Get(rid)
+	recordMustNotExist(t, err, "the purged reply shouldn't exist anymore")
+	topic = getTopic(tid)
+	expect(t, topic.PostCount == 1, fmt.Sprintf("the topic should have 1 post, not %d", topic.PostCount))
+
+	expectNilErr(t, topic.SoftDelete(1))
+	expectNilErr(t, topic.Delete())
+	_, err = c.Topics.Get(tid)
+	recordMustNotExist(t, err, "the purged topic shouldn't exist anymore")
+	expect(t, topicCount() == startCount, fmt.Sprintf("the forum should have %d topics, not %d", startCount, topicCount()))
+
+	count, err := c.Trash.Purge(c.Config.TrashPurgeCutoff)
+	expectNilErr(t, err)
+	expect(t, count == 0, "nothing should have been in the trash for long enough to be purged")
+}
+
 func TestMFAStore(t *testing.T) {
 	_, err := c.MFAstore.Get(-1)
 	recordMustNotExist(t, err, "mfa uid -1 should not exist")
@@ -2123,6 +2874,132 @@ func TestWidgets(t *testing.T) {
 	expectf(t, len(widgets) == 0, "RightSidebar should have 0 items, not %d", len(widgets))
 }
 
+func TestWidgetRules(t *testing.T) {
+	miscinit(t)
+	_, err := c.ParseWidgetRules(map[string]string{"VisGroups": "999"})
+	expect(t, err == c.ErrWidgetRuleGroup, "group 999 shouldn't exist")
+	_, err = c.ParseWidgetRules(map[string]string{"VisForums": "abc"})
+	expect(t, err == c.ErrWidgetRuleForum, "abc isn't a forum ID")
+	_, err = c.ParseWidgetRules(map[string]string{"VisLoggedin": "3"})
+	expect(t, err == c.ErrWidgetRuleLoggedin, "3 isn't a logged in state")
+	_, err = c.ParseWidgetRules(map[string]string{"VisRoutes": "topics"})
+	expect(t, err == c.ErrWidgetRuleRoute, "routes should have to start with a slash")
+
+	rules, err := c.ParseWidgetRules(map[string]string{"VisGroups": "3, 4", "VisForums": "2", "VisLoggedin": "1", "VisRoutes": "/topic/\r\n!/topic/secret"})
+	expectNilErr(t, err)
+	expectf(t, len(rules.Groups) == 2 && rules.Groups[0] == 3 && rules.Groups[1] == 4, "the groups should be 3 and 4, not %+v", rules.Groups)
+	expectf(t, len(rules.Routes) == 2, "there should be two routes, not %+v", rules.Routes)
+	data := make(map[string]string)
+	rules.Data(data)
+	expectf(t, data["VisGroups"] == "3,4" && data["VisForums"] == "2" && data["VisLoggedin"] == "1", "the rules didn't survive being turned back into data: %+v", data)
+
+	member := &c.User{ID: 2, Group: 3, Loggedin: true}
+	topic := c.TopicUser{ID: 1, ParentID: 2}
+	h := &c.Header{CurrentUser: member, Zone: "view_topic", ZoneID: 1, ZoneData: topic, RequestPath: "/topic/test.1"}
+	expect(t, rules.Match(h), "a member in group 3 viewing a topic in forum 2 should see the widget")
+	h.RequestPath = "/topic/secret.2"
+	expect(t, !rules.Match(h), "the widget should be hidden on /topic/secret")
+	h.RequestPath = "/topics/"
+	expect(t, !rules.Match(h), "the widget should only be on /topic/")
+	h.RequestPath = "/topic/test.1"
+	h.ZoneData = c.TopicUser{ID: 1, ParentID: 1}
+	expect(t, !rules.Match(h), "the widget shouldn't be shown in forum 1")
+	h.ZoneData = topic
+	h.CurrentUser = &c.User{ID: 3, Group: 5, Loggedin: true}
+	expect(t, !rules.Match(h), "the widget shouldn't be shown to group 5")
+	h.CurrentUser = &c.GuestUser
+	expect(t, !rules.Match(h), "the widget shouldn't be shown to guests")
+
+	guests := c.WidgetRules{Loggedin: c.WidgetLoggedinGuests}
+	expect(t, guests.Match(h), "guests should see a guest only widget")
+	h.CurrentUser = member
+	expect(t, !guests.Match(h), "members shouldn't see a guest only widget")
+	empty := c.WidgetRules{}
+	expect(t, empty.Match(h), "everyone should see a widget without any rules")
+
+	// Widgets with rules they can't meet shouldn't show up at all
+	w := &c.Widget{Position: 0, Side: "rightSidebar", Type: "forum_stats", Enabled: true, Location: "global"}
+	wid, err := (&c.WidgetEdit{w, map[string]string{"Name": "Stats", "VisLoggedin": "2"}}).Create()
+	expectNilErr(t, err)
+	defer func() {
+		w, err := c.Widgets.Get(wid)
+		expectNilErr(t, err)
+		expectNilErr(t, w.Delete())
+	}()
+	w, err = c.Widgets.Get(wid)
+	expectNilErr(t, err)
+	expect(t, w.Rules.Loggedin == c.WidgetLoggedinGuests, "the rules should have been loaded with the widget")
+	rec := httptest.NewRecorder()
+	h = c.SimpleDefaultHeader(rec)
+	h.Zone = "overview"
+	expect(t, w.Visible(h), "guests should be able to see the stats")
+	_, err = w.Build(h)
+	expectNilErr(t, err)
+	out := rec.Body.String()
+	expectf(t, strings.Contains(out, "Stats") && strings.Contains(out, "widget_forum_stats"), "the stats widget should have been rendered, not %s", out)
+	h.CurrentUser = member
+	expect(t, !w.Visible(h), "members shouldn't be able to see the stats")
+}
+
+func TestWidgetTypes(t *testing.T) {
+	miscinit(t)
+	build := func(wtype string, data map[string]string) string {
+		w := &c.Widget{Position: 0, Side: "rightSidebar", Type: wtype, Enabled: true, Location: "global"}
+		wid, err := (&c.WidgetEdit{w, data}).Create()
+		expectNilErr(t, err)
+		w, err = c.Widgets.Get(wid)
+		expectNilErr(t, err)
+		defer func() {
+			expectNilErr(t, w.Delete())
+		}()
+		rec := httptest.NewRecorder()
+		out, err := w.Build(c.SimpleDefaultHeader(rec))
+		expectNilErr(t, err)
+		return out + rec.Body.String()
+	}
+
+	out := build("top_posters", map[string]string{"Count": "3"})
+	expectf(t, strings.Contains(out, "widget_top_posters") && strings.Contains(out, "Admin"), "the admin should be one of the top posters, not %s", out)
+	out = build("newest_members", map[string]string{"Name": "New Folks"})
+	expectf(t, strings.Contains(out, "New Folks"), "the newest members widget should have it's title, not %s", out)
+	out = build("recent_topics", map[string]string{})
+	expectf(t, strings.Contains(out, "widget_recent_topics") && strings.Contains(out, phrases.GetTmplPhrase("widget.recent_topics_name")), "the recent topics widget should have the default title, not %s", out)
+	out = build("custom_html", map[string]string{"Name": "Hi", "Text": "<b>Hello</b><script>alert(1)</script>"})
+	expectf(t, strings.Contains(out, "<b>Hello</b>") && !strings.Contains(out, "alert"), "the script should have been stripped from the custom html widget, not %s", out)
+}
+
+func TestSanitiseWidgetHTML(t *testing.T) {
+	type pair struct {
+		In, Out string
+	}
+	for _, p := range []pair{
+		{"hi", "hi"},
+		{"<b>hi</b>", "<b>hi</b>"},
+		{"<B>hi</B>", "<b>hi</b>"},
+		{"<b>hi", "<b>hi</b>"},
+		{"hi</b>", "hi"},
+		{"<div><b>hi</div>", "<div><b>hi</b></div>"},
+		{"<script>alert(1)</script>hi", "hi"},
+		{"<style>body{display:none}</style>", ""},
+		{"<iframe src='//example.com'></iframe>", ""},
+		{"<marquee>hi</marquee>", "hi"},
+		{"<b onclick='alert(1)'>hi</b>", "<b>hi</b>"},
+		{"<b class='x'>hi</b>", "<b class=\"x\">hi</b>"},
+		{"<a href='https://example.com'>hi</a>", "<a href=\"https://example.com\" rel=\"nofollow noopener\">hi</a>"},
+		{"<a href='javascript:alert(1)'>hi</a>", "<a rel=\"nofollow noopener\">hi</a>"},
+		{"<a href=' JaVaScRiPt:alert(1)'>hi</a>", "<a rel=\"nofollow noopener\">hi</a>"},
+		{"<a href='java\tscript:alert(1)'>hi</a>", "<a rel=\"nofollow noopener\">hi</a>"},
+		{"<img src='/s/x.png' onerror='alert(1)'>", "<img src=\"/s/x.png\">"},
+		{"<img src='data:image/png;base64,AA'>", "<img>"},
+		{"<br/>", "<br>"},
+		{"&lt;b&gt;", "&lt;b&gt;"},
+		{"<b title='\"><script>'>hi</b>", "<b title=\"&#34;&gt;&lt;script&gt;\">hi</b>"},
+	} {
+		out := c.SanitiseWidgetHTML(p.In)
+		expectf(t, out == p.Out, "%s should be %s not %s", p.In, p.Out, out)
+	}
+}
+
 func TestUtils(t *testing.T) {
 	email := "test@example.com"
 	cemail := c.CanonEmail(email)
@@ -2226,6 +3103,13 @@ func TestAuth(t *testing.T) {
 	passwordTest(t, realPassword, hashedPassword2)
 	// TODO: Peek at the prefix to verify this is a bcrypt hash
 
+	// A hash from the phpass test suite
+	phpass := "$P$9IQRaTwmfeRo7ud9Fh4E2PdI0S3r.L0"
+	expect(t, c.HashAlgo(phpass) == "phpass", "the phpass hash should be recognised")
+	expectNilErr(t, c.CheckPassword(phpass, "test12345", ""))
+	expect(t, c.CheckPassword(phpass, "test12346", "") == c.ErrMismatchedHashAndPassword, "the wrong password shouldn't match the phpass hash")
+	expect(t, c.HashAlgo("5f4dcc3b5aa765d61d8327deb882cf99") == "", "plain md5 hashes shouldn't be recognised")
+
 	_, err, _ = c.Auth.Authenticate("None", "password")
 	errmsg := "Username None shouldn't exist"
 	if err != nil {
@@ -2475,3 +3359,869 @@ func TestWordCount(t *testing.T) {
 		}
 	}
 }
+
+func TestMigrationSteps(t *testing.T) {
+	m := &migrations.Migration{Version: 1, Name: "test", Up: []migrations.Step{
+		migrations.CreateTable{Table: "tests", Columns: []qgen.DBTableColumn{{"tid", "int", 0, false, true, ""}}, Keys: []qgen.DBTableKey{{"tid", "primary", "", false}}},
+		migrations.AddColumn{Table: "topics", Column: qgen.DBTableColumn{"testy", "int", 0, false, false, "0"}},
+		migrations.RenameColumn{Table: "topics", From: "testy", To: "testier"},
+	}}
+	sum := m.Checksum()
+	expect(t, sum == m.Checksum(), "the checksum should be stable")
+	m2 := *m
+	m2.Up = append([]migrations.Step{}, m.Up[:2]...)
+	expect(t, sum != m2.Checksum(), "changing the steps should change the checksum")
+
+	down, err := m.DownSteps()
+	expectNilErr(t, err)
+	expectIntToBeX(t, len(down), 3, "there should be a down step for every up step")
+	if rc, ok := down[0].(migrations.RenameColumn); ok {
+		expect(t, rc.From == "testier" && rc.To == "testy", "the rename should be swapped")
+	} else {
+		t.Errorf("the last up step should be undone first, not %T", down[0])
+	}
+	_, ok := down[2].(migrations.DropTable)
+	expect(t, ok, "the table should be dropped last")
+
+	m2.Up = append(m2.Up, migrations.Raw{"mysql": "SELECT 1"})
+	_, err = m2.DownSteps()
+	expect(t, err != nil, "raw steps can't be reversed without down steps")
+
+	a, err := qgen.GetAdapter("mysql")
+	expectNilErr(t, err)
+	qs, err := m.UpSQL(a)
+	expectNilErr(t, err)
+	expectIntToBeX(t, len(qs), 3, "there should be a query for every step")
+	_, err = m2.UpSQL(a)
+	expectNilErr(t, err)
+	a, err = qgen.GetAdapter("mssql")
+	expectNilErr(t, err)
+	_, err = m2.UpSQL(a)
+	expect(t, err != nil, "a raw step without a query for the adapter should fail")
+}
+
+// The fresh installations are seeded with the migrations they already have, so the checksums there have to match the migrations, otherwise they couldn't be upgraded
+func TestSeededMigrations(t *testing.T) {
+	row := regexp.MustCompile(`schema_migrations.*VALUES \((\d+),'([^']*)','([0-9a-f]+)'`)
+	for _, adapter := range []string{"mysql", "pgsql", "sqlite", "mssql"} {
+		data, err := ioutil.ReadFile("./schema/" + adapter + "/inserts.sql")
+		expectNilErr(t, err)
+		seeded := make(map[int]string)
+		for _, m := range row.FindAllStringSubmatch(string(data), -1) {
+			v, err := strconv.Atoi(m[1])
+			expectNilErr(t, err)
+			seeded[v] = m[3]
+		}
+		for _, m := range migrations.List() {
+			sum, ok := seeded[m.Version]
+			expectf(t, ok, "migration %d '%s' isn't seeded in the %s schema", m.Version, m.Name, adapter)
+			expectf(t, !ok || sum == m.Checksum(), "the %s schema has the wrong checksum for migration %d '%s', run the query generator again", adapter, m.Version, m.Name)
+		}
+		expectf(t, len(seeded) == len(migrations.List()), "the %s schema has %d migrations rather than %d", adapter, len(seeded), len(migrations.List()))
+	}
+}
+
+func TestOwnPostPermsMigration(t *testing.T) {
+	miscinit(t)
+	m := migrations.Get(8)
+	expect(t, m != nil, "the own post permissions migration should exist")
+	up, ok := m.Up[len(m.Up)-1].(migrations.Func)
+	expect(t, ok, "the permissions should be changed by a func step")
+	down, ok := m.Down[0].(migrations.Func)
+	expect(t, ok, "the permissions should be stripped by a func step")
+
+	// Everything is done in a transaction which is thrown away, so the groups are left as they were
+	a := qgen.Builder.GetAdapter()
+	tx, err := qgen.Builder.GetConn().Begin()
+	expectNilErr(t, err)
+	defer tx.Rollback()
+	set, err := a.Builder().Update().Table("users_groups").Set("permissions=?").Where("gid=?").Text()
+	expectNilErr(t, err)
+	get, err := a.SimpleSelect("", "users_groups", "permissions", "gid=?", "", "")
+	expectNilErr(t, err)
+	perms := func(gid int) (perms map[string]bool) {
+		var data string
+		expectNilErr(t, tx.QueryRow(get, gid).Scan(&data))
+		expectNilErr(t, json.Unmarshal([]byte(data), &perms))
+		expectf(t, strings.Count(data, "EditOwnReply") <= 1, "EditOwnReply shouldn't be in %s more than once", data)
+		return perms
+	}
+
+	// The admin has already decided that members can't edit their replies, so that should be left alone
+	_, err = tx.Exec(set, `{"ViewTopic":true,"CreateReply":true,"EditOwnReply":false}`, 3)
+	expectNilErr(t, err)
+	// This group already had them all before the migration, so a rollback shouldn't take them away
+	_, err = tx.Exec(set, `{"CreateReply":true,"EditOwnTopic":true,"DeleteOwnTopic":true,"EditOwnReply":true,"DeleteOwnReply":true}`, 2)
+	expectNilErr(t, err)
+	expectNilErr(t, up.Run(tx, a))
+	p := perms(3)
+	expect(t, p["CreateReply"] && p["EditOwnTopic"] && p["DeleteOwnTopic"] && p["DeleteOwnReply"], "the missing own post permissions should've been granted")
+	expect(t, !p["EditOwnReply"], "EditOwnReply should still be false")
+
+	expectNilErr(t, down.Run(tx, a))
+	p = perms(3)
+	expectIntToBeX(t, len(p), 3, "only ViewTopic, CreateReply and EditOwnReply should be left")
+	_, ok = p["EditOwnReply"]
+	expect(t, ok, "EditOwnReply wasn't added by the migration, so it shouldn't have been taken away")
+	expectIntToBeX(t, len(perms(2)), 5, "the permissions the group had before the migration should still be there")
+
+	// Everything granted has been taken back, so there's nothing left to strip
+	expectNilErr(t, down.Run(tx, a))
+	expectIntToBeX(t, len(perms(3)), 3, "a second rollback shouldn't take anything else away")
+}
+
+func TestBackup(t *testing.T) {
+	miscinit(t)
+	topicCount := c.Topics.Count()
+	userCount := c.Users.Count()
+	name, err := c.CreateBackup()
+	expectNilErr(t, err)
+	defer os.Remove("./backups/" + name)
+
+	list, err := c.ListBackups()
+	expectNilErr(t, err)
+	expect(t, len(list) > 0 && list[0].SQLURL == name, "the new backup should be at the top of the list")
+	expect(t, list[0].Restorable, "the new backup should be restorable")
+
+	// This should be gone once the backup is restored
+	tid, err := c.Topics.Create(2, "Backup Test", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	expectIntToBeX(t, c.Topics.Count(), topicCount+1, "there should be one more topic than before")
+	fid, err := c.Forums.Create("Backup Forum", "", true, "all")
+	expectNilErr(t, err)
+	expect(t, c.Forums.Exists(fid), "the new forum should exist")
+
+	m, err := c.RestoreBackup("./backups/" + name)
+	expectNilErr(t, err)
+	expect(t, m.Format == c.BackupFormat, "the backup should be in the current format")
+	expect(t, len(m.Tables) > 0, "there should be tables in the backup")
+	expectNilErr(t, c.ReloadAfterRestore())
+	expect(t, !c.Forums.Exists(fid), "the forum made after the backup shouldn't be in memory after the restore")
+	expectIntToBeX(t, c.Topics.Count(), topicCount, "the topic count should be back to what it was")
+	expectIntToBeX(t, c.Users.Count(), userCount, "the user count should be the same")
+	_, err = c.Topics.Get(tid)
+	recordMustNotExist(t, err, "topic %d should be gone after the restore", tid)
+
+	// The IDs have to keep going after the restore
+	tid, err = c.Topics.Create(2, "Backup Test", "Filler Body", 1, "")
+	expectNilErr(t, err)
+	topic, err := c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expectNilErr(t, topic.Delete())
+
+	_, err = c.RestoreBackup("./backups/not-a-backup.zip")
+	expect(t, err != nil, "restoring a backup which doesn't exist should fail")
+}
+
+func TestSelfDelete(t *testing.T) {
+	miscinit(t)
+	getUser := func(uid int) *c.User {
+		if uc := c.Users.GetCache(); uc != nil {
+			uc.Remove(uid)
+		}
+		u, err := c.Users.Get(uid)
+		expectNilErr(t, err)
+		return u
+	}
+	uid, err := c.Users.Create("Leaving Sam", "ReallyBadPassword", "leaving@localhost.loc", c.Config.DefaultGroup, true)
+	expectNilErr(t, err)
+	u := getUser(uid)
+	tid, err := c.Topics.Create(2, "Self Delete Test", "Filler Body", uid, "127.0.0.1")
+	expectNilErr(t, err)
+	topic, err := c.Topics.Get(tid)
+	expectNilErr(t, err)
+	rid, err := c.Rstore.Create(topic, "Self Delete Reply", "127.0.0.1", uid)
+	expectNilErr(t, err)
+	expectNilErr(t, c.Emails.Add(uid, "leaving2@localhost.loc", ""))
+
+	var buf bytes.Buffer
+	expectNilErr(t, c.ExportUserData(u, &buf))
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	expectNilErr(t, err)
+	readJSON := func(name string, v interface{}) {
+		for _, f := range zr.File {
+			if f.Name != name {
+				continue
+			}
+			rc, err := f.Open()
+			expectNilErr(t, err)
+			defer rc.Close()
+			expectNilErr(t, json.NewDecoder(rc).Decode(v))
+			return
+		}
+		t.Fatalf("%s should be in the export", name)
+	}
+	var profile c.ExportProfile
+	readJSON("profile.json", &profile)
+	expect(t, profile.ID == uid && profile.Name == "Leaving Sam", "the profile in the export should belong to the user")
+	var topics, replies []c.ExportPost
+	readJSON("topics.json", &topics)
+	expect(t, len(topics) == 1 && topics[0].ID == tid && topics[0].Title == "Self Delete Test", "the user's topic should be in the export")
+	readJSON("replies.json", &replies)
+	expect(t, len(replies) == 1 && replies[0].ID == rid, "the user's reply should be in the export")
+	var emails []c.ExportEmail
+	readJSON("emails.json", &emails)
+	expect(t, len(emails) == 1 && emails[0].Email == "leaving2@localhost.loc", "the user's emails should be in the export")
+
+	_, err = c.SelfDeletes.Get(uid)
+	recordMustNotExist(t, err, "user %d shouldn't have asked to be deleted yet", uid)
+	expect(t, c.SelfDeletes.Schedule(uid, "shred") == c.ErrSelfDeleteMode, "shred shouldn't be a way of deleting an account")
+	expectNilErr(t, c.SelfDeletes.Schedule(uid, c.SelfDeleteAnonymise))
+	sd, err := c.SelfDeletes.Get(uid)
+	recordMustExist(t, err, "user %d should have asked to be deleted", uid)
+	expect(t, sd.Mode == c.SelfDeleteAnonymise, "the account should be set to be anonymised")
+	expect(t, sd.DueAt().After(time.Now()), "the account shouldn't be due to be deleted yet")
+	count, err := c.SelfDeletes.Process(c.SelfDeleteGrace())
+	expectNilErr(t, err)
+	expectIntToBeX(t, count, 0, "nothing should be deleted during the grace period, %d were")
+	expectNilErr(t, c.SelfDeletes.Cancel(uid))
+	_, err = c.SelfDeletes.Get(uid)
+	recordMustNotExist(t, err, "user %d's request should have been cancelled", uid)
+
+	// Wind the clock back on the requests, so they're past the grace period
+	backdate := func(uid int) {
+		_, err := qgen.NewAcc().Update("users_self_deletes").Set("requestedAt=?").Where("uid=?").Exec(time.Now().UTC().AddDate(0, 0, -c.SelfDeleteGrace()-2).Format("2006-01-02 15:04:05"), uid)
+		expectNilErr(t, err)
+	}
+	expectNilErr(t, c.SelfDeletes.Schedule(uid, c.SelfDeleteAnonymise))
+	backdate(uid)
+	count, err = c.SelfDeletes.Process(c.SelfDeleteGrace())
+	expectNilErr(t, err)
+	expectIntToBeX(t, count, 1, "one account should have been deleted, not %d")
+	u = getUser(uid)
+	expect(t, u.Name != "Leaving Sam" && u.Email == "" && !u.Active, "the account should have been anonymised")
+	emailList, err := c.Emails.GetEmailsByUser(u)
+	expectNilErr(t, err)
+	expectIntToBeX(t, len(emailList), 0, "the anonymised user shouldn't have any emails, they have %d")
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.CreatedBy == uid, "the topic should still be there")
+	var ip string
+	expectNilErr(t, qgen.NewAcc().Select("topics").Columns("ip").Where("tid=?").QueryRow(tid).Scan(&ip))
+	expect(t, ip == "", "the IP should have been stripped from the topic")
+	_, err = c.SelfDeletes.Get(uid)
+	recordMustNotExist(t, err, "user %d's request should be gone once it's been done", uid)
+	expectNilErr(t, topic.Delete())
+
+	uid, err = c.Users.Create("Purged Sam", "ReallyBadPassword", "purged@localhost.loc", c.Config.DefaultGroup, true)
+	expectNilErr(t, err)
+	tid, err = c.Topics.Create(2, "Self Purge Test", "Filler Body", uid, "")
+	expectNilErr(t, err)
+	expectNilErr(t, c.SelfDeletes.Schedule(uid, c.SelfDeletePurge))
+	backdate(uid)
+	count, err = c.SelfDeletes.Process(c.SelfDeleteGrace())
+	expectNilErr(t, err)
+	expectIntToBeX(t, count, 1, "one account should have been purged, not %d")
+	if uc := c.Users.GetCache(); uc != nil {
+		uc.Remove(uid)
+	}
+	_, err = c.Users.Get(uid)
+	recordMustNotExist(t, err, "user %d should have been purged", uid)
+	_, err = c.Topics.Get(tid)
+	recordMustNotExist(t, err, "topic %d should have been purged along with it's creator", tid)
+
+	// The administrators have to be demoted before they can go
+	expect(t, getUser(1).Anonymise() == c.ErrSelfDeleteAdmin, "an administrator shouldn't be able to delete their own account")
+}
+
+func TestThemeOverrides(t *testing.T) {
+	miscinit(t)
+	dir := "./themes/override_test/overrides/"
+	expectNilErr(t, os.MkdirAll(dir, 0755))
+	defer os.RemoveAll("./themes/override_test")
+	write := func(src string) {
+		expectNilErr(t, ioutil.WriteFile(dir+"login.html", []byte(src), 0644))
+	}
+	run := func(th *c.Theme, pi interface{}) (string, error) {
+		var b bytes.Buffer
+		err := th.RunTmpl("login", pi, &b)
+		return b.String(), err
+	}
+
+	write("<b>{{.Title}} override</b>")
+	th := &c.Theme{Name: "override_test"}
+	expectNilErr(t, th.LoadOverrides())
+	expect(t, th.OverridenMap["login"], "login should be overriden")
+	expect(t, th.RuntimeOverrides, "the overrides haven't been compiled in, so they should be interpreted")
+	out, err := run(th, struct{ Title string }{"Hello"})
+	expectNilErr(t, err)
+	expect(t, out == "<b>Hello override</b>", fmt.Sprintf("out should be '<b>Hello override</b>' not '%s'", out))
+
+	// Changes should be picked up without a rebuild
+	write("<i>{{.Title}} changed</i>")
+	expectNilErr(t, th.ReloadOverrides())
+	out, err = run(th, struct{ Title string }{"Hello"})
+	expectNilErr(t, err)
+	expect(t, out == "<i>Hello changed</i>", fmt.Sprintf("out should be '<i>Hello changed</i>' not '%s'", out))
+
+	// The methods which could change something are off limits, and a bad override shouldn't replace the last good one
+	write("{{.CurrentUser.Delete}}")
+	expect(t, th.ReloadOverrides() != nil, "overrides shouldn't be able to call User.Delete")
+	write("{{.Header.Theme.RunTmpl}}")
+	expect(t, th.ReloadOverrides() != nil, "overrides shouldn't be able to call Theme.RunTmpl")
+	out, err = run(th, struct{ Title string }{"Hello"})
+	expectNilErr(t, err)
+	expect(t, out == "<i>Hello changed</i>", fmt.Sprintf("the last good override should have been kept, not '%s'", out))
+
+	// call would let them get at any function they can reach in the page data
+	called := false
+	write("{{call .F}}")
+	expectNilErr(t, th.ReloadOverrides())
+	_, err = run(th, struct{ F func() string }{func() string {
+		called = true
+		return ""
+	}})
+	expect(t, err != nil, "call shouldn't be available to the overrides")
+	expect(t, !called, "the function shouldn't have been called")
+
+	// The overrides which ship with Gosora have to get through the vetting
+	for name := range c.Themes {
+		expectNilErr(t, (&c.Theme{Name: name}).LoadOverrides())
+	}
+}
+
+func TestThemeEditor(t *testing.T) {
+	miscinit(t)
+	const name = "editor_test"
+	cleanup := func() {
+		os.RemoveAll("./themes/" + name)
+		delete(c.Themes, name)
+	}
+	cleanup()
+	defer cleanup()
+
+	parent := c.Themes["cosora"]
+	_, err := c.CreateChildTheme(parent, "Bad Name", "")
+	expect(t, err == c.ErrThemeName, "Bad Name shouldn't be a valid theme name")
+	_, err = c.CreateChildTheme(parent, "cosora", "")
+	expect(t, err == c.ErrThemeExists, "a child theme shouldn't be able to take the name of an existing theme")
+
+	th, err := c.CreateChildTheme(parent, name, "Editor Test")
+	expectNilErr(t, err)
+	expect(t, c.Themes[name] == th, "the child theme should have been added to the theme list")
+	expect(t, th.ForkOf == "cosora", fmt.Sprintf("ForkOf should be cosora not %s", th.ForkOf))
+	expect(t, th.FriendlyName == "Editor Test", fmt.Sprintf("FriendlyName should be Editor Test not %s", th.FriendlyName))
+	var found bool
+	for _, res := range th.Resources {
+		expect(t, !strings.HasPrefix(res.Name, "cosora/"), fmt.Sprintf("resource %s should point at the child theme", res.Name))
+		found = found || res.Name == name+"/misc.js"
+	}
+	expect(t, found, "the child theme should have it's own copy of misc.js")
+
+	// Only the files in the theme can be touched, and only in the child themes
+	err = parent.WriteFile("public/main.css", []byte("a{}"))
+	expect(t, err == c.ErrThemeNotChild, "the parent theme shouldn't be editable")
+	err = th.WriteFile("../cosora/public/main.css", []byte("a{}"))
+	expect(t, err == c.ErrThemeFile, "files outside of the theme shouldn't be editable")
+	err = th.WriteFile("theme.json", []byte("{}"))
+	expect(t, err == c.ErrThemeFile, "theme.json shouldn't be editable")
+
+	expectNilErr(t, th.WriteFile("public/main.css", []byte(".editor_test { color: blue; }")))
+	sfile, ok := c.StaticFiles.Get("/s/" + name + "/main.css")
+	expect(t, ok, "main.css should be in the static files")
+	expect(t, strings.Contains(string(sfile.Data), "blue"), "main.css should have the new content")
+
+	// A broken file shouldn't replace the working one
+	err = th.WriteFile("public/main.css", []byte(".editor_test { color: {{.Nope"))
+	expect(t, err != nil, "a broken stylesheet shouldn't be saved")
+	data, err := th.ReadFile("public/main.css")
+	expectNilErr(t, err)
+	expect(t, string(data) == ".editor_test { color: blue; }", fmt.Sprintf("main.css should have been restored, not %s", string(data)))
+
+	// Settings
+	th.Settings = map[string]c.ThemeSetting{"accent": {FriendlyName: "Accent", Options: []string{"blue", "red"}}}
+	err = th.SetSettings(map[string]string{"accent": "green"})
+	expect(t, err == c.ErrThemeSetting, "green isn't one of the options")
+	expectNilErr(t, th.SetSettings(map[string]string{"accent": "red"}))
+	expect(t, th.Setting("accent") == "red", fmt.Sprintf("accent should be red not %s", th.Setting("accent")))
+	out, err := th.PreviewCSS("public/main.css", []byte(".editor_test { color: {{.Settings.accent}}; }"))
+	expectNilErr(t, err)
+	expect(t, strings.Contains(string(out), "red"), fmt.Sprintf("the preview should have the setting in it, not %s", string(out)))
+
+	// Export it, take it away and bring it back
+	var b bytes.Buffer
+	expectNilErr(t, th.Export(&b))
+	cleanup()
+	zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	expectNilErr(t, err)
+	th, err = c.ImportTheme(zr)
+	expectNilErr(t, err)
+	expect(t, c.Themes[name] == th, "the imported theme should have been added to the theme list")
+	data, err = th.ReadFile("public/main.css")
+	expectNilErr(t, err)
+	expect(t, string(data) == ".editor_test { color: blue; }", fmt.Sprintf("main.css should have come along, not %s", string(data)))
+	zr, err = zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	expectNilErr(t, err)
+	_, err = c.ImportTheme(zr)
+	expect(t, err == c.ErrThemeExists, "the theme shouldn't be imported twice")
+
+	badZip := func(files map[string]string) *zip.Reader {
+		var b bytes.Buffer
+		zw := zip.NewWriter(&b)
+		for fname, content := range files {
+			f, err := zw.Create(fname)
+			expectNilErr(t, err)
+			_, err = f.Write([]byte(content))
+			expectNilErr(t, err)
+		}
+		expectNilErr(t, zw.Close())
+		zr, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+		expectNilErr(t, err)
+		return zr
+	}
+	_, err = c.ImportTheme(badZip(map[string]string{"evil/theme.json": `{"Name":"evil"}`, "evil/../../evil.txt": "boo"}))
+	expect(t, err == c.ErrBadThemeZip, "files outside of the theme's folder shouldn't be unpacked")
+	_, err = os.Stat("./themes/evil")
+	expect(t, os.IsNotExist(err), "the bad theme should have been cleaned up")
+	_, err = c.ImportTheme(badZip(map[string]string{"theme.json": `{"Name":"evil","Path":"/etc"}`}))
+	expect(t, err == c.ErrBadThemeZip, "themes shouldn't be able to redirect to other folders")
+
+	// Only the preview frame should be allowed to put our pages in a frame
+	frameOpt := func(path string, u *c.User) string {
+		w := httptest.NewRecorder()
+		w.Header().Set("X-Frame-Options", "deny")
+		_, err := c.UserCheck(w, httptest.NewRequest("", path, nil), u)
+		expect(t, err == nil, "UserCheck shouldn't fail")
+		return w.Header().Get("X-Frame-Options")
+	}
+	admin, err := c.Users.Get(1)
+	expectNilErr(t, err)
+	opt := frameOpt("/?preview_theme=cosora", admin)
+	expectf(t, opt == "sameorigin", "the preview frame should be allowed to frame the page, not %s", opt)
+	opt = frameOpt("/", admin)
+	expectf(t, opt == "deny", "other pages shouldn't be allowed in frames, not %s", opt)
+	opt = frameOpt("/?preview_theme=cosora", &c.GuestUser)
+	expectf(t, opt == "deny", "guests shouldn't be able to get pages into frames, not %s", opt)
+}
+
+func TestUserThemeSettings(t *testing.T) {
+	miscinit(t)
+	th := c.Themes["cosora"]
+	expect(t, len(th.UserSettings) > 0, "cosora should have user settings")
+	expect(t, th.ValidUserSetting("scheme", "dark"), "dark should be a valid scheme")
+	expect(t, !th.ValidUserSetting("scheme", "purple"), "purple shouldn't be a valid scheme")
+	expect(t, !th.ValidUserSetting("nope", "dark"), "nope shouldn't be a valid setting")
+
+	// Guests get the defaults, the dark scheme only applies when their browser asks for it
+	style := string(th.DefaultUserStyle)
+	expect(t, strings.Contains(style, "@media (prefers-color-scheme: dark){:root{"), fmt.Sprintf("the default style should have a dark mode media query, not %s", style))
+	expect(t, !strings.HasPrefix(style, ":root{"), fmt.Sprintf("the default style shouldn't override anything outside of the media query, not %s", style))
+	expect(t, th.UserStyleFor(&c.GuestUser) == th.DefaultUserStyle, "guests should get the default style")
+
+	style = string(th.UserStyle(map[string]string{"scheme": "light", "font_size": "large"}))
+	expect(t, !strings.Contains(style, "@media"), fmt.Sprintf("picking light should turn off the dark mode media query, not %s", style))
+	expect(t, strings.Contains(style, "--body-font-size:18px;"), fmt.Sprintf("the large font size should be in the style, not %s", style))
+	style = string(th.UserStyle(map[string]string{"scheme": "dark"}))
+	expect(t, strings.HasPrefix(style, ":root{") && strings.Contains(style, "--element-background-color:hsl(0,0%,15%);"), fmt.Sprintf("picking dark should apply it regardless of the browser, not %s", style))
+	expect(t, !strings.Contains(style, "@media"), fmt.Sprintf("picking dark shouldn't need the media query, not %s", style))
+
+	uid, err := c.Users.Create("Theme Prefs", "ReallyBadPassword", "", 2, true)
+	expectNilErr(t, err)
+	u, err := c.Users.Get(uid)
+	expectNilErr(t, err)
+	u.Loggedin = true
+	vals, err := c.UserThemeSettings.Get(uid, "cosora")
+	expectNilErr(t, err)
+	expect(t, len(vals) == 0, "the user shouldn't have any theme settings yet")
+	expect(t, th.UserStyleFor(u) == th.DefaultUserStyle, "the user should get the default style until they pick something")
+
+	expectNilErr(t, c.UserThemeSettings.Set(uid, "cosora", map[string]string{"scheme": "dark", "density": "compact"}))
+	vals, err = c.UserThemeSettings.Get(uid, "cosora")
+	expectNilErr(t, err)
+	expect(t, len(vals) == 2 && vals["scheme"] == "dark" && vals["density"] == "compact", fmt.Sprintf("the user should have two theme settings, not %+v", vals))
+	style = string(th.UserStyleFor(u))
+	expect(t, strings.Contains(style, "--row-padding:6px;") && !strings.Contains(style, "@media"), fmt.Sprintf("the user should get the style for the options they picked, not %s", style))
+	all, err := c.UserThemeSettings.GetAll(uid)
+	expectNilErr(t, err)
+	expect(t, len(all) == 1 && len(all["cosora"]) == 2, fmt.Sprintf("the user should only have settings for cosora, not %+v", all))
+
+	// The settings for each theme are replaced as a whole
+	expectNilErr(t, c.UserThemeSettings.Set(uid, "cosora", map[string]string{"font_size": "small"}))
+	vals, err = c.UserThemeSettings.Get(uid, "cosora")
+	expectNilErr(t, err)
+	expect(t, len(vals) == 1 && vals["font_size"] == "small", fmt.Sprintf("the user should only have the font size setting, not %+v", vals))
+
+	expectNilErr(t, c.UserThemeSettings.DeleteAll(uid))
+	vals, err = c.UserThemeSettings.Get(uid, "cosora")
+	expectNilErr(t, err)
+	expect(t, len(vals) == 0, fmt.Sprintf("the user shouldn't have any theme settings left, not %+v", vals))
+	expectNilErr(t, u.Delete())
+}
+
+func TestClusterBus(t *testing.T) {
+	miscinit(t)
+	var got []c.ClusterEvent
+	c.AddClusterHandler("test", func(ev *c.ClusterEvent) error {
+		got = append(got, *ev)
+		return nil
+	})
+	defer delete(c.ClusterHandlers, "test")
+
+	a, err := c.NewDBClusterBus(qgen.NewAcc(), "server-a")
+	expectNilErr(t, err)
+	b, err := c.NewDBClusterBus(qgen.NewAcc(), "server-b")
+	expectNilErr(t, err)
+	expectNilErr(t, a.Publish(c.ClusterEvent{Type: "test", ID: 1}))
+	expectNilErr(t, a.Publish(c.ClusterEvent{Type: "test", ID: 1}))
+	expectNilErr(t, a.Publish(c.ClusterEvent{Type: "test", ID: 2, Data: "hi"}))
+	expectNilErr(t, a.Tick())
+	expect(t, len(got) == 0, fmt.Sprintf("a server shouldn't get it's own events, not %+v", got))
+	expectNilErr(t, b.Tick())
+	expect(t, len(got) == 2, fmt.Sprintf("there should be two events as the duplicate is dropped, not %+v", got))
+	if len(got) == 2 {
+		expect(t, got[0] == c.ClusterEvent{Origin: "server-a", Type: "test", ID: 1}, fmt.Sprintf("the first event should be test #1 from server-a, not %+v", got[0]))
+		expect(t, got[1] == c.ClusterEvent{Origin: "server-a", Type: "test", ID: 2, Data: "hi"}, fmt.Sprintf("the second event should be test #2 from server-a, not %+v", got[1]))
+	}
+	expectNilErr(t, b.Tick())
+	expect(t, len(got) == 2, fmt.Sprintf("the events should only be applied once, not %+v", got))
+
+	// The events from the other servers are applied to the cache underneath, rather than being sent back out
+	prevBus := c.Cluster
+	c.Cluster = a
+	defer func() {
+		c.Cluster = prevBus
+	}()
+	prevCache := c.Users.GetCache()
+	ucache := c.NewMemoryUserCache(10)
+	ustore := c.Users.(*c.DefaultUserStore)
+	ustore.SetCache(c.NewClusterUserCache(ucache))
+	defer func() {
+		if prevCache == nil {
+			prevCache = c.NewNullUserCache()
+		}
+		ustore.SetCache(prevCache)
+	}()
+
+	_, err = c.Users.Get(1)
+	expectNilErr(t, err)
+	_, err = ucache.Get(1)
+	expectNilErr(t, err)
+	expectNilErr(t, b.Publish(c.ClusterEvent{Type: c.ClusterUser, ID: 1}))
+	expectNilErr(t, b.Tick())
+	count, err := qgen.NewAcc().Count("cluster_events").Total()
+	expectNilErr(t, err)
+	expectNilErr(t, a.Tick())
+	_, err = ucache.Get(1)
+	expect(t, err == sql.ErrNoRows, "user #1 should have been dropped from the cache")
+	expectNilErr(t, a.Tick())
+	count2, err := qgen.NewAcc().Count("cluster_events").Total()
+	expectNilErr(t, err)
+	expect(t, count == count2, fmt.Sprintf("the event shouldn't have been sent back out, there were %d events and now there are %d", count, count2))
+
+	// Removing an item locally lets the others know
+	_, err = c.Users.Get(1)
+	expectNilErr(t, err)
+	expectNilErr(t, c.Users.GetCache().Remove(1))
+	expectNilErr(t, a.Tick())
+	count2, err = qgen.NewAcc().Count("cluster_events").Total()
+	expectNilErr(t, err)
+	expect(t, count2 == count+1, fmt.Sprintf("removing user #1 should have published an event, there were %d events and now there are %d", count, count2))
+	expectNilErr(t, b.Tick())
+	expectNilErr(t, a.Prune())
+}
+
+func TestClusterTCPBus(t *testing.T) {
+	miscinit(t)
+	_, err := c.NewTCPClusterBus("127.0.0.1:0", nil, "", "tcp-nope")
+	expect(t, err == c.ErrClusterSecret, "the tcp driver shouldn't start without a secret")
+
+	evs := make(chan c.ClusterEvent, 10)
+	c.AddClusterHandler("test", func(ev *c.ClusterEvent) error {
+		evs <- *ev
+		return nil
+	})
+	defer delete(c.ClusterHandlers, "test")
+
+	a, err := c.NewTCPClusterBus("127.0.0.1:0", nil, "sekrit", "tcp-a")
+	expectNilErr(t, err)
+	defer a.Close()
+	b, err := c.NewTCPClusterBus("", []string{a.Addr()}, "sekrit", "tcp-b")
+	expectNilErr(t, err)
+	defer b.Close()
+	evil, err := c.NewTCPClusterBus("", []string{a.Addr()}, "not-sekrit", "tcp-evil")
+	expectNilErr(t, err)
+	defer evil.Close()
+
+	expectNilErr(t, b.Publish(c.ClusterEvent{Type: "test", ID: 5, Data: "hello"}))
+	select {
+	case ev := <-evs:
+		expect(t, ev == c.ClusterEvent{Origin: "tcp-b", Type: "test", ID: 5, Data: "hello"}, fmt.Sprintf("the event should be test #5 from tcp-b, not %+v", ev))
+	case <-time.After(5 * time.Second):
+		t.Error("the event from tcp-b never arrived")
+	}
+
+	expectNilErr(t, evil.Publish(c.ClusterEvent{Type: "test", ID: 6}))
+	select {
+	case ev := <-evs:
+		t.Errorf("the event from tcp-evil shouldn't have been accepted as it's signed with the wrong secret: %+v", ev)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+	ip := "::1"
+	joinDiff := func(chunks []c.DiffChunk, ins, del bool) (out string) {
+		for _, ch := range chunks {
+			if (ch.Insert && !ins) || (ch.Delete && !del) {
+				continue
+			}
+			out += ch.Text
+		}
+		return out
+	}
+	diff := c.Diff("a b c", "a x c")
+	expectf(t, len(diff) == 4, "there should be 4 chunks, not %d", len(diff))
+	expect(t, diff[0].Text == "a " && !diff[0].Insert && !diff[0].Delete, "the first chunk should be the unchanged 'a '")
+	expect(t, diff[1].Text == "b" && diff[1].Delete, "the second chunk should be the deleted 'b'")
+	expect(t, diff[2].Text == "x" && diff[2].Insert, "the third chunk should be the inserted 'x'")
+	expect(t, joinDiff(diff, false, true) == "a b c" && joinDiff(diff, true, false) == "a x c", "the chunks should join back into either version")
+	expect(t, len(c.Diff("same", "same")) == 1, "there shouldn't be any changes between two identical posts")
+
+	tid, err := c.Topics.Create(2, "Revision Topic", "First version", 1, ip)
+	expectNilErr(t, err)
+	topic, err := c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.LastEdit == 0, "a new topic shouldn't be marked as edited")
+	expect(t, c.Revisions.Count(tid, "topics") == 0, "a new topic shouldn't have any revisions")
+
+	// The first edit records the original too
+	expectNilErr(t, topic.Update("Revision Topic", "Second version", ip, 2))
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.LastEdit != 0 && topic.LastEditBy == 2, "the topic should be marked as edited by user #2")
+	revs, err := c.Revisions.GetList(tid, "topics")
+	expectNilErr(t, err)
+	expectf(t, len(revs) == 2, "there should be 2 revisions, not %d", len(revs))
+	expect(t, revs[0].Content == "First version" && revs[0].CreatedBy == 1, "the first revision should be the original")
+	expect(t, revs[1].Content == "Second version" && revs[1].CreatedBy == 2, "the second revision should be the edit")
+	expect(t, revs[1].Link() == "/topic/revisions/"+strconv.Itoa(tid), "the revision should link to the topic's revisions")
+
+	// Saving it without any changes isn't an edit
+	expectNilErr(t, topic.Update("Revision Topic", "Second version", ip, 2))
+	expect(t, c.Revisions.Count(tid, "topics") == 2, "an unchanged topic shouldn't add a revision")
+
+	rev, err := c.Revisions.Get(revs[0].ID)
+	expectNilErr(t, err)
+	expect(t, rev.ContentID == tid && rev.ContentType == "topics" && rev.Title == "Revision Topic", "the revision should belong to the topic")
+	expectNilErr(t, topic.Revert(rev, ip, 1))
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.Content == "First version", "the topic should've been reverted to the original")
+	expect(t, c.Revisions.Count(tid, "topics") == 3, "reverting should add a revision")
+
+	rid, err := c.Rstore.Create(topic, "Reply version", ip, 1)
+	expectNilErr(t, err)
+	reply, err := c.Rstore.Get(rid)
+	expectNilErr(t, err)
+	expectNilErr(t, reply.SetPost("Reply edited", ip, 1))
+	revs, err = c.Revisions.GetList(rid, "replies")
+	expectNilErr(t, err)
+	expectf(t, len(revs) == 2, "there should be 2 reply revisions, not %d", len(revs))
+	expect(t, revs[0].Content == "Reply version" && revs[1].Content == "Reply edited", "the reply revisions should be the original and the edit")
+	reply, err = c.Rstore.Get(rid)
+	expectNilErr(t, err)
+	expect(t, reply.LastEdit != 0 && reply.LastEditBy == 1, "the reply should be marked as edited by user #1")
+	diff = c.Diff(revs[0].Content, revs[1].Content)
+	expect(t, joinDiff(diff, false, true) == "Reply version" && joinDiff(diff, true, false) == "Reply edited", "the reply diff should join back into either version")
+
+	// Deleting the topic takes the revisions of it and it's replies with it
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expectNilErr(t, topic.Delete())
+	expect(t, c.Revisions.Count(tid, "topics") == 0, "the topic's revisions should've been deleted")
+	expect(t, c.Revisions.Count(rid, "replies") == 0, "the reply's revisions should've been deleted")
+}
+
+func TestDrafts(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+	uid := 1
+	_, err := c.Drafts.Get(uid, "topic", 1)
+	expect(t, err == sql.ErrNoRows, "there shouldn't be a draft yet")
+	expect(t, c.Drafts.Save(uid, "profile", 1, "", "hi") == c.ErrBadDraftType, "profile isn't a place drafts can be saved for")
+
+	expectNilErr(t, c.Drafts.Save(uid, "forum", 2, "Draft Title", "Draft Content"))
+	d, err := c.Drafts.Get(uid, "forum", 2)
+	expectNilErr(t, err)
+	expect(t, d.Title == "Draft Title" && d.Content == "Draft Content", "the draft should have the title and content which were saved")
+	expect(t, d.Link() == "/topics/create/2", "the draft should link to the create topic page")
+
+	// Saving it again should update the one which is there, rather than adding another
+	expectNilErr(t, c.Drafts.Save(uid, "forum", 2, "Draft Title", "More Content"))
+	expectNilErr(t, c.Drafts.Save(uid, "forum", 2, "Draft Title", "More Content"))
+	expectNilErr(t, c.Drafts.Save(uid, "topic", 1, "", "Reply Content"))
+	expectNilErr(t, c.Drafts.Save(2, "topic", 1, "", "Someone else's reply"))
+	count := c.Drafts.CountUser(uid)
+	expectf(t, count == 2, "user #1 should have 2 drafts, not %d", count)
+	d, err = c.Drafts.Get(uid, "forum", 2)
+	expectNilErr(t, err)
+	expect(t, d.Content == "More Content", "the draft should've been updated")
+
+	drafts, err := c.Drafts.GetByUser(uid, 0, 10)
+	expectNilErr(t, err)
+	expectf(t, len(drafts) == 2, "there should be 2 drafts, not %d", len(drafts))
+	for _, d := range drafts {
+		expect(t, d.UID == uid && c.ValidDraftType(d.ContextType), "the drafts should belong to user #1")
+	}
+
+	// Emptying a draft deletes it
+	expectNilErr(t, c.Drafts.Save(uid, "topic", 1, "", ""))
+	_, err = c.Drafts.Get(uid, "topic", 1)
+	expect(t, err == sql.ErrNoRows, "the empty draft should've been deleted")
+	_, err = c.Drafts.Get(2, "topic", 1)
+	expectNilErr(t, err)
+
+	expectNilErr(t, c.Drafts.Prune(1))
+	expect(t, c.Drafts.CountUser(uid) == 1, "a draft saved just now shouldn't have expired")
+
+	// People can only delete their own drafts
+	expectNilErr(t, c.Drafts.DeleteByID(2, d.ID))
+	expect(t, c.Drafts.CountUser(uid) == 1, "user #2 shouldn't be able to delete user #1's draft")
+	expectNilErr(t, c.Drafts.DeleteByID(uid, d.ID))
+	expect(t, c.Drafts.CountUser(uid) == 0, "user #1 should be able to delete their draft")
+
+	expectNilErr(t, c.Drafts.Delete(2, "topic", 1))
+	expect(t, c.Drafts.CountUser(2) == 0, "user #2's draft should've been deleted")
+	expectNilErr(t, c.Drafts.DeleteAll(uid))
+}
+
+func TestScheduledTopics(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+	_, err := c.ScheduledTopics.Create(&c.ScheduledTopic{ParentID: 2, Title: "", Content: "Body", CreatedBy: 1, PublishAt: time.Now()})
+	expect(t, err == c.ErrNoTitle, "a scheduled topic without a title should be rejected")
+
+	later := &c.ScheduledTopic{ParentID: 2, Title: "Later Topic", Content: "Later Body", CreatedBy: 1, IP: "127.0.0.1", Tags: "news,later", PublishAt: time.Now().Add(24 * time.Hour)}
+	later.ID, err = c.ScheduledTopics.Create(later)
+	expectNilErr(t, err)
+	due := &c.ScheduledTopic{ParentID: 2, Title: "Due Topic", Content: "Due Body", CreatedBy: 1, PublishAt: time.Now().Add(-time.Minute)}
+	due.ID, err = c.ScheduledTopics.Create(due)
+	expectNilErr(t, err)
+
+	list, err := c.ScheduledTopics.GetList(1)
+	expectNilErr(t, err)
+	expectf(t, len(list) == 2, "user #1 should have 2 scheduled topics, not %d", len(list))
+	expect(t, list[0].ID == due.ID, "the soonest topic should come first")
+	list, err = c.ScheduledTopics.GetList(2)
+	expectNilErr(t, err)
+	expectf(t, len(list) == 0, "user #2 shouldn't have any scheduled topics, not %d", len(list))
+
+	// Only the topic whose time has come should be published
+	expectNilErr(t, c.ScheduledTopics.PublishDue())
+	list, err = c.ScheduledTopics.GetList(0)
+	expectNilErr(t, err)
+	expectf(t, len(list) == 1 && list[0].ID == later.ID, "only the later topic should still be waiting, not %d topics", len(list))
+	_, err = c.ScheduledTopics.Get(due.ID)
+	expect(t, err == sql.ErrNoRows, "the due topic shouldn't be waiting anymore")
+
+	// The topic should go through the same steps as one which was created by hand
+	var hookTid int
+	hooks := c.GetHookTable()
+	hooks.VhookSkippable_["action_end_create_topic"] = func(args ...interface{}) (bool, c.RouteError) {
+		hookTid = args[0].(int)
+		return false, nil
+	}
+	defer delete(hooks.VhookSkippable_, "action_end_create_topic")
+
+	later, err = c.ScheduledTopics.Get(later.ID)
+	expectNilErr(t, err)
+	tid, err := c.ScheduledTopics.Publish(later)
+	expectNilErr(t, err)
+	expect(t, tid != 0, "publishing the later topic should've created a topic")
+	expectf(t, hookTid == tid, "the create topic hook should've been run for topic %d, not %d", tid, hookTid)
+	topic, err := c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, topic.Title == "Later Topic" && topic.Content == "Later Body" && topic.CreatedBy == 1, "the topic should have the scheduled title, body and author")
+	tags, err := c.TopicTags.Get(tid)
+	expectNilErr(t, err)
+	expectf(t, len(tags) == 2, "the topic should have the 2 scheduled tags, not %d", len(tags))
+
+	// Something else has already published it, so it shouldn't be published twice
+	tid2, err := c.ScheduledTopics.Publish(later)
+	expectNilErr(t, err)
+	expect(t, tid2 == 0, "a topic shouldn't be published twice")
+	expectNilErr(t, topic.Delete())
+
+	// A regular member isn't allowed to schedule topics, so theirs should be handed back to them as a draft
+	uid, err := c.Users.Create("Scheduling Sam", "ReallyBadPassword", "", c.Config.DefaultGroup, true)
+	expectNilErr(t, err)
+	member := &c.ScheduledTopic{ParentID: 2, Title: "Member Topic", Content: "Member Body", CreatedBy: uid, PublishAt: time.Now().Add(-time.Minute)}
+	member.ID, err = c.ScheduledTopics.Create(member)
+	expectNilErr(t, err)
+	tid, err = c.ScheduledTopics.Publish(member)
+	expect(t, err == c.ErrCantPublish, "a member shouldn't be able to publish a scheduled topic")
+	expect(t, tid == 0, "the member's topic shouldn't have been published")
+	_, err = c.ScheduledTopics.Get(member.ID)
+	expect(t, err == sql.ErrNoRows, "the member's topic shouldn't be waiting anymore")
+	draft, err := c.Drafts.Get(uid, "forum", 2)
+	expectNilErr(t, err)
+	expect(t, draft.Title == "Member Topic" && draft.Content == "Member Body", "the member's topic should've been turned into a draft")
+	expectNilErr(t, c.Drafts.Delete(uid, "forum", 2))
+
+	// The author has been deleted, so there's no one to publish it as
+	u, err := c.Users.Get(uid)
+	expectNilErr(t, err)
+	expectNilErr(t, u.Delete())
+	ghost := &c.ScheduledTopic{ParentID: 2, Title: "Ghost Topic", Content: "Ghost Body", CreatedBy: uid, PublishAt: time.Now().Add(-time.Minute)}
+	ghost.ID, err = c.ScheduledTopics.Create(ghost)
+	expectNilErr(t, err)
+	tid, err = c.ScheduledTopics.Publish(ghost)
+	expect(t, err == sql.ErrNoRows, "a topic by a deleted user shouldn't be published")
+	expect(t, tid == 0, "the deleted user's topic shouldn't have been published")
+}
+
+func TestTopicTimedActions(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+	tid, err := c.Topics.Create(2, "Timed Topic", "Timed Body", 1, "")
+	expectNilErr(t, err)
+	topic, err := c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expectNilErr(t, topic.Stick())
+
+	expect(t, c.TopicTimedActions.Set(tid, "delete", 1, false, 1) == c.ErrBadTimedAction, "delete isn't an action which can be timed")
+	expect(t, c.TopicTimedActions.Set(tid, "lock", 0, false, 1) == c.ErrBadTimedAction, "an action has to be at least a day away")
+	expectNilErr(t, c.TopicTimedActions.Set(tid, "unpin", 7, false, 1))
+	// Setting it again replaces the one which is there
+	expectNilErr(t, c.TopicTimedActions.Set(tid, "unpin", 1, false, 1))
+	expectNilErr(t, c.TopicTimedActions.Set(tid, "lock", 30, true, 1))
+	actions, err := c.TopicTimedActions.GetByTopic(tid)
+	expectNilErr(t, err)
+	expectf(t, len(actions) == 2, "there should be 2 timed actions, not %d", len(actions))
+	unpin := actions[0]
+	expect(t, unpin.Action == "unpin" && unpin.Days == 1 && !unpin.Inactive, "the unpin action should've been replaced")
+	expect(t, unpin.DueAt(topic).Equal(unpin.CreatedAt.AddDate(0, 0, 1)), "the unpin should be due a day after it was set")
+	lock := actions[1]
+	expect(t, lock.Inactive, "the lock should count from the last post")
+	topic.LastReplyAt = lock.CreatedAt.Add(time.Hour)
+	expect(t, lock.DueAt(topic).Equal(topic.LastReplyAt.AddDate(0, 0, 30)), "the lock should be due 30 days after the last post")
+
+	// Neither of them are due yet
+	expectNilErr(t, c.TopicTimedActions.RunDue())
+	actions, err = c.TopicTimedActions.GetByTopic(tid)
+	expectNilErr(t, err)
+	expectf(t, len(actions) == 2, "there should still be 2 timed actions, not %d", len(actions))
+
+	expectNilErr(t, c.TopicTimedActions.Run(unpin))
+	topic, err = c.Topics.Get(tid)
+	expectNilErr(t, err)
+	expect(t, !topic.Sticky, "the topic should've been unpinned")
+	expect(t, topic.PostCount == 2, "the unpin should've been noted in the topic")
+	_, err = c.TopicTimedActions.Get(unpin.ID)
+	expect(t, err == sql.ErrNoRows, "the unpin should be gone once it's been done")
+
+	// Deleting the topic takes the actions waiting on it with it
+	expectNilErr(t, topic.Delete())
+	_, err = c.TopicTimedActions.Get(lock.ID)
+	expect(t, err == sql.ErrNoRows, "the lock should've gone with the topic")
+}
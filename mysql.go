@@ -1,4 +1,4 @@
-// +build !pgsql,!mssql
+// +build !pgsql,!mssql,!sqlite
 
 /*
 *
@@ -121,6 +121,7 @@ func TestPreparser(t *testing.T) {
 	l.Add("@Admin\ndd", "@1\ndd")
 	l.Add("d@Admin", "d@Admin")
 	l.Add("\\@Admin", "@Admin")
+	l.Add("\\*hi\\*", "*hi*")
 	l.Add("@元気", "@元気")
 	// TODO: More tests for unicode names?
 	//l.Add("\\\\@Admin", "@1")
@@ -141,6 +142,23 @@ func TestPreparser(t *testing.T) {
 			t.Error("Expected:", "'"+item.Expects+"'")
 		}
 	}
+
+	// The Markdown plugin needs the backslashes which aren't escaping a tag or a mention
+	c.PreparseKeepBackslashes = true
+	defer func() { c.PreparseKeepBackslashes = false }()
+	l = &METriList{nil}
+	l.Add("\\*hi\\*", "\\*hi\\*")
+	l.Add("\\\\", "\\\\")
+	l.Add("hi\\", "hi\\")
+	l.Add("\\@Admin", "@Admin")
+	l.Add("\\<blockquote>hi</blockquote>", "&lt;blockquote&gt;hi&lt;/blockquote&gt;")
+	for _, item := range l.Items {
+		if res := c.PreparseMessage(item.Msg); res != item.Expects {
+			t.Error("Testing string '" + item.Msg + "'")
+			t.Error("Bad output:", "'"+res+"'")
+			t.Error("Expected:", "'"+item.Expects+"'")
+		}
+	}
 }
 
 func TestParser(t *testing.T) {
@@ -362,6 +380,8 @@ func TestParser(t *testing.T) {
 	l.Add("# ", "# ")
 	l.Add(" @", " @")
 	l.Add(" #", " #")
+	l.Add("a # b #tid-1", "a # b <a href='/topic/1'>#tid-1</a>")
+	l.Add("# hi #tid-1", "# hi <a href='/topic/1'>#tid-1</a>")
 	l.Add("#@", "#@")
 	l.Add("#@ ", "#@ ")
 	l.Add("#@1", "#@1")
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -12,6 +13,7 @@ import (
 	"strconv"
 
 	c "github.com/Azareal/Gosora/common"
+	"github.com/Azareal/Gosora/migrations"
 	qgen "github.com/Azareal/Gosora/query_gen"
 	_ "github.com/go-sql-driver/mysql"
 )
@@ -23,6 +25,13 @@ func addPatch(index int, handle func(*bufio.Scanner) error) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := migrate(os.Args[2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	scanner := bufio.NewScanner(os.Stdin)
 
 	// Capture panics instead of closing the window at a superhuman speed before the user can read the message on Windows
@@ -48,11 +57,14 @@ func main() {
 		log.Fatal(err)
 	}
 
-	if c.DbConfig.Adapter != "mysql" && c.DbConfig.Adapter != "" {
-		log.Fatal("Only MySQL is supported for upgrades right now, please wait for a newer build of the patcher")
+	switch c.DbConfig.Adapter {
+	case "mysql", "":
+		err = prepMySQL()
+	case "pgsql":
+		err = prepPgsql()
+	default:
+		log.Fatal("Only MySQL and PostgreSQL are supported for upgrades right now, please wait for a newer build of the patcher")
 	}
-
-	err = prepMySQL()
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -82,6 +94,32 @@ func prepMySQL() error {
 	})
 }
 
+func prepPgsql() error {
+	err := qgen.Builder.Init("pgsql", map[string]string{
+		"host":     c.DbConfig.Host,
+		"port":     c.DbConfig.Port,
+		"name":     c.DbConfig.Dbname,
+		"username": c.DbConfig.Username,
+		"password": c.DbConfig.Password,
+	})
+	if err != nil {
+		return err
+	}
+	// Older installations might not have this yet and we need it to get the IDs of the rows the patches insert
+	_, err = qgen.Builder.GetConn().Exec(qgen.PgsqlLastvalFunc)
+	return err
+}
+
+// The SQLite driver needs cgo, so it's only built into the patcher with the sqlite build tag
+func prepSqlite() error {
+	for _, name := range sql.Drivers() {
+		if name == qgen.SqliteDriver {
+			return qgen.Builder.Init("sqlite", map[string]string{"name": c.DbConfig.Dbname})
+		}
+	}
+	return errors.New("this patcher wasn't built with SQLite support, build it again with -tags sqlite")
+}
+
 type SchemaFile struct {
 	DBVersion          string // Current version of the database schema
 	DynamicFileVersion string
@@ -145,5 +183,12 @@ func patcher(scanner *bufio.Scanner) error {
 		fmt.Println("No new patches found.")
 	}
 
-	return nil
+	// The legacy patches come first, as the migrations assume they've been applied
+	fmt.Println("Applying the migrations")
+	mg := migrations.NewMigrator(os.Stdout)
+	err = mg.EnsureTable()
+	if err != nil {
+		return err
+	}
+	return mg.Up(0)
 }
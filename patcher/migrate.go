@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	c "github.com/Azareal/Gosora/common"
+	"github.com/Azareal/Gosora/migrations"
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+func migrateUsage() {
+	fmt.Println("Usage: patcher migrate <up|down|status|verify> [flags]")
+	fmt.Println("  up      applies the pending migrations")
+	fmt.Println("  down    rolls back the newest migrations, one unless -steps or -to is given")
+	fmt.Println("  status  lists the migrations and whether they've been applied")
+	fmt.Println("  verify  checks that the applied migrations haven't been changed since")
+}
+
+// migrate is the non-interactive way of dealing with the schema, so it can be run from scripts
+func migrate(args []string) error {
+	if len(args) == 0 {
+		migrateUsage()
+		os.Exit(2)
+	}
+	cmd := args[0]
+	fs := flag.NewFlagSet("migrate "+cmd, flag.ExitOnError)
+	to := fs.Int("to", 0, "the version to migrate up or down to")
+	steps := fs.Int("steps", 1, "the number of migrations to roll back when -to isn't given")
+	dryRun := fs.Bool("dry-run", false, "print the SQL rather than running it")
+	adapter := fs.String("adapter", "", "print the SQL for this adapter without connecting to a database, implies -dry-run")
+	from := fs.Int("from", 0, "the version the database is assumed to be at when -adapter is used")
+	fs.Parse(args[1:])
+
+	var mg *migrations.Migrator
+	if *adapter != "" {
+		a, err := qgen.GetAdapter(*adapter)
+		if err != nil {
+			return fmt.Errorf("unknown adapter '%s'", *adapter)
+		}
+		mg = migrations.NewOfflineMigrator(a, *from, os.Stdout)
+	} else {
+		err := prepMigrate()
+		if err != nil {
+			return err
+		}
+		mg = migrations.NewMigrator(os.Stdout)
+		mg.DryRun = *dryRun
+		err = mg.EnsureTable()
+		if err != nil {
+			return err
+		}
+	}
+
+	switch cmd {
+	case "up":
+		return mg.Up(*to)
+	case "down":
+		target := *to
+		if target == 0 {
+			applied, err := mg.Applied()
+			if err != nil {
+				return err
+			}
+			// Count back over the applied migrations rather than the version numbers, as there might be gaps
+			var versions []int
+			for _, m := range migrations.List() {
+				if _, ok := applied[m.Version]; ok {
+					versions = append(versions, m.Version)
+				}
+			}
+			if i := len(versions) - *steps - 1; i >= 0 {
+				target = versions[i]
+			}
+		}
+		return mg.Down(target)
+	case "status":
+		return mg.Status()
+	case "verify":
+		err := mg.Verify()
+		if err == nil {
+			fmt.Println("Every applied migration matches")
+		}
+		return err
+	}
+	migrateUsage()
+	os.Exit(2)
+	return nil
+}
+
+func prepMigrate() error {
+	log.Print("Loading the configuration data")
+	err := c.LoadConfig()
+	if err != nil {
+		return err
+	}
+	err = c.ProcessConfig()
+	if err != nil {
+		return err
+	}
+	switch c.DbConfig.Adapter {
+	case "mysql", "":
+		return prepMySQL()
+	case "pgsql":
+		return prepPgsql()
+	case "sqlite":
+		return prepSqlite()
+	case "mssql":
+		// The mssql adapter can build the queries, but it can't connect to anything yet
+		return errors.New("the patcher can't connect to mssql databases yet, use -adapter mssql to print the SQL for the migrations and run it yourself")
+	}
+	return fmt.Errorf("the patcher doesn't know how to connect to the '%s' adapter", c.DbConfig.Adapter)
+}
@@ -53,6 +53,10 @@ func init() {
 	addPatch(33, patch33)
 	addPatch(34, patch34)
 	addPatch(35, patch35)
+	addPatch(36, patch36)
+	addPatch(37, patch37)
+	addPatch(38, patch38)
+	addPatch(39, patch39)
 }
 
 func bcol(col string, val bool) qgen.DBTableColumn {
@@ -774,7 +778,8 @@ func patch29(scanner *bufio.Scanner) error {
 	fixCols := func(tbls ...string) error {
 		for _, tbl := range tbls {
 			//err := execStmt(qgen.Builder.RenameColumn(tbl, "ipaddress","ip"))
-			err := execStmt(qgen.Builder.ChangeColumn(tbl, "ipaddress", ccol("ip", 200, "''")))
+			// The rename is a statement of it's own on PostgreSQL
+			err := qgen.Builder.ExecMulti(qgen.Builder.GetAdapter().ChangeColumn("", tbl, "ipaddress", ccol("ip", 200, "''")))
 			if err != nil {
 				return err
 			}
@@ -940,3 +945,118 @@ func patch35(scanner *bufio.Scanner) error {
 	}
 	return execStmt(qgen.Builder.AddColumn("topics", tC{"weekOddViews", "int", 0, false, false, "0"}, nil))
 }
+
+func patch36(scanner *bufio.Scanner) error {
+	err := createTable("profile_fields", "utf8mb4", "utf8mb4_general_ci",
+		[]tC{
+			{"pfid", "int", 0, false, true, ""},
+			ccol("name", 100, ""),
+			ccol("type", 50, ""),
+			{"options", "text", 0, false, false, ""},
+			bcol("required", false),
+			{"maxLength", "int", 0, false, false, "0"},
+			{"allowedGroups", "text", 0, false, false, ""},
+			{"privacy", "int", 0, false, false, "1"},
+			bcol("showOnPosts", false),
+			{"order", "int", 0, false, false, "0"},
+		},
+		[]tK{
+			{"pfid", "primary", "", false},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return createTable("users_profile_fields", "utf8mb4", "utf8mb4_general_ci",
+		[]tC{
+			{"uid", "int", 0, false, false, ""},
+			{"pfid", "int", 0, false, false, ""},
+			{"value", "text", 0, false, false, ""},
+			{"privacy", "int", 0, false, false, "0"},
+		},
+		[]tK{
+			{"uid,pfid", "unique", "", false},
+		},
+	)
+}
+
+func patch37(scanner *bufio.Scanner) error {
+	err := execStmt(qgen.Builder.AddColumn("topics", tC{"prefix", "int", 0, false, false, "0"}, nil))
+	if err != nil {
+		return err
+	}
+
+	err = createTable("topic_prefixes", "utf8mb4", "utf8mb4_general_ci",
+		[]tC{
+			{"prid", "int", 0, false, true, ""},
+			ccol("name", 100, ""),
+			ccol("cssClass", 100, "''"),
+			{"forums", "text", 0, false, false, ""},
+			{"allowedGroups", "text", 0, false, false, ""},
+			{"order", "int", 0, false, false, "0"},
+		},
+		[]tK{
+			{"prid", "primary", "", false},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return createTable("topic_tags", "utf8mb4", "utf8mb4_general_ci",
+		[]tC{
+			{"tid", "int", 0, false, false, ""},
+			ccol("tag", 100, ""),
+		},
+		[]tK{
+			{"tid,tag", "unique", "", false},
+		},
+	)
+}
+
+func patch38(scanner *bufio.Scanner) error {
+	err := execStmt(qgen.Builder.AddColumn("forums", bcol("questions", false), nil))
+	if err != nil {
+		return err
+	}
+	err = execStmt(qgen.Builder.AddColumn("topics", tC{"answer", "int", 0, false, false, "0"}, nil))
+	if err != nil {
+		return err
+	}
+	err = execStmt(qgen.Builder.AddColumn("replies", tC{"votes", "int", 0, false, false, "0"}, nil))
+	if err != nil {
+		return err
+	}
+
+	return createTable("reply_votes", "", "",
+		[]tC{
+			{"rid", "int", 0, false, false, ""},
+			{"tid", "int", 0, false, false, ""},
+			{"uid", "int", 0, false, false, ""},
+			{"weight", "tinyint", 0, false, false, "1"},
+			{"createdAt", "datetime", 0, false, false, ""},
+		},
+		[]tK{
+			{"rid,uid", "unique", "", false},
+		},
+	)
+}
+
+func patch39(scanner *bufio.Scanner) error {
+	for _, table := range []string{"topics", "replies"} {
+		err := execStmt(qgen.Builder.AddColumn(table, bcol("deleted", false), nil))
+		if err != nil {
+			return err
+		}
+		err = execStmt(qgen.Builder.AddColumn(table, tC{"deletedAt", "datetime", 0, true, false, ""}, nil))
+		if err != nil {
+			return err
+		}
+		err = execStmt(qgen.Builder.AddColumn(table, tC{"deletedBy", "int", 0, false, false, "0"}, nil))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
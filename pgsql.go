@@ -1,16 +1,19 @@
 // +build pgsql
 
-/* Copyright Azareal 2016 - 2020 */
-/* Super experimental and incomplete. DON'T USE IT YET! */
+/*
+*
+*	Gosora PostgreSQL Interface
+*	Copyright Azareal 2016 - 2020
+*
+ */
 package main
 
 import (
-	"database/sql"
-	"strings"
+	"log"
 
 	c "github.com/Azareal/Gosora/common"
-	"github.com/Azareal/Gosora/query_gen"
-	_ "github.com/lib/pq"
+	qgen "github.com/Azareal/Gosora/query_gen"
+	"github.com/pkg/errors"
 )
 
 // TODO: Add support for SSL for all database drivers, not just pgsql
@@ -23,23 +26,20 @@ func init() {
 
 func initPgsql() (err error) {
 	// TODO: Investigate connect_timeout to see what it does exactly and whether it's relevant to us
-	var _dbpassword string
-	if c.DbConfig.Password != "" {
-		_dbpassword = " password='" + _escape_bit(c.DbConfig.Password) + "'"
-	}
-	// TODO: Move this bit to the query gen lib
-	db, err = sql.Open("postgres", "host='"+_escape_bit(c.DbConfig.Host)+"' port='"+_escape_bit(c.DbConfig.Port)+"' user='"+_escape_bit(c.DbConfig.Username)+"' dbname='"+_escape_bit(c.DbConfig.Dbname)+"'"+_dbpassword+" sslmode='"+dbSslmode+"'")
+	err = qgen.Builder.Init("pgsql", map[string]string{
+		"host":     c.DbConfig.Host,
+		"port":     c.DbConfig.Port,
+		"name":     c.DbConfig.Dbname,
+		"username": c.DbConfig.Username,
+		"password": c.DbConfig.Password,
+		"sslmode":  dbSslmode,
+	})
 	if err != nil {
-		return err
-	}
-
-	// Make sure that the connection is alive
-	err = db.Ping()
-	if err != nil {
-		return err
+		return errors.WithStack(err)
 	}
 
 	// Set the number of max open connections. How many do we need? Might need to do some tests.
+	db = qgen.Builder.GetConn()
 	db.SetMaxOpenConns(64)
 	db.SetMaxIdleConns(32)
 
@@ -48,22 +48,20 @@ func initPgsql() (err error) {
 
 	err = _gen_pgsql()
 	if err != nil {
-		return err
+		return errors.WithStack(err)
 	}
 
-	// Ready the query builder
-	qgen.Builder.SetConn(db)
-	err = qgen.Builder.SetAdapter("pgsql")
+	// These identifiers aren't quoted, so PostgreSQL folds them into the lower-case names the query generator gives our columns
+	log.Print("Preparing getActivityFeedByWatcher statement.")
+	stmts.getActivityFeedByWatcher, err = db.Prepare("SELECT activity_stream_matches.asid, activity_stream.actor, activity_stream.targetUser, activity_stream.event, activity_stream.elementType, activity_stream.elementID, activity_stream.createdAt FROM activity_stream_matches INNER JOIN activity_stream ON activity_stream_matches.asid = activity_stream.asid AND activity_stream_matches.watcher != activity_stream.actor WHERE watcher=? ORDER BY activity_stream.asid DESC LIMIT ?")
 	if err != nil {
-		return err
+		return errors.WithStack(err)
 	}
 
-	// TO-DO Handle the queries which the query generator can't handle yet
-
+	log.Print("Preparing getActivityCountByWatcher statement.")
+	stmts.getActivityCountByWatcher, err = db.Prepare("SELECT count(*) FROM activity_stream_matches INNER JOIN activity_stream ON activity_stream_matches.asid = activity_stream.asid AND activity_stream_matches.watcher != activity_stream.actor WHERE watcher=?")
+	if err != nil {
+		return errors.WithStack(err)
+	}
 	return nil
 }
-
-func _escape_bit(bit string) string {
-	// TODO: Write a custom parser, so that backslashes work properly in the sql.Open string. Do something similar for the database driver, if possible?
-	return strings.Replace(bit, "'", "\\'", -1)
-}
@@ -0,0 +1,36 @@
+// +build pgsql
+
+package main
+
+import (
+	"testing"
+
+	qgen "github.com/Azareal/Gosora/query_gen"
+)
+
+// These are only run against PostgreSQL, e.g. with run-linux-tests-pgsql, the rest of the tests are run there too
+func TestPgsqlDriver(t *testing.T) {
+	miscinit(t)
+	db := qgen.Builder.GetConn()
+
+	// Something which looks like the end of a statement in a string shouldn't change how the query is sent
+	stmt, err := db.Prepare("SELECT CAST(? AS TEXT) || ';\n'")
+	expectNilErr(t, err)
+	defer stmt.Close()
+	var res string
+	expectNilErr(t, stmt.QueryRow("a").Scan(&res))
+	expectf(t, res == "a;\n", "the placeholder should've been filled in, not '%s'", res)
+
+	// Several statements at once have to go through ExecMulti
+	_, err = db.Prepare("SELECT 1;\nSELECT 2")
+	expect(t, err != nil, "several statements shouldn't be preparable")
+	expectNilErr(t, qgen.Builder.ExecMulti("DROP TABLE IF EXISTS \"pgsql_tests\";\nCREATE TABLE \"pgsql_tests\" (\"a\" int NOT NULL DEFAULT 0)", nil))
+	defer db.Exec("DROP TABLE IF EXISTS \"pgsql_tests\"")
+	a := qgen.Builder.GetAdapter()
+	expectNilErr(t, qgen.Builder.ExecMulti(a.ChangeColumn("", "pgsql_tests", "a", qgen.DBTableColumn{"b", "int", 0, false, false, "1"})))
+	_, err = db.Exec("INSERT INTO \"pgsql_tests\" DEFAULT VALUES")
+	expectNilErr(t, err)
+	var b int
+	expectNilErr(t, db.QueryRow("SELECT \"b\" FROM \"pgsql_tests\"").Scan(&b))
+	expectIntToBeX(t, b, 1, "the column should've been renamed and given it's new default")
+}
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"math/rand"
+	"regexp"
 	"strconv"
+	"strings"
 	"testing"
 
 	c "github.com/Azareal/Gosora/common"
@@ -182,95 +186,313 @@ func TestBBCodeRender(t *testing.T) {
 	}
 }
 
-func TestMarkdownRender(t *testing.T) {
-	//t.Skip()
-	if err := e.InitMarkdown(c.Plugins["markdown"]); err != nil {
-		t.Fatal(err)
+func TestBBCodeTags(t *testing.T) {
+	if e := e.InitBbcode(c.Plugins["bbcode"]); e != nil {
+		t.Fatal(e)
+	}
+
+	l := &MEPairList{nil}
+	l.Add("[B]hi[/B]", "<b>hi</b>")
+	l.Add("[b][i]hi[/i][/b]", "<b><i>hi</i></b>")
+	l.Add("[b][i]hi[/b]", "<b><i>hi</i></b>")
+	l.Add("[b]hi[/b][/i]", "<b>hi</b>[/i]")
+	l.Add("[quote][quote]hi[/quote][/quote]", "<blockquote><blockquote>hi</blockquote></blockquote>")
+	l.Add("[quote=Azareal]hi[/quote]", "<blockquote><span class='quote_by'>Azareal</span>hi</blockquote>")
+	l.Add("[quote=Azareal;12]hi[/quote]", "<blockquote><a class='quote_by'href='#post-12'>Azareal</a>hi</blockquote>")
+	l.Add("[quote=;12]hi[/quote]", "[quote=;12]hi[/quote]")
+	l.Add("[quote]hi", "[quote]hi")
+	l.Add("[quote][h1]hi[/quote]", "<blockquote>[h1]hi</blockquote>")
+	l.Add("[list][*]a[*]b[/list]", "<ul><li>a</li><li>b</li></ul>")
+	l.Add("[list]<br>[*]a<br>[*]b<br>[/list]", "<ul><li>a<br></li><li>b<br></li></ul>")
+	l.Add("[list=1][*]a[/*][*]b[/*][/list]", "<ol><li>a</li><li>b</li></ol>")
+	l.Add("[list=a][*]a[/list]", "<ol type='a'><li>a</li></ol>")
+	l.Add("[list=x][*]a[/list]", "[list=x][*]a[/list]")
+	l.Add("[list][*]a", "[list][*]a")
+	l.Add("[*]a", "[*]a")
+	l.Add("[list][*][list][*]a[/list][/list]", "<ul><li><ul><li>a</li></ul></li></ul>")
+	l.Add("[table][tr][th]a[/th][th]b[/th][/tr][tr][td]1[td]2[/table]", "<table class='bbcode_table'><tr><th>a</th><th>b</th></tr><tr><td>1</td><td>2</td></tr></table>")
+	l.Add("[table][tr][td]1[tr][td]2[/table]", "<table class='bbcode_table'><tr><td>1</td></tr><tr><td>2</td></tr></table>")
+	l.Add("[td]1[/td]", "[td]1[/td]")
+	l.Add("[tr][td]1[/td][/tr]", "[tr][td]1[/td][/tr]")
+	l.Add("[code=go]fmt.Println()[/code]", "<span class='codequotes'data-lang='go'>fmt.Println()</span>")
+	l.Add("[code=c++]x[/code]", "<span class='codequotes'data-lang='c++'>x</span>")
+	l.Add("[code=x'y]x[/code]", "<span class='codequotes'>x</span>")
+	l.Add("[code][list][*]a[/list][/code]", "<span class='codequotes'>[list][*]a[/list]</span>")
+	l.Add("[code]hi", "[code]hi")
+	l.Add("[size=5]hi[/size]", "<span style='font-size:150%'>hi</span>")
+	l.Add("[size=8]hi[/size]", "[size=8]hi[/size]")
+	l.Add("[size=1;x]hi[/size]", "[size=1;x]hi[/size]")
+	l.Add("[color=red]hi[/color]", "<span style='color:red'>hi</span>")
+	l.Add("[color=#FF0000]hi[/color]", "<span style='color:#FF0000'>hi</span>")
+	l.Add("[color=#ff]hi[/color]", "[color=#ff]hi[/color]")
+	l.Add("[color=red;background:url(x)]hi[/color]", "[color=red;background:url(x)]hi[/color]")
+	l.Add("[spoiler][b]hi[/b][/spoiler]", "<spoiler><b>hi</b></spoiler>")
+	l.Add("[url=https://github.com/Azareal/Gosora]Gosora[/url]", "<a rel='ugc'href='https://github.com/Azareal/Gosora'>Gosora</a>")
+	l.Add("[url=https://github.com/Azareal/Gosora][b]Gosora[/b][/url]", "<a rel='ugc'href='https://github.com/Azareal/Gosora'><b>Gosora</b></a>")
+	l.Add("[url=https://a.com][url=https://b.com]b[/url][/url]", "<a rel='ugc'href='https://a.com'>[url=https://b.com]b</a>[/url]")
+	l.Add("[url=javascript:alert(1)]hi[/url]", "[url=javascript:alert(1)]hi[/url]")
+	l.Add("[url=https://a.com'onmouseover='alert(1)]hi[/url]", "[url=https://a.com'onmouseover='alert(1)]hi[/url]")
+	l.Add("[url]javascript:alert(1)[/url]", "<red>[Invalid URL]</red>")
+	l.Add("[url]https://a.com' onmouseover='alert(1)[/url]", "<red>[Invalid URL]</red>")
+	l.Add("[img]https://example.com/a.png[/img]", "<img src='https://example.com/a.png'class='bbcode_img'>")
+	l.Add("[img]/s/a.png[/img]", "<img src='/s/a.png'class='bbcode_img'>")
+	l.Add("[img]javascript:alert(1)[/img]", "<red>[Invalid URL]</red>")
+	l.Add("[img]ftp://example.com/a.png[/img]", "<red>[Invalid URL]</red>")
+	l.Add("[img]https://example.com/a.png", "[img]https://example.com/a.png")
+	l.Add("[url=https://github.com][img]https://example.com/a.png[/img][/url]", "<a rel='ugc'href='https://github.com'><img src='https://example.com/a.png'class='bbcode_img'></a>")
+	l.Add("[rand]5", "<red>[Missing Tag]</red>[rand]5")
+	l.Add("[b", "[b")
+	l.Add("[b=1]hi[/b]", "[b=1]hi[/b]")
+	l.Add("[/b]", "[/b]")
+	l.Add("[]", "[]")
+	l.Add("[*", "[*")
+	l.Add("[quote=bob", "[quote=bob")
+	l.Add("[i]-你好-", "<i>-你好-</i>")
+	l.Add("[b]😀[i]😀[/b]😀", "<b>😀<i>😀</i></b>😀")
+	for _, item := range l.Items {
+		if res := e.BbcodeFullParse(item.Msg); res != item.Expects {
+			t.Error("Testing string '" + item.Msg + "'")
+			t.Error("Bad output:", "'"+res+"'")
+			t.Error("Expected:", "'"+item.Expects+"'")
+		}
+	}
+
+	l = &MEPairList{nil}
+	l.Add("[quote=Azareal;12][i]hi[/i][/quote]", "hi")
+	l.Add("[list][*]a[*]b[/list]", "ab")
+	l.Add("[url=https://github.com]Gosora[/url]", "Gosora")
+	l.Add("[url]https://github.com[/url]", "https://github.com")
+	l.Add("a[img]https://example.com/a.png[/img]b", "ab")
+	l.Add("[code][b]hi[/b][/code]", "[b]hi[/b]")
+	l.Add("[h1]hi", "[h1]hi")
+	for _, item := range l.Items {
+		if res := e.BbcodeStripTags(item.Msg); res != item.Expects {
+			t.Error("Testing string '" + item.Msg + "'")
+			t.Error("Bad output:", "'"+res+"'")
+			t.Error("Expected:", "'"+item.Expects+"'")
+		}
+	}
+}
+
+// The tags the BBCode plugin is allowed to output, anything else means something has gotten through which shouldn't have
+var bbcodeSafeTag = regexp.MustCompile(`^<(/?(b|i|u|s|h2|spoiler|blockquote|ul|ol|li|table|tr|td|th|span|a|red)|ol type='[aAiI]'|table class='bbcode_table'|span class='codequotes'(data-lang='[a-zA-Z0-9+#._-]+')?|span style='font-size:[0-9]+%'|span style='color:#?[a-zA-Z0-9]+'|span class='quote_by'|a class='quote_by'href='#post-[0-9]+'|a (rel='ugc')?href='((https?://|ftp://|mailto:|/)[^'"<>\s\x60\\]*)?'|img src='(https?://|/)[^'"<>\s\x60\\]*'class='bbcode_img')>$`)
+
+// bbcodeCheckHTML makes sure out only has the tags the plugin is meant to output and that they're balanced, the input mustn't have any of it's own
+func bbcodeCheckHTML(out string) error {
+	var stack []string
+	for i := 0; i < len(out); i++ {
+		if out[i] == '>' {
+			return fmt.Errorf("stray > at %d", i)
+		}
+		if out[i] != '<' {
+			continue
+		}
+		end := strings.IndexByte(out[i:], '>')
+		if end == -1 {
+			return fmt.Errorf("unterminated tag at %d", i)
+		}
+		tag := out[i : i+end+1]
+		if !bbcodeSafeTag.MatchString(tag) {
+			return fmt.Errorf("unsafe tag %s", tag)
+		}
+		i += end
+		name := strings.TrimPrefix(strings.Trim(tag, "<>"), "/")
+		if sp := strings.IndexAny(name, " "); sp != -1 {
+			name = name[:sp]
+		}
+		switch {
+		case name == "img":
+		case tag[1] == '/':
+			if len(stack) == 0 || stack[len(stack)-1] != name {
+				return fmt.Errorf("%s doesn't close the last tag opened", tag)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			stack = append(stack, name)
+		}
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("unclosed tags: %v", stack)
+	}
+	return nil
+}
+
+var bbcodeFuzzPieces = []string{"[b]", "[/b]", "[I]", "[/i]", "[u]", "[/u]", "[s]", "[/s]", "[h1]", "[/h1]", "[quote]", "[quote=Azareal;12]", "[quote=a'b]", "[/quote]", "[list]", "[list=1]", "[list=A]", "[list=x]", "[*]", "[/*]", "[/list]", "[table]", "[tr]", "[td]", "[th]", "[/td]", "[/th]", "[/tr]", "[/table]", "[code]", "[code=go]", "[code=x'y]", "[/code]", "[size=5]", "[size=9]", "[/size]", "[color=red]", "[color=#fff]", "[color=red;x:url(a)]", "[/color]", "[spoiler]", "[/spoiler]", "[url]", "[url=https://example.com/]", "[url=javascript:alert(1)]", "[url=/a'b]", "[/url]", "[img]", "[/img]", "[rand]", "[/rand]", "https://example.com/a.png", "javascript:alert(1)", "5", "-1", "'", "\"", "hi", " ", "\n", "<br>", "[", "]", "=", "/", ";", "😀", "你好"}
+
+// TestBBCodeProperties throws random mixes of tags and text at the parser, the output has to be safe and balanced no matter what it gets
+func TestBBCodeProperties(t *testing.T) {
+	if e := e.InitBbcode(c.Plugins["bbcode"]); e != nil {
+		t.Fatal(e)
+	}
+	runs := 5000
+	if testing.Short() {
+		runs = 500
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < runs; i++ {
+		var sb strings.Builder
+		for n := rnd.Intn(40); n > 0; n-- {
+			sb.WriteString(bbcodeFuzzPieces[rnd.Intn(len(bbcodeFuzzPieces))])
+		}
+		msg := sb.String()
+		// <br> is the only HTML which gets to the plugin from the outer parser here
+		out := strings.Replace(e.BbcodeFullParse(msg), "<br>", "", -1)
+		if err := bbcodeCheckHTML(out); err != nil {
+			t.Error("Testing string '" + msg + "'")
+			t.Error("Bad output:", "'"+out+"'")
+			t.Error(err)
+			break
+		}
+		// The tags are the only thing which should be taken out
+		if strip := e.BbcodeStripTags(msg); len(strip) > len(msg) {
+			t.Error("Testing string '" + msg + "'")
+			t.Error("Stripping the tags made it longer:", "'"+strip+"'")
+			break
+		}
+	}
+
+	// Anything without a tag in it should come out the other side untouched
+	for i := 0; i < runs; i++ {
+		var sb strings.Builder
+		for n := rnd.Intn(20); n > 0; n-- {
+			piece := bbcodeFuzzPieces[rnd.Intn(len(bbcodeFuzzPieces))]
+			sb.WriteString(strings.NewReplacer("[", "", "]", "").Replace(piece))
+		}
+		msg := sb.String()
+		if out := e.BbcodeFullParse(msg); out != msg {
+			t.Error("Testing string '" + msg + "'")
+			t.Error("Bad output:", "'"+out+"'")
+			break
+		}
+	}
+}
+
+func FuzzBBCode(f *testing.F) {
+	if e := e.InitBbcode(c.Plugins["bbcode"]); e != nil {
+		f.Fatal(e)
+	}
+	for _, piece := range bbcodeFuzzPieces {
+		f.Add(piece + "hi" + piece)
 	}
+	f.Add("[list][*]a[*][b]b[/list]")
+	f.Add("[table][tr][td]1[td]2[tr][th]3[/table]")
+	f.Add("[url=https://example.com/][img]https://example.com/a.png[/img][/url]")
+	f.Fuzz(func(t *testing.T, msg string) {
+		// The outer parser escapes the HTML before it gets here
+		if strings.ContainsAny(msg, "<>") {
+			return
+		}
+		if err := bbcodeCheckHTML(e.BbcodeFullParse(msg)); err != nil {
+			t.Error(err)
+		}
+		_ = e.BbcodeStripTags(msg)
+	})
+}
 
+func TestMarkdownRender(t *testing.T) {
+	//t.Skip()
+	// These are how messages look by the time they get to the plugin, they've been escaped and the rest of the parser has had a go at them
 	l := &MEPairList{nil}
-	l2 := &MEPairList{nil}
-	// TODO: Fix more of these odd cases
 	l.Add("", "")
-	l.Add(" ", " ")
-	l.Add("  ", "  ")
-	l.Add("   ", "   ")
-	l.Add("\t", "\t")
-	l.Add("\n", "\n")
-	l.Add("*", "*")
-	l.Add("`", "`")
-	//l.Add("**", "<i></i>")
-	l.Add("h", "h")
-	l.Add("hi", "hi")
-	l.Add("**h**", "<b>h</b>")
-	l.Add("**hi**", "<b>hi</b>")
-	l.Add("_h_", "<u>h</u>")
-	l.Add("_hi_", "<u>hi</u>")
-	l.Add(" _hi_", " <u>hi</u>")
-	l.Add("h_hi_h", "h_hi_h")
-	l.Add("h _hi_ h", "h <u>hi</u> h")
-	l.Add("h _hi_h", "h <u>hi</u>h")
-	l.Add("*h*", "<i>h</i>")
-	l.Add("*hi*", "<i>hi</i>")
-	l.Add("~h~", "<s>h</s>")
-	l.Add("~hi~", "<s>hi</s>")
-	l.Add("`hi`", "<blockquote>hi</blockquote>")
-	// TODO: Hide the backslash after escaping the item
-	// TODO: Doesn't behave consistently with d in-front of it
-	l2.Add("\\`hi`", "\\`hi`")
-	l2.Add("#", "#")
-	l2.Add("#h", "<h2>h</h2>")
-	l2.Add("#hi", "<h2>hi</h2>")
-	l2.Add("# hi", "<h2>hi</h2>")
-	l2.Add("#      hi", "<h2>hi</h2>")
-	l.Add("\n#", "\n#")
-	l.Add("\n#h", "\n<h2>h</h2>")
-	l.Add("\n#hi", "\n<h2>hi</h2>")
-	l.Add("\n#h\n", "\n<h2>h</h2>")
-	l.Add("\n#hi\n", "\n<h2>hi</h2>")
-	l.Add("*hi**", "<i>hi</i>*")
-	l.Add("**hi***", "<b>hi</b>*")
-	//l.Add("**hi*", "*<i>hi</i>")
-	l.Add("***hi***", "<b><i>hi</i></b>")
-	l.Add("***h***", "<b><i>h</i></b>")
-	l.Add("\\***h**\\*", "*<b>h</b>*")
-	l.Add("\\*\\**h*\\*\\*", "**<i>h</i>**")
-	l.Add("\\*hi\\*", "*hi*")
-	l.Add("d\\*hi\\*", "d*hi*")
-	l.Add("\\*hi\\*d", "*hi*d")
-	l.Add("d\\*hi\\*d", "d*hi*d")
-	l.Add("\\", "\\")
-	l.Add("\\\\", "\\\\")
-	l.Add("\\d", "\\d")
-	l.Add("\\\\d", "\\\\d")
-	l.Add("\\\\\\d", "\\\\\\d")
-	l.Add("d\\", "d\\")
-	l.Add("\\d\\", "\\d\\")
-	l.Add("*_hi_*", "<i><u>hi</u></i>")
-	l.Add("*~hi~*", "<i><s>hi</s></i>")
-	//l.Add("~*hi*~", "<s><i>hi</i></s>")
-	//l.Add("~ *hi* ~", "<s> <i>hi</i> </s>")
-	l.Add("_~hi~_", "<u><s>hi</s></u>")
-	l.Add("***~hi~***", "<b><i><s>hi</s></i></b>")
-	l.Add("**", "**")
-	l.Add("***", "***")
-	l.Add("****", "****")
-	l.Add("*****", "*****")
-	l.Add("******", "******")
-	l.Add("*******", "*******")
-	l.Add("~~", "~~")
-	l.Add("~~~", "~~~")
-	l.Add("~~~~", "~~~~")
-	l.Add("~~~~~", "~~~~~")
-	l.Add("|hi|", "<spoiler>hi</spoiler>")
-	l.Add("__", "__")
-	l.Add("___", "___")
-	l.Add("_ _", "<u> </u>")
-	l.Add("* *", "<i> </i>")
-	l.Add("** **", "<b> </b>")
-	l.Add("*** ***", "<b><i> </i></b>")
-	l.Add("-你好-", "-你好-")
-	l.Add("*-你好-*", "<i>-你好-</i>") // TODO: More of these Unicode tests? Emoji, Chinese, etc.?
+	l.Add(" ", "")
+	l.Add("   ", "")
+	l.Add("\t", "")
+	l.Add("<br>", "")
+	l.Add("*", "<ul>\n<li></li>\n</ul>")
+	l.Add("`", "<p>`</p>")
+	l.Add("h", "<p>h</p>")
+	l.Add("hi", "<p>hi</p>")
+	l.Add("**h**", "<p><strong>h</strong></p>")
+	l.Add("**hi**", "<p><strong>hi</strong></p>")
+	l.Add("__hi__", "<p><strong>hi</strong></p>")
+	l.Add("_h_", "<p><em>h</em></p>")
+	l.Add("_hi_", "<p><em>hi</em></p>")
+	l.Add(" _hi_", "<p><em>hi</em></p>")
+	l.Add("h_hi_h", "<p>h_hi_h</p>")
+	l.Add("h _hi_ h", "<p>h <em>hi</em> h</p>")
+	l.Add("h _hi_h", "<p>h _hi_h</p>")
+	l.Add("*h*", "<p><em>h</em></p>")
+	l.Add("*hi*", "<p><em>hi</em></p>")
+	l.Add("h*hi*h", "<p>h<em>hi</em>h</p>")
+	l.Add("~h~", "<p><del>h</del></p>")
+	l.Add("~~hi~~", "<p><del>hi</del></p>")
+	l.Add("`hi`", "<p><code>hi</code></p>")
+	l.Add("``hi ` there``", "<p><code>hi ` there</code></p>")
+	l.Add("\\`hi`", "<p>`hi`</p>")
+	l.Add("#", "<h2></h2>")
+	l.Add("#h", "<p>#h</p>")
+	l.Add("# hi", "<h2>hi</h2>")
+	l.Add("#      hi", "<h2>hi</h2>")
+	l.Add("## hi ##", "<h3>hi</h3>")
+	l.Add("###### hi", "<h6>hi</h6>")
+	l.Add("hi<br>#", "<p>hi</p>\n<h2></h2>")
+	l.Add("hi<br># hi", "<p>hi</p>\n<h2>hi</h2>")
+	l.Add("hi<br>===", "<h2>hi</h2>")
+	l.Add("*hi**", "<p><em>hi</em>*</p>")
+	l.Add("**hi***", "<p><strong>hi</strong>*</p>")
+	l.Add("***hi***", "<p><em><strong>hi</strong></em></p>")
+	l.Add("\\***h**\\*", "<p>*<strong>h</strong>*</p>")
+	l.Add("\\*\\**h*\\*\\*", "<p>**<em>h</em>**</p>")
+	l.Add("\\*hi\\*", "<p>*hi*</p>")
+	l.Add("d\\*hi\\*d", "<p>d*hi*d</p>")
+	l.Add("\\", "<p>\\</p>")
+	l.Add("\\\\", "<p>\\</p>")
+	l.Add("\\d", "<p>\\d</p>")
+	l.Add("d\\", "<p>d\\</p>")
+	l.Add("*_hi_*", "<p><em><em>hi</em></em></p>")
+	l.Add("*~hi~*", "<p><em><del>hi</del></em></p>")
+	l.Add("~*hi*~", "<p><del><em>hi</em></del></p>")
+	l.Add("**", "<p>**</p>")
+	l.Add("***", "<hr />")
+	l.Add("******", "<hr />")
+	l.Add("~~", "<p>~~</p>")
+	l.Add("~~~~~", "<pre><code></code></pre>")
+	l.Add("|hi|", "<p>|hi|</p>")
+	l.Add("__", "<p>__</p>")
+	l.Add("* *", "<ul>\n<li>\n<ul>\n<li></li>\n</ul>\n</li>\n</ul>")
+	l.Add("-你好-", "<p>-你好-</p>")
+	l.Add("*-你好-*", "<p><em>-你好-</em></p>")
+	l.Add("hi<br>there", "<p>hi<br />\nthere</p>")
+	l.Add("hi<br><br>there", "<p>hi</p>\n<p>there</p>")
+	l.Add("hi<br><br><br><br>there", "<p>hi</p>\n<p>there</p>")
+	l.Add("&gt; hi<br>&gt; there", "<blockquote>\n<p>hi<br />\nthere</p>\n</blockquote>")
+	l.Add("&gt; hi<br>there", "<blockquote>\n<p>hi<br />\nthere</p>\n</blockquote>")
+	l.Add("- a<br>- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>")
+	l.Add("1. a<br>2. b", "<ol>\n<li>a</li>\n<li>b</li>\n</ol>")
+	l.Add("3) a", "<ol start=\"3\">\n<li>a</li>\n</ol>")
+	l.Add("---", "<hr />")
+	l.Add("    code", "<pre><code>code\n</code></pre>")
+	l.Add("```<br>&lt;b&gt;&amp;<br>```", "<pre><code>&lt;b&gt;&amp;\n</code></pre>")
+	l.Add("```go<br>x := 1<br>```", "<pre><code class=\"language-go\">x := 1\n</code></pre>")
+	l.Add("&lt;b&gt;hi&lt;/b&gt;", "<p>&lt;b&gt;hi&lt;/b&gt;</p>")
+	l.Add("&lt;script&gt;alert(1)&lt;/script&gt;", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>")
+	l.Add("&#39;quoted&#39; &#34;quoted&#34; &amp;amp; &amp;copy;", "<p>'quoted' &quot;quoted&quot; &amp; ©</p>")
+	l.Add("[hi](/topic/1)", "<p><a href=\"/topic/1\" rel=\"ugc\">hi</a></p>")
+	l.Add("[hi](/topic/1 &#34;title&#34;)", "<p><a href=\"/topic/1\" title=\"title\" rel=\"ugc\">hi</a></p>")
+	l.Add("[hi](javascript:alert(1))", "<p><a href=\"\" rel=\"ugc\">hi</a></p>")
+	l.Add("[hi][1]<br><br>[1]: /topic/1", "<p><a href=\"/topic/1\" rel=\"ugc\">hi</a></p>")
+	l.Add("![hi](/a.png)", "<p><img src=\"/a.png\" alt=\"hi\" /></p>")
+	l.Add("&lt;https://github.com/Azareal/Gosora&gt;", "<p><a href=\"https://github.com/Azareal/Gosora\" rel=\"ugc\">https://github.com/Azareal/Gosora</a></p>")
+	l.Add("&lt;azareal@example.com&gt;", "<p><a href=\"mailto:azareal@example.com\" rel=\"ugc\">azareal@example.com</a></p>")
+	l.Add("<strong>hi</strong> *there*", "<p><strong>hi</strong> <em>there</em></p>")
+	l.Add("<em>*hi*</em>", "<p><em><em>hi</em></em></p>")
+	l.Add("<blockquote>hi</blockquote>", "<blockquote>\n<p>hi</p>\n</blockquote>")
+	l.Add("<a href='/user/admin.1'class='mention'>@Admin</a> **hi**", "<p><a href='/user/admin.1'class='mention'>@Admin</a> <strong>hi</strong></p>")
+	l.Add("**<a href='/topic/1'>#tid-1</a>**", "<p><strong><a href='/topic/1'>#tid-1</a></strong></p>")
+	l.Add("`<a href='/user/admin.1'class='mention'>@Admin</a>`", "<p><code>@Admin</code></p>")
+	l.Add("[<a href='/topic/1'>#tid-1</a>](/topic/2)", "<p><a href=\"/topic/2\" rel=\"ugc\">#tid-1</a></p>")
+	l.Add("![<a href='/user/admin.1'class='mention'>@Admin</a>](/a.png)", "<p><img src=\"/a.png\" alt=\"@Admin\" /></p>")
+	l.Add("<red>[Invalid Profile]</red>", "<p><red>[Invalid Profile]</red></p>")
+	l.Add("a ** b <red>*</red> c **", "<p>a ** b <red>*</red> c **</p>")
+	l.Add("😀 *hi*", "<p>😀 <em>hi</em></p>")
+	l.Add("| a | b |<br>| --- | :-: |<br>| 1 | 2 |", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th align=\"center\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>1</td>\n<td align=\"center\">2</td>\n</tr>\n</tbody>\n</table>")
+	l.Add("- [ ] todo<br>- [x] done", "<ul>\n<li><input disabled=\"\" type=\"checkbox\"> todo</li>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n</ul>")
+	l.Add("hi ﷐0﷑", "<p>hi �0�</p>")
+	l.Add("<blockquote>hi</blockquote>", "<blockquote>\n<p>hi</p>\n</blockquote>")
+	l.Add("<blockquote><strong>Admin</strong> said:<br>quoted</blockquote><br>reply *here*", "<blockquote>\n<p><strong>Admin</strong> said:<br />\nquoted</p>\n</blockquote>\n<p>reply <em>here</em></p>")
+	l.Add("<h2>Title</h2>", "<h2>Title</h2>")
+	l.Add("[hi <a href='/user/admin.1'class='mention'>@Admin</a>](/topic/2)", "<p><a href=\"/topic/2\" rel=\"ugc\">hi @Admin</a></p>")
+	l.Add("<h3>Title</h3>text", "<h3>Title</h3>\n<p>text</p>")
 
 	for _, item := range l.Items {
 		if res := e.MarkdownParse(item.Msg); res != item.Expects {
@@ -280,25 +502,279 @@ func TestMarkdownRender(t *testing.T) {
 			t.Error("Expected:", "'"+item.Expects+"'")
 		}
 	}
+}
 
-	for _, item := range l2.Items {
-		if res := e.MarkdownParse(item.Msg); res != item.Expects {
+func TestMarkdownSpec(t *testing.T) {
+	// A sample of the examples in the CommonMark spec, raw HTML is on for these, as it is in the spec
+	l := &MEPairList{nil}
+	l.Add("\tfoo\tbaz\t\tbim\n", "<pre><code>foo\tbaz\t\tbim\n</code></pre>\n")
+	l.Add(">\t\tfoo\n", "<blockquote>\n<pre><code>  foo\n</code></pre>\n</blockquote>\n")
+	l.Add("***\n---\n___\n", "<hr />\n<hr />\n<hr />\n")
+	l.Add(" - - -\n", "<hr />\n")
+	l.Add("Foo\n***\nbar\n", "<p>Foo</p>\n<hr />\n<p>bar</p>\n")
+	l.Add("####### foo\n", "<p>####### foo</p>\n")
+	l.Add("# foo *bar* \\*baz\\*\n", "<h1>foo <em>bar</em> *baz*</h1>\n")
+	l.Add("## \n#\n### ###\n", "<h2></h2>\n<h1></h1>\n<h3></h3>\n")
+	l.Add("Foo\n= =\n\nFoo\n--- -\n", "<p>Foo\n= =</p>\n<p>Foo</p>\n<hr />\n")
+	l.Add("Foo\nbar\n---\nbaz\n", "<h2>Foo\nbar</h2>\n<p>baz</p>\n")
+	l.Add("  - foo\n\n    bar\n", "<ul>\n<li>\n<p>foo</p>\n<p>bar</p>\n</li>\n</ul>\n")
+	l.Add("Foo\n    bar\n", "<p>Foo\nbar</p>\n")
+	l.Add("~~~\n<\n >\n~~~\n", "<pre><code>&lt;\n &gt;\n</code></pre>\n")
+	l.Add("```\n", "<pre><code></code></pre>\n")
+	l.Add(" ```\n aaa\naaa\n```\n", "<pre><code>aaa\naaa\n</code></pre>\n")
+	l.Add("```;\n```\n", "<pre><code class=\"language-;\"></code></pre>\n")
+	l.Add("<table><tr><td>\n<pre>\n**Hello**,\n\n_world_.\n</pre>\n</td></tr></table>\n", "<table><tr><td>\n<pre>\n**Hello**,\n<p><em>world</em>.\n</pre></p>\n</td></tr></table>\n")
+	l.Add("<a href=\"foo\">\n*bar*\n</a>\n", "<a href=\"foo\">\n*bar*\n</a>\n")
+	l.Add("<!-- Foo\n\nbar\n   baz -->\nokay\n", "<!-- Foo\n\nbar\n   baz -->\n<p>okay</p>\n")
+	l.Add("   [foo]: \n      /url  \n           'the title'  \n\n[foo]\n", "<p><a href=\"/url\" title=\"the title\">foo</a></p>\n")
+	l.Add("[foo]:\n\n[foo]\n", "<p>[foo]:</p>\n<p>[foo]</p>\n")
+	l.Add("[ΑΓΩ]: /φου\n\n[αγω]\n", "<p><a href=\"/%CF%86%CE%BF%CF%85\">αγω</a></p>\n")
+	l.Add("aaa\n             bbb\n                                       ccc\n", "<p>aaa\nbbb\nccc</p>\n")
+	l.Add("> # Foo\n> bar\n> baz\n", "<blockquote>\n<h1>Foo</h1>\n<p>bar\nbaz</p>\n</blockquote>\n")
+	l.Add("> - foo\n- bar\n", "<blockquote>\n<ul>\n<li>foo</li>\n</ul>\n</blockquote>\n<ul>\n<li>bar</li>\n</ul>\n")
+	l.Add("> foo\n\n> bar\n", "<blockquote>\n<p>foo</p>\n</blockquote>\n<blockquote>\n<p>bar</p>\n</blockquote>\n")
+	l.Add(">     code\n\n>    not code\n", "<blockquote>\n<pre><code>code\n</code></pre>\n</blockquote>\n<blockquote>\n<p>not code</p>\n</blockquote>\n")
+	l.Add("- one\n\n two\n", "<ul>\n<li>one</li>\n</ul>\n<p>two</p>\n")
+	l.Add("-one\n\n2.two\n", "<p>-one</p>\n<p>2.two</p>\n")
+	l.Add("1234567890. not ok\n", "<p>1234567890. not ok</p>\n")
+	l.Add("1.     indented code\n\n   paragraph\n\n       more code\n", "<ol>\n<li>\n<pre><code>indented code\n</code></pre>\n<p>paragraph</p>\n<pre><code>more code\n</code></pre>\n</li>\n</ol>\n")
+	l.Add("- foo\n-\n- bar\n", "<ul>\n<li>foo</li>\n<li></li>\n<li>bar</li>\n</ul>\n")
+	l.Add("  1.  A paragraph\n      with two lines.\n", "<ol>\n<li>A paragraph\nwith two lines.</li>\n</ol>\n")
+	l.Add("- foo\n- bar\n+ baz\n", "<ul>\n<li>foo</li>\n<li>bar</li>\n</ul>\n<ul>\n<li>baz</li>\n</ul>\n")
+	l.Add("- foo\n\n- bar\n\n\n- baz\n", "<ul>\n<li>\n<p>foo</p>\n</li>\n<li>\n<p>bar</p>\n</li>\n<li>\n<p>baz</p>\n</li>\n</ul>\n")
+	l.Add("* a\n*\n\n* c\n", "<ul>\n<li>\n<p>a</p>\n</li>\n<li></li>\n<li>\n<p>c</p>\n</li>\n</ul>\n")
+	l.Add("- a\n- ```\n  b\n\n\n  ```\n- c\n", "<ul>\n<li>a</li>\n<li>\n<pre><code>b\n\n\n</code></pre>\n</li>\n<li>c</li>\n</ul>\n")
+	l.Add("- a\n  > b\n  ```\n  c\n  ```\n- d\n", "<ul>\n<li>a\n<blockquote>\n<p>b</p>\n</blockquote>\n<pre><code>c\n</code></pre>\n</li>\n<li>d</li>\n</ul>\n")
+	l.Add("- a\n  - b\n  - c\n\n- d\n  - e\n  - f\n", "<ul>\n<li>\n<p>a</p>\n<ul>\n<li>b</li>\n<li>c</li>\n</ul>\n</li>\n<li>\n<p>d</p>\n<ul>\n<li>e</li>\n<li>f</li>\n</ul>\n</li>\n</ul>\n")
+	l.Add("foo\\\nbar\n", "<p>foo<br />\nbar</p>\n")
+	l.Add("[foo](/bar\\* \"ti\\*tle\")\n", "<p><a href=\"/bar*\" title=\"ti*tle\">foo</a></p>\n")
+	l.Add("&#35; &#1234; &#992; &#0;\n", "<p># Ӓ Ϡ �</p>\n")
+	l.Add("&copy\n", "<p>&amp;copy</p>\n")
+	l.Add("``` f&ouml;&ouml;\nfoo\n```\n", "<pre><code class=\"language-föö\">foo\n</code></pre>\n")
+	l.Add("&#9;foo\n", "<p>\tfoo</p>\n")
+	l.Add("` `` `\n", "<p><code>``</code></p>\n")
+	l.Add("` b `\n", "<p><code>b</code></p>\n")
+	l.Add("`foo   bar \nbaz`\n", "<p><code>foo   bar  baz</code></p>\n")
+	l.Add("[not a `link](/foo`)\n", "<p>[not a <code>link](/foo</code>)</p>\n")
+	l.Add("```foo``\n", "<p>```foo``</p>\n")
+	l.Add("*foo bar*\n", "<p><em>foo bar</em></p>\n")
+	l.Add("* a *\n", "<ul>\n<li>a *</li>\n</ul>\n")
+	l.Add("_foo bar_\n", "<p><em>foo bar</em></p>\n")
+	l.Add("foo_bar_\n", "<p>foo_bar_</p>\n")
+	l.Add("foo-_(bar)_\n", "<p>foo-<em>(bar)</em></p>\n")
+	l.Add("*foo bar\n*\n", "<p>*foo bar\n*</p>\n")
+	l.Add("*foo*bar\n", "<p><em>foo</em>bar</p>\n")
+	l.Add("_(_foo_)_\n", "<p><em>(<em>foo</em>)</em></p>\n")
+	l.Add("_foo_bar_baz_\n", "<p><em>foo_bar_baz</em></p>\n")
+	l.Add("** foo bar**\n", "<p>** foo bar**</p>\n")
+	l.Add("__foo bar__\n", "<p><strong>foo bar</strong></p>\n")
+	l.Add("a__\"foo\"__\n", "<p>a__&quot;foo&quot;__</p>\n")
+	l.Add("пристаням__стремятся__\n", "<p>пристаням__стремятся__</p>\n")
+	l.Add("**foo bar **\n", "<p>**foo bar **</p>\n")
+	l.Add("**Gomphocarpus (*Gomphocarpus physocarpus*, syn.\n*Asclepias physocarpa*)**\n", "<p><strong>Gomphocarpus (<em>Gomphocarpus physocarpus</em>, syn.\n<em>Asclepias physocarpa</em>)</strong></p>\n")
+	l.Add("__foo bar __\n", "<p>__foo bar __</p>\n")
+	l.Add("__foo__bar\n", "<p>__foo__bar</p>\n")
+	l.Add("__(bar)__.\n", "<p><strong>(bar)</strong>.</p>\n")
+	l.Add("_foo __bar__ baz_\n", "<p><em>foo <strong>bar</strong> baz</em></p>\n")
+	l.Add("*foo *bar**\n", "<p><em>foo <em>bar</em></em></p>\n")
+	l.Add("*foo**bar*\n", "<p><em>foo**bar</em></p>\n")
+	l.Add("*foo**bar***\n", "<p><em>foo<strong>bar</strong></em></p>\n")
+	l.Add("*foo **bar *baz* bim** bop*\n", "<p><em>foo <strong>bar <em>baz</em> bim</strong> bop</em></p>\n")
+	l.Add("**** is not an empty strong emphasis\n", "<p>**** is not an empty strong emphasis</p>\n")
+	l.Add("__foo _bar_ baz__\n", "<p><strong>foo <em>bar</em> baz</strong></p>\n")
+	l.Add("**foo **bar****\n", "<p><strong>foo <strong>bar</strong></strong></p>\n")
+	l.Add("***foo* bar**\n", "<p><strong><em>foo</em> bar</strong></p>\n")
+	l.Add("**foo [*bar*](/url)**\n", "<p><strong>foo <a href=\"/url\"><em>bar</em></a></strong></p>\n")
+	l.Add("foo ***\n", "<p>foo ***</p>\n")
+	l.Add("foo *****\n", "<p>foo *****</p>\n")
+	l.Add("**foo*\n", "<p>*<em>foo</em></p>\n")
+	l.Add("****foo*\n", "<p>***<em>foo</em></p>\n")
+	l.Add("foo ___\n", "<p>foo ___</p>\n")
+	l.Add("foo _____\n", "<p>foo _____</p>\n")
+	l.Add("__foo_\n", "<p>_<em>foo</em></p>\n")
+	l.Add("____foo_\n", "<p>___<em>foo</em></p>\n")
+	l.Add("**foo**\n", "<p><strong>foo</strong></p>\n")
+	l.Add("_*foo*_\n", "<p><em><em>foo</em></em></p>\n")
+	l.Add("******foo******\n", "<p><strong><strong><strong>foo</strong></strong></strong></p>\n")
+	l.Add("*foo _bar* baz_\n", "<p><em>foo _bar</em> baz_</p>\n")
+	l.Add("*foo *bar baz*\n", "<p>*foo <em>bar baz</em></p>\n")
+	l.Add("*<img src=\"foo\" title=\"*\"/>\n", "<p>*<img src=\"foo\" title=\"*\"/></p>\n")
+	l.Add("*a `*`*\n", "<p><em>a <code>*</code></em></p>\n")
+	l.Add("__a<http://foo.bar/?q=__>\n", "<p>__a<a href=\"http://foo.bar/?q=__\">http://foo.bar/?q=__</a></p>\n")
+	l.Add("[](./target.md)\n", "<p><a href=\"./target.md\"></a></p>\n")
+	l.Add("[]()\n", "<p><a href=\"\"></a></p>\n")
+	l.Add("[link](foo\nbar)\n", "<p>[link](foo\nbar)</p>\n")
+	l.Add("[link](<foo\\>)\n", "<p>[link](&lt;foo&gt;)</p>\n")
+	l.Add("[link](foo(and(bar)))\n", "<p><a href=\"foo(and(bar))\">link</a></p>\n")
+	l.Add("[link](<foo(and(bar)>)\n", "<p><a href=\"foo(and(bar)\">link</a></p>\n")
+	l.Add("[link](foo\\bar)\n", "<p><a href=\"foo%5Cbar\">link</a></p>\n")
+	l.Add("[link](/url \"title\")\n[link](/url 'title')\n[link](/url (title))\n", "<p><a href=\"/url\" title=\"title\">link</a>\n<a href=\"/url\" title=\"title\">link</a>\n<a href=\"/url\" title=\"title\">link</a></p>\n")
+	l.Add("[link](/url \"title \"and\" title\")\n", "<p>[link](/url &quot;title &quot;and&quot; title&quot;)</p>\n")
+	l.Add("[link] (/uri)\n", "<p>[link] (/uri)</p>\n")
+	l.Add("[link [bar](/uri)\n", "<p>[link <a href=\"/uri\">bar</a></p>\n")
+	l.Add("[![moon](moon.jpg)](/uri)\n", "<p><a href=\"/uri\"><img src=\"moon.jpg\" alt=\"moon\" /></a></p>\n")
+	l.Add("![[[foo](uri1)](uri2)](uri3)\n", "<p><img src=\"uri3\" alt=\"[foo](uri2)\" /></p>\n")
+	l.Add("*foo [bar* baz]\n", "<p><em>foo [bar</em> baz]</p>\n")
+	l.Add("[foo<http://example.com/?search=](uri)>\n", "<p>[foo<a href=\"http://example.com/?search=%5D(uri)\">http://example.com/?search=](uri)</a></p>\n")
+	l.Add("[link \\[bar][ref]\n\n[ref]: /uri\n", "<p><a href=\"/uri\">link [bar</a></p>\n")
+	l.Add("*[foo*][ref]\n\n[ref]: /uri\n", "<p>*<a href=\"/uri\">foo*</a></p>\n")
+	l.Add("[Foo\n  bar]: /url\n\n[Baz][Foo bar]\n", "<p><a href=\"/url\">Baz</a></p>\n")
+	l.Add("[bar][foo\\!]\n\n[foo!]: /url\n", "<p>[bar][foo!]</p>\n")
+	l.Add("[]\n\n[]: /uri\n", "<p>[]</p>\n<p>[]: /uri</p>\n")
+	l.Add("[foo] \n[]\n\n[foo]: /url \"title\"\n", "<p><a href=\"/url\" title=\"title\">foo</a>\n[]</p>\n")
+	l.Add("[[bar [foo]\n\n[foo]: /url\n", "<p>[[bar <a href=\"/url\">foo</a></p>\n")
+	l.Add("[foo][bar]\n\n[foo]: /url1\n[bar]: /url2\n", "<p><a href=\"/url2\">foo</a></p>\n")
+	l.Add("[foo][bar][baz]\n\n[baz]: /url1\n[foo]: /url2\n", "<p>[foo]<a href=\"/url1\">bar</a></p>\n")
+	l.Add("![foo ![bar](/url)](/url2)\n", "<p><img src=\"/url2\" alt=\"foo bar\" /></p>\n")
+	l.Add("My ![foo bar](/path/to/train.jpg  \"title\"   )\n", "<p>My <img src=\"/path/to/train.jpg\" alt=\"foo bar\" title=\"title\" /></p>\n")
+	l.Add("![foo][]\n\n[foo]: /url \"title\"\n", "<p><img src=\"/url\" alt=\"foo\" title=\"title\" /></p>\n")
+	l.Add("\\![foo]\n\n[foo]: /url \"title\"\n", "<p>!<a href=\"/url\" title=\"title\">foo</a></p>\n")
+	l.Add("<irc://foo.bar:2233/baz>\n", "<p><a href=\"irc://foo.bar:2233/baz\">irc://foo.bar:2233/baz</a></p>\n")
+	l.Add("<made-up-scheme://foo,bar>\n", "<p><a href=\"made-up-scheme://foo,bar\">made-up-scheme://foo,bar</a></p>\n")
+	l.Add("<http://foo.bar/baz bim>\n", "<p>&lt;http://foo.bar/baz bim&gt;</p>\n")
+	l.Add("<foo+special@Bar.baz-bar0.com>\n", "<p><a href=\"mailto:foo+special@Bar.baz-bar0.com\">foo+special@Bar.baz-bar0.com</a></p>\n")
+	l.Add("< http://foo.bar >\n", "<p>&lt; http://foo.bar &gt;</p>\n")
+	l.Add("http://example.com\n", "<p>http://example.com</p>\n")
+	l.Add("<a/><b2/>\n", "<p><a/><b2/></p>\n")
+	l.Add("Foo <responsive-image src=\"foo.jpg\" />\n", "<p>Foo <responsive-image src=\"foo.jpg\" /></p>\n")
+	l.Add("<a href=\"hi'> <a href=hi'>\n", "<p>&lt;a href=&quot;hi'&gt; &lt;a href=hi'&gt;</p>\n")
+	l.Add("</a></foo >\n", "<p></a></foo ></p>\n")
+	l.Add("foo <!-- not a comment -- two hyphens -->\n", "<p>foo &lt;!-- not a comment -- two hyphens --&gt;</p>\n")
+	l.Add("foo <!ELEMENT br EMPTY>\n", "<p>foo <!ELEMENT br EMPTY></p>\n")
+	l.Add("foo <a href=\"\\*\">\n", "<p>foo <a href=\"\\*\"></p>\n")
+	l.Add("foo\\\nbaz\n", "<p>foo<br />\nbaz</p>\n")
+	l.Add("foo\\\n     bar\n", "<p>foo<br />\nbar</p>\n")
+	l.Add("`code  \nspan`\n", "<p><code>code   span</code></p>\n")
+	l.Add("<a href=\"foo\\\nbar\">\n", "<p><a href=\"foo\\\nbar\"></p>\n")
+	l.Add("### foo\\\n", "<h3>foo\\</h3>\n")
+	l.Add("foo \n baz\n", "<p>foo\nbaz</p>\n")
+	l.Add("Multiple     spaces\n", "<p>Multiple     spaces</p>\n")
+
+	for _, item := range l.Items {
+		if res := e.MarkdownRender(item.Msg, &e.MarkdownOptions{HTML: true}); res != item.Expects {
 			t.Error("Testing string '" + item.Msg + "'")
 			t.Error("Bad output:", "'"+res+"'")
-			//t.Error("Ouput in bytes:", []byte(res))
 			t.Error("Expected:", "'"+item.Expects+"'")
 		}
 	}
+}
 
-	/*for _, item := range l.Items {
-		if res := e.MarkdownParse("d" + item.Msg); res != "d"+item.Expects {
-			t.Error("Testing string 'd" + item.Msg + "'")
+func TestMarkdownGFM(t *testing.T) {
+	// Tables, task lists and strikethrough, along with the unsafe URLs and raw HTML which have to be defanged
+	l := &MEPairList{nil}
+	l.Add("| foo | bar |\n| --- | --- |\n| baz | bim |\n", "<table>\n<thead>\n<tr>\n<th>foo</th>\n<th>bar</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>baz</td>\n<td>bim</td>\n</tr>\n</tbody>\n</table>\n")
+	l.Add("| abc | defghi |\n:-: | -----------:\nbar | baz\n", "<table>\n<thead>\n<tr>\n<th align=\"center\">abc</th>\n<th align=\"right\">defghi</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td align=\"center\">bar</td>\n<td align=\"right\">baz</td>\n</tr>\n</tbody>\n</table>\n")
+	l.Add("| f\\|oo  |\n| ------ |\n| b `\\|` az |\n| b **\\|** im |\n", "<table>\n<thead>\n<tr>\n<th>f|oo</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>b <code>|</code> az</td>\n</tr>\n<tr>\n<td>b <strong>|</strong> im</td>\n</tr>\n</tbody>\n</table>\n")
+	l.Add("| abc | def |\n| --- | --- |\n| bar | baz |\n> bar\n", "<table>\n<thead>\n<tr>\n<th>abc</th>\n<th>def</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>bar</td>\n<td>baz</td>\n</tr>\n</tbody>\n</table>\n<blockquote>\n<p>bar</p>\n</blockquote>\n")
+	l.Add("| abc | def |\n| --- | --- |\n| bar | baz |\nbar\n\nbar\n", "<table>\n<thead>\n<tr>\n<th>abc</th>\n<th>def</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>bar</td>\n<td>baz</td>\n</tr>\n<tr>\n<td>bar</td>\n<td></td>\n</tr>\n</tbody>\n</table>\n<p>bar</p>\n")
+	l.Add("| abc | def |\n| --- |\n| bar |\n", "<p>| abc | def |\n| --- |\n| bar |</p>\n")
+	l.Add("| abc | def |\n| --- | --- |\n| bar |\n| bar | baz | boo |\n", "<table>\n<thead>\n<tr>\n<th>abc</th>\n<th>def</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>bar</td>\n<td></td>\n</tr>\n<tr>\n<td>bar</td>\n<td>baz</td>\n</tr>\n</tbody>\n</table>\n")
+	l.Add("| abc | def |\n| --- | --- |\n", "<table>\n<thead>\n<tr>\n<th>abc</th>\n<th>def</th>\n</tr>\n</thead>\n</table>\n")
+	l.Add("para\n| a | b |\n|---|---|\n| 1 | 2 |\n", "<p>para</p>\n<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>1</td>\n<td>2</td>\n</tr>\n</tbody>\n</table>\n")
+	l.Add("- | a |\n  |---|\n  | b |\n", "<ul>\n<li>\n<table>\n<thead>\n<tr>\n<th>a</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>b</td>\n</tr>\n</tbody>\n</table>\n</li>\n</ul>\n")
+	l.Add("a | b\n-- | --\n", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th>b</th>\n</tr>\n</thead>\n</table>\n")
+	l.Add("- [ ] foo\n- [x] bar\n", "<ul>\n<li><input disabled=\"\" type=\"checkbox\"> foo</li>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> bar</li>\n</ul>\n")
+	l.Add("- [x] foo\n  - [ ] bar\n  - [x] baz\n- [ ] bim\n", "<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> foo\n<ul>\n<li><input disabled=\"\" type=\"checkbox\"> bar</li>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> baz</li>\n</ul>\n</li>\n<li><input disabled=\"\" type=\"checkbox\"> bim</li>\n</ul>\n")
+	l.Add("1. [X] loose\n\n2. [ ] two\n", "<ol>\n<li>\n<p><input checked=\"\" disabled=\"\" type=\"checkbox\"> loose</p>\n</li>\n<li>\n<p><input disabled=\"\" type=\"checkbox\"> two</p>\n</li>\n</ol>\n")
+	l.Add("~~Hi~~ Hello, ~there~ world!\n", "<p><del>Hi</del> Hello, <del>there</del> world!</p>\n")
+	l.Add("This ~~has a\n\nnew paragraph~~.\n", "<p>This ~~has a</p>\n<p>new paragraph~~.</p>\n")
+	l.Add("This will ~~~not~~~ strike.\n", "<p>This will ~~~not~~~ strike.</p>\n")
+	l.Add("~~a~ b~\n", "<p>~~a~ b~</p>\n")
+	l.Add("[a](javascript:alert(1)) ![b](data:image/png;base64,xx) [c](data:text/html,x) <javascript:alert(1)>\n", "<p><a href=\"\">a</a> <img src=\"data:image/png;base64,xx\" alt=\"b\" /> <a href=\"\">c</a> <a href=\"\">javascript:alert(1)</a></p>\n")
+	l.Add("<script>alert(1)</script>\n\n<b onclick=x>hi</b>\n", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n<p>&lt;b onclick=x&gt;hi&lt;/b&gt;</p>\n")
+	l.Add("<https://example.com/a?b=c&d>, <me@example.com>\n", "<p><a href=\"https://example.com/a?b=c&amp;d\">https://example.com/a?b=c&amp;d</a>, <a href=\"mailto:me@example.com\">me@example.com</a></p>\n")
+
+	for _, item := range l.Items {
+		if res := e.MarkdownRender(item.Msg, nil); res != item.Expects {
+			t.Error("Testing string '" + item.Msg + "'")
 			t.Error("Bad output:", "'"+res+"'")
-			//t.Error("Ouput in bytes:", []byte(res))
-			t.Error("Expected:", "'d"+item.Expects+"'")
+			t.Error("Expected:", "'"+item.Expects+"'")
 		}
-	}*/
+	}
+
+	l = &MEPairList{nil}
+	l.Add("www.commonmark.org\n", "<p><a href=\"http://www.commonmark.org\">www.commonmark.org</a></p>\n")
+	l.Add("Visit www.commonmark.org/help for more information.\n", "<p>Visit <a href=\"http://www.commonmark.org/help\">www.commonmark.org/help</a> for more information.</p>\n")
+	l.Add("Visit www.commonmark.org.\n\nVisit www.commonmark.org/a.b.\n", "<p>Visit <a href=\"http://www.commonmark.org\">www.commonmark.org</a>.</p>\n<p>Visit <a href=\"http://www.commonmark.org/a.b\">www.commonmark.org/a.b</a>.</p>\n")
+	l.Add("www.google.com/search?q=Markup+(business)\n\nwww.google.com/search?q=Markup+(business)))\n\n(www.google.com/search?q=Markup+(business))\n\n(www.google.com/search?q=Markup+(business)\n", "<p><a href=\"http://www.google.com/search?q=Markup+(business)\">www.google.com/search?q=Markup+(business)</a></p>\n<p><a href=\"http://www.google.com/search?q=Markup+(business)\">www.google.com/search?q=Markup+(business)</a>))</p>\n<p>(<a href=\"http://www.google.com/search?q=Markup+(business)\">www.google.com/search?q=Markup+(business)</a>)</p>\n<p>(<a href=\"http://www.google.com/search?q=Markup+(business)\">www.google.com/search?q=Markup+(business)</a></p>\n")
+	l.Add("www.google.com/search?q=(business))+ok\n", "<p><a href=\"http://www.google.com/search?q=(business))+ok\">www.google.com/search?q=(business))+ok</a></p>\n")
+	l.Add("www.google.com/search?q=commonmark&hl=en\n\nwww.google.com/search?q=commonmark&hl;\n", "<p><a href=\"http://www.google.com/search?q=commonmark&amp;hl=en\">www.google.com/search?q=commonmark&amp;hl=en</a></p>\n<p><a href=\"http://www.google.com/search?q=commonmark\">www.google.com/search?q=commonmark</a>&amp;hl;</p>\n")
+	l.Add("www.commonmark.org/he<lp\n", "<p><a href=\"http://www.commonmark.org/he\">www.commonmark.org/he</a>&lt;lp</p>\n")
+	l.Add("http://commonmark.org\n\n(Visit https://encrypted.google.com/search?q=Markup+(business))\n", "<p><a href=\"http://commonmark.org\">http://commonmark.org</a></p>\n<p>(Visit <a href=\"https://encrypted.google.com/search?q=Markup+(business)\">https://encrypted.google.com/search?q=Markup+(business)</a>)</p>\n")
+	l.Add("foo@bar.baz\n", "<p><a href=\"mailto:foo@bar.baz\">foo@bar.baz</a></p>\n")
+	l.Add("hello@mail+xyz.example isn't valid, but hello+xyz@mail.example is.\n", "<p>hello@mail+xyz.example isn't valid, but <a href=\"mailto:hello+xyz@mail.example\">hello+xyz@mail.example</a> is.</p>\n")
+	l.Add("a.b-c_d@a.b\n\na.b-c_d@a.b.\n\na.b-c_d@a.b-\n\na.b-c_d@a.b_\n", "<p><a href=\"mailto:a.b-c_d@a.b\">a.b-c_d@a.b</a></p>\n<p><a href=\"mailto:a.b-c_d@a.b\">a.b-c_d@a.b</a>.</p>\n<p>a.b-c_d@a.b-</p>\n<p>a.b-c_d@a.b_</p>\n")
+	l.Add("www.a_b.c_d.com www.x_y.com\n", "<p>www.a_b.c_d.com www.x_y.com</p>\n")
+	l.Add("*www.a.com* _http://x.org/a_b_c_ [www.a.com](/b) `www.a.com` xwww.a.com\n", "<p><em><a href=\"http://www.a.com\">www.a.com</a></em> <em><a href=\"http://x.org/a_b_c\">http://x.org/a_b_c</a></em> <a href=\"/b\">www.a.com</a> <code>www.a.com</code> xwww.a.com</p>\n")
+	l.Add("see http://a.com/x*y*z and me@x.io.\n", "<p>see <a href=\"http://a.com/x\">http://a.com/x</a><em>y</em>z and <a href=\"mailto:me@x.io\">me@x.io</a>.</p>\n")
+	l.Add("www.\n\nwww..\n\nhttp://\n\nhttp://.\n", "<p>www.</p>\n<p>www..</p>\n<p>http://</p>\n<p>http://.</p>\n")
+	l.Add("a @ b @x.y foo@bar x/y@z.com\n", "<p>a @ b @x.y foo@bar x/y@z.com</p>\n")
 
-	// TODO: Write suffix tests and double string tests
-	// TODO: Write similar prefix, suffix, and double string tests for plugin_bbcode. Ditto for the outer parser along with suitable tests for that like making sure the URL parser and media embedder works.
+	for _, item := range l.Items {
+		if res := e.MarkdownRender(item.Msg, &e.MarkdownOptions{Autolink: true}); res != item.Expects {
+			t.Error("Testing string '" + item.Msg + "'")
+			t.Error("Bad output:", "'"+res+"'")
+			t.Error("Expected:", "'"+item.Expects+"'")
+		}
+	}
+}
+
+func TestMarkdownParser(t *testing.T) {
+	miscinit(t)
+	if !c.PluginsInited {
+		c.InitPlugins()
+	}
+	pl := c.Plugins["markdown"]
+	if err := e.InitMarkdown(pl); err != nil {
+		t.Fatal(err)
+	}
+	defer pl.Deactivate(pl)
+
+	c.AddHashLinkType("mdtest-", func(sb *strings.Builder, msg string, i *int) {
+		tid, intLen := c.CoerceIntString(msg[*i:])
+		*i += intLen
+		c.WriteURL(sb, c.BuildTopicURL("", tid), "#mdtest-"+strconv.Itoa(tid))
+	})
+
+	// The whole way through, from what someone typed to what gets shown
+	l := &MEPairList{nil}
+	l.Add("**hi** @Admin", "<p><strong>hi</strong> <a href='/user/admin.1'class='mention'>@Admin</a></p>")
+	l.Add("*hi* #tid-1", "<p><em>hi</em> <a href='/topic/1'>#tid-1</a></p>")
+	l.Add("see #mdtest-5", "<p>see <a href='/topic/5'>#mdtest-5</a></p>")
+	l.Add("[#tid-1](/topic/2)", "<p><a href=\"/topic/2\" rel=\"ugc\">#tid-1</a></p>")
+	l.Add("`@Admin`", "<p><code>@Admin</code></p>")
+	l.Add("<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>")
+	l.Add("[hi](javascript:alert(1))", "<p><a href=\"\" rel=\"ugc\">hi</a></p>")
+	l.Add("a \\*b\\*", "<p>a *b*</p>")
+	l.Add("# hi\n- [x] @Admin\n- [ ] #tid-1", "<h2>hi</h2>\n<ul>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> <a href='/user/admin.1'class='mention'>@Admin</a></li>\n<li><input disabled=\"\" type=\"checkbox\"> <a href='/topic/1'>#tid-1</a></li>\n</ul>")
+	l.Add("| a | b |\n|---|--:|\n| #tid-1 | <b>2</b> |", "<table>\n<thead>\n<tr>\n<th>a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td><a href='/topic/1'>#tid-1</a></td>\n<td align=\"right\"><strong>2</strong></td>\n</tr>\n</tbody>\n</table>")
+	l.Add("```\n<b>@Admin</b>\n```", "<pre><code>@Admin\n</code></pre>")
+	l.Add("<blockquote>quoted</blockquote>reply", "<blockquote>\n<p>quoted</p>\n</blockquote>\n<p>reply</p>")
+
+	for _, item := range l.Items {
+		if res := c.ParseMessage(c.PreparseMessage(item.Msg), 1, "forums", nil, nil); res != item.Expects {
+			t.Error("Testing string '" + item.Msg + "'")
+			t.Error("Bad output:", "'"+res+"'")
+			t.Error("Expected:", "'"+item.Expects+"'")
+		}
+	}
+}
+
+var markdownFuzzPieces = []string{"*", "**", "_", "~~", "`", "```", "    ", "# ", "> ", "- ", "1. ", "- [ ] ", "- [x] ", "---", "|", "| --- |", ":-:", "[", "]", "(", ")", "![", "[hi]: /url", "<", ">", "&amp;", "&", "\\", "\n", "\n\n", "\t", "https://example.com/", "www.example.com", "a@example.com", "javascript:alert(1)", "<b>", "</b>", "<script>", "hi", " ", "😀", "你好"}
+
+func FuzzMarkdown(f *testing.F) {
+	for _, piece := range markdownFuzzPieces {
+		f.Add(piece + "hi" + piece)
+	}
+	f.Add("| a | b |\n|---|:-:|\n| 1 | 2 |")
+	f.Add("- [x] done\n  - [ ] nested\n\n> quote *em* **strong**")
+	f.Add("[link](/url \"title\") ![img](/a.png) <https://example.com>")
+	f.Fuzz(func(t *testing.T, msg string) {
+		// Without raw HTML, nothing in msg should be able to turn into a tag or an unsafe link
+		out := strings.ToLower(e.MarkdownRender(msg, &e.MarkdownOptions{Autolink: true}))
+		if strings.Contains(out, "<script") || strings.Contains(out, "href=\"javascript:") || strings.Contains(out, "src=\"javascript:") {
+			t.Error("Unsafe output:", "'"+out+"'")
+		}
+		_ = e.MarkdownParse(msg)
+	})
 }
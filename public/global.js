@@ -958,13 +958,93 @@ function bindPage() {
 	});
 	
 	bindTopic();
+	bindTagSuggestions();
+	bindDrafts();
+	bindPublishAt();
 	runInitHook("end_bind_page")
 }
 
+// Save what people are writing every few seconds, so an expired session or a crash doesn't take it with it
+function bindDrafts() {
+	if(!me.User || me.User.ID==0) return;
+	$("form[data-draft]").each(function(){
+		let form = this;
+		let els = form.elements;
+		let content = els["content"];
+		if(!content) return;
+		let typ = form.getAttribute("data-draft");
+		// The forum can be changed on the create topic page, so new topics follow the board input
+		let id = () => els["board"] ? els["board"].value : form.getAttribute("data-draft-id");
+
+		fetch("/api/draft/?type="+typ+"&id="+id(),{credentials:"same-origin"})
+			.then(resp => resp.json())
+			.then(dat => {
+				if(!dat.UpdatedAt) return;
+				if(content.value=="") content.value = dat.Content;
+				if(els["name"] && els["name"].value=="") els["name"].value = dat.Title;
+			}).catch(e => log("Unable to load the draft", e));
+
+		let timer = 0;
+		let save = () => {
+			timer = 0;
+			let data = { type: typ, id: id(), content: content.value, js: 1 };
+			if(els["name"]) data.name = els["name"].value;
+			$.ajax({ url: "/user/drafts/save/submit/?s="+me.User.S, type:"POST", dataType:"json", data: data, error: (xhr,status,e) => log("Unable to save the draft", e) });
+		};
+		$(content).add(els["name"]).on("input.draft", () => {
+			if(timer==0) timer = setTimeout(save, 5000);
+		});
+		// The draft is deleted once the post goes through, so don't bring it back
+		$(form).on("submit.draft", () => clearTimeout(timer));
+	});
+}
+
+// The publish time is in the browser's local time, so tell the server how far that is from UTC on the day it's for
+function bindPublishAt() {
+	$(".publish_at_input").each(function(){
+		let input = this;
+		if(!input.form) return;
+		$(input.form).on("submit.publish", () => {
+			let offset = input.form.elements["publish_offset"];
+			if(offset && input.value!="") offset.value = new Date(input.value).getTimezoneOffset();
+		});
+	});
+}
+
+// Autocomplete the last tag in a comma separated tag list
+function bindTagSuggestions() {
+	$(".tags_input").on("input", function() {
+		let list = document.getElementById(this.getAttribute("list"));
+		if(!list) return;
+		let parts = this.value.split(",");
+		let last = parts.pop().trim();
+		if(last.length < 2) return;
+		let before = parts.map(p => p.trim()).filter(p => p != "");
+		fetch("/api/tags/?q="+encodeURIComponent(last),{credentials:"same-origin"})
+			.then(resp => resp.json())
+			.then(dat => {
+				list.innerHTML = "";
+				for(const tag of (dat.Tags || [])) {
+					let opt = document.createElement("option");
+					opt.value = before.concat([tag]).join(", ");
+					list.appendChild(opt);
+				}
+			}).catch(e => log("Unable to load the tag suggestions", e));
+	});
+}
+
 function unbindPage() {
 	log("enter unbindPage");
 	$(".create_topic_link").unbind("click");
 	$(".topic_create_form .close_form").unbind("click");
+	$(".tags_input").unbind("input");
+	$("form[data-draft]").each(function(){
+		$(this).off(".draft");
+		$(this.elements).off(".draft");
+	});
+	$(".publish_at_input").each(function(){
+		if(this.form) $(this.form).off(".publish");
+	});
 	unbindTopic();
 	runHook("end_unbind_page")
 }
@@ -992,16 +1072,23 @@ function bindTopic() {
 		$('.show_on_edit').removeClass("edit_opened");
 		runHook("close_edit");
 
+		let data = {
+			name: nameInput,
+			status: statusInput,
+			content: contentInput,
+			js: 1
+		};
+		// Only send the prefix and tags, if the page gave us a way to change them
+		let prefixInput = $(".topic_prefix_input");
+		if(prefixInput.length > 0) data.prefix = prefixInput.val();
+		let tagsInput = $(".topic_tags_input");
+		if(tagsInput.length > 0) data.tags = tagsInput.val();
+
 		$.ajax({
 			url: this.form.getAttribute("action"),
 			type:"POST",
 			dataType:"json",
-			data: {
-				name: nameInput,
-				status: statusInput,
-				content: contentInput,
-				js: 1
-			},
+			data: data,
 			error: ajaxError,
 			success: (dat,status,xhr) => {
 				if("Content" in dat) $(".topic_content").html(dat["Content"]);
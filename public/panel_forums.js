@@ -3,26 +3,17 @@ addInitHook("end_init", () => {
 
 formVars = {
 	'forum_active': ['Hide','Show'],
-	'forum_preset': ['all','announce','members','staff','admins','archive','custom']
+	'forum_preset': ['all','announce','members','staff','admins','archive','inherit','custom']
 };
-var forums = {};
-let items = document.getElementsByClassName("panel_forum_item");
-for(let i=0; item=items[i]; i++) forums[i] = item.getAttribute("data-fid");
-log("forums",forums);
-
-Sortable.create(document.getElementById("panel_forums"), {
-	sort: true,
-	onEnd: (evt) => {
-		log("pre forums",forums)
-		log("evt",evt)
-		let oldFid = forums[evt.newIndex];
-		forums[evt.oldIndex] = oldFid;
-		let newFid = evt.item.getAttribute("data-fid");
-		log("newFid",newFid);
-		forums[evt.newIndex] = newFid;
-		log("post forums",forums);
-	}
-});
+// Every level of the tree gets a list of its own, they share a group so the forums can be dragged from one level to another
+let lists = document.getElementsByClassName("panel_forum_list");
+for(let i=0; list=lists[i]; i++) {
+	Sortable.create(list, {
+		group: "panel_forums",
+		sort: true,
+		onEnd: (evt) => log("evt",evt)
+	});
+}
 
 document.getElementById("panel_forums_order_button").addEventListener("click", () => {
 	let req = new XMLHttpRequest();
@@ -49,10 +40,12 @@ document.getElementById("panel_forums_order_button").addEventListener("click", (
 	// ? - Is encodeURIComponent the right function for this?
 	req.open("POST","/panel/forums/order/edit/submit/?s=" + encodeURIComponent(me.User.S));
 	req.setRequestHeader('Content-Type', 'application/x-www-form-urlencoded');
+	// fid:parent for each forum in the order they're shown
 	let items = "";
-	for(let i=0;item=forums[i];i++) items += item+",";
+	let nodes = document.getElementsByClassName("panel_forum_node");
+	for(let i=0; node=nodes[i]; i++) items += node.getAttribute("data-fid")+":"+node.parentNode.getAttribute("data-parent")+",";
 	if(items.length > 0) items = items.slice(0,-1);
-	req.send("js=1&amp;items={"+items+"}");
+	req.send("js=1&items={"+items+"}");
 });
 
 });
@@ -0,0 +1,42 @@
+(() => {
+	addInitHook("end_init", () => {
+
+// Swaps the stylesheet being edited in the preview frame for the one in the editor, without saving it
+let button = document.getElementById("panel_theme_preview_button");
+let frame = document.getElementById("panel_theme_preview");
+if(!button || !frame) return;
+button.addEventListener("click", () => {
+	let theme = button.getAttribute("data-theme");
+	let file = frame.getAttribute("data-file");
+	let req = new XMLHttpRequest();
+	req.onreadystatechange = () => {
+		if(req.readyState!==XMLHttpRequest.DONE) return;
+		if(req.status!==200) {
+			try {
+				pushNotice(JSON.parse(req.responseText).errmsg);
+			} catch(e) {
+				console.error("e",e);
+			}
+			return;
+		}
+		let doc = frame.contentDocument;
+		if(!doc) return;
+		let path = "/s/"+theme+"/"+file.replace(/^public\//,"");
+		let old = doc.querySelector("style[data-preview]");
+		if(old) old.remove();
+		let links = doc.querySelectorAll("link[rel='stylesheet']");
+		for(let i=0; i<links.length; i++) {
+			if(new URL(links[i].href).pathname===path) links[i].disabled = true;
+		}
+		let style = doc.createElement("style");
+		style.setAttribute("data-preview","1");
+		style.textContent = req.responseText;
+		doc.head.appendChild(style);
+	};
+	req.open("POST","/panel/themes/file/preview/submit/"+theme+"?s="+encodeURIComponent(me.User.S));
+	req.setRequestHeader("Content-Type","application/x-www-form-urlencoded");
+	req.send("f="+encodeURIComponent(file)+"&content="+encodeURIComponent(document.getElementById("panel_theme_file_content").value));
+});
+
+});
+})()
@@ -32,6 +32,12 @@ func (b *accDeleteBuilder) DateOlderThan(col string, quantity int, unit string)
 	return b
 }
 
+// DateOlderThanQ is DateOlderThan with the quantity passed in as a parameter when the statement is run
+func (b *accDeleteBuilder) DateOlderThanQ(col, unit string) *accDeleteBuilder {
+	b.dateCutoff = &dateCutoff{col, 0, unit, 11}
+	return b
+}
+
 /*func (b *accDeleteBuilder) Prepare() *sql.Stmt {
 	return b.build.SimpleDelete(b.table, b.where)
 }*/
@@ -283,7 +289,7 @@ func (b *AccSelectBuilder) EachP(h func(*sql.Rows) error, p ...interface{}) erro
 	if e != nil {
 		return e
 	}
-	rows, e := b.build.query(query, p)
+	rows, e := b.build.query(query, p...)
 	if e != nil {
 		return e
 	}
@@ -84,6 +84,16 @@ func (b *builder) prepare(res string, err error) (*sql.Stmt, error) {
 	return b.conn.Prepare(res)
 }
 
+// ExecMulti runs q without preparing it, so that it can hold several statements, such as ChangeColumn renaming a column on PostgreSQL.
+// Parameters can only be bound to a single statement, so it can't have any.
+func (b *builder) ExecMulti(q string, err error) error {
+	if err != nil {
+		return err
+	}
+	_, err = b.conn.Exec(q)
+	return err
+}
+
 func (b *builder) SimpleSelect(table, columns, where, orderby, limit string) (stmt *sql.Stmt, err error) {
 	return b.prepare(b.adapter.SimpleSelect("", table, columns, where, orderby, limit))
 }
@@ -1276,7 +1276,7 @@ func (a *MysqlAdapter) Write() error {
 	}
 
 	// TODO: Move these custom queries out of this file
-	out := `// +build !pgsql,!mssql
+	out := `// +build !pgsql,!mssql,!sqlite
 
 /* This file was generated by Gosora's Query Generator. Please try to avoid modifying this file, as it might change at any time. */
 
@@ -1,4 +1,10 @@
-/* WIP Under Really Heavy Construction */
+/*
+*
+*	Query Generator Library
+*	PostgreSQL Adapter
+*	Copyright Azareal 2017 - 2020
+*
+ */
 package qgen
 
 import (
@@ -14,6 +20,7 @@ func init() {
 	)
 }
 
+// PostgreSQL folds unquoted identifiers to lower-case, so we quote everything in lower-case to make sure that the hand-written queries which don't quote anything hit the same columns as the generated ones.
 type PgsqlAdapter struct {
 	Name        string // ? - Do we really need this? Can't we hard-code this?
 	Buffer      map[string]DBStmt
@@ -33,9 +40,28 @@ func (a *PgsqlAdapter) GetStmts() map[string]DBStmt {
 	return a.Buffer
 }
 
-// TODO: Implement this
+// BuildConn connects through our wrapper around lib/pq, see pgsql_driver.go
 func (a *PgsqlAdapter) BuildConn(config map[string]string) (*sql.DB, error) {
-	return nil, nil
+	sslmode := config["sslmode"]
+	if sslmode == "" {
+		sslmode = "disable"
+	}
+	dsn := "host=" + pgEscape(config["host"]) + " port=" + pgEscape(config["port"]) + " user=" + pgEscape(config["username"]) + " dbname=" + pgEscape(config["name"]) + " sslmode=" + pgEscape(sslmode)
+	if config["password"] != "" {
+		dsn += " password=" + pgEscape(config["password"])
+	}
+	db, err := sql.Open(PgsqlDriver, dsn)
+	if err != nil {
+		return db, err
+	}
+	// Make sure that the connection is alive
+	return db, db.Ping()
+}
+
+// pgEscape quotes a value for a key=value connection string
+func pgEscape(val string) string {
+	val = strings.Replace(val, "\\", "\\\\", -1)
+	return "'" + strings.Replace(val, "'", "\\'", -1) + "'"
 }
 
 func (a *PgsqlAdapter) DbVersion() string {
@@ -46,13 +72,13 @@ func (a *PgsqlAdapter) DropTable(name, table string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	q := "DROP TABLE IF EXISTS \"" + table + "\";"
+	q := "DROP TABLE IF EXISTS " + a.quote(table) + ";"
 	a.pushStatement(name, "drop-table", q)
 	return q, nil
 }
 
-// TODO: Implement this
-// We may need to change the CreateTable API to better suit PGSQL and the other database drivers which are coming up
+// PostgreSQL sets the encoding when the database is created, so the charset and collation are ignored.
+// Fulltext keys become separate GIN indices which are tacked onto the end of the statement, so the statement has to be executed without any parameters.
 func (a *PgsqlAdapter) CreateTable(name, table, charset, collation string, cols []DBTableColumn, keys []DBTableKey) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
@@ -61,89 +87,176 @@ func (a *PgsqlAdapter) CreateTable(name, table, charset, collation string, cols
 		return "", errors.New("You can't have a table with no columns")
 	}
 
-	q := "CREATE TABLE \"" + table + "\" ("
+	q := "CREATE TABLE " + a.quote(table) + " ("
 	for _, col := range cols {
-		if col.AutoIncrement {
-			col.Type = "serial"
-		} else if col.Type == "createdAt" {
-			col.Type = "timestamp"
-		} else if col.Type == "datetime" {
-			col.Type = "timestamp"
+		col, size, end := a.parseColumn(col)
+		q += "\n\t" + a.quote(col.Name) + " " + col.Type + size + end + ","
+	}
+
+	var after string
+	for _, key := range keys {
+		switch key.Type {
+		case "primary":
+			q += "\n\tPRIMARY KEY(" + a.quoteList(key.Columns) + "),"
+		case "unique":
+			q += "\n\tUNIQUE(" + a.quoteList(key.Columns) + "),"
+		case "foreign":
+			cols := strings.Split(key.Columns, ",")
+			q += "\n\tFOREIGN KEY(" + a.quote(cols[0]) + ") REFERENCES " + a.quote(key.FTable) + "(" + a.quote(cols[1]) + ")"
+			if key.Cascade {
+				q += " ON DELETE CASCADE"
+			}
+			q += ","
+		case "fulltext":
+			after += "\n" + a.fulltextIndex(table, key.Columns) + ";"
+		default:
+			return "", errors.New("Unknown key type '" + key.Type + "'")
 		}
+	}
 
-		var size string
-		if col.Size > 0 {
-			size = " (" + strconv.Itoa(col.Size) + ")"
-		}
+	q = q[0:len(q)-1] + "\n);" + after
+	a.pushStatement(name, "create-table", q)
+	return q, nil
+}
 
-		var end string
-		if col.Default != "" {
-			end = " DEFAULT "
-			if a.stringyType(col.Type) && col.Default != "''" {
-				end += "'" + col.Default + "'"
-			} else {
-				end += col.Default
-			}
-		}
-		if !col.Null {
-			end += " not null"
-		}
+// The searcher uses the simple configuration, as we have no idea which language the posts are going to be in, the expression has to match that for the index to be used
+func (a *PgsqlAdapter) fulltextIndex(table, col string) string {
+	return "CREATE INDEX " + a.quote("ft_"+table+"_"+col) + " ON " + a.quote(table) + " USING GIN(to_tsvector('simple'," + a.quote(col) + "))"
+}
 
-		q += "\n\t`" + col.Name + "` " + col.Type + size + end + ","
+func (a *PgsqlAdapter) parseColumn(col DBTableColumn) (ocol DBTableColumn, size, end string) {
+	switch strings.ToLower(col.Type) {
+	case "createdat", "datetime":
+		col.Type = "timestamp"
+	// Booleans are compared with and set to integers everywhere, so we can't use PostgreSQL's strict boolean type
+	case "boolean", "tinyint":
+		col.Type = "smallint"
+	case "json", "mediumtext", "longtext":
+		col.Type = "text"
 	}
-
-	if len(keys) > 0 {
-		for _, key := range keys {
-			q += "\n\t" + key.Type
-			if key.Type != "unique" {
-				q += " key"
-			}
-			q += "("
-			for _, column := range strings.Split(key.Columns, ",") {
-				q += "`" + column + "`,"
-			}
-			q = q[0:len(q)-1] + "),"
+	if col.AutoIncrement {
+		if col.Type == "bigint" {
+			col.Type = "bigserial"
+		} else {
+			col.Type = "serial"
 		}
+		return col, "", " not null"
+	}
+	// Only the character types have a size, int(11) and the like are just a MySQL display hint
+	if col.Size > 0 && (col.Type == "varchar" || col.Type == "char") {
+		size = "(" + strconv.Itoa(col.Size) + ")"
 	}
 
-	q = q[0:len(q)-1] + "\n);"
-	a.pushStatement(name, "create-table", q)
-	return q, nil
+	if col.Default != "" {
+		end = " DEFAULT "
+		if a.stringyType(col.Type) && col.Default != "''" {
+			end += "'" + col.Default + "'"
+		} else {
+			end += col.Default
+		}
+	}
+	if col.Null {
+		end += " null"
+	} else {
+		end += " not null"
+	}
+	return col, size, end
 }
 
-// TODO: Implement this
-func (a *PgsqlAdapter) AddColumn(name, table string, column DBTableColumn, key *DBTableKey) (string, error) {
+func (a *PgsqlAdapter) AddColumn(name, table string, col DBTableColumn, key *DBTableKey) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	return "", nil
+	col, size, end := a.parseColumn(col)
+	q := "ALTER TABLE " + a.quote(table) + " ADD COLUMN " + a.quote(col.Name) + " " + col.Type + size + end
+	if key != nil {
+		switch key.Type {
+		case "primary":
+			q += " PRIMARY KEY"
+		case "unique":
+			q += " UNIQUE"
+		default:
+			return "", errors.New("Only primary and unique keys can be added alongside a column")
+		}
+	}
+	a.pushStatement(name, "add-column", q)
+	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) DropColumn(name, table, colName string) (string, error) {
-	return "", errors.New("not implemented")
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if colName == "" {
+		return "", errors.New("You need a name for the column")
+	}
+	q := "ALTER TABLE " + a.quote(table) + " DROP COLUMN " + a.quote(colName)
+	a.pushStatement(name, "drop-column", q)
+	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) RenameColumn(name, table, oldName, newName string) (string, error) {
-	return "", errors.New("not implemented")
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	q := "ALTER TABLE " + a.quote(table) + " RENAME COLUMN " + a.quote(oldName) + " TO " + a.quote(newName)
+	a.pushStatement(name, "rename-column", q)
+	return q, nil
 }
 
-// TODO: Implement this
+// ChangeColumn works like MySQL's CHANGE COLUMN, so the column may be renamed, retyped and given a new default all at once
+// PostgreSQL can't rename a column in the same statement, so that takes a second one, the query has to be run with Builder.ExecMulti rather than prepared
 func (a *PgsqlAdapter) ChangeColumn(name, table, colName string, col DBTableColumn) (string, error) {
-	return "", errors.New("not implemented")
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if colName == "" {
+		return "", errors.New("You need a name for the column")
+	}
+	col, size, _ := a.parseColumn(col)
+	if col.AutoIncrement {
+		return "", errors.New("PostgreSQL can't turn an existing column into a serial one")
+	}
+	tbl, old := a.quote(table), a.quote(colName)
+	ctype := col.Type + size
+	q := "ALTER TABLE " + tbl + " ALTER COLUMN " + old + " DROP DEFAULT, ALTER COLUMN " + old + " TYPE " + ctype + " USING " + old + "::" + ctype
+	if col.Default != "" {
+		def := col.Default
+		if a.stringyType(col.Type) && def != "''" {
+			def = "'" + def + "'"
+		}
+		q += ", ALTER COLUMN " + old + " SET DEFAULT " + def
+	}
+	if col.Null {
+		q += ", ALTER COLUMN " + old + " DROP NOT NULL"
+	} else {
+		q += ", ALTER COLUMN " + old + " SET NOT NULL"
+	}
+	if !strings.EqualFold(colName, col.Name) {
+		q += ";\nALTER TABLE " + tbl + " RENAME COLUMN " + old + " TO " + a.quote(col.Name)
+	}
+	a.pushStatement(name, "change-column", q)
+	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) SetDefaultColumn(name, table, colName, colType, defaultStr string) (string, error) {
-	if colType == "text" {
-		return "", errors.New("text fields cannot have default values")
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if colName == "" {
+		return "", errors.New("You need a name for the column")
 	}
-	return "", errors.New("not implemented")
+	if defaultStr == "" {
+		defaultStr = "''"
+	}
+	if a.stringyType(colType) && defaultStr != "''" {
+		defaultStr = "'" + defaultStr + "'"
+	}
+	q := "ALTER TABLE " + a.quote(table) + " ALTER COLUMN " + a.quote(colName) + " SET DEFAULT " + defaultStr
+	a.pushStatement(name, "set-default-column", q)
+	return q, nil
 }
 
-// TODO: Implement this
-// TODO: Test to make sure everything works here
 func (a *PgsqlAdapter) AddIndex(name, table, iname, colname string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
@@ -154,23 +267,37 @@ func (a *PgsqlAdapter) AddIndex(name, table, iname, colname string) (string, err
 	if colname == "" {
 		return "", errors.New("You need a name for the column")
 	}
-	return "", errors.New("not implemented")
+	// Index names are shared by every table in a schema, so the table name has to be a part of it
+	q := "CREATE INDEX " + a.quote("i_"+table+"_"+iname) + " ON " + a.quote(table) + "(" + a.quote(colname) + ")"
+	a.pushStatement(name, "add-index", q)
+	return q, nil
 }
 
-// TODO: Implement this
-// TODO: Test to make sure everything works here
-func (a *PgsqlAdapter) AddKey(name, table, column string, key DBTableKey) (string, error) {
+func (a *PgsqlAdapter) AddKey(name, table, cols string, key DBTableKey) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	if column == "" {
-		return "", errors.New("You need a name for the column")
+	if cols == "" {
+		return "", errors.New("You need to specify columns")
+	}
+	var q string
+	switch key.Type {
+	case "primary":
+		q = "ALTER TABLE " + a.quote(table) + " ADD PRIMARY KEY(" + a.quoteList(cols) + ")"
+	case "unique":
+		q = "ALTER TABLE " + a.quote(table) + " ADD UNIQUE(" + a.quoteList(cols) + ")"
+	case "fulltext":
+		if strings.Contains(cols, ",") {
+			return "", errors.New("Fulltext keys can only cover one column on PostgreSQL")
+		}
+		q = a.fulltextIndex(table, cols)
+	default:
+		return "", errors.New("Unknown key type '" + key.Type + "'")
 	}
-	return "", errors.New("not implemented")
+	a.pushStatement(name, "add-key", q)
+	return q, nil
 }
 
-// TODO: Implement this
-// TODO: Test to make sure everything works here
 func (a *PgsqlAdapter) RemoveIndex(name, table, iname string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
@@ -178,11 +305,11 @@ func (a *PgsqlAdapter) RemoveIndex(name, table, iname string) (string, error) {
 	if iname == "" {
 		return "", errors.New("You need a name for the index")
 	}
-	return "", errors.New("not implemented")
+	q := "DROP INDEX IF EXISTS " + a.quote("i_"+table+"_"+iname)
+	a.pushStatement(name, "remove-index", q)
+	return q, nil
 }
 
-// TODO: Implement this
-// TODO: Test to make sure everything works here
 func (a *PgsqlAdapter) AddForeignKey(name, table, column, ftable, fcolumn string, cascade bool) (out string, e error) {
 	var c = func(str string, val bool) {
 		if e != nil || !val {
@@ -197,42 +324,66 @@ func (a *PgsqlAdapter) AddForeignKey(name, table, column, ftable, fcolumn string
 	if e != nil {
 		return "", e
 	}
-	return "", errors.New("not implemented")
+	q := "ALTER TABLE " + a.quote(table) + " ADD CONSTRAINT " + a.quote("fk_"+table+"_"+column) + " FOREIGN KEY(" + a.quote(column) + ") REFERENCES " + a.quote(ftable) + "(" + a.quote(fcolumn) + ")"
+	if cascade {
+		q += " ON DELETE CASCADE"
+	}
+	a.pushStatement(name, "add-foreign-key", q)
+	return q, nil
 }
 
-// TODO: Test this
-// ! We need to get the last ID out of this somehow, maybe add returning to every query? Might require some sort of wrapper over the sql statements
-func (a *PgsqlAdapter) SimpleInsert(name, table, columns, fields string) (string, error) {
+func (a *PgsqlAdapter) SimpleInsert(name, table, cols, fields string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
 	}
+	q := "INSERT INTO " + a.quote(table)
+	if cols != "" {
+		q += "(" + a.buildColumns(cols) + ") VALUES (" + a.buildFields(fields) + ")"
+	} else {
+		q += " DEFAULT VALUES"
+	}
+	a.pushStatement(name, "insert", q)
+	return q, nil
+}
 
-	q := "INSERT INTO \"" + table + "\"("
-	if columns != "" {
-		q += a.buildColumns(columns) + ") VALUES ("
-		for _, field := range processFields(fields) {
-			nameLen := len(field.Name)
-			if field.Name[0] == '"' && field.Name[nameLen-1] == '"' && nameLen >= 3 {
-				field.Name = "'" + field.Name[1:nameLen-1] + "'"
-			}
-			if field.Name[0] == '\'' && field.Name[nameLen-1] == '\'' && nameLen >= 3 {
-				field.Name = "'" + strings.Replace(field.Name[1:nameLen-1], "'", "''", -1) + "'"
+func (a *PgsqlAdapter) SimpleBulkInsert(name, table, cols string, fieldSet []string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	q := "INSERT INTO " + a.quote(table)
+	if cols != "" {
+		q += "(" + a.buildColumns(cols) + ") VALUES "
+		for i, fields := range fieldSet {
+			if i != 0 {
+				q += ","
 			}
-			q += field.Name + ","
+			q += "(" + a.buildFields(fields) + ")"
 		}
-		q = q[0 : len(q)-1]
 	} else {
-		q += ") VALUES ("
+		q += " DEFAULT VALUES"
 	}
-	q += ")"
-
-	a.pushStatement(name, "insert", q)
+	a.pushStatement(name, "bulk-insert", q)
 	return q, nil
 }
 
-// TODO: Implement this
-func (a *PgsqlAdapter) SimpleBulkInsert(name, table, columns string, fieldSet []string) (string, error) {
-	return "", nil
+func (a *PgsqlAdapter) buildFields(fields string) (q string) {
+	for _, field := range processFields(fields) {
+		nameLen := len(field.Name)
+		if field.Name == "\"\"" {
+			field.Name = "''"
+		} else if field.Name[0] == '"' && field.Name[nameLen-1] == '"' && nameLen >= 3 {
+			field.Name = "'" + field.Name[1:nameLen-1] + "'"
+		} else if field.Name[0] == '\'' && field.Name[nameLen-1] == '\'' && nameLen >= 3 {
+			field.Name = "'" + strings.Replace(field.Name[1:nameLen-1], "'", "''", -1) + "'"
+		} else {
+			field.Name = a.fn(field.Name)
+		}
+		q += field.Name + ","
+	}
+	if q != "" {
+		q = q[0 : len(q)-1]
+	}
+	return q
 }
 
 func (a *PgsqlAdapter) buildColumns(cols string) (q string) {
@@ -242,111 +393,66 @@ func (a *PgsqlAdapter) buildColumns(cols string) (q string) {
 	// Escape the column names, just in case we've used a reserved keyword
 	for _, col := range processColumns(cols) {
 		if col.Type == TokenFunc {
-			q += col.Left + ","
+			q += a.fn(col.Left) + ","
 		} else {
-			q += "\"" + col.Left + "\","
+			q += a.quote(col.Left) + ","
 		}
 	}
 	return q[0 : len(q)-1]
 }
 
-// TODO: Implement this
-func (a *PgsqlAdapter) SimpleReplace(name, table, columns, fields string) (string, error) {
-	if table == "" {
-		return "", errors.New("You need a name for this table")
-	}
-	if len(columns) == 0 {
-		return "", errors.New("No columns found for SimpleInsert")
-	}
-	if len(fields) == 0 {
-		return "", errors.New("No input data found for SimpleInsert")
-	}
-	return "", nil
-}
-
-// TODO: Implement this
-func (a *PgsqlAdapter) SimpleUpsert(name, table, columns, fields, where string) (string, error) {
-	if table == "" {
-		return "", errors.New("You need a name for this table")
-	}
-	if len(columns) == 0 {
-		return "", errors.New("No columns found for SimpleInsert")
-	}
-	if len(fields) == 0 {
-		return "", errors.New("No input data found for SimpleInsert")
-	}
-	return "", nil
-}
-
-// TODO: Implemented, but we need CreateTable and a better installer to *test* it
-func (a *PgsqlAdapter) SimpleUpdate(up *updatePrebuilder) (string, error) {
-	if up.table == "" {
-		return "", errors.New("You need a name for this table")
-	}
-	if up.set == "" {
-		return "", errors.New("You need to set data in this update statement")
-	}
-
-	q := "UPDATE \"" + up.table + "\" SET "
-	for _, item := range processSet(up.set) {
-		q += "`" + item.Column + "`="
+func (a *PgsqlAdapter) buildSet(set string) (q string) {
+	for _, item := range processSet(set) {
+		// PostgreSQL doesn't let you qualify the column being set with the name of the table
+		col := item.Column
+		if i := strings.LastIndex(col, "."); i != -1 {
+			col = col[i+1:]
+		}
+		q += a.quote(col) + "="
 		for _, token := range item.Expr {
 			switch token.Type {
 			case TokenFunc:
-				// TODO: Write a more sophisticated function parser on the utils side.
-				if strings.ToUpper(token.Contents) == "UTC_TIMESTAMP()" {
-					token.Contents = "LOCALTIMESTAMP()"
-				}
-				q += " " + token.Contents
+				q += " " + a.fn(token.Contents)
 			case TokenOp, TokenNumber, TokenSub, TokenOr:
 				q += " " + token.Contents
 			case TokenColumn:
-				q += " `" + token.Contents + "`"
+				q += " " + a.quote(token.Contents)
 			case TokenString:
 				q += " '" + token.Contents + "'"
 			}
 		}
 		q += ","
 	}
-	q = q[0 : len(q)-1]
-
-	// Add support for BETWEEN x.x
-	if len(up.where) != 0 {
-		q += " WHERE"
-		for _, loc := range processWhere(up.where) {
-			for _, token := range loc.Expr {
-				switch token.Type {
-				case TokenFunc:
-					// TODO: Write a more sophisticated function parser on the utils side. What's the situation in regards to case sensitivity?
-					if strings.ToUpper(token.Contents) == "UTC_TIMESTAMP()" {
-						token.Contents = "LOCALTIMESTAMP()"
-					}
-					q += " " + token.Contents
-				case TokenOp, TokenNumber, TokenSub, TokenOr, TokenNot, TokenLike:
-					q += " " + token.Contents
-				case TokenColumn:
-					q += " `" + token.Contents + "`"
-				case TokenString:
-					q += " '" + token.Contents + "'"
-				default:
-					panic("This token doesn't exist o_o")
-				}
-			}
-			q += " AND"
-		}
-		q = q[0 : len(q)-4]
-	}
+	return q[0 : len(q)-1]
+}
 
+func (a *PgsqlAdapter) SimpleUpdate(up *updatePrebuilder) (string, error) {
+	if up.table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if up.set == "" {
+		return "", errors.New("You need to set data in this update statement")
+	}
+	whereStr, err := a.buildFlexiWhere(up.where, up.dateCutoff)
+	if err != nil {
+		return "", err
+	}
+	q := "UPDATE " + a.quote(up.table) + " SET " + a.buildSet(up.set) + whereStr
 	a.pushStatement(up.name, "update", q)
 	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) SimpleUpdateSelect(up *updatePrebuilder) (string, error) {
-	return "", errors.New("not implemented")
+	sel := up.whereSubQuery
+	whereStr, err := a.buildWhere(sel.where)
+	if err != nil {
+		return "", err
+	}
+	q := "UPDATE " + a.quote(up.table) + " SET " + a.buildSet(up.set) + " WHERE (SELECT" + a.buildJoinColumns(sel.columns) + " FROM " + a.quote(sel.table) + whereStr + a.buildOrderby(sel.orderby) + a.buildLimit(sel.limit) + ")"
+	a.pushStatement(up.name, "update", q)
+	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) SimpleDelete(name, table, where string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
@@ -354,106 +460,301 @@ func (a *PgsqlAdapter) SimpleDelete(name, table, where string) (string, error) {
 	if where == "" {
 		return "", errors.New("You need to specify what data you want to delete")
 	}
-	return "", nil
+	whereStr, err := a.buildWhere(where)
+	if err != nil {
+		return "", err
+	}
+	q := "DELETE FROM " + a.quote(table) + whereStr
+	a.pushStatement(name, "delete", q)
+	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) ComplexDelete(b *deletePrebuilder) (string, error) {
 	if b.table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	if b.where == "" {
+	if b.where == "" && b.dateCutoff == nil {
 		return "", errors.New("You need to specify what data you want to delete")
 	}
-	return "", nil
+	whereStr, err := a.buildFlexiWhere(b.where, b.dateCutoff)
+	if err != nil {
+		return "", err
+	}
+	q := "DELETE FROM " + a.quote(b.table) + whereStr
+	a.pushStatement(b.name, "delete", q)
+	return q, nil
 }
 
-// TODO: Implement this
 // We don't want to accidentally wipe tables, so we'll have a separate method for purging tables instead
 func (a *PgsqlAdapter) Purge(name, table string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	return "", nil
+	q := "DELETE FROM " + a.quote(table)
+	a.pushStatement(name, "purge", q)
+	return q, nil
+}
+
+func (a *PgsqlAdapter) buildWhere(where string) (q string, err error) {
+	return a.buildFlexiWhere(where, nil)
+}
+
+const pgsqlNow = "(NOW() AT TIME ZONE 'UTC')"
+
+// fn swaps out the MySQL functions which PostgreSQL doesn't have
+func (a *PgsqlAdapter) fn(expr string) string {
+	if !strings.Contains(strings.ToUpper(expr), "UTC_TIMESTAMP()") {
+		return expr
+	}
+	expr = strings.Replace(expr, "UTC_TIMESTAMP()", pgsqlNow, -1)
+	return strings.Replace(expr, "utc_timestamp()", pgsqlNow, -1)
+}
+
+func (a *PgsqlAdapter) buildDateCutoff(dc *dateCutoff) string {
+	col := a.quote(dc.Column)
+	switch dc.Type {
+	case 0:
+		return col + " BETWEEN " + pgsqlNow + " - interval '" + strconv.Itoa(dc.Quantity) + " " + dc.Unit + "' AND " + pgsqlNow
+	case 11:
+		return col + "<" + pgsqlNow + " - ?::integer * interval '1 " + dc.Unit + "'"
+	}
+	return col + "<" + pgsqlNow + " - interval '" + strconv.Itoa(dc.Quantity) + " " + dc.Unit + "'"
+}
+
+func (a *PgsqlAdapter) buildFlexiWhere(where string, dateCutoff *dateCutoff) (q string, err error) {
+	if len(where) == 0 && dateCutoff == nil {
+		return "", nil
+	}
+	q = " WHERE"
+	if dateCutoff != nil {
+		q += " " + a.buildDateCutoff(dateCutoff)
+		if len(where) != 0 {
+			q += " AND"
+		}
+	}
+	for i, loc := range processWhere(where) {
+		if i != 0 {
+			q += " AND"
+		}
+		for _, token := range loc.Expr {
+			switch token.Type {
+			case TokenFunc:
+				q += " " + a.fn(token.Contents)
+			case TokenOp, TokenNumber, TokenSub, TokenOr, TokenNot, TokenLike:
+				q += " " + token.Contents
+			case TokenColumn:
+				q += " " + a.quote(token.Contents)
+			case TokenString:
+				q += " '" + token.Contents + "'"
+			default:
+				return q, errors.New("This token doesn't exist o_o")
+			}
+		}
+	}
+	return q, nil
+}
+
+func (a *PgsqlAdapter) buildOrderby(orderby string) (q string) {
+	if len(orderby) == 0 {
+		return ""
+	}
+	q = " ORDER BY "
+	for i, col := range processOrderby(orderby) {
+		if i != 0 {
+			q += ","
+		}
+		q += a.quote(col.Column) + " " + strings.ToUpper(col.Order)
+	}
+	return q
+}
+
+// buildLimit turns MySQL's LIMIT offset,count into something PostgreSQL understands, OFFSET goes first to keep the parameters in the same order
+func (a *PgsqlAdapter) buildLimit(limit string) (q string) {
+	if limit == "" {
+		return ""
+	}
+	halves := strings.Split(limit, ",")
+	if len(halves) == 2 {
+		return " OFFSET " + strings.TrimSpace(halves[0]) + " LIMIT " + strings.TrimSpace(halves[1])
+	}
+	return " LIMIT " + limit
 }
 
-// TODO: Implement this
-func (a *PgsqlAdapter) SimpleSelect(name, table, columns, where, orderby, limit string) (string, error) {
+func (a *PgsqlAdapter) SimpleSelect(name, table, cols, where, orderby, limit string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	if len(columns) == 0 {
+	if len(cols) == 0 {
 		return "", errors.New("No columns found for SimpleSelect")
 	}
-	return "", nil
+	whereStr, err := a.buildWhere(where)
+	if err != nil {
+		return "", err
+	}
+
+	q := "SELECT " + a.quoteList(strings.TrimSpace(cols)) + " FROM " + a.quote(table) + whereStr + a.buildOrderby(orderby) + a.buildLimit(limit)
+	a.pushStatement(name, "select", q)
+	return q, nil
+}
+
+func (a *PgsqlAdapter) ComplexSelect(preBuilder *selectPrebuilder) (out string, err error) {
+	out, err = a.complexSelect(preBuilder)
+	a.pushStatement(preBuilder.name, "select", out)
+	return out, err
 }
 
-// TODO: Implement this
-func (a *PgsqlAdapter) ComplexSelect(prebuilder *selectPrebuilder) (string, error) {
-	if prebuilder.table == "" {
+func (a *PgsqlAdapter) complexSelect(preBuilder *selectPrebuilder) (string, error) {
+	if preBuilder.table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	if len(prebuilder.columns) == 0 {
+	if len(preBuilder.columns) == 0 {
 		return "", errors.New("No columns found for ComplexSelect")
 	}
-	return "", nil
+
+	q := "SELECT" + a.buildJoinColumns(preBuilder.columns) + " FROM " + a.quote(preBuilder.table)
+	// TODO: Let callers have a Where() and a InQ()
+	if preBuilder.inChain != nil {
+		sub, err := a.complexSelect(preBuilder.inChain)
+		if err != nil {
+			return q, err
+		}
+		q += " WHERE " + a.quote(preBuilder.inColumn) + " IN(" + sub + ")"
+	} else {
+		whereStr, err := a.buildFlexiWhere(preBuilder.where, preBuilder.dateCutoff)
+		if err != nil {
+			return q, err
+		}
+		q += whereStr
+	}
+	return q + a.buildOrderby(preBuilder.orderby) + a.buildLimit(preBuilder.limit), nil
 }
 
-// TODO: Implement this
-func (a *PgsqlAdapter) SimpleLeftJoin(name, table1, table2, columns, joiners, where, orderby, limit string) (string, error) {
+func (a *PgsqlAdapter) simpleJoin(name, joinType, table1, table2, cols, joiners, where, orderby, limit string) (string, error) {
 	if table1 == "" {
 		return "", errors.New("You need a name for the left table")
 	}
 	if table2 == "" {
 		return "", errors.New("You need a name for the right table")
 	}
-	if len(columns) == 0 {
-		return "", errors.New("No columns found for SimpleLeftJoin")
+	if len(cols) == 0 {
+		return "", errors.New("No columns found for Simple" + joinType + "Join")
 	}
 	if len(joiners) == 0 {
-		return "", errors.New("No joiners found for SimpleLeftJoin")
+		return "", errors.New("No joiners found for Simple" + joinType + "Join")
+	}
+	whereStr, err := a.buildWhere(where)
+	if err != nil {
+		return "", err
 	}
-	return "", nil
+	q := "SELECT" + a.buildJoinColumns(cols) + " FROM " + a.buildJoinTable(table1) + " " + strings.ToUpper(joinType) + " JOIN " + a.buildJoinTable(table2) + " ON " + a.buildJoiners(joiners) + whereStr + a.buildOrderby(orderby) + a.buildLimit(limit)
+	return q, nil
 }
 
-// TODO: Implement this
-func (a *PgsqlAdapter) SimpleInnerJoin(name, table1, table2, columns, joiners, where, orderby, limit string) (string, error) {
-	if table1 == "" {
-		return "", errors.New("You need a name for the left table")
-	}
-	if table2 == "" {
-		return "", errors.New("You need a name for the right table")
-	}
-	if len(columns) == 0 {
-		return "", errors.New("No columns found for SimpleInnerJoin")
+func (a *PgsqlAdapter) SimpleLeftJoin(name, table1, table2, cols, joiners, where, orderby, limit string) (string, error) {
+	q, err := a.simpleJoin(name, "Left", table1, table2, cols, joiners, where, orderby, limit)
+	if err != nil {
+		return "", err
 	}
-	if len(joiners) == 0 {
-		return "", errors.New("No joiners found for SimpleInnerJoin")
+	a.pushStatement(name, "select", q)
+	return q, nil
+}
+
+func (a *PgsqlAdapter) SimpleInnerJoin(name, table1, table2, cols, joiners, where, orderby, limit string) (string, error) {
+	q, err := a.simpleJoin(name, "Inner", table1, table2, cols, joiners, where, orderby, limit)
+	if err != nil {
+		return "", err
 	}
-	return "", nil
+	a.pushStatement(name, "select", q)
+	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) SimpleInsertSelect(name string, ins DBInsert, sel DBSelect) (string, error) {
-	return "", nil
+	whereStr, err := a.buildWhere(sel.Where)
+	if err != nil {
+		return "", err
+	}
+	q := "INSERT INTO " + a.quote(ins.Table) + "(" + a.buildColumns(ins.Columns) + ") SELECT" + a.buildJoinColumns(sel.Columns) + " FROM " + a.quote(sel.Table) + whereStr + a.buildOrderby(sel.Orderby) + a.buildLimit(sel.Limit)
+	a.pushStatement(name, "insert", q)
+	return q, nil
+}
+
+func (a *PgsqlAdapter) simpleInsertJoin(name, joinType string, ins DBInsert, sel DBJoin) (string, error) {
+	whereStr, err := a.buildWhere(sel.Where)
+	if err != nil {
+		return "", err
+	}
+	q := "INSERT INTO " + a.quote(ins.Table) + "(" + a.buildColumns(ins.Columns) + ") SELECT" + a.buildJoinColumns(sel.Columns) + " FROM " + a.buildJoinTable(sel.Table1) + " " + joinType + " JOIN " + a.buildJoinTable(sel.Table2) + " ON " + a.buildJoiners(sel.Joiners) + whereStr + a.buildOrderby(sel.Orderby) + a.buildLimit(sel.Limit)
+	a.pushStatement(name, "insert", q)
+	return q, nil
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) SimpleInsertLeftJoin(name string, ins DBInsert, sel DBJoin) (string, error) {
-	return "", nil
+	return a.simpleInsertJoin(name, "LEFT", ins, sel)
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) SimpleInsertInnerJoin(name string, ins DBInsert, sel DBJoin) (string, error) {
-	return "", nil
+	return a.simpleInsertJoin(name, "INNER", ins, sel)
+}
+
+func (a *PgsqlAdapter) buildJoinTable(table string) string {
+	halves := strings.Split(strings.Replace(table, " as ", " AS ", -1), " AS ")
+	if len(halves) == 2 {
+		return a.quote(strings.TrimSpace(halves[0])) + " AS " + a.quote(strings.TrimSpace(halves[1]))
+	}
+	return a.quote(table)
+}
+
+func (a *PgsqlAdapter) buildJoiners(joiners string) (q string) {
+	for _, j := range processJoiner(joiners) {
+		q += a.quote(j.LeftTable+"."+j.LeftColumn) + " " + j.Operator + " " + a.quote(j.RightTable+"."+j.RightColumn) + " AND "
+	}
+	// Remove the trailing AND
+	return q[0 : len(q)-5]
+}
+
+func (a *PgsqlAdapter) buildJoinColumns(cols string) (q string) {
+	for _, col := range processColumns(cols) {
+		// TODO: Error if [0] doesn't exist
+		firstChar := col.Left[0]
+		if firstChar == '\'' {
+			col.Type = TokenString
+		} else {
+			_, err := strconv.Atoi(string(firstChar))
+			if err == nil {
+				col.Type = TokenNumber
+			}
+		}
+
+		// Escape the column names, just in case we've used a reserved keyword
+		source := col.Left
+		if col.Table != "" {
+			source = a.quote(col.Table + "." + source)
+		} else if col.Type == TokenFunc {
+			source = a.fn(source)
+		} else if col.Type != TokenNumber && col.Type != TokenSub && col.Type != TokenString {
+			source = a.quote(source)
+		}
+
+		var alias string
+		if col.Alias != "" {
+			alias = " AS " + a.quote(col.Alias)
+		}
+		q += " " + source + alias + ","
+	}
+	return q[0 : len(q)-1]
 }
 
-// TODO: Implement this
 func (a *PgsqlAdapter) SimpleCount(name, table, where, limit string) (string, error) {
 	if table == "" {
 		return "", errors.New("You need a name for this table")
 	}
-	return "", nil
+	whereStr, err := a.buildWhere(where)
+	if err != nil {
+		return "", err
+	}
+	q := "SELECT COUNT(*) FROM " + a.quote(table) + whereStr + a.buildLimit(limit)
+	a.pushStatement(name, "select", q)
+	return q, nil
 }
 
 func (a *PgsqlAdapter) Builder() *prebuilder {
@@ -467,10 +768,10 @@ func (a *PgsqlAdapter) Write() error {
 			continue
 		}
 		stmt := a.Buffer[name]
-		// TODO: Add support for create-table? Table creation might be a little complex for Go to do outside a SQL file :(
+		// ? - Table creation might be a little complex for Go to do outside a SQL file :(
 		if stmt.Type != "create-table" {
 			stmts += "\t" + name + " *sql.Stmt\n"
-			body += `	
+			body += `
 	common.DebugLog("Preparing ` + name + ` statement.")
 	stmts.` + name + `, err = db.Prepare("` + strings.Replace(stmt.Contents, "\"", "\\\"", -1) + `")
 	if err != nil {
@@ -484,7 +785,8 @@ func (a *PgsqlAdapter) Write() error {
 	// TODO: Move these custom queries out of this file
 	out := `// +build pgsql
 
-// This file was generated by Gosora's Query Generator. Please try to avoid modifying this file, as it might change at any time.
+/* This file was generated by Gosora's Query Generator. Please try to avoid modifying this file, as it might change at any time. */
+
 package main
 
 import "log"
@@ -519,7 +821,22 @@ func (a *PgsqlAdapter) pushStatement(name, stype, q string) {
 	a.BufferOrder = append(a.BufferOrder, name)
 }
 
-func (a *PgsqlAdapter) stringyType(ctype string) bool {
-	ctype = strings.ToLower(ctype)
-	return ctype == "char" || ctype == "varchar" || ctype == "timestamp" || ctype == "text"
+// quote escapes an identifier, which may be qualified with the name of a table
+func (a *PgsqlAdapter) quote(ident string) string {
+	return "\"" + strings.Replace(strings.ToLower(ident), ".", "\".\"", -1) + "\""
+}
+
+func (a *PgsqlAdapter) quoteList(idents string) (q string) {
+	for i, ident := range strings.Split(idents, ",") {
+		if i != 0 {
+			q += ","
+		}
+		q += a.quote(strings.TrimSpace(ident))
+	}
+	return q
+}
+
+func (a *PgsqlAdapter) stringyType(ct string) bool {
+	ct = strings.ToLower(ct)
+	return ct == "char" || ct == "varchar" || ct == "timestamp" || ct == "text"
 }
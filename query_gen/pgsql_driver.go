@@ -0,0 +1,179 @@
+package qgen
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PgsqlDriver is the name our wrapper around lib/pq is registered under
+var PgsqlDriver = "gosora_postgres"
+
+// PgsqlLastvalFunc has to be run on the database before anything is inserted, lastval() errors when nothing has been inserted into a serial column on the connection yet and that would abort any transaction we happen to be in
+var PgsqlLastvalFunc = `CREATE OR REPLACE FUNCTION gosora_lastval() RETURNS bigint AS $$
+BEGIN
+	RETURN lastval();
+EXCEPTION WHEN OTHERS THEN
+	RETURN 0;
+END
+$$ LANGUAGE plpgsql;`
+
+// The rest of the software is written with MySQL in mind, so we paper over the differences here rather than at every call site:
+// ? placeholders are turned into $n ones, bools are sent as integers and we dig out the last inserted ID after each insert
+func init() {
+	sql.Register(PgsqlDriver, &pgsqlDriver{})
+}
+
+type pgsqlDriver struct{}
+
+func (d *pgsqlDriver) Open(name string) (driver.Conn, error) {
+	cn, err := pq.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &pgsqlConn{cn}, nil
+}
+
+type pgsqlConn struct {
+	driver.Conn
+}
+
+func (cn *pgsqlConn) Prepare(q string) (driver.Stmt, error) {
+	stmt, err := cn.Conn.Prepare(pgsqlRebind(q))
+	if err != nil {
+		return nil, err
+	}
+	return &pgsqlStmt{stmt, cn, pgsqlIsInsert(q)}, nil
+}
+
+func (cn *pgsqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return cn.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+// ExecContext sends queries without any parameters as they are, lib/pq runs those without preparing them, which is the only way several statements can be sent at once, see Builder.ExecMulti
+func (cn *pgsqlConn) ExecContext(ctx context.Context, q string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) > 0 {
+		q = pgsqlRebind(q)
+	}
+	res, err := cn.Conn.(driver.ExecerContext).ExecContext(ctx, q, pgsqlNamedArgs(args))
+	if err != nil || !pgsqlIsInsert(q) {
+		return res, err
+	}
+	return cn.result(res), nil
+}
+
+func (cn *pgsqlConn) QueryContext(ctx context.Context, q string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) > 0 {
+		q = pgsqlRebind(q)
+	}
+	return cn.Conn.(driver.QueryerContext).QueryContext(ctx, q, pgsqlNamedArgs(args))
+}
+
+// result fetches the last inserted ID straight away, as the connection might be handed to someone else by the time the caller gets around to asking for it
+func (cn *pgsqlConn) result(res driver.Result) driver.Result {
+	rows, err := cn.Conn.(driver.QueryerContext).QueryContext(context.Background(), "SELECT gosora_lastval()", nil)
+	if err != nil {
+		return pgsqlResult{res, 0}
+	}
+	defer rows.Close()
+	dest := make([]driver.Value, 1)
+	if rows.Next(dest) != nil {
+		return pgsqlResult{res, 0}
+	}
+	id, _ := dest[0].(int64)
+	return pgsqlResult{res, id}
+}
+
+type pgsqlStmt struct {
+	driver.Stmt
+	cn     *pgsqlConn
+	insert bool
+}
+
+func (st *pgsqlStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := st.Stmt.Exec(pgsqlArgs(args))
+	if err != nil || !st.insert {
+		return res, err
+	}
+	return st.cn.result(res), nil
+}
+
+func (st *pgsqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return st.Stmt.Query(pgsqlArgs(args))
+}
+
+type pgsqlResult struct {
+	driver.Result
+	lastID int64
+}
+
+func (r pgsqlResult) LastInsertId() (int64, error) {
+	return r.lastID, nil
+}
+
+func pgsqlArgs(args []driver.Value) []driver.Value {
+	for i, arg := range args {
+		args[i] = pgsqlArg(arg)
+	}
+	return args
+}
+
+func pgsqlNamedArgs(args []driver.NamedValue) []driver.NamedValue {
+	for i, arg := range args {
+		args[i].Value = pgsqlArg(arg.Value)
+	}
+	return args
+}
+
+// The columns which hold booleans are smallints, so we can't send them as true and false.
+// Our timestamps don't have a time zone and PostgreSQL throws away the offset rather than converting them, so they have to be in UTC like the MySQL driver sends them.
+func pgsqlArg(arg driver.Value) driver.Value {
+	switch v := arg.(type) {
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case time.Time:
+		return v.UTC()
+	}
+	return arg
+}
+
+func pgsqlIsInsert(q string) bool {
+	q = strings.TrimSpace(q)
+	return len(q) > 6 && strings.EqualFold(q[:6], "INSERT")
+}
+
+// pgsqlRebind turns the ? placeholders into $1, $2 and so on, skipping over anything in quotes
+func pgsqlRebind(q string) string {
+	if !strings.Contains(q, "?") {
+		return q
+	}
+	var sb strings.Builder
+	var quote byte
+	n := 0
+	for i := 0; i < len(q); i++ {
+		ch := q[i]
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '\'' || ch == '"':
+			quote = ch
+		case ch == '?':
+			n++
+			sb.WriteByte('$')
+			sb.WriteString(strconv.Itoa(n))
+			continue
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String()
+}
@@ -0,0 +1,734 @@
+/*
+*
+*	Query Generator Library
+*	SQLite Adapter
+*	Copyright Azareal 2020
+*
+ */
+package qgen
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SqliteDriver is the name the SQLite driver is registered under, the driver itself is only compiled in with the sqlite build tag as it requires cgo
+var SqliteDriver = "gosora_sqlite3"
+
+func init() {
+	Registry = append(Registry,
+		&SqliteAdapter{Name: "sqlite", Buffer: make(map[string]DBStmt)},
+	)
+}
+
+type SqliteAdapter struct {
+	Name        string // ? - Do we really need this? Can't we hard-code this?
+	Buffer      map[string]DBStmt
+	BufferOrder []string // Map iteration order is random, so we need this to track the order, so we don't get huge diffs every commit
+}
+
+// GetName gives you the name of the database adapter. In this case, it's sqlite
+func (a *SqliteAdapter) GetName() string {
+	return a.Name
+}
+
+func (a *SqliteAdapter) GetStmt(name string) DBStmt {
+	return a.Buffer[name]
+}
+
+func (a *SqliteAdapter) GetStmts() map[string]DBStmt {
+	return a.Buffer
+}
+
+// BuildConn opens the database file named by config["name"], it's created if it doesn't exist yet
+func (a *SqliteAdapter) BuildConn(config map[string]string) (*sql.DB, error) {
+	if config["name"] == "" {
+		return nil, errors.New("You need to provide a path to the database file")
+	}
+	// WAL lets readers carry on while someone is writing and immediate transactions stop two writers from deadlocking each other when they both try to upgrade their locks
+	db, err := sql.Open(SqliteDriver, "file:"+config["name"]+"?_foreign_keys=1&_busy_timeout=10000&_journal_mode=WAL&_txlock=immediate")
+	if err != nil {
+		return db, err
+	}
+	// Make sure that the file can be opened
+	return db, db.Ping()
+}
+
+func (a *SqliteAdapter) DbVersion() string {
+	return "SELECT sqlite_version()"
+}
+
+func (a *SqliteAdapter) DropTable(name, table string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	q := "DROP TABLE IF EXISTS \"" + table + "\";"
+	a.pushStatement(name, "drop-table", q)
+	return q, nil
+}
+
+// SQLite doesn't have charsets or collations in the same sense as MySQL, everything is UTF-8, so those are ignored
+func (a *SqliteAdapter) CreateTable(name, table, charset, collation string, cols []DBTableColumn, keys []DBTableKey) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if len(cols) == 0 {
+		return "", errors.New("You can't have a table with no columns")
+	}
+
+	// Auto-increment columns have to be declared as the primary key inline, so we need to skip the key for them further down
+	var autoCol string
+	q := "CREATE TABLE \"" + table + "\" ("
+	for _, col := range cols {
+		col, size, end := a.parseColumn(col)
+		if col.AutoIncrement {
+			autoCol = col.Name
+		}
+		q += "\n\t\"" + col.Name + "\" " + col.Type + size + end + ","
+	}
+
+	for _, key := range keys {
+		switch key.Type {
+		case "primary":
+			if key.Columns == autoCol {
+				continue
+			}
+			q += "\n\tPRIMARY KEY(" + a.quoteList(key.Columns) + "),"
+		case "unique":
+			q += "\n\tUNIQUE(" + a.quoteList(key.Columns) + "),"
+		case "foreign":
+			cols := strings.Split(key.Columns, ",")
+			q += "\n\tFOREIGN KEY(\"" + cols[0] + "\") REFERENCES \"" + key.FTable + "\"(\"" + cols[1] + "\")"
+			if key.Cascade {
+				q += " ON DELETE CASCADE"
+			}
+			q += ","
+		case "fulltext":
+			// SQLite only has full-text search through virtual tables, so the searcher falls back to plain matching
+			continue
+		default:
+			return "", errors.New("Unknown key type '" + key.Type + "'")
+		}
+	}
+
+	q = q[0:len(q)-1] + "\n);"
+	a.pushStatement(name, "create-table", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) parseColumn(col DBTableColumn) (ocol DBTableColumn, size, end string) {
+	if col.Type == "createdAt" {
+		col.Type = "datetime"
+	} else if col.Type == "json" {
+		col.Type = "text"
+	}
+	if col.AutoIncrement {
+		// SQLite will only auto-increment a column which is declared exactly like this
+		col.Type = "integer"
+		return col, "", " PRIMARY KEY AUTOINCREMENT not null"
+	}
+	if col.Size > 0 {
+		size = "(" + strconv.Itoa(col.Size) + ")"
+	}
+
+	if col.Default != "" {
+		end = " DEFAULT "
+		if a.stringyType(col.Type) && col.Default != "''" {
+			end += "'" + col.Default + "'"
+		} else {
+			end += col.Default
+		}
+	}
+	if col.Null {
+		end += " null"
+	} else {
+		end += " not null"
+	}
+	return col, size, end
+}
+
+func (a *SqliteAdapter) AddColumn(name, table string, col DBTableColumn, key *DBTableKey) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if key != nil {
+		return "", errors.New("SQLite can't add keys to existing tables")
+	}
+	col, size, end := a.parseColumn(col)
+	q := "ALTER TABLE \"" + table + "\" ADD COLUMN \"" + col.Name + "\" " + col.Type + size + end
+	a.pushStatement(name, "add-column", q)
+	return q, nil
+}
+
+// The version of SQLite bundled with the driver doesn't support dropping columns
+func (a *SqliteAdapter) DropColumn(name, table, colName string) (string, error) {
+	return "", errors.New("SQLite doesn't support dropping columns")
+}
+
+func (a *SqliteAdapter) RenameColumn(name, table, oldName, newName string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	q := "ALTER TABLE \"" + table + "\" RENAME COLUMN \"" + oldName + "\" TO \"" + newName + "\";"
+	a.pushStatement(name, "rename-column", q)
+	return q, nil
+}
+
+// SQLite would need the table to be rebuilt to do this, which is something we can't do without knowing the rest of the table
+func (a *SqliteAdapter) ChangeColumn(name, table, colName string, col DBTableColumn) (string, error) {
+	return "", errors.New("SQLite doesn't support changing columns")
+}
+
+func (a *SqliteAdapter) SetDefaultColumn(name, table, colName, colType, defaultStr string) (string, error) {
+	return "", errors.New("SQLite doesn't support changing the default value of a column")
+}
+
+func (a *SqliteAdapter) AddIndex(name, table, iname, colname string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if iname == "" {
+		return "", errors.New("You need a name for the index")
+	}
+	if colname == "" {
+		return "", errors.New("You need a name for the column")
+	}
+	// Index names are shared by every table in SQLite, so the table name has to be a part of it
+	q := "CREATE INDEX \"i_" + table + "_" + iname + "\" ON \"" + table + "\"(\"" + colname + "\")"
+	a.pushStatement(name, "add-index", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) AddKey(name, table, cols string, key DBTableKey) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if cols == "" {
+		return "", errors.New("You need to specify columns")
+	}
+	if key.Type != "unique" {
+		return "", errors.New("Only unique keys are supported by AddKey on SQLite")
+	}
+	q := "CREATE UNIQUE INDEX \"u_" + table + "_" + strings.Replace(cols, ",", "_", -1) + "\" ON \"" + table + "\"(" + a.quoteList(cols) + ")"
+	a.pushStatement(name, "add-key", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) RemoveIndex(name, table, iname string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if iname == "" {
+		return "", errors.New("You need a name for the index")
+	}
+	q := "DROP INDEX IF EXISTS \"" + iname + "\""
+	a.pushStatement(name, "remove-index", q)
+	return q, nil
+}
+
+// Foreign keys can only be declared when the table is created in SQLite
+func (a *SqliteAdapter) AddForeignKey(name, table, column, ftable, fcolumn string, cascade bool) (out string, e error) {
+	return "", errors.New("SQLite can't add foreign keys to existing tables")
+}
+
+func (a *SqliteAdapter) SimpleInsert(name, table, cols, fields string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	q := "INSERT INTO \"" + table + "\""
+	if cols != "" {
+		q += "(" + a.buildColumns(cols) + ") VALUES (" + a.buildFields(fields) + ")"
+	} else {
+		q += " DEFAULT VALUES"
+	}
+	a.pushStatement(name, "insert", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) SimpleBulkInsert(name, table, cols string, fieldSet []string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	q := "INSERT INTO \"" + table + "\""
+	if cols != "" {
+		q += "(" + a.buildColumns(cols) + ") VALUES "
+		for i, fields := range fieldSet {
+			if i != 0 {
+				q += ","
+			}
+			q += "(" + a.buildFields(fields) + ")"
+		}
+	} else {
+		q += " DEFAULT VALUES"
+	}
+	a.pushStatement(name, "bulk-insert", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) buildFields(fields string) (q string) {
+	for _, field := range processFields(fields) {
+		nameLen := len(field.Name)
+		if field.Name == "\"\"" {
+			field.Name = "''"
+		} else if field.Name[0] == '"' && field.Name[nameLen-1] == '"' && nameLen >= 3 {
+			field.Name = "'" + field.Name[1:nameLen-1] + "'"
+		} else if field.Name[0] == '\'' && field.Name[nameLen-1] == '\'' && nameLen >= 3 {
+			field.Name = "'" + strings.Replace(field.Name[1:nameLen-1], "'", "''", -1) + "'"
+		}
+		q += field.Name + ","
+	}
+	if q != "" {
+		q = q[0 : len(q)-1]
+	}
+	return q
+}
+
+func (a *SqliteAdapter) buildColumns(cols string) (q string) {
+	if cols == "" {
+		return ""
+	}
+	// Escape the column names, just in case we've used a reserved keyword
+	for _, col := range processColumns(cols) {
+		if col.Type == TokenFunc {
+			q += col.Left + ","
+		} else {
+			q += "\"" + col.Left + "\","
+		}
+	}
+	return q[0 : len(q)-1]
+}
+
+func (a *SqliteAdapter) buildSet(set string) (q string) {
+	for _, item := range processSet(set) {
+		q += "\"" + item.Column + "\"="
+		for _, token := range item.Expr {
+			switch token.Type {
+			case TokenFunc, TokenOp, TokenNumber, TokenSub, TokenOr:
+				q += " " + token.Contents
+			case TokenColumn:
+				q += " " + a.quote(token.Contents)
+			case TokenString:
+				q += " '" + token.Contents + "'"
+			}
+		}
+		q += ","
+	}
+	return q[0 : len(q)-1]
+}
+
+func (a *SqliteAdapter) SimpleUpdate(up *updatePrebuilder) (string, error) {
+	if up.table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if up.set == "" {
+		return "", errors.New("You need to set data in this update statement")
+	}
+	whereStr, err := a.buildFlexiWhere(up.where, up.dateCutoff)
+	if err != nil {
+		return "", err
+	}
+	q := "UPDATE \"" + up.table + "\" SET " + a.buildSet(up.set) + whereStr
+	a.pushStatement(up.name, "update", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) SimpleUpdateSelect(up *updatePrebuilder) (string, error) {
+	sel := up.whereSubQuery
+	whereStr, err := a.buildWhere(sel.where)
+	if err != nil {
+		return "", err
+	}
+	q := "UPDATE \"" + up.table + "\" SET " + a.buildSet(up.set) + " WHERE (SELECT" + a.buildJoinColumns(sel.columns) + " FROM \"" + sel.table + "\"" + whereStr + a.buildOrderby(sel.orderby) + a.buildLimit(sel.limit) + ")"
+	a.pushStatement(up.name, "update", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) SimpleDelete(name, table, where string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if where == "" {
+		return "", errors.New("You need to specify what data you want to delete")
+	}
+	whereStr, err := a.buildWhere(where)
+	if err != nil {
+		return "", err
+	}
+	q := "DELETE FROM \"" + table + "\"" + whereStr
+	a.pushStatement(name, "delete", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) ComplexDelete(b *deletePrebuilder) (string, error) {
+	if b.table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if b.where == "" && b.dateCutoff == nil {
+		return "", errors.New("You need to specify what data you want to delete")
+	}
+	whereStr, err := a.buildFlexiWhere(b.where, b.dateCutoff)
+	if err != nil {
+		return "", err
+	}
+	q := "DELETE FROM \"" + b.table + "\"" + whereStr
+	a.pushStatement(b.name, "delete", q)
+	return q, nil
+}
+
+// We don't want to accidentally wipe tables, so we'll have a separate method for purging tables instead
+func (a *SqliteAdapter) Purge(name, table string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	q := "DELETE FROM \"" + table + "\""
+	a.pushStatement(name, "purge", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) buildWhere(where string) (q string, err error) {
+	return a.buildFlexiWhere(where, nil)
+}
+
+// buildDateCutoff turns MySQL style intervals into the modifiers SQLite's datetime() understands, it doesn't know about weeks, so those are turned into days
+func (a *SqliteAdapter) buildDateCutoff(dc *dateCutoff) string {
+	unit, mul := dc.Unit, 1
+	if unit == "week" {
+		unit, mul = "day", 7
+	}
+	col := a.quote(dc.Column)
+	switch dc.Type {
+	case 0:
+		return col + " BETWEEN datetime('now','-" + strconv.Itoa(dc.Quantity*mul) + " " + unit + "') AND datetime('now')"
+	case 11:
+		q := "?"
+		if mul != 1 {
+			q = "(?*" + strconv.Itoa(mul) + ")"
+		}
+		return col + "<datetime('now','-'||" + q + "||' " + unit + "')"
+	}
+	return col + "<datetime('now','-" + strconv.Itoa(dc.Quantity*mul) + " " + unit + "')"
+}
+
+func (a *SqliteAdapter) buildFlexiWhere(where string, dateCutoff *dateCutoff) (q string, err error) {
+	if len(where) == 0 && dateCutoff == nil {
+		return "", nil
+	}
+	q = " WHERE"
+	if dateCutoff != nil {
+		q += " " + a.buildDateCutoff(dateCutoff)
+		if len(where) != 0 {
+			q += " AND"
+		}
+	}
+	for i, loc := range processWhere(where) {
+		if i != 0 {
+			q += " AND"
+		}
+		for _, token := range loc.Expr {
+			switch token.Type {
+			case TokenFunc, TokenOp, TokenNumber, TokenSub, TokenOr, TokenNot, TokenLike:
+				q += " " + token.Contents
+			case TokenColumn:
+				q += " " + a.quote(token.Contents)
+			case TokenString:
+				q += " '" + token.Contents + "'"
+			default:
+				return q, errors.New("This token doesn't exist o_o")
+			}
+		}
+	}
+	return q, nil
+}
+
+func (a *SqliteAdapter) buildOrderby(orderby string) (q string) {
+	if len(orderby) == 0 {
+		return ""
+	}
+	q = " ORDER BY "
+	for i, col := range processOrderby(orderby) {
+		if i != 0 {
+			q += ","
+		}
+		q += a.quote(col.Column) + " " + strings.ToUpper(col.Order)
+	}
+	return q
+}
+
+func (a *SqliteAdapter) buildLimit(limit string) (q string) {
+	if limit != "" {
+		q = " LIMIT " + limit
+	}
+	return q
+}
+
+func (a *SqliteAdapter) SimpleSelect(name, table, cols, where, orderby, limit string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if len(cols) == 0 {
+		return "", errors.New("No columns found for SimpleSelect")
+	}
+	whereStr, err := a.buildWhere(where)
+	if err != nil {
+		return "", err
+	}
+
+	q := "SELECT "
+	for i, col := range strings.Split(strings.TrimSpace(cols), ",") {
+		if i != 0 {
+			q += ","
+		}
+		q += "\"" + strings.TrimSpace(col) + "\""
+	}
+	q += " FROM \"" + table + "\"" + whereStr + a.buildOrderby(orderby) + a.buildLimit(limit)
+	a.pushStatement(name, "select", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) ComplexSelect(preBuilder *selectPrebuilder) (out string, err error) {
+	out, err = a.complexSelect(preBuilder)
+	a.pushStatement(preBuilder.name, "select", out)
+	return out, err
+}
+
+func (a *SqliteAdapter) complexSelect(preBuilder *selectPrebuilder) (string, error) {
+	if preBuilder.table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	if len(preBuilder.columns) == 0 {
+		return "", errors.New("No columns found for ComplexSelect")
+	}
+
+	q := "SELECT" + a.buildJoinColumns(preBuilder.columns) + " FROM \"" + preBuilder.table + "\""
+	// TODO: Let callers have a Where() and a InQ()
+	if preBuilder.inChain != nil {
+		sub, err := a.complexSelect(preBuilder.inChain)
+		if err != nil {
+			return q, err
+		}
+		q += " WHERE \"" + preBuilder.inColumn + "\" IN(" + sub + ")"
+	} else {
+		whereStr, err := a.buildFlexiWhere(preBuilder.where, preBuilder.dateCutoff)
+		if err != nil {
+			return q, err
+		}
+		q += whereStr
+	}
+	return q + a.buildOrderby(preBuilder.orderby) + a.buildLimit(preBuilder.limit), nil
+}
+
+func (a *SqliteAdapter) simpleJoin(name, joinType, table1, table2, cols, joiners, where, orderby, limit string) (string, error) {
+	if table1 == "" {
+		return "", errors.New("You need a name for the left table")
+	}
+	if table2 == "" {
+		return "", errors.New("You need a name for the right table")
+	}
+	if len(cols) == 0 {
+		return "", errors.New("No columns found for Simple" + joinType + "Join")
+	}
+	if len(joiners) == 0 {
+		return "", errors.New("No joiners found for Simple" + joinType + "Join")
+	}
+	whereStr, err := a.buildJoinWhere(where)
+	if err != nil {
+		return "", err
+	}
+	q := "SELECT" + a.buildJoinColumns(cols) + " FROM " + a.buildJoinTable(table1) + " " + strings.ToUpper(joinType) + " JOIN " + a.buildJoinTable(table2) + " ON " + a.buildJoiners(joiners) + whereStr + a.buildOrderby(orderby) + a.buildLimit(limit)
+	return q, nil
+}
+
+func (a *SqliteAdapter) SimpleLeftJoin(name, table1, table2, cols, joiners, where, orderby, limit string) (string, error) {
+	q, err := a.simpleJoin(name, "Left", table1, table2, cols, joiners, where, orderby, limit)
+	if err != nil {
+		return "", err
+	}
+	a.pushStatement(name, "select", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) SimpleInnerJoin(name, table1, table2, cols, joiners, where, orderby, limit string) (string, error) {
+	q, err := a.simpleJoin(name, "Inner", table1, table2, cols, joiners, where, orderby, limit)
+	if err != nil {
+		return "", err
+	}
+	a.pushStatement(name, "select", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) SimpleInsertSelect(name string, ins DBInsert, sel DBSelect) (string, error) {
+	whereStr, err := a.buildWhere(sel.Where)
+	if err != nil {
+		return "", err
+	}
+	q := "INSERT INTO \"" + ins.Table + "\"(" + a.buildColumns(ins.Columns) + ") SELECT" + a.buildJoinColumns(sel.Columns) + " FROM \"" + sel.Table + "\"" + whereStr + a.buildOrderby(sel.Orderby) + a.buildLimit(sel.Limit)
+	a.pushStatement(name, "insert", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) simpleInsertJoin(name, joinType string, ins DBInsert, sel DBJoin) (string, error) {
+	whereStr, err := a.buildJoinWhere(sel.Where)
+	if err != nil {
+		return "", err
+	}
+	q := "INSERT INTO \"" + ins.Table + "\"(" + a.buildColumns(ins.Columns) + ") SELECT" + a.buildJoinColumns(sel.Columns) + " FROM " + a.buildJoinTable(sel.Table1) + " " + joinType + " JOIN " + a.buildJoinTable(sel.Table2) + " ON " + a.buildJoiners(sel.Joiners) + whereStr + a.buildOrderby(sel.Orderby) + a.buildLimit(sel.Limit)
+	a.pushStatement(name, "insert", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) SimpleInsertLeftJoin(name string, ins DBInsert, sel DBJoin) (string, error) {
+	return a.simpleInsertJoin(name, "LEFT", ins, sel)
+}
+
+func (a *SqliteAdapter) SimpleInsertInnerJoin(name string, ins DBInsert, sel DBJoin) (string, error) {
+	return a.simpleInsertJoin(name, "INNER", ins, sel)
+}
+
+func (a *SqliteAdapter) buildJoinTable(table string) string {
+	halves := strings.Split(strings.Replace(table, " as ", " AS ", -1), " AS ")
+	if len(halves) == 2 {
+		return "\"" + strings.TrimSpace(halves[0]) + "\" AS \"" + strings.TrimSpace(halves[1]) + "\""
+	}
+	return "\"" + table + "\""
+}
+
+func (a *SqliteAdapter) buildJoiners(joiners string) (q string) {
+	for _, j := range processJoiner(joiners) {
+		q += "\"" + j.LeftTable + "\".\"" + j.LeftColumn + "\" " + j.Operator + " \"" + j.RightTable + "\".\"" + j.RightColumn + "\" AND "
+	}
+	// Remove the trailing AND
+	return q[0 : len(q)-5]
+}
+
+func (a *SqliteAdapter) buildJoinWhere(where string) (q string, err error) {
+	return a.buildFlexiWhere(where, nil)
+}
+
+func (a *SqliteAdapter) buildJoinColumns(cols string) (q string) {
+	for _, col := range processColumns(cols) {
+		// TODO: Error if [0] doesn't exist
+		firstChar := col.Left[0]
+		if firstChar == '\'' {
+			col.Type = TokenString
+		} else {
+			_, err := strconv.Atoi(string(firstChar))
+			if err == nil {
+				col.Type = TokenNumber
+			}
+		}
+
+		// Escape the column names, just in case we've used a reserved keyword
+		source := col.Left
+		if col.Table != "" {
+			source = "\"" + col.Table + "\".\"" + source + "\""
+		} else if col.Type != TokenFunc && col.Type != TokenNumber && col.Type != TokenSub && col.Type != TokenString {
+			source = "\"" + source + "\""
+		}
+
+		var alias string
+		if col.Alias != "" {
+			alias = " AS \"" + col.Alias + "\""
+		}
+		q += " " + source + alias + ","
+	}
+	return q[0 : len(q)-1]
+}
+
+func (a *SqliteAdapter) SimpleCount(name, table, where, limit string) (string, error) {
+	if table == "" {
+		return "", errors.New("You need a name for this table")
+	}
+	whereStr, err := a.buildWhere(where)
+	if err != nil {
+		return "", err
+	}
+	q := "SELECT COUNT(*) FROM \"" + table + "\"" + whereStr + a.buildLimit(limit)
+	a.pushStatement(name, "select", q)
+	return q, nil
+}
+
+func (a *SqliteAdapter) Builder() *prebuilder {
+	return &prebuilder{a}
+}
+
+func (a *SqliteAdapter) Write() error {
+	var stmts, body string
+	for _, name := range a.BufferOrder {
+		if name[0] == '_' {
+			continue
+		}
+		stmt := a.Buffer[name]
+		// ? - Table creation might be a little complex for Go to do outside a SQL file :(
+		if stmt.Type != "create-table" {
+			stmts += "\t" + name + " *sql.Stmt\n"
+			body += `
+	common.DebugLog("Preparing ` + name + ` statement.")
+	stmts.` + name + `, err = db.Prepare("` + strings.Replace(stmt.Contents, "\"", "\\\"", -1) + `")
+	if err != nil {
+		log.Print("Error in ` + name + ` statement.")
+		return err
+	}
+	`
+		}
+	}
+
+	// TODO: Move these custom queries out of this file
+	out := `// +build sqlite
+
+/* This file was generated by Gosora's Query Generator. Please try to avoid modifying this file, as it might change at any time. */
+
+package main
+
+import "log"
+import "database/sql"
+import "github.com/Azareal/Gosora/common"
+
+// nolint
+type Stmts struct {
+` + stmts + `
+	getActivityFeedByWatcher *sql.Stmt
+	getActivityCountByWatcher *sql.Stmt
+
+	Mocks bool
+}
+
+// nolint
+func _gen_sqlite() (err error) {
+	common.DebugLog("Building the generated statements")
+` + body + `
+	return nil
+}
+`
+	return writeFile("./gen_sqlite.go", out)
+}
+
+// Internal methods, not exposed in the interface
+func (a *SqliteAdapter) pushStatement(name, stype, q string) {
+	if name == "" {
+		return
+	}
+	a.Buffer[name] = DBStmt{q, stype}
+	a.BufferOrder = append(a.BufferOrder, name)
+}
+
+// quote escapes an identifier, which may be qualified with the name of a table
+func (a *SqliteAdapter) quote(ident string) string {
+	return "\"" + strings.Replace(ident, ".", "\".\"", -1) + "\""
+}
+
+func (a *SqliteAdapter) quoteList(idents string) (q string) {
+	for i, ident := range strings.Split(idents, ",") {
+		if i != 0 {
+			q += ","
+		}
+		q += "\"" + strings.TrimSpace(ident) + "\""
+	}
+	return q
+}
+
+func (a *SqliteAdapter) stringyType(ct string) bool {
+	ct = strings.ToLower(ct)
+	return ct == "varchar" || ct == "char" || ct == "text" || ct == "datetime" || ct == "timestamp" || ct == "date" || ct == "time"
+}
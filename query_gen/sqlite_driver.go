@@ -0,0 +1,35 @@
+// +build sqlite
+
+package qgen
+
+import (
+	"database/sql"
+	"math"
+	"strings"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// SQLite is missing a few of the MySQL functions which we use in our queries, so we plug in our own versions
+func init() {
+	sql.Register(SqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			err := conn.RegisterFunc("UTC_TIMESTAMP", func() string {
+				return time.Now().UTC().Format("2006-01-02 15:04:05")
+			}, false)
+			if err != nil {
+				return err
+			}
+			err = conn.RegisterFunc("CONCAT", func(args ...string) string {
+				return strings.Join(args, "")
+			}, true)
+			if err != nil {
+				return err
+			}
+			return conn.RegisterFunc("FLOOR", func(f float64) int64 {
+				return int64(math.Floor(f))
+			}, true)
+		},
+	})
+}
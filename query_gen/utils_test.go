@@ -1,6 +1,7 @@
 package qgen
 
 import (
+	"database/sql/driver"
 	"strings"
 	"testing"
 )
@@ -71,3 +72,56 @@ func TestMySQLBuildWhere(t *testing.T) {
 	reap("uid = '0'", " WHERE `uid`= '0'")
 	reap("uid=0", " WHERE `uid`= 0 ")
 }
+
+func TestPgsqlBuildWhere(t *testing.T) {
+	a := &PgsqlAdapter{Name: "pgsql", Buffer: make(map[string]DBStmt)}
+	reap := func(wh, ex string) {
+		res, err := a.buildWhere(wh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res != ex {
+			t.Fatalf("build where mismatch: '%+v' - '%+v'\n", ex, res)
+		}
+	}
+	reap("uid = 0", " WHERE \"uid\" = 0")
+	reap("uid = '0'", " WHERE \"uid\" = '0'")
+	reap("parentID=? AND topics.deleted=0", " WHERE \"parentid\" = ? AND \"topics\".\"deleted\" = 0")
+	reap("UTC_TIMESTAMP() > revert_at", " WHERE (NOW() AT TIME ZONE 'UTC') > \"revert_at\"")
+
+	if res := a.buildLimit("?,?"); res != " OFFSET ? LIMIT ?" {
+		t.Fatalf("build limit mismatch: '%+v'\n", res)
+	}
+}
+
+func TestPgsqlRebind(t *testing.T) {
+	reap := func(q, ex string) {
+		if res := pgsqlRebind(q); res != ex {
+			t.Fatalf("rebind mismatch: '%+v' - '%+v'\n", ex, res)
+		}
+	}
+	reap("SELECT 1", "SELECT 1")
+	reap("UPDATE \"users\" SET \"name\"=? WHERE \"uid\"=?", "UPDATE \"users\" SET \"name\"=$1 WHERE \"uid\"=$2")
+	reap("SELECT \"a?\" FROM t WHERE b='?' AND c=? AND d='it''s?'", "SELECT \"a?\" FROM t WHERE b='?' AND c=$1 AND d='it''s?'")
+}
+
+type prepConn struct {
+	driver.Conn
+	q string
+}
+
+func (cn *prepConn) Prepare(q string) (driver.Stmt, error) {
+	cn.q = q
+	return nil, nil
+}
+
+func TestPgsqlPrepare(t *testing.T) {
+	cn := &prepConn{}
+	_, err := (&pgsqlConn{cn}).Prepare("UPDATE t SET a=';\n' WHERE b=?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ex := "UPDATE t SET a=';\n' WHERE b=$1"; cn.q != ex {
+		t.Fatalf("prepare mismatch: '%+v' - '%+v'\n", ex, cn.q)
+	}
+}
@@ -6,6 +6,7 @@ func routes(r *Router) {
 	r.Add(View("routes.CustomPage", "/pages/", "extraData"))
 	r.Add(View("routes.ForumList", "/forums/" /*,"&forums"*/))
 	r.Add(View("routes.ViewForum", "/forum/", "extraData"))
+	r.Add(View("routes.TopicListByTag", "/tags/", "extraData"))
 	r.Add(AnonAction("routes.ChangeTheme", "/theme/"))
 	r.Add(
 		View("routes.ShowAttachment", "/attachs/", "extraData").Before("ParseForm").NoGzip().NoHeader(),
@@ -15,6 +16,9 @@ func routes(r *Router) {
 		View("routeAPI", "/api/"),
 		View("routeAPIPhrases", "/api/phrases/"), // TODO: Be careful with exposing the panel phrases here
 		View("routes.APIMe", "/api/me/"),
+		View("routes.APIUserFields", "/api/user/fields/"),
+		View("routes.APITagSuggest", "/api/tags/"),
+		View("routes.APIDraft", "/api/draft/"),
 		View("routeJSAntispam", "/api/watches/"),
 	).NoHeader()
 	r.AddGroup(apiGroup)
@@ -30,6 +34,9 @@ func routes(r *Router) {
 		View("routes.TopicListMostViewed", "/topics/most-viewed/"),
 		View("routes.TopicListWeekViews", "/topics/week-views/"),
 		MView("routes.CreateTopic", "/topics/create/", "extraData"),
+		MView("routes.ScheduledTopics", "/topics/scheduled/"),
+		Action("routes.ScheduledTopicDeleteSubmit", "/topics/scheduled/delete/submit/", "extraData"),
+		Action("routes.ScheduledTopicPublishSubmit", "/topics/scheduled/publish/submit/", "extraData"),
 	)
 	r.AddGroup(topicGroup)
 
@@ -59,6 +66,16 @@ func userRoutes() *RouteGroup {
 			Action("UsernameSubmit", "/username/submit/"), // TODO: Full test this
 			MView("Privacy", "/privacy/"),
 			Action("PrivacySubmit", "/privacy/submit/"),
+			MView("Fields", "/fields/"),
+			Action("FieldsSubmit", "/fields/submit/"),
+			MView("Theme", "/theme/"),
+			Action("ThemeSubmit", "/theme/submit/"),
+			MView("Lang", "/lang/"),
+			Action("LangSubmit", "/lang/submit/"),
+			MView("Data", "/data/"),
+			Action("DataExportSubmit", "/data/export/submit/"),
+			Action("DataDeleteSubmit", "/data/delete/submit/"),
+			Action("DataDeleteCancelSubmit", "/data/delete/cancel/submit/"),
 			MView("MFA", "/mfa/"),
 			MView("MFASetup", "/mfa/setup/"),
 			Action("MFASetupSubmit", "/mfa/setup/submit/"),
@@ -84,6 +101,9 @@ func userRoutes() *RouteGroup {
 
 		MView("routes.AccountLogins", "/user/edit/logins/"),
 		MView("routes.AccountBlocked", "/user/edit/blocked/"),
+		MView("routes.AccountDrafts", "/user/edit/drafts/"),
+		Action("routes.AccountDraftsDeleteSubmit", "/user/edit/drafts/delete/submit/", "extraData"),
+		Action("routes.DraftSaveSubmit", "/user/drafts/save/submit/"),
 
 		MView("routes.LevelList", "/user/levels/"),
 		//MView("routes.LevelRankings", "/user/rankings/"),
@@ -122,15 +142,24 @@ func topicRoutes() *RouteGroup {
 		UploadAction("routes.CreateTopicSubmit", "/topic/create/submit/").MaxSizeVar("int(c.Config.MaxRequestSize)"),
 		Action("routes.EditTopicSubmit", "/topic/edit/submit/", "extraData"),
 		Action("routes.DeleteTopicSubmit", "/topic/delete/submit/").LitBefore("req.URL.Path += extraData"),
+		Action("routes.RestoreTopicSubmit", "/topic/restore/submit/", "extraData"),
 		Action("routes.StickTopicSubmit", "/topic/stick/submit/", "extraData"),
 		Action("routes.UnstickTopicSubmit", "/topic/unstick/submit/", "extraData"),
 		Action("routes.LockTopicSubmit", "/topic/lock/submit/").LitBefore("req.URL.Path += extraData"),
 		Action("routes.UnlockTopicSubmit", "/topic/unlock/submit/", "extraData"),
 		Action("routes.MoveTopicSubmit", "/topic/move/submit/", "extraData"),
+		Action("routes.MergeTopicSubmit", "/topic/merge/submit/", "extraData"),
+		Action("routes.SplitTopicSubmit", "/topic/split/submit/", "extraData"),
+		Action("routes.MoveRepliesSubmit", "/topic/move-replies/submit/", "extraData"),
 		Action("routes.LikeTopicSubmit", "/topic/like/submit/", "extraData"),
 		Action("routes.UnlikeTopicSubmit", "/topic/unlike/submit/", "extraData"),
 		UploadAction("routes.AddAttachToTopicSubmit", "/topic/attach/add/submit/", "extraData").MaxSizeVar("int(c.Config.MaxRequestSize)"),
 		Action("routes.RemoveAttachFromTopicSubmit", "/topic/attach/remove/submit/", "extraData"),
+		View("routes.TopicRevisions", "/topic/revisions/", "extraData"),
+		Action("routes.TopicRevertSubmit", "/topic/revert/submit/", "extraData"),
+		MView("routes.TopicTimedActions", "/topic/timed/", "extraData"),
+		Action("routes.TopicTimedActionCreateSubmit", "/topic/timed/create/submit/", "extraData"),
+		Action("routes.TopicTimedActionDeleteSubmit", "/topic/timed/delete/submit/", "extraData"),
 	)
 }
 
@@ -140,12 +169,19 @@ func replyRoutes() *RouteGroup {
 		UploadAction("routes.CreateReplySubmit", "/reply/create/").MaxSizeVar("int(c.Config.MaxRequestSize)"), // TODO: Rename the route so it's /reply/create/submit/
 		Action("routes.ReplyEditSubmit", "/reply/edit/submit/", "extraData"),
 		Action("routes.ReplyDeleteSubmit", "/reply/delete/submit/", "extraData"),
+		Action("routes.ReplyRestoreSubmit", "/reply/restore/submit/", "extraData"),
 		Action("routes.ReplyLikeSubmit", "/reply/like/submit/", "extraData"),
 		Action("routes.ReplyUnlikeSubmit", "/reply/unlike/submit/", "extraData"),
+		Action("routes.ReplyUpvoteSubmit", "/reply/upvote/submit/", "extraData"),
+		Action("routes.ReplyDownvoteSubmit", "/reply/downvote/submit/", "extraData"),
+		Action("routes.ReplyAcceptSubmit", "/reply/accept/submit/", "extraData"),
+		Action("routes.ReplyUnacceptSubmit", "/reply/unaccept/submit/", "extraData"),
 		//MemberView("routes.ReplyEdit","/reply/edit/","extraData"), // No js fallback
 		//MemberView("routes.ReplyDelete","/reply/delete/","extraData"), // No js confirmation page? We could have a confirmation modal for the JS case
 		UploadAction("routes.AddAttachToReplySubmit", "/reply/attach/add/submit/", "extraData").MaxSizeVar("int(c.Config.MaxRequestSize)"),
 		Action("routes.RemoveAttachFromReplySubmit", "/reply/attach/remove/submit/", "extraData"),
+		View("routes.ReplyRevisions", "/reply/revisions/", "extraData"),
+		Action("routes.ReplyRevertSubmit", "/reply/revert/submit/", "extraData"),
 	)
 }
 
@@ -196,6 +232,8 @@ func panelRoutes() *RouteGroup {
 		View("panel.ForumsEdit", "/panel/forums/edit/", "extraData"),
 		Action("panel.ForumsEditSubmit", "/panel/forums/edit/submit/", "extraData"),
 		Action("panel.ForumsEditPermsSubmit", "/panel/forums/edit/perms/submit/", "extraData"),
+		Action("panel.ForumsEditModSubmit", "/panel/forums/edit/mods/submit/", "extraData"),
+		Action("panel.ForumsEditModDeleteSubmit", "/panel/forums/edit/mods/delete/submit/", "extraData"),
 		View("panel.ForumsEditPermsAdvance", "/panel/forums/edit/perms/", "extraData"),
 		Action("panel.ForumsEditPermsAdvanceSubmit", "/panel/forums/edit/perms/adv/submit/", "extraData"),
 
@@ -209,6 +247,29 @@ func panelRoutes() *RouteGroup {
 		Action("panel.WordFiltersEditSubmit", "/panel/settings/word-filters/edit/submit/", "extraData"),
 		Action("panel.WordFiltersDeleteSubmit", "/panel/settings/word-filters/delete/submit/", "extraData"),
 
+		View("panel.Phrases", "/panel/phrases/"),
+		View("panel.PhrasesEdit", "/panel/phrases/edit/", "extraData"),
+		Action("panel.PhrasesCreateSubmit", "/panel/phrases/create/submit/", "extraData"),
+		Action("panel.PhrasesEditSubmit", "/panel/phrases/edit/submit/", "extraData"),
+		Action("panel.PhrasesDeleteSubmit", "/panel/phrases/delete/submit/", "extraData"),
+
+		View("panel.ProfileFields", "/panel/profile-fields/"),
+		Action("panel.ProfileFieldsCreateSubmit", "/panel/profile-fields/create/submit/"),
+		View("panel.ProfileFieldsEdit", "/panel/profile-fields/edit/", "extraData"),
+		Action("panel.ProfileFieldsEditSubmit", "/panel/profile-fields/edit/submit/", "extraData"),
+		Action("panel.ProfileFieldsDeleteSubmit", "/panel/profile-fields/delete/submit/", "extraData"),
+
+		View("panel.TopicPrefixes", "/panel/topic-prefixes/"),
+		Action("panel.TopicPrefixesCreateSubmit", "/panel/topic-prefixes/create/submit/"),
+		View("panel.TopicPrefixesEdit", "/panel/topic-prefixes/edit/", "extraData"),
+		Action("panel.TopicPrefixesEditSubmit", "/panel/topic-prefixes/edit/submit/", "extraData"),
+		Action("panel.TopicPrefixesDeleteSubmit", "/panel/topic-prefixes/delete/submit/", "extraData"),
+
+		View("panel.Trash", "/panel/trash/"),
+		View("panel.TrashReplies", "/panel/trash/replies/"),
+		Action("panel.TrashPurgeTopicSubmit", "/panel/trash/purge/topic/submit/", "extraData"),
+		Action("panel.TrashPurgeReplySubmit", "/panel/trash/purge/reply/submit/", "extraData"),
+
 		View("panel.Pages", "/panel/pages/").Before("AdminOnly"),
 		Action("panel.PagesCreateSubmit", "/panel/pages/create/submit/").Before("AdminOnly"),
 		View("panel.PagesEdit", "/panel/pages/edit/", "extraData").Before("AdminOnly"),
@@ -217,6 +278,14 @@ func panelRoutes() *RouteGroup {
 
 		View("panel.Themes", "/panel/themes/"),
 		Action("panel.ThemesSetDefault", "/panel/themes/default/", "extraData"),
+		View("panel.ThemesEdit", "/panel/themes/edit/", "extraData"),
+		Action("panel.ThemesSettingsSubmit", "/panel/themes/settings/submit/", "extraData"),
+		Action("panel.ThemesCreateChildSubmit", "/panel/themes/create/submit/", "extraData"),
+		View("panel.ThemesExport", "/panel/themes/export/", "extraData"),
+		UploadAction("panel.ThemesImportSubmit", "/panel/themes/import/submit/").MaxSizeVar("int(c.Config.MaxRequestSize)").Before("AdminOnly"),
+		View("panel.ThemesFileEdit", "/panel/themes/file/edit/", "extraData").Before("AdminOnly"),
+		Action("panel.ThemesFileEditSubmit", "/panel/themes/file/edit/submit/", "extraData").Before("AdminOnly"),
+		Action("panel.ThemesFilePreviewSubmit", "/panel/themes/file/preview/submit/", "extraData").Before("AdminOnly"),
 		View("panel.ThemesMenus", "/panel/themes/menus/"),
 		View("panel.ThemesMenusEdit", "/panel/themes/menus/edit/", "extraData"),
 		View("panel.ThemesMenuItemEdit", "/panel/themes/menus/item/edit/", "extraData"),
@@ -273,6 +342,8 @@ func panelRoutes() *RouteGroup {
 		Action("panel.GroupsCreateSubmit", "/panel/groups/create/"),
 
 		View("panel.Backups", "/panel/backups/", "extraData").Before("SuperAdminOnly").NoGzip(), // TODO: Tests for this
+		Action("panel.BackupsCreateSubmit", "/panel/backups/create/submit/").Before("SuperAdminOnly"),
+		Action("panel.BackupsRestoreSubmit", "/panel/backups/restore/submit/", "extraData").Before("SuperAdminOnly"),
 		View("panel.LogsRegs", "/panel/logs/regs/"),
 		View("panel.LogsMod", "/panel/logs/mod/"),
 		View("panel.LogsAdmin", "/panel/logs/admin/"),
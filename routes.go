@@ -21,7 +21,6 @@ import (
 	"unicode"
 
 	c "github.com/Azareal/Gosora/common"
-	"github.com/Azareal/Gosora/common/phrases"
 )
 
 // A blank list to fill out that parameter in Page for routes which don't use it
@@ -256,13 +255,16 @@ func routeAPIPhrases(w http.ResponseWriter, r *http.Request, user *c.User) c.Rou
 		return c.PreErrorJS("You haven't requested any phrases", w, r)
 	}
 	h.Set("Cache-Control", cacheControlMaxAge) //Cache-Control: max-age=31536000
+	h.Set("Vary", "Accept-Language")
 
+	// The user might have picked a different language from the one the site is in
+	lp := c.GetLangPackByReq(r, user)
 	var etag string
 	_, ok := w.(c.GzipResponseWriter)
 	if ok {
-		etag = "\"" + strconv.FormatInt(phrases.GetCurrentLangPack().ModTime.Unix(), 10) + "-ng\""
+		etag = "\"" + lp.Name + "-" + strconv.FormatInt(lp.ModTime.Unix(), 10) + "-ng\""
 	} else {
-		etag = "\"" + strconv.FormatInt(phrases.GetCurrentLangPack().ModTime.Unix(), 10) + "-n\""
+		etag = "\"" + lp.Name + "-" + strconv.FormatInt(lp.ModTime.Unix(), 10) + "-n\""
 	}
 
 	var plist map[string]string
@@ -305,7 +307,7 @@ func routeAPIPhrases(w http.ResponseWriter, r *http.Request, user *c.User) c.Rou
 			if rerr != nil {
 				return rerr
 			}
-			pPhrases, ok := phrases.GetTmplPhrasesByPrefix(positive)
+			pPhrases, ok := lp.TmplPhrasesPrefixes[positive]
 			if !ok {
 				return c.PreErrorJS("No such prefix", w, r)
 			}
@@ -318,7 +320,7 @@ func routeAPIPhrases(w http.ResponseWriter, r *http.Request, user *c.User) c.Rou
 		if rerr != nil {
 			return rerr
 		}
-		pPhrases, ok := phrases.GetTmplPhrasesByPrefix(positives[0])
+		pPhrases, ok := lp.TmplPhrasesPrefixes[positives[0]]
 		if !ok {
 			return c.PreErrorJS("No such prefix", w, r)
 		}
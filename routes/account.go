@@ -649,6 +649,238 @@ func AccountEditPrivacySubmit(w http.ResponseWriter, r *http.Request, u *c.User)
 	return nil
 }
 
+func AccountEditFields(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c.RouteError {
+	accountEditHead("account_fields", w, r, u, h)
+	if r.FormValue("updated") == "1" {
+		h.AddNotice("account_fields_updated")
+	}
+	vals, err := c.ProfileFields.Values(u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	fields := c.ProfileFields.GetAll()
+	fList := make([]c.ProfileFieldEdit, len(fields))
+	for i, f := range fields {
+		fList[i] = c.ProfileFieldEdit{ProfileField: f}
+		if v, ok := vals[f.ID]; ok {
+			fList[i].Value = v.Value
+			fList[i].Privacy = v.Privacy
+		}
+	}
+
+	pi := c.Account{h, "fields", "account_own_edit_fields", c.AccountProfileFieldsPage{h, fList}}
+	return renderTemplate("account", w, r, h, pi)
+}
+
+func AccountEditFieldsSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	vals, err := c.ProfileFields.Values(u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	fields := c.ProfileFields.GetAll()
+	// Validate everything first, so that we don't end up with a partial update
+	newVals := make([]string, len(fields))
+	newPrivacy := make([]int, len(fields))
+	for i, f := range fields {
+		sid := strconv.Itoa(f.ID)
+		newVals[i], err = f.Validate(r.PostFormValue("field-" + sid))
+		if err != nil {
+			return c.LocalError(f.Name+": "+err.Error(), w, r, u)
+		}
+		newPrivacy[i], err = strconv.Atoi(r.PostFormValue("privacy-" + sid))
+		if err != nil || newPrivacy[i] < 0 || newPrivacy[i] > 3 {
+			return c.LocalError(f.Name+": invalid privacy setting", w, r, u)
+		}
+	}
+
+	for i, f := range fields {
+		if v, ok := vals[f.ID]; ok && v.Value == newVals[i] && v.Privacy == newPrivacy[i] {
+			continue
+		} else if !ok && newVals[i] == "" {
+			continue
+		}
+		err = c.ProfileFields.SetValue(u.ID, f.ID, newVals[i], newPrivacy[i])
+		if err != nil {
+			return c.InternalError(err, w, r)
+		}
+	}
+
+	http.Redirect(w, r, "/user/edit/fields/?updated=1", http.StatusSeeOther)
+	return nil
+}
+
+func AccountEditTheme(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c.RouteError {
+	accountEditHead("account_theme", w, r, u, h)
+	if r.FormValue("updated") == "1" {
+		h.AddNotice("account_theme_updated")
+	}
+	vals, err := c.UserThemeSettings.Get(u.ID, h.Theme.Name)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	var settings []c.AccountThemeSetting
+	for _, name := range h.Theme.UserSettingNames() {
+		setting := h.Theme.UserSettings[name]
+		opt, _ := h.Theme.UserOption(name, vals)
+		settings = append(settings, c.AccountThemeSetting{name, setting.FriendlyName, setting.Options, opt.Name})
+	}
+
+	pi := c.Account{h, "theme", "account_own_edit_theme", c.AccountThemePage{h, settings}}
+	return renderTemplate("account", w, r, h, pi)
+}
+
+func AccountEditThemeSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	theme := c.GetThemeByReq(r)
+	vals := make(map[string]string)
+	for name := range theme.UserSettings {
+		val := r.PostFormValue("setting-" + name)
+		if val == "" {
+			continue
+		}
+		if !theme.ValidUserSetting(name, val) {
+			return c.LocalError(theme.UserSettings[name].FriendlyName+": "+c.ErrThemeUserSetting.Error(), w, r, u)
+		}
+		// There's no need to hang onto the defaults, guests get those anyway
+		if val != theme.UserSettings[name].Options[0].Name {
+			vals[name] = val
+		}
+	}
+	err := c.UserThemeSettings.Set(u.ID, theme.Name, vals)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/user/edit/theme/?updated=1", http.StatusSeeOther)
+	return nil
+}
+
+func AccountEditLang(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c.RouteError {
+	accountEditHead("account_lang", w, r, u, h)
+	if r.FormValue("updated") == "1" {
+		h.AddNotice("account_lang_updated")
+	}
+	var langs []c.AccountLangItem
+	for _, lp := range p.GetLangPacks() {
+		// The languages are named in the one the page is in, so the user can find their way back if they pick the wrong one
+		name, ok := h.LangPack().HumanLanguages[lp.IsoCode]
+		if !ok {
+			name = lp.Name
+		}
+		langs = append(langs, c.AccountLangItem{lp.Name, name})
+	}
+
+	pi := c.Account{h, "lang", "account_own_edit_lang", c.AccountLangPage{h, langs, u.Lang}}
+	return renderTemplate("account", w, r, h, pi)
+}
+
+func AccountEditLangSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	err := u.SetLang(r.PostFormValue("lang"))
+	if err == p.ErrNoLangPack {
+		return c.LocalError(err.Error(), w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/user/edit/lang/?updated=1", http.StatusSeeOther)
+	return nil
+}
+
+func AccountEditData(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c.RouteError {
+	accountEditHead("account_data", w, r, u, h)
+	switch {
+	case r.FormValue("delete_scheduled") == "1":
+		h.AddNotice("account_data_delete_scheduled")
+	case r.FormValue("delete_cancelled") == "1":
+		h.AddNotice("account_data_delete_cancelled")
+	}
+	sd, err := c.SelfDeletes.Get(u.ID)
+	if err == sql.ErrNoRows {
+		sd = nil
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	pi := c.Account{h, "data", "account_own_edit_data", c.AccountDataPage{h, !c.Config.DisableSelfDelete && !u.IsAdmin, c.SelfDeleteGrace(), sd}}
+	return renderTemplate("account", w, r, h, pi)
+}
+
+func AccountEditDataExportSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	_, ferr := c.SimpleUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	h := w.Header()
+	h.Set("Content-Type", "application/zip")
+	h.Set("Content-Disposition", "attachment; filename=gosora_data_"+strconv.Itoa(u.ID)+".zip")
+	// The headers are already out by the time anything could go wrong, so all we can do is log it
+	err := c.ExportUserData(u, w)
+	if err != nil {
+		c.LogError(err)
+	}
+	return nil
+}
+
+func AccountEditDataDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	_, ferr := c.SimpleUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if c.Config.DisableSelfDelete {
+		return c.LocalError("Accounts can't be deleted by their owners on this site, please contact an administrator.", w, r, u)
+	}
+	if u.IsAdmin {
+		return c.LocalError(c.ErrSelfDeleteAdmin.Error(), w, r, u)
+	}
+
+	// TODO: Use a reusable statement
+	var realPassword, salt string
+	err := qgen.NewAcc().Select("users").Columns("password,salt").Where("uid=?").QueryRow(u.ID).Scan(&realPassword, &salt)
+	if err == sql.ErrNoRows {
+		return c.LocalError("Your account no longer exists.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.CheckPassword(realPassword, r.PostFormValue("password"), salt)
+	if err == c.ErrMismatchedHashAndPassword {
+		return c.LocalError("That's not the correct password.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	mode := r.PostFormValue("mode")
+	if mode != c.SelfDeleteAnonymise && mode != c.SelfDeletePurge {
+		return c.LocalError(c.ErrSelfDeleteMode.Error(), w, r, u)
+	}
+	// There's no grace period, so it's done right away
+	if c.Config.SelfDeleteGracePeriod < 0 {
+		err = (&c.SelfDeleteRequest{UID: u.ID, Mode: mode}).Run()
+		if err != nil {
+			return c.InternalError(err, w, r)
+		}
+		c.Auth.Logout(w, u.ID)
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return nil
+	}
+	err = c.SelfDeletes.Schedule(u.ID, mode)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/user/edit/data/?delete_scheduled=1", http.StatusSeeOther)
+	return nil
+}
+
+func AccountEditDataDeleteCancelSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	_, ferr := c.SimpleUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	err := c.SelfDeletes.Cancel(u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/user/edit/data/?delete_cancelled=1", http.StatusSeeOther)
+	return nil
+}
+
 func AccountEditEmail(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c.RouteError {
 	accountEditHead("account_email", w, r, u, h)
 	emails, err := c.Emails.GetEmailsByUser(u)
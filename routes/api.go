@@ -1,11 +1,13 @@
 package routes
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	c "github.com/Azareal/Gosora/common"
 )
@@ -46,6 +48,7 @@ func SitemapXml(w http.ResponseWriter, r *http.Request) c.RouteError {
 	writeXMLHeader(w, r)
 	w.Write([]byte("<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n"))
 	sitemapItem("sitemaps/topics.xml")
+	sitemapItem("sitemaps/tags.xml")
 	//sitemapItem("sitemaps/forums.xml")
 	//sitemapItem("sitemaps/users.xml")
 	w.Write([]byte("</sitemapindex>"))
@@ -64,6 +67,7 @@ type FuzzyRoute struct {
 var sitemapRoutes = map[string]func(http.ResponseWriter, *http.Request) c.RouteError{
 	"forums.xml": SitemapForums,
 	"topics.xml": SitemapTopics,
+	"tags.xml":   SitemapTags,
 }
 
 // TODO: Use a router capable of parsing this rather than hard-coding the logic in
@@ -117,7 +121,7 @@ func SitemapForums(w http.ResponseWriter, r *http.Request) c.RouteError {
 	for _, fid := range group.CanSee {
 		// Avoid data races by copying the struct into something we can freely mold without worrying about breaking something somewhere else
 		f := c.Forums.DirtyGet(fid).Copy()
-		if f.ParentID == 0 && f.Name != "" && f.Active {
+		if (f.ParentID == 0 || f.ParentType == "forum") && f.Name != "" && f.Active {
 			sitemapItem(c.BuildForumURL(c.NameToSlug(f.Name), f.ID))
 		}
 	}
@@ -216,6 +220,44 @@ func SitemapTopic(w http.ResponseWriter, r *http.Request, page int) c.RouteError
 	return nil
 }
 
+// SitemapTags lists the tag pages for the tags on topics guests can see
+func SitemapTags(w http.ResponseWriter, r *http.Request) c.RouteError {
+	var s string
+	if c.Config.SslSchema {
+		s = "s"
+	}
+	sitemapItem := func(path string) {
+		w.Write([]byte(`<url>
+	<loc>http` + s + `://` + c.Site.URL + path + `</loc>
+</url>
+`))
+	}
+
+	group, err := c.Groups.Get(c.GuestUser.Group)
+	if err != nil {
+		return c.SilentInternalErrorXML(errors.New("The guest group doesn't exist for some reason"), w, r)
+	}
+	var fids []int
+	for _, fid := range group.CanSee {
+		f := c.Forums.DirtyGet(fid)
+		if f.Name != "" && f.Active {
+			fids = append(fids, fid)
+		}
+	}
+	tags, err := c.TopicTags.InForums(fids, sitemapPageCap)
+	if err != nil {
+		return c.InternalErrorXML(err, w, r)
+	}
+
+	writeXMLHeader(w, r)
+	w.Write([]byte("<urlset xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n"))
+	for _, tag := range tags {
+		sitemapItem(c.BuildTagURL(tag))
+	}
+	w.Write([]byte("</urlset>"))
+	return nil
+}
+
 func SitemapUsers(w http.ResponseWriter, r *http.Request) c.RouteError {
 	writeXMLHeader(w, r)
 	w.Write([]byte("<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">\n"))
@@ -231,6 +273,8 @@ type JsonMe struct {
 type MeSite struct {
 	MaxReqSize   int
 	StaticPrefix string
+	EditWindow   int // How many minutes people have to edit their own posts, zero means there isn't a limit
+	DeleteWindow int // How many minutes people have to delete their own posts, zero means there isn't a limit
 }
 
 // APIMe returns information about the current logged-in user
@@ -244,7 +288,7 @@ func APIMe(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
 	// TODO: Use this header anywhere with a user check?
 	h.Set("Cache-Control", "private")
 
-	me := JsonMe{u.Me(), MeSite{c.Site.MaxRequestSize, c.StaticFiles.Prefix}}
+	me := JsonMe{u.Me(), MeSite{c.Site.MaxRequestSize, c.StaticFiles.Prefix, int(c.OwnPostEditWindow() / time.Minute), int(c.OwnPostDeleteWindow() / time.Minute)}}
 	jsonBytes, err := json.Marshal(me)
 	if err != nil {
 		return c.InternalErrorJS(err, w, r)
@@ -254,6 +298,63 @@ func APIMe(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
 	return nil
 }
 
+type JsonUserFields struct {
+	UID    int
+	Fields []*c.ProfileFieldShow
+}
+
+// APIUserFields returns the custom profile fields the current user is allowed to see on the profile of the user specified by the uid parameter
+func APIUserFields(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	w.Header().Set("Content-Type", "application/json")
+	uid, err := strconv.Atoi(r.FormValue("uid"))
+	if err != nil {
+		return c.PreErrorJS("Invalid uid", w, r)
+	}
+	pu, err := c.Users.Get(uid)
+	if err == sql.ErrNoRows {
+		return c.PreErrorJS("That user doesn't exist", w, r)
+	} else if err != nil {
+		return c.InternalErrorJS(err, w, r)
+	}
+	fields, err := c.ProfileFields.Show(pu, u)
+	if err != nil {
+		return c.InternalErrorJS(err, w, r)
+	}
+	if fields == nil {
+		fields = []*c.ProfileFieldShow{}
+	}
+
+	jsonBytes, err := json.Marshal(JsonUserFields{pu.ID, fields})
+	if err != nil {
+		return c.InternalErrorJS(err, w, r)
+	}
+	w.Write(jsonBytes)
+	return nil
+}
+
+type JsonTagSuggestions struct {
+	Tags []string
+}
+
+// APITagSuggest returns the most popular tags starting with the q parameter, for autocompleting tag inputs
+func APITagSuggest(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	w.Header().Set("Content-Type", "application/json")
+	tags, err := c.TopicTags.Suggest(r.FormValue("q"), 10)
+	if err != nil {
+		return c.InternalErrorJS(err, w, r)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+
+	jsonBytes, err := json.Marshal(JsonTagSuggestions{tags})
+	if err != nil {
+		return c.InternalErrorJS(err, w, r)
+	}
+	w.Write(jsonBytes)
+	return nil
+}
+
 func OpenSearchXml(w http.ResponseWriter, r *http.Request) c.RouteError {
 	w.Header().Set("Content-Type", "application/xml")
 	furl := "http"
@@ -56,7 +56,7 @@ func ShowAttachment(w http.ResponseWriter, r *http.Request, u *c.User, filename
 		if ferr != nil {
 			return ferr
 		}
-		if !u.Perms.ViewTopic {
+		if !u.Perms.ViewTopic && !(u.Perms.ViewOwnTopic && attachInOwnTopic(u, originID, originTable)) {
 			return c.NoPermissions(w, r, u)
 		}
 	} else {
@@ -88,6 +88,20 @@ func ShowAttachment(w http.ResponseWriter, r *http.Request, u *c.User, filename
 	return nil
 }
 
+// attachInOwnTopic tells you whether an attachment is in a topic u started, for the people who can only see their own topics
+func attachInOwnTopic(u *c.User, oid int, otable string) bool {
+	tid := oid
+	if otable == "replies" {
+		reply, err := c.Rstore.Get(oid)
+		if err != nil {
+			return false
+		}
+		tid = reply.ParentID
+	}
+	t, err := c.Topics.Get(tid)
+	return err == nil && u.CanViewTopic(t.CreatedBy)
+}
+
 func deleteAttachment(w http.ResponseWriter, r *http.Request, u *c.User, aid int, js bool) c.RouteError {
 	err := c.DeleteAttachment(aid)
 	if err == sql.ErrNoRows {
@@ -10,6 +10,7 @@ import (
 
 	c "github.com/Azareal/Gosora/common"
 	co "github.com/Azareal/Gosora/common/counters"
+	"github.com/Azareal/Gosora/common/phrases"
 	"github.com/Azareal/Gosora/uutils"
 )
 
@@ -224,3 +225,12 @@ func actionSuccess(w http.ResponseWriter, r *http.Request, dest string, js bool)
 	}
 	return nil
 }
+
+// ownPostDenied is for when someone isn't allowed to change a post, if it's theirs and they only missed out because the window closed, then we tell them as much rather than giving them a vague no permissions error
+func ownPostDenied(w http.ResponseWriter, r *http.Request, u *c.User, ownPerm bool, createdBy int, window time.Duration, phrase string, js bool) c.RouteError {
+	if ownPerm && u.ID != 0 && u.ID == createdBy && window > 0 {
+		msg := strings.Replace(phrases.GetErrorPhrase(phrase), "{0}", strconv.Itoa(int(window/time.Minute)), -1)
+		return c.LocalErrorJSQ(msg, w, r, u, js)
+	}
+	return c.NoPermissionsJSQ(w, r, u, js)
+}
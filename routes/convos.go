@@ -299,6 +299,10 @@ func ConvosCreateReplySubmit(w http.ResponseWriter, r *http.Request, user *c.Use
 	if err != nil {
 		return c.InternalError(err, w, r)
 	}
+	err = c.Drafts.Delete(user.ID, "convo", cid)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
 	err = c.AddActivityAndNotifyAll(c.Alert{ActorID: user.ID, Event: "reply", ElementType: "convo", ElementID: cid, Actor: user, Extra: strconv.Itoa(pid)})
 	if err != nil {
 		return c.InternalError(err, w, r)
@@ -0,0 +1,168 @@
+package routes
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	c "github.com/Azareal/Gosora/common"
+	p "github.com/Azareal/Gosora/common/phrases"
+)
+
+// draftCheck makes sure u is still allowed to post in the place a draft is for, so that drafts can't be used to sneak a peek at things they shouldn't
+func draftCheck(w http.ResponseWriter, r *http.Request, u *c.User, typ string, id int) c.RouteError {
+	switch typ {
+	case "forum":
+		_, ferr := c.SimpleForumUserCheck(w, r, u, id)
+		if ferr != nil {
+			return ferr
+		}
+		if !(u.Perms.ViewTopic || u.Perms.ViewOwnTopic) || !u.Perms.CreateTopic {
+			return c.NoPermissionsJS(w, r, u)
+		}
+	case "topic":
+		t, err := c.Topics.Get(id)
+		if err == sql.ErrNoRows {
+			return c.PreErrorJS("Couldn't find the topic", w, r)
+		} else if err != nil {
+			return c.InternalErrorJS(err, w, r)
+		}
+		_, ferr := c.SimpleForumUserCheck(w, r, u, t.ParentID)
+		if ferr != nil {
+			return ferr
+		}
+		if !u.CanReplyTo(t.CreatedBy) {
+			return c.NoPermissionsJS(w, r, u)
+		}
+	case "convo":
+		if !u.Perms.UseConvos && !u.Perms.UseConvosOnlyWithMod {
+			return c.NoPermissionsJS(w, r, u)
+		}
+		convo, err := c.Convos.Get(id)
+		if err == sql.ErrNoRows {
+			return c.PreErrorJS("Couldn't find the conversation", w, r)
+		} else if err != nil {
+			return c.InternalErrorJS(err, w, r)
+		}
+		if !convo.Has(u.ID) {
+			return c.NoPermissionsJS(w, r, u)
+		}
+	default:
+		return c.PreErrorJS(c.ErrBadDraftType.Error(), w, r)
+	}
+	return nil
+}
+
+func draftParams(w http.ResponseWriter, r *http.Request, u *c.User) (typ string, id int, ferr c.RouteError) {
+	if !u.Loggedin {
+		return "", 0, c.NoPermissionsJS(w, r, u)
+	}
+	typ = r.FormValue("type")
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		return "", 0, c.PreErrorJS(p.GetErrorPhrase("id_must_be_integer"), w, r)
+	}
+	return typ, id, draftCheck(w, r, u, typ, id)
+}
+
+type JsonDraft struct {
+	Title     string
+	Content   string
+	UpdatedAt int64 // A unix timestamp, zero if there isn't a draft
+}
+
+// APIDraft returns the draft the current user has saved for the place in the type and id parameters, so the editor can be filled back in
+func APIDraft(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "private")
+	typ, id, ferr := draftParams(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	var jd JsonDraft
+	d, err := c.Drafts.Get(u.ID, typ, id)
+	if err == nil {
+		jd = JsonDraft{d.Title, d.Content, d.UpdatedAt.Unix()}
+	} else if err != sql.ErrNoRows {
+		return c.InternalErrorJS(err, w, r)
+	}
+
+	jsonBytes, err := json.Marshal(jd)
+	if err != nil {
+		return c.InternalErrorJS(err, w, r)
+	}
+	w.Write(jsonBytes)
+	return nil
+}
+
+// DraftSaveSubmit is hit periodically by the editors as people type, an empty draft deletes the one which was there
+func DraftSaveSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	typ, id, ferr := draftParams(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	title := c.SanitiseSingleLine(r.PostFormValue("name"))
+	if typ != "forum" {
+		title = ""
+	}
+	// Unlike a topic, a draft with an overly long title is still worth keeping, so trim it down to the length the topic would allow
+	for len(title) > c.Config.MaxTopicTitleLength {
+		_, size := utf8.DecodeLastRuneInString(title)
+		title = title[:len(title)-size]
+	}
+	err := c.Drafts.Save(u.ID, typ, id, title, r.PostFormValue("content"))
+	if err != nil {
+		return c.InternalErrorJS(err, w, r)
+	}
+	return actionSuccess(w, r, "/user/edit/drafts/", r.PostFormValue("js") == "1")
+}
+
+func AccountDrafts(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c.RouteError {
+	accountEditHead("account_drafts", w, r, u, h)
+	if r.FormValue("deleted") == "1" {
+		h.AddNotice("account_draft_deleted")
+	}
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	perPage := 12
+	offset, page, lastPage := c.PageOffset(c.Drafts.CountUser(u.ID), page, perPage)
+
+	drafts, err := c.Drafts.GetByUser(u.ID, offset, perPage)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	items := make([]c.DraftItem, len(drafts))
+	for i, d := range drafts {
+		// The place it was for might have gone since, but the draft is still worth showing, so they can copy it out
+		var where string
+		switch d.ContextType {
+		case "forum":
+			if f, err := c.Forums.Get(d.ContextID); err == nil {
+				where = f.Name
+			}
+		case "topic":
+			if t, err := c.Topics.Get(d.ContextID); err == nil {
+				where = t.Title
+			}
+		}
+		items[i] = c.DraftItem{d, where}
+	}
+
+	pageList := c.Paginate(page, lastPage, 5)
+	pi := c.Account{h, "drafts", "account_drafts", c.AccountDraftsPage{h, items, c.Paginator{pageList, page, lastPage}}}
+	return renderTemplate("account", w, r, h, pi)
+}
+
+func AccountDraftsDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sdid string) c.RouteError {
+	did, err := strconv.Atoi(sdid)
+	if err != nil {
+		return c.LocalError(p.GetErrorPhrase("id_must_be_integer"), w, r, u)
+	}
+	err = c.Drafts.DeleteByID(u.ID, did)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/user/edit/drafts/?deleted=1", http.StatusSeeOther)
+	return nil
+}
@@ -22,7 +22,7 @@ func ViewForum(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, s
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic {
+	if !u.Perms.ViewTopic && !u.Perms.ViewOwnTopic {
 		return c.NoPermissions(w, r, u)
 	}
 	h.Path = "/forums/"
@@ -37,7 +37,14 @@ func ViewForum(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, s
 	h.Title = forum.Name
 	h.OGDesc = forum.Desc
 
-	topicList, pagi, err := c.TopicList.GetListByForum(forum, page, 0)
+	var topicList []*c.TopicsRow
+	pagi := c.Paginator{[]int{}, 1, 1}
+	if u.Perms.ViewTopic {
+		topicList, pagi, err = c.TopicList.GetListByForum(forum, page, 0)
+	} else if u.Loggedin {
+		// They can only see the topics they started here
+		topicList, _, pagi, err = c.TopicList.GetListByCanSee([]int{forum.ID}, page, 0, nil, c.TopicListFilter{CreatedBy: u.ID})
+	}
 	if err != nil {
 		return c.InternalError(err, w, r)
 	}
@@ -61,7 +68,16 @@ func ViewForum(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, s
 	}
 
 	//pageList := c.Paginate(page, lastPage, 5)
-	pi := c.ForumPage{h, topicList2, forum, u.Perms.CloseTopic, u.Perms.MoveTopic, pagi}
+	subforums, err := forumSubforums(u, forum.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	parents := forum.Parents()
+	mods, err := forumModerators(append(parents, forum))
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	pi := c.ForumPage{h, topicList2, forum, u.Perms.CloseTopic, u.Perms.MoveTopic, pagi, parents, subforums, mods}
 	tmpl := forum.Tmpl
 	if tmpl == "" {
 		ferr = renderTemplate("forum", w, r, h, pi)
@@ -75,3 +91,54 @@ func ViewForum(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, s
 	co.ForumViewCounter.Bump(forum.ID)
 	return ferr
 }
+
+// forumModerators fetches the users who moderate any of the forums in forums, each one is only listed once
+func forumModerators(forums []*c.Forum) ([]*c.User, error) {
+	var uids []int
+	seen := make(map[int]bool)
+	for _, f := range forums {
+		for _, m := range c.ForumMods.GetByForum(f.ID) {
+			if !seen[m.UserID] && m.Any() {
+				seen[m.UserID] = true
+				uids = append(uids, m.UserID)
+			}
+		}
+	}
+	if len(uids) == 0 {
+		return nil, nil
+	}
+	umap, err := c.Users.BulkGetMap(uids)
+	if err != nil {
+		return nil, err
+	}
+	mods := make([]*c.User, 0, len(uids))
+	for _, uid := range uids {
+		if mu, ok := umap[uid]; ok {
+			mods = append(mods, mu)
+		}
+	}
+	return mods, nil
+}
+
+// forumSubforums fetches the forums inside fid which u can see, with the topic counts and last topics of the ones below them rolled up into them
+func forumSubforums(u *c.User, fid int) ([]c.ForumListItem, error) {
+	var canSee []int
+	if u.IsSuperAdmin {
+		var err error
+		canSee, err = c.Forums.GetAllVisibleIDs()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		g, err := c.Groups.Get(u.Group)
+		if err != nil {
+			return nil, err
+		}
+		canSee = g.CanSee
+	}
+	tree, err := c.NewForumTree(canSee)
+	if err != nil {
+		return nil, err
+	}
+	return tree.Items(fid), nil
+}
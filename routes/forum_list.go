@@ -38,21 +38,17 @@ func ForumList(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c
 		canSee = g.CanSee
 	}
 
-	var forumList []c.Forum
-	for _, fid := range canSee {
-		// Avoid data races by copying the struct into something we can freely mold without worrying about breaking something somewhere else
-		f := c.Forums.DirtyGet(fid).Copy()
-		if f.ParentID == 0 && f.Name != "" && f.Active {
-			if f.LastTopicID != 0 {
-				if f.LastTopic.ID != 0 && f.LastReplyer.ID != 0 {
-					f.LastTopicTime = c.RelativeTime(f.LastTopic.LastReplyAt)
-				}
-			}
+	tree, err := c.NewForumTree(canSee)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	cats := tree.Categories()
+	for _, cat := range cats {
+		for i := range cat.ItemList {
 			//h.Hooks.Hook("forums_frow_assign", &f)
-			c.H_forums_frow_assign_hook(h.Hooks, &f)
-			forumList = append(forumList, f)
+			c.H_forums_frow_assign_hook(h.Hooks, &cat.ItemList[i].Forum)
 		}
 	}
 
-	return renderTemplate("forums", w, r, h, c.ForumsPage{h, forumList})
+	return renderTemplate("forums", w, r, h, c.ForumsPage{h, cats})
 }
@@ -1,7 +1,6 @@
 package panel
 
 import (
-	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -49,18 +48,70 @@ func Backups(w http.ResponseWriter, r *http.Request, u *c.User, backupURL string
 		return nil
 	}
 
-	var backupList []c.BackupItem
-	backupFiles, err := ioutil.ReadDir("./backups")
+	switch r.FormValue("done") {
+	case "create":
+		basePage.AddNotice("panel_backups_created")
+	case "restore":
+		basePage.AddNotice("panel_backups_restored")
+	}
+	backupList, err := c.ListBackups()
 	if err != nil {
 		return c.InternalError(err, w, r)
 	}
-	for _, backupFile := range backupFiles {
-		ext := filepath.Ext(backupFile.Name())
-		if ext != ".sql" {
-			continue
-		}
-		backupList = append(backupList, c.BackupItem{backupFile.Name(), backupFile.ModTime()})
-	}
 
 	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_backups", c.PanelBackupPage{basePage, backupList}})
 }
+
+func BackupsCreateSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	_, err := c.CreateBackup()
+	if err == c.ErrBackupBusy {
+		return c.LocalError("A backup is already being made or restored, please wait for it to finish.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.Create("create", 0, "backup", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/panel/backups/?done=create", http.StatusSeeOther)
+	return nil
+}
+
+func BackupsRestoreSubmit(w http.ResponseWriter, r *http.Request, u *c.User, name string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	name = c.Stripslashes(name)
+	if filepath.Ext(name) != ".zip" {
+		return c.LocalError("Only the backups made by Gosora can be restored.", w, r, u)
+	}
+	if _, err := os.Stat("./backups/" + name); err != nil {
+		return c.LocalError("This backup doesn't exist.", w, r, u)
+	}
+
+	_, err := c.RestoreBackup("./backups/" + name)
+	if err == c.ErrBackupBusy {
+		return c.LocalError("A backup is already being made or restored, please wait for it to finish.", w, r, u)
+	} else if err == c.ErrBadBackup {
+		return c.LocalError("This backup is either damaged or from a newer version of Gosora.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	// Everything in memory is from before the restore
+	err = c.ReloadAfterRestore()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	// This goes in after the restore, otherwise it'd be wiped out along with everything else
+	err = c.AdminLogs.Create("restore", 0, "backup", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/panel/backups/?done=restore", http.StatusSeeOther)
+	return nil
+}
@@ -47,6 +47,49 @@ func dashMySQLStmts() (stmts dashStmts, err error) {
 	return stmts, err
 }
 
+// TODO: Stop hard-coding these queries
+func dashSQLiteStmts() (stmts dashStmts, err error) {
+	db := qgen.Builder.GetConn()
+	prepStmt := func(table, ext, dur string) *sql.Stmt {
+		if err != nil {
+			return nil
+		}
+		stmt, ierr := db.Prepare("select count(*) from " + table + " where createdAt BETWEEN datetime('now','-" + dur + "') and datetime('now')" + ext)
+		err = errors.WithStack(ierr)
+		return stmt
+	}
+
+	stmts.todaysPostCount = prepStmt("replies", "", "1 day")
+	stmts.todaysTopicCount = prepStmt("topics", "", "1 day")
+	stmts.todaysNewUserCount = prepStmt("users", "", "1 day")
+	stmts.todaysTopicCountByForum = prepStmt("topics", " and parentID=?", "1 day")
+	// SQLite doesn't know what a week is
+	stmts.weeklyTopicCountByForum = prepStmt("topics", " and parentID=?", "7 days")
+
+	return stmts, err
+}
+
+// TODO: Stop hard-coding these queries
+func dashPgsqlStmts() (stmts dashStmts, err error) {
+	db := qgen.Builder.GetConn()
+	prepStmt := func(table, ext, dur string) *sql.Stmt {
+		if err != nil {
+			return nil
+		}
+		stmt, ierr := db.Prepare("select count(*) from " + table + " where createdAt BETWEEN (now() at time zone 'utc') - interval '1 " + dur + "' and (now() at time zone 'utc')" + ext)
+		err = errors.WithStack(ierr)
+		return stmt
+	}
+
+	stmts.todaysPostCount = prepStmt("replies", "", "day")
+	stmts.todaysTopicCount = prepStmt("topics", "", "day")
+	stmts.todaysNewUserCount = prepStmt("users", "", "day")
+	stmts.todaysTopicCountByForum = prepStmt("topics", " and parentID=?", "day")
+	stmts.weeklyTopicCountByForum = prepStmt("topics", " and parentID=?", "week")
+
+	return stmts, err
+}
+
 // TODO: Stop hard-coding these queries
 func dashMSSQLStmts() (stmts dashStmts, err error) {
 	db := qgen.Builder.GetConn()
@@ -144,8 +187,12 @@ func Dashboard(w http.ResponseWriter, r *http.Request, user *c.User) c.RouteErro
 	switch qgen.Builder.GetAdapter().GetName() {
 	case "mysql":
 		stmts, err = dashMySQLStmts()
+	case "pgsql":
+		stmts, err = dashPgsqlStmts()
 	case "mssql":
 		stmts, err = dashMSSQLStmts()
+	case "sqlite":
+		stmts, err = dashSQLiteStmts()
 	default:
 		return c.InternalError(errors.New("Unknown database adapter on dashboard"), w, r)
 	}
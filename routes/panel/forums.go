@@ -23,23 +23,11 @@ func Forums(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
 	basePage.Header.AddScriptAsync("panel_forums.js")
 
 	// TODO: Paginate this?
-	var forumList []interface{}
-	forums, err := c.Forums.GetAll()
+	children, err := forumChildren()
 	if err != nil {
 		return c.InternalError(err, w, r)
 	}
 
-	// ? - Should we generate something similar to the forumView? It might be a little overkill for a page which is rarely loaded in comparison to /forums/
-	for _, f := range forums {
-		if f.Name != "" && f.ParentID == 0 {
-			fadmin := c.ForumAdmin{f.ID, f.Name, f.Desc, f.Active, f.Preset, f.TopicCount, c.PresetToLang(f.Preset)}
-			if fadmin.Preset == "" {
-				fadmin.Preset = "custom"
-			}
-			forumList = append(forumList, fadmin)
-		}
-	}
-
 	if r.FormValue("created") == "1" {
 		basePage.AddNotice("panel_forum_created")
 	} else if r.FormValue("deleted") == "1" {
@@ -48,10 +36,62 @@ func Forums(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
 		basePage.AddNotice("panel_forum_updated")
 	}
 
-	pi := c.PanelPage{basePage, forumList, nil}
+	pi := c.PanelForumsPage{basePage, forumAdminList(children, 0, u.Session, 0), forumParentOptions(children, 0, 0, 0)}
 	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_forums", &pi})
 }
 
+// forumChildren arranges the forums by the forums they're in, the ones in guilds are left out as the plugin manages those
+// ? - Should we generate something similar to the forumView? It might be a little overkill for a page which is rarely loaded in comparison to /forums/
+func forumChildren() (map[int][]*c.Forum, error) {
+	forums, err := c.Forums.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int][]*c.Forum)
+	for _, f := range forums {
+		if f.Name == "" {
+			continue
+		}
+		switch f.ParentType {
+		case "":
+			children[0] = append(children[0], f)
+		case "forum":
+			children[f.ParentID] = append(children[f.ParentID], f)
+		}
+	}
+	return children, nil
+}
+
+func forumAdminList(children map[int][]*c.Forum, pid int, session string, depth int) c.PanelForumList {
+	l := c.PanelForumList{ParentID: pid, Session: session}
+	if depth > 50 {
+		return l
+	}
+	for _, f := range children[pid] {
+		fadmin := c.ForumAdmin{f.ID, f.Name, f.Desc, f.Active, f.Preset, f.TopicCount, c.PresetToLang(f.Preset), f.Category, forumAdminList(children, f.ID, session, depth+1)}
+		if fadmin.Preset == "" {
+			fadmin.Preset = "custom"
+		}
+		l.ItemList = append(l.ItemList, fadmin)
+	}
+	return l
+}
+
+// forumParentOptions lists the forums fid can be put inside of, which is everything but itself and the forums under it
+func forumParentOptions(children map[int][]*c.Forum, pid, fid, depth int) (opts []c.PanelForumParent) {
+	if depth > 50 {
+		return nil
+	}
+	for _, f := range children[pid] {
+		if f.ID == fid {
+			continue
+		}
+		opts = append(opts, c.PanelForumParent{f.ID, f.Name, strings.Repeat("- ", depth)})
+		opts = append(opts, forumParentOptions(children, f.ID, fid, depth+1)...)
+	}
+	return opts
+}
+
 func ForumsCreateSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
 	_, ferr := c.SimplePanelUserCheck(w, r, u)
 	if ferr != nil {
@@ -67,10 +107,33 @@ func ForumsCreateSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.Rou
 	factive := r.PostFormValue("active")
 	active := (factive == "on" || factive == "1")
 
+	parentID, _ := strconv.Atoi(r.PostFormValue("parent"))
+	category := r.PostFormValue("category") == "1"
+	if category && parentID != 0 {
+		return c.LocalError(c.ErrCategoryParent.Error(), w, r, u)
+	}
+	if parentID != 0 && !c.Forums.Exists(parentID) {
+		return c.LocalError(c.ErrNoParentForum.Error(), w, r, u)
+	}
+
 	fid, err := c.Forums.Create(name, desc, active, preset)
 	if err != nil {
 		return c.InternalError(err, w, r)
 	}
+	if parentID != 0 || category {
+		f, err := c.Forums.Get(fid)
+		if err != nil {
+			return c.InternalError(err, w, r)
+		}
+		if parentID != 0 {
+			err = f.SetParent(parentID)
+		} else {
+			err = f.SetCategory(true)
+		}
+		if err != nil {
+			return forumTreeError(err, w, r, u, false)
+		}
+	}
 	err = c.AdminLogs.Create("create", fid, "forum", u.GetIP(), u.ID)
 	if err != nil {
 		return c.InternalError(err, w, r)
@@ -151,17 +214,30 @@ func ForumsOrderSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.Rout
 	sitems := strings.TrimSuffix(strings.TrimPrefix(r.PostFormValue("items"), "{"), "}")
 	//fmt.Printf("sitems: %+v\n", sitems)
 
-	updateMap := make(map[int]int)
-	for index, sfid := range strings.Split(sitems, ",") {
+	// Each item is fid:parentID in the order they're shown, a bare fid is on the top level
+	var items []c.ForumTreeItem
+	orders := make(map[int]int) // [parentID]next order
+	for _, sitem := range strings.Split(sitems, ",") {
+		sfid, spid := sitem, "0"
+		if i := strings.IndexByte(sitem, ':'); i != -1 {
+			sfid, spid = sitem[:i], sitem[i+1:]
+		}
 		fid, err := strconv.Atoi(sfid)
 		if err != nil {
 			return c.LocalErrorJSQ("Invalid integer in forum list", w, r, u, js)
 		}
-		updateMap[fid] = index
+		pid, err := strconv.Atoi(spid)
+		if err != nil {
+			return c.LocalErrorJSQ("Invalid integer in forum list", w, r, u, js)
+		}
+		items = append(items, c.ForumTreeItem{fid, pid, orders[pid]})
+		orders[pid]++
 	}
-	err := c.Forums.UpdateOrder(updateMap)
-	if err != nil {
-		return c.InternalErrorJSQ(err, w, r, js)
+	err := c.Forums.UpdateTree(items)
+	if err == sql.ErrNoRows {
+		return c.LocalErrorJSQ("One of the forums you're trying to move doesn't exist.", w, r, u, js)
+	} else if err != nil {
+		return forumTreeError(err, w, r, u, js)
 	}
 
 	err = c.AdminLogs.Create("reorder", 0, "forum", u.GetIP(), u.ID)
@@ -219,9 +295,26 @@ func ForumsEdit(w http.ResponseWriter, r *http.Request, u *c.User, sfid string)
 
 	if r.FormValue("updated") == "1" {
 		basePage.AddNotice("panel_forum_updated")
+	} else if r.FormValue("mods_updated") == "1" {
+		basePage.AddNotice("panel_forum_mods_updated")
+	}
+
+	children, err := forumChildren()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	var parents []c.PanelForumParent
+	// Categories have to stay on the top level
+	if !forum.Category {
+		parents = forumParentOptions(children, 0, forum.ID, 0)
 	}
 
-	pi := c.PanelEditForumPage{basePage, forum.ID, forum.Name, forum.Desc, forum.Active, forum.Preset, gplist}
+	var mods []c.PanelForumMod
+	for _, m := range c.ForumMods.GetByForum(fid) {
+		mods = append(mods, c.PanelForumMod{handleUnknownUser(c.Users.Get(m.UserID)), m.ForumModPerms})
+	}
+
+	pi := c.PanelEditForumPage{basePage, forum.ID, forum.Name, forum.Desc, forum.Active, forum.Preset, forum.Questions, forum.Category, forum.ParentID, parents, gplist, mods}
 	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_forum_edit", &pi})
 }
 
@@ -262,6 +355,41 @@ func ForumsEditSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sfid st
 	if err != nil {
 		return c.InternalErrorJSQ(err, w, r, js)
 	}
+	if fquestions := r.PostFormValue("forum_questions"); fquestions != "" {
+		questions := fquestions == "1"
+		if questions != forum.Questions {
+			err = forum.SetQuestions(questions)
+			if err != nil {
+				return c.InternalErrorJSQ(err, w, r, js)
+			}
+		}
+	}
+	if sparent := r.PostFormValue("forum_parent"); sparent != "" {
+		parentID, err := strconv.Atoi(sparent)
+		if err != nil {
+			return c.LocalErrorJSQ("The provided parent forum ID is not a valid number.", w, r, u, js)
+		}
+		if parentID != forum.ParentID || (parentID != 0 && forum.ParentType != "forum") {
+			err = forum.SetParent(parentID)
+			if err != nil {
+				return forumTreeError(err, w, r, u, js)
+			}
+		}
+	}
+	if fcategory := r.PostFormValue("forum_category"); fcategory != "" {
+		category := fcategory == "1"
+		if category != forum.Category {
+			// SetParent may have moved it, so we need the latest copy
+			forum, err = c.Forums.Get(fid)
+			if err != nil {
+				return c.InternalErrorJSQ(err, w, r, js)
+			}
+			err = forum.SetCategory(category)
+			if err != nil {
+				return forumTreeError(err, w, r, u, js)
+			}
+		}
+	}
 	err = c.AdminLogs.Create("edit", fid, "forum", u.GetIP(), u.ID)
 	if err != nil {
 		return c.InternalError(err, w, r)
@@ -271,6 +399,114 @@ func ForumsEditSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sfid st
 	return successRedirect("/panel/forums/", w, r, js)
 }
 
+// forumModPermsFromForm reads the moderation permissions ticked in the perms select on the forum moderator forms
+func forumModPermsFromForm(r *http.Request) (mp c.ForumModPerms) {
+	for _, perm := range r.PostForm["perms"] {
+		switch perm {
+		case "pin":
+			mp.PinTopic = true
+		case "close":
+			mp.CloseTopic = true
+		case "move":
+			mp.MoveTopic = true
+		case "delete":
+			mp.DeletePosts = true
+		case "edit":
+			mp.EditPosts = true
+		}
+	}
+	return mp
+}
+
+// ForumsEditModSubmit makes someone a moderator of a forum, or changes the permissions of one who already is
+func ForumsEditModSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sfid string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	js := r.PostFormValue("js") == "1"
+	if !u.Perms.ManageForums {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+
+	fid, err := strconv.Atoi(sfid)
+	if err != nil {
+		return c.LocalErrorJSQ("The provided Forum ID is not a valid number.", w, r, u, js)
+	}
+	if !c.Forums.Exists(fid) {
+		return c.LocalErrorJSQ("This forum doesn't exist", w, r, u, js)
+	}
+
+	var mu *c.User
+	if name := strings.TrimSpace(r.PostFormValue("name")); name != "" {
+		mu, err = c.Users.GetByName(name)
+	} else {
+		uid, cerr := strconv.Atoi(r.PostFormValue("uid"))
+		if cerr != nil {
+			return c.LocalErrorJSQ("Invalid User ID", w, r, u, js)
+		}
+		mu, err = c.Users.Get(uid)
+	}
+	if err == sql.ErrNoRows {
+		return c.LocalErrorJSQ("The user you're trying to make a moderator doesn't exist.", w, r, u, js)
+	} else if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	mp := forumModPermsFromForm(r)
+	if !mp.Any() {
+		return c.LocalErrorJSQ("A moderator needs at-least one moderation permission.", w, r, u, js)
+	}
+
+	action := "edit"
+	if _, err := c.ForumMods.Get(fid, mu.ID); err == sql.ErrNoRows {
+		action = "create"
+	}
+	err = c.ForumMods.Set(fid, mu.ID, mp)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	err = c.AdminLogs.CreateExtra(action, fid, "forum_mod", u.GetIP(), u.ID, strconv.Itoa(mu.ID))
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	return successRedirect("/panel/forums/edit/"+strconv.Itoa(fid)+"?mods_updated=1", w, r, js)
+}
+
+func ForumsEditModDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sfid string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	js := r.PostFormValue("js") == "1"
+	if !u.Perms.ManageForums {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+
+	fid, err := strconv.Atoi(sfid)
+	if err != nil {
+		return c.LocalErrorJSQ("The provided Forum ID is not a valid number.", w, r, u, js)
+	}
+	uid, err := strconv.Atoi(r.FormValue("uid"))
+	if err != nil {
+		return c.LocalErrorJSQ("Invalid User ID", w, r, u, js)
+	}
+	_, err = c.ForumMods.Get(fid, uid)
+	if err == sql.ErrNoRows {
+		return c.LocalErrorJSQ("That user isn't a moderator of this forum.", w, r, u, js)
+	} else if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	err = c.ForumMods.Delete(fid, uid)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	err = c.AdminLogs.CreateExtra("delete", fid, "forum_mod", u.GetIP(), u.ID, strconv.Itoa(uid))
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	return successRedirect("/panel/forums/edit/"+strconv.Itoa(fid)+"?mods_updated=1", w, r, js)
+}
+
 func ForumsEditPermsSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sfid string) c.RouteError {
 	_, ferr := c.SimplePanelUserCheck(w, r, u)
 	if ferr != nil {
@@ -310,6 +546,15 @@ func ForumsEditPermsSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sf
 	return successRedirect("/panel/forums/edit/"+strconv.Itoa(fid)+"?updated=1", w, r, js)
 }
 
+// forumTreeError turns the errors from moving forums around into something we can show the admin
+func forumTreeError(err error, w http.ResponseWriter, r *http.Request, u *c.User, js bool) c.RouteError {
+	switch err {
+	case c.ErrForumParentLoop, c.ErrCategoryParent, c.ErrCategoryTopics, c.ErrNoParentForum:
+		return c.LocalErrorJSQ(err.Error(), w, r, u, js)
+	}
+	return c.InternalErrorJSQ(err, w, r, js)
+}
+
 // A helper function for the Advanced portion of the Forum Perms Editor
 func forumPermsExtractDash(paramList string) (fid, gid int, err error) {
 	params := strings.Split(paramList, "-")
@@ -368,16 +613,23 @@ func ForumsEditPermsAdvance(w http.ResponseWriter, r *http.Request, u *c.User, p
 		formattedPermList = append(formattedPermList, c.NameLangToggle{permStr, p.GetPermPhrase(permStr), perm})
 	}
 	addToggle("ViewTopic", fp.ViewTopic)
+	addToggle("ViewOwnTopic", fp.ViewOwnTopic)
 	addToggle("LikeItem", fp.LikeItem)
 	addToggle("CreateTopic", fp.CreateTopic)
 	//<--
 	addToggle("EditTopic", fp.EditTopic)
+	addToggle("EditOwnTopic", fp.EditOwnTopic)
 	addToggle("DeleteTopic", fp.DeleteTopic)
+	addToggle("DeleteOwnTopic", fp.DeleteOwnTopic)
 	addToggle("CreateReply", fp.CreateReply)
+	addToggle("CreateReplyToOwn", fp.CreateReplyToOwn)
 	addToggle("EditReply", fp.EditReply)
+	addToggle("EditOwnReply", fp.EditOwnReply)
 	addToggle("DeleteReply", fp.DeleteReply)
+	addToggle("DeleteOwnReply", fp.DeleteOwnReply)
 	addToggle("PinTopic", fp.PinTopic)
 	addToggle("CloseTopic", fp.CloseTopic)
+	addToggle("CloseOwnTopic", fp.CloseOwnTopic)
 	addToggle("MoveTopic", fp.MoveTopic)
 
 	if r.FormValue("updated") == "1" {
@@ -424,15 +676,22 @@ func ForumsEditPermsAdvanceSubmit(w http.ResponseWriter, r *http.Request, u *c.U
 
 	// TODO: Generate this code?
 	fp.ViewTopic = extractPerm("ViewTopic")
+	fp.ViewOwnTopic = extractPerm("ViewOwnTopic")
 	fp.LikeItem = extractPerm("LikeItem")
 	fp.CreateTopic = extractPerm("CreateTopic")
 	fp.EditTopic = extractPerm("EditTopic")
+	fp.EditOwnTopic = extractPerm("EditOwnTopic")
 	fp.DeleteTopic = extractPerm("DeleteTopic")
+	fp.DeleteOwnTopic = extractPerm("DeleteOwnTopic")
 	fp.CreateReply = extractPerm("CreateReply")
+	fp.CreateReplyToOwn = extractPerm("CreateReplyToOwn")
 	fp.EditReply = extractPerm("EditReply")
+	fp.EditOwnReply = extractPerm("EditOwnReply")
 	fp.DeleteReply = extractPerm("DeleteReply")
+	fp.DeleteOwnReply = extractPerm("DeleteOwnReply")
 	fp.PinTopic = extractPerm("PinTopic")
 	fp.CloseTopic = extractPerm("CloseTopic")
+	fp.CloseOwnTopic = extractPerm("CloseOwnTopic")
 	fp.MoveTopic = extractPerm("MoveTopic")
 
 	err = forum.SetPerms(&fp, "custom", gid)
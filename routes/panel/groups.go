@@ -332,16 +332,23 @@ func GroupsEditPerms(w http.ResponseWriter, r *http.Request, u *c.User, sgid str
 	}
 
 	addPerm("ViewTopic", g.Perms.ViewTopic)
+	addPerm("ViewOwnTopic", g.Perms.ViewOwnTopic)
 	addPerm("LikeItem", g.Perms.LikeItem)
 	addPerm("CreateTopic", g.Perms.CreateTopic)
 	//<--
 	addPerm("EditTopic", g.Perms.EditTopic)
+	addPerm("EditOwnTopic", g.Perms.EditOwnTopic)
 	addPerm("DeleteTopic", g.Perms.DeleteTopic)
+	addPerm("DeleteOwnTopic", g.Perms.DeleteOwnTopic)
 	addPerm("CreateReply", g.Perms.CreateReply)
+	addPerm("CreateReplyToOwn", g.Perms.CreateReplyToOwn)
 	addPerm("EditReply", g.Perms.EditReply)
+	addPerm("EditOwnReply", g.Perms.EditOwnReply)
 	addPerm("DeleteReply", g.Perms.DeleteReply)
+	addPerm("DeleteOwnReply", g.Perms.DeleteOwnReply)
 	addPerm("PinTopic", g.Perms.PinTopic)
 	addPerm("CloseTopic", g.Perms.CloseTopic)
+	addPerm("CloseOwnTopic", g.Perms.CloseOwnTopic)
 	addPerm("MoveTopic", g.Perms.MoveTopic)
 
 	var globalPerms []c.NameLangToggle
@@ -2,6 +2,7 @@ package panel
 
 import (
 	"fmt"
+	"html"
 	"html/template"
 	"net/http"
 	"strconv"
@@ -53,13 +54,13 @@ func handleUnknownTopic(t *c.Topic, err error) *c.Topic {
 
 // TODO: Move the log building logic into /common/ and it's own abstraction
 func topicElementTypeAction(action, elementType string, elementID int, actor *c.User, topic *c.Topic) (out string) {
-	if action == "delete" {
-		return p.GetTmplPhrasef("panel_logs_mod_action_topic_delete", elementID, actor.Link, actor.Name)
+	if action == "delete" || action == "purge" {
+		return p.GetTmplPhrasef("panel_logs_mod_action_topic_"+action, elementID, actor.Link, actor.Name)
 	}
 	var tbit string
 	aarr := strings.Split(action, "-")
 	switch aarr[0] {
-	case "lock", "unlock", "stick", "unstick":
+	case "lock", "unlock", "stick", "unstick", "restore", "revert":
 		tbit = aarr[0]
 	case "move":
 		if len(aarr) == 2 {
@@ -70,6 +71,17 @@ func topicElementTypeAction(action, elementType string, elementID int, actor *c.
 			}
 		}
 		tbit = "move"
+	case "merge":
+		tbit = "merge"
+	case "split", "movereplies":
+		if len(aarr) == 2 {
+			tid, _ := strconv.Atoi(aarr[1])
+			dest, err := c.Topics.Get(tid)
+			if err == nil {
+				return p.GetTmplPhrasef("panel_logs_mod_action_topic_"+aarr[0]+"_dest", topic.Link, topic.Title, dest.Link, dest.Title, actor.Link, actor.Name)
+			}
+		}
+		tbit = aarr[0]
 	default:
 		return p.GetTmplPhrasef("panel_logs_mod_action_topic_unknown", action, elementType, actor.Link, actor.Name)
 	}
@@ -88,9 +100,20 @@ func modlogsElementType(action, elementType string, elementID int, actor *c.User
 		targetUser := handleUnknownUser(c.Users.Get(elementID))
 		out = p.GetTmplPhrasef("panel_logs_mod_action_user_"+action, targetUser.Link, targetUser.Name, actor.Link, actor.Name)
 	case "reply":
-		if action == "delete" {
+		switch action {
+		case "delete":
 			topic := handleUnknownTopic(c.TopicByReplyID(elementID))
 			out = p.GetTmplPhrasef("panel_logs_mod_action_reply_delete", topic.Link, topic.Title, actor.Link, actor.Name)
+		case "restore":
+			topic := handleUnknownTopic(c.TopicByReplyID(elementID))
+			out = p.GetTmplPhrasef("panel_logs_mod_action_reply_restore", topic.Link, topic.Title, actor.Link, actor.Name)
+		case "revert":
+			topic := handleUnknownTopic(c.TopicByReplyID(elementID))
+			out = p.GetTmplPhrasef("panel_logs_mod_action_reply_revert", topic.Link, topic.Title, actor.Link, actor.Name)
+		case "purge":
+			// The reply doesn't exist anymore, so the topic it was in is logged instead
+			topic := handleUnknownTopic(c.Topics.Get(elementID))
+			out = p.GetTmplPhrasef("panel_logs_mod_action_reply_purge", topic.Link, topic.Title, actor.Link, actor.Name)
 		}
 	case "profile-reply":
 		if action == "delete" {
@@ -135,6 +158,14 @@ func adminlogsElementType(action, elementType string, elementID int, actor *c.Us
 		} else {
 			out = p.GetTmplPhrasef("panel_logs_admin_action_forum_"+action, "/panel/forums/edit/"+strconv.Itoa(f.ID), f.Name, actor.Link, actor.Name)
 		}
+	case "forum_mod":
+		f, err := c.Forums.Get(elementID)
+		if err != nil {
+			f = &c.Forum{Name: p.GetTmplPhrase("forum_unknown")}
+		}
+		uid, _ := strconv.Atoi(extra)
+		mu := handleUnknownUser(c.Users.Get(uid))
+		out = p.GetTmplPhrasef("panel_logs_admin_action_forum_mod_"+action, mu.Link, mu.Name, "/panel/forums/edit/"+strconv.Itoa(elementID), f.Name, actor.Link, actor.Name)
 	case "page":
 		pp, err := c.Pages.Get(elementID)
 		if err != nil {
@@ -149,6 +180,10 @@ func adminlogsElementType(action, elementType string, elementID int, actor *c.Us
 		out = p.GetTmplPhrasef("panel_logs_admin_action_setting_edit", "/panel/settings/edit/"+s.Name, s.Name, actor.Link, actor.Name)
 	case "word_filter":
 		out = p.GetTmplPhrasef("panel_logs_admin_action_word_filter_"+action, actor.Link, actor.Name)
+	case "profile_field":
+		out = p.GetTmplPhrasef("panel_logs_admin_action_profile_field_"+action, "/panel/profile-fields/edit/"+strconv.Itoa(elementID), elementID, actor.Link, actor.Name)
+	case "topic_prefix":
+		out = p.GetTmplPhrasef("panel_logs_admin_action_topic_prefix_"+action, "/panel/topic-prefixes/edit/"+strconv.Itoa(elementID), elementID, actor.Link, actor.Name)
 	case "menu":
 		if action == "suborder" {
 			out = p.GetTmplPhrasef("panel_logs_admin_action_menu_suborder", elementID, actor.Link, actor.Name)
@@ -161,6 +196,10 @@ func adminlogsElementType(action, elementType string, elementID int, actor *c.Us
 		out = p.GetTmplPhrasef("panel_logs_admin_action_plugin_"+action, extra, actor.Link, actor.Name)
 	case "backup":
 		out = p.GetTmplPhrasef("panel_logs_admin_action_backup_"+action, actor.Link, actor.Name)
+	case "theme":
+		out = p.GetTmplPhrasef("panel_logs_admin_action_theme_"+action, html.EscapeString(extra), actor.Link, actor.Name)
+	case "phrase":
+		out = p.GetTmplPhrasef("panel_logs_admin_action_phrase_"+action, html.EscapeString(extra), actor.Link, actor.Name)
 	}
 	if out == "" {
 		out = p.GetTmplPhrasef("panel_logs_admin_action_unknown", action, elementType, actor.Link, actor.Name)
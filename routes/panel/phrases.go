@@ -0,0 +1,182 @@
+package panel
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	c "github.com/Azareal/Gosora/common"
+	p "github.com/Azareal/Gosora/common/phrases"
+)
+
+func Phrases(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "phrases", "phrases")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.EditSettings {
+		return c.NoPermissions(w, r, u)
+	}
+
+	def := p.GetLangPack().Name
+	var packs []c.PanelPhrasePack
+	for _, lp := range p.GetLangPacks() {
+		var changed int
+		for _, phrases := range lp.Overrides {
+			changed += len(phrases)
+		}
+		packs = append(packs, c.PanelPhrasePack{lp.Name, lp.IsoCode, changed, lp.Name == def})
+	}
+
+	pi := c.PanelPhrasesPage{basePage, packs}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_phrases", &pi})
+}
+
+func PhrasesEdit(w http.ResponseWriter, r *http.Request, u *c.User, spack string) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "phrases_edit", "phrases")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.EditSettings {
+		return c.NoPermissions(w, r, u)
+	}
+	lp, ok := p.GetLanguagePackByName(spack)
+	if !ok {
+		return c.LocalError("That language pack doesn't exist.", w, r, u)
+	}
+
+	group := r.FormValue("group")
+	if group == "" {
+		group = "TmplPhrases"
+	}
+	phrases := lp.Phrases(group)
+	if phrases == nil {
+		return c.LocalError("Those phrases can't be edited.", w, r, u)
+	}
+	q := strings.TrimSpace(r.FormValue("q"))
+	lq := strings.ToLower(q)
+
+	names := make([]string, 0, len(phrases))
+	for name, phrase := range phrases {
+		if lq != "" && !strings.Contains(strings.ToLower(name), lq) && !strings.Contains(strings.ToLower(phrase), lq) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	perPage := 50
+	offset, page, lastPage := c.PageOffset(len(names), page, perPage)
+	end := offset + perPage
+	if end > len(names) {
+		end = len(names)
+	}
+	items := make([]c.PanelPhrase, 0, end-offset)
+	for _, name := range names[offset:end] {
+		items = append(items, c.PanelPhrase{name, phrases[name], lp.IsChanged(group, name), lp.IsCustom(group, name)})
+	}
+
+	if r.FormValue("created") == "1" {
+		basePage.AddNotice("panel_phrase_created")
+	} else if r.FormValue("deleted") == "1" {
+		basePage.AddNotice("panel_phrase_deleted")
+	} else if r.FormValue("updated") == "1" {
+		basePage.AddNotice("panel_phrase_updated")
+	}
+
+	params := "group=" + url.QueryEscape(group) + "&"
+	if q != "" {
+		params += "q=" + url.QueryEscape(q) + "&"
+	}
+	pageList := c.Paginate(page, lastPage, 5)
+	pi := c.PanelPhrasesEditPage{basePage, lp.Name, p.PhraseGroups, group, q, items, c.PaginatorMod{template.URL(params), pageList, page, lastPage}}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_phrases_edit", &pi})
+}
+
+func phrasesEditLink(pack, group string) string {
+	return "/panel/phrases/edit/" + url.PathEscape(pack) + "?group=" + url.QueryEscape(group)
+}
+
+// phraseError turns the errors from the phrase mutators into something we can show the admin
+func phraseError(err error, w http.ResponseWriter, r *http.Request, u *c.User, js bool) c.RouteError {
+	switch err {
+	case p.ErrNoLangPack, p.ErrNoPhraseGroup, p.ErrBlankPhraseName, p.ErrPhraseExists, p.ErrNoPhrase, p.ErrPhraseBuiltin:
+		return c.LocalErrorJSQ(err.Error(), w, r, u, js)
+	}
+	return c.InternalErrorJSQ(err, w, r, js)
+}
+
+func PhrasesCreateSubmit(w http.ResponseWriter, r *http.Request, u *c.User, spack string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	js := r.PostFormValue("js") == "1"
+	if !u.Perms.EditSettings {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+
+	group := r.PostFormValue("group")
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	phrase := r.PostFormValue("phrase")
+	err := p.AddPhrase(spack, group, name, phrase)
+	if err != nil {
+		return phraseError(err, w, r, u, js)
+	}
+	err = c.AdminLogs.CreateExtra("create", 0, "phrase", u.GetIP(), u.ID, spack+":"+group+":"+name)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	return successRedirect(phrasesEditLink(spack, group)+"&created=1", w, r, js)
+}
+
+func PhrasesEditSubmit(w http.ResponseWriter, r *http.Request, u *c.User, spack string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	js := r.PostFormValue("js") == "1"
+	if !u.Perms.EditSettings {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+
+	group := r.PostFormValue("group")
+	name := r.PostFormValue("name")
+	err := p.UpdatePhrase(spack, group, name, r.PostFormValue("phrase"))
+	if err != nil {
+		return phraseError(err, w, r, u, js)
+	}
+	err = c.AdminLogs.CreateExtra("edit", 0, "phrase", u.GetIP(), u.ID, spack+":"+group+":"+name)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	return successRedirect(phrasesEditLink(spack, group)+"&updated=1", w, r, js)
+}
+
+// PhrasesDeleteSubmit deletes the phrases added from the Control Panel and reverts the ones which were changed
+func PhrasesDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, spack string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	js := r.PostFormValue("js") == "1"
+	if !u.Perms.EditSettings {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+
+	group := r.FormValue("group")
+	name := r.FormValue("name")
+	err := p.DeletePhrase(spack, group, name)
+	if err != nil {
+		return phraseError(err, w, r, u, js)
+	}
+	err = c.AdminLogs.CreateExtra("delete", 0, "phrase", u.GetIP(), u.ID, spack+":"+group+":"+name)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	return successRedirect(phrasesEditLink(spack, group)+"&deleted=1", w, r, js)
+}
@@ -0,0 +1,224 @@
+package panel
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	c "github.com/Azareal/Gosora/common"
+)
+
+func ProfileFields(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "profile_fields", "profile-fields")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.EditSettings {
+		return c.NoPermissions(w, r, u)
+	}
+	if r.FormValue("created") == "1" {
+		basePage.AddNotice("panel_profile_field_created")
+	} else if r.FormValue("updated") == "1" {
+		basePage.AddNotice("panel_profile_field_updated")
+	} else if r.FormValue("deleted") == "1" {
+		basePage.AddNotice("panel_profile_field_deleted")
+	}
+
+	groups, err := c.Groups.GetAll()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	pi := c.PanelProfileFieldsPage{basePage, c.ProfileFields.GetAll(), c.ProfileFieldTypes, groups}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_profile_fields", &pi})
+}
+
+// profileFieldFromForm builds a field from the submitted form, returning a message for the user if anything is amiss
+func profileFieldFromForm(r *http.Request) (*c.ProfileField, string) {
+	f := &c.ProfileField{}
+	f.Name = c.SanitiseSingleLine(r.PostFormValue("name"))
+	if f.Name == "" {
+		return nil, "You need to give this field a name"
+	}
+	f.Type = r.PostFormValue("type")
+	var validType bool
+	for _, typ := range c.ProfileFieldTypes {
+		if typ == f.Type {
+			validType = true
+			break
+		}
+	}
+	if !validType {
+		return nil, "Invalid field type"
+	}
+
+	for _, opt := range strings.Split(r.PostFormValue("options"), "\n") {
+		opt = c.SanitiseSingleLine(strings.TrimSpace(opt))
+		if opt != "" {
+			f.Options = append(f.Options, opt)
+		}
+	}
+	if f.Type == "select" && len(f.Options) == 0 {
+		return nil, "Select fields need at least one option"
+	}
+
+	var err error
+	f.MaxLength, err = strconv.Atoi(r.PostFormValue("max-length"))
+	if err != nil || f.MaxLength < 0 {
+		return nil, "The maximum length must be a positive integer"
+	}
+	f.Privacy, err = strconv.Atoi(r.PostFormValue("privacy"))
+	if err != nil || f.Privacy < 1 || f.Privacy > 3 {
+		return nil, "Invalid privacy setting"
+	}
+	f.Order, err = strconv.Atoi(r.PostFormValue("order"))
+	if err != nil {
+		return nil, "The order must be an integer"
+	}
+	f.Required = r.PostFormValue("required") == "1"
+	f.ShowOnPosts = r.PostFormValue("show-on-posts") == "1"
+
+	for _, sgid := range r.PostForm["groups"] {
+		gid, err := strconv.Atoi(sgid)
+		if err != nil || !c.Groups.Exists(gid) {
+			return nil, "Invalid group"
+		}
+		f.Groups = append(f.Groups, gid)
+	}
+	return f, ""
+}
+
+func ProfileFieldsCreateSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.EditSettings {
+		return c.NoPermissions(w, r, u)
+	}
+
+	f, msg := profileFieldFromForm(r)
+	if msg != "" {
+		return c.LocalError(msg, w, r, u)
+	}
+	pfid, err := c.ProfileFields.Create(f)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.Create("create", pfid, "profile_field", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/profile-fields/?created=1", http.StatusSeeOther)
+	return nil
+}
+
+func ProfileFieldsEdit(w http.ResponseWriter, r *http.Request, u *c.User, spfid string) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "profile_fields_edit", "profile-fields")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.EditSettings {
+		return c.NoPermissions(w, r, u)
+	}
+
+	pfid, err := strconv.Atoi(spfid)
+	if err != nil {
+		return c.LocalError("The profile field ID must be an integer.", w, r, u)
+	}
+	f, err := c.ProfileFields.Get(pfid)
+	if err == sql.ErrNoRows {
+		return c.NotFound(w, r, basePage.Header)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	groups, err := c.Groups.GetAll()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	gList := make([]c.PanelProfileFieldGroup, len(groups))
+	for i, g := range groups {
+		var selected bool
+		for _, gid := range f.Groups {
+			if gid == g.ID {
+				selected = true
+				break
+			}
+		}
+		gList[i] = c.PanelProfileFieldGroup{g, selected}
+	}
+
+	pi := c.PanelProfileFieldEditPage{basePage, f, c.ProfileFieldTypes, gList}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_profile_fields_edit", &pi})
+}
+
+func ProfileFieldsEditSubmit(w http.ResponseWriter, r *http.Request, u *c.User, spfid string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.EditSettings {
+		return c.NoPermissions(w, r, u)
+	}
+
+	pfid, err := strconv.Atoi(spfid)
+	if err != nil {
+		return c.LocalError("The profile field ID must be an integer.", w, r, u)
+	}
+	_, err = c.ProfileFields.Get(pfid)
+	if err == sql.ErrNoRows {
+		return c.LocalError("This profile field doesn't exist.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	f, msg := profileFieldFromForm(r)
+	if msg != "" {
+		return c.LocalError(msg, w, r, u)
+	}
+	f.ID = pfid
+	err = c.ProfileFields.Update(f)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.Create("edit", pfid, "profile_field", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/profile-fields/?updated=1", http.StatusSeeOther)
+	return nil
+}
+
+func ProfileFieldsDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, spfid string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.EditSettings {
+		return c.NoPermissions(w, r, u)
+	}
+
+	pfid, err := strconv.Atoi(spfid)
+	if err != nil {
+		return c.LocalError("The profile field ID must be an integer.", w, r, u)
+	}
+	_, err = c.ProfileFields.Get(pfid)
+	if err == sql.ErrNoRows {
+		return c.LocalError("This profile field doesn't exist.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.ProfileFields.Delete(pfid)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.Create("delete", pfid, "profile_field", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/profile-fields/?deleted=1", http.StatusSeeOther)
+	return nil
+}
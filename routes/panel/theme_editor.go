@@ -0,0 +1,270 @@
+package panel
+
+import (
+	"archive/zip"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	c "github.com/Azareal/Gosora/common"
+)
+
+func ThemesEdit(w http.ResponseWriter, r *http.Request, u *c.User, name string) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "themes_edit", "themes")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+	theme, ok := c.Themes[name]
+	if !ok {
+		return c.NotFound(w, r, basePage.Header)
+	}
+
+	switch r.FormValue("done") {
+	case "settings":
+		basePage.AddNotice("panel_themes_settings_updated")
+	case "file":
+		basePage.AddNotice("panel_themes_file_updated")
+	case "created":
+		basePage.AddNotice("panel_themes_child_created")
+	case "imported":
+		basePage.AddNotice("panel_themes_imported")
+	}
+
+	// The files might have scripts in them, so only the admins can touch them
+	var files []string
+	if u.IsAdmin {
+		var err error
+		files, err = theme.EditableFiles()
+		if err != nil {
+			return c.InternalError(err, w, r)
+		}
+	}
+	var settings []c.PanelThemeSetting
+	for sname, setting := range theme.Settings {
+		settings = append(settings, c.PanelThemeSetting{sname, setting.FriendlyName, setting.Options, theme.Setting(sname)})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Name < settings[j].Name
+	})
+
+	pi := c.PanelThemeEditPage{basePage, theme, files, settings, u.IsAdmin && theme.ForkOf != ""}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_themes_edit", &pi})
+}
+
+func ThemesSettingsSubmit(w http.ResponseWriter, r *http.Request, u *c.User, name string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+	theme, ok := c.Themes[name]
+	if !ok {
+		return c.LocalError("The theme isn't registered in the system", w, r, u)
+	}
+
+	vals := make(map[string]string)
+	for sname := range theme.Settings {
+		if val, ok := r.PostForm["setting-"+sname]; ok && len(val) > 0 {
+			vals[sname] = val[0]
+		}
+	}
+	err := theme.SetSettings(vals)
+	if err == c.ErrThemeSetting {
+		return c.LocalError(err.Error(), w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.CreateExtra("settings", 0, "theme", u.GetIP(), u.ID, theme.Name)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/themes/edit/"+theme.Name+"?done=settings", http.StatusSeeOther)
+	return nil
+}
+
+func ThemesCreateChildSubmit(w http.ResponseWriter, r *http.Request, u *c.User, parentName string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+	parent, ok := c.Themes[parentName]
+	if !ok {
+		return c.LocalError("The theme isn't registered in the system", w, r, u)
+	}
+
+	name := strings.TrimSpace(r.PostFormValue("name"))
+	friendlyName := c.SanitiseSingleLine(r.PostFormValue("friendly-name"))
+	theme, err := c.CreateChildTheme(parent, name, friendlyName)
+	if err == c.ErrThemeName || err == c.ErrThemeExists {
+		return c.LocalError(err.Error(), w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.CreateExtra("create", 0, "theme", u.GetIP(), u.ID, theme.Name)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/themes/edit/"+theme.Name+"?done=created", http.StatusSeeOther)
+	return nil
+}
+
+func ThemesExport(w http.ResponseWriter, r *http.Request, u *c.User, name string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+	theme, ok := c.Themes[name]
+	if !ok {
+		return c.LocalError("The theme isn't registered in the system", w, r, u)
+	}
+
+	h := w.Header()
+	h.Set("Content-Disposition", "attachment; filename="+theme.Name+".zip")
+	h.Set("Content-Type", "application/zip")
+	// The headers are already out, so there isn't much we can do if this fails other than log it
+	err := theme.Export(w)
+	if err != nil {
+		c.LogError(err)
+	}
+	return nil
+}
+
+func ThemesImportSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+
+	f, hdr, err := r.FormFile("theme")
+	if err != nil {
+		return c.LocalError("You need to pick a theme to import.", w, r, u)
+	}
+	defer f.Close()
+	zr, err := zip.NewReader(f, hdr.Size)
+	if err != nil {
+		return c.LocalError(c.ErrBadThemeZip.Error(), w, r, u)
+	}
+	theme, err := c.ImportTheme(zr)
+	switch err {
+	case nil:
+	case c.ErrBadThemeZip, c.ErrThemeName, c.ErrThemeExists, c.ErrThemeZipTooBig:
+		return c.LocalError(err.Error(), w, r, u)
+	default:
+		// It's most likely something wrong with the theme, rather than with us
+		return c.LocalError("This theme couldn't be loaded: "+err.Error(), w, r, u)
+	}
+	err = c.AdminLogs.CreateExtra("import", 0, "theme", u.GetIP(), u.ID, theme.Name)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/themes/edit/"+theme.Name+"?done=imported", http.StatusSeeOther)
+	return nil
+}
+
+func ThemesFileEdit(w http.ResponseWriter, r *http.Request, u *c.User, name string) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "themes_file_edit", "themes")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+	theme, ok := c.Themes[name]
+	if !ok {
+		return c.NotFound(w, r, basePage.Header)
+	}
+	fname := r.FormValue("f")
+	data, err := theme.ReadFile(fname)
+	if err == c.ErrThemeFile {
+		return c.NotFound(w, r, basePage.Header)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	if r.FormValue("done") != "" {
+		basePage.AddNotice("panel_themes_file_updated")
+	}
+	isCSS := filepath.Ext(fname) == ".css"
+	if isCSS {
+		basePage.Header.AddScriptAsync("panel_theme_editor.js")
+	}
+
+	pi := c.PanelThemeFilePage{basePage, theme, fname, string(data), isCSS}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_themes_file_edit", &pi})
+}
+
+func ThemesFileEditSubmit(w http.ResponseWriter, r *http.Request, u *c.User, name string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+	theme, ok := c.Themes[name]
+	if !ok {
+		return c.LocalError("The theme isn't registered in the system", w, r, u)
+	}
+
+	fname := r.PostFormValue("f")
+	content := strings.Replace(r.PostFormValue("content"), "\r\n", "\n", -1)
+	err := theme.WriteFile(fname, []byte(content))
+	switch err {
+	case nil:
+	case c.ErrThemeFile, c.ErrThemeNotChild:
+		return c.LocalError(err.Error(), w, r, u)
+	default:
+		return c.LocalError("This file has a mistake in it, so it's been left as it was: "+err.Error(), w, r, u)
+	}
+	err = c.AdminLogs.CreateExtra("edit", 0, "theme", u.GetIP(), u.ID, theme.Name+"/"+fname)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/themes/file/edit/"+theme.Name+"?f="+url.QueryEscape(fname)+"&done=1", http.StatusSeeOther)
+	return nil
+}
+
+// ThemesFilePreviewSubmit hands back what a stylesheet would look like if it were saved, the editor swaps it into the preview frame
+func ThemesFilePreviewSubmit(w http.ResponseWriter, r *http.Request, u *c.User, name string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageThemes {
+		return c.NoPermissions(w, r, u)
+	}
+	theme, ok := c.Themes[name]
+	if !ok {
+		return c.LocalErrorJS("The theme isn't registered in the system", w, r)
+	}
+	fname := r.PostFormValue("f")
+	if filepath.Ext(fname) != ".css" {
+		return c.LocalErrorJS("Only stylesheets can be previewed", w, r)
+	}
+	out, err := theme.PreviewCSS(fname, []byte(r.PostFormValue("content")))
+	if err != nil {
+		return c.LocalErrorJS(err.Error(), w, r)
+	}
+	w.Header().Set("Content-Type", "text/css; charset=utf-8")
+	w.Write(out)
+	return nil
+}
@@ -406,10 +406,40 @@ func widgetsParseInputs(r *http.Request, widget *c.Widget) (*c.WidgetEdit, error
 		widget.Type = wtype // ? - Are we sure we should be directly assigning user provided data even if it's validated?
 	case "wol", "wol_context", "search_and_filter":
 		widget.Type = wtype // ? - Are we sure we should be directly assigning user provided data even if it's validated?
+	case "recent_topics", "top_posters", "newest_members", "forum_stats":
+		// These have sensible defaults, so the title and count can be left blank
+		data["Name"] = c.SanitiseSingleLine(r.FormValue("wname"))
+		if wtype != "forum_stats" && r.FormValue("wcount") != "" {
+			count, err := strconv.Atoi(r.FormValue("wcount"))
+			if err != nil || count < 1 || count > c.WidgetMaxCount {
+				return nil, errors.New("The number of items needs to be between 1 and " + strconv.Itoa(c.WidgetMaxCount) + ".")
+			}
+			data["Count"] = strconv.Itoa(count)
+		}
+		widget.Type = wtype
+	case "custom_html":
+		data["Name"] = c.SanitiseSingleLine(r.FormValue("wname"))
+		data["Text"] = c.SanitiseWidgetHTML(r.FormValue("wtext"))
+		if strings.TrimSpace(data["Text"]) == "" {
+			return nil, errors.New("You need to fill in the body for this widget.")
+		}
+		widget.Type = wtype
 	default:
 		return nil, errors.New("Unknown widget type")
 	}
 
+	rules, err := c.ParseWidgetRules(map[string]string{
+		"VisGroups":   r.FormValue("wvisgroups"),
+		"VisForums":   r.FormValue("wvisforums"),
+		"VisLoggedin": r.FormValue("wvisloggedin"),
+		"VisRoutes":   r.FormValue("wvisroutes"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	rules.Data(data)
+	widget.Rules = rules
+
 	return &c.WidgetEdit{widget, data}, nil
 }
 
@@ -0,0 +1,195 @@
+package panel
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	c "github.com/Azareal/Gosora/common"
+)
+
+func TopicPrefixes(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "topic_prefixes", "topic-prefixes")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageForums {
+		return c.NoPermissions(w, r, u)
+	}
+	if r.FormValue("created") == "1" {
+		basePage.AddNotice("panel_topic_prefix_created")
+	} else if r.FormValue("updated") == "1" {
+		basePage.AddNotice("panel_topic_prefix_updated")
+	} else if r.FormValue("deleted") == "1" {
+		basePage.AddNotice("panel_topic_prefix_deleted")
+	}
+
+	forums, err := c.Forums.GetAll()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	groups, err := c.Groups.GetAll()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	pi := c.PanelTopicPrefixesPage{basePage, c.TopicPrefixes.GetAll(), forums, groups}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_topic_prefixes", &pi})
+}
+
+// topicPrefixFromForm builds a prefix from the submitted form, returning a message for the user if anything is amiss
+func topicPrefixFromForm(r *http.Request) (*c.TopicPrefix, string) {
+	p := &c.TopicPrefix{}
+	p.Name = c.SanitiseSingleLine(r.PostFormValue("name"))
+	if p.Name == "" {
+		return nil, "You need to give this prefix a name"
+	}
+	p.CSSClass = c.SanitiseSingleLine(r.PostFormValue("css-class"))
+
+	var err error
+	p.Order, err = strconv.Atoi(r.PostFormValue("order"))
+	if err != nil {
+		return nil, "The order must be an integer"
+	}
+	for _, sfid := range r.PostForm["forums"] {
+		fid, err := strconv.Atoi(sfid)
+		if err != nil || !c.Forums.Exists(fid) {
+			return nil, "Invalid forum"
+		}
+		p.Forums = append(p.Forums, fid)
+	}
+	for _, sgid := range r.PostForm["groups"] {
+		gid, err := strconv.Atoi(sgid)
+		if err != nil || !c.Groups.Exists(gid) {
+			return nil, "Invalid group"
+		}
+		p.Groups = append(p.Groups, gid)
+	}
+	return p, ""
+}
+
+func TopicPrefixesCreateSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageForums {
+		return c.NoPermissions(w, r, u)
+	}
+
+	p, msg := topicPrefixFromForm(r)
+	if msg != "" {
+		return c.LocalError(msg, w, r, u)
+	}
+	prid, err := c.TopicPrefixes.Create(p)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.Create("create", prid, "topic_prefix", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/topic-prefixes/?created=1", http.StatusSeeOther)
+	return nil
+}
+
+func TopicPrefixesEdit(w http.ResponseWriter, r *http.Request, u *c.User, sprid string) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "topic_prefixes_edit", "topic-prefixes")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageForums {
+		return c.NoPermissions(w, r, u)
+	}
+
+	prid, err := strconv.Atoi(sprid)
+	if err != nil {
+		return c.LocalError("The prefix ID must be an integer.", w, r, u)
+	}
+	p, err := c.TopicPrefixes.Get(prid)
+	if err == sql.ErrNoRows {
+		return c.NotFound(w, r, basePage.Header)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	forums, err := c.Forums.GetAll()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	groups, err := c.Groups.GetAll()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	pi := c.PanelTopicPrefixEditPage{basePage, p, forums, groups}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_topic_prefixes_edit", &pi})
+}
+
+func TopicPrefixesEditSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sprid string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageForums {
+		return c.NoPermissions(w, r, u)
+	}
+
+	prid, err := strconv.Atoi(sprid)
+	if err != nil {
+		return c.LocalError("The prefix ID must be an integer.", w, r, u)
+	}
+	_, err = c.TopicPrefixes.Get(prid)
+	if err == sql.ErrNoRows {
+		return c.LocalError("This prefix doesn't exist.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	p, msg := topicPrefixFromForm(r)
+	if msg != "" {
+		return c.LocalError(msg, w, r, u)
+	}
+	p.ID = prid
+	err = c.TopicPrefixes.Update(p)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.Create("edit", prid, "topic_prefix", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/topic-prefixes/?updated=1", http.StatusSeeOther)
+	return nil
+}
+
+func TopicPrefixesDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sprid string) c.RouteError {
+	_, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ManageForums {
+		return c.NoPermissions(w, r, u)
+	}
+
+	prid, err := strconv.Atoi(sprid)
+	if err != nil {
+		return c.LocalError("The prefix ID must be an integer.", w, r, u)
+	}
+	_, err = c.TopicPrefixes.Get(prid)
+	if err == sql.ErrNoRows {
+		return c.LocalError("This prefix doesn't exist.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.TopicPrefixes.Delete(prid)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.AdminLogs.Create("delete", prid, "topic_prefix", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	http.Redirect(w, r, "/panel/topic-prefixes/?deleted=1", http.StatusSeeOther)
+	return nil
+}
@@ -0,0 +1,159 @@
+package panel
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	c "github.com/Azareal/Gosora/common"
+)
+
+func Trash(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	return trashPage(w, r, u, false)
+}
+
+func TrashReplies(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	return trashPage(w, r, u, true)
+}
+
+// trashPage lists either the topics or the replies in the trash, they're kept apart as the paginator can't carry anything other than the page across
+func trashPage(w http.ResponseWriter, r *http.Request, u *c.User, replies bool) c.RouteError {
+	basePage, ferr := buildBasePage(w, r, u, "trash", "trash")
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.DeleteTopic && !u.Perms.DeleteReply {
+		return c.NoPermissions(w, r, u)
+	}
+	if r.FormValue("purged") == "1" {
+		basePage.AddNotice("panel_trash_purged")
+	}
+
+	count := c.Trash.CountTopics()
+	if replies {
+		count = c.Trash.CountReplies()
+	}
+	page, _ := strconv.Atoi(r.FormValue("page"))
+	perPage := 12
+	offset, page, lastPage := c.PageOffset(count, page, perPage)
+
+	var items []*c.TrashItem
+	var err error
+	if replies {
+		items, err = c.Trash.Replies(offset, perPage)
+	} else {
+		items, err = c.Trash.Topics(offset, perPage)
+	}
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	// Bulk load the posters and the people who trashed their posts
+	idMap := make(map[int]bool)
+	for _, item := range items {
+		idMap[item.CreatedBy] = true
+		idMap[item.DeletedBy] = true
+	}
+	ids := make([]int, 0, len(idMap))
+	for id := range idMap {
+		ids = append(ids, id)
+	}
+	users, err := c.Users.BulkGetMap(ids)
+	if err != nil && err != sql.ErrNoRows {
+		return c.InternalError(err, w, r)
+	}
+	ilist := make([]c.PanelTrashItem, len(items))
+	for i, item := range items {
+		ilist[i] = c.PanelTrashItem{item, trashUser(users, item.CreatedBy), trashUser(users, item.DeletedBy)}
+	}
+
+	pageList := c.Paginate(page, lastPage, 5)
+	pi := c.PanelTrashPage{basePage, ilist, replies, c.Paginator{pageList, page, lastPage}}
+	return renderTemplate("panel", w, r, basePage.Header, c.Panel{basePage, "", "", "panel_trash", &pi})
+}
+
+func trashUser(users map[int]*c.User, uid int) *c.User {
+	if u, ok := users[uid]; ok {
+		return u
+	}
+	return handleUnknownUser(nil, sql.ErrNoRows)
+}
+
+func TrashPurgeTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid string) c.RouteError {
+	lite, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.DeleteTopic {
+		return c.NoPermissions(w, r, u)
+	}
+
+	tid, err := strconv.Atoi(stid)
+	if err != nil {
+		return c.LocalError("The topic ID must be an integer.", w, r, u)
+	}
+	topic, err := c.Topics.Get(tid)
+	if err == sql.ErrNoRows {
+		return c.LocalError("This topic doesn't exist.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	if !topic.Deleted {
+		return c.LocalError("Only topics which are in the trash can be purged.", w, r, u)
+	}
+	err = topic.Delete()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.ModLogs.Create("purge", tid, "topic", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_purge_topic", tid, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, "/panel/trash/?purged=1", http.StatusSeeOther)
+	return nil
+}
+
+func TrashPurgeReplySubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid string) c.RouteError {
+	lite, ferr := c.SimplePanelUserCheck(w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.DeleteReply {
+		return c.NoPermissions(w, r, u)
+	}
+
+	rid, err := strconv.Atoi(srid)
+	if err != nil {
+		return c.LocalError("The reply ID must be an integer.", w, r, u)
+	}
+	reply, err := c.Rstore.Get(rid)
+	if err == sql.ErrNoRows {
+		return c.LocalError("This reply doesn't exist.", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	if !reply.Deleted {
+		return c.LocalError("Only replies which are in the trash can be purged.", w, r, u)
+	}
+	err = reply.Delete()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	// The reply is gone, so log the topic it was in
+	err = c.ModLogs.Create("purge", reply.ParentID, "reply", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_purge_reply", rid, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, "/panel/trash/replies/?purged=1", http.StatusSeeOther)
+	return nil
+}
@@ -48,9 +48,13 @@ func PollVote(w http.ResponseWriter, r *http.Request, u *c.User, sPollID string)
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic {
+	if !u.CanViewTopic(topic.CreatedBy) {
 		return c.NoPermissions(w, r, u)
 	}
+	// Topics in the trash are only visible to those who could restore them
+	if topic.Deleted && !u.Perms.DeleteTopic {
+		return c.NotFound(w, r, nil)
+	}
 
 	optIndex, err := strconv.Atoi(r.PostFormValue("poll_option_input"))
 	if err != nil {
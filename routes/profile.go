@@ -79,7 +79,7 @@ func ViewProfile(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Head
 
 		reLiked := false
 		reLikeCount := 0
-		ru := &c.ReplyUser{Reply: c.Reply{rid, puser.ID, reContent, reCreatedBy /*, reGroup*/, reCreatedAt, reLastEdit, reLastEditBy, 0, "", reLiked, reLikeCount, 0, ""}, ContentHtml: c.ParseMessage(reContent, 0, "", user.ParseSettings, user), CreatedByName: reCreatedByName, Avatar: reAvatar, Group: reGroup, Level: 0}
+		ru := &c.ReplyUser{Reply: c.Reply{rid, puser.ID, reContent, reCreatedBy /*, reGroup*/, reCreatedAt, reLastEdit, reLastEditBy, 0, "", reLiked, reLikeCount, 0, "", 0, false}, ContentHtml: c.ParseMessage(reContent, 0, "", user.ParseSettings, user), CreatedByName: reCreatedByName, Avatar: reAvatar, Group: reGroup, Level: 0}
 		_, err = ru.Init(user)
 		if err != nil {
 			return c.InternalError(err, w, r)
@@ -121,6 +121,11 @@ func ViewProfile(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Head
 		canMessage = false
 	}
 
-	ppage := c.ProfilePage{h, reList, *puser, currentScore, nextScore, blocked, canMessage, canComment, showComments}
+	fields, err := c.ProfileFields.Show(puser, user)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	ppage := c.ProfilePage{h, reList, *puser, currentScore, nextScore, blocked, canMessage, canComment, showComments, fields}
 	return renderTemplate("profile", w, r, h, ppage)
 }
@@ -24,7 +24,7 @@ var replyStmts ReplyStmts
 func init() {
 	c.DbInits.Add(func(acc *qgen.Accumulator) error {
 		replyStmts = ReplyStmts{
-			createReplyPaging: acc.Select("replies").Cols("rid").Where("rid >= ? - 1 AND tid=?").Orderby("rid ASC").Prepare(),
+			createReplyPaging: acc.Select("replies").Cols("rid").Where("rid >= ? - 1 AND tid=? AND deleted=0").Orderby("rid ASC").Prepare(),
 		}
 		return acc.FirstError()
 	})
@@ -54,12 +54,16 @@ func CreateReplySubmit(w http.ResponseWriter, r *http.Request, user *c.User) c.R
 	if ferr != nil {
 		return ferr
 	}
-	if !user.Perms.ViewTopic || !user.Perms.CreateReply {
+	if !user.CanReplyTo(topic.CreatedBy) {
 		return c.NoPermissionsJSQ(w, r, user, js)
 	}
 	if topic.IsClosed && !user.Perms.CloseTopic {
 		return c.NoPermissionsJSQ(w, r, user, js)
 	}
+	// Topics in the trash are only visible to those who could restore them
+	if topic.Deleted && !user.Perms.DeleteTopic {
+		return c.NotFoundJSQ(w, r, nil, js)
+	}
 
 	content := c.PreparseMessage(r.PostFormValue("content"))
 	// TODO: Fully parse the post and put that in the parsed column
@@ -67,6 +71,10 @@ func CreateReplySubmit(w http.ResponseWriter, r *http.Request, user *c.User) c.R
 	if err != nil {
 		return c.InternalErrorJSQ(err, w, r, js)
 	}
+	err = c.Drafts.Delete(user.ID, "topic", topic.ID)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
 
 	reply, err := c.Rstore.Get(rid)
 	if err != nil {
@@ -235,14 +243,14 @@ func ReplyEditSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid str
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.EditReply {
-		return c.NoPermissionsJSQ(w, r, u, js)
+	if !u.CanEditReply(topic.CreatedBy, reply.CreatedBy, reply.CreatedAt) {
+		return ownPostDenied(w, r, u, u.Perms.EditOwnReply, reply.CreatedBy, c.OwnPostEditWindow(), "own_post_edit_window_closed", js)
 	}
 	if topic.IsClosed && !u.Perms.CloseTopic {
 		return c.NoPermissionsJSQ(w, r, u, js)
 	}
 
-	err = reply.SetPost(r.PostFormValue("edit_item"))
+	err = reply.SetPost(r.PostFormValue("edit_item"), u.GetIP(), u.ID)
 	if err == sql.ErrNoRows {
 		return c.PreErrorJSQ("The parent topic doesn't exist.", w, r, js)
 	} else if err != nil {
@@ -302,12 +310,10 @@ func ReplyDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid s
 	if ferr != nil {
 		return ferr
 	}
-	if reply.CreatedBy != u.ID {
-		if !u.Perms.ViewTopic || !u.Perms.DeleteReply {
-			return c.NoPermissionsJSQ(w, r, u, js)
-		}
+	if !u.CanDeleteReply(topic.CreatedBy, reply.CreatedBy, reply.CreatedAt) {
+		return ownPostDenied(w, r, u, u.Perms.DeleteOwnReply, reply.CreatedBy, c.OwnPostDeleteWindow(), "own_post_delete_window_closed", js)
 	}
-	if err := reply.Delete(); err != nil {
+	if err := reply.SoftDelete(u.ID); err != nil {
 		return c.InternalErrorJSQ(err, w, r, js)
 	}
 
@@ -366,8 +372,8 @@ func AddAttachToReplySubmit(w http.ResponseWriter, r *http.Request, u *c.User, s
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.EditReply || !u.Perms.UploadFiles {
-		return c.NoPermissionsJS(w, r, u)
+	if !u.CanEditReply(topic.CreatedBy, reply.CreatedBy, reply.CreatedAt) || !u.Perms.UploadFiles {
+		return ownPostDenied(w, r, u, u.Perms.EditOwnReply && u.Perms.UploadFiles, reply.CreatedBy, c.OwnPostEditWindow(), "own_post_edit_window_closed", true)
 	}
 	if topic.IsClosed && !u.Perms.CloseTopic {
 		return c.NoPermissionsJS(w, r, u)
@@ -423,8 +429,8 @@ func RemoveAttachFromReplySubmit(w http.ResponseWriter, r *http.Request, u *c.Us
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.EditReply {
-		return c.NoPermissionsJS(w, r, u)
+	if !u.CanEditReply(topic.CreatedBy, reply.CreatedBy, reply.CreatedAt) {
+		return ownPostDenied(w, r, u, u.Perms.EditOwnReply, reply.CreatedBy, c.OwnPostEditWindow(), "own_post_edit_window_closed", true)
 	}
 	if topic.IsClosed && !u.Perms.CloseTopic {
 		return c.NoPermissionsJS(w, r, u)
@@ -481,9 +487,13 @@ func ReplyLikeSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid str
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.LikeItem {
+	if !u.CanViewTopic(topic.CreatedBy) || !u.Perms.LikeItem {
 		return c.NoPermissionsJSQ(w, r, u, js)
 	}
+	// Topics in the trash are only visible to those who could restore them
+	if topic.Deleted && !u.Perms.DeleteTopic {
+		return c.NotFoundJSQ(w, r, nil, js)
+	}
 	if reply.CreatedBy == u.ID {
 		return c.LocalErrorJSQ("You can't like your own replies", w, r, u, js)
 	}
@@ -542,9 +552,13 @@ func ReplyUnlikeSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid s
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.LikeItem {
+	if !u.CanViewTopic(topic.CreatedBy) || !u.Perms.LikeItem {
 		return c.NoPermissionsJSQ(w, r, u, js)
 	}
+	// Topics in the trash are only visible to those who could restore them
+	if topic.Deleted && !u.Perms.DeleteTopic {
+		return c.NotFoundJSQ(w, r, nil, js)
+	}
 
 	_, err = c.Users.Get(reply.CreatedBy)
 	if err != nil && err != sql.ErrNoRows {
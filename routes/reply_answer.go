@@ -0,0 +1,139 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	c "github.com/Azareal/Gosora/common"
+)
+
+// questionReplyCommon loads a reply along with it's topic for the question forum actions, making sure the forum is actually in question mode
+func questionReplyCommon(w http.ResponseWriter, r *http.Request, u *c.User, srid string, js bool) (reply *c.Reply, topic *c.Topic, lite *c.HeaderLite, ferr c.RouteError) {
+	rid, err := strconv.Atoi(srid)
+	if err != nil {
+		return nil, nil, nil, c.PreErrorJSQ("The provided Reply ID is not a valid number.", w, r, js)
+	}
+	reply, err = c.Rstore.Get(rid)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil, c.PreErrorJSQ("The target reply doesn't exist.", w, r, js)
+	} else if err != nil {
+		return nil, nil, nil, c.InternalErrorJSQ(err, w, r, js)
+	}
+	topic, err = c.Topics.Get(reply.ParentID)
+	if err == sql.ErrNoRows {
+		return nil, nil, nil, c.PreErrorJSQ("The parent topic doesn't exist.", w, r, js)
+	} else if err != nil {
+		return nil, nil, nil, c.InternalErrorJSQ(err, w, r, js)
+	}
+
+	// TODO: Add hooks to make use of headerLite
+	lite, ferr = c.SimpleForumUserCheck(w, r, u, topic.ParentID)
+	if ferr != nil {
+		return nil, nil, nil, ferr
+	}
+	if !u.CanViewTopic(topic.CreatedBy) {
+		return nil, nil, nil, c.NoPermissionsJSQ(w, r, u, js)
+	}
+	forum, err := c.Forums.Get(topic.ParentID)
+	if err != nil {
+		return nil, nil, nil, c.InternalErrorJSQ(err, w, r, js)
+	}
+	if !forum.Questions {
+		return nil, nil, nil, c.LocalErrorJSQ("This forum isn't for questions.", w, r, u, js)
+	}
+	if reply.ActionType != "" {
+		return nil, nil, nil, c.LocalErrorJSQ("You can't do that to an action.", w, r, u, js)
+	}
+	if reply.Deleted || topic.Deleted {
+		return nil, nil, nil, c.LocalErrorJSQ("You can't do that to something which is in the trash.", w, r, u, js)
+	}
+	return reply, topic, lite, nil
+}
+
+// canAcceptAnswer tells you whether u is allowed to pick the answer to a question, the forum perms need to have been loaded for u beforehand
+func canAcceptAnswer(u *c.User, createdBy int, isClosed bool) bool {
+	return u.Loggedin && (u.ID == createdBy || u.Perms.EditTopic) && (!isClosed || u.Perms.CloseTopic)
+}
+
+func ReplyAcceptSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid string) c.RouteError {
+	js := r.PostFormValue("js") == "1"
+	reply, topic, lite, ferr := questionReplyCommon(w, r, u, srid, js)
+	if ferr != nil {
+		return ferr
+	}
+	if !canAcceptAnswer(u, topic.CreatedBy, topic.IsClosed) {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+
+	err := topic.SetAnswer(reply.ID)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_accept_answer", reply.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	return actionSuccess(w, r, "/topic/"+strconv.Itoa(reply.ParentID), js)
+}
+
+func ReplyUnacceptSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid string) c.RouteError {
+	js := r.PostFormValue("js") == "1"
+	reply, topic, lite, ferr := questionReplyCommon(w, r, u, srid, js)
+	if ferr != nil {
+		return ferr
+	}
+	if !canAcceptAnswer(u, topic.CreatedBy, topic.IsClosed) {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+	if topic.Answer != reply.ID {
+		return c.LocalErrorJSQ("This reply isn't the accepted answer.", w, r, u, js)
+	}
+
+	err := topic.SetAnswer(0)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_unaccept_answer", reply.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	return actionSuccess(w, r, "/topic/"+strconv.Itoa(reply.ParentID), js)
+}
+
+func ReplyUpvoteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid string) c.RouteError {
+	return replyVoteSubmit(w, r, u, srid, 1)
+}
+
+func ReplyDownvoteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid string) c.RouteError {
+	return replyVoteSubmit(w, r, u, srid, -1)
+}
+
+// Voting the same way twice takes the vote back, so there isn't a separate route for that
+func replyVoteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid string, weight int) c.RouteError {
+	js := r.PostFormValue("js") == "1"
+	reply, _, lite, ferr := questionReplyCommon(w, r, u, srid, js)
+	if ferr != nil {
+		return ferr
+	}
+	// Votes are a kind of like, so they're covered by the same permission
+	if !u.Perms.LikeItem {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+	if reply.CreatedBy == u.ID {
+		return c.LocalErrorJSQ("You can't vote on your own replies", w, r, u, js)
+	}
+
+	err := c.Votes.Vote(reply, u.ID, weight)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_vote_reply", reply.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	return actionSuccess(w, r, "/topic/"+strconv.Itoa(reply.ParentID), js)
+}
@@ -0,0 +1,213 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	c "github.com/Azareal/Gosora/common"
+	p "github.com/Azareal/Gosora/common/phrases"
+)
+
+// TopicRevisions shows the past versions of a topic and what changed between them
+func TopicRevisions(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, stid string) c.RouteError {
+	tid, err := strconv.Atoi(stid)
+	if err != nil {
+		return c.SimpleError(p.GetErrorPhrase("url_id_must_be_integer"), w, r, h)
+	}
+	t, err := c.Topics.Get(tid)
+	if err == sql.ErrNoRows {
+		return c.NotFound(w, r, h)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	ferr := c.ForumUserCheck(h, w, r, u, t.ParentID)
+	if ferr != nil {
+		return ferr
+	}
+	// Only the author and the people who can edit it get to see how it was changed
+	if !u.CanViewTopic(t.CreatedBy) || !(u.Perms.EditTopic || (u.Loggedin && u.ID == t.CreatedBy)) {
+		return c.NoPermissions(w, r, u)
+	}
+	if t.Deleted && !u.Perms.DeleteTopic {
+		return c.NotFound(w, r, h)
+	}
+	return revisionsPage(w, r, h, t, t.ID, "topics", u.Perms.EditTopic, "/topic/revert/submit/")
+}
+
+// ReplyRevisions shows the past versions of a reply and what changed between them
+func ReplyRevisions(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, srid string) c.RouteError {
+	rid, err := strconv.Atoi(srid)
+	if err != nil {
+		return c.SimpleError(p.GetErrorPhrase("url_id_must_be_integer"), w, r, h)
+	}
+	reply, err := c.Rstore.Get(rid)
+	if err == sql.ErrNoRows {
+		return c.NotFound(w, r, h)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	t, err := reply.Topic()
+	if err == sql.ErrNoRows {
+		return c.NotFound(w, r, h)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	ferr := c.ForumUserCheck(h, w, r, u, t.ParentID)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.CanViewTopic(t.CreatedBy) || !(u.Perms.EditReply || (u.Loggedin && u.ID == reply.CreatedBy)) {
+		return c.NoPermissions(w, r, u)
+	}
+	if reply.Deleted && !u.Perms.DeleteReply {
+		return c.NotFound(w, r, h)
+	}
+	return revisionsPage(w, r, h, t, reply.ID, "replies", u.Perms.EditReply, "/reply/revert/submit/")
+}
+
+func revisionsPage(w http.ResponseWriter, r *http.Request, h *c.Header, t *c.Topic, id int, typ string, canRevert bool, revertPath string) c.RouteError {
+	revs, err := c.Revisions.GetList(id, typ)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	h.Title = p.GetTitlePhrase("revisions")
+	h.Zone = "revisions"
+	h.ZoneID = t.ID
+
+	pi := c.RevisionsPage{Header: h, Topic: t, CanRevert: canRevert, RevertPath: revertPath}
+	if len(revs) > 1 {
+		// The last edit is shown, unless they've picked a pair of revisions to compare
+		get := func(field string, def *c.Revision) *c.Revision {
+			reid, err := strconv.Atoi(r.FormValue(field))
+			if err != nil {
+				return def
+			}
+			for _, rev := range revs {
+				if rev.ID == reid {
+					return rev
+				}
+			}
+			return def
+		}
+		pi.From = get("from", revs[len(revs)-2])
+		pi.To = get("to", revs[len(revs)-1])
+		pi.TitleDiff = c.Diff(pi.From.Title, pi.To.Title)
+		pi.Diff = c.Diff(pi.From.Content, pi.To.Content)
+	}
+
+	uids := make([]int, 0, len(revs))
+	for _, rev := range revs {
+		uids = append(uids, rev.CreatedBy)
+	}
+	users, err := c.Users.BulkGetMap(uids)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	for i := len(revs) - 1; i >= 0; i-- {
+		creator, ok := users[revs[i].CreatedBy]
+		if !ok {
+			creator = &c.User{Name: p.GetTmplPhrase("user_unknown"), Link: c.BuildProfileURL("unknown", 0)}
+		}
+		pi.ItemList = append(pi.ItemList, c.RevisionItem{revs[i], creator})
+	}
+	return renderTemplate("revisions", w, r, h, pi)
+}
+
+func revisionActionPre(sreid, typ string, w http.ResponseWriter, r *http.Request) (*c.Revision, c.RouteError) {
+	reid, err := strconv.Atoi(sreid)
+	if err != nil {
+		return nil, c.PreError(p.GetErrorPhrase("id_must_be_integer"), w, r)
+	}
+	rev, err := c.Revisions.Get(reid)
+	if err == sql.ErrNoRows || (err == nil && rev.ContentType != typ) {
+		return nil, c.PreError("The revision you tried to go back to doesn't exist.", w, r)
+	} else if err != nil {
+		return nil, c.InternalError(err, w, r)
+	}
+	return rev, nil
+}
+
+// TopicRevertSubmit puts a topic back the way it was in one of its revisions
+func TopicRevertSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sreid string) c.RouteError {
+	rev, ferr := revisionActionPre(sreid, "topics", w, r)
+	if ferr != nil {
+		return ferr
+	}
+	t, lite, ferr := topicActionPre(strconv.Itoa(rev.ContentID), "revert", w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.CanViewTopic(t.CreatedBy) || !u.Perms.EditTopic {
+		return c.NoPermissions(w, r, u)
+	}
+	if t.IsClosed && !u.Perms.CloseTopic {
+		return c.NoPermissions(w, r, u)
+	}
+
+	err := t.Revert(rev, u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.ModLogs.Create("revert", t.ID, "topic", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_revert_topic", t.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, rev.Link(), http.StatusSeeOther)
+	return nil
+}
+
+// ReplyRevertSubmit puts a reply back the way it was in one of its revisions
+func ReplyRevertSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sreid string) c.RouteError {
+	rev, ferr := revisionActionPre(sreid, "replies", w, r)
+	if ferr != nil {
+		return ferr
+	}
+	reply, err := c.Rstore.Get(rev.ContentID)
+	if err == sql.ErrNoRows {
+		return c.PreError("The reply you tried to revert doesn't exist.", w, r)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	t, err := reply.Topic()
+	if err == sql.ErrNoRows {
+		return c.PreError("The parent topic doesn't exist.", w, r)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	// TODO: Add hooks to make use of headerLite
+	lite, ferr := c.SimpleForumUserCheck(w, r, u, t.ParentID)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.CanViewTopic(t.CreatedBy) || !u.Perms.EditReply {
+		return c.NoPermissions(w, r, u)
+	}
+	if t.IsClosed && !u.Perms.CloseTopic {
+		return c.NoPermissions(w, r, u)
+	}
+
+	err = reply.Revert(rev, u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.ModLogs.Create("revert", reply.ID, "reply", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_revert_reply", reply.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, rev.Link(), http.StatusSeeOther)
+	return nil
+}
@@ -63,9 +63,15 @@ func ViewTopic(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Header
 	if ferr != nil {
 		return ferr
 	}
-	if !user.Perms.ViewTopic {
+	if !user.CanViewTopic(topic.CreatedBy) {
 		return c.NoPermissions(w, r, user)
 	}
+	// The quick reply form and the like go off this copy of the permissions, so fold the reply to own topic permission into it
+	user.Perms.CreateReply = user.CanReplyTo(topic.CreatedBy)
+	// Topics in the trash are only visible to those who could restore them
+	if topic.Deleted && !user.Perms.DeleteTopic {
+		return c.NotFound(w, r, h)
+	}
 	h.Title = topic.Title
 	h.Path = topic.Link
 	//h.Path = c.BuildTopicURL(c.NameToSlug(topic.Title), topic.ID)
@@ -104,7 +110,19 @@ func ViewTopic(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Header
 	if postGroup.IsMod {
 		topic.ClassName = c.Config.StaffCSS
 	}
-	topic.Deletable = user.Perms.DeleteTopic || topic.CreatedBy == user.ID
+	topic.Deletable = user.CanDeleteTopic(topic.CreatedBy, topic.CreatedAt)
+	topic.Editable = user.CanEditTopic(topic.CreatedBy, topic.CreatedAt)
+	topic.Closable = user.CanCloseTopic(topic.CreatedBy)
+	if topic.Prefix != 0 {
+		if prefix, err := c.TopicPrefixes.Get(topic.Prefix); err == nil {
+			topic.PrefixName, topic.PrefixClass = prefix.Name, prefix.CSSClass
+		}
+	}
+	tags, err := c.TopicTags.Get(topic.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	topic.Tags = c.TagsToTopicTags(tags)
 
 	forum, err := c.Forums.Get(topic.ParentID)
 	if err != nil {
@@ -142,17 +160,37 @@ func ViewTopic(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Header
 	}
 
 	// Calculate the offset
-	offset, page, lastPage := c.PageOffset(topic.PostCount, page, c.Config.ItemsPerPage)
+	canSeeDeleted := user.Perms.DeleteReply
+	showDeleted := canSeeDeleted && r.FormValue("deleted") == "1"
+	postCount := topic.PostCount
+	if showDeleted {
+		postCount += c.Trash.CountRepliesIn(topic.ID)
+	}
+	offset, page, lastPage := c.PageOffset(postCount, page, c.Config.ItemsPerPage)
 	pageList := c.Paginate(page, lastPage, 5)
-	tpage := c.TopicPage{h, nil, topic, forum, poll, c.Paginator{pageList, page, lastPage}}
+	var prefixes []*c.TopicPrefix
+	if topic.Editable {
+		prefixes = c.TopicPrefixes.GetUsable(topic.ParentID, user)
+	}
+	sortVotes := forum.Questions && r.FormValue("sort") == "votes"
+	tpage := c.TopicPage{h, nil, topic, forum, poll, c.Paginator{pageList, page, lastPage}, prefixes, strings.Join(tags, ", "), nil, false, sortVotes, canSeeDeleted, showDeleted, append(forum.Parents(), forum)}
+	if forum.Questions {
+		tpage.CanAccept = canAcceptAnswer(user, topic.CreatedBy, topic.IsClosed)
+		if topic.Answer != 0 {
+			tpage.Answer, err = topic.AnswerReply(user)
+			if err != nil && err != sql.ErrNoRows {
+				return c.InternalError(err, w, r)
+			}
+		}
+	}
 
 	// Get the replies if we have any...
-	if topic.PostCount > 0 {
+	if postCount > 0 {
 		/*var pFrag int
 		if strings.HasPrefix(r.URL.Fragment, "post-") {
 			pFrag, _ = strconv.Atoi(strings.TrimPrefix(r.URL.Fragment, "post-"))
 		}*/
-		rlist, externalHead, err := topic.Replies(offset /* pFrag,*/, user)
+		rlist, externalHead, err := topic.Replies(offset /* pFrag,*/, user, sortVotes, showDeleted)
 		if err == sql.ErrNoRows {
 			return c.LocalError("Bad Page. Some of the posts may have been deleted or you got here by directly typing in the page number.", w, r, user)
 		} else if err != nil {
@@ -163,6 +201,47 @@ func ViewTopic(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Header
 			h.ExternalMedia = true
 		}
 	}
+	if forum.Questions && len(tpage.ItemList) > 0 {
+		rids := make([]int, len(tpage.ItemList))
+		for i, ru := range tpage.ItemList {
+			rids[i] = ru.ID
+			ru.Accepted = ru.ID == topic.Answer
+		}
+		if user.Loggedin {
+			votes, err := c.Votes.BulkGetMap(rids, user.ID)
+			if err != nil {
+				return c.InternalError(err, w, r)
+			}
+			for _, ru := range tpage.ItemList {
+				ru.Upvoted = votes[ru.ID] > 0
+				ru.Downvoted = votes[ru.ID] < 0
+			}
+		}
+	}
+
+	// TODO: Cache the post fields alongside the user?
+	uids := []int{topic.CreatedBy}
+	for _, ru := range tpage.ItemList {
+		if ru.CreatedBy != topic.CreatedBy && ru.ActionType == "" {
+			uids = append(uids, ru.CreatedBy)
+		}
+	}
+	if tpage.Answer != nil {
+		uids = append(uids, tpage.Answer.CreatedBy)
+	}
+	postFields, err := c.ProfileFields.BulkShowOnPosts(uids, user)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	if len(postFields) > 0 {
+		tpage.Topic.ProfileFields = postFields[topic.CreatedBy]
+		for _, ru := range tpage.ItemList {
+			ru.ProfileFields = postFields[ru.CreatedBy]
+		}
+		if tpage.Answer != nil {
+			tpage.Answer.ProfileFields = postFields[tpage.Answer.CreatedBy]
+		}
+	}
 
 	h.Zone = "view_topic"
 	h.ZoneID = topic.ID
@@ -230,8 +309,8 @@ func AddAttachToTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, s
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.EditTopic || !u.Perms.UploadFiles {
-		return c.NoPermissionsJS(w, r, u)
+	if !u.CanEditTopic(topic.CreatedBy, topic.CreatedAt) || !u.Perms.UploadFiles {
+		return ownPostDenied(w, r, u, u.Perms.EditOwnTopic && u.Perms.UploadFiles, topic.CreatedBy, c.OwnPostEditWindow(), "own_post_edit_window_closed", true)
 	}
 
 	// Handle the file attachments
@@ -257,12 +336,12 @@ func AddAttachToTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, s
 }
 
 func RemoveAttachFromTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid string) c.RouteError {
-	_, ferr := AttachTopicActCommon(w, r, u, stid)
+	topic, ferr := AttachTopicActCommon(w, r, u, stid)
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.EditTopic {
-		return c.NoPermissionsJS(w, r, u)
+	if !u.CanEditTopic(topic.CreatedBy, topic.CreatedAt) {
+		return ownPostDenied(w, r, u, u.Perms.EditOwnTopic, topic.CreatedBy, c.OwnPostEditWindow(), "own_post_edit_window_closed", true)
 	}
 
 	for _, said := range strings.Split(r.PostFormValue("aids"), ",") {
@@ -304,7 +383,7 @@ func CreateTopic(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header,
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.CreateTopic {
+	if !(u.Perms.ViewTopic || u.Perms.ViewOwnTopic) || !u.Perms.CreateTopic {
 		return c.NoPermissions(w, r, u)
 	}
 	// TODO: Add a phrase for this
@@ -344,7 +423,7 @@ func CreateTopic(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header,
 
 		// Do a bulk forum fetch, just in case it's the SqlForumStore?
 		f := c.Forums.DirtyGet(ffid)
-		if f.Name != "" && f.Active {
+		if f.Name != "" && f.Active && !f.Category {
 			fcopy := f.Copy()
 			// TODO: Abstract this
 			//if h.Hooks.HookSkip("topic_create_frow_assign", &fcopy) {
@@ -355,7 +434,21 @@ func CreateTopic(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header,
 		}
 	}
 
-	return renderTemplate("create_topic", w, r, h, c.CreateTopicPage{h, forumList, fid})
+	// The forum can be changed on the page, so offer every prefix which can be used in at least one of the forums
+	var prefixes []*c.TopicPrefix
+	for _, prefix := range c.TopicPrefixes.GetAll() {
+		if !prefix.CanUse(u) {
+			continue
+		}
+		for _, f := range forumList {
+			if prefix.AppliesTo(f.ID) {
+				prefixes = append(prefixes, prefix)
+				break
+			}
+		}
+	}
+
+	return renderTemplate("create_topic", w, r, h, c.CreateTopicPage{h, forumList, fid, prefixes})
 }
 
 func CreateTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
@@ -364,29 +457,36 @@ func CreateTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.Rout
 		return c.LocalError(phrases.GetErrorPhrase("id_must_be_integer"), w, r, u)
 	}
 	// TODO: Add hooks to make use of headerLite
-	lite, ferr := c.SimpleForumUserCheck(w, r, u, fid)
+	_, ferr := c.SimpleForumUserCheck(w, r, u, fid)
 	if ferr != nil {
 		return ferr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.CreateTopic {
+	if !(u.Perms.ViewTopic || u.Perms.ViewOwnTopic) || !u.Perms.CreateTopic {
 		return c.NoPermissions(w, r, u)
 	}
 
+	prefix, rerr := topicPrefixFromForm(w, r, u, fid, false)
+	if rerr != nil {
+		return rerr
+	}
+	publishAt, rerr := topicPublishAtFromForm(w, r, u)
+	if rerr != nil {
+		return rerr
+	}
+	if !publishAt.IsZero() {
+		return scheduleTopic(w, r, u, fid, prefix, publishAt)
+	}
+
 	name := c.SanitiseSingleLine(r.PostFormValue("name"))
 	content := c.PreparseMessage(r.PostFormValue("content"))
 	// TODO: Fully parse the post and store it in the parsed column
 	tid, err := c.Topics.Create(fid, name, content, u.ID, u.GetIP())
 	if err != nil {
-		switch err {
-		case c.ErrNoRows:
-			return c.LocalError("Something went wrong, perhaps the forum got deleted?", w, r, u)
-		case c.ErrNoTitle:
-			return c.LocalError("This topic doesn't have a title", w, r, u)
-		case c.ErrLongTitle:
-			return c.LocalError("The length of the title is too long, max: "+strconv.Itoa(c.Config.MaxTopicTitleLength), w, r, u)
-		case c.ErrNoBody:
-			return c.LocalError("This topic doesn't have a body", w, r, u)
-		}
+		return createTopicError(err, w, r, u)
+	}
+
+	err = c.Drafts.Delete(u.ID, "forum", fid)
+	if err != nil {
 		return c.InternalError(err, w, r)
 	}
 
@@ -394,6 +494,18 @@ func CreateTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.Rout
 	if err != nil {
 		return c.LocalError("Unable to load the topic", w, r, u)
 	}
+	if prefix != 0 {
+		err = topic.SetPrefix(prefix)
+		if err != nil {
+			return c.InternalError(err, w, r)
+		}
+	}
+	if tags := c.ParseTags(r.PostFormValue("tags")); len(tags) > 0 {
+		err = c.TopicTags.Set(tid, tags)
+		if err != nil {
+			return c.InternalError(err, w, r)
+		}
+	}
 	if r.PostFormValue("has_poll") == "1" {
 		maxPollOptions := 10
 		pollInputItems := make(map[int]string)
@@ -456,10 +568,7 @@ func CreateTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.Rout
 		}
 	}
 
-	co.PostCounter.Bump()
-	co.TopicCounter.Bump()
-	// TODO: Pass more data to this hook?
-	skip, rerr := lite.Hooks.VhookSkippable("action_end_create_topic", tid, u)
+	skip, rerr := TopicCreated(tid, u)
 	if skip || rerr != nil {
 		return rerr
 	}
@@ -467,6 +576,53 @@ func CreateTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User) c.Rout
 	return nil
 }
 
+// TopicCreated is run once a topic has gone up, however it got there, the scheduled topics reach it through c.TopicCreatedAlias
+func TopicCreated(tid int, u *c.User) (skip bool, rerr c.RouteError) {
+	co.PostCounter.Bump()
+	co.TopicCounter.Bump()
+	// TODO: Pass more data to this hook?
+	return c.GetHookTable().VhookSkippable("action_end_create_topic", tid, u)
+}
+
+// topicPrefixFromForm validates the prefix the user picked for a topic in the forum fid, zero means no prefix
+func topicPrefixFromForm(w http.ResponseWriter, r *http.Request, u *c.User, fid int, js bool) (int, c.RouteError) {
+	sprefix := r.PostFormValue("prefix")
+	if sprefix == "" || sprefix == "0" {
+		return 0, nil
+	}
+	prid, err := strconv.Atoi(sprefix)
+	if err != nil {
+		return 0, c.LocalErrorJSQ("The prefix ID must be an integer", w, r, u, js)
+	}
+	prefix, err := c.TopicPrefixes.Get(prid)
+	if err == sql.ErrNoRows {
+		return 0, c.LocalErrorJSQ("That prefix doesn't exist", w, r, u, js)
+	} else if err != nil {
+		return 0, c.InternalErrorJSQ(err, w, r, js)
+	}
+	if !prefix.AppliesTo(fid) || !prefix.CanUse(u) {
+		return 0, c.LocalErrorJSQ("You can't use that prefix here", w, r, u, js)
+	}
+	return prid, nil
+}
+
+// createTopicError turns the errors which can come out of creating a topic into the page the user sees
+func createTopicError(err error, w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	switch err {
+	case c.ErrNoRows:
+		return c.LocalError("Something went wrong, perhaps the forum got deleted?", w, r, u)
+	case c.ErrNoTitle:
+		return c.LocalError("This topic doesn't have a title", w, r, u)
+	case c.ErrLongTitle:
+		return c.LocalError("The length of the title is too long, max: "+strconv.Itoa(c.Config.MaxTopicTitleLength), w, r, u)
+	case c.ErrNoBody:
+		return c.LocalError("This topic doesn't have a body", w, r, u)
+	case c.ErrCantPublish:
+		return c.LocalError("The author of this topic isn't allowed to publish it anymore, so it's been turned back into one of their drafts", w, r, u)
+	}
+	return c.InternalError(err, w, r)
+}
+
 // TODO: Move this function
 func uploadFilesWithHash(w http.ResponseWriter, r *http.Request, u *c.User, dir string) (filenames []string, rerr c.RouteError) {
 	files, ok := r.MultipartForm.File["upload_files"]
@@ -580,14 +736,24 @@ func EditTopicSubmit(w http.ResponseWriter, r *http.Request, user *c.User, stid
 	if ferr != nil {
 		return ferr
 	}
-	if !user.Perms.ViewTopic || !user.Perms.EditTopic {
-		return c.NoPermissionsJSQ(w, r, user, js)
+	if !user.CanEditTopic(topic.CreatedBy, topic.CreatedAt) {
+		return ownPostDenied(w, r, user, user.Perms.EditOwnTopic, topic.CreatedBy, c.OwnPostEditWindow(), "own_post_edit_window_closed", js)
 	}
 	if topic.IsClosed && !user.Perms.CloseTopic {
 		return c.NoPermissionsJSQ(w, r, user, js)
 	}
 
-	err = topic.Update(r.PostFormValue("name"), r.PostFormValue("content"))
+	// The prefix and tags are optional, so that older clients which don't know about them don't wipe them out
+	_, setPrefix := r.PostForm["prefix"]
+	var prefix int
+	if setPrefix {
+		prefix, ferr = topicPrefixFromForm(w, r, user, topic.ParentID, js)
+		if ferr != nil {
+			return ferr
+		}
+	}
+
+	err = topic.Update(r.PostFormValue("name"), r.PostFormValue("content"), user.GetIP(), user.ID)
 	// TODO: Avoid duplicating this across this route and the topic creation route
 	if err != nil {
 		switch err {
@@ -600,6 +766,18 @@ func EditTopicSubmit(w http.ResponseWriter, r *http.Request, user *c.User, stid
 		}
 		return c.InternalErrorJSQ(err, w, r, js)
 	}
+	if setPrefix && prefix != topic.Prefix {
+		err = topic.SetPrefix(prefix)
+		if err != nil {
+			return c.InternalErrorJSQ(err, w, r, js)
+		}
+	}
+	if _, ok := r.PostForm["tags"]; ok {
+		err = c.TopicTags.Set(topic.ID, c.ParseTags(r.PostFormValue("tags")))
+		if err != nil {
+			return c.InternalErrorJSQ(err, w, r, js)
+		}
+	}
 
 	err = c.Forums.UpdateLastTopic(topic.ID, user.ID, topic.ParentID)
 	if err != nil && err != sql.ErrNoRows {
@@ -669,14 +847,12 @@ func DeleteTopicSubmit(w http.ResponseWriter, r *http.Request, user *c.User) c.R
 		if ferr != nil {
 			return ferr
 		}
-		if topic.CreatedBy != user.ID {
-			if !user.Perms.ViewTopic || !user.Perms.DeleteTopic {
-				return c.NoPermissionsJSQ(w, r, user, js)
-			}
+		if !user.CanDeleteTopic(topic.CreatedBy, topic.CreatedAt) {
+			return ownPostDenied(w, r, user, user.Perms.DeleteOwnTopic, topic.CreatedBy, c.OwnPostDeleteWindow(), "own_post_delete_window_closed", js)
 		}
 
 		// We might be able to handle this err better
-		err = topic.Delete()
+		err = topic.SoftDelete(user.ID)
 		if err != nil {
 			return c.InternalErrorJSQ(err, w, r, js)
 		}
@@ -802,7 +978,7 @@ func LockTopicSubmit(w http.ResponseWriter, r *http.Request, user *c.User) c.Rou
 		if ferr != nil {
 			return ferr
 		}
-		if !user.Perms.ViewTopic || !user.Perms.CloseTopic {
+		if !user.CanCloseTopic(topic.CreatedBy) {
 			return c.NoPermissionsJSQ(w, r, user, js)
 		}
 
@@ -834,7 +1010,7 @@ func UnlockTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid s
 	if rerr != nil {
 		return rerr
 	}
-	if !u.Perms.ViewTopic || !u.Perms.CloseTopic {
+	if !u.CanCloseTopic(t.CreatedBy) {
 		return c.NoPermissions(w, r, u)
 	}
 	return topicActionPost(t.Unlock(), "unlock", w, r, lite, t, u)
@@ -934,9 +1110,13 @@ func LikeTopicSubmit(w http.ResponseWriter, r *http.Request, user *c.User, stid
 	if ferr != nil {
 		return ferr
 	}
-	if !user.Perms.ViewTopic || !user.Perms.LikeItem {
+	if !user.CanViewTopic(topic.CreatedBy) || !user.Perms.LikeItem {
 		return c.NoPermissionsJSQ(w, r, user, js)
 	}
+	// Topics in the trash are only visible to those who could restore them
+	if topic.Deleted && !user.Perms.DeleteTopic {
+		return c.NotFoundJSQ(w, r, nil, js)
+	}
 	if topic.CreatedBy == user.ID {
 		return c.LocalErrorJSQ("You can't like your own topics", w, r, user, js)
 	}
@@ -987,9 +1167,13 @@ func UnlikeTopicSubmit(w http.ResponseWriter, r *http.Request, user *c.User, sti
 	if ferr != nil {
 		return ferr
 	}
-	if !user.Perms.ViewTopic || !user.Perms.LikeItem {
+	if !user.CanViewTopic(topic.CreatedBy) || !user.Perms.LikeItem {
 		return c.NoPermissionsJSQ(w, r, user, js)
 	}
+	// Topics in the trash are only visible to those who could restore them
+	if topic.Deleted && !user.Perms.DeleteTopic {
+		return c.NotFoundJSQ(w, r, nil, js)
+	}
 
 	_, err = c.Users.Get(topic.CreatedBy)
 	if err != nil && err == sql.ErrNoRows {
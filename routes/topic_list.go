@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -62,12 +63,35 @@ func TopicListWeekViews(w http.ResponseWriter, r *http.Request, u *c.User, h *c.
 	return TopicListCommon(w, r, u, h, "weekviews", c.TopicListWeekViews)
 }
 
+// TopicListByTag shows the topics with a certain tag on them
+func TopicListByTag(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, stag string) c.RouteError {
+	stag, err := url.PathUnescape(strings.TrimSuffix(stag, "/"))
+	if err != nil {
+		return c.NotFound(w, r, h)
+	}
+	tag := c.NormaliseTag(stag)
+	if tag == "" {
+		return c.NotFound(w, r, h)
+	}
+	return topicListCommon(w, r, u, h, "lastupdated", 0, tag)
+}
+
 // TODO: Implement search
 func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Header, torder string, tsorder int) c.RouteError {
+	return topicListCommon(w, r, user, h, torder, tsorder, "")
+}
+
+func topicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.Header, torder string, tsorder int, tag string) c.RouteError {
 	h.Title = phrases.GetTitlePhrase("topics")
 	h.Zone = "topics"
 	h.Path = "/topics/"
 	h.MetaDesc = h.Settings["meta_desc"].(string)
+	var ttag c.TopicTag
+	if tag != "" {
+		ttag = c.TopicTag{tag, c.BuildTagURL(tag)}
+		h.Title = phrases.GetTitlePhrase("topics_tag") + tag
+		h.Path = ttag.Link
+	}
 
 	group, err := c.Groups.Get(user.Group)
 	if err != nil {
@@ -88,7 +112,7 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 			}
 			fids = append(fids, fid)
 		}
-		if len(fids) == 1 {
+		if len(fids) == 1 && tag == "" {
 			f, err := c.Forums.Get(fids[0])
 			if err != nil {
 				return c.LocalError("Invalid fid forum", w, r, user)
@@ -104,8 +128,17 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 	var topicList []*c.TopicsRow
 	var pagi c.Paginator
 	var canDelete, ccanDelete, canLock, ccanLock, canMove, ccanMove bool
+	filter := c.TopicListFilter{Tag: tag}
+	filter.Prefix, _ = strconv.Atoi(r.FormValue("prefix"))
+	switch r.FormValue("solved") {
+	case "1":
+		filter.Solved = c.TopicListSolvedOnly
+	case "0":
+		filter.Solved = c.TopicListUnsolvedOnly
+	}
+	filtered := filter != (c.TopicListFilter{})
 	q := r.FormValue("q")
-	if q != "" && c.RepliesSearch != nil {
+	if q != "" && c.RepliesSearch != nil && !filtered {
 		var canSee []int
 		if user.IsSuperAdmin {
 			canSee, err = c.Forums.GetAllVisibleIDs()
@@ -153,6 +186,10 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 		// TODO: Cache emptied map across requests with sync pool
 		reqUserList := make(map[int]bool)
 		for _, t := range tMap {
+			// A reply might still match in a topic which has been moved to the trash
+			if t.Deleted {
+				continue
+			}
 			reqUserList[t.CreatedBy] = true
 			reqUserList[t.LastReplyBy] = true
 			topicList = append(topicList, t.TopicsRow())
@@ -205,6 +242,11 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 					ccanLock = user.Perms.CloseTopic
 					ccanMove = user.Perms.MoveTopic
 				}
+				if mp, ok := c.ForumMods.PermsFor(f.ID, user); ok {
+					ccanDelete = ccanDelete || mp.DeletePosts
+					ccanLock = ccanLock || mp.CloseTopic
+					ccanMove = ccanMove || mp.MoveTopic
+				}
 				if ccanDelete {
 					canDelete = true
 				}
@@ -249,7 +291,7 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 
 		h.Title = phrases.GetTitlePhrase("topics_search")
 		//log.Printf("cfids: %+v\n", cfids)
-		pi := c.TopicListPage{h, topicList2, forumList, c.Config.DefaultForum, c.TopicListSort{torder, false}, cfids, c.QuickTools{canDelete, canLock, canMove}, pagi}
+		pi := c.TopicListPage{h, topicList2, forumList, c.Config.DefaultForum, c.TopicListSort{torder, false}, cfids, c.QuickTools{canDelete, canLock, canMove}, pagi, ttag}
 		return renderTemplate("topics", w, r, h, pi)
 	}
 
@@ -258,11 +300,24 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 	var fps map[int]c.QuickTools
 	if user.IsSuperAdmin {
 		//log.Print("user.IsSuperAdmin")
-		topicList, forumList, pagi, err = c.TopicList.GetList(page, tsorder, fids)
+		if filtered {
+			var canSee []int
+			canSee, err = c.Forums.GetAllVisibleIDs()
+			if err != nil {
+				return c.InternalError(err, w, r)
+			}
+			topicList, forumList, pagi, err = c.TopicList.GetListByCanSee(canSee, page, tsorder, fids, filter)
+		} else {
+			topicList, forumList, pagi, err = c.TopicList.GetList(page, tsorder, fids)
+		}
 		canLock, canMove = true, true
 	} else {
 		//log.Print("!user.IsSuperAdmin")
-		topicList, forumList, pagi, err = c.TopicList.GetListByGroup(group, page, tsorder, fids)
+		if filtered {
+			topicList, forumList, pagi, err = c.TopicList.GetListByCanSee(group.CanSee, page, tsorder, fids, filter)
+		} else {
+			topicList, forumList, pagi, err = c.TopicList.GetListByGroup(group, page, tsorder, fids)
+		}
 		fps = make(map[int]c.QuickTools)
 		for _, f := range forumList {
 			fp, err := c.FPStore.Get(f.ID, user.Group)
@@ -280,6 +335,11 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 				ccanLock = user.Perms.CloseTopic
 				ccanMove = user.Perms.MoveTopic
 			}
+			if mp, ok := c.ForumMods.PermsFor(f.ID, user); ok {
+				ccanDelete = ccanDelete || mp.DeletePosts
+				ccanLock = ccanLock || mp.CloseTopic
+				ccanMove = ccanMove || mp.MoveTopic
+			}
 			if ccanDelete {
 				canDelete = true
 			}
@@ -320,7 +380,7 @@ func TopicListCommon(w http.ResponseWriter, r *http.Request, user *c.User, h *c.
 		topicList2[i] = c.TopicsRowMut{t, canMod}
 	}
 
-	pi := c.TopicListPage{h, topicList2, forumList, c.Config.DefaultForum, c.TopicListSort{torder, false}, fids, c.QuickTools{canDelete, canLock, canMove}, pagi}
+	pi := c.TopicListPage{h, topicList2, forumList, c.Config.DefaultForum, c.TopicListSort{torder, false}, fids, c.QuickTools{canDelete, canLock, canMove}, pagi, ttag}
 	if r.FormValue("i") == "1" {
 		return renderTemplate("topics_mini", w, r, h, pi)
 	}
@@ -0,0 +1,192 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+
+	c "github.com/Azareal/Gosora/common"
+	"github.com/Azareal/Gosora/common/phrases"
+)
+
+// parseTopicRef pulls a topic ID out of either a plain number or a link to the topic, as moderators are likely to paste whatever is in their address bar
+func parseTopicRef(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if i := strings.IndexAny(raw, "?#"); i != -1 {
+		raw = raw[:i]
+	}
+	raw = strings.TrimRight(raw, "/")
+	if i := strings.LastIndexAny(raw, "./"); i != -1 {
+		raw = raw[i+1:]
+	}
+	return strconv.Atoi(raw)
+}
+
+// topicMoveCheck loads the topic tid and makes sure that u is allowed to move things in and out of it
+func topicMoveCheck(w http.ResponseWriter, r *http.Request, u *c.User, tid int) (*c.Topic, *c.HeaderLite, c.RouteError) {
+	topic, err := c.Topics.Get(tid)
+	if err == sql.ErrNoRows {
+		return nil, nil, c.PreError("The topic you tried to move posts in or out of doesn't exist.", w, r)
+	} else if err != nil {
+		return nil, nil, c.InternalError(err, w, r)
+	}
+	// TODO: Add hooks to make use of headerLite
+	lite, ferr := c.SimpleForumUserCheck(w, r, u, topic.ParentID)
+	if ferr != nil {
+		return nil, nil, ferr
+	}
+	if !u.Perms.ViewTopic || !u.Perms.MoveTopic {
+		return nil, nil, c.NoPermissions(w, r, u)
+	}
+	return topic, lite, nil
+}
+
+func ridsFromForm(r *http.Request) (rids []int, err error) {
+	for _, srid := range r.PostForm["rids"] {
+		rid, err := strconv.Atoi(srid)
+		if err != nil {
+			return nil, err
+		}
+		rids = append(rids, rid)
+	}
+	return rids, nil
+}
+
+func topicMergeError(err error, w http.ResponseWriter, r *http.Request, u *c.User) c.RouteError {
+	switch err {
+	case c.ErrNoRepliesPicked, c.ErrReplyNotInTopic, c.ErrMoveActionReply, c.ErrMoveDeletedReply, c.ErrSameTopic:
+		return c.LocalError(err.Error(), w, r, u)
+	case c.ErrNoTitle:
+		return c.LocalError("This topic doesn't have a title", w, r, u)
+	case c.ErrLongTitle:
+		return c.LocalError("The length of the title is too long, max: "+strconv.Itoa(c.Config.MaxTopicTitleLength), w, r, u)
+	case c.ErrNoBody:
+		return c.LocalError("The first of those replies doesn't have anything in it", w, r, u)
+	}
+	return c.InternalError(err, w, r)
+}
+
+// MergeTopicSubmit folds the topic stid into the one given in the dest field, the opening post of the old topic becomes a reply
+func MergeTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid string) c.RouteError {
+	tid, err := strconv.Atoi(stid)
+	if err != nil {
+		return c.PreError(phrases.GetErrorPhrase("id_must_be_integer"), w, r)
+	}
+	destID, err := parseTopicRef(r.PostFormValue("dest"))
+	if err != nil {
+		return c.LocalError("That isn't a valid topic.", w, r, u)
+	}
+	topic, _, ferr := topicMoveCheck(w, r, u, tid)
+	if ferr != nil {
+		return ferr
+	}
+	dest, lite, ferr := topicMoveCheck(w, r, u, destID)
+	if ferr != nil {
+		return ferr
+	}
+
+	err = topic.MergeInto(dest)
+	if err != nil {
+		return topicMergeError(err, w, r, u)
+	}
+	err = addTopicAction("merge-"+stid, dest, u)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_merge_topic", dest.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, dest.Link, http.StatusSeeOther)
+	return nil
+}
+
+// SplitTopicSubmit moves the picked replies into a new topic, in either the same forum or the one given in the fid field
+func SplitTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid string) c.RouteError {
+	tid, err := strconv.Atoi(stid)
+	if err != nil {
+		return c.PreError(phrases.GetErrorPhrase("id_must_be_integer"), w, r)
+	}
+	rids, err := ridsFromForm(r)
+	if err != nil {
+		return c.LocalError("One of those replies isn't valid.", w, r, u)
+	}
+	topic, lite, ferr := topicMoveCheck(w, r, u, tid)
+	if ferr != nil {
+		return ferr
+	}
+	fid := topic.ParentID
+	if sfid := r.PostFormValue("fid"); sfid != "" {
+		fid, err = strconv.Atoi(sfid)
+		if err != nil {
+			return c.LocalError("The provided Forum ID is not a valid number.", w, r, u)
+		}
+	}
+	if fid != topic.ParentID {
+		lite, ferr = c.SimpleForumUserCheck(w, r, u, fid)
+		if ferr != nil {
+			return ferr
+		}
+		if !u.Perms.ViewTopic || !u.Perms.MoveTopic {
+			return c.NoPermissions(w, r, u)
+		}
+	}
+
+	newTid, err := topic.Split(rids, c.SanitiseSingleLine(r.PostFormValue("title")), fid)
+	if err != nil {
+		return topicMergeError(err, w, r, u)
+	}
+	err = addTopicAction("split-"+strconv.Itoa(newTid), topic, u)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_split_topic", newTid, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, "/topic/"+strconv.Itoa(newTid), http.StatusSeeOther)
+	return nil
+}
+
+// MoveRepliesSubmit moves the picked replies onto the end of the topic given in the dest field
+func MoveRepliesSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid string) c.RouteError {
+	tid, err := strconv.Atoi(stid)
+	if err != nil {
+		return c.PreError(phrases.GetErrorPhrase("id_must_be_integer"), w, r)
+	}
+	rids, err := ridsFromForm(r)
+	if err != nil {
+		return c.LocalError("One of those replies isn't valid.", w, r, u)
+	}
+	destID, err := parseTopicRef(r.PostFormValue("dest"))
+	if err != nil {
+		return c.LocalError("That isn't a valid topic.", w, r, u)
+	}
+	topic, _, ferr := topicMoveCheck(w, r, u, tid)
+	if ferr != nil {
+		return ferr
+	}
+	dest, lite, ferr := topicMoveCheck(w, r, u, destID)
+	if ferr != nil {
+		return ferr
+	}
+
+	err = topic.MoveReplies(rids, dest)
+	if err != nil {
+		return topicMergeError(err, w, r, u)
+	}
+	err = addTopicAction("movereplies-"+strconv.Itoa(dest.ID), topic, u)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_move_replies", dest.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, dest.Link, http.StatusSeeOther)
+	return nil
+}
@@ -0,0 +1,248 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/Azareal/Gosora/common"
+	p "github.com/Azareal/Gosora/common/phrases"
+)
+
+// topicPublishAtFromForm reads the time a new topic should go live at, the zero time means right away
+func topicPublishAtFromForm(w http.ResponseWriter, r *http.Request, u *c.User) (time.Time, c.RouteError) {
+	spub := r.PostFormValue("publish_at")
+	if spub == "" {
+		return time.Time{}, nil
+	}
+	at, err := time.Parse("2006-01-02T15:04", spub)
+	if err != nil {
+		return time.Time{}, c.LocalError("The publish time isn't a valid date and time", w, r, u)
+	}
+	// The browser tells us how far it's clock is from UTC in minutes, otherwise we have to assume it's in UTC
+	if offset, err := strconv.Atoi(r.PostFormValue("publish_offset")); err == nil {
+		at = at.Add(time.Duration(offset) * time.Minute)
+	}
+	if !at.After(time.Now()) {
+		return time.Time{}, nil
+	}
+	return at, nil
+}
+
+// scheduleTopic sets aside the topic in the form to be published at publishAt by the scheduled topic task
+func scheduleTopic(w http.ResponseWriter, r *http.Request, u *c.User, fid, prefix int, publishAt time.Time) c.RouteError {
+	if !u.CanScheduleTopic() {
+		return c.NoPermissions(w, r, u)
+	}
+	// TODO: Hold onto polls and attachments until the topic is published
+	if r.PostFormValue("has_poll") == "1" {
+		return c.LocalError("Polls can't be added to scheduled topics yet", w, r, u)
+	}
+	if r.MultipartForm != nil {
+		for _, file := range r.MultipartForm.File["upload_files"] {
+			if file.Filename != "" {
+				return c.LocalError("Files can't be attached to scheduled topics yet", w, r, u)
+			}
+		}
+	}
+
+	st := &c.ScheduledTopic{
+		ParentID:  fid,
+		Title:     c.SanitiseSingleLine(r.PostFormValue("name")),
+		Content:   c.PreparseMessage(r.PostFormValue("content")),
+		CreatedBy: u.ID,
+		IP:        u.GetIP(),
+		Prefix:    prefix,
+		Tags:      strings.Join(c.ParseTags(r.PostFormValue("tags")), ","),
+		PublishAt: publishAt,
+	}
+	_, err := c.ScheduledTopics.Create(st)
+	if err != nil {
+		return createTopicError(err, w, r, u)
+	}
+	err = c.Drafts.Delete(u.ID, "forum", fid)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/topics/scheduled/?created=1", http.StatusSeeOther)
+	return nil
+}
+
+func ScheduledTopics(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header) c.RouteError {
+	// Super mods look after everyone's, everyone else only gets to see their own
+	uid := u.ID
+	if u.IsSuperMod {
+		uid = 0
+	}
+	list, err := c.ScheduledTopics.GetList(uid)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	h.Title = p.GetTitlePhrase("scheduled_topics")
+	h.Zone = "scheduled_topics"
+	if r.FormValue("created") == "1" {
+		h.AddNotice("scheduled_topic_created")
+	} else if r.FormValue("deleted") == "1" {
+		h.AddNotice("scheduled_topic_deleted")
+	}
+
+	items := make([]c.ScheduledTopicItem, len(list))
+	for i, st := range list {
+		// The forum might have gone since, but the topic should still be shown, so it can be deleted
+		f, err := c.Forums.Get(st.ParentID)
+		if err != nil && err != sql.ErrNoRows {
+			return c.InternalError(err, w, r)
+		}
+		items[i] = c.ScheduledTopicItem{st, f}
+	}
+	return renderTemplate("scheduled_topics", w, r, h, c.ScheduledTopicsPage{h, items})
+}
+
+func scheduledTopicPre(w http.ResponseWriter, r *http.Request, u *c.User, sstid string) (*c.ScheduledTopic, c.RouteError) {
+	stid, err := strconv.Atoi(sstid)
+	if err != nil {
+		return nil, c.LocalError(p.GetErrorPhrase("id_must_be_integer"), w, r, u)
+	}
+	st, err := c.ScheduledTopics.Get(stid)
+	if err == sql.ErrNoRows {
+		return nil, c.LocalError("The scheduled topic you tried to change doesn't exist, perhaps it has already been published?", w, r, u)
+	} else if err != nil {
+		return nil, c.InternalError(err, w, r)
+	}
+	if st.CreatedBy != u.ID && !u.IsSuperMod {
+		return nil, c.NoPermissions(w, r, u)
+	}
+	return st, nil
+}
+
+func ScheduledTopicDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sstid string) c.RouteError {
+	st, rerr := scheduledTopicPre(w, r, u, sstid)
+	if rerr != nil {
+		return rerr
+	}
+	err := c.ScheduledTopics.Delete(st.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/topics/scheduled/?deleted=1", http.StatusSeeOther)
+	return nil
+}
+
+// ScheduledTopicPublishSubmit publishes a scheduled topic right away rather than waiting for it's time to come
+func ScheduledTopicPublishSubmit(w http.ResponseWriter, r *http.Request, u *c.User, sstid string) c.RouteError {
+	st, rerr := scheduledTopicPre(w, r, u, sstid)
+	if rerr != nil {
+		return rerr
+	}
+	tid, err := c.ScheduledTopics.Publish(st)
+	if err != nil {
+		return createTopicError(err, w, r, u)
+	}
+	// Something else got to it first
+	if tid == 0 {
+		http.Redirect(w, r, "/topics/scheduled/", http.StatusSeeOther)
+		return nil
+	}
+	http.Redirect(w, r, c.BuildTopicURL("", tid), http.StatusSeeOther)
+	return nil
+}
+
+// canTimeAction tells you whether u is allowed to set or remove a timed action, the forum permissions have to be applied to u first
+func canTimeAction(u *c.User, action string) bool {
+	switch action {
+	case "unpin":
+		return u.Perms.PinTopic
+	case "lock":
+		return u.Perms.CloseTopic
+	}
+	return false
+}
+
+func TopicTimedActions(w http.ResponseWriter, r *http.Request, u *c.User, h *c.Header, stid string) c.RouteError {
+	tid, err := strconv.Atoi(stid)
+	if err != nil {
+		return c.SimpleError(p.GetErrorPhrase("url_id_must_be_integer"), w, r, h)
+	}
+	t, err := c.Topics.Get(tid)
+	if err == sql.ErrNoRows {
+		return c.NotFound(w, r, h)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	ferr := c.ForumUserCheck(h, w, r, u, t.ParentID)
+	if ferr != nil {
+		return ferr
+	}
+	canUnpin, canLock := canTimeAction(u, "unpin"), canTimeAction(u, "lock")
+	if !u.Perms.ViewTopic || !(canUnpin || canLock) {
+		return c.NoPermissions(w, r, u)
+	}
+
+	actions, err := c.TopicTimedActions.GetByTopic(t.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	items := make([]c.TopicTimedActionItem, len(actions))
+	for i, a := range actions {
+		items[i] = c.TopicTimedActionItem{a, a.DueAt(t)}
+	}
+	h.Title = p.GetTitlePhrase("topic_timed_actions")
+	h.Zone = "topic_timed_actions"
+	h.ZoneID = t.ID
+	if r.FormValue("created") == "1" {
+		h.AddNotice("topic_timed_action_created")
+	}
+	return renderTemplate("topic_timed_actions", w, r, h, c.TopicTimedActionsPage{h, t, items, canUnpin, canLock})
+}
+
+func TopicTimedActionCreateSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid string) c.RouteError {
+	t, _, rerr := topicActionPre(stid, "time an action for", w, r, u)
+	if rerr != nil {
+		return rerr
+	}
+	action := r.PostFormValue("action")
+	if !c.ValidTimedAction(action) {
+		return c.LocalError(c.ErrBadTimedAction.Error(), w, r, u)
+	}
+	if !u.Perms.ViewTopic || !canTimeAction(u, action) {
+		return c.NoPermissions(w, r, u)
+	}
+	days, err := strconv.Atoi(r.PostFormValue("days"))
+	if err != nil || days < 1 {
+		return c.LocalError("The number of days has to be a whole number greater than zero", w, r, u)
+	}
+	err = c.TopicTimedActions.Set(t.ID, action, days, r.PostFormValue("inactive") == "1", u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/topic/timed/"+strconv.Itoa(t.ID)+"?created=1", http.StatusSeeOther)
+	return nil
+}
+
+func TopicTimedActionDeleteSubmit(w http.ResponseWriter, r *http.Request, u *c.User, staid string) c.RouteError {
+	taid, err := strconv.Atoi(staid)
+	if err != nil {
+		return c.LocalError(p.GetErrorPhrase("id_must_be_integer"), w, r, u)
+	}
+	a, err := c.TopicTimedActions.Get(taid)
+	if err == sql.ErrNoRows {
+		return c.LocalError("The timed action you tried to remove doesn't exist, perhaps it has already been done?", w, r, u)
+	} else if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	t, _, rerr := topicActionPre(strconv.Itoa(a.TopicID), "remove a timed action from", w, r, u)
+	if rerr != nil {
+		return rerr
+	}
+	if !u.Perms.ViewTopic || !canTimeAction(u, a.Action) {
+		return c.NoPermissions(w, r, u)
+	}
+	err = c.TopicTimedActions.Delete(a.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	http.Redirect(w, r, "/topic/timed/"+strconv.Itoa(t.ID), http.StatusSeeOther)
+	return nil
+}
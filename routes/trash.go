@@ -0,0 +1,93 @@
+package routes
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	c "github.com/Azareal/Gosora/common"
+)
+
+// RestoreTopicSubmit pulls a topic back out of the trash
+func RestoreTopicSubmit(w http.ResponseWriter, r *http.Request, u *c.User, stid string) c.RouteError {
+	topic, lite, ferr := topicActionPre(stid, "restore", w, r, u)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ViewTopic || !u.Perms.DeleteTopic {
+		return c.NoPermissions(w, r, u)
+	}
+	if !topic.Deleted {
+		return c.LocalError("This topic isn't in the trash.", w, r, u)
+	}
+
+	err := topic.Restore()
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+	err = c.ModLogs.Create("restore", topic.ID, "topic", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalError(err, w, r)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_restore_topic", topic.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	http.Redirect(w, r, topic.Link, http.StatusSeeOther)
+	return nil
+}
+
+// ReplyRestoreSubmit pulls a reply back out of the trash
+func ReplyRestoreSubmit(w http.ResponseWriter, r *http.Request, u *c.User, srid string) c.RouteError {
+	js := r.PostFormValue("js") == "1"
+	rid, err := strconv.Atoi(srid)
+	if err != nil {
+		return c.PreErrorJSQ("The provided Reply ID is not a valid number.", w, r, js)
+	}
+
+	reply, err := c.Rstore.Get(rid)
+	if err == sql.ErrNoRows {
+		return c.PreErrorJSQ("The reply you tried to restore doesn't exist.", w, r, js)
+	} else if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	topic, err := c.Topics.Get(reply.ParentID)
+	if err == sql.ErrNoRows {
+		return c.PreErrorJSQ("The parent topic doesn't exist.", w, r, js)
+	} else if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+
+	// TODO: Add hooks to make use of headerLite
+	lite, ferr := c.SimpleForumUserCheck(w, r, u, topic.ParentID)
+	if ferr != nil {
+		return ferr
+	}
+	if !u.Perms.ViewTopic || !u.Perms.DeleteReply {
+		return c.NoPermissionsJSQ(w, r, u, js)
+	}
+	if !reply.Deleted {
+		return c.LocalErrorJSQ("This reply isn't in the trash.", w, r, u, js)
+	}
+
+	err = reply.Restore()
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+	err = c.ModLogs.Create("restore", reply.ID, "reply", u.GetIP(), u.ID)
+	if err != nil {
+		return c.InternalErrorJSQ(err, w, r, js)
+	}
+
+	skip, rerr := lite.Hooks.VhookSkippable("action_end_restore_reply", reply.ID, u)
+	if skip || rerr != nil {
+		return rerr
+	}
+	if !js {
+		http.Redirect(w, r, topic.Link+"?deleted=1#post-"+srid, http.StatusSeeOther)
+	} else {
+		w.Write(successJSONBytes)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+CREATE TABLE [cluster_events] (
+	[ceid] int not null IDENTITY,
+	[origin] nvarchar (50) not null,
+	[type] nvarchar (50) not null,
+	[eid] int DEFAULT 0 not null,
+	[data] nvarchar (MAX) not null,
+	[createdAt] datetime not null,
+	primary key([ceid])
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE [drafts] (
+	[draftID] int not null IDENTITY,
+	[uid] int not null,
+	[contextType] nvarchar (50) not null,
+	[contextID] int not null,
+	[title] nvarchar (100) DEFAULT '' not null,
+	[content] nvarchar (MAX) not null,
+	[updatedAt] datetime not null,
+	primary key([draftID]),
+	unique([uid],[contextType],[contextID])
+);
\ No newline at end of file
@@ -9,6 +9,8 @@ CREATE TABLE [forums] (
 	[preset] nvarchar (100) DEFAULT '' not null,
 	[parentID] int DEFAULT 0 not null,
 	[parentType] nvarchar (50) DEFAULT '' not null,
+	[questions] bit DEFAULT 0 not null,
+	[category] bit DEFAULT 0 not null,
 	[lastTopicID] int DEFAULT 0 not null,
 	[lastReplyerID] int DEFAULT 0 not null,
 	primary key([fid])
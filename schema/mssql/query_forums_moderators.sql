@@ -0,0 +1,10 @@
+CREATE TABLE [forums_moderators] (
+	[fid] int not null,
+	[uid] int not null,
+	[pinTopic] bit DEFAULT 0 not null,
+	[closeTopic] bit DEFAULT 0 not null,
+	[moveTopic] bit DEFAULT 0 not null,
+	[deletePosts] bit DEFAULT 0 not null,
+	[editPosts] bit DEFAULT 0 not null,
+	primary key([fid],[uid])
+);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE [profile_fields] (
+	[pfid] int not null IDENTITY,
+	[name] nvarchar (100) not null,
+	[type] nvarchar (50) not null,
+	[options] nvarchar (MAX) not null,
+	[required] bit DEFAULT 0 not null,
+	[maxLength] int DEFAULT 0 not null,
+	[allowedGroups] nvarchar (MAX) not null,
+	[privacy] int DEFAULT 1 not null,
+	[showOnPosts] bit DEFAULT 0 not null,
+	[order] int DEFAULT 0 not null,
+	primary key([pfid])
+);
\ No newline at end of file
@@ -14,6 +14,10 @@ CREATE TABLE [replies] (
 	[words] int DEFAULT 1 not null,
 	[actionType] nvarchar (20) DEFAULT '' not null,
 	[poll] int DEFAULT 0 not null,
+	[votes] int DEFAULT 0 not null,
+	[deleted] bit DEFAULT 0 not null,
+	[deletedAt] datetime,
+	[deletedBy] int DEFAULT 0 not null,
 	primary key([rid]),
 	fulltext key([content])
 );
\ No newline at end of file
@@ -1,8 +1,11 @@
 CREATE TABLE [revisions] (
 	[reviseID] int not null IDENTITY,
+	[title] nvarchar (100) DEFAULT '' not null,
 	[content] nvarchar (MAX) not null,
 	[contentID] int not null,
 	[contentType] nvarchar (100) DEFAULT 'replies' not null,
 	[createdAt] datetime not null,
+	[createdBy] int DEFAULT 0 not null,
+	[ip] nvarchar (200) DEFAULT '' not null,
 	primary key([reviseID])
 );
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE [topic_prefixes] (
+	[prid] int not null IDENTITY,
+	[name] nvarchar (100) not null,
+	[cssClass] nvarchar (100) DEFAULT '' not null,
+	[forums] nvarchar (MAX) not null,
+	[allowedGroups] nvarchar (MAX) not null,
+	[order] int DEFAULT 0 not null,
+	primary key([prid])
+);
\ No newline at end of file
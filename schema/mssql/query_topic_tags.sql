@@ -0,0 +1,5 @@
+CREATE TABLE [topic_tags] (
+	[tid] int not null,
+	[tag] nvarchar (100) not null,
+	unique([tid],[tag])
+);
\ No newline at end of file
@@ -22,6 +22,13 @@ CREATE TABLE [topics] (
 	[css_class] nvarchar (100) DEFAULT '' not null,
 	[poll] int DEFAULT 0 not null,
 	[data] nvarchar (200) DEFAULT '' not null,
+	[prefix] int DEFAULT 0 not null,
+	[answer] int DEFAULT 0 not null,
+	[deleted] bit DEFAULT 0 not null,
+	[deletedAt] datetime,
+	[deletedBy] int DEFAULT 0 not null,
+	[lastEdit] int DEFAULT 0 not null,
+	[lastEditBy] int DEFAULT 0 not null,
 	primary key([tid]),
 	fulltext key([title]),
 	fulltext key([content])
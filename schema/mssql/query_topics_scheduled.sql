@@ -0,0 +1,13 @@
+CREATE TABLE [topics_scheduled] (
+	[stid] int not null IDENTITY,
+	[parentID] int not null,
+	[title] nvarchar (100) not null,
+	[content] nvarchar (MAX) not null,
+	[createdBy] int not null,
+	[ip] nvarchar (200) DEFAULT '' not null,
+	[prefix] int DEFAULT 0 not null,
+	[tags] nvarchar (MAX) not null,
+	[publishAt] datetime not null,
+	[createdAt] datetime not null,
+	primary key([stid])
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE [topics_timed_actions] (
+	[taid] int not null IDENTITY,
+	[tid] int not null,
+	[action] nvarchar (50) not null,
+	[days] int not null,
+	[inactive] bit DEFAULT 0 not null,
+	[createdBy] int not null,
+	[createdAt] datetime not null,
+	primary key([taid]),
+	unique([tid],[action])
+);
\ No newline at end of file
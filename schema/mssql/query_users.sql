@@ -10,10 +10,13 @@ CREATE TABLE [users] (
 	[lastActiveAt] datetime not null,
 	[session] nvarchar (200) DEFAULT '' not null,
 	[last_ip] nvarchar (200) DEFAULT '' not null,
+	[profile_comments] int DEFAULT 0 not null,
+	[who_can_convo] int DEFAULT 0 not null,
 	[enable_embeds] int DEFAULT -1 not null,
+	[lang] nvarchar (50) DEFAULT '' not null,
 	[email] nvarchar (200) DEFAULT '' not null,
 	[avatar] nvarchar (100) DEFAULT '' not null,
-	[message] nvarchar (MAX) DEFAULT '' not null,
+	[message] nvarchar (MAX) not null,
 	[url_prefix] nvarchar (20) DEFAULT '' not null,
 	[url_name] nvarchar (100) DEFAULT '' not null,
 	[level] smallint DEFAULT 0 not null,
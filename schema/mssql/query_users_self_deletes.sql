@@ -0,0 +1,6 @@
+CREATE TABLE [users_self_deletes] (
+	[uid] int not null,
+	[mode] nvarchar (20) not null,
+	[requestedAt] datetime not null,
+	primary key([uid])
+);
\ No newline at end of file
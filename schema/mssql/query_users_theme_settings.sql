@@ -0,0 +1,7 @@
+CREATE TABLE [users_theme_settings] (
+	[uid] int not null,
+	[theme] nvarchar (50) not null,
+	[name] nvarchar (50) not null,
+	[value] nvarchar (50) not null,
+	unique([uid],[theme],[name])
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE `cluster_events` (
+	`ceid` int not null AUTO_INCREMENT,
+	`origin` varchar(50) not null,
+	`type` varchar(50) not null,
+	`eid` int DEFAULT 0 not null,
+	`data` text not null,
+	`createdAt` datetime not null,
+	primary key(`ceid`)
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE `drafts` (
+	`draftID` int not null AUTO_INCREMENT,
+	`uid` int not null,
+	`contextType` varchar(50) not null,
+	`contextID` int not null,
+	`title` varchar(100) DEFAULT '' not null,
+	`content` text not null,
+	`updatedAt` datetime not null,
+	primary key(`draftID`),
+	unique(`uid`,`contextType`,`contextID`)
+) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
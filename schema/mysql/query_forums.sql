@@ -9,6 +9,8 @@ CREATE TABLE `forums` (
 	`preset` varchar(100) DEFAULT '' not null,
 	`parentID` int DEFAULT 0 not null,
 	`parentType` varchar(50) DEFAULT '' not null,
+	`questions` boolean DEFAULT 0 not null,
+	`category` boolean DEFAULT 0 not null,
 	`lastTopicID` int DEFAULT 0 not null,
 	`lastReplyerID` int DEFAULT 0 not null,
 	primary key(`fid`)
@@ -0,0 +1,13 @@
+CREATE TABLE `profile_fields` (
+	`pfid` int not null AUTO_INCREMENT,
+	`name` varchar(100) not null,
+	`type` varchar(50) not null,
+	`options` text not null,
+	`required` boolean DEFAULT 0 not null,
+	`maxLength` int DEFAULT 0 not null,
+	`allowedGroups` text not null,
+	`privacy` int DEFAULT 1 not null,
+	`showOnPosts` boolean DEFAULT 0 not null,
+	`order` int DEFAULT 0 not null,
+	primary key(`pfid`)
+) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
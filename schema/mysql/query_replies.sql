@@ -14,6 +14,10 @@ CREATE TABLE `replies` (
 	`words` int DEFAULT 1 not null,
 	`actionType` varchar(20) DEFAULT '' not null,
 	`poll` int DEFAULT 0 not null,
+	`votes` int DEFAULT 0 not null,
+	`deleted` boolean DEFAULT 0 not null,
+	`deletedAt` datetime null,
+	`deletedBy` int DEFAULT 0 not null,
 	primary key(`rid`),
 	fulltext key(`content`)
 ) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
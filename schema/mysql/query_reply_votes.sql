@@ -0,0 +1,8 @@
+CREATE TABLE `reply_votes` (
+	`rid` int not null,
+	`tid` int not null,
+	`uid` int not null,
+	`weight` tinyint DEFAULT 1 not null,
+	`createdAt` datetime not null,
+	unique(`rid`,`uid`)
+);
\ No newline at end of file
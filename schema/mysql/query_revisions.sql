@@ -1,8 +1,11 @@
 CREATE TABLE `revisions` (
 	`reviseID` int not null AUTO_INCREMENT,
+	`title` varchar(100) DEFAULT '' not null,
 	`content` text not null,
 	`contentID` int not null,
 	`contentType` varchar(100) DEFAULT 'replies' not null,
 	`createdAt` datetime not null,
+	`createdBy` int DEFAULT 0 not null,
+	`ip` varchar(200) DEFAULT '' not null,
 	primary key(`reviseID`)
 ) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE `topic_prefixes` (
+	`prid` int not null AUTO_INCREMENT,
+	`name` varchar(100) not null,
+	`cssClass` varchar(100) DEFAULT '' not null,
+	`forums` text not null,
+	`allowedGroups` text not null,
+	`order` int DEFAULT 0 not null,
+	primary key(`prid`)
+) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
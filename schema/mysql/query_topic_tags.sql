@@ -0,0 +1,5 @@
+CREATE TABLE `topic_tags` (
+	`tid` int not null,
+	`tag` varchar(100) not null,
+	unique(`tid`,`tag`)
+) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE `topics_scheduled` (
+	`stid` int not null AUTO_INCREMENT,
+	`parentID` int not null,
+	`title` varchar(100) not null,
+	`content` text not null,
+	`createdBy` int not null,
+	`ip` varchar(200) DEFAULT '' not null,
+	`prefix` int DEFAULT 0 not null,
+	`tags` text not null,
+	`publishAt` datetime not null,
+	`createdAt` datetime not null,
+	primary key(`stid`)
+) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE `topics_timed_actions` (
+	`taid` int not null AUTO_INCREMENT,
+	`tid` int not null,
+	`action` varchar(50) not null,
+	`days` int not null,
+	`inactive` boolean DEFAULT 0 not null,
+	`createdBy` int not null,
+	`createdAt` datetime not null,
+	primary key(`taid`),
+	unique(`tid`,`action`)
+) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
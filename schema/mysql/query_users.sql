@@ -13,6 +13,7 @@ CREATE TABLE `users` (
 	`profile_comments` int DEFAULT 0 not null,
 	`who_can_convo` int DEFAULT 0 not null,
 	`enable_embeds` int DEFAULT -1 not null,
+	`lang` varchar(50) DEFAULT '' not null,
 	`email` varchar(200) DEFAULT '' not null,
 	`avatar` varchar(100) DEFAULT '' not null,
 	`message` text not null,
@@ -0,0 +1,7 @@
+CREATE TABLE `users_theme_settings` (
+	`uid` int not null,
+	`theme` varchar(50) not null,
+	`name` varchar(50) not null,
+	`value` varchar(50) not null,
+	unique(`uid`,`theme`,`name`)
+) CHARSET=utf8mb4 COLLATE utf8mb4_general_ci;
\ No newline at end of file
@@ -1,20 +1,41 @@
-INSERT INTO "sync"("last_update") VALUES (UTC_TIMESTAMP());
+CREATE INDEX "i_topics_parentid" ON "topics"("parentid");
+CREATE INDEX "i_replies_tid" ON "replies"("tid");
+CREATE INDEX "i_polls_parentid" ON "polls"("parentid");
+CREATE INDEX "i_likes_targetitem" ON "likes"("targetitem");
+CREATE INDEX "i_emails_uid" ON "emails"("uid");
+CREATE INDEX "i_attachments_originid" ON "attachments"("originid");
+CREATE INDEX "i_attachments_path" ON "attachments"("path");
+CREATE INDEX "i_activity_stream_matches_watcher" ON "activity_stream_matches"("watcher");
+INSERT INTO "sync"("last_update") VALUES ((NOW() AT TIME ZONE 'UTC'));
 INSERT INTO "settings"("name","content","type","constraints") VALUES ('activation_type','1','list','1-3');
 INSERT INTO "settings"("name","content","type") VALUES ('bigpost_min_words','250','int');
 INSERT INTO "settings"("name","content","type") VALUES ('megapost_min_words','1000','int');
 INSERT INTO "settings"("name","content","type") VALUES ('meta_desc','','html-attribute');
 INSERT INTO "settings"("name","content","type") VALUES ('rapid_loading','1','bool');
 INSERT INTO "settings"("name","content","type") VALUES ('google_site_verify','','html-attribute');
+INSERT INTO "settings"("name","content","type") VALUES ('own_post_edit_window','0','int');
+INSERT INTO "settings"("name","content","type") VALUES ('own_post_delete_window','0','int');
 INSERT INTO "themes"("uname","default") VALUES ('cosora',1);
 INSERT INTO "emails"("email","uid","validated") VALUES ('admin@localhost',1,1);
-INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Administrator','{"BanUsers":true,"ActivateUsers":true,"EditUser":true,"EditUserEmail":true,"EditUserPassword":true,"EditUserGroup":true,"EditUserGroupSuperMod":true,"EditGroup":true,"EditGroupLocalPerms":true,"EditGroupGlobalPerms":true,"EditGroupSuperMod":true,"ManageForums":true,"EditSettings":true,"ManageThemes":true,"ManagePlugins":true,"ViewAdminLogs":true,"ViewIPs":true,"UploadFiles":true,"UploadAvatars":true,"UseConvos":true,"UseConvosOnlyWithMod":true,"CreateProfileReply":true,"AutoEmbed":true,"AutoLink":true,"ViewTopic":true,"LikeItem":true,"CreateTopic":true,"EditTopic":true,"DeleteTopic":true,"CreateReply":true,"EditReply":true,"DeleteReply":true,"PinTopic":true,"CloseTopic":true,"MoveTopic":true}','{}',1,1,0,'Admin');
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (1,'convo_post_bodies','dc56c7f9b570de9869d7ec2868e42ce2f4494e1fb8af1e7bff58702e939a94fe',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (2,'self_deletes','826abaf2c54e3b64f50e82bd48f2d8fb4f4cd205adc69614c9eaae09d72649d8',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (3,'user_theme_settings','018cc4b86a572c8f8ed8cba2976dada24194edac4cdf0a59933a733bb332cdb3',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (4,'cluster_events','28b16274c0a1adc79f4735f84f65c9bb3dad7a81ee88bea53be626806f9d9aaa',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (5,'user_lang','f56ba3fecc3c4e6c48a8c3c24fd6cec2c857557ee48f8b908b15b44749b658a2',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (6,'forum_categories','82d78b30e75b49b2b2daa45c3150c7a74535abfe20088247041956fae6ccbeda',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (7,'forum_moderators','56e1501fd7b57ba9b58b85e785a2382d7f7c2ee820d04f5c617e80ac9802603c',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (8,'own_post_perms','7bbbb1762fed3898282c175217216774266c30c883431bfe1f722f371c246da9',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (9,'post_revisions','1cbddc91d0b681c385bb6a5018f2f3b9d44b8257ace8c9aa38499e2b4ef0c974',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (10,'drafts','286c8aaa20e91efd691a0405aedd68392d4e46f024826718833908b1980dfece',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "schema_migrations"("version","name","checksum","appliedat") VALUES (11,'scheduled_topics','2594e8db424ebdff86828c48c28267c2fe98a7c4a9de420bd15fe1d0bada8431',(NOW() AT TIME ZONE 'UTC'));
+INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Administrator','{"BanUsers":true,"ActivateUsers":true,"EditUser":true,"EditUserEmail":true,"EditUserPassword":true,"EditUserGroup":true,"EditUserGroupSuperMod":true,"EditGroup":true,"EditGroupLocalPerms":true,"EditGroupGlobalPerms":true,"EditGroupSuperMod":true,"ManageForums":true,"EditSettings":true,"ManageThemes":true,"ManagePlugins":true,"ViewAdminLogs":true,"ViewIPs":true,"UploadFiles":true,"UploadAvatars":true,"UseConvos":true,"UseConvosOnlyWithMod":true,"CreateProfileReply":true,"AutoEmbed":true,"AutoLink":true,"ViewTopic":true,"ViewOwnTopic":true,"LikeItem":true,"CreateTopic":true,"EditTopic":true,"EditOwnTopic":true,"DeleteTopic":true,"DeleteOwnTopic":true,"CreateReply":true,"CreateReplyToOwn":true,"EditReply":true,"EditOwnReply":true,"DeleteReply":true,"DeleteOwnReply":true,"PinTopic":true,"CloseTopic":true,"CloseOwnTopic":true,"MoveTopic":true}','{}',1,1,0,'Admin');
 INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Moderator','{"BanUsers":true,"ActivateUsers":true,"EditUser":true,"EditUserGroup":true,"ViewIPs":true,"UploadFiles":true,"UploadAvatars":true,"UseConvos":true,"UseConvosOnlyWithMod":true,"CreateProfileReply":true,"AutoEmbed":true,"AutoLink":true,"ViewTopic":true,"LikeItem":true,"CreateTopic":true,"EditTopic":true,"DeleteTopic":true,"CreateReply":true,"EditReply":true,"DeleteReply":true,"PinTopic":true,"CloseTopic":true,"MoveTopic":true}','{}',1,0,0,'Mod');
-INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Member','{"UploadFiles":true,"UploadAvatars":true,"UseConvos":true,"UseConvosOnlyWithMod":true,"CreateProfileReply":true,"AutoEmbed":true,"AutoLink":true,"ViewTopic":true,"LikeItem":true,"CreateTopic":true,"CreateReply":true}','{}',0,0,0,"");
-INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Banned','{"ViewTopic":true}','{}',0,0,1,"");
-INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Awaiting Activation','{"UseConvosOnlyWithMod":true,"ViewTopic":true}','{}',0,0,0,"");
+INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Member','{"UploadFiles":true,"UploadAvatars":true,"UseConvos":true,"UseConvosOnlyWithMod":true,"CreateProfileReply":true,"AutoEmbed":true,"AutoLink":true,"ViewTopic":true,"LikeItem":true,"CreateTopic":true,"EditOwnTopic":true,"DeleteOwnTopic":true,"CreateReply":true,"EditOwnReply":true,"DeleteOwnReply":true}','{}',0,0,0,'');
+INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Banned','{"ViewTopic":true}','{}',0,0,1,'');
+INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Awaiting Activation','{"UseConvosOnlyWithMod":true,"ViewTopic":true}','{}',0,0,0,'');
 INSERT INTO "users_groups"("name","permissions","plugin_perms","is_mod","is_admin","is_banned","tag") VALUES ('Not Loggedin','{"ViewTopic":true}','{}',0,0,0,'Guest');
 INSERT INTO "forums"("name","active","desc","tmpl") VALUES ('Reports',0,'All the reports go here','');
-INSERT INTO "forums"("name","lastTopicID","lastReplyerID","desc","tmpl") VALUES ('General',1,1,'A place for general discussions which don''t fit elsewhere','');
+INSERT INTO "forums"("name","lasttopicid","lastreplyerid","desc","tmpl") VALUES ('General',1,1,'A place for general discussions which don''t fit elsewhere','');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (1,1,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"PinTopic":true,"CloseTopic":true}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (2,1,'{"ViewTopic":true,"CreateReply":true,"CloseTopic":true}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (3,1,'{}');
@@ -23,19 +44,19 @@ INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (5,1,'{}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (6,1,'{}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (1,2,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"LikeItem":true,"EditTopic":true,"DeleteTopic":true,"EditReply":true,"DeleteReply":true,"PinTopic":true,"CloseTopic":true,"MoveTopic":true}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (2,2,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"LikeItem":true,"EditTopic":true,"DeleteTopic":true,"EditReply":true,"DeleteReply":true,"PinTopic":true,"CloseTopic":true,"MoveTopic":true}');
-INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (3,2,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"LikeItem":true}');
+INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (3,2,'{"ViewTopic":true,"CreateReply":true,"CreateTopic":true,"LikeItem":true,"EditOwnTopic":true,"DeleteOwnTopic":true,"EditOwnReply":true,"DeleteOwnReply":true}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (4,2,'{"ViewTopic":true}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (5,2,'{"ViewTopic":true}');
 INSERT INTO "forums_permissions"("gid","fid","permissions") VALUES (6,2,'{"ViewTopic":true}');
-INSERT INTO "topics"("title","content","parsed_content","createdAt","lastReplyAt","lastReplyBy","createdBy","parentID","ip") VALUES ('Test Topic','A topic automatically generated by the software.','A topic automatically generated by the software.',UTC_TIMESTAMP(),UTC_TIMESTAMP(),1,1,2,'::1');
-INSERT INTO "replies"("tid","content","parsed_content","createdAt","createdBy","lastUpdated","lastEdit","lastEditBy","ip") VALUES (1,'A reply!','A reply!',UTC_TIMESTAMP(),1,UTC_TIMESTAMP(),0,0,'::1');
-INSERT INTO "menus"() VALUES ();
-INSERT INTO "menu_items"("mid","name","htmlID","position","path","aria","tooltip","order") VALUES (1,'{lang.menu_forums}','menu_forums','left','/forums/','{lang.menu_forums_aria}','{lang.menu_forums_tooltip}',0);
-INSERT INTO "menu_items"("mid","name","htmlID","cssClass","position","path","aria","tooltip","order") VALUES (1,'{lang.menu_topics}','menu_topics','menu_topics','left','/topics/','{lang.menu_topics_aria}','{lang.menu_topics_tooltip}',1);
-INSERT INTO "menu_items"("mid","htmlID","cssClass","position","tmplName","order") VALUES (1,'general_alerts','menu_alerts','right','menu_alerts',2);
-INSERT INTO "menu_items"("mid","name","cssClass","position","path","aria","tooltip","memberOnly","order") VALUES (1,'{lang.menu_account}','menu_account','left','/user/edit/','{lang.menu_account_aria}','{lang.menu_account_tooltip}',1,3);
-INSERT INTO "menu_items"("mid","name","cssClass","position","path","aria","tooltip","memberOnly","order") VALUES (1,'{lang.menu_profile}','menu_profile','left','{me.Link}','{lang.menu_profile_aria}','{lang.menu_profile_tooltip}',1,4);
-INSERT INTO "menu_items"("mid","name","cssClass","position","path","aria","tooltip","memberOnly","staffOnly","order") VALUES (1,'{lang.menu_panel}','menu_panel menu_account','left','/panel/','{lang.menu_panel_aria}','{lang.menu_panel_tooltip}',1,1,5);
-INSERT INTO "menu_items"("mid","name","cssClass","position","path","aria","tooltip","memberOnly","order") VALUES (1,'{lang.menu_logout}','menu_logout','left','/accounts/logout/?s={me.Session}','{lang.menu_logout_aria}','{lang.menu_logout_tooltip}',1,6);
-INSERT INTO "menu_items"("mid","name","cssClass","position","path","aria","tooltip","guestOnly","order") VALUES (1,'{lang.menu_register}','menu_register','left','/accounts/create/','{lang.menu_register_aria}','{lang.menu_register_tooltip}',1,7);
-INSERT INTO "menu_items"("mid","name","cssClass","position","path","aria","tooltip","guestOnly","order") VALUES (1,'{lang.menu_login}','menu_login','left','/accounts/login/','{lang.menu_login_aria}','{lang.menu_login_tooltip}',1,8);
+INSERT INTO "topics"("title","content","parsed_content","createdat","lastreplyat","lastreplyby","createdby","parentid","ip") VALUES ('Test Topic','A topic automatically generated by the software.','A topic automatically generated by the software.',(NOW() AT TIME ZONE 'UTC'),(NOW() AT TIME ZONE 'UTC'),1,1,2,'::1');
+INSERT INTO "replies"("tid","content","parsed_content","createdat","createdby","lastupdated","lastedit","lasteditby","ip") VALUES (1,'A reply!','A reply!',(NOW() AT TIME ZONE 'UTC'),1,(NOW() AT TIME ZONE 'UTC'),0,0,'::1');
+INSERT INTO "menus" DEFAULT VALUES;
+INSERT INTO "menu_items"("mid","name","htmlid","position","path","aria","tooltip","order") VALUES (1,'{lang.menu_forums}','menu_forums','left','/forums/','{lang.menu_forums_aria}','{lang.menu_forums_tooltip}',0);
+INSERT INTO "menu_items"("mid","name","htmlid","cssclass","position","path","aria","tooltip","order") VALUES (1,'{lang.menu_topics}','menu_topics','menu_topics','left','/topics/','{lang.menu_topics_aria}','{lang.menu_topics_tooltip}',1);
+INSERT INTO "menu_items"("mid","htmlid","cssclass","position","tmplname","order") VALUES (1,'general_alerts','menu_alerts','right','menu_alerts',2);
+INSERT INTO "menu_items"("mid","name","cssclass","position","path","aria","tooltip","memberonly","order") VALUES (1,'{lang.menu_account}','menu_account','left','/user/edit/','{lang.menu_account_aria}','{lang.menu_account_tooltip}',1,3);
+INSERT INTO "menu_items"("mid","name","cssclass","position","path","aria","tooltip","memberonly","order") VALUES (1,'{lang.menu_profile}','menu_profile','left','{me.Link}','{lang.menu_profile_aria}','{lang.menu_profile_tooltip}',1,4);
+INSERT INTO "menu_items"("mid","name","cssclass","position","path","aria","tooltip","memberonly","staffonly","order") VALUES (1,'{lang.menu_panel}','menu_panel menu_account','left','/panel/','{lang.menu_panel_aria}','{lang.menu_panel_tooltip}',1,1,5);
+INSERT INTO "menu_items"("mid","name","cssclass","position","path","aria","tooltip","memberonly","order") VALUES (1,'{lang.menu_logout}','menu_logout','left','/accounts/logout/?s={me.Session}','{lang.menu_logout_aria}','{lang.menu_logout_tooltip}',1,6);
+INSERT INTO "menu_items"("mid","name","cssclass","position","path","aria","tooltip","guestonly","order") VALUES (1,'{lang.menu_register}','menu_register','left','/accounts/create/','{lang.menu_register_aria}','{lang.menu_register_tooltip}',1,7);
+INSERT INTO "menu_items"("mid","name","cssclass","position","path","aria","tooltip","guestonly","order") VALUES (1,'{lang.menu_login}','menu_login','left','/accounts/login/','{lang.menu_login_aria}','{lang.menu_login_tooltip}',1,8);
@@ -1,11 +1,12 @@
 CREATE TABLE "activity_stream" (
-	`asid` serial not null,
-	`actor` int not null,
-	`targetUser` int not null,
-	`event` varchar (50) not null,
-	`elementType` varchar (50) not null,
-	`elementID` int not null,
-	`createdAt` timestamp not null,
-	`extra` varchar (200) DEFAULT '' not null,
-	primary key(`asid`)
+	"asid" serial not null,
+	"actor" int not null,
+	"targetuser" int not null,
+	"event" varchar(50) not null,
+	"elementtype" varchar(50) not null,
+	"elementtable" int DEFAULT 0 not null,
+	"elementid" int not null,
+	"createdat" timestamp not null,
+	"extra" varchar(200) DEFAULT '' not null,
+	PRIMARY KEY("asid")
 );
\ No newline at end of file
@@ -1,6 +1,6 @@
 CREATE TABLE "activity_subscriptions" (
-	`user` int not null,
-	`targetID` int not null,
-	`targetType` varchar (50) not null,
-	`level` int DEFAULT 0 not null
+	"user" int not null,
+	"targetid" int not null,
+	"targettype" varchar(50) not null,
+	"level" int DEFAULT 0 not null
 );
\ No newline at end of file
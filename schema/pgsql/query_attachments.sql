@@ -1,11 +1,11 @@
 CREATE TABLE "attachments" (
-	`attachID` serial not null,
-	`sectionID` int DEFAULT 0 not null,
-	`sectionTable` varchar (200) DEFAULT 'forums' not null,
-	`originID` int not null,
-	`originTable` varchar (200) DEFAULT 'replies' not null,
-	`uploadedBy` int not null,
-	`path` varchar (200) not null,
-	`extra` varchar (200) not null,
-	primary key(`attachID`)
+	"attachid" serial not null,
+	"sectionid" int DEFAULT 0 not null,
+	"sectiontable" varchar(200) DEFAULT 'forums' not null,
+	"originid" int not null,
+	"origintable" varchar(200) DEFAULT 'replies' not null,
+	"uploadedby" int not null,
+	"path" varchar(200) not null,
+	"extra" varchar(200) not null,
+	PRIMARY KEY("attachid")
 );
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE "cluster_events" (
+	"ceid" serial not null,
+	"origin" varchar(50) not null,
+	"type" varchar(50) not null,
+	"eid" int DEFAULT 0 not null,
+	"data" text not null,
+	"createdat" timestamp not null,
+	PRIMARY KEY("ceid")
+);
\ No newline at end of file
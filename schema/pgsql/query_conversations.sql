@@ -1,8 +1,8 @@
 CREATE TABLE "conversations" (
-	`cid` serial not null,
-	`createdBy` int not null,
-	`createdAt` timestamp not null,
-	`lastReplyAt` timestamp not null,
-	`lastReplyBy` int not null,
-	primary key(`cid`)
+	"cid" serial not null,
+	"createdby" int not null,
+	"createdat" timestamp not null,
+	"lastreplyat" timestamp not null,
+	"lastreplyby" int not null,
+	PRIMARY KEY("cid")
 );
\ No newline at end of file
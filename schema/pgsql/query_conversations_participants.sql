@@ -1,4 +1,4 @@
 CREATE TABLE "conversations_participants" (
-	`uid` int not null,
-	`cid` int not null
+	"uid" int not null,
+	"cid" int not null
 );
\ No newline at end of file
@@ -1,8 +1,8 @@
 CREATE TABLE "conversations_posts" (
-	`pid` serial not null,
-	`cid` int not null,
-	`createdBy` int not null,
-	`body` varchar (50) not null,
-	`post` varchar (50) DEFAULT '' not null,
-	primary key(`pid`)
+	"pid" serial not null,
+	"cid" int not null,
+	"createdby" int not null,
+	"body" text not null,
+	"post" varchar(50) DEFAULT '' not null,
+	PRIMARY KEY("pid")
 );
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE "drafts" (
+	"draftid" serial not null,
+	"uid" int not null,
+	"contexttype" varchar(50) not null,
+	"contextid" int not null,
+	"title" varchar(100) DEFAULT '' not null,
+	"content" text not null,
+	"updatedat" timestamp not null,
+	PRIMARY KEY("draftid"),
+	UNIQUE("uid","contexttype","contextid")
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE "forums_moderators" (
+	"fid" int not null,
+	"uid" int not null,
+	"pintopic" smallint DEFAULT 0 not null,
+	"closetopic" smallint DEFAULT 0 not null,
+	"movetopic" smallint DEFAULT 0 not null,
+	"deleteposts" smallint DEFAULT 0 not null,
+	"editposts" smallint DEFAULT 0 not null,
+	PRIMARY KEY("fid","uid")
+);
\ No newline at end of file
@@ -1,8 +1,8 @@
 CREATE TABLE "likes" (
-	`weight` tinyint DEFAULT 1 not null,
-	`targetItem` int not null,
-	`targetType` varchar (50) DEFAULT 'replies' not null,
-	`sentBy` int not null,
-	`createdAt` timestamp not null,
-	`recalc` tinyint DEFAULT 0 not null
+	"weight" smallint DEFAULT 1 not null,
+	"targetitem" int not null,
+	"targettype" varchar(50) DEFAULT 'replies' not null,
+	"sentby" int not null,
+	"createdat" timestamp not null,
+	"recalc" smallint DEFAULT 0 not null
 );
\ No newline at end of file
@@ -1,8 +1,8 @@
 CREATE TABLE "login_logs" (
-	`lid` serial not null,
-	`uid` int not null,
-	`success` boolean DEFAULT 0 not null,
-	`ipaddress` varchar (200) not null,
-	`doneAt` timestamp not null,
-	primary key(`lid`)
+	"lid" serial not null,
+	"uid" int not null,
+	"success" smallint DEFAULT 0 not null,
+	"ipaddress" varchar(200) not null,
+	"doneat" timestamp not null,
+	PRIMARY KEY("lid")
 );
\ No newline at end of file
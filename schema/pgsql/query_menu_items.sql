@@ -1,18 +1,18 @@
 CREATE TABLE "menu_items" (
-	`miid` serial not null,
-	`mid` int not null,
-	`name` varchar (200) DEFAULT '' not null,
-	`htmlID` varchar (200) DEFAULT '' not null,
-	`cssClass` varchar (200) DEFAULT '' not null,
-	`position` varchar (100) not null,
-	`path` varchar (200) DEFAULT '' not null,
-	`aria` varchar (200) DEFAULT '' not null,
-	`tooltip` varchar (200) DEFAULT '' not null,
-	`tmplName` varchar (200) DEFAULT '' not null,
-	`order` int DEFAULT 0 not null,
-	`guestOnly` boolean DEFAULT 0 not null,
-	`memberOnly` boolean DEFAULT 0 not null,
-	`staffOnly` boolean DEFAULT 0 not null,
-	`adminOnly` boolean DEFAULT 0 not null,
-	primary key(`miid`)
+	"miid" serial not null,
+	"mid" int not null,
+	"name" varchar(200) DEFAULT '' not null,
+	"htmlid" varchar(200) DEFAULT '' not null,
+	"cssclass" varchar(200) DEFAULT '' not null,
+	"position" varchar(100) not null,
+	"path" varchar(200) DEFAULT '' not null,
+	"aria" varchar(200) DEFAULT '' not null,
+	"tooltip" varchar(200) DEFAULT '' not null,
+	"tmplname" varchar(200) DEFAULT '' not null,
+	"order" int DEFAULT 0 not null,
+	"guestonly" smallint DEFAULT 0 not null,
+	"memberonly" smallint DEFAULT 0 not null,
+	"staffonly" smallint DEFAULT 0 not null,
+	"adminonly" smallint DEFAULT 0 not null,
+	PRIMARY KEY("miid")
 );
\ No newline at end of file
@@ -1,4 +1,4 @@
 CREATE TABLE "menus" (
-	`mid` serial not null,
-	primary key(`mid`)
+	"mid" serial not null,
+	PRIMARY KEY("mid")
 );
\ No newline at end of file
@@ -1,9 +1,9 @@
 CREATE TABLE "moderation_logs" (
-	`action` varchar (100) not null,
-	`elementID` int not null,
-	`elementType` varchar (100) not null,
-	`ipaddress` varchar (200) not null,
-	`actorID` int not null,
-	`doneAt` timestamp not null,
-	`extra` text not null
+	"action" varchar(100) not null,
+	"elementid" int not null,
+	"elementtype" varchar(100) not null,
+	"ipaddress" varchar(200) not null,
+	"actorid" int not null,
+	"doneat" timestamp not null,
+	"extra" text not null
 );
\ No newline at end of file
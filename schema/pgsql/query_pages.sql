@@ -1,9 +1,9 @@
 CREATE TABLE "pages" (
-	`pid` serial not null,
-	`name` varchar (200) not null,
-	`title` varchar (200) not null,
-	`body` text not null,
-	`allowedGroups` text not null,
-	`menuID` int DEFAULT -1 not null,
-	primary key(`pid`)
+	"pid" serial not null,
+	"name" varchar(200) not null,
+	"title" varchar(200) not null,
+	"body" text not null,
+	"allowedgroups" text not null,
+	"menuid" int DEFAULT -1 not null,
+	PRIMARY KEY("pid")
 );
\ No newline at end of file
@@ -1,6 +1,6 @@
 CREATE TABLE "perfchunks" (
-	`low` int DEFAULT 0 not null,
-	`high` int DEFAULT 0 not null,
-	`avg` int DEFAULT 0 not null,
-	`createdAt` timestamp not null
+	"low" int DEFAULT 0 not null,
+	"high" int DEFAULT 0 not null,
+	"avg" int DEFAULT 0 not null,
+	"createdat" timestamp not null
 );
\ No newline at end of file
@@ -1,6 +1,6 @@
 CREATE TABLE "plugins" (
-	`uname` varchar (180) not null,
-	`active` boolean DEFAULT 0 not null,
-	`installed` boolean DEFAULT 0 not null,
-	unique(`uname`)
+	"uname" varchar(180) not null,
+	"active" smallint DEFAULT 0 not null,
+	"installed" smallint DEFAULT 0 not null,
+	UNIQUE("uname")
 );
\ No newline at end of file
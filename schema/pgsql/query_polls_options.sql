@@ -1,5 +1,5 @@
 CREATE TABLE "polls_options" (
-	`pollID` int not null,
-	`option` int DEFAULT 0 not null,
-	`votes` int DEFAULT 0 not null
+	"pollid" int not null,
+	"option" int DEFAULT 0 not null,
+	"votes" int DEFAULT 0 not null
 );
\ No newline at end of file
@@ -1,4 +1,4 @@
 CREATE TABLE "postchunks" (
-	`count` int DEFAULT 0 not null,
-	`createdAt` timestamp not null
+	"count" int DEFAULT 0 not null,
+	"createdat" timestamp not null
 );
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE "profile_fields" (
+	"pfid" serial not null,
+	"name" varchar(100) not null,
+	"type" varchar(50) not null,
+	"options" text not null,
+	"required" smallint DEFAULT 0 not null,
+	"maxlength" int DEFAULT 0 not null,
+	"allowedgroups" text not null,
+	"privacy" int DEFAULT 1 not null,
+	"showonposts" smallint DEFAULT 0 not null,
+	"order" int DEFAULT 0 not null,
+	PRIMARY KEY("pfid")
+);
\ No newline at end of file
@@ -1,19 +1,23 @@
 CREATE TABLE "replies" (
-	`rid` serial not null,
-	`tid` int not null,
-	`content` text not null,
-	`parsed_content` text not null,
-	`createdAt` timestamp not null,
-	`createdBy` int not null,
-	`lastEdit` int DEFAULT 0 not null,
-	`lastEditBy` int DEFAULT 0 not null,
-	`lastUpdated` timestamp not null,
-	`ip` varchar (200) DEFAULT '' not null,
-	`likeCount` int DEFAULT 0 not null,
-	`attachCount` int DEFAULT 0 not null,
-	`words` int DEFAULT 1 not null,
-	`actionType` varchar (20) DEFAULT '' not null,
-	`poll` int DEFAULT 0 not null,
-	primary key(`rid`),
-	fulltext key(`content`)
-);
\ No newline at end of file
+	"rid" serial not null,
+	"tid" int not null,
+	"content" text not null,
+	"parsed_content" text not null,
+	"createdat" timestamp not null,
+	"createdby" int not null,
+	"lastedit" int DEFAULT 0 not null,
+	"lasteditby" int DEFAULT 0 not null,
+	"lastupdated" timestamp not null,
+	"ip" varchar(200) DEFAULT '' not null,
+	"likecount" int DEFAULT 0 not null,
+	"attachcount" int DEFAULT 0 not null,
+	"words" int DEFAULT 1 not null,
+	"actiontype" varchar(20) DEFAULT '' not null,
+	"poll" int DEFAULT 0 not null,
+	"votes" int DEFAULT 0 not null,
+	"deleted" smallint DEFAULT 0 not null,
+	"deletedat" timestamp null,
+	"deletedby" int DEFAULT 0 not null,
+	PRIMARY KEY("rid")
+);
+CREATE INDEX "ft_replies_content" ON "replies" USING GIN(to_tsvector('simple',"content"));
\ No newline at end of file
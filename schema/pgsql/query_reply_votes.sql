@@ -0,0 +1,8 @@
+CREATE TABLE "reply_votes" (
+	"rid" int not null,
+	"tid" int not null,
+	"uid" int not null,
+	"weight" smallint DEFAULT 1 not null,
+	"createdat" timestamp not null,
+	UNIQUE("rid","uid")
+);
\ No newline at end of file
@@ -1,8 +1,11 @@
 CREATE TABLE "revisions" (
-	`reviseID` serial not null,
-	`content` text not null,
-	`contentID` int not null,
-	`contentType` varchar (100) DEFAULT 'replies' not null,
-	`createdAt` timestamp not null,
-	primary key(`reviseID`)
+	"reviseid" serial not null,
+	"title" varchar(100) DEFAULT '' not null,
+	"content" text not null,
+	"contentid" int not null,
+	"contenttype" varchar(100) DEFAULT 'replies' not null,
+	"createdat" timestamp not null,
+	"createdby" int DEFAULT 0 not null,
+	"ip" varchar(200) DEFAULT '' not null,
+	PRIMARY KEY("reviseid")
 );
\ No newline at end of file
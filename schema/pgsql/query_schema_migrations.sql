@@ -0,0 +1,7 @@
+CREATE TABLE "schema_migrations" (
+	"version" int not null,
+	"name" varchar(200) not null,
+	"checksum" varchar(100) not null,
+	"appliedat" timestamp not null,
+	PRIMARY KEY("version")
+);
\ No newline at end of file
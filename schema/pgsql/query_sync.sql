@@ -1,3 +1,3 @@
 CREATE TABLE "sync" (
-	`last_update` timestamp not null
+	"last_update" timestamp not null
 );
\ No newline at end of file
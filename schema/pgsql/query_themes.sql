@@ -1,5 +1,5 @@
 CREATE TABLE "themes" (
-	`uname` varchar (180) not null,
-	`default` boolean DEFAULT 0 not null,
-	unique(`uname`)
+	"uname" varchar(180) not null,
+	"default" smallint DEFAULT 0 not null,
+	UNIQUE("uname")
 );
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE "topic_prefixes" (
+	"prid" serial not null,
+	"name" varchar(100) not null,
+	"cssclass" varchar(100) DEFAULT '' not null,
+	"forums" text not null,
+	"allowedgroups" text not null,
+	"order" int DEFAULT 0 not null,
+	PRIMARY KEY("prid")
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE "topic_tags" (
+	"tid" int not null,
+	"tag" varchar(100) not null,
+	UNIQUE("tid","tag")
+);
\ No newline at end of file
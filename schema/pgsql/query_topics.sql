@@ -1,28 +1,35 @@
 CREATE TABLE "topics" (
-	`tid` serial not null,
-	`title` varchar (100) not null,
-	`content` text not null,
-	`parsed_content` text not null,
-	`createdAt` timestamp not null,
-	`lastReplyAt` timestamp not null,
-	`lastReplyBy` int not null,
-	`lastReplyID` int DEFAULT 0 not null,
-	`createdBy` int not null,
-	`is_closed` boolean DEFAULT 0 not null,
-	`sticky` boolean DEFAULT 0 not null,
-	`parentID` int DEFAULT 2 not null,
-	`ip` varchar (200) DEFAULT '' not null,
-	`postCount` int DEFAULT 1 not null,
-	`likeCount` int DEFAULT 0 not null,
-	`attachCount` int DEFAULT 0 not null,
-	`words` int DEFAULT 0 not null,
-	`views` int DEFAULT 0 not null,
-	`weekEvenViews` int DEFAULT 0 not null,
-	`weekOddViews` int DEFAULT 0 not null,
-	`css_class` varchar (100) DEFAULT '' not null,
-	`poll` int DEFAULT 0 not null,
-	`data` varchar (200) DEFAULT '' not null,
-	primary key(`tid`),
-	fulltext key(`title`),
-	fulltext key(`content`)
-);
\ No newline at end of file
+	"tid" serial not null,
+	"title" varchar(100) not null,
+	"content" text not null,
+	"parsed_content" text not null,
+	"createdat" timestamp not null,
+	"lastreplyat" timestamp not null,
+	"lastreplyby" int not null,
+	"lastreplyid" int DEFAULT 0 not null,
+	"createdby" int not null,
+	"is_closed" smallint DEFAULT 0 not null,
+	"sticky" smallint DEFAULT 0 not null,
+	"parentid" int DEFAULT 2 not null,
+	"ip" varchar(200) DEFAULT '' not null,
+	"postcount" int DEFAULT 1 not null,
+	"likecount" int DEFAULT 0 not null,
+	"attachcount" int DEFAULT 0 not null,
+	"words" int DEFAULT 0 not null,
+	"views" int DEFAULT 0 not null,
+	"weekevenviews" int DEFAULT 0 not null,
+	"weekoddviews" int DEFAULT 0 not null,
+	"css_class" varchar(100) DEFAULT '' not null,
+	"poll" int DEFAULT 0 not null,
+	"data" varchar(200) DEFAULT '' not null,
+	"prefix" int DEFAULT 0 not null,
+	"answer" int DEFAULT 0 not null,
+	"deleted" smallint DEFAULT 0 not null,
+	"deletedat" timestamp null,
+	"deletedby" int DEFAULT 0 not null,
+	"lastedit" int DEFAULT 0 not null,
+	"lasteditby" int DEFAULT 0 not null,
+	PRIMARY KEY("tid")
+);
+CREATE INDEX "ft_topics_title" ON "topics" USING GIN(to_tsvector('simple',"title"));
+CREATE INDEX "ft_topics_content" ON "topics" USING GIN(to_tsvector('simple',"content"));
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE "topics_scheduled" (
+	"stid" serial not null,
+	"parentid" int not null,
+	"title" varchar(100) not null,
+	"content" text not null,
+	"createdby" int not null,
+	"ip" varchar(200) DEFAULT '' not null,
+	"prefix" int DEFAULT 0 not null,
+	"tags" text not null,
+	"publishat" timestamp not null,
+	"createdat" timestamp not null,
+	PRIMARY KEY("stid")
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE "topics_timed_actions" (
+	"taid" serial not null,
+	"tid" int not null,
+	"action" varchar(50) not null,
+	"days" int not null,
+	"inactive" smallint DEFAULT 0 not null,
+	"createdby" int not null,
+	"createdat" timestamp not null,
+	PRIMARY KEY("taid"),
+	UNIQUE("tid","action")
+);
\ No newline at end of file
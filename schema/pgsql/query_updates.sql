@@ -1,3 +1,3 @@
 CREATE TABLE "updates" (
-	`dbVersion` int DEFAULT 0 not null
+	"dbversion" int DEFAULT 0 not null
 );
\ No newline at end of file
@@ -1,4 +1,4 @@
 CREATE TABLE "users_avatar_queue" (
-	`uid` int not null,
-	primary key(`uid`)
+	"uid" int not null,
+	PRIMARY KEY("uid")
 );
\ No newline at end of file
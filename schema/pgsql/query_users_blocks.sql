@@ -1,4 +1,4 @@
 CREATE TABLE "users_blocks" (
-	`blocker` int not null,
-	`blockedUser` int not null
+	"blocker" int not null,
+	"blockeduser" int not null
 );
\ No newline at end of file
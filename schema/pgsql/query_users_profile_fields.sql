@@ -0,0 +1,7 @@
+CREATE TABLE "users_profile_fields" (
+	"uid" int not null,
+	"pfid" int not null,
+	"value" text not null,
+	"privacy" int DEFAULT 0 not null,
+	UNIQUE("uid","pfid")
+);
\ No newline at end of file
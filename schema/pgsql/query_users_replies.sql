@@ -1,12 +1,12 @@
 CREATE TABLE "users_replies" (
-	`rid` serial not null,
-	`uid` int not null,
-	`content` text not null,
-	`parsed_content` text not null,
-	`createdAt` timestamp not null,
-	`createdBy` int not null,
-	`lastEdit` int DEFAULT 0 not null,
-	`lastEditBy` int DEFAULT 0 not null,
-	`ip` varchar (200) DEFAULT '' not null,
-	primary key(`rid`)
+	"rid" serial not null,
+	"uid" int not null,
+	"content" text not null,
+	"parsed_content" text not null,
+	"createdat" timestamp not null,
+	"createdby" int not null,
+	"lastedit" int DEFAULT 0 not null,
+	"lasteditby" int DEFAULT 0 not null,
+	"ip" varchar(200) DEFAULT '' not null,
+	PRIMARY KEY("rid")
 );
\ No newline at end of file
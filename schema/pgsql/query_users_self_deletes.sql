@@ -0,0 +1,6 @@
+CREATE TABLE "users_self_deletes" (
+	"uid" int not null,
+	"mode" varchar(20) not null,
+	"requestedat" timestamp not null,
+	PRIMARY KEY("uid")
+);
\ No newline at end of file
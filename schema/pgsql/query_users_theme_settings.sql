@@ -0,0 +1,7 @@
+CREATE TABLE "users_theme_settings" (
+	"uid" int not null,
+	"theme" varchar(50) not null,
+	"name" varchar(50) not null,
+	"value" varchar(50) not null,
+	UNIQUE("uid","theme","name")
+);
\ No newline at end of file
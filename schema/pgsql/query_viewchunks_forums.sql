@@ -1,5 +1,5 @@
 CREATE TABLE "viewchunks_forums" (
-	`count` int DEFAULT 0 not null,
-	`createdAt` timestamp not null,
-	`forum` int not null
+	"count" int DEFAULT 0 not null,
+	"createdat" timestamp not null,
+	"forum" int not null
 );
\ No newline at end of file
@@ -1,5 +1,5 @@
 CREATE TABLE "viewchunks_systems" (
-	`count` int DEFAULT 0 not null,
-	`createdAt` timestamp not null,
-	`system` varchar (200) not null
+	"count" int DEFAULT 0 not null,
+	"createdat" timestamp not null,
+	"system" varchar(200) not null
 );
\ No newline at end of file
@@ -1,6 +1,6 @@
 CREATE TABLE "word_filters" (
-	`wfid` serial not null,
-	`find` varchar (200) not null,
-	`replacement` varchar (200) not null,
-	primary key(`wfid`)
+	"wfid" serial not null,
+	"find" varchar(200) not null,
+	"replacement" varchar(200) not null,
+	PRIMARY KEY("wfid")
 );
\ No newline at end of file
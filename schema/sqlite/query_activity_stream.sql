@@ -0,0 +1,11 @@
+CREATE TABLE "activity_stream" (
+	"asid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"actor" int not null,
+	"targetUser" int not null,
+	"event" varchar(50) not null,
+	"elementType" varchar(50) not null,
+	"elementTable" int DEFAULT 0 not null,
+	"elementID" int not null,
+	"createdAt" datetime not null,
+	"extra" varchar(200) DEFAULT '' not null
+);
\ No newline at end of file
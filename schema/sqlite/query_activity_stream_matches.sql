@@ -0,0 +1,5 @@
+CREATE TABLE "activity_stream_matches" (
+	"watcher" int not null,
+	"asid" int not null,
+	FOREIGN KEY("asid") REFERENCES "activity_stream"("asid") ON DELETE CASCADE
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE "attachments" (
+	"attachID" integer PRIMARY KEY AUTOINCREMENT not null,
+	"sectionID" int DEFAULT 0 not null,
+	"sectionTable" varchar(200) DEFAULT 'forums' not null,
+	"originID" int not null,
+	"originTable" varchar(200) DEFAULT 'replies' not null,
+	"uploadedBy" int not null,
+	"path" varchar(200) not null,
+	"extra" varchar(200) not null
+);
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE "cluster_events" (
+	"ceid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"origin" varchar(50) not null,
+	"type" varchar(50) not null,
+	"eid" int DEFAULT 0 not null,
+	"data" text not null,
+	"createdAt" datetime not null
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE "conversations" (
+	"cid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"createdBy" int not null,
+	"createdAt" datetime not null,
+	"lastReplyAt" datetime not null,
+	"lastReplyBy" int not null
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE "conversations_posts" (
+	"pid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"cid" int not null,
+	"createdBy" int not null,
+	"body" text not null,
+	"post" varchar(50) DEFAULT '' not null
+);
\ No newline at end of file
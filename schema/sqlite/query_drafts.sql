@@ -0,0 +1,10 @@
+CREATE TABLE "drafts" (
+	"draftID" integer PRIMARY KEY AUTOINCREMENT not null,
+	"uid" int not null,
+	"contextType" varchar(50) not null,
+	"contextID" int not null,
+	"title" varchar(100) DEFAULT '' not null,
+	"content" text not null,
+	"updatedAt" datetime not null,
+	UNIQUE("uid","contextType","contextID")
+);
\ No newline at end of file
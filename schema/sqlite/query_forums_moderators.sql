@@ -0,0 +1,10 @@
+CREATE TABLE "forums_moderators" (
+	"fid" int not null,
+	"uid" int not null,
+	"pinTopic" boolean DEFAULT 0 not null,
+	"closeTopic" boolean DEFAULT 0 not null,
+	"moveTopic" boolean DEFAULT 0 not null,
+	"deletePosts" boolean DEFAULT 0 not null,
+	"editPosts" boolean DEFAULT 0 not null,
+	PRIMARY KEY("fid","uid")
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE "login_logs" (
+	"lid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"uid" int not null,
+	"success" boolean DEFAULT 0 not null,
+	"ipaddress" varchar(200) not null,
+	"doneAt" datetime not null
+);
\ No newline at end of file
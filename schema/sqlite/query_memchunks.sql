@@ -0,0 +1,6 @@
+CREATE TABLE "memchunks" (
+	"count" int DEFAULT 0 not null,
+	"stack" int DEFAULT 0 not null,
+	"heap" int DEFAULT 0 not null,
+	"createdAt" datetime not null
+);
\ No newline at end of file
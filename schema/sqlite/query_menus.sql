@@ -0,0 +1,3 @@
+CREATE TABLE "menus" (
+	"mid" integer PRIMARY KEY AUTOINCREMENT not null
+);
\ No newline at end of file
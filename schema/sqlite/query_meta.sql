@@ -0,0 +1,4 @@
+CREATE TABLE "meta" (
+	"name" varchar(200) not null,
+	"value" varchar(200) not null
+);
\ No newline at end of file
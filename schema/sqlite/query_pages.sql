@@ -0,0 +1,8 @@
+CREATE TABLE "pages" (
+	"pid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"name" varchar(200) not null,
+	"title" varchar(200) not null,
+	"body" text not null,
+	"allowedGroups" text not null,
+	"menuID" int DEFAULT -1 not null
+);
\ No newline at end of file
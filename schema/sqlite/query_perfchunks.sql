@@ -0,0 +1,6 @@
+CREATE TABLE "perfchunks" (
+	"low" int DEFAULT 0 not null,
+	"high" int DEFAULT 0 not null,
+	"avg" int DEFAULT 0 not null,
+	"createdAt" datetime not null
+);
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE "polls" (
+	"pollID" integer PRIMARY KEY AUTOINCREMENT not null,
+	"parentID" int DEFAULT 0 not null,
+	"parentTable" varchar(100) DEFAULT 'topics' not null,
+	"type" int DEFAULT 0 not null,
+	"options" text not null,
+	"votes" int DEFAULT 0 not null
+);
\ No newline at end of file
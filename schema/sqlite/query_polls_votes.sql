@@ -0,0 +1,7 @@
+CREATE TABLE "polls_votes" (
+	"pollID" int not null,
+	"uid" int not null,
+	"option" int DEFAULT 0 not null,
+	"castAt" datetime not null,
+	"ip" varchar(200) DEFAULT '' not null
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+CREATE TABLE "postchunks" (
+	"count" int DEFAULT 0 not null,
+	"createdAt" datetime not null
+);
\ No newline at end of file
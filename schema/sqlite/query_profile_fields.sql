@@ -0,0 +1,12 @@
+CREATE TABLE "profile_fields" (
+	"pfid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"name" varchar(100) not null,
+	"type" varchar(50) not null,
+	"options" text not null,
+	"required" boolean DEFAULT 0 not null,
+	"maxLength" int DEFAULT 0 not null,
+	"allowedGroups" text not null,
+	"privacy" int DEFAULT 1 not null,
+	"showOnPosts" boolean DEFAULT 0 not null,
+	"order" int DEFAULT 0 not null
+);
\ No newline at end of file
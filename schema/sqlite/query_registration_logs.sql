@@ -0,0 +1,9 @@
+CREATE TABLE "registration_logs" (
+	"rlid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"username" varchar(100) not null,
+	"email" varchar(100) not null,
+	"failureReason" varchar(100) not null,
+	"success" boolean DEFAULT 0 not null,
+	"ipaddress" varchar(200) not null,
+	"doneAt" datetime not null
+);
\ No newline at end of file
@@ -0,0 +1,21 @@
+CREATE TABLE "replies" (
+	"rid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"tid" int not null,
+	"content" text not null,
+	"parsed_content" text not null,
+	"createdAt" datetime not null,
+	"createdBy" int not null,
+	"lastEdit" int DEFAULT 0 not null,
+	"lastEditBy" int DEFAULT 0 not null,
+	"lastUpdated" datetime not null,
+	"ip" varchar(200) DEFAULT '' not null,
+	"likeCount" int DEFAULT 0 not null,
+	"attachCount" int DEFAULT 0 not null,
+	"words" int DEFAULT 1 not null,
+	"actionType" varchar(20) DEFAULT '' not null,
+	"poll" int DEFAULT 0 not null,
+	"votes" int DEFAULT 0 not null,
+	"deleted" boolean DEFAULT 0 not null,
+	"deletedAt" datetime null,
+	"deletedBy" int DEFAULT 0 not null
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE "revisions" (
+	"reviseID" integer PRIMARY KEY AUTOINCREMENT not null,
+	"title" varchar(100) DEFAULT '' not null,
+	"content" text not null,
+	"contentID" int not null,
+	"contentType" varchar(100) DEFAULT 'replies' not null,
+	"createdAt" datetime not null,
+	"createdBy" int DEFAULT 0 not null,
+	"ip" varchar(200) DEFAULT '' not null
+);
\ No newline at end of file
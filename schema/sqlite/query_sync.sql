@@ -0,0 +1,3 @@
+CREATE TABLE "sync" (
+	"last_update" datetime not null
+);
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE "topic_prefixes" (
+	"prid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"name" varchar(100) not null,
+	"cssClass" varchar(100) DEFAULT '' not null,
+	"forums" text not null,
+	"allowedGroups" text not null,
+	"order" int DEFAULT 0 not null
+);
\ No newline at end of file
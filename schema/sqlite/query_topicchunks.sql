@@ -0,0 +1,4 @@
+CREATE TABLE "topicchunks" (
+	"count" int DEFAULT 0 not null,
+	"createdAt" datetime not null
+);
\ No newline at end of file
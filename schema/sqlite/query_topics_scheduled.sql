@@ -0,0 +1,12 @@
+CREATE TABLE "topics_scheduled" (
+	"stid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"parentID" int not null,
+	"title" varchar(100) not null,
+	"content" text not null,
+	"createdBy" int not null,
+	"ip" varchar(200) DEFAULT '' not null,
+	"prefix" int DEFAULT 0 not null,
+	"tags" text not null,
+	"publishAt" datetime not null,
+	"createdAt" datetime not null
+);
\ No newline at end of file
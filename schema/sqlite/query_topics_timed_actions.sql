@@ -0,0 +1,10 @@
+CREATE TABLE "topics_timed_actions" (
+	"taid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"tid" int not null,
+	"action" varchar(50) not null,
+	"days" int not null,
+	"inactive" boolean DEFAULT 0 not null,
+	"createdBy" int not null,
+	"createdAt" datetime not null,
+	UNIQUE("tid","action")
+);
\ No newline at end of file
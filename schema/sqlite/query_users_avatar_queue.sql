@@ -0,0 +1,4 @@
+CREATE TABLE "users_avatar_queue" (
+	"uid" int not null,
+	PRIMARY KEY("uid")
+);
\ No newline at end of file
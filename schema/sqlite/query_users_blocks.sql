@@ -0,0 +1,4 @@
+CREATE TABLE "users_blocks" (
+	"blocker" int not null,
+	"blockedUser" int not null
+);
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE "users_replies" (
+	"rid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"uid" int not null,
+	"content" text not null,
+	"parsed_content" text not null,
+	"createdAt" datetime not null,
+	"createdBy" int not null,
+	"lastEdit" int DEFAULT 0 not null,
+	"lastEditBy" int DEFAULT 0 not null,
+	"ip" varchar(200) DEFAULT '' not null
+);
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE "viewchunks" (
+	"count" int DEFAULT 0 not null,
+	"avg" int DEFAULT 0 not null,
+	"createdAt" datetime not null,
+	"route" varchar(200) not null
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE "viewchunks_forums" (
+	"count" int DEFAULT 0 not null,
+	"createdAt" datetime not null,
+	"forum" int not null
+);
\ No newline at end of file
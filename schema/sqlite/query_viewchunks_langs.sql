@@ -0,0 +1,5 @@
+CREATE TABLE "viewchunks_langs" (
+	"count" int DEFAULT 0 not null,
+	"createdAt" datetime not null,
+	"lang" varchar(200) not null
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE "viewchunks_referrers" (
+	"count" int DEFAULT 0 not null,
+	"createdAt" datetime not null,
+	"domain" varchar(200) not null
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE "widgets" (
+	"wid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"position" int not null,
+	"side" varchar(100) not null,
+	"type" varchar(100) not null,
+	"active" boolean DEFAULT 0 not null,
+	"location" varchar(100) not null,
+	"data" text not null
+);
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE "word_filters" (
+	"wfid" integer PRIMARY KEY AUTOINCREMENT not null,
+	"find" varchar(200) not null,
+	"replacement" varchar(200) not null
+);
\ No newline at end of file
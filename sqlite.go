@@ -0,0 +1,55 @@
+// +build sqlite
+
+/*
+*
+*	Gosora SQLite Interface
+*	Copyright Azareal 2020
+*
+ */
+package main
+
+import (
+	"log"
+
+	c "github.com/Azareal/Gosora/common"
+	qgen "github.com/Azareal/Gosora/query_gen"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	dbAdapter = "sqlite"
+	_initDatabase = initSqlite
+}
+
+// The database name is used as the path to the database file
+func initSqlite() (err error) {
+	err = qgen.Builder.Init("sqlite", map[string]string{
+		"name": c.DbConfig.Dbname,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// SQLite only lets one connection write at a time, so there isn't much point in having as many connections open as we do with the other adapters
+	db = qgen.Builder.GetConn()
+	db.SetMaxOpenConns(16)
+	db.SetMaxIdleConns(8)
+
+	err = _gen_sqlite()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Print("Preparing getActivityFeedByWatcher statement.")
+	stmts.getActivityFeedByWatcher, err = db.Prepare("SELECT activity_stream_matches.asid, activity_stream.actor, activity_stream.targetUser, activity_stream.event, activity_stream.elementType, activity_stream.elementID, activity_stream.createdAt FROM activity_stream_matches INNER JOIN activity_stream ON activity_stream_matches.asid = activity_stream.asid AND activity_stream_matches.watcher != activity_stream.actor WHERE watcher=? ORDER BY activity_stream.asid DESC LIMIT ?")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	log.Print("Preparing getActivityCountByWatcher statement.")
+	stmts.getActivityCountByWatcher, err = db.Prepare("SELECT count(*) FROM activity_stream_matches INNER JOIN activity_stream ON activity_stream_matches.asid = activity_stream.asid AND activity_stream_matches.watcher != activity_stream.actor WHERE watcher=?")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
@@ -91,7 +91,7 @@ func tickLoop(thumbChan chan bool) {
 
 			// TODO: Handle delayed moderation tasks
 
-			// Sync with the database, if there are any changes
+			// Apply the changes the other servers in the cluster have made
 			if err = c.HandleServerSync(); err != nil {
 				c.LogError(err)
 			}
@@ -186,6 +186,31 @@ func dailies() {
 		f("login_logs")
 		f("registration_logs")
 	}
+	if c.Config.TrashPurgeCutoff > -1 {
+		_, err := c.Trash.Purge(c.Config.TrashPurgeCutoff)
+		if err != nil {
+			c.LogError(err)
+		}
+	}
+	if c.Config.DraftExpiry > -1 {
+		err := c.Drafts.Prune(c.Config.DraftExpiry)
+		if err != nil {
+			c.LogError(err)
+		}
+	}
+	_, err := c.SelfDeletes.Process(c.SelfDeleteGrace())
+	if err != nil {
+		c.LogError(err)
+	}
+	if c.Config.BackupInterval > 0 {
+		// Backups of the larger forums might take a while, so we don't want to hold up the other tasks
+		go func() {
+			err := c.ScheduledBackup()
+			if err != nil {
+				c.LogError(err)
+			}
+		}()
+	}
 
 	if c.Config.DisablePostIP {
 		f := func(tbl string) {